@@ -44,7 +44,8 @@ func main() {
 	// Run tenant migrations for all existing active tenants
 	if err := migrationRunner.RunAllTenantMigrations("migrations/tenants"); err != nil {
 		log.Printf("⚠️  Warning: Some tenant migrations failed: %v", err)
-		// Don't fail startup, just log the warning
+		// Don't fail startup, just log the warning; `stegctl migrate status` lists the
+		// tenants that are behind and `stegctl migrate up` retries them
 	}
 
 	log.Println("✅ Database migrations completed")
@@ -0,0 +1,312 @@
+// Command stegctl manages the databases of the platform outside of the API process.
+//
+// Usage:
+//
+//	stegctl migrate status [-tenant ID|slug] [-json]
+//	stegctl migrate up     [-tenant ID|slug] [-concurrency N] [-json]
+//	stegctl migrate down   -tenant ID|slug [-steps N] [-json]
+//	stegctl migrate force  -tenant ID|slug -version N [-json]
+//	stegctl migrate drift  [-tenant ID|slug] [-concurrency N] [-json]
+//	stegctl tenant import  -file PATH -owner USER_ID -name NAME -slug SLUG -email EMAIL -phone PHONE [-description TEXT] [-json]
+//
+// Migrate commands act on every active tenant unless -tenant is given; down and force always
+// need one. The report lists the migration version of each tenant against the latest
+// migration in -path. drift also checks that the tables and indexes created by the applied
+// migrations exist. status and drift exit with 1 when a tenant is behind, dirty, drifted or
+// unreachable, and the other commands when they fail for any tenant.
+//
+// tenant import creates a tenant from an archive exported by the API. The archive is read
+// from disk as it is imported, so archives larger than the request body limit of the API
+// can be imported.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/DanielIturra1610/stegmaier-landing/internal/shared/config"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/shared/database"
+)
+
+const usage = `Usage:
+  stegctl migrate status [-tenant ID|slug] [-json]
+  stegctl migrate up     [-tenant ID|slug] [-concurrency N] [-json]
+  stegctl migrate down   -tenant ID|slug [-steps N] [-json]
+  stegctl migrate force  -tenant ID|slug -version N [-json]
+  stegctl migrate drift  [-tenant ID|slug] [-concurrency N] [-json]
+  stegctl tenant import  -file PATH -owner USER_ID -name NAME -slug SLUG -email EMAIL -phone PHONE [-description TEXT] [-json]
+`
+
+// Exit codes
+const (
+	exitOK     = 0
+	exitFailed = 1
+	exitUsage  = 2
+)
+
+// defaultPath is the directory of the tenant migrations, relative to the backend
+const defaultPath = "migrations/tenants"
+
+// noVersion is the default of -version, which force requires; -1 is a valid version that
+// marks the database as having no migration applied
+const noVersion = -2
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout))
+}
+
+// migrateOptions are the flags of the migrate commands
+type migrateOptions struct {
+	command     string
+	tenant      string
+	path        string
+	concurrency int
+	steps       int
+	version     int
+	json        bool
+}
+
+// run runs a command and returns the exit code
+func run(args []string, stdout io.Writer) int {
+	if len(args) >= 2 && args[0] == "tenant" {
+		return runTenant(args[1:], stdout)
+	}
+	if len(args) < 2 || args[0] != "migrate" {
+		fmt.Fprint(os.Stderr, usage)
+		return exitUsage
+	}
+
+	opts := migrateOptions{command: args[1]}
+	flags := flag.NewFlagSet("stegctl migrate "+opts.command, flag.ContinueOnError)
+	flags.StringVar(&opts.tenant, "tenant", "", "ID or slug of the tenant (default: every active tenant)")
+	flags.StringVar(&opts.path, "path", defaultPath, "Directory of the tenant migrations")
+	flags.IntVar(&opts.concurrency, "concurrency", 4, "Tenants migrated or checked at the same time")
+	flags.IntVar(&opts.steps, "steps", 1, "Migrations to roll back")
+	flags.IntVar(&opts.version, "version", noVersion, "Version to force (-1 for none)")
+	flags.BoolVar(&opts.json, "json", false, "Print the report as JSON")
+	if err := flags.Parse(args[2:]); err != nil {
+		return exitUsage
+	}
+
+	switch opts.command {
+	case "status", "up", "drift":
+	case "down":
+		if opts.tenant == "" || opts.steps < 1 {
+			fmt.Fprintln(os.Stderr, "down needs -tenant and a positive -steps")
+			return exitUsage
+		}
+	case "force":
+		if opts.tenant == "" || opts.version < -1 {
+			fmt.Fprintln(os.Stderr, "force needs -tenant and -version")
+			return exitUsage
+		}
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		return exitUsage
+	}
+
+	versions, err := database.MigrationVersions(opts.path)
+	if err != nil {
+		log.Printf("❌ %v", err)
+		return exitFailed
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Printf("❌ Failed to load configuration: %v", err)
+		return exitFailed
+	}
+	manager, err := database.NewManager(cfg)
+	if err != nil {
+		log.Printf("❌ Failed to initialize database manager: %v", err)
+		return exitFailed
+	}
+	defer manager.CloseAll()
+
+	tenants, err := selectTenants(manager, opts.tenant)
+	if err != nil {
+		log.Printf("❌ %v", err)
+		return exitFailed
+	}
+
+	runner := database.NewMigrationRunner(manager)
+	if opts.command == "drift" {
+		return runDriftCommand(runner, tenants, opts, stdout)
+	}
+	report := runMigrateCommand(runner, tenants, versions, opts)
+
+	if opts.json {
+		if err := writeJSON(stdout, report); err != nil {
+			log.Printf("❌ Failed to write report: %v", err)
+			return exitFailed
+		}
+	} else {
+		printReport(stdout, report)
+	}
+
+	if report.failed(opts.command == "status") {
+		return exitFailed
+	}
+	return exitOK
+}
+
+// runDriftCommand checks the migrations and schema of the tenants
+func runDriftCommand(runner *database.MigrationRunner, tenants []database.TenantInfo, opts migrateOptions, stdout io.Writer) int {
+	report, err := runner.CheckTenantDrift(tenants, opts.path, opts.concurrency)
+	if err != nil {
+		log.Printf("❌ %v", err)
+		return exitFailed
+	}
+
+	if opts.json {
+		if err := writeJSON(stdout, report); err != nil {
+			log.Printf("❌ Failed to write report: %v", err)
+			return exitFailed
+		}
+	} else {
+		printDriftReport(stdout, report)
+	}
+
+	if report.Summary.Drifted() > 0 {
+		return exitFailed
+	}
+	return exitOK
+}
+
+// writeJSON writes a report as indented JSON
+func writeJSON(w io.Writer, report interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
+
+// selectTenants returns the active tenant named by ID or slug, or every active tenant
+func selectTenants(manager *database.Manager, tenant string) ([]database.TenantInfo, error) {
+	tenants, err := manager.ListActiveTenants()
+	if err != nil {
+		return nil, err
+	}
+	if tenant == "" {
+		return tenants, nil
+	}
+
+	for _, t := range tenants {
+		if t.ID == tenant || t.Slug == tenant {
+			return []database.TenantInfo{t}, nil
+		}
+	}
+	return nil, fmt.Errorf("tenant %s not found or not active", tenant)
+}
+
+// runMigrateCommand runs a migrate command on the tenants and reports their versions after it
+func runMigrateCommand(runner *database.MigrationRunner, tenants []database.TenantInfo, versions []uint, opts migrateOptions) *migrationReport {
+	results := database.ForEachTenant(tenants, opts.concurrency, func(tenant database.TenantInfo) tenantReport {
+		if opts.command == "status" {
+			return tenantReport{TenantMigrationStatus: runner.GetTenantMigrationStatus(tenant, opts.path, versions)}
+		}
+
+		before := runner.GetTenantMigrationStatus(tenant, opts.path, versions)
+		var err error
+		switch opts.command {
+		case "up":
+			err = runner.RunTenantMigrations(tenant.ID, opts.path)
+		case "down":
+			err = runner.RollbackTenantMigrations(tenant.ID, opts.path, opts.steps)
+		case "force":
+			err = runner.ForceTenantMigrationVersion(tenant.ID, opts.path, opts.version)
+		}
+
+		after := runner.GetTenantMigrationStatus(tenant, opts.path, versions)
+		if err != nil {
+			after.State = database.MigrationStateError
+			after.Error = err.Error()
+		}
+		return tenantReport{TenantMigrationStatus: after, PreviousVersion: &before.Version}
+	})
+
+	report := &migrationReport{
+		Command:        opts.command,
+		MigrationsPath: opts.path,
+		Tenants:        results,
+	}
+	if len(versions) > 0 {
+		report.LatestVersion = versions[len(versions)-1]
+	}
+	for _, result := range results {
+		report.Summary.Add(result.State)
+	}
+	return report
+}
+
+// migrationReport is the machine-readable result of a migrate command
+type migrationReport struct {
+	Command        string                    `json:"command"`
+	MigrationsPath string                    `json:"migrations_path"`
+	LatestVersion  uint                      `json:"latest_version"`
+	Summary        database.MigrationSummary `json:"summary"`
+	Tenants        []tenantReport            `json:"tenants"`
+}
+
+// tenantReport is the migration state of a tenant; PreviousVersion is its version before the
+// command when it changes migrations
+type tenantReport struct {
+	database.TenantMigrationStatus
+	PreviousVersion *uint `json:"previous_version,omitempty"`
+}
+
+// failed checks if the command failed for a tenant. Drift only counts as a failure for status,
+// since a rollback leaves tenants behind on purpose.
+func (r *migrationReport) failed(drift bool) bool {
+	if r.Summary.Failed > 0 {
+		return true
+	}
+	return drift && (r.Summary.Behind > 0 || r.Summary.Dirty > 0)
+}
+
+// printReport prints a report as a table
+func printReport(w io.Writer, report *migrationReport) {
+	table := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(table, "TENANT\tSLUG\tVERSION\tLATEST\tPENDING\tSTATE\tERROR")
+	for _, tenant := range report.Tenants {
+		version := fmt.Sprint(tenant.Version)
+		if tenant.PreviousVersion != nil && *tenant.PreviousVersion != tenant.Version {
+			version = fmt.Sprintf("%d -> %d", *tenant.PreviousVersion, tenant.Version)
+		}
+		fmt.Fprintf(table, "%s\t%s\t%s\t%d\t%d\t%s\t%s\n",
+			tenant.TenantID, tenant.Slug, version, tenant.LatestVersion, tenant.Pending, tenant.State, tenant.Error)
+	}
+	table.Flush()
+
+	s := report.Summary
+	fmt.Fprintf(w, "\n%d tenants: %d up to date, %d behind, %d dirty, %d failed (latest version %d)\n",
+		s.Total, s.UpToDate, s.Behind, s.Dirty, s.Failed, report.LatestVersion)
+}
+
+// printDriftReport prints a drift report as a table, with the missing tables and indexes
+func printDriftReport(w io.Writer, report *database.TenantDriftReport) {
+	table := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(table, "TENANT\tSLUG\tVERSION\tLATEST\tPENDING\tSTATE\tMISSING\tERROR")
+	for _, tenant := range report.Tenants {
+		var missing []string
+		for _, name := range tenant.MissingTables {
+			missing = append(missing, "table "+name)
+		}
+		for _, name := range tenant.MissingIndexes {
+			missing = append(missing, "index "+name)
+		}
+		fmt.Fprintf(table, "%s\t%s\t%d\t%d\t%d\t%s\t%s\t%s\n",
+			tenant.TenantID, tenant.Slug, tenant.Version, tenant.LatestVersion, tenant.Pending, tenant.State,
+			strings.Join(missing, ", "), tenant.Error)
+	}
+	table.Flush()
+
+	s := report.Summary
+	fmt.Fprintf(w, "\n%d tenants: %d up to date, %d behind, %d dirty, %d with schema drift, %d failed (latest version %d)\n",
+		s.Total, s.UpToDate, s.Behind, s.Dirty, s.SchemaDrift, s.Failed, report.LatestVersion)
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	mediaadapters "github.com/DanielIturra1610/stegmaier-landing/internal/core/media/adapters"
+	tenantadapters "github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/adapters"
+	tenantdomain "github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/domain"
+	tenantports "github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/ports"
+	tenantservices "github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/services"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/shared/config"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/shared/database"
+	"github.com/google/uuid"
+)
+
+// importOptions are the flags of tenant import
+type importOptions struct {
+	file  string
+	owner string
+	dto   tenantdomain.CreateTenantDTO
+	json  bool
+}
+
+// runTenant runs a tenant command and returns the exit code
+func runTenant(args []string, stdout io.Writer) int {
+	if args[0] != "import" {
+		fmt.Fprint(os.Stderr, usage)
+		return exitUsage
+	}
+
+	var opts importOptions
+	flags := flag.NewFlagSet("stegctl tenant import", flag.ContinueOnError)
+	flags.StringVar(&opts.file, "file", "", "Path of the tenant archive")
+	flags.StringVar(&opts.owner, "owner", "", "ID of the user owning the new tenant")
+	flags.StringVar(&opts.dto.Name, "name", "", "Tenant name")
+	flags.StringVar(&opts.dto.Slug, "slug", "", "Tenant slug")
+	flags.StringVar(&opts.dto.Email, "email", "", "Tenant email")
+	flags.StringVar(&opts.dto.Phone, "phone", "", "Tenant phone")
+	flags.StringVar(&opts.dto.Description, "description", "", "Tenant description")
+	flags.BoolVar(&opts.json, "json", false, "Print the result as JSON")
+	if err := flags.Parse(args[1:]); err != nil {
+		return exitUsage
+	}
+
+	if opts.file == "" {
+		fmt.Fprintln(os.Stderr, "import needs -file")
+		return exitUsage
+	}
+	if _, err := uuid.Parse(opts.owner); err != nil {
+		fmt.Fprintln(os.Stderr, "import needs the user ID of the owner in -owner")
+		return exitUsage
+	}
+
+	archive, err := os.Open(opts.file)
+	if err != nil {
+		log.Printf("❌ Failed to open archive: %v", err)
+		return exitFailed
+	}
+	defer archive.Close()
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Printf("❌ Failed to load configuration: %v", err)
+		return exitFailed
+	}
+	manager, err := database.NewManager(cfg)
+	if err != nil {
+		log.Printf("❌ Failed to initialize database manager: %v", err)
+		return exitFailed
+	}
+	defer manager.CloseAll()
+
+	service := newArchiveTenantService(cfg, manager)
+	result, err := service.ImportTenantArchive(context.Background(), &opts.dto, archive, opts.owner)
+	if err != nil {
+		log.Printf("❌ Failed to import archive: %v", err)
+		return exitFailed
+	}
+
+	if opts.json {
+		if err := writeJSON(stdout, result); err != nil {
+			log.Printf("❌ Failed to write result: %v", err)
+			return exitFailed
+		}
+		return exitOK
+	}
+
+	fmt.Fprintf(stdout, "Tenant %s (%s) imported into database %s: %d tables, %d rows, %d media files from tenant %s\n",
+		result.Slug, result.TenantID, result.DatabaseName,
+		len(result.Archive.Tables), result.Archive.Rows, result.Archive.Blobs, result.Archive.TenantSlug)
+	return exitOK
+}
+
+// newArchiveTenantService creates a tenant service with what archive imports need. Without
+// media storage, archives with media files can't be imported.
+func newArchiveTenantService(cfg *config.Config, manager *database.Manager) *tenantservices.TenantService {
+	var blobStorage tenantports.ArchiveBlobStorage
+	storageService, err := mediaadapters.NewMinioStorageService(
+		cfg.Storage.Endpoint,
+		cfg.Storage.AWSAccessKey,
+		cfg.Storage.AWSSecretKey,
+		cfg.Storage.AWSRegion,
+		cfg.Storage.BucketPrefix,
+		cfg.Storage.UseSSL,
+	)
+	if err != nil {
+		log.Printf("⚠️  Failed to initialize storage service: %v", err)
+	} else {
+		blobStorage = storageService
+	}
+
+	return tenantservices.NewTenantService(
+		tenantadapters.NewPostgresTenantRepository(manager.GetControlDB(), manager),
+		tenantadapters.NewPostgresTenantArchiveRepository(manager),
+		blobStorage,
+		nil,
+		manager,
+		database.NewMigrationRunner(manager),
+		nil,
+		nil,
+		tenantservices.MemberImportDependencies{},
+		cfg.Tenants.DeletionGracePeriod,
+	)
+}
@@ -28,10 +28,10 @@ func NewAssignmentController(assignmentService ports.AssignmentService) *Assignm
 // CreateAssignment creates a new assignment
 // POST /api/v1/assignments
 func (ctrl *AssignmentController) CreateAssignment(c *fiber.Ctx) error {
-	// Get user ID from context
-	userID, err := uuid.Parse(c.Locals("userID").(string))
+	// Get the user performing the action
+	actor, err := getCourseActorFromContext(c)
 	if err != nil {
-		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid user ID")
+		return err
 	}
 
 	// Get tenant ID from context
@@ -52,7 +52,7 @@ func (ctrl *AssignmentController) CreateAssignment(c *fiber.Ctx) error {
 	}
 
 	// Call service
-	assignment, err := ctrl.assignmentService.CreateAssignment(c.Context(), tenantID, userID, &req)
+	assignment, err := ctrl.assignmentService.CreateAssignment(c.Context(), tenantID, actor, &req)
 	if err != nil {
 		return HandleError(c, err)
 	}
@@ -163,10 +163,10 @@ func (ctrl *AssignmentController) UpdateAssignment(c *fiber.Ctx) error {
 		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid assignment ID")
 	}
 
-	// Get user ID from context
-	userID, err := uuid.Parse(c.Locals("userID").(string))
+	// Get the user performing the action
+	actor, err := getCourseActorFromContext(c)
 	if err != nil {
-		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid user ID")
+		return err
 	}
 
 	// Get tenant ID from context
@@ -187,7 +187,7 @@ func (ctrl *AssignmentController) UpdateAssignment(c *fiber.Ctx) error {
 	}
 
 	// Call service
-	assignment, err := ctrl.assignmentService.UpdateAssignment(c.Context(), assignmentID, tenantID, userID, &req)
+	assignment, err := ctrl.assignmentService.UpdateAssignment(c.Context(), assignmentID, tenantID, actor, &req)
 	if err != nil {
 		return HandleError(c, err)
 	}
@@ -204,10 +204,10 @@ func (ctrl *AssignmentController) DeleteAssignment(c *fiber.Ctx) error {
 		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid assignment ID")
 	}
 
-	// Get user ID from context
-	userID, err := uuid.Parse(c.Locals("userID").(string))
+	// Get the user performing the action
+	actor, err := getCourseActorFromContext(c)
 	if err != nil {
-		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid user ID")
+		return err
 	}
 
 	// Get tenant ID from context
@@ -217,7 +217,7 @@ func (ctrl *AssignmentController) DeleteAssignment(c *fiber.Ctx) error {
 	}
 
 	// Call service
-	if err := ctrl.assignmentService.DeleteAssignment(c.Context(), assignmentID, tenantID, userID); err != nil {
+	if err := ctrl.assignmentService.DeleteAssignment(c.Context(), assignmentID, tenantID, actor); err != nil {
 		return HandleError(c, err)
 	}
 
@@ -233,10 +233,10 @@ func (ctrl *AssignmentController) PublishAssignment(c *fiber.Ctx) error {
 		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid assignment ID")
 	}
 
-	// Get user ID from context
-	userID, err := uuid.Parse(c.Locals("userID").(string))
+	// Get the user performing the action
+	actor, err := getCourseActorFromContext(c)
 	if err != nil {
-		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid user ID")
+		return err
 	}
 
 	// Get tenant ID from context
@@ -246,7 +246,7 @@ func (ctrl *AssignmentController) PublishAssignment(c *fiber.Ctx) error {
 	}
 
 	// Call service
-	assignment, err := ctrl.assignmentService.PublishAssignment(c.Context(), assignmentID, tenantID, userID)
+	assignment, err := ctrl.assignmentService.PublishAssignment(c.Context(), assignmentID, tenantID, actor)
 	if err != nil {
 		return HandleError(c, err)
 	}
@@ -263,10 +263,10 @@ func (ctrl *AssignmentController) UnpublishAssignment(c *fiber.Ctx) error {
 		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid assignment ID")
 	}
 
-	// Get user ID from context
-	userID, err := uuid.Parse(c.Locals("userID").(string))
+	// Get the user performing the action
+	actor, err := getCourseActorFromContext(c)
 	if err != nil {
-		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid user ID")
+		return err
 	}
 
 	// Get tenant ID from context
@@ -276,7 +276,7 @@ func (ctrl *AssignmentController) UnpublishAssignment(c *fiber.Ctx) error {
 	}
 
 	// Call service
-	assignment, err := ctrl.assignmentService.UnpublishAssignment(c.Context(), assignmentID, tenantID, userID)
+	assignment, err := ctrl.assignmentService.UnpublishAssignment(c.Context(), assignmentID, tenantID, actor)
 	if err != nil {
 		return HandleError(c, err)
 	}
@@ -708,10 +708,10 @@ func (ctrl *AssignmentController) UploadAssignmentFile(c *fiber.Ctx) error {
 		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid assignment ID")
 	}
 
-	// Get user ID from context
-	userID, err := uuid.Parse(c.Locals("userID").(string))
+	// Get the user performing the action
+	actor, err := getCourseActorFromContext(c)
 	if err != nil {
-		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid user ID")
+		return err
 	}
 
 	// Get tenant ID from context
@@ -751,7 +751,7 @@ func (ctrl *AssignmentController) UploadAssignmentFile(c *fiber.Ctx) error {
 		c.Context(),
 		assignmentID,
 		tenantID,
-		userID,
+		actor,
 		filename,
 		file.Filename,
 		file.Header.Get("Content-Type"),
@@ -905,10 +905,10 @@ func (ctrl *AssignmentController) DeleteAssignmentFile(c *fiber.Ctx) error {
 		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid file ID")
 	}
 
-	// Get user ID from context
-	userID, err := uuid.Parse(c.Locals("userID").(string))
+	// Get the user performing the action
+	actor, err := getCourseActorFromContext(c)
 	if err != nil {
-		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid user ID")
+		return err
 	}
 
 	// Get tenant ID from context
@@ -918,7 +918,7 @@ func (ctrl *AssignmentController) DeleteAssignmentFile(c *fiber.Ctx) error {
 	}
 
 	// Call service
-	if err := ctrl.assignmentService.DeleteAssignmentFile(c.Context(), assignmentID, fileID, tenantID, userID); err != nil {
+	if err := ctrl.assignmentService.DeleteAssignmentFile(c.Context(), assignmentID, fileID, tenantID, actor); err != nil {
 		return HandleError(c, err)
 	}
 
@@ -1308,10 +1308,10 @@ func (ctrl *AssignmentController) AttachRubricToAssignment(c *fiber.Ctx) error {
 		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid rubric ID")
 	}
 
-	// Get user ID from context
-	userID, err := uuid.Parse(c.Locals("userID").(string))
+	// Get the user performing the action
+	actor, err := getCourseActorFromContext(c)
 	if err != nil {
-		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid user ID")
+		return err
 	}
 
 	// Get tenant ID from context
@@ -1321,7 +1321,7 @@ func (ctrl *AssignmentController) AttachRubricToAssignment(c *fiber.Ctx) error {
 	}
 
 	// Call service
-	if err := ctrl.assignmentService.AttachRubricToAssignment(c.Context(), assignmentID, rubricID, tenantID, userID); err != nil {
+	if err := ctrl.assignmentService.AttachRubricToAssignment(c.Context(), assignmentID, rubricID, tenantID, actor); err != nil {
 		return HandleError(c, err)
 	}
 
@@ -1337,10 +1337,10 @@ func (ctrl *AssignmentController) DetachRubricFromAssignment(c *fiber.Ctx) error
 		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid assignment ID")
 	}
 
-	// Get user ID from context
-	userID, err := uuid.Parse(c.Locals("userID").(string))
+	// Get the user performing the action
+	actor, err := getCourseActorFromContext(c)
 	if err != nil {
-		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid user ID")
+		return err
 	}
 
 	// Get tenant ID from context
@@ -1350,7 +1350,7 @@ func (ctrl *AssignmentController) DetachRubricFromAssignment(c *fiber.Ctx) error
 	}
 
 	// Call service
-	if err := ctrl.assignmentService.DetachRubricFromAssignment(c.Context(), assignmentID, tenantID, userID); err != nil {
+	if err := ctrl.assignmentService.DetachRubricFromAssignment(c.Context(), assignmentID, tenantID, actor); err != nil {
 		return HandleError(c, err)
 	}
 
@@ -30,6 +30,7 @@ func (ctrl *AuthController) Register(c *fiber.Ctx) error {
 		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
 	}
 	log.Printf("✅ Body parsed successfully: %s\n", dto.Email)
+	dto.SessionMetadata = sessionMetadata(c)
 
 	// Get tenant ID from context (set by tenant middleware)
 	// For public registration without tenant, we'll use empty string
@@ -61,6 +62,7 @@ func (ctrl *AuthController) Login(c *fiber.Ctx) error {
 	if err := c.BodyParser(&dto); err != nil {
 		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
 	}
+	dto.SessionMetadata = sessionMetadata(c)
 
 	// Get tenant ID from context (may be empty for users without tenant)
 	tenantID := ""
@@ -106,6 +108,7 @@ func (ctrl *AuthController) RefreshToken(c *fiber.Ctx) error {
 	if err := c.BodyParser(&dto); err != nil {
 		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
 	}
+	dto.SessionMetadata = sessionMetadata(c)
 
 	// Call service using Fiber's context
 	response, err := ctrl.authService.RefreshAccessToken(c.Context(), &dto)
@@ -157,6 +160,7 @@ func (ctrl *AuthController) ForgotPassword(c *fiber.Ctx) error {
 	if err := c.BodyParser(&dto); err != nil {
 		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
 	}
+	dto.SessionMetadata = sessionMetadata(c)
 
 	// Get tenant ID from context
 	tenantID := c.Locals("tenant_id").(string)
@@ -188,6 +192,81 @@ func (ctrl *AuthController) ResetPassword(c *fiber.Ctx) error {
 	return SuccessResponse(c, fiber.StatusOK, "Password reset successfully", nil)
 }
 
+// UnlockAccount handles lifting a lockout with the link from the account locked email
+// POST /api/v1/auth/unlock-account
+func (ctrl *AuthController) UnlockAccount(c *fiber.Ctx) error {
+	var dto domain.UnlockAccountDTO
+	if err := c.BodyParser(&dto); err != nil {
+		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	// Call service using Fiber's context
+	if err := ctrl.authService.UnlockAccount(c.Context(), &dto); err != nil {
+		return HandleError(c, err)
+	}
+
+	return SuccessResponse(c, fiber.StatusOK, "Account unlocked successfully", nil)
+}
+
+// RequestMagicLink handles a request for a passwordless login link
+// POST /api/v1/auth/magic-link
+func (ctrl *AuthController) RequestMagicLink(c *fiber.Ctx) error {
+	var dto domain.MagicLinkRequestDTO
+	if err := c.BodyParser(&dto); err != nil {
+		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+	dto.SessionMetadata = sessionMetadata(c)
+
+	// Get tenant ID from context (magic links are enabled per tenant)
+	tenantID := ""
+	if tid := c.Locals("tenant_id"); tid != nil {
+		if tidStr, ok := tid.(string); ok {
+			tenantID = tidStr
+		}
+	}
+
+	// Call service using Fiber's context
+	if err := ctrl.authService.RequestMagicLink(c.Context(), tenantID, &dto); err != nil {
+		return HandleError(c, err)
+	}
+
+	// Always return success for security (don't reveal if email exists)
+	return SuccessResponse(c, fiber.StatusOK, "If the email exists, a login link has been sent", nil)
+}
+
+// LoginWithMagicLink handles login with the token of a link sent by email
+// POST /api/v1/auth/magic-link/verify
+func (ctrl *AuthController) LoginWithMagicLink(c *fiber.Ctx) error {
+	var dto domain.MagicLinkLoginDTO
+	if err := c.BodyParser(&dto); err != nil {
+		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+	dto.SessionMetadata = sessionMetadata(c)
+
+	// Call service using Fiber's context
+	response, err := ctrl.authService.LoginWithMagicLink(c.Context(), &dto)
+	if err != nil {
+		return HandleError(c, err)
+	}
+
+	return SuccessResponse(c, fiber.StatusOK, "Login successful", response)
+}
+
+// UnlockUser handles an admin lifting the lockout of a tenant member
+// POST /api/v1/admin/users/:id/unlock
+func (ctrl *AuthController) UnlockUser(c *fiber.Ctx) error {
+	// Get tenant ID from context (set by tenant middleware)
+	tenantID := c.Locals("tenant_id").(string)
+	userID := c.Params("id")
+
+	// Call service using Fiber's context
+	if err := ctrl.authService.UnlockUser(c.Context(), tenantID, userID); err != nil {
+		return HandleError(c, err)
+	}
+
+	return SuccessResponse(c, fiber.StatusOK, "User unlocked successfully", nil)
+}
+
 // ChangePassword handles password change for authenticated users
 // POST /api/v1/auth/change-password
 func (ctrl *AuthController) ChangePassword(c *fiber.Ctx) error {
@@ -258,6 +337,96 @@ func (ctrl *AuthController) RevokeAllSessions(c *fiber.Ctx) error {
 	return SuccessResponse(c, fiber.StatusOK, "All sessions revoked successfully", nil)
 }
 
+// ListSessions handles listing the active sessions of the current user
+// GET /api/v1/auth/sessions
+func (ctrl *AuthController) ListSessions(c *fiber.Ctx) error {
+	// Get user ID from context (set by auth middleware)
+	userID := c.Locals("userID").(string)
+
+	// Call service using Fiber's context
+	sessions, err := ctrl.authService.ListSessions(c.Context(), userID)
+	if err != nil {
+		return HandleError(c, err)
+	}
+
+	return SuccessResponse(c, fiber.StatusOK, "Sessions retrieved successfully", sessions)
+}
+
+// RevokeSession handles revoking a single session (device)
+// DELETE /api/v1/auth/sessions/:id
+func (ctrl *AuthController) RevokeSession(c *fiber.Ctx) error {
+	// Get user ID from context (set by auth middleware)
+	userID := c.Locals("userID").(string)
+	sessionID := c.Params("id")
+
+	// Call service using Fiber's context
+	err := ctrl.authService.RevokeSession(c.Context(), userID, sessionID)
+	if err != nil {
+		return HandleError(c, err)
+	}
+
+	return SuccessResponse(c, fiber.StatusOK, "Session revoked successfully", nil)
+}
+
+// ListPersonalAccessTokens handles listing the active personal access tokens of the current user
+// GET /api/v1/auth/tokens
+func (ctrl *AuthController) ListPersonalAccessTokens(c *fiber.Ctx) error {
+	// Get user ID from context (set by auth middleware)
+	userID := c.Locals("userID").(string)
+
+	// Call service using Fiber's context
+	tokens, err := ctrl.authService.ListPersonalAccessTokens(c.Context(), userID)
+	if err != nil {
+		return HandleError(c, err)
+	}
+
+	return SuccessResponse(c, fiber.StatusOK, "Personal access tokens retrieved successfully", tokens)
+}
+
+// CreatePersonalAccessToken handles creating a scoped personal access token in the current tenant.
+// The plaintext token is only included in this response.
+// POST /api/v1/auth/tokens
+func (ctrl *AuthController) CreatePersonalAccessToken(c *fiber.Ctx) error {
+	var dto domain.CreateAPITokenDTO
+	if err := c.BodyParser(&dto); err != nil {
+		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID := c.Locals("userID").(string)
+
+	// Get tenant ID from context
+	tenantID := ""
+	if tid := c.Locals("tenant_id"); tid != nil {
+		if tidStr, ok := tid.(string); ok {
+			tenantID = tidStr
+		}
+	}
+
+	// Call service using Fiber's context
+	response, err := ctrl.authService.CreatePersonalAccessToken(c.Context(), userID, tenantID, &dto)
+	if err != nil {
+		return HandleError(c, err)
+	}
+
+	return SuccessResponse(c, fiber.StatusCreated, "Personal access token created successfully", response)
+}
+
+// RevokePersonalAccessToken handles revoking one of the current user's personal access tokens
+// DELETE /api/v1/auth/tokens/:id
+func (ctrl *AuthController) RevokePersonalAccessToken(c *fiber.Ctx) error {
+	// Get user ID from context (set by auth middleware)
+	userID := c.Locals("userID").(string)
+	tokenID := c.Params("id")
+
+	// Call service using Fiber's context
+	if err := ctrl.authService.RevokePersonalAccessToken(c.Context(), userID, tokenID); err != nil {
+		return HandleError(c, err)
+	}
+
+	return SuccessResponse(c, fiber.StatusOK, "Personal access token revoked successfully", nil)
+}
+
 // SwitchRole handles switching between user's assigned roles
 // POST /api/v1/auth/switch-role
 func (ctrl *AuthController) SwitchRole(c *fiber.Ctx) error {
@@ -285,3 +454,878 @@ func (ctrl *AuthController) SwitchRole(c *fiber.Ctx) error {
 
 	return SuccessResponse(c, fiber.StatusOK, "Role switched successfully", response)
 }
+
+// VerifyMFALogin handles the second login step for users with MFA
+// POST /api/v1/auth/mfa/verify
+func (ctrl *AuthController) VerifyMFALogin(c *fiber.Ctx) error {
+	var dto domain.MFALoginDTO
+	if err := c.BodyParser(&dto); err != nil {
+		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+	dto.SessionMetadata = sessionMetadata(c)
+
+	// Call service using Fiber's context
+	response, err := ctrl.authService.VerifyMFALogin(c.Context(), &dto)
+	if err != nil {
+		return HandleError(c, err)
+	}
+
+	return SuccessResponse(c, fiber.StatusOK, "Login successful", response)
+}
+
+// EnrollMFAWithChallenge handles MFA enrollment required by tenant policy during login
+// POST /api/v1/auth/mfa/challenge/enroll
+func (ctrl *AuthController) EnrollMFAWithChallenge(c *fiber.Ctx) error {
+	var dto domain.MFAChallengeDTO
+	if err := c.BodyParser(&dto); err != nil {
+		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	// Call service using Fiber's context
+	response, err := ctrl.authService.EnrollMFAWithChallenge(c.Context(), &dto)
+	if err != nil {
+		return HandleError(c, err)
+	}
+
+	return SuccessResponse(c, fiber.StatusOK, "MFA enrollment started", response)
+}
+
+// GetMFAStatus handles getting the MFA status of the current user
+// GET /api/v1/auth/mfa
+func (ctrl *AuthController) GetMFAStatus(c *fiber.Ctx) error {
+	// Get user ID from context (set by auth middleware)
+	userID := c.Locals("userID").(string)
+
+	// Get tenant ID from context
+	tenantID := ""
+	if tid := c.Locals("tenant_id"); tid != nil {
+		if tidStr, ok := tid.(string); ok {
+			tenantID = tidStr
+		}
+	}
+
+	// Call service using Fiber's context
+	status, err := ctrl.authService.GetMFAStatus(c.Context(), userID, tenantID)
+	if err != nil {
+		return HandleError(c, err)
+	}
+
+	return SuccessResponse(c, fiber.StatusOK, "MFA status retrieved successfully", status)
+}
+
+// EnrollMFA handles starting a TOTP enrollment
+// POST /api/v1/auth/mfa/enroll
+func (ctrl *AuthController) EnrollMFA(c *fiber.Ctx) error {
+	// Get user ID from context (set by auth middleware)
+	userID := c.Locals("userID").(string)
+
+	// Call service using Fiber's context
+	response, err := ctrl.authService.EnrollMFA(c.Context(), userID)
+	if err != nil {
+		return HandleError(c, err)
+	}
+
+	return SuccessResponse(c, fiber.StatusOK, "MFA enrollment started", response)
+}
+
+// EnableMFA handles confirming a TOTP enrollment
+// POST /api/v1/auth/mfa/enable
+func (ctrl *AuthController) EnableMFA(c *fiber.Ctx) error {
+	var dto domain.MFACodeDTO
+	if err := c.BodyParser(&dto); err != nil {
+		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID := c.Locals("userID").(string)
+
+	// Call service using Fiber's context
+	response, err := ctrl.authService.EnableMFA(c.Context(), userID, &dto)
+	if err != nil {
+		return HandleError(c, err)
+	}
+
+	return SuccessResponse(c, fiber.StatusOK, "MFA enabled successfully", response)
+}
+
+// DisableMFA handles removing the TOTP enrollment
+// POST /api/v1/auth/mfa/disable
+func (ctrl *AuthController) DisableMFA(c *fiber.Ctx) error {
+	var dto domain.MFACodeDTO
+	if err := c.BodyParser(&dto); err != nil {
+		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID := c.Locals("userID").(string)
+
+	// Call service using Fiber's context
+	err := ctrl.authService.DisableMFA(c.Context(), userID, &dto)
+	if err != nil {
+		return HandleError(c, err)
+	}
+
+	return SuccessResponse(c, fiber.StatusOK, "MFA disabled successfully", nil)
+}
+
+// RegenerateRecoveryCodes handles generating a new set of MFA recovery codes
+// POST /api/v1/auth/mfa/recovery-codes
+func (ctrl *AuthController) RegenerateRecoveryCodes(c *fiber.Ctx) error {
+	var dto domain.MFACodeDTO
+	if err := c.BodyParser(&dto); err != nil {
+		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID := c.Locals("userID").(string)
+
+	// Call service using Fiber's context
+	response, err := ctrl.authService.RegenerateRecoveryCodes(c.Context(), userID, &dto)
+	if err != nil {
+		return HandleError(c, err)
+	}
+
+	return SuccessResponse(c, fiber.StatusOK, "Recovery codes regenerated successfully", response)
+}
+
+// GetMFAPolicy handles getting the tenant MFA policy
+// GET /api/v1/admin/security/mfa-policy
+func (ctrl *AuthController) GetMFAPolicy(c *fiber.Ctx) error {
+	// Get tenant ID from context (set by tenant middleware)
+	tenantID := c.Locals("tenant_id").(string)
+
+	// Call service using Fiber's context
+	policy, err := ctrl.authService.GetMFAPolicy(c.Context(), tenantID)
+	if err != nil {
+		return HandleError(c, err)
+	}
+
+	return SuccessResponse(c, fiber.StatusOK, "MFA policy retrieved successfully", policy)
+}
+
+// UpdateMFAPolicy handles updating the tenant MFA policy
+// PUT /api/v1/admin/security/mfa-policy
+func (ctrl *AuthController) UpdateMFAPolicy(c *fiber.Ctx) error {
+	var dto domain.MFAPolicyDTO
+	if err := c.BodyParser(&dto); err != nil {
+		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	// Get tenant ID from context (set by tenant middleware)
+	tenantID := c.Locals("tenant_id").(string)
+
+	// Call service using Fiber's context
+	policy, err := ctrl.authService.UpdateMFAPolicy(c.Context(), tenantID, &dto)
+	if err != nil {
+		return HandleError(c, err)
+	}
+
+	return SuccessResponse(c, fiber.StatusOK, "MFA policy updated successfully", policy)
+}
+
+// GetMagicLinkSettings handles getting whether the tenant allows magic link login
+// GET /api/v1/admin/security/magic-link
+func (ctrl *AuthController) GetMagicLinkSettings(c *fiber.Ctx) error {
+	// Get tenant ID from context (set by tenant middleware)
+	tenantID := c.Locals("tenant_id").(string)
+
+	// Call service using Fiber's context
+	settings, err := ctrl.authService.GetMagicLinkSettings(c.Context(), tenantID)
+	if err != nil {
+		return HandleError(c, err)
+	}
+
+	return SuccessResponse(c, fiber.StatusOK, "Magic link settings retrieved successfully", settings)
+}
+
+// UpdateMagicLinkSettings handles enabling or disabling magic link login in the tenant
+// PUT /api/v1/admin/security/magic-link
+func (ctrl *AuthController) UpdateMagicLinkSettings(c *fiber.Ctx) error {
+	var dto domain.MagicLinkSettingsDTO
+	if err := c.BodyParser(&dto); err != nil {
+		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	// Get tenant ID from context (set by tenant middleware)
+	tenantID := c.Locals("tenant_id").(string)
+
+	// Call service using Fiber's context
+	settings, err := ctrl.authService.UpdateMagicLinkSettings(c.Context(), tenantID, &dto)
+	if err != nil {
+		return HandleError(c, err)
+	}
+
+	return SuccessResponse(c, fiber.StatusOK, "Magic link settings updated successfully", settings)
+}
+
+// GetPasswordRequirements handles getting the password policy that applies to new passwords,
+// so that registration and password forms can show the requirements
+// GET /api/v1/auth/password-policy
+func (ctrl *AuthController) GetPasswordRequirements(c *fiber.Ctx) error {
+	// Get tenant ID from context (default policy without a tenant)
+	tenantID := ""
+	if tid := c.Locals("tenant_id"); tid != nil {
+		if tidStr, ok := tid.(string); ok {
+			tenantID = tidStr
+		}
+	}
+
+	// Call service using Fiber's context
+	policy, err := ctrl.authService.GetPasswordPolicy(c.Context(), tenantID)
+	if err != nil {
+		return HandleError(c, err)
+	}
+
+	return SuccessResponse(c, fiber.StatusOK, "Password policy retrieved successfully", policy)
+}
+
+// GetPasswordPolicy handles getting the tenant password policy
+// GET /api/v1/admin/security/password-policy
+func (ctrl *AuthController) GetPasswordPolicy(c *fiber.Ctx) error {
+	// Get tenant ID from context (set by tenant middleware)
+	tenantID := c.Locals("tenant_id").(string)
+
+	// Call service using Fiber's context
+	policy, err := ctrl.authService.GetPasswordPolicy(c.Context(), tenantID)
+	if err != nil {
+		return HandleError(c, err)
+	}
+
+	return SuccessResponse(c, fiber.StatusOK, "Password policy retrieved successfully", policy)
+}
+
+// UpdatePasswordPolicy handles updating the tenant password policy
+// PUT /api/v1/admin/security/password-policy
+func (ctrl *AuthController) UpdatePasswordPolicy(c *fiber.Ctx) error {
+	var dto domain.PasswordPolicyDTO
+	if err := c.BodyParser(&dto); err != nil {
+		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	// Get tenant ID from context (set by tenant middleware)
+	tenantID := c.Locals("tenant_id").(string)
+
+	// Call service using Fiber's context
+	policy, err := ctrl.authService.UpdatePasswordPolicy(c.Context(), tenantID, &dto)
+	if err != nil {
+		return HandleError(c, err)
+	}
+
+	return SuccessResponse(c, fiber.StatusOK, "Password policy updated successfully", policy)
+}
+
+// BeginWebAuthnRegistration handles starting the registration of a passkey
+// POST /api/v1/auth/webauthn/register/begin
+func (ctrl *AuthController) BeginWebAuthnRegistration(c *fiber.Ctx) error {
+	// Get user ID from context (set by auth middleware)
+	userID := c.Locals("userID").(string)
+
+	// Call service using Fiber's context
+	response, err := ctrl.authService.BeginWebAuthnRegistration(c.Context(), userID)
+	if err != nil {
+		return HandleError(c, err)
+	}
+
+	return SuccessResponse(c, fiber.StatusOK, "Passkey registration started", response)
+}
+
+// FinishWebAuthnRegistration handles storing the passkey created by the browser
+// POST /api/v1/auth/webauthn/register/finish
+func (ctrl *AuthController) FinishWebAuthnRegistration(c *fiber.Ctx) error {
+	// Get user ID from context (set by auth middleware)
+	userID := c.Locals("userID").(string)
+
+	var dto domain.WebAuthnRegistrationDTO
+	if err := c.BodyParser(&dto); err != nil {
+		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	// Call service using Fiber's context
+	credential, err := ctrl.authService.FinishWebAuthnRegistration(c.Context(), userID, &dto)
+	if err != nil {
+		return HandleError(c, err)
+	}
+
+	return SuccessResponse(c, fiber.StatusCreated, "Passkey registered successfully", credential)
+}
+
+// ListWebAuthnCredentials handles listing the passkeys of the current user
+// GET /api/v1/auth/webauthn/credentials
+func (ctrl *AuthController) ListWebAuthnCredentials(c *fiber.Ctx) error {
+	// Get user ID from context (set by auth middleware)
+	userID := c.Locals("userID").(string)
+
+	// Call service using Fiber's context
+	credentials, err := ctrl.authService.ListWebAuthnCredentials(c.Context(), userID)
+	if err != nil {
+		return HandleError(c, err)
+	}
+
+	return SuccessResponse(c, fiber.StatusOK, "Passkeys retrieved successfully", credentials)
+}
+
+// DeleteWebAuthnCredential handles removing one of the current user's passkeys
+// DELETE /api/v1/auth/webauthn/credentials/:id
+func (ctrl *AuthController) DeleteWebAuthnCredential(c *fiber.Ctx) error {
+	// Get user ID from context (set by auth middleware)
+	userID := c.Locals("userID").(string)
+	credentialID := c.Params("id")
+
+	// Call service using Fiber's context
+	if err := ctrl.authService.DeleteWebAuthnCredential(c.Context(), userID, credentialID); err != nil {
+		return HandleError(c, err)
+	}
+
+	return SuccessResponse(c, fiber.StatusOK, "Passkey deleted successfully", nil)
+}
+
+// BeginWebAuthnLogin handles starting a passwordless login with a passkey
+// POST /api/v1/auth/webauthn/login/begin
+func (ctrl *AuthController) BeginWebAuthnLogin(c *fiber.Ctx) error {
+	var dto domain.WebAuthnLoginBeginDTO
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&dto); err != nil {
+			return ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
+		}
+	}
+
+	// Get tenant ID from context (may be empty for users without tenant)
+	tenantID := ""
+	if tid := c.Locals("tenant_id"); tid != nil {
+		if tidStr, ok := tid.(string); ok {
+			tenantID = tidStr
+		}
+	}
+
+	// Call service using Fiber's context
+	response, err := ctrl.authService.BeginWebAuthnLogin(c.Context(), tenantID, &dto)
+	if err != nil {
+		return HandleError(c, err)
+	}
+
+	return SuccessResponse(c, fiber.StatusOK, "Passkey login started", response)
+}
+
+// FinishWebAuthnLogin handles completing a passwordless login with a passkey
+// POST /api/v1/auth/webauthn/login/finish
+func (ctrl *AuthController) FinishWebAuthnLogin(c *fiber.Ctx) error {
+	var dto domain.WebAuthnLoginDTO
+	if err := c.BodyParser(&dto); err != nil {
+		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+	dto.SessionMetadata = sessionMetadata(c)
+
+	// Get tenant ID from context (may be empty for users without tenant)
+	tenantID := ""
+	if tid := c.Locals("tenant_id"); tid != nil {
+		if tidStr, ok := tid.(string); ok {
+			tenantID = tidStr
+		}
+	}
+
+	// Call service using Fiber's context
+	response, err := ctrl.authService.FinishWebAuthnLogin(c.Context(), tenantID, &dto)
+	if err != nil {
+		return HandleError(c, err)
+	}
+
+	return SuccessResponse(c, fiber.StatusOK, "Login successful", response)
+}
+
+// BeginWebAuthnMFA handles starting the second login step with a passkey or security key
+// POST /api/v1/auth/webauthn/mfa/begin
+func (ctrl *AuthController) BeginWebAuthnMFA(c *fiber.Ctx) error {
+	var dto domain.MFAChallengeDTO
+	if err := c.BodyParser(&dto); err != nil {
+		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	// Call service using Fiber's context
+	response, err := ctrl.authService.BeginWebAuthnMFA(c.Context(), &dto)
+	if err != nil {
+		return HandleError(c, err)
+	}
+
+	return SuccessResponse(c, fiber.StatusOK, "Passkey verification started", response)
+}
+
+// FinishWebAuthnMFA handles completing the second login step with a passkey or security key
+// POST /api/v1/auth/webauthn/mfa/finish
+func (ctrl *AuthController) FinishWebAuthnMFA(c *fiber.Ctx) error {
+	var dto domain.WebAuthnMFALoginDTO
+	if err := c.BodyParser(&dto); err != nil {
+		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+	dto.SessionMetadata = sessionMetadata(c)
+
+	// Call service using Fiber's context
+	response, err := ctrl.authService.FinishWebAuthnMFA(c.Context(), &dto)
+	if err != nil {
+		return HandleError(c, err)
+	}
+
+	return SuccessResponse(c, fiber.StatusOK, "Login successful", response)
+}
+
+// BeginOIDCLogin handles starting a single sign-on login with the tenant identity provider
+// GET /api/v1/auth/oidc/authorize
+func (ctrl *AuthController) BeginOIDCLogin(c *fiber.Ctx) error {
+	// Get tenant ID from context (the login page must identify the tenant)
+	tenantID := ""
+	if tid := c.Locals("tenant_id"); tid != nil {
+		if tidStr, ok := tid.(string); ok {
+			tenantID = tidStr
+		}
+	}
+
+	// Call service using Fiber's context
+	response, err := ctrl.authService.BeginOIDCLogin(c.Context(), tenantID)
+	if err != nil {
+		return HandleError(c, err)
+	}
+
+	return SuccessResponse(c, fiber.StatusOK, "Authorization URL created successfully", response)
+}
+
+// CompleteOIDCLogin handles the identity provider callback parameters
+// POST /api/v1/auth/oidc/callback
+func (ctrl *AuthController) CompleteOIDCLogin(c *fiber.Ctx) error {
+	var dto domain.OIDCCallbackDTO
+	if err := c.BodyParser(&dto); err != nil {
+		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+	dto.SessionMetadata = sessionMetadata(c)
+
+	// Call service using Fiber's context
+	response, err := ctrl.authService.CompleteOIDCLogin(c.Context(), &dto)
+	if err != nil {
+		return HandleError(c, err)
+	}
+
+	return SuccessResponse(c, fiber.StatusOK, "Login successful", response)
+}
+
+// GetOIDCProvider handles getting the tenant identity provider configuration
+// GET /api/v1/admin/security/oidc
+func (ctrl *AuthController) GetOIDCProvider(c *fiber.Ctx) error {
+	// Get tenant ID from context (set by tenant middleware)
+	tenantID := c.Locals("tenant_id").(string)
+
+	// Call service using Fiber's context
+	provider, err := ctrl.authService.GetOIDCProvider(c.Context(), tenantID)
+	if err != nil {
+		return HandleError(c, err)
+	}
+
+	return SuccessResponse(c, fiber.StatusOK, "OIDC provider retrieved successfully", provider)
+}
+
+// UpdateOIDCProvider handles creating or updating the tenant identity provider configuration
+// PUT /api/v1/admin/security/oidc
+func (ctrl *AuthController) UpdateOIDCProvider(c *fiber.Ctx) error {
+	var dto domain.OIDCProviderDTO
+	if err := c.BodyParser(&dto); err != nil {
+		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	// Get tenant ID from context (set by tenant middleware)
+	tenantID := c.Locals("tenant_id").(string)
+
+	// Call service using Fiber's context
+	provider, err := ctrl.authService.UpdateOIDCProvider(c.Context(), tenantID, &dto)
+	if err != nil {
+		return HandleError(c, err)
+	}
+
+	return SuccessResponse(c, fiber.StatusOK, "OIDC provider updated successfully", provider)
+}
+
+// DeleteOIDCProvider handles removing the tenant identity provider configuration
+// DELETE /api/v1/admin/security/oidc
+func (ctrl *AuthController) DeleteOIDCProvider(c *fiber.Ctx) error {
+	// Get tenant ID from context (set by tenant middleware)
+	tenantID := c.Locals("tenant_id").(string)
+
+	// Call service using Fiber's context
+	if err := ctrl.authService.DeleteOIDCProvider(c.Context(), tenantID); err != nil {
+		return HandleError(c, err)
+	}
+
+	return SuccessResponse(c, fiber.StatusOK, "OIDC provider deleted successfully", nil)
+}
+
+// GetSAMLMetadata handles serving the SAML service provider metadata of a tenant
+// GET /api/v1/auth/saml/:tenantId/metadata
+func (ctrl *AuthController) GetSAMLMetadata(c *fiber.Ctx) error {
+	// Call service using Fiber's context
+	metadata, err := ctrl.authService.GetSAMLMetadata(c.Context(), c.Params("tenantId"))
+	if err != nil {
+		return HandleError(c, err)
+	}
+
+	c.Set(fiber.HeaderContentType, "application/samlmetadata+xml")
+	return c.Send(metadata)
+}
+
+// BeginSAMLLogin handles starting a SAML single sign-on login with the tenant identity provider
+// GET /api/v1/auth/saml/authorize
+func (ctrl *AuthController) BeginSAMLLogin(c *fiber.Ctx) error {
+	// Get tenant ID from context (the login page must identify the tenant)
+	tenantID := ""
+	if tid := c.Locals("tenant_id"); tid != nil {
+		if tidStr, ok := tid.(string); ok {
+			tenantID = tidStr
+		}
+	}
+
+	// Call service using Fiber's context
+	response, err := ctrl.authService.BeginSAMLLogin(c.Context(), tenantID)
+	if err != nil {
+		return HandleError(c, err)
+	}
+
+	return SuccessResponse(c, fiber.StatusOK, "Authorization URL created successfully", response)
+}
+
+// SAMLAssertionConsumer handles the response the identity provider posts through the browser.
+// The browser is redirected to the frontend with a one-time login code or an error code.
+// POST /api/v1/auth/saml/:tenantId/acs
+func (ctrl *AuthController) SAMLAssertionConsumer(c *fiber.Ctx) error {
+	var dto domain.SAMLResponseDTO
+	if err := c.BodyParser(&dto); err != nil {
+		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+	dto.TenantID = c.Params("tenantId")
+
+	// Call service using Fiber's context
+	redirectURL := ctrl.authService.CompleteSAMLLogin(c.Context(), &dto)
+
+	return c.Redirect(redirectURL, fiber.StatusSeeOther)
+}
+
+// ExchangeSAMLLoginCode handles exchanging the one-time code of a SAML login for tokens
+// POST /api/v1/auth/saml/exchange
+func (ctrl *AuthController) ExchangeSAMLLoginCode(c *fiber.Ctx) error {
+	var dto domain.SAMLExchangeDTO
+	if err := c.BodyParser(&dto); err != nil {
+		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+	dto.SessionMetadata = sessionMetadata(c)
+
+	// Call service using Fiber's context
+	response, err := ctrl.authService.ExchangeSAMLLoginCode(c.Context(), &dto)
+	if err != nil {
+		return HandleError(c, err)
+	}
+
+	return SuccessResponse(c, fiber.StatusOK, "Login successful", response)
+}
+
+// ConfirmFederatedLink handles confirming the link of a single sign-on login to an existing
+// account with the account's password or second factor
+// POST /api/v1/auth/federated/link
+func (ctrl *AuthController) ConfirmFederatedLink(c *fiber.Ctx) error {
+	var dto domain.FederatedLinkDTO
+	if err := c.BodyParser(&dto); err != nil {
+		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+	dto.SessionMetadata = sessionMetadata(c)
+
+	// Call service using Fiber's context
+	response, err := ctrl.authService.ConfirmFederatedLink(c.Context(), &dto)
+	if err != nil {
+		return HandleError(c, err)
+	}
+
+	return SuccessResponse(c, fiber.StatusOK, "Login successful", response)
+}
+
+// GetSAMLProvider handles getting the tenant SAML identity provider configuration
+// GET /api/v1/admin/security/saml
+func (ctrl *AuthController) GetSAMLProvider(c *fiber.Ctx) error {
+	// Get tenant ID from context (set by tenant middleware)
+	tenantID := c.Locals("tenant_id").(string)
+
+	// Call service using Fiber's context
+	provider, err := ctrl.authService.GetSAMLProvider(c.Context(), tenantID)
+	if err != nil {
+		return HandleError(c, err)
+	}
+
+	return SuccessResponse(c, fiber.StatusOK, "SAML provider retrieved successfully", provider)
+}
+
+// UpdateSAMLProvider handles creating or updating the tenant SAML identity provider configuration
+// PUT /api/v1/admin/security/saml
+func (ctrl *AuthController) UpdateSAMLProvider(c *fiber.Ctx) error {
+	var dto domain.SAMLProviderDTO
+	if err := c.BodyParser(&dto); err != nil {
+		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	// Get tenant ID from context (set by tenant middleware)
+	tenantID := c.Locals("tenant_id").(string)
+
+	// Call service using Fiber's context
+	provider, err := ctrl.authService.UpdateSAMLProvider(c.Context(), tenantID, &dto)
+	if err != nil {
+		return HandleError(c, err)
+	}
+
+	return SuccessResponse(c, fiber.StatusOK, "SAML provider updated successfully", provider)
+}
+
+// DeleteSAMLProvider handles removing the tenant SAML identity provider configuration
+// DELETE /api/v1/admin/security/saml
+func (ctrl *AuthController) DeleteSAMLProvider(c *fiber.Ctx) error {
+	// Get tenant ID from context (set by tenant middleware)
+	tenantID := c.Locals("tenant_id").(string)
+
+	// Call service using Fiber's context
+	if err := ctrl.authService.DeleteSAMLProvider(c.Context(), tenantID); err != nil {
+		return HandleError(c, err)
+	}
+
+	return SuccessResponse(c, fiber.StatusOK, "SAML provider deleted successfully", nil)
+}
+
+// ListTenantAPIKeys handles listing the active API keys of the tenant
+// GET /api/v1/admin/security/api-keys
+func (ctrl *AuthController) ListTenantAPIKeys(c *fiber.Ctx) error {
+	// Get tenant ID from context (set by tenant middleware)
+	tenantID := c.Locals("tenant_id").(string)
+
+	// Call service using Fiber's context
+	keys, err := ctrl.authService.ListTenantAPIKeys(c.Context(), tenantID)
+	if err != nil {
+		return HandleError(c, err)
+	}
+
+	return SuccessResponse(c, fiber.StatusOK, "API keys retrieved successfully", keys)
+}
+
+// CreateTenantAPIKey handles creating a scoped API key for integrations of the tenant.
+// The plaintext key is only included in this response.
+// POST /api/v1/admin/security/api-keys
+func (ctrl *AuthController) CreateTenantAPIKey(c *fiber.Ctx) error {
+	var dto domain.CreateAPITokenDTO
+	if err := c.BodyParser(&dto); err != nil {
+		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	// Get tenant ID from context (set by tenant middleware)
+	tenantID := c.Locals("tenant_id").(string)
+	// Get admin ID from context (set by auth middleware)
+	adminID := c.Locals("userID").(string)
+
+	// Call service using Fiber's context
+	response, err := ctrl.authService.CreateTenantAPIKey(c.Context(), tenantID, adminID, &dto)
+	if err != nil {
+		return HandleError(c, err)
+	}
+
+	return SuccessResponse(c, fiber.StatusCreated, "API key created successfully", response)
+}
+
+// RevokeTenantAPIKey handles revoking one of the tenant's API keys
+// DELETE /api/v1/admin/security/api-keys/:id
+func (ctrl *AuthController) RevokeTenantAPIKey(c *fiber.Ctx) error {
+	// Get tenant ID from context (set by tenant middleware)
+	tenantID := c.Locals("tenant_id").(string)
+	tokenID := c.Params("id")
+
+	// Call service using Fiber's context
+	if err := ctrl.authService.RevokeTenantAPIKey(c.Context(), tenantID, tokenID); err != nil {
+		return HandleError(c, err)
+	}
+
+	return SuccessResponse(c, fiber.StatusOK, "API key revoked successfully", nil)
+}
+
+// StartImpersonation handles an admin starting to act as a tenant member
+// POST /api/v1/admin/users/:id/impersonate
+func (ctrl *AuthController) StartImpersonation(c *fiber.Ctx) error {
+	// Get tenant ID and admin from context (set by tenant and auth middleware)
+	tenantID := c.Locals("tenant_id").(string)
+	adminID := c.Locals("userID").(string)
+	adminRole := c.Locals("userRole").(string)
+	targetUserID := c.Params("id")
+
+	var dto domain.StartImpersonationDTO
+	if err := c.BodyParser(&dto); err != nil {
+		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+	dto.SessionMetadata = sessionMetadata(c)
+
+	// Call service using Fiber's context
+	response, err := ctrl.authService.StartImpersonation(c.Context(), tenantID, adminID, adminRole, targetUserID, &dto)
+	if err != nil {
+		return HandleError(c, err)
+	}
+
+	return SuccessResponse(c, fiber.StatusCreated, "Impersonation started", response)
+}
+
+// StopImpersonation handles ending the impersonation session of the current token
+// POST /api/v1/auth/impersonation/stop
+func (ctrl *AuthController) StopImpersonation(c *fiber.Ctx) error {
+	// Get impersonation session from context (set by auth middleware)
+	sessionID, ok := c.Locals("impersonationSessionID").(string)
+	if !ok || sessionID == "" {
+		return ErrorResponse(c, fiber.StatusBadRequest, "Not impersonating a user")
+	}
+
+	// Call service using Fiber's context
+	if err := ctrl.authService.StopImpersonation(c.Context(), sessionID, sessionMetadata(c)); err != nil {
+		return HandleError(c, err)
+	}
+
+	return SuccessResponse(c, fiber.StatusOK, "Impersonation stopped", nil)
+}
+
+// ListImpersonationSessions handles listing the impersonation sessions of the tenant
+// GET /api/v1/admin/security/impersonations
+func (ctrl *AuthController) ListImpersonationSessions(c *fiber.Ctx) error {
+	// Get tenant ID from context (set by tenant middleware)
+	tenantID := c.Locals("tenant_id").(string)
+
+	// Call service using Fiber's context
+	sessions, err := ctrl.authService.ListImpersonationSessions(c.Context(), tenantID)
+	if err != nil {
+		return HandleError(c, err)
+	}
+
+	return SuccessResponse(c, fiber.StatusOK, "Impersonation sessions retrieved successfully", sessions)
+}
+
+// ListImpersonationEvents handles getting the audit trail of an impersonation session
+// GET /api/v1/admin/security/impersonations/:id/events
+func (ctrl *AuthController) ListImpersonationEvents(c *fiber.Ctx) error {
+	// Get tenant ID from context (set by tenant middleware)
+	tenantID := c.Locals("tenant_id").(string)
+	sessionID := c.Params("id")
+
+	// Call service using Fiber's context
+	events, err := ctrl.authService.ListImpersonationEvents(c.Context(), tenantID, sessionID)
+	if err != nil {
+		return HandleError(c, err)
+	}
+
+	return SuccessResponse(c, fiber.StatusOK, "Impersonation events retrieved successfully", events)
+}
+
+// maxUserAgentLength limits the user agent stored with each session
+const maxUserAgentLength = 512
+
+// sessionMetadata extracts the client information stored with refresh tokens
+func sessionMetadata(c *fiber.Ctx) domain.SessionMetadata {
+	userAgent := c.Get(fiber.HeaderUserAgent)
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = userAgent[:maxUserAgentLength]
+	}
+
+	return domain.SessionMetadata{
+		UserAgent: userAgent,
+		IPAddress: c.IP(),
+	}
+}
+
+// GetPermissionCatalog handles listing the permissions that can be granted to custom roles
+// GET /api/v1/admin/roles/permissions
+func (ctrl *AuthController) GetPermissionCatalog(c *fiber.Ctx) error {
+	// Call service using Fiber's context
+	catalog := ctrl.authService.GetPermissionCatalog(c.Context())
+
+	return SuccessResponse(c, fiber.StatusOK, "Permissions retrieved successfully", catalog)
+}
+
+// ListTenantRoles handles listing the custom roles of the tenant
+// GET /api/v1/admin/roles
+func (ctrl *AuthController) ListTenantRoles(c *fiber.Ctx) error {
+	// Get tenant ID from context (set by tenant middleware)
+	tenantID := c.Locals("tenant_id").(string)
+
+	// Call service using Fiber's context
+	roles, err := ctrl.authService.ListTenantRoles(c.Context(), tenantID)
+	if err != nil {
+		return HandleError(c, err)
+	}
+
+	return SuccessResponse(c, fiber.StatusOK, "Roles retrieved successfully", roles)
+}
+
+// CreateTenantRole handles defining a custom role in the tenant
+// POST /api/v1/admin/roles
+func (ctrl *AuthController) CreateTenantRole(c *fiber.Ctx) error {
+	var dto domain.TenantRoleDTO
+	if err := c.BodyParser(&dto); err != nil {
+		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	// Get tenant ID from context (set by tenant middleware)
+	tenantID := c.Locals("tenant_id").(string)
+
+	// Call service using Fiber's context
+	role, err := ctrl.authService.CreateTenantRole(c.Context(), tenantID, &dto)
+	if err != nil {
+		return HandleError(c, err)
+	}
+
+	return SuccessResponse(c, fiber.StatusCreated, "Role created successfully", role)
+}
+
+// UpdateTenantRole handles updating a custom role of the tenant
+// PUT /api/v1/admin/roles/:id
+func (ctrl *AuthController) UpdateTenantRole(c *fiber.Ctx) error {
+	var dto domain.TenantRoleDTO
+	if err := c.BodyParser(&dto); err != nil {
+		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	// Get tenant ID from context (set by tenant middleware)
+	tenantID := c.Locals("tenant_id").(string)
+	roleID := c.Params("id")
+
+	// Call service using Fiber's context
+	role, err := ctrl.authService.UpdateTenantRole(c.Context(), tenantID, roleID, &dto)
+	if err != nil {
+		return HandleError(c, err)
+	}
+
+	return SuccessResponse(c, fiber.StatusOK, "Role updated successfully", role)
+}
+
+// DeleteTenantRole handles removing a custom role of the tenant
+// DELETE /api/v1/admin/roles/:id
+func (ctrl *AuthController) DeleteTenantRole(c *fiber.Ctx) error {
+	// Get tenant ID from context (set by tenant middleware)
+	tenantID := c.Locals("tenant_id").(string)
+	roleID := c.Params("id")
+
+	// Call service using Fiber's context
+	if err := ctrl.authService.DeleteTenantRole(c.Context(), tenantID, roleID); err != nil {
+		return HandleError(c, err)
+	}
+
+	return SuccessResponse(c, fiber.StatusOK, "Role deleted successfully", nil)
+}
+
+// AssignTenantRole handles assigning a custom role to a tenant member, or removing it
+// PUT /api/v1/admin/users/:id/custom-role
+func (ctrl *AuthController) AssignTenantRole(c *fiber.Ctx) error {
+	var dto domain.AssignTenantRoleDTO
+	if err := c.BodyParser(&dto); err != nil {
+		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	// Get tenant ID from context (set by tenant middleware)
+	tenantID := c.Locals("tenant_id").(string)
+	userID := c.Params("id")
+
+	// Call service using Fiber's context
+	if err := ctrl.authService.AssignTenantRole(c.Context(), tenantID, userID, &dto); err != nil {
+		return HandleError(c, err)
+	}
+
+	return SuccessResponse(c, fiber.StatusOK, "Role assigned successfully", nil)
+}
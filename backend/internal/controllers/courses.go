@@ -222,8 +222,14 @@ func (ctrl *CourseController) CreateCourse(c *fiber.Ctx) error {
 		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
 	}
 
+	// Get the user performing the action
+	actor, err := getCourseActorFromContext(c)
+	if err != nil {
+		return err
+	}
+
 	// Call service
-	course, err := ctrl.courseService.CreateCourse(c.Context(), tenantID, &req)
+	course, err := ctrl.courseService.CreateCourse(c.Context(), tenantID, actor, &req)
 	if err != nil {
 		return HandleError(c, err)
 	}
@@ -252,8 +258,14 @@ func (ctrl *CourseController) UpdateCourse(c *fiber.Ctx) error {
 		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
 	}
 
+	// Get the user performing the action
+	actor, err := getCourseActorFromContext(c)
+	if err != nil {
+		return err
+	}
+
 	// Call service
-	course, err := ctrl.courseService.UpdateCourse(c.Context(), courseID, tenantID, &req)
+	course, err := ctrl.courseService.UpdateCourse(c.Context(), courseID, tenantID, actor, &req)
 	if err != nil {
 		return HandleError(c, err)
 	}
@@ -276,8 +288,14 @@ func (ctrl *CourseController) DeleteCourse(c *fiber.Ctx) error {
 		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid tenant ID")
 	}
 
+	// Get the user performing the action
+	actor, err := getCourseActorFromContext(c)
+	if err != nil {
+		return err
+	}
+
 	// Call service
-	if err := ctrl.courseService.DeleteCourse(c.Context(), courseID, tenantID); err != nil {
+	if err := ctrl.courseService.DeleteCourse(c.Context(), courseID, tenantID, actor); err != nil {
 		return HandleError(c, err)
 	}
 
@@ -299,8 +317,14 @@ func (ctrl *CourseController) PublishCourse(c *fiber.Ctx) error {
 		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid tenant ID")
 	}
 
+	// Get the user performing the action
+	actor, err := getCourseActorFromContext(c)
+	if err != nil {
+		return err
+	}
+
 	// Call service
-	if err := ctrl.courseService.PublishCourse(c.Context(), courseID, tenantID); err != nil {
+	if err := ctrl.courseService.PublishCourse(c.Context(), courseID, tenantID, actor); err != nil {
 		return HandleError(c, err)
 	}
 
@@ -322,8 +346,14 @@ func (ctrl *CourseController) UnpublishCourse(c *fiber.Ctx) error {
 		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid tenant ID")
 	}
 
+	// Get the user performing the action
+	actor, err := getCourseActorFromContext(c)
+	if err != nil {
+		return err
+	}
+
 	// Call service
-	if err := ctrl.courseService.UnpublishCourse(c.Context(), courseID, tenantID); err != nil {
+	if err := ctrl.courseService.UnpublishCourse(c.Context(), courseID, tenantID, actor); err != nil {
 		return HandleError(c, err)
 	}
 
@@ -345,8 +375,14 @@ func (ctrl *CourseController) ArchiveCourse(c *fiber.Ctx) error {
 		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid tenant ID")
 	}
 
+	// Get the user performing the action
+	actor, err := getCourseActorFromContext(c)
+	if err != nil {
+		return err
+	}
+
 	// Call service
-	if err := ctrl.courseService.ArchiveCourse(c.Context(), courseID, tenantID); err != nil {
+	if err := ctrl.courseService.ArchiveCourse(c.Context(), courseID, tenantID, actor); err != nil {
 		return HandleError(c, err)
 	}
 
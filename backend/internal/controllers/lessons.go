@@ -272,8 +272,14 @@ func (ctrl *LessonController) CreateLesson(c *fiber.Ctx) error {
 	// Set course ID from params (override if in body)
 	req.CourseID = courseID
 
+	// Get the user performing the action
+	actor, err := getCourseActorFromContext(c)
+	if err != nil {
+		return err
+	}
+
 	// Call service
-	lesson, err := ctrl.lessonService.CreateLesson(c.Context(), tenantID, &req)
+	lesson, err := ctrl.lessonService.CreateLesson(c.Context(), tenantID, actor, &req)
 	if err != nil {
 		return HandleError(c, err)
 	}
@@ -302,8 +308,14 @@ func (ctrl *LessonController) UpdateLesson(c *fiber.Ctx) error {
 		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
 	}
 
+	// Get the user performing the action
+	actor, err := getCourseActorFromContext(c)
+	if err != nil {
+		return err
+	}
+
 	// Call service
-	lesson, err := ctrl.lessonService.UpdateLesson(c.Context(), lessonID, tenantID, &req)
+	lesson, err := ctrl.lessonService.UpdateLesson(c.Context(), lessonID, tenantID, actor, &req)
 	if err != nil {
 		return HandleError(c, err)
 	}
@@ -326,8 +338,14 @@ func (ctrl *LessonController) DeleteLesson(c *fiber.Ctx) error {
 		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid tenant ID")
 	}
 
+	// Get the user performing the action
+	actor, err := getCourseActorFromContext(c)
+	if err != nil {
+		return err
+	}
+
 	// Call service
-	if err := ctrl.lessonService.DeleteLesson(c.Context(), lessonID, tenantID); err != nil {
+	if err := ctrl.lessonService.DeleteLesson(c.Context(), lessonID, tenantID, actor); err != nil {
 		return HandleError(c, err)
 	}
 
@@ -355,8 +373,14 @@ func (ctrl *LessonController) ReorderLessons(c *fiber.Ctx) error {
 		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
 	}
 
+	// Get the user performing the action
+	actor, err := getCourseActorFromContext(c)
+	if err != nil {
+		return err
+	}
+
 	// Call service
-	if err := ctrl.lessonService.ReorderLessons(c.Context(), courseID, tenantID, &req); err != nil {
+	if err := ctrl.lessonService.ReorderLessons(c.Context(), courseID, tenantID, actor, &req); err != nil {
 		return HandleError(c, err)
 	}
 
@@ -378,10 +402,15 @@ func (ctrl *LessonController) UploadLessonVideo(c *fiber.Ctx) error {
 		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid tenant ID")
 	}
 
-	// Get user ID from context
-	userID, err := uuid.Parse(c.Locals("user_id").(string))
+	// Get the user performing the action
+	actor, err := getCourseActorFromContext(c)
 	if err != nil {
-		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid user ID")
+		return err
+	}
+
+	// Check access before storing the video
+	if err := ctrl.lessonService.CanManageLesson(c.Context(), lessonID, tenantID, actor); err != nil {
+		return HandleError(c, err)
 	}
 
 	// Get video file from form
@@ -405,7 +434,7 @@ func (ctrl *LessonController) UploadLessonVideo(c *fiber.Ctx) error {
 	// Upload to MinIO via Media Service
 	uploadReq := mediadomain.UploadMediaRequest{
 		TenantID:     tenantID,
-		UserID:       userID,
+		UserID:       actor.UserID,
 		FileName:     file.Filename,
 		OriginalName: file.Filename,
 		MimeType:     file.Header.Get("Content-Type"),
@@ -421,7 +450,7 @@ func (ctrl *LessonController) UploadLessonVideo(c *fiber.Ctx) error {
 	}
 
 	// Update lesson with media_id and video_url
-	lesson, err := ctrl.lessonService.UpdateLessonVideo(c.Context(), lessonID, tenantID, mediaResp.ID, mediaResp.URL)
+	lesson, err := ctrl.lessonService.UpdateLessonVideo(c.Context(), lessonID, tenantID, mediaResp.ID, actor, mediaResp.URL)
 	if err != nil {
 		return HandleError(c, err)
 	}
@@ -26,10 +26,10 @@ func NewModuleController(moduleService ports.ModuleService) *ModuleController {
 // CreateModule creates a new module
 // POST /api/v1/modules
 func (ctrl *ModuleController) CreateModule(c *fiber.Ctx) error {
-	// Get user ID from context
-	userID, err := uuid.Parse(c.Locals("userID").(string))
+	// Get the user performing the action
+	actor, err := getCourseActorFromContext(c)
 	if err != nil {
-		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid user ID")
+		return err
 	}
 
 	// Get tenant ID from context
@@ -50,7 +50,7 @@ func (ctrl *ModuleController) CreateModule(c *fiber.Ctx) error {
 	}
 
 	// Call service
-	module, err := ctrl.moduleService.CreateModule(tenantID, userID, req)
+	module, err := ctrl.moduleService.CreateModule(tenantID, actor, req)
 	if err != nil {
 		return HandleError(c, err)
 	}
@@ -169,10 +169,10 @@ func (ctrl *ModuleController) UpdateModule(c *fiber.Ctx) error {
 		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid module ID")
 	}
 
-	// Get user ID from context
-	userID, err := uuid.Parse(c.Locals("userID").(string))
+	// Get the user performing the action
+	actor, err := getCourseActorFromContext(c)
 	if err != nil {
-		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid user ID")
+		return err
 	}
 
 	// Get tenant ID from context
@@ -193,7 +193,7 @@ func (ctrl *ModuleController) UpdateModule(c *fiber.Ctx) error {
 	}
 
 	// Call service
-	module, err := ctrl.moduleService.UpdateModule(tenantID, userID, moduleID, req)
+	module, err := ctrl.moduleService.UpdateModule(tenantID, actor, moduleID, req)
 	if err != nil {
 		return HandleError(c, err)
 	}
@@ -210,10 +210,10 @@ func (ctrl *ModuleController) DeleteModule(c *fiber.Ctx) error {
 		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid module ID")
 	}
 
-	// Get user ID from context
-	userID, err := uuid.Parse(c.Locals("userID").(string))
+	// Get the user performing the action
+	actor, err := getCourseActorFromContext(c)
 	if err != nil {
-		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid user ID")
+		return err
 	}
 
 	// Get tenant ID from context
@@ -223,7 +223,7 @@ func (ctrl *ModuleController) DeleteModule(c *fiber.Ctx) error {
 	}
 
 	// Call service
-	if err := ctrl.moduleService.DeleteModule(tenantID, userID, moduleID); err != nil {
+	if err := ctrl.moduleService.DeleteModule(tenantID, actor, moduleID); err != nil {
 		return HandleError(c, err)
 	}
 
@@ -243,10 +243,10 @@ func (ctrl *ModuleController) PublishModule(c *fiber.Ctx) error {
 		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid module ID")
 	}
 
-	// Get user ID from context
-	userID, err := uuid.Parse(c.Locals("userID").(string))
+	// Get the user performing the action
+	actor, err := getCourseActorFromContext(c)
 	if err != nil {
-		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid user ID")
+		return err
 	}
 
 	// Get tenant ID from context
@@ -256,7 +256,7 @@ func (ctrl *ModuleController) PublishModule(c *fiber.Ctx) error {
 	}
 
 	// Call service
-	module, err := ctrl.moduleService.PublishModule(tenantID, userID, moduleID)
+	module, err := ctrl.moduleService.PublishModule(tenantID, actor, moduleID)
 	if err != nil {
 		return HandleError(c, err)
 	}
@@ -273,10 +273,10 @@ func (ctrl *ModuleController) UnpublishModule(c *fiber.Ctx) error {
 		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid module ID")
 	}
 
-	// Get user ID from context
-	userID, err := uuid.Parse(c.Locals("userID").(string))
+	// Get the user performing the action
+	actor, err := getCourseActorFromContext(c)
 	if err != nil {
-		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid user ID")
+		return err
 	}
 
 	// Get tenant ID from context
@@ -286,7 +286,7 @@ func (ctrl *ModuleController) UnpublishModule(c *fiber.Ctx) error {
 	}
 
 	// Call service
-	module, err := ctrl.moduleService.UnpublishModule(tenantID, userID, moduleID)
+	module, err := ctrl.moduleService.UnpublishModule(tenantID, actor, moduleID)
 	if err != nil {
 		return HandleError(c, err)
 	}
@@ -307,10 +307,10 @@ func (ctrl *ModuleController) ReorderModules(c *fiber.Ctx) error {
 		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid course ID")
 	}
 
-	// Get user ID from context
-	userID, err := uuid.Parse(c.Locals("userID").(string))
+	// Get the user performing the action
+	actor, err := getCourseActorFromContext(c)
 	if err != nil {
-		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid user ID")
+		return err
 	}
 
 	// Get tenant ID from context
@@ -331,7 +331,7 @@ func (ctrl *ModuleController) ReorderModules(c *fiber.Ctx) error {
 	}
 
 	// Call service
-	if err := ctrl.moduleService.ReorderModules(tenantID, userID, courseID, req); err != nil {
+	if err := ctrl.moduleService.ReorderModules(tenantID, actor, courseID, req); err != nil {
 		return HandleError(c, err)
 	}
 
@@ -49,8 +49,14 @@ func (ctrl *QuizController) CreateQuiz(c *fiber.Ctx) error {
 	// Set course ID from params (override if in body)
 	req.CourseID = courseID
 
+	// Get the user performing the action
+	actor, err := getCourseActorFromContext(c)
+	if err != nil {
+		return err
+	}
+
 	// Call service
-	quiz, err := ctrl.quizService.CreateQuiz(c.Context(), tenantID, &req)
+	quiz, err := ctrl.quizService.CreateQuiz(c.Context(), tenantID, actor, &req)
 	if err != nil {
 		return HandleError(c, err)
 	}
@@ -79,8 +85,14 @@ func (ctrl *QuizController) UpdateQuiz(c *fiber.Ctx) error {
 		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
 	}
 
+	// Get the user performing the action
+	actor, err := getCourseActorFromContext(c)
+	if err != nil {
+		return err
+	}
+
 	// Call service
-	quiz, err := ctrl.quizService.UpdateQuiz(c.Context(), quizID, tenantID, &req)
+	quiz, err := ctrl.quizService.UpdateQuiz(c.Context(), quizID, tenantID, actor, &req)
 	if err != nil {
 		return HandleError(c, err)
 	}
@@ -103,8 +115,14 @@ func (ctrl *QuizController) DeleteQuiz(c *fiber.Ctx) error {
 		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid tenant ID")
 	}
 
+	// Get the user performing the action
+	actor, err := getCourseActorFromContext(c)
+	if err != nil {
+		return err
+	}
+
 	// Call service
-	if err := ctrl.quizService.DeleteQuiz(c.Context(), quizID, tenantID); err != nil {
+	if err := ctrl.quizService.DeleteQuiz(c.Context(), quizID, tenantID, actor); err != nil {
 		return HandleError(c, err)
 	}
 
@@ -230,8 +248,14 @@ func (ctrl *QuizController) CreateQuestion(c *fiber.Ctx) error {
 	// Set quiz ID from params (override if in body)
 	req.QuizID = quizID
 
+	// Get the user performing the action
+	actor, err := getCourseActorFromContext(c)
+	if err != nil {
+		return err
+	}
+
 	// Call service
-	question, err := ctrl.quizService.CreateQuestion(c.Context(), tenantID, &req)
+	question, err := ctrl.quizService.CreateQuestion(c.Context(), tenantID, actor, &req)
 	if err != nil {
 		return HandleError(c, err)
 	}
@@ -260,8 +284,14 @@ func (ctrl *QuizController) UpdateQuestion(c *fiber.Ctx) error {
 		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
 	}
 
+	// Get the user performing the action
+	actor, err := getCourseActorFromContext(c)
+	if err != nil {
+		return err
+	}
+
 	// Call service
-	question, err := ctrl.quizService.UpdateQuestion(c.Context(), questionID, tenantID, &req)
+	question, err := ctrl.quizService.UpdateQuestion(c.Context(), questionID, tenantID, actor, &req)
 	if err != nil {
 		return HandleError(c, err)
 	}
@@ -284,8 +314,14 @@ func (ctrl *QuizController) DeleteQuestion(c *fiber.Ctx) error {
 		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid tenant ID")
 	}
 
+	// Get the user performing the action
+	actor, err := getCourseActorFromContext(c)
+	if err != nil {
+		return err
+	}
+
 	// Call service
-	if err := ctrl.quizService.DeleteQuestion(c.Context(), questionID, tenantID); err != nil {
+	if err := ctrl.quizService.DeleteQuestion(c.Context(), questionID, tenantID, actor); err != nil {
 		return HandleError(c, err)
 	}
 
@@ -313,8 +349,14 @@ func (ctrl *QuizController) ReorderQuestions(c *fiber.Ctx) error {
 		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
 	}
 
+	// Get the user performing the action
+	actor, err := getCourseActorFromContext(c)
+	if err != nil {
+		return err
+	}
+
 	// Call service
-	if err := ctrl.quizService.ReorderQuestions(c.Context(), quizID, tenantID, &req); err != nil {
+	if err := ctrl.quizService.ReorderQuestions(c.Context(), quizID, tenantID, actor, &req); err != nil {
 		return HandleError(c, err)
 	}
 
@@ -8,12 +8,14 @@ import (
 	certificatePorts "github.com/DanielIturra1610/stegmaier-landing/internal/core/certificates/ports"
 	coursePorts "github.com/DanielIturra1610/stegmaier-landing/internal/core/courses/ports"
 	enrollmentPorts "github.com/DanielIturra1610/stegmaier-landing/internal/core/enrollments/ports"
+	featurePorts "github.com/DanielIturra1610/stegmaier-landing/internal/core/features/ports"
 	lessonPorts "github.com/DanielIturra1610/stegmaier-landing/internal/core/lessons/ports"
 	"github.com/DanielIturra1610/stegmaier-landing/internal/core/media/domain"
 	notificationPorts "github.com/DanielIturra1610/stegmaier-landing/internal/core/notifications/ports"
 	profilePorts "github.com/DanielIturra1610/stegmaier-landing/internal/core/profile/ports"
 	progressPorts "github.com/DanielIturra1610/stegmaier-landing/internal/core/progress/ports"
 	quizPorts "github.com/DanielIturra1610/stegmaier-landing/internal/core/quizzes/ports"
+	tenantPorts "github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/ports"
 	"github.com/gofiber/fiber/v2"
 )
 
@@ -59,7 +61,11 @@ func MapDomainError(err error) (int, string) {
 	case authPorts.ErrAccountNotVerified:
 		return fiber.StatusForbidden, "Account not verified. Please check your email"
 	case authPorts.ErrAccountLocked:
-		return fiber.StatusForbidden, "Account is locked. Please contact support"
+		return fiber.StatusForbidden, "Account is temporarily locked. Check your email to unlock it"
+	case authPorts.ErrTooManyLoginAttempts:
+		return fiber.StatusTooManyRequests, "Too many failed attempts. Please try again later"
+	case authPorts.ErrUnlockTokenInvalid:
+		return fiber.StatusBadRequest, "Invalid or expired unlock link"
 	case authPorts.ErrAccountDisabled:
 		return fiber.StatusForbidden, "Account is disabled"
 
@@ -74,6 +80,86 @@ func MapDomainError(err error) (int, string) {
 		return fiber.StatusNotFound, "Token not found"
 	case authPorts.ErrRefreshTokenInvalid:
 		return fiber.StatusUnauthorized, "Invalid refresh token"
+	case authPorts.ErrRefreshTokenReused:
+		return fiber.StatusUnauthorized, "Refresh token reuse detected. Please login again"
+	case authPorts.ErrSessionNotFound:
+		return fiber.StatusNotFound, "Session not found"
+
+	// Multi-factor authentication errors
+	case authPorts.ErrMFANotEnrolled:
+		return fiber.StatusBadRequest, "Multi-factor authentication is not enrolled"
+	case authPorts.ErrMFAAlreadyEnabled:
+		return fiber.StatusConflict, "Multi-factor authentication is already enabled"
+	case authPorts.ErrMFACodeInvalid:
+		return fiber.StatusUnauthorized, "Invalid authentication code"
+	case authPorts.ErrMFARecoveryCodeInvalid:
+		return fiber.StatusUnauthorized, "Invalid or already used recovery code"
+	case authPorts.ErrMFAChallengeInvalid:
+		return fiber.StatusUnauthorized, "Invalid MFA challenge"
+	case authPorts.ErrMFAChallengeExpired:
+		return fiber.StatusUnauthorized, "MFA challenge has expired. Please login again"
+
+	// OpenID Connect errors
+	case authPorts.ErrOIDCNotConfigured:
+		return fiber.StatusNotFound, "Single sign-on is not configured for this tenant"
+	case authPorts.ErrOIDCStateInvalid:
+		return fiber.StatusBadRequest, "Invalid or expired single sign-on request. Please try again"
+	case authPorts.ErrOIDCAuthenticationFailed:
+		return fiber.StatusUnauthorized, "Single sign-on authentication failed"
+	case authPorts.ErrOIDCAccountConflict:
+		return fiber.StatusConflict, "An account with this email already exists"
+	case authPorts.ErrFederatedLinkInvalid:
+		return fiber.StatusUnauthorized, "Invalid or expired account link request. Please login again"
+
+	// SAML errors
+	case authPorts.ErrSAMLNotConfigured:
+		return fiber.StatusNotFound, "SAML single sign-on is not configured for this tenant"
+	case authPorts.ErrSAMLCertificateInvalid:
+		return fiber.StatusBadRequest, "Invalid identity provider certificate"
+	case authPorts.ErrSAMLRequestInvalid:
+		return fiber.StatusBadRequest, "Invalid or expired SAML request. Please try again"
+	case authPorts.ErrSAMLAuthenticationFailed:
+		return fiber.StatusUnauthorized, "SAML authentication failed"
+	case authPorts.ErrSAMLLoginCodeInvalid:
+		return fiber.StatusUnauthorized, "Invalid or expired login code. Please login again"
+
+	// WebAuthn errors
+	case authPorts.ErrWebAuthnChallengeInvalid:
+		return fiber.StatusBadRequest, "Invalid or expired passkey challenge. Please try again"
+	case authPorts.ErrWebAuthnVerificationFailed:
+		return fiber.StatusUnauthorized, "Passkey verification failed"
+	case authPorts.ErrWebAuthnCredentialNotFound:
+		return fiber.StatusNotFound, "Passkey not found"
+	case authPorts.ErrWebAuthnCredentialExists:
+		return fiber.StatusConflict, "Passkey is already registered"
+
+	// Impersonation errors
+	case authPorts.ErrImpersonationNotAllowed:
+		return fiber.StatusForbidden, "You are not allowed to impersonate this user"
+	case authPorts.ErrImpersonationSessionInvalid:
+		return fiber.StatusBadRequest, "Impersonation session has ended"
+	case authPorts.ErrImpersonationSessionNotFound:
+		return fiber.StatusNotFound, "Impersonation session not found"
+	case authPorts.ErrTenantRoleNotFound:
+		return fiber.StatusNotFound, "Role not found"
+	case authPorts.ErrTenantRoleExists:
+		return fiber.StatusConflict, "A role with this name already exists"
+	case authPorts.ErrPermissionInvalid:
+		return fiber.StatusBadRequest, "Unknown permission"
+	case authPorts.ErrMagicLinkDisabled:
+		return fiber.StatusForbidden, "Magic link login is not enabled for this tenant"
+	case authPorts.ErrMagicLinkInvalid:
+		return fiber.StatusUnauthorized, "Invalid or expired login link"
+
+	// API token errors
+	case authPorts.ErrAPITokenNotFound:
+		return fiber.StatusNotFound, "API token not found"
+	case authPorts.ErrAPITokenInvalid:
+		return fiber.StatusUnauthorized, "Invalid, expired or revoked API token"
+	case authPorts.ErrAPITokenScopeInvalid:
+		return fiber.StatusBadRequest, "Invalid scope. Use <resource>:read or <resource>:write"
+	case authPorts.ErrAPITokenExpiryInvalid:
+		return fiber.StatusBadRequest, "Token expiry must be in the future"
 
 	// Email verification errors
 	case authPorts.ErrVerificationTokenInvalid:
@@ -90,6 +176,10 @@ func MapDomainError(err error) (int, string) {
 		return fiber.StatusBadRequest, "Password exceeds maximum length"
 	case authPorts.ErrPasswordSameAsOld:
 		return fiber.StatusBadRequest, "New password must be different from old password"
+	case authPorts.ErrPasswordReused:
+		return fiber.StatusBadRequest, "Password was used recently. Please choose a different password"
+	case authPorts.ErrPasswordBreached:
+		return fiber.StatusBadRequest, "This password appeared in a data breach. Please choose a different password"
 	case authPorts.ErrCurrentPasswordIncorrect:
 		return fiber.StatusBadRequest, "Current password is incorrect"
 	case authPorts.ErrPasswordResetTokenInvalid:
@@ -122,6 +212,8 @@ func MapDomainError(err error) (int, string) {
 		return fiber.StatusForbidden, "Tenant is inactive"
 	case authPorts.ErrTenantMismatch:
 		return fiber.StatusForbidden, "User does not belong to this tenant"
+	case authPorts.ErrServiceUnavailable:
+		return fiber.StatusServiceUnavailable, "Service temporarily unavailable"
 
 	// Profile errors
 	case profilePorts.ErrProfileNotFound:
@@ -891,6 +983,18 @@ func MapDomainError(err error) (int, string) {
 	case domain.ErrInsufficientStorage:
 		return fiber.StatusInsufficientStorage, "Insufficient storage space"
 
+	// Plan quota errors
+	case tenantPorts.ErrLearnerLimitReached:
+		return fiber.StatusForbidden, "The tenant reached the active learner limit of its plan"
+	case tenantPorts.ErrCourseLimitReached:
+		return fiber.StatusForbidden, "The tenant reached the course limit of its plan"
+	case tenantPorts.ErrStorageLimitReached:
+		return fiber.StatusForbidden, "The tenant reached the storage limit of its plan"
+
+	// Feature flag errors
+	case featurePorts.ErrFeatureDisabled:
+		return fiber.StatusForbidden, "Feature is not enabled for this tenant"
+
 	// Default
 	default:
 		return fiber.StatusInternalServerError, "Internal server error"
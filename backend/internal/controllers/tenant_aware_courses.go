@@ -4,10 +4,12 @@ import (
 	"log"
 	"strconv"
 
+	authdomain "github.com/DanielIturra1610/stegmaier-landing/internal/core/auth/domain"
 	courseadapters "github.com/DanielIturra1610/stegmaier-landing/internal/core/courses/adapters"
 	"github.com/DanielIturra1610/stegmaier-landing/internal/core/courses/domain"
 	"github.com/DanielIturra1610/stegmaier-landing/internal/core/courses/ports"
 	courseservices "github.com/DanielIturra1610/stegmaier-landing/internal/core/courses/services"
+	tenantports "github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/ports"
 	"github.com/DanielIturra1610/stegmaier-landing/internal/middleware"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -15,11 +17,17 @@ import (
 
 // TenantAwareCourseController handles course-related HTTP requests with dynamic tenant DB connection
 // This controller creates repositories and services dynamically using the tenant DB from context
-type TenantAwareCourseController struct{}
+type TenantAwareCourseController struct {
+	members ports.TenantMemberReader
+	quota   tenantports.QuotaService
+}
 
 // NewTenantAwareCourseController creates a new TenantAwareCourseController
-func NewTenantAwareCourseController() *TenantAwareCourseController {
-	return &TenantAwareCourseController{}
+func NewTenantAwareCourseController(members ports.TenantMemberReader, quota tenantports.QuotaService) *TenantAwareCourseController {
+	return &TenantAwareCourseController{
+		members: members,
+		quota:   quota,
+	}
 }
 
 // getCourseService creates a course service using the tenant DB from context
@@ -34,7 +42,30 @@ func (ctrl *TenantAwareCourseController) getCourseService(c *fiber.Ctx) (ports.C
 	categoryRepo := courseadapters.NewPostgreSQLCourseCategoryRepository(tenantDB)
 
 	// Create and return service
-	return courseservices.NewCourseService(courseRepo, categoryRepo), nil
+	return courseservices.NewCourseService(courseRepo, categoryRepo, ctrl.members, ctrl.quota), nil
+}
+
+// getCourseActorFromContext returns the authenticated user and their tenant role,
+// which the course policy uses to authorize changes to course content.
+// The role is the one of the membership in the current tenant (set by MembershipMiddleware),
+// not the global role of the user, which may come from another tenant. Superadmins keep
+// their platform role.
+func getCourseActorFromContext(c *fiber.Ctx) (domain.CourseActor, error) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		return domain.CourseActor{}, err
+	}
+
+	if role, err := middleware.GetAuthenticatedUserRole(c); err == nil && role == string(authdomain.RoleSuperAdmin) {
+		return domain.NewCourseActor(userID, role), nil
+	}
+
+	role, ok := c.Locals(middleware.MembershipRoleKey).(string)
+	if !ok || role == "" {
+		return domain.CourseActor{}, fiber.NewError(fiber.StatusForbidden, "Tenant membership required")
+	}
+
+	return domain.NewCourseActor(userID, role), nil
 }
 
 // GetCourse retrieves a course by ID
@@ -284,7 +315,12 @@ func (ctrl *TenantAwareCourseController) CreateCourse(c *fiber.Ctx) error {
 		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
 	}
 
-	course, err := courseService.CreateCourse(c.Context(), tenantID, &req)
+	actor, err := getCourseActorFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	course, err := courseService.CreateCourse(c.Context(), tenantID, actor, &req)
 	if err != nil {
 		return HandleError(c, err)
 	}
@@ -319,7 +355,12 @@ func (ctrl *TenantAwareCourseController) UpdateCourse(c *fiber.Ctx) error {
 		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
 	}
 
-	course, err := courseService.UpdateCourse(c.Context(), courseID, tenantID, &req)
+	actor, err := getCourseActorFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	course, err := courseService.UpdateCourse(c.Context(), courseID, tenantID, actor, &req)
 	if err != nil {
 		return HandleError(c, err)
 	}
@@ -349,7 +390,12 @@ func (ctrl *TenantAwareCourseController) DeleteCourse(c *fiber.Ctx) error {
 		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid tenant ID")
 	}
 
-	if err := courseService.DeleteCourse(c.Context(), courseID, tenantID); err != nil {
+	actor, err := getCourseActorFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	if err := courseService.DeleteCourse(c.Context(), courseID, tenantID, actor); err != nil {
 		return HandleError(c, err)
 	}
 
@@ -378,7 +424,12 @@ func (ctrl *TenantAwareCourseController) PublishCourse(c *fiber.Ctx) error {
 		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid tenant ID")
 	}
 
-	if err := courseService.PublishCourse(c.Context(), courseID, tenantID); err != nil {
+	actor, err := getCourseActorFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	if err := courseService.PublishCourse(c.Context(), courseID, tenantID, actor); err != nil {
 		return HandleError(c, err)
 	}
 
@@ -407,7 +458,12 @@ func (ctrl *TenantAwareCourseController) UnpublishCourse(c *fiber.Ctx) error {
 		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid tenant ID")
 	}
 
-	if err := courseService.UnpublishCourse(c.Context(), courseID, tenantID); err != nil {
+	actor, err := getCourseActorFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	if err := courseService.UnpublishCourse(c.Context(), courseID, tenantID, actor); err != nil {
 		return HandleError(c, err)
 	}
 
@@ -436,7 +492,12 @@ func (ctrl *TenantAwareCourseController) ArchiveCourse(c *fiber.Ctx) error {
 		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid tenant ID")
 	}
 
-	if err := courseService.ArchiveCourse(c.Context(), courseID, tenantID); err != nil {
+	actor, err := getCourseActorFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	if err := courseService.ArchiveCourse(c.Context(), courseID, tenantID, actor); err != nil {
 		return HandleError(c, err)
 	}
 
@@ -546,3 +607,100 @@ func (ctrl *TenantAwareCourseController) RateCourse(c *fiber.Ctx) error {
 
 	return SuccessResponse(c, fiber.StatusOK, "Course rated successfully", nil)
 }
+
+// ListCourseInstructors retrieves the co-instructors of a course
+// GET /api/v1/courses/:id/instructors
+func (ctrl *TenantAwareCourseController) ListCourseInstructors(c *fiber.Ctx) error {
+	courseService, err := ctrl.getCourseService(c)
+	if err != nil {
+		return ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	courseID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid course ID")
+	}
+
+	tenantID, err := getTenantIDFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	instructors, err := courseService.ListCourseInstructors(c.Context(), courseID, tenantID)
+	if err != nil {
+		return HandleError(c, err)
+	}
+
+	return SuccessResponse(c, fiber.StatusOK, "Course instructors retrieved successfully", instructors)
+}
+
+// AddCourseInstructor adds a co-instructor to a course (course instructor/admin only)
+// POST /api/v1/courses/:id/instructors
+func (ctrl *TenantAwareCourseController) AddCourseInstructor(c *fiber.Ctx) error {
+	courseService, err := ctrl.getCourseService(c)
+	if err != nil {
+		return ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	courseID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid course ID")
+	}
+
+	tenantID, err := getTenantIDFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	var req domain.AddCourseInstructorRequest
+	if err := c.BodyParser(&req); err != nil {
+		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	actor, err := getCourseActorFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	instructor, err := courseService.AddCourseInstructor(c.Context(), courseID, tenantID, actor, &req)
+	if err != nil {
+		return HandleError(c, err)
+	}
+
+	return SuccessResponse(c, fiber.StatusCreated, "Course instructor added successfully", instructor)
+}
+
+// RemoveCourseInstructor removes a co-instructor from a course (course instructor/admin only)
+// DELETE /api/v1/courses/:id/instructors/:userId
+func (ctrl *TenantAwareCourseController) RemoveCourseInstructor(c *fiber.Ctx) error {
+	courseService, err := ctrl.getCourseService(c)
+	if err != nil {
+		return ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	courseID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid course ID")
+	}
+
+	userID, err := uuid.Parse(c.Params("userId"))
+	if err != nil {
+		return ErrorResponse(c, fiber.StatusBadRequest, "Invalid user ID")
+	}
+
+	tenantID, err := getTenantIDFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	actor, err := getCourseActorFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	if err := courseService.RemoveCourseInstructor(c.Context(), courseID, userID, tenantID, actor); err != nil {
+		return HandleError(c, err)
+	}
+
+	return SuccessResponse(c, fiber.StatusOK, "Course instructor removed successfully", nil)
+}
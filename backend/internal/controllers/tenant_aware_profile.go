@@ -22,6 +22,7 @@ type TenantAwareProfileController struct {
 	userRepo       authPorts.UserRepository
 	fileStorage    ports.FileStorageService
 	passwordHasher hasher.PasswordHasher
+	passwordPolicy authPorts.PasswordPolicyService
 }
 
 // NewTenantAwareProfileController creates a new TenantAwareProfileController
@@ -30,12 +31,14 @@ func NewTenantAwareProfileController(
 	userRepo authPorts.UserRepository,
 	fileStorage ports.FileStorageService,
 	passwordHasher hasher.PasswordHasher,
+	passwordPolicy authPorts.PasswordPolicyService,
 ) *TenantAwareProfileController {
 	return &TenantAwareProfileController{
 		authRepo:       authRepo,
 		userRepo:       userRepo,
 		fileStorage:    fileStorage,
 		passwordHasher: passwordHasher,
+		passwordPolicy: passwordPolicy,
 	}
 }
 
@@ -56,6 +59,7 @@ func (ctrl *TenantAwareProfileController) getProfileService(c *fiber.Ctx) (ports
 		ctrl.userRepo,
 		ctrl.fileStorage,
 		ctrl.passwordHasher,
+		ctrl.passwordPolicy,
 	), nil
 }
 
@@ -4,20 +4,21 @@ import (
 	"context"
 
 	"github.com/DanielIturra1610/stegmaier-landing/internal/core/assignments/domain"
+	coursedomain "github.com/DanielIturra1610/stegmaier-landing/internal/core/courses/domain"
 	"github.com/google/uuid"
 )
 
 // AssignmentService define las operaciones de lógica de negocio para assignments
 type AssignmentService interface {
 	// Assignment operations
-	CreateAssignment(ctx context.Context, tenantID, userID uuid.UUID, req *domain.CreateAssignmentRequest) (*domain.AssignmentResponse, error)
+	CreateAssignment(ctx context.Context, tenantID uuid.UUID, actor coursedomain.CourseActor, req *domain.CreateAssignmentRequest) (*domain.AssignmentResponse, error)
 	GetAssignment(ctx context.Context, id, tenantID uuid.UUID) (*domain.AssignmentResponse, error)
 	GetCourseAssignments(ctx context.Context, courseID, tenantID uuid.UUID, page, pageSize int) (*domain.AssignmentListResponse, error)
 	GetMyAssignments(ctx context.Context, studentID, tenantID uuid.UUID, page, pageSize int) (*domain.AssignmentListResponse, error)
-	UpdateAssignment(ctx context.Context, id, tenantID, userID uuid.UUID, req *domain.UpdateAssignmentRequest) (*domain.AssignmentResponse, error)
-	DeleteAssignment(ctx context.Context, id, tenantID, userID uuid.UUID) error
-	PublishAssignment(ctx context.Context, id, tenantID, userID uuid.UUID) (*domain.AssignmentResponse, error)
-	UnpublishAssignment(ctx context.Context, id, tenantID, userID uuid.UUID) (*domain.AssignmentResponse, error)
+	UpdateAssignment(ctx context.Context, id, tenantID uuid.UUID, actor coursedomain.CourseActor, req *domain.UpdateAssignmentRequest) (*domain.AssignmentResponse, error)
+	DeleteAssignment(ctx context.Context, id, tenantID uuid.UUID, actor coursedomain.CourseActor) error
+	PublishAssignment(ctx context.Context, id, tenantID uuid.UUID, actor coursedomain.CourseActor) (*domain.AssignmentResponse, error)
+	UnpublishAssignment(ctx context.Context, id, tenantID uuid.UUID, actor coursedomain.CourseActor) (*domain.AssignmentResponse, error)
 
 	// Submission operations - Student
 	GetMySubmission(ctx context.Context, assignmentID, studentID, tenantID uuid.UUID) (*domain.SubmissionResponse, error)
@@ -36,11 +37,11 @@ type AssignmentService interface {
 	DeleteSubmission(ctx context.Context, id, tenantID, userID uuid.UUID) error
 
 	// File operations
-	UploadAssignmentFile(ctx context.Context, assignmentID, tenantID, userID uuid.UUID, filename, originalFilename, mimeType string, fileData []byte, description string, isTemplate bool) (*domain.FileResponse, error)
+	UploadAssignmentFile(ctx context.Context, assignmentID, tenantID uuid.UUID, actor coursedomain.CourseActor, filename, originalFilename, mimeType string, fileData []byte, description string, isTemplate bool) (*domain.FileResponse, error)
 	UploadSubmissionFile(ctx context.Context, submissionID, tenantID, userID uuid.UUID, filename, originalFilename, mimeType string, fileData []byte, description string) (*domain.FileResponse, error)
 	GetFile(ctx context.Context, fileID, tenantID uuid.UUID) (*domain.FileResponse, error)
 	DownloadFile(ctx context.Context, fileID, tenantID, userID uuid.UUID) ([]byte, string, error)
-	DeleteAssignmentFile(ctx context.Context, assignmentID, fileID, tenantID, userID uuid.UUID) error
+	DeleteAssignmentFile(ctx context.Context, assignmentID, fileID, tenantID uuid.UUID, actor coursedomain.CourseActor) error
 	DeleteSubmissionFile(ctx context.Context, submissionID, fileID, tenantID, userID uuid.UUID) error
 
 	// Comment operations
@@ -56,8 +57,8 @@ type AssignmentService interface {
 	GetRubricTemplates(ctx context.Context, tenantID uuid.UUID) (*domain.RubricListResponse, error)
 	UpdateRubric(ctx context.Context, id, tenantID, userID uuid.UUID, req *domain.UpdateRubricRequest) (*domain.RubricResponse, error)
 	DeleteRubric(ctx context.Context, id, tenantID, userID uuid.UUID) error
-	AttachRubricToAssignment(ctx context.Context, assignmentID, rubricID, tenantID, userID uuid.UUID) error
-	DetachRubricFromAssignment(ctx context.Context, assignmentID, tenantID, userID uuid.UUID) error
+	AttachRubricToAssignment(ctx context.Context, assignmentID, rubricID, tenantID uuid.UUID, actor coursedomain.CourseActor) error
+	DetachRubricFromAssignment(ctx context.Context, assignmentID, tenantID uuid.UUID, actor coursedomain.CourseActor) error
 
 	// Peer review operations
 	AssignPeerReview(ctx context.Context, tenantID, instructorID uuid.UUID, req *domain.CreatePeerReviewRequest) (*domain.PeerReviewResponse, error)
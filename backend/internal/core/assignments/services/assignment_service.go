@@ -2,27 +2,38 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/DanielIturra1610/stegmaier-landing/internal/core/assignments/domain"
 	"github.com/DanielIturra1610/stegmaier-landing/internal/core/assignments/ports"
+	coursedomain "github.com/DanielIturra1610/stegmaier-landing/internal/core/courses/domain"
+	courseports "github.com/DanielIturra1610/stegmaier-landing/internal/core/courses/ports"
+	featuredomain "github.com/DanielIturra1610/stegmaier-landing/internal/core/features/domain"
+	featureports "github.com/DanielIturra1610/stegmaier-landing/internal/core/features/ports"
 	"github.com/google/uuid"
 )
 
 // AssignmentService implementa la interfaz ports.AssignmentService
 type AssignmentService struct {
-	repo    ports.AssignmentRepository
-	storage ports.FileStorage
+	repo         ports.AssignmentRepository
+	storage      ports.FileStorage
+	coursePolicy courseports.CoursePolicy
+	features     featureports.FeatureFlagService
 }
 
 // NewAssignmentService crea una nueva instancia del servicio
 func NewAssignmentService(
 	repo ports.AssignmentRepository,
 	storage ports.FileStorage,
+	coursePolicy courseports.CoursePolicy,
+	features featureports.FeatureFlagService,
 ) ports.AssignmentService {
 	return &AssignmentService{
-		repo:    repo,
-		storage: storage,
+		repo:         repo,
+		storage:      storage,
+		coursePolicy: coursePolicy,
+		features:     features,
 	}
 }
 
@@ -33,13 +44,22 @@ func NewAssignmentService(
 // CreateAssignment crea un nuevo assignment
 func (s *AssignmentService) CreateAssignment(
 	ctx context.Context,
-	tenantID, userID uuid.UUID,
+	tenantID uuid.UUID,
+	actor coursedomain.CourseActor,
 	req *domain.CreateAssignmentRequest,
 ) (*domain.AssignmentResponse, error) {
+	// Verificar permisos sobre el curso
+	if err := s.coursePolicy.CanManageCourse(ctx, req.CourseID, tenantID, actor); err != nil {
+		if errors.Is(err, courseports.ErrNotCourseOwner) {
+			return nil, ports.ErrNotAssignmentOwner
+		}
+		return nil, err
+	}
+
 	// Crear assignment
 	assignment := domain.NewAssignment(
 		tenantID,
-		userID,
+		actor.UserID,
 		req.CourseID,
 		req.Title,
 		req.Description,
@@ -163,7 +183,8 @@ func (s *AssignmentService) GetMyAssignments(
 // UpdateAssignment actualiza un assignment
 func (s *AssignmentService) UpdateAssignment(
 	ctx context.Context,
-	id, tenantID, userID uuid.UUID,
+	id, tenantID uuid.UUID,
+	actor coursedomain.CourseActor,
 	req *domain.UpdateAssignmentRequest,
 ) (*domain.AssignmentResponse, error) {
 	// Obtener assignment existente
@@ -172,9 +193,9 @@ func (s *AssignmentService) UpdateAssignment(
 		return nil, ports.ErrAssignmentNotFound
 	}
 
-	// Verificar permisos
-	if assignment.CreatedBy != userID {
-		return nil, ports.ErrNotAssignmentOwner
+	// Verificar permisos sobre el curso
+	if err := s.checkAssignmentAccess(ctx, assignment, tenantID, actor); err != nil {
+		return nil, err
 	}
 
 	// Aplicar actualizaciones
@@ -291,7 +312,8 @@ func (s *AssignmentService) UpdateAssignment(
 // DeleteAssignment elimina un assignment
 func (s *AssignmentService) DeleteAssignment(
 	ctx context.Context,
-	id, tenantID, userID uuid.UUID,
+	id, tenantID uuid.UUID,
+	actor coursedomain.CourseActor,
 ) error {
 	// Obtener assignment
 	assignment, err := s.repo.GetAssignment(ctx, id, tenantID)
@@ -299,9 +321,9 @@ func (s *AssignmentService) DeleteAssignment(
 		return ports.ErrAssignmentNotFound
 	}
 
-	// Verificar permisos
-	if assignment.CreatedBy != userID {
-		return ports.ErrNotAssignmentOwner
+	// Verificar permisos sobre el curso
+	if err := s.checkAssignmentAccess(ctx, assignment, tenantID, actor); err != nil {
+		return err
 	}
 
 	// Eliminar assignment
@@ -315,7 +337,8 @@ func (s *AssignmentService) DeleteAssignment(
 // PublishAssignment publica un assignment
 func (s *AssignmentService) PublishAssignment(
 	ctx context.Context,
-	id, tenantID, userID uuid.UUID,
+	id, tenantID uuid.UUID,
+	actor coursedomain.CourseActor,
 ) (*domain.AssignmentResponse, error) {
 	// Obtener assignment
 	assignment, err := s.repo.GetAssignment(ctx, id, tenantID)
@@ -323,9 +346,9 @@ func (s *AssignmentService) PublishAssignment(
 		return nil, ports.ErrAssignmentNotFound
 	}
 
-	// Verificar permisos
-	if assignment.CreatedBy != userID {
-		return nil, ports.ErrNotAssignmentOwner
+	// Verificar permisos sobre el curso
+	if err := s.checkAssignmentAccess(ctx, assignment, tenantID, actor); err != nil {
+		return nil, err
 	}
 
 	// Publicar
@@ -344,7 +367,8 @@ func (s *AssignmentService) PublishAssignment(
 // UnpublishAssignment despublica un assignment
 func (s *AssignmentService) UnpublishAssignment(
 	ctx context.Context,
-	id, tenantID, userID uuid.UUID,
+	id, tenantID uuid.UUID,
+	actor coursedomain.CourseActor,
 ) (*domain.AssignmentResponse, error) {
 	// Obtener assignment
 	assignment, err := s.repo.GetAssignment(ctx, id, tenantID)
@@ -352,9 +376,9 @@ func (s *AssignmentService) UnpublishAssignment(
 		return nil, ports.ErrAssignmentNotFound
 	}
 
-	// Verificar permisos
-	if assignment.CreatedBy != userID {
-		return nil, ports.ErrNotAssignmentOwner
+	// Verificar permisos sobre el curso
+	if err := s.checkAssignmentAccess(ctx, assignment, tenantID, actor); err != nil {
+		return nil, err
 	}
 
 	// Despublicar
@@ -767,7 +791,8 @@ func (s *AssignmentService) DeleteSubmission(
 // UploadAssignmentFile sube un archivo para un assignment
 func (s *AssignmentService) UploadAssignmentFile(
 	ctx context.Context,
-	assignmentID, tenantID, userID uuid.UUID,
+	assignmentID, tenantID uuid.UUID,
+	actor coursedomain.CourseActor,
 	filename, originalFilename, mimeType string,
 	fileData []byte,
 	description string,
@@ -779,9 +804,9 @@ func (s *AssignmentService) UploadAssignmentFile(
 		return nil, ports.ErrAssignmentNotFound
 	}
 
-	// Verificar permisos
-	if assignment.CreatedBy != userID {
-		return nil, ports.ErrNotAssignmentOwner
+	// Verificar permisos sobre el curso
+	if err := s.checkAssignmentAccess(ctx, assignment, tenantID, actor); err != nil {
+		return nil, err
 	}
 
 	// Validar archivo
@@ -792,7 +817,7 @@ func (s *AssignmentService) UploadAssignmentFile(
 	// Crear registro de archivo
 	file := domain.NewAssignmentFile(
 		tenantID,
-		userID,
+		actor.UserID,
 		filename,
 		originalFilename,
 		mimeType,
@@ -946,7 +971,8 @@ func (s *AssignmentService) DownloadFile(
 // DeleteAssignmentFile elimina un archivo de un assignment
 func (s *AssignmentService) DeleteAssignmentFile(
 	ctx context.Context,
-	assignmentID, fileID, tenantID, userID uuid.UUID,
+	assignmentID, fileID, tenantID uuid.UUID,
+	actor coursedomain.CourseActor,
 ) error {
 	// Obtener assignment
 	assignment, err := s.repo.GetAssignment(ctx, assignmentID, tenantID)
@@ -954,9 +980,9 @@ func (s *AssignmentService) DeleteAssignmentFile(
 		return ports.ErrAssignmentNotFound
 	}
 
-	// Verificar permisos
-	if assignment.CreatedBy != userID {
-		return ports.ErrNotAssignmentOwner
+	// Verificar permisos sobre el curso
+	if err := s.checkAssignmentAccess(ctx, assignment, tenantID, actor); err != nil {
+		return err
 	}
 
 	// Obtener archivo
@@ -1357,7 +1383,8 @@ func (s *AssignmentService) DeleteRubric(
 // AttachRubricToAssignment asocia una rúbrica a un assignment
 func (s *AssignmentService) AttachRubricToAssignment(
 	ctx context.Context,
-	assignmentID, rubricID, tenantID, userID uuid.UUID,
+	assignmentID, rubricID, tenantID uuid.UUID,
+	actor coursedomain.CourseActor,
 ) error {
 	// Obtener assignment
 	assignment, err := s.repo.GetAssignment(ctx, assignmentID, tenantID)
@@ -1365,9 +1392,9 @@ func (s *AssignmentService) AttachRubricToAssignment(
 		return ports.ErrAssignmentNotFound
 	}
 
-	// Verificar permisos
-	if assignment.CreatedBy != userID {
-		return ports.ErrNotAssignmentOwner
+	// Verificar permisos sobre el curso
+	if err := s.checkAssignmentAccess(ctx, assignment, tenantID, actor); err != nil {
+		return err
 	}
 
 	// Verificar que la rúbrica existe
@@ -1390,7 +1417,8 @@ func (s *AssignmentService) AttachRubricToAssignment(
 // DetachRubricFromAssignment desasocia una rúbrica de un assignment
 func (s *AssignmentService) DetachRubricFromAssignment(
 	ctx context.Context,
-	assignmentID, tenantID, userID uuid.UUID,
+	assignmentID, tenantID uuid.UUID,
+	actor coursedomain.CourseActor,
 ) error {
 	// Obtener assignment
 	assignment, err := s.repo.GetAssignment(ctx, assignmentID, tenantID)
@@ -1398,9 +1426,9 @@ func (s *AssignmentService) DetachRubricFromAssignment(
 		return ports.ErrAssignmentNotFound
 	}
 
-	// Verificar permisos
-	if assignment.CreatedBy != userID {
-		return ports.ErrNotAssignmentOwner
+	// Verificar permisos sobre el curso
+	if err := s.checkAssignmentAccess(ctx, assignment, tenantID, actor); err != nil {
+		return err
 	}
 
 	// Desasociar rúbrica
@@ -1424,6 +1452,10 @@ func (s *AssignmentService) AssignPeerReview(
 	tenantID, instructorID uuid.UUID,
 	req *domain.CreatePeerReviewRequest,
 ) (*domain.PeerReviewResponse, error) {
+	if err := s.requirePeerReview(ctx, tenantID); err != nil {
+		return nil, err
+	}
+
 	// Verificar que la submission existe
 	submission, err := s.repo.GetSubmission(ctx, req.SubmissionID, tenantID)
 	if err != nil {
@@ -1480,6 +1512,10 @@ func (s *AssignmentService) GetMyPeerReviews(
 	ctx context.Context,
 	reviewerID, tenantID uuid.UUID,
 ) ([]domain.PeerReviewResponse, error) {
+	if err := s.requirePeerReview(ctx, tenantID); err != nil {
+		return nil, err
+	}
+
 	reviews, err := s.repo.GetPeerReviewsByReviewer(ctx, reviewerID, tenantID)
 	if err != nil {
 		return nil, err
@@ -1498,6 +1534,10 @@ func (s *AssignmentService) GetSubmissionPeerReviews(
 	ctx context.Context,
 	submissionID, tenantID uuid.UUID,
 ) ([]domain.PeerReviewResponse, error) {
+	if err := s.requirePeerReview(ctx, tenantID); err != nil {
+		return nil, err
+	}
+
 	reviews, err := s.repo.GetPeerReviewsBySubmission(ctx, submissionID, tenantID)
 	if err != nil {
 		return nil, err
@@ -1517,6 +1557,10 @@ func (s *AssignmentService) SubmitPeerReview(
 	reviewID, reviewerID, tenantID uuid.UUID,
 	req *domain.SubmitPeerReviewRequest,
 ) (*domain.PeerReviewResponse, error) {
+	if err := s.requirePeerReview(ctx, tenantID); err != nil {
+		return nil, err
+	}
+
 	// Obtener peer review
 	review, err := s.repo.GetPeerReview(ctx, reviewID, tenantID)
 	if err != nil {
@@ -1546,6 +1590,10 @@ func (s *AssignmentService) DeletePeerReview(
 	ctx context.Context,
 	reviewID, tenantID, userID uuid.UUID,
 ) error {
+	if err := s.requirePeerReview(ctx, tenantID); err != nil {
+		return err
+	}
+
 	// Obtener peer review
 	_, err := s.repo.GetPeerReview(ctx, reviewID, tenantID)
 	if err != nil {
@@ -1602,3 +1650,36 @@ func (s *AssignmentService) GetCourseStatistics(
 
 	return stats, nil
 }
+
+// checkAssignmentAccess verifica que el usuario pueda modificar el curso del assignment
+func (s *AssignmentService) checkAssignmentAccess(
+	ctx context.Context,
+	assignment *domain.Assignment,
+	tenantID uuid.UUID,
+	actor coursedomain.CourseActor,
+) error {
+	// Los assignments sin curso solo los modifica su creador
+	if assignment.CourseID == nil {
+		if actor.IsAdmin() || assignment.CreatedBy == actor.UserID {
+			return nil
+		}
+		return ports.ErrNotAssignmentOwner
+	}
+
+	if err := s.coursePolicy.CanManageCourse(ctx, *assignment.CourseID, tenantID, actor); err != nil {
+		if errors.Is(err, courseports.ErrNotCourseOwner) {
+			return ports.ErrNotAssignmentOwner
+		}
+		return err
+	}
+
+	return nil
+}
+
+// requirePeerReview verifica que el feature flag de peer review esté activo para el tenant
+func (s *AssignmentService) requirePeerReview(ctx context.Context, tenantID uuid.UUID) error {
+	if s.features == nil {
+		return nil
+	}
+	return s.features.RequireFeature(ctx, tenantID.String(), featuredomain.FeaturePeerReview)
+}
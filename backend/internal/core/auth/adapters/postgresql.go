@@ -3,12 +3,15 @@ package adapters
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/DanielIturra1610/stegmaier-landing/internal/core/auth/domain"
 	"github.com/DanielIturra1610/stegmaier-landing/internal/core/auth/ports"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 // PostgreSQLAuthRepository implements AuthRepository interface for PostgreSQL
@@ -26,8 +29,8 @@ func NewPostgreSQLAuthRepository(db *sqlx.DB) ports.AuthRepository {
 // CreateUser persists a new user to the database
 func (r *PostgreSQLAuthRepository) CreateUser(ctx context.Context, user *domain.User) error {
 	query := `
-		INSERT INTO users (id, tenant_id, email, password_hash, full_name, roles, active_role, is_verified, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		INSERT INTO users (id, tenant_id, email, password_hash, full_name, roles, active_role, is_verified, created_at, updated_at, password_changed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $9)
 	`
 
 	// Convert *string to sql.NullString for proper NULL handling
@@ -62,7 +65,8 @@ func (r *PostgreSQLAuthRepository) CreateUser(ctx context.Context, user *domain.
 // GetUserByID retrieves a user by their unique identifier
 func (r *PostgreSQLAuthRepository) GetUserByID(ctx context.Context, userID string) (*domain.User, error) {
 	query := `
-		SELECT id, tenant_id, email, password_hash, full_name, roles, active_role, is_verified, created_at, updated_at
+		SELECT id, tenant_id, email, password_hash, full_name, roles, active_role, is_verified, created_at, updated_at,
+		       password_changed_at, must_change_password
 		FROM users
 		WHERE id = $1
 	`
@@ -82,7 +86,8 @@ func (r *PostgreSQLAuthRepository) GetUserByID(ctx context.Context, userID strin
 // GetUserByEmail retrieves a user by their email address
 func (r *PostgreSQLAuthRepository) GetUserByEmail(ctx context.Context, email string) (*domain.User, error) {
 	query := `
-		SELECT id, tenant_id, email, password_hash, full_name, roles, active_role, is_verified, created_at, updated_at
+		SELECT id, tenant_id, email, password_hash, full_name, roles, active_role, is_verified, created_at, updated_at,
+		       password_changed_at, must_change_password
 		FROM users
 		WHERE email = $1
 	`
@@ -159,7 +164,8 @@ func (r *PostgreSQLAuthRepository) DeleteUser(ctx context.Context, userID string
 func (r *PostgreSQLAuthRepository) ListUsers(ctx context.Context, filters *domain.UserListFilters, page, pageSize int) ([]*domain.User, int, error) {
 	// Build query with filters
 	query := `
-		SELECT id, tenant_id, email, password_hash, full_name, roles, active_role, is_verified, created_at, updated_at
+		SELECT id, tenant_id, email, password_hash, full_name, roles, active_role, is_verified, created_at, updated_at,
+		       password_changed_at, must_change_password
 		FROM users
 		WHERE 1=1
 	`
@@ -377,21 +383,125 @@ func (r *PostgreSQLAuthRepository) DeletePasswordResetTokensByUserID(ctx context
 	return nil
 }
 
+// Magic link operations
+
+// CreateMagicLinkToken persists a new passwordless login token
+func (r *PostgreSQLAuthRepository) CreateMagicLinkToken(ctx context.Context, token *domain.MagicLinkToken) error {
+	query := `
+		INSERT INTO magic_link_tokens (id, user_id, tenant_id, token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		token.ID,
+		token.UserID,
+		token.TenantID,
+		token.TokenHash,
+		token.ExpiresAt,
+		token.CreatedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create magic link token: %w", err)
+	}
+
+	return nil
+}
+
+// ConsumeMagicLinkToken removes a login token by the hash of its value and returns it, so
+// that each link works once
+func (r *PostgreSQLAuthRepository) ConsumeMagicLinkToken(ctx context.Context, tokenHash string) (*domain.MagicLinkToken, error) {
+	query := `
+		DELETE FROM magic_link_tokens
+		WHERE token_hash = $1
+		RETURNING id, user_id, tenant_id, token_hash, expires_at, created_at
+	`
+
+	var magicLink domain.MagicLinkToken
+	err := r.db.GetContext(ctx, &magicLink, query, tokenHash)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ports.ErrTokenNotFound
+		}
+		return nil, fmt.Errorf("failed to consume magic link token: %w", err)
+	}
+
+	return &magicLink, nil
+}
+
+// DeleteMagicLinkTokensByUserID removes all login tokens for a user
+func (r *PostgreSQLAuthRepository) DeleteMagicLinkTokensByUserID(ctx context.Context, userID string) error {
+	query := `DELETE FROM magic_link_tokens WHERE user_id = $1`
+
+	_, err := r.db.ExecContext(ctx, query, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete magic link tokens by user ID: %w", err)
+	}
+
+	return nil
+}
+
+// GetTenantMagicLinkEnabled checks if a tenant allows login with links sent by email
+func (r *PostgreSQLAuthRepository) GetTenantMagicLinkEnabled(ctx context.Context, tenantID string) (bool, error) {
+	query := `SELECT magic_link_enabled FROM tenants WHERE id = $1`
+
+	var enabled bool
+	err := r.db.GetContext(ctx, &enabled, query, tenantID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, ports.ErrTenantNotFound
+		}
+		return false, fmt.Errorf("failed to get tenant magic link setting: %w", err)
+	}
+
+	return enabled, nil
+}
+
+// UpdateTenantMagicLinkEnabled enables or disables login with links sent by email in a tenant
+func (r *PostgreSQLAuthRepository) UpdateTenantMagicLinkEnabled(ctx context.Context, tenantID string, enabled bool) error {
+	query := `
+		UPDATE tenants
+		SET magic_link_enabled = $2, updated_at = $3
+		WHERE id = $1
+	`
+
+	result, err := r.db.ExecContext(ctx, query, tenantID, enabled, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to update tenant magic link setting: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ports.ErrTenantNotFound
+	}
+
+	return nil
+}
+
 // Refresh token operations
 
 // CreateRefreshToken persists a new refresh token
 func (r *PostgreSQLAuthRepository) CreateRefreshToken(ctx context.Context, token *domain.RefreshToken) error {
 	query := `
-		INSERT INTO refresh_tokens (id, user_id, token, expires_at, revoked_at, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO refresh_tokens (id, user_id, token, family_id, parent_id, user_agent, ip_address, expires_at, revoked_at, last_used_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 	`
 
 	_, err := r.db.ExecContext(ctx, query,
 		token.ID,
 		token.UserID,
 		token.Token,
+		token.FamilyID,
+		token.ParentID,
+		token.UserAgent,
+		token.IPAddress,
 		token.ExpiresAt,
 		token.RevokedAt,
+		token.LastUsedAt,
 		token.CreatedAt,
 	)
 
@@ -405,7 +515,7 @@ func (r *PostgreSQLAuthRepository) CreateRefreshToken(ctx context.Context, token
 // GetRefreshToken retrieves a refresh token by its token string
 func (r *PostgreSQLAuthRepository) GetRefreshToken(ctx context.Context, token string) (*domain.RefreshToken, error) {
 	query := `
-		SELECT id, user_id, token, expires_at, revoked_at, created_at
+		SELECT id, user_id, token, family_id, parent_id, user_agent, ip_address, expires_at, revoked_at, last_used_at, created_at
 		FROM refresh_tokens
 		WHERE token = $1
 	`
@@ -448,6 +558,133 @@ func (r *PostgreSQLAuthRepository) RevokeRefreshToken(ctx context.Context, token
 	return nil
 }
 
+// RotateRefreshToken revokes the parent token and persists its replacement atomically
+func (r *PostgreSQLAuthRepository) RotateRefreshToken(ctx context.Context, parentID string, token *domain.RefreshToken) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Only an unrevoked parent can be rotated, so concurrent refreshes can't both succeed
+	revokeQuery := `
+		UPDATE refresh_tokens
+		SET revoked_at = $2
+		WHERE id = $1 AND revoked_at IS NULL
+	`
+
+	result, err := tx.ExecContext(ctx, revokeQuery, parentID, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to revoke parent refresh token: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ports.ErrTokenRevoked
+	}
+
+	insertQuery := `
+		INSERT INTO refresh_tokens (id, user_id, token, family_id, parent_id, user_agent, ip_address, expires_at, revoked_at, last_used_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+
+	_, err = tx.ExecContext(ctx, insertQuery,
+		token.ID,
+		token.UserID,
+		token.Token,
+		token.FamilyID,
+		token.ParentID,
+		token.UserAgent,
+		token.IPAddress,
+		token.ExpiresAt,
+		token.RevokedAt,
+		token.LastUsedAt,
+		token.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create rotated refresh token: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// IsRefreshTokenRotated checks whether a refresh token was already exchanged for a new one
+func (r *PostgreSQLAuthRepository) IsRefreshTokenRotated(ctx context.Context, tokenID string) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM refresh_tokens WHERE parent_id = $1)`
+
+	var rotated bool
+	err := r.db.GetContext(ctx, &rotated, query, tokenID)
+	if err != nil {
+		return false, fmt.Errorf("failed to check refresh token rotation: %w", err)
+	}
+
+	return rotated, nil
+}
+
+// ListUserSessions retrieves the active sessions of a user, one per refresh token family
+func (r *PostgreSQLAuthRepository) ListUserSessions(ctx context.Context, userID string) ([]*domain.Session, error) {
+	// The active token of a family carries the latest device metadata;
+	// the oldest token of the family gives the login time
+	query := `
+		SELECT rt.family_id AS id,
+		       rt.user_agent,
+		       rt.ip_address,
+		       f.created_at,
+		       COALESCE(rt.last_used_at, rt.created_at) AS last_used_at,
+		       rt.expires_at
+		FROM refresh_tokens rt
+		JOIN (
+			SELECT family_id, MIN(created_at) AS created_at
+			FROM refresh_tokens
+			WHERE user_id = $1
+			GROUP BY family_id
+		) f ON f.family_id = rt.family_id
+		WHERE rt.user_id = $1 AND rt.revoked_at IS NULL AND rt.expires_at > $2
+		ORDER BY last_used_at DESC
+	`
+
+	sessions := []*domain.Session{}
+	err := r.db.SelectContext(ctx, &sessions, query, userID, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list user sessions: %w", err)
+	}
+
+	return sessions, nil
+}
+
+// RevokeUserSession revokes every token of a session (refresh token family) owned by the user
+func (r *PostgreSQLAuthRepository) RevokeUserSession(ctx context.Context, userID string, sessionID string) error {
+	query := `
+		UPDATE refresh_tokens
+		SET revoked_at = $3
+		WHERE user_id = $1 AND family_id = $2 AND revoked_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, userID, sessionID, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to revoke user session: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ports.ErrSessionNotFound
+	}
+
+	return nil
+}
+
 // RevokeAllUserRefreshTokens revokes all refresh tokens for a specific user
 func (r *PostgreSQLAuthRepository) RevokeAllUserRefreshTokens(ctx context.Context, userID string) error {
 	query := `
@@ -478,57 +715,1723 @@ func (r *PostgreSQLAuthRepository) DeleteExpiredRefreshTokens(ctx context.Contex
 	return nil
 }
 
-// Utility operations
+// Multi-factor authentication operations
 
-// EmailExists checks if an email address is already registered
-func (r *PostgreSQLAuthRepository) EmailExists(ctx context.Context, email string) (bool, error) {
-	query := `SELECT EXISTS(SELECT 1 FROM users WHERE email = $1)`
+// GetUserMFA retrieves a user's TOTP enrollment
+func (r *PostgreSQLAuthRepository) GetUserMFA(ctx context.Context, userID string) (*domain.UserMFA, error) {
+	query := `
+		SELECT user_id, secret, enabled, last_used_step, enabled_at, created_at, updated_at
+		FROM user_mfa
+		WHERE user_id = $1
+	`
 
-	var exists bool
-	err := r.db.GetContext(ctx, &exists, query, email)
+	var mfa domain.UserMFA
+	err := r.db.GetContext(ctx, &mfa, query, userID)
 	if err != nil {
-		return false, fmt.Errorf("failed to check email existence: %w", err)
+		if err == sql.ErrNoRows {
+			return nil, ports.ErrMFANotEnrolled
+		}
+		return nil, fmt.Errorf("failed to get user MFA: %w", err)
 	}
 
-	return exists, nil
+	return &mfa, nil
 }
 
-// EmailExistsExcludingUser checks if an email exists for any user except the specified one
-func (r *PostgreSQLAuthRepository) EmailExistsExcludingUser(ctx context.Context, email string, userID string) (bool, error) {
-	query := `SELECT EXISTS(SELECT 1 FROM users WHERE email = $1 AND id != $2)`
+// SaveUserMFA creates or replaces a user's TOTP enrollment
+func (r *PostgreSQLAuthRepository) SaveUserMFA(ctx context.Context, mfa *domain.UserMFA) error {
+	query := `
+		INSERT INTO user_mfa (user_id, secret, enabled, last_used_step, enabled_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (user_id) DO UPDATE
+		SET secret = EXCLUDED.secret,
+		    enabled = EXCLUDED.enabled,
+		    last_used_step = EXCLUDED.last_used_step,
+		    enabled_at = EXCLUDED.enabled_at,
+		    updated_at = EXCLUDED.updated_at
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		mfa.UserID,
+		mfa.Secret,
+		mfa.Enabled,
+		mfa.LastUsedStep,
+		mfa.EnabledAt,
+		mfa.CreatedAt,
+		mfa.UpdatedAt,
+	)
 
-	var exists bool
-	err := r.db.GetContext(ctx, &exists, query, email, userID)
 	if err != nil {
-		return false, fmt.Errorf("failed to check email existence: %w", err)
+		return fmt.Errorf("failed to save user MFA: %w", err)
 	}
 
-	return exists, nil
+	return nil
 }
 
-// GetFirstActiveMembership retrieves the first active tenant membership for a user
-// This is used during login to determine the user's tenant when they don't have
-// a tenant_id set in their user record
-func (r *PostgreSQLAuthRepository) GetFirstActiveMembership(ctx context.Context, userID string) (*domain.UserMembership, error) {
+// EnableUserMFA marks a user's TOTP enrollment as enabled
+func (r *PostgreSQLAuthRepository) EnableUserMFA(ctx context.Context, userID string) error {
 	query := `
-		SELECT tenant_id, role, status
-		FROM tenant_memberships
-		WHERE user_id = $1 AND status = 'active'
-		ORDER BY joined_at DESC
-		LIMIT 1
+		UPDATE user_mfa
+		SET enabled = true, enabled_at = $2
+		WHERE user_id = $1
 	`
 
-	var membership domain.UserMembership
-	err := r.db.GetContext(ctx, &membership, query, userID)
+	result, err := r.db.ExecContext(ctx, query, userID, time.Now())
 	if err != nil {
-		if err == sql.ErrNoRows {
-			// No active membership found, this is OK
-			return nil, nil
+		return fmt.Errorf("failed to enable user MFA: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ports.ErrMFANotEnrolled
+	}
+
+	return nil
+}
+
+// UpdateMFALastUsedStep records the last accepted TOTP time step
+// The update only succeeds for newer steps, so a code can't be used twice
+func (r *PostgreSQLAuthRepository) UpdateMFALastUsedStep(ctx context.Context, userID string, step int64) error {
+	query := `
+		UPDATE user_mfa
+		SET last_used_step = $2
+		WHERE user_id = $1 AND last_used_step < $2
+	`
+
+	result, err := r.db.ExecContext(ctx, query, userID, step)
+	if err != nil {
+		return fmt.Errorf("failed to update MFA last used step: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ports.ErrMFACodeInvalid
+	}
+
+	return nil
+}
+
+// DeleteUserMFA removes a user's TOTP enrollment and recovery codes
+func (r *PostgreSQLAuthRepository) DeleteUserMFA(ctx context.Context, userID string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_mfa WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to delete user MFA: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// ReplaceRecoveryCodes deletes existing recovery codes and stores the given hashes
+func (r *PostgreSQLAuthRepository) ReplaceRecoveryCodes(ctx context.Context, userID string, codeHashes []string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+
+	query := `
+		INSERT INTO mfa_recovery_codes (user_id, code_hash, created_at)
+		VALUES ($1, $2, $3)
+	`
+
+	now := time.Now()
+	for _, codeHash := range codeHashes {
+		if _, err := tx.ExecContext(ctx, query, userID, codeHash, now); err != nil {
+			return fmt.Errorf("failed to create recovery code: %w", err)
 		}
-		return nil, fmt.Errorf("failed to get first active membership: %w", err)
 	}
 
-	return &membership, nil
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// UseRecoveryCode marks an unused recovery code as used
+func (r *PostgreSQLAuthRepository) UseRecoveryCode(ctx context.Context, userID string, codeHash string) error {
+	query := `
+		UPDATE mfa_recovery_codes
+		SET used_at = $3
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, userID, codeHash, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to use recovery code: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ports.ErrMFARecoveryCodeInvalid
+	}
+
+	return nil
+}
+
+// CountUnusedRecoveryCodes returns the number of recovery codes still available
+func (r *PostgreSQLAuthRepository) CountUnusedRecoveryCodes(ctx context.Context, userID string) (int, error) {
+	query := `SELECT COUNT(*) FROM mfa_recovery_codes WHERE user_id = $1 AND used_at IS NULL`
+
+	var count int
+	if err := r.db.GetContext(ctx, &count, query, userID); err != nil {
+		return 0, fmt.Errorf("failed to count recovery codes: %w", err)
+	}
+
+	return count, nil
+}
+
+// CreateMFAChallenge persists a new login MFA challenge
+func (r *PostgreSQLAuthRepository) CreateMFAChallenge(ctx context.Context, challenge *domain.MFAChallenge) error {
+	query := `
+		INSERT INTO mfa_challenges (id, user_id, token_hash, attempts, enrollment_required, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		challenge.ID,
+		challenge.UserID,
+		challenge.TokenHash,
+		challenge.Attempts,
+		challenge.EnrollmentRequired,
+		challenge.ExpiresAt,
+		challenge.CreatedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create MFA challenge: %w", err)
+	}
+
+	return nil
+}
+
+// GetMFAChallenge retrieves a login MFA challenge by the hash of its token
+func (r *PostgreSQLAuthRepository) GetMFAChallenge(ctx context.Context, tokenHash string) (*domain.MFAChallenge, error) {
+	query := `
+		SELECT id, user_id, token_hash, attempts, enrollment_required, expires_at, created_at
+		FROM mfa_challenges
+		WHERE token_hash = $1
+	`
+
+	var challenge domain.MFAChallenge
+	err := r.db.GetContext(ctx, &challenge, query, tokenHash)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ports.ErrTokenNotFound
+		}
+		return nil, fmt.Errorf("failed to get MFA challenge: %w", err)
+	}
+
+	return &challenge, nil
+}
+
+// IncrementMFAChallengeAttempts increments the failed attempts counter of a challenge
+func (r *PostgreSQLAuthRepository) IncrementMFAChallengeAttempts(ctx context.Context, challengeID string) error {
+	query := `UPDATE mfa_challenges SET attempts = attempts + 1 WHERE id = $1`
+
+	if _, err := r.db.ExecContext(ctx, query, challengeID); err != nil {
+		return fmt.Errorf("failed to increment MFA challenge attempts: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteMFAChallenge removes a login MFA challenge by its ID
+func (r *PostgreSQLAuthRepository) DeleteMFAChallenge(ctx context.Context, challengeID string) error {
+	query := `DELETE FROM mfa_challenges WHERE id = $1`
+
+	if _, err := r.db.ExecContext(ctx, query, challengeID); err != nil {
+		return fmt.Errorf("failed to delete MFA challenge: %w", err)
+	}
+
+	return nil
+}
+
+// GetTenantMFARequiredRoles retrieves the membership roles that must use MFA in a tenant
+func (r *PostgreSQLAuthRepository) GetTenantMFARequiredRoles(ctx context.Context, tenantID string) ([]string, error) {
+	query := `SELECT mfa_required_roles FROM tenants WHERE id = $1`
+
+	var roles pq.StringArray
+	err := r.db.GetContext(ctx, &roles, query, tenantID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ports.ErrTenantNotFound
+		}
+		return nil, fmt.Errorf("failed to get tenant MFA policy: %w", err)
+	}
+
+	return []string(roles), nil
+}
+
+// UpdateTenantMFARequiredRoles replaces the membership roles that must use MFA in a tenant
+func (r *PostgreSQLAuthRepository) UpdateTenantMFARequiredRoles(ctx context.Context, tenantID string, roles []string) error {
+	query := `
+		UPDATE tenants
+		SET mfa_required_roles = $2, updated_at = $3
+		WHERE id = $1
+	`
+
+	result, err := r.db.ExecContext(ctx, query, tenantID, pq.StringArray(roles), time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to update tenant MFA policy: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ports.ErrTenantNotFound
+	}
+
+	return nil
+}
+
+// OpenID Connect operations
+
+// GetOIDCProvider retrieves the OIDC identity provider configured for a tenant
+func (r *PostgreSQLAuthRepository) GetOIDCProvider(ctx context.Context, tenantID string) (*domain.OIDCProvider, error) {
+	query := `
+		SELECT tenant_id, issuer, client_id, client_secret, redirect_uri, scopes, role_claim,
+		       role_mapping, default_role, enabled, created_at, updated_at
+		FROM tenant_oidc_providers
+		WHERE tenant_id = $1
+	`
+
+	var provider domain.OIDCProvider
+	var roleMappingJSON []byte
+	err := r.db.QueryRowContext(ctx, query, tenantID).Scan(
+		&provider.TenantID,
+		&provider.Issuer,
+		&provider.ClientID,
+		&provider.ClientSecret,
+		&provider.RedirectURI,
+		&provider.Scopes,
+		&provider.RoleClaim,
+		&roleMappingJSON,
+		&provider.DefaultRole,
+		&provider.Enabled,
+		&provider.CreatedAt,
+		&provider.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ports.ErrOIDCNotConfigured
+		}
+		return nil, fmt.Errorf("failed to get OIDC provider: %w", err)
+	}
+
+	provider.RoleMapping = map[string]string{}
+	if len(roleMappingJSON) > 0 {
+		if err := json.Unmarshal(roleMappingJSON, &provider.RoleMapping); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal OIDC role mapping: %w", err)
+		}
+	}
+
+	return &provider, nil
+}
+
+// SaveOIDCProvider creates or replaces the OIDC identity provider of a tenant
+func (r *PostgreSQLAuthRepository) SaveOIDCProvider(ctx context.Context, provider *domain.OIDCProvider) error {
+	roleMapping := provider.RoleMapping
+	if roleMapping == nil {
+		roleMapping = map[string]string{}
+	}
+	roleMappingJSON, err := json.Marshal(roleMapping)
+	if err != nil {
+		return fmt.Errorf("failed to marshal OIDC role mapping: %w", err)
+	}
+
+	query := `
+		INSERT INTO tenant_oidc_providers (
+			tenant_id, issuer, client_id, client_secret, redirect_uri, scopes, role_claim,
+			role_mapping, default_role, enabled, created_at, updated_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		ON CONFLICT (tenant_id) DO UPDATE SET
+			issuer = EXCLUDED.issuer,
+			client_id = EXCLUDED.client_id,
+			client_secret = EXCLUDED.client_secret,
+			redirect_uri = EXCLUDED.redirect_uri,
+			scopes = EXCLUDED.scopes,
+			role_claim = EXCLUDED.role_claim,
+			role_mapping = EXCLUDED.role_mapping,
+			default_role = EXCLUDED.default_role,
+			enabled = EXCLUDED.enabled,
+			updated_at = EXCLUDED.updated_at
+	`
+
+	_, err = r.db.ExecContext(ctx, query,
+		provider.TenantID,
+		provider.Issuer,
+		provider.ClientID,
+		provider.ClientSecret,
+		provider.RedirectURI,
+		provider.Scopes,
+		provider.RoleClaim,
+		roleMappingJSON,
+		provider.DefaultRole,
+		provider.Enabled,
+		provider.CreatedAt,
+		provider.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save OIDC provider: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteOIDCProvider removes the OIDC identity provider of a tenant
+func (r *PostgreSQLAuthRepository) DeleteOIDCProvider(ctx context.Context, tenantID string) error {
+	query := `DELETE FROM tenant_oidc_providers WHERE tenant_id = $1`
+
+	result, err := r.db.ExecContext(ctx, query, tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to delete OIDC provider: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ports.ErrOIDCNotConfigured
+	}
+
+	return nil
+}
+
+// CreateOIDCAuthRequest persists a pending OIDC authorization request
+func (r *PostgreSQLAuthRepository) CreateOIDCAuthRequest(ctx context.Context, request *domain.OIDCAuthRequest) error {
+	query := `
+		INSERT INTO oidc_auth_requests (id, tenant_id, state, nonce, code_verifier, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		request.ID,
+		request.TenantID,
+		request.State,
+		request.Nonce,
+		request.CodeVerifier,
+		request.ExpiresAt,
+		request.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create OIDC authorization request: %w", err)
+	}
+
+	return nil
+}
+
+// ConsumeOIDCAuthRequest retrieves and deletes a pending OIDC authorization request by its state
+func (r *PostgreSQLAuthRepository) ConsumeOIDCAuthRequest(ctx context.Context, state string) (*domain.OIDCAuthRequest, error) {
+	query := `
+		DELETE FROM oidc_auth_requests
+		WHERE state = $1
+		RETURNING id, tenant_id, state, nonce, code_verifier, expires_at, created_at
+	`
+
+	var request domain.OIDCAuthRequest
+	err := r.db.GetContext(ctx, &request, query, state)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ports.ErrOIDCStateInvalid
+		}
+		return nil, fmt.Errorf("failed to consume OIDC authorization request: %w", err)
+	}
+
+	return &request, nil
+}
+
+// GetUserIdentity retrieves the user linked to a subject at an identity provider
+func (r *PostgreSQLAuthRepository) GetUserIdentity(ctx context.Context, issuer string, subject string) (*domain.UserIdentity, error) {
+	query := `
+		SELECT id, user_id, tenant_id, issuer, subject, email, last_login_at, created_at
+		FROM user_identities
+		WHERE issuer = $1 AND subject = $2
+	`
+
+	var identity domain.UserIdentity
+	err := r.db.GetContext(ctx, &identity, query, issuer, subject)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			// No linked user yet, this is OK
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get user identity: %w", err)
+	}
+
+	return &identity, nil
+}
+
+// CreateUserIdentity links an existing user to a subject at an identity provider
+func (r *PostgreSQLAuthRepository) CreateUserIdentity(ctx context.Context, identity *domain.UserIdentity) error {
+	if err := insertUserIdentity(ctx, r.db, identity); err != nil {
+		return fmt.Errorf("failed to create user identity: %w", err)
+	}
+	return nil
+}
+
+// UpdateUserIdentityLogin records a login through an external identity
+func (r *PostgreSQLAuthRepository) UpdateUserIdentityLogin(ctx context.Context, identityID string, email string) error {
+	query := `UPDATE user_identities SET email = $2, last_login_at = $3 WHERE id = $1`
+
+	if _, err := r.db.ExecContext(ctx, query, identityID, email, time.Now()); err != nil {
+		return fmt.Errorf("failed to update user identity: %w", err)
+	}
+
+	return nil
+}
+
+// CreateFederatedUser creates a user provisioned by an identity provider together
+// with its identity link and tenant membership in a single transaction
+func (r *PostgreSQLAuthRepository) CreateFederatedUser(ctx context.Context, user *domain.User, identity *domain.UserIdentity, role string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var tenantID sql.NullString
+	if user.TenantID != nil {
+		tenantID = sql.NullString{String: *user.TenantID, Valid: true}
+	}
+	user.InitializeRoles()
+
+	userQuery := `
+		INSERT INTO users (id, tenant_id, email, password_hash, full_name, roles, active_role, is_verified, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+	if _, err := tx.ExecContext(ctx, userQuery,
+		user.ID,
+		tenantID,
+		user.Email,
+		user.PasswordHash,
+		user.FullName,
+		user.Roles,
+		user.ActiveRole,
+		user.IsVerified,
+		user.CreatedAt,
+		user.UpdatedAt,
+	); err != nil {
+		return fmt.Errorf("failed to create user: %w", err)
+	}
+
+	if err := insertUserIdentity(ctx, tx, identity); err != nil {
+		return fmt.Errorf("failed to create user identity: %w", err)
+	}
+
+	membershipQuery := `
+		INSERT INTO tenant_memberships (user_id, tenant_id, role, status, joined_at)
+		VALUES ($1, $2, $3, 'active', $4)
+	`
+	if _, err := tx.ExecContext(ctx, membershipQuery, user.ID, identity.TenantID, role, user.CreatedAt); err != nil {
+		return fmt.Errorf("failed to create membership: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// EnsureMembership creates an active membership if the user has none in the tenant
+// and returns the user's membership, whatever its status
+func (r *PostgreSQLAuthRepository) EnsureMembership(ctx context.Context, userID string, tenantID string, role string) (*domain.UserMembership, error) {
+	insertQuery := `
+		INSERT INTO tenant_memberships (user_id, tenant_id, role, status, joined_at)
+		VALUES ($1, $2, $3, 'active', $4)
+		ON CONFLICT (user_id, tenant_id) DO NOTHING
+	`
+	if _, err := r.db.ExecContext(ctx, insertQuery, userID, tenantID, role, time.Now()); err != nil {
+		return nil, fmt.Errorf("failed to create membership: %w", err)
+	}
+
+	selectQuery := `
+		SELECT tenant_id, role, status
+		FROM tenant_memberships
+		WHERE user_id = $1 AND tenant_id = $2
+	`
+
+	var membership domain.UserMembership
+	if err := r.db.GetContext(ctx, &membership, selectQuery, userID, tenantID); err != nil {
+		return nil, fmt.Errorf("failed to get membership: %w", err)
+	}
+
+	return &membership, nil
+}
+
+// UpdateMembershipRole changes the role of a user's membership in a tenant
+func (r *PostgreSQLAuthRepository) UpdateMembershipRole(ctx context.Context, userID string, tenantID string, role string) error {
+	query := `UPDATE tenant_memberships SET role = $3 WHERE user_id = $1 AND tenant_id = $2`
+
+	if _, err := r.db.ExecContext(ctx, query, userID, tenantID, role); err != nil {
+		return fmt.Errorf("failed to update membership role: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateUserTenant sets the tenant a user is currently working in
+func (r *PostgreSQLAuthRepository) UpdateUserTenant(ctx context.Context, userID string, tenantID string) error {
+	query := `UPDATE users SET tenant_id = $2, updated_at = $3 WHERE id = $1`
+
+	result, err := r.db.ExecContext(ctx, query, userID, tenantID, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to update user tenant: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ports.ErrUserNotFound
+	}
+
+	return nil
+}
+
+// HasTenantMembership checks whether a user has a membership in a tenant, whatever its status
+func (r *PostgreSQLAuthRepository) HasTenantMembership(ctx context.Context, userID string, tenantID string) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM tenant_memberships WHERE user_id = $1 AND tenant_id = $2)`
+
+	var exists bool
+	if err := r.db.GetContext(ctx, &exists, query, userID, tenantID); err != nil {
+		return false, fmt.Errorf("failed to check membership: %w", err)
+	}
+
+	return exists, nil
+}
+
+// IsEmailDomainVerified checks whether a tenant has verified one of its domains matching the email domain
+func (r *PostgreSQLAuthRepository) IsEmailDomainVerified(ctx context.Context, tenantID string, emailDomain string) (bool, error) {
+	query := `
+		SELECT EXISTS(
+			SELECT 1 FROM tenant_domains
+			WHERE tenant_id = $1 AND LOWER(domain) = LOWER($2) AND verified_at IS NOT NULL
+		)
+	`
+
+	var exists bool
+	if err := r.db.GetContext(ctx, &exists, query, tenantID, emailDomain); err != nil {
+		return false, fmt.Errorf("failed to check tenant domain: %w", err)
+	}
+
+	return exists, nil
+}
+
+// CreateFederatedLinkRequest persists a single sign-on login waiting for the user to confirm the link
+func (r *PostgreSQLAuthRepository) CreateFederatedLinkRequest(ctx context.Context, request *domain.FederatedLinkRequest) error {
+	query := `
+		INSERT INTO federated_link_requests (id, token_hash, user_id, tenant_id, issuer, subject, email, role, role_mapped, attempts, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		request.ID,
+		request.TokenHash,
+		request.UserID,
+		request.TenantID,
+		request.Issuer,
+		request.Subject,
+		request.Email,
+		request.Role,
+		request.RoleMapped,
+		request.Attempts,
+		request.ExpiresAt,
+		request.CreatedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create federated link request: %w", err)
+	}
+
+	return nil
+}
+
+// GetFederatedLinkRequest retrieves a link request by the hash of its token
+func (r *PostgreSQLAuthRepository) GetFederatedLinkRequest(ctx context.Context, tokenHash string) (*domain.FederatedLinkRequest, error) {
+	query := `
+		SELECT id, token_hash, user_id, tenant_id, issuer, subject, email, role, role_mapped, attempts, expires_at, created_at
+		FROM federated_link_requests
+		WHERE token_hash = $1
+	`
+
+	var request domain.FederatedLinkRequest
+	err := r.db.GetContext(ctx, &request, query, tokenHash)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ports.ErrTokenNotFound
+		}
+		return nil, fmt.Errorf("failed to get federated link request: %w", err)
+	}
+
+	return &request, nil
+}
+
+// IncrementFederatedLinkAttempts increments the failed confirmation attempts of a link request
+func (r *PostgreSQLAuthRepository) IncrementFederatedLinkAttempts(ctx context.Context, requestID string) error {
+	query := `UPDATE federated_link_requests SET attempts = attempts + 1 WHERE id = $1`
+
+	if _, err := r.db.ExecContext(ctx, query, requestID); err != nil {
+		return fmt.Errorf("failed to increment federated link attempts: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteFederatedLinkRequest removes a link request by its ID
+func (r *PostgreSQLAuthRepository) DeleteFederatedLinkRequest(ctx context.Context, requestID string) error {
+	query := `DELETE FROM federated_link_requests WHERE id = $1`
+
+	if _, err := r.db.ExecContext(ctx, query, requestID); err != nil {
+		return fmt.Errorf("failed to delete federated link request: %w", err)
+	}
+
+	return nil
+}
+
+// insertUserIdentity inserts an identity link using either the database or a transaction
+func insertUserIdentity(ctx context.Context, db sqlx.ExecerContext, identity *domain.UserIdentity) error {
+	query := `
+		INSERT INTO user_identities (id, user_id, tenant_id, issuer, subject, email, last_login_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	_, err := db.ExecContext(ctx, query,
+		identity.ID,
+		identity.UserID,
+		identity.TenantID,
+		identity.Issuer,
+		identity.Subject,
+		identity.Email,
+		identity.LastLoginAt,
+		identity.CreatedAt,
+	)
+	return err
+}
+
+// SAML operations
+
+// GetSAMLProvider retrieves the SAML identity provider configured for a tenant
+func (r *PostgreSQLAuthRepository) GetSAMLProvider(ctx context.Context, tenantID string) (*domain.SAMLProvider, error) {
+	query := `
+		SELECT tenant_id, idp_entity_id, idp_sso_url, idp_certificate, email_attribute, name_attribute,
+		       role_attribute, role_mapping, default_role, enabled, created_at, updated_at
+		FROM tenant_saml_providers
+		WHERE tenant_id = $1
+	`
+
+	var provider domain.SAMLProvider
+	var roleMappingJSON []byte
+	err := r.db.QueryRowContext(ctx, query, tenantID).Scan(
+		&provider.TenantID,
+		&provider.IdPEntityID,
+		&provider.IdPSSOURL,
+		&provider.IdPCertificate,
+		&provider.EmailAttribute,
+		&provider.NameAttribute,
+		&provider.RoleAttribute,
+		&roleMappingJSON,
+		&provider.DefaultRole,
+		&provider.Enabled,
+		&provider.CreatedAt,
+		&provider.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ports.ErrSAMLNotConfigured
+		}
+		return nil, fmt.Errorf("failed to get SAML provider: %w", err)
+	}
+
+	provider.RoleMapping = map[string]string{}
+	if len(roleMappingJSON) > 0 {
+		if err := json.Unmarshal(roleMappingJSON, &provider.RoleMapping); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal SAML role mapping: %w", err)
+		}
+	}
+
+	return &provider, nil
+}
+
+// SaveSAMLProvider creates or replaces the SAML identity provider of a tenant
+func (r *PostgreSQLAuthRepository) SaveSAMLProvider(ctx context.Context, provider *domain.SAMLProvider) error {
+	roleMapping := provider.RoleMapping
+	if roleMapping == nil {
+		roleMapping = map[string]string{}
+	}
+	roleMappingJSON, err := json.Marshal(roleMapping)
+	if err != nil {
+		return fmt.Errorf("failed to marshal SAML role mapping: %w", err)
+	}
+
+	query := `
+		INSERT INTO tenant_saml_providers (
+			tenant_id, idp_entity_id, idp_sso_url, idp_certificate, email_attribute, name_attribute,
+			role_attribute, role_mapping, default_role, enabled, created_at, updated_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		ON CONFLICT (tenant_id) DO UPDATE SET
+			idp_entity_id = EXCLUDED.idp_entity_id,
+			idp_sso_url = EXCLUDED.idp_sso_url,
+			idp_certificate = EXCLUDED.idp_certificate,
+			email_attribute = EXCLUDED.email_attribute,
+			name_attribute = EXCLUDED.name_attribute,
+			role_attribute = EXCLUDED.role_attribute,
+			role_mapping = EXCLUDED.role_mapping,
+			default_role = EXCLUDED.default_role,
+			enabled = EXCLUDED.enabled,
+			updated_at = EXCLUDED.updated_at
+	`
+
+	_, err = r.db.ExecContext(ctx, query,
+		provider.TenantID,
+		provider.IdPEntityID,
+		provider.IdPSSOURL,
+		provider.IdPCertificate,
+		provider.EmailAttribute,
+		provider.NameAttribute,
+		provider.RoleAttribute,
+		roleMappingJSON,
+		provider.DefaultRole,
+		provider.Enabled,
+		provider.CreatedAt,
+		provider.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save SAML provider: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteSAMLProvider removes the SAML identity provider of a tenant
+func (r *PostgreSQLAuthRepository) DeleteSAMLProvider(ctx context.Context, tenantID string) error {
+	query := `DELETE FROM tenant_saml_providers WHERE tenant_id = $1`
+
+	result, err := r.db.ExecContext(ctx, query, tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to delete SAML provider: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ports.ErrSAMLNotConfigured
+	}
+
+	return nil
+}
+
+// CreateSAMLAuthRequest persists the ID of a pending AuthnRequest
+func (r *PostgreSQLAuthRepository) CreateSAMLAuthRequest(ctx context.Context, request *domain.SAMLAuthRequest) error {
+	query := `
+		INSERT INTO saml_auth_requests (id, tenant_id, expires_at, created_at)
+		VALUES ($1, $2, $3, $4)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		request.ID,
+		request.TenantID,
+		request.ExpiresAt,
+		request.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create SAML authentication request: %w", err)
+	}
+
+	return nil
+}
+
+// ConsumeSAMLAuthRequest retrieves and deletes a pending AuthnRequest by its ID
+func (r *PostgreSQLAuthRepository) ConsumeSAMLAuthRequest(ctx context.Context, requestID string) (*domain.SAMLAuthRequest, error) {
+	query := `
+		DELETE FROM saml_auth_requests
+		WHERE id = $1
+		RETURNING id, tenant_id, expires_at, created_at
+	`
+
+	var request domain.SAMLAuthRequest
+	err := r.db.GetContext(ctx, &request, query, requestID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ports.ErrSAMLRequestInvalid
+		}
+		return nil, fmt.Errorf("failed to consume SAML authentication request: %w", err)
+	}
+
+	return &request, nil
+}
+
+// CreateSAMLLoginCode persists a single-use login code
+func (r *PostgreSQLAuthRepository) CreateSAMLLoginCode(ctx context.Context, code *domain.SAMLLoginCode) error {
+	query := `
+		INSERT INTO saml_login_codes (id, code, user_id, tenant_id, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		code.ID,
+		code.Code,
+		code.UserID,
+		code.TenantID,
+		code.ExpiresAt,
+		code.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create SAML login code: %w", err)
+	}
+
+	return nil
+}
+
+// ConsumeSAMLLoginCode retrieves and deletes a login code
+func (r *PostgreSQLAuthRepository) ConsumeSAMLLoginCode(ctx context.Context, code string) (*domain.SAMLLoginCode, error) {
+	query := `
+		DELETE FROM saml_login_codes
+		WHERE code = $1
+		RETURNING id, code, user_id, tenant_id, expires_at, created_at
+	`
+
+	var loginCode domain.SAMLLoginCode
+	err := r.db.GetContext(ctx, &loginCode, query, code)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ports.ErrSAMLLoginCodeInvalid
+		}
+		return nil, fmt.Errorf("failed to consume SAML login code: %w", err)
+	}
+
+	return &loginCode, nil
+}
+
+// WebAuthn operations
+
+// webAuthnCredentialColumns lists the columns selected for a WebAuthn credential
+const webAuthnCredentialColumns = `
+	id, user_id, credential_id, public_key, sign_count, aaguid, transports,
+	backup_eligible, name, last_used_at, created_at
+`
+
+// CreateWebAuthnCredential persists a newly registered passkey or security key
+func (r *PostgreSQLAuthRepository) CreateWebAuthnCredential(ctx context.Context, credential *domain.WebAuthnCredential) error {
+	query := `
+		INSERT INTO webauthn_credentials (id, user_id, credential_id, public_key, sign_count, aaguid, transports, backup_eligible, name, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		credential.ID,
+		credential.UserID,
+		credential.CredentialID,
+		credential.PublicKey,
+		credential.SignCount,
+		credential.AAGUID,
+		credential.Transports,
+		credential.BackupEligible,
+		credential.Name,
+		credential.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create WebAuthn credential: %w", err)
+	}
+
+	return nil
+}
+
+// GetWebAuthnCredential retrieves a credential by its base64url credential ID
+func (r *PostgreSQLAuthRepository) GetWebAuthnCredential(ctx context.Context, credentialID string) (*domain.WebAuthnCredential, error) {
+	query := `SELECT ` + webAuthnCredentialColumns + ` FROM webauthn_credentials WHERE credential_id = $1`
+
+	var credential domain.WebAuthnCredential
+	err := r.db.GetContext(ctx, &credential, query, credentialID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ports.ErrWebAuthnCredentialNotFound
+		}
+		return nil, fmt.Errorf("failed to get WebAuthn credential: %w", err)
+	}
+
+	return &credential, nil
+}
+
+// ListWebAuthnCredentials lists the credentials registered by a user
+func (r *PostgreSQLAuthRepository) ListWebAuthnCredentials(ctx context.Context, userID string) ([]*domain.WebAuthnCredential, error) {
+	query := `
+		SELECT ` + webAuthnCredentialColumns + `
+		FROM webauthn_credentials
+		WHERE user_id = $1
+		ORDER BY created_at ASC
+	`
+
+	credentials := []*domain.WebAuthnCredential{}
+	if err := r.db.SelectContext(ctx, &credentials, query, userID); err != nil {
+		return nil, fmt.Errorf("failed to list WebAuthn credentials: %w", err)
+	}
+
+	return credentials, nil
+}
+
+// CountWebAuthnCredentials returns the number of credentials registered by a user
+func (r *PostgreSQLAuthRepository) CountWebAuthnCredentials(ctx context.Context, userID string) (int, error) {
+	query := `SELECT COUNT(*) FROM webauthn_credentials WHERE user_id = $1`
+
+	var count int
+	if err := r.db.GetContext(ctx, &count, query, userID); err != nil {
+		return 0, fmt.Errorf("failed to count WebAuthn credentials: %w", err)
+	}
+
+	return count, nil
+}
+
+// UpdateWebAuthnCredentialUse stores the signature counter and time of the latest use of a credential
+func (r *PostgreSQLAuthRepository) UpdateWebAuthnCredentialUse(ctx context.Context, id string, signCount int64) error {
+	query := `UPDATE webauthn_credentials SET sign_count = $2, last_used_at = $3 WHERE id = $1`
+
+	if _, err := r.db.ExecContext(ctx, query, id, signCount, time.Now()); err != nil {
+		return fmt.Errorf("failed to update WebAuthn credential: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteWebAuthnCredential removes one of the user's credentials
+func (r *PostgreSQLAuthRepository) DeleteWebAuthnCredential(ctx context.Context, userID string, id string) error {
+	query := `DELETE FROM webauthn_credentials WHERE user_id = $1 AND id = $2`
+
+	result, err := r.db.ExecContext(ctx, query, userID, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete WebAuthn credential: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ports.ErrWebAuthnCredentialNotFound
+	}
+
+	return nil
+}
+
+// CreateWebAuthnChallenge persists the challenge of a pending ceremony
+func (r *PostgreSQLAuthRepository) CreateWebAuthnChallenge(ctx context.Context, challenge *domain.WebAuthnChallenge) error {
+	query := `
+		INSERT INTO webauthn_challenges (id, user_id, challenge, ceremony, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		challenge.ID,
+		challenge.UserID,
+		challenge.Challenge,
+		challenge.Ceremony,
+		challenge.ExpiresAt,
+		challenge.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create WebAuthn challenge: %w", err)
+	}
+
+	return nil
+}
+
+// ConsumeWebAuthnChallenge retrieves and deletes a pending challenge of the given ceremony
+func (r *PostgreSQLAuthRepository) ConsumeWebAuthnChallenge(ctx context.Context, challenge string, ceremony domain.WebAuthnCeremony) (*domain.WebAuthnChallenge, error) {
+	query := `
+		DELETE FROM webauthn_challenges
+		WHERE challenge = $1 AND ceremony = $2
+		RETURNING id, user_id, challenge, ceremony, expires_at, created_at
+	`
+
+	var pending domain.WebAuthnChallenge
+	err := r.db.GetContext(ctx, &pending, query, challenge, ceremony)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ports.ErrWebAuthnChallengeInvalid
+		}
+		return nil, fmt.Errorf("failed to consume WebAuthn challenge: %w", err)
+	}
+
+	return &pending, nil
+}
+
+// Impersonation operations
+
+// impersonationSessionColumns lists the columns selected for an impersonation session
+const impersonationSessionColumns = `
+	id, tenant_id, impersonator_id, target_user_id, reason, token_id,
+	ip_address, user_agent, expires_at, ended_at, created_at
+`
+
+// CreateImpersonationSession persists a new impersonation session
+func (r *PostgreSQLAuthRepository) CreateImpersonationSession(ctx context.Context, session *domain.ImpersonationSession) error {
+	query := `
+		INSERT INTO impersonation_sessions (id, tenant_id, impersonator_id, target_user_id, reason, token_id, ip_address, user_agent, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		session.ID,
+		session.TenantID,
+		session.ImpersonatorID,
+		session.TargetUserID,
+		session.Reason,
+		session.TokenID,
+		session.IPAddress,
+		session.UserAgent,
+		session.ExpiresAt,
+		session.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create impersonation session: %w", err)
+	}
+
+	return nil
+}
+
+// GetImpersonationSessionByTokenID retrieves the session of an impersonation access token by its jti
+func (r *PostgreSQLAuthRepository) GetImpersonationSessionByTokenID(ctx context.Context, tokenID string) (*domain.ImpersonationSession, error) {
+	query := `SELECT ` + impersonationSessionColumns + ` FROM impersonation_sessions WHERE token_id = $1`
+
+	var session domain.ImpersonationSession
+	err := r.db.GetContext(ctx, &session, query, tokenID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ports.ErrImpersonationSessionInvalid
+		}
+		return nil, fmt.Errorf("failed to get impersonation session: %w", err)
+	}
+
+	return &session, nil
+}
+
+// EndImpersonationSession marks an active session as stopped
+func (r *PostgreSQLAuthRepository) EndImpersonationSession(ctx context.Context, sessionID string) error {
+	query := `UPDATE impersonation_sessions SET ended_at = $2 WHERE id = $1 AND ended_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, sessionID, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to end impersonation session: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ports.ErrImpersonationSessionInvalid
+	}
+
+	return nil
+}
+
+// ListImpersonationSessions lists the impersonation sessions of a tenant
+func (r *PostgreSQLAuthRepository) ListImpersonationSessions(ctx context.Context, tenantID string) ([]*domain.ImpersonationSession, error) {
+	query := `
+		SELECT ` + impersonationSessionColumns + `
+		FROM impersonation_sessions
+		WHERE tenant_id = $1
+		ORDER BY created_at DESC
+	`
+
+	sessions := []*domain.ImpersonationSession{}
+	if err := r.db.SelectContext(ctx, &sessions, query, tenantID); err != nil {
+		return nil, fmt.Errorf("failed to list impersonation sessions: %w", err)
+	}
+
+	return sessions, nil
+}
+
+// RecordImpersonationEvent adds an entry to the impersonation audit trail
+func (r *PostgreSQLAuthRepository) RecordImpersonationEvent(ctx context.Context, event *domain.ImpersonationEvent) error {
+	query := `
+		INSERT INTO impersonation_events (id, session_id, type, method, path, status_code, ip_address, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		event.ID,
+		event.SessionID,
+		event.Type,
+		event.Method,
+		event.Path,
+		event.StatusCode,
+		event.IPAddress,
+		event.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record impersonation event: %w", err)
+	}
+
+	return nil
+}
+
+// ListImpersonationEvents lists the audit trail of a session of the tenant
+func (r *PostgreSQLAuthRepository) ListImpersonationEvents(ctx context.Context, tenantID string, sessionID string) ([]*domain.ImpersonationEvent, error) {
+	var exists bool
+	existsQuery := `SELECT EXISTS(SELECT 1 FROM impersonation_sessions WHERE id = $1 AND tenant_id = $2)`
+	if err := r.db.GetContext(ctx, &exists, existsQuery, sessionID, tenantID); err != nil {
+		return nil, fmt.Errorf("failed to get impersonation session: %w", err)
+	}
+	if !exists {
+		return nil, ports.ErrImpersonationSessionNotFound
+	}
+
+	query := `
+		SELECT id, session_id, type, method, path, status_code, ip_address, created_at
+		FROM impersonation_events
+		WHERE session_id = $1
+		ORDER BY created_at ASC
+	`
+
+	events := []*domain.ImpersonationEvent{}
+	if err := r.db.SelectContext(ctx, &events, query, sessionID); err != nil {
+		return nil, fmt.Errorf("failed to list impersonation events: %w", err)
+	}
+
+	return events, nil
+}
+
+// API token operations
+
+// apiTokenColumns lists the columns selected for an API token
+const apiTokenColumns = `
+	id, type, tenant_id, user_id, name, token_hash, token_prefix, scopes,
+	expires_at, last_used_at, last_used_ip, revoked_at, created_at
+`
+
+// CreateAPIToken persists a personal access token or tenant API key
+func (r *PostgreSQLAuthRepository) CreateAPIToken(ctx context.Context, token *domain.APIToken) error {
+	query := `
+		INSERT INTO api_tokens (id, type, tenant_id, user_id, name, token_hash, token_prefix, scopes, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		token.ID,
+		token.Type,
+		token.TenantID,
+		token.UserID,
+		token.Name,
+		token.TokenHash,
+		token.TokenPrefix,
+		token.Scopes,
+		token.ExpiresAt,
+		token.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create API token: %w", err)
+	}
+
+	return nil
+}
+
+// GetAPITokenByHash retrieves a token by the hash of its plaintext value
+func (r *PostgreSQLAuthRepository) GetAPITokenByHash(ctx context.Context, tokenHash string) (*domain.APIToken, error) {
+	query := `SELECT ` + apiTokenColumns + ` FROM api_tokens WHERE token_hash = $1`
+
+	var token domain.APIToken
+	err := r.db.GetContext(ctx, &token, query, tokenHash)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ports.ErrAPITokenInvalid
+		}
+		return nil, fmt.Errorf("failed to get API token: %w", err)
+	}
+
+	return &token, nil
+}
+
+// ListPersonalAccessTokens lists the non-revoked personal access tokens of a user
+func (r *PostgreSQLAuthRepository) ListPersonalAccessTokens(ctx context.Context, userID string) ([]*domain.APIToken, error) {
+	query := `
+		SELECT ` + apiTokenColumns + `
+		FROM api_tokens
+		WHERE type = 'personal' AND user_id = $1 AND revoked_at IS NULL
+		ORDER BY created_at DESC
+	`
+
+	tokens := []*domain.APIToken{}
+	if err := r.db.SelectContext(ctx, &tokens, query, userID); err != nil {
+		return nil, fmt.Errorf("failed to list personal access tokens: %w", err)
+	}
+
+	return tokens, nil
+}
+
+// ListTenantAPIKeys lists the non-revoked API keys of a tenant
+func (r *PostgreSQLAuthRepository) ListTenantAPIKeys(ctx context.Context, tenantID string) ([]*domain.APIToken, error) {
+	query := `
+		SELECT ` + apiTokenColumns + `
+		FROM api_tokens
+		WHERE type = 'tenant' AND tenant_id = $1 AND revoked_at IS NULL
+		ORDER BY created_at DESC
+	`
+
+	tokens := []*domain.APIToken{}
+	if err := r.db.SelectContext(ctx, &tokens, query, tenantID); err != nil {
+		return nil, fmt.Errorf("failed to list tenant API keys: %w", err)
+	}
+
+	return tokens, nil
+}
+
+// RevokePersonalAccessToken revokes one of the user's personal access tokens
+func (r *PostgreSQLAuthRepository) RevokePersonalAccessToken(ctx context.Context, userID string, tokenID string) error {
+	query := `
+		UPDATE api_tokens
+		SET revoked_at = $3
+		WHERE type = 'personal' AND user_id = $1 AND id = $2 AND revoked_at IS NULL
+	`
+
+	return r.revokeAPIToken(ctx, query, userID, tokenID)
+}
+
+// RevokeTenantAPIKey revokes one of the tenant's API keys
+func (r *PostgreSQLAuthRepository) RevokeTenantAPIKey(ctx context.Context, tenantID string, tokenID string) error {
+	query := `
+		UPDATE api_tokens
+		SET revoked_at = $3
+		WHERE type = 'tenant' AND tenant_id = $1 AND id = $2 AND revoked_at IS NULL
+	`
+
+	return r.revokeAPIToken(ctx, query, tenantID, tokenID)
+}
+
+// revokeAPIToken runs a revocation query scoped to the token owner
+func (r *PostgreSQLAuthRepository) revokeAPIToken(ctx context.Context, query string, ownerID string, tokenID string) error {
+	result, err := r.db.ExecContext(ctx, query, ownerID, tokenID, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to revoke API token: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ports.ErrAPITokenNotFound
+	}
+
+	return nil
+}
+
+// RecordAPITokenUse stores the time and client address of the latest use of a token
+func (r *PostgreSQLAuthRepository) RecordAPITokenUse(ctx context.Context, tokenID string, ipAddress string) error {
+	query := `UPDATE api_tokens SET last_used_at = $2, last_used_ip = $3 WHERE id = $1`
+
+	if _, err := r.db.ExecContext(ctx, query, tokenID, time.Now(), ipAddress); err != nil {
+		return fmt.Errorf("failed to record API token use: %w", err)
+	}
+
+	return nil
+}
+
+// Password policy operations
+
+// GetTenantPasswordPolicy retrieves the password policy of a tenant
+func (r *PostgreSQLAuthRepository) GetTenantPasswordPolicy(ctx context.Context, tenantID string) (*domain.PasswordPolicy, error) {
+	query := `
+		SELECT tenant_id, min_length, require_uppercase, require_lowercase, require_digit, require_symbol,
+		       history_size, max_age_days, check_breached, created_at, updated_at
+		FROM tenant_password_policies
+		WHERE tenant_id = $1
+	`
+
+	var policy domain.PasswordPolicy
+	err := r.db.GetContext(ctx, &policy, query, tenantID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get password policy: %w", err)
+	}
+
+	return &policy, nil
+}
+
+// UpsertTenantPasswordPolicy creates or replaces the password policy of a tenant
+func (r *PostgreSQLAuthRepository) UpsertTenantPasswordPolicy(ctx context.Context, policy *domain.PasswordPolicy) error {
+	query := `
+		INSERT INTO tenant_password_policies (
+			tenant_id, min_length, require_uppercase, require_lowercase, require_digit, require_symbol,
+			history_size, max_age_days, check_breached, created_at, updated_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		ON CONFLICT (tenant_id) DO UPDATE SET
+			min_length = EXCLUDED.min_length,
+			require_uppercase = EXCLUDED.require_uppercase,
+			require_lowercase = EXCLUDED.require_lowercase,
+			require_digit = EXCLUDED.require_digit,
+			require_symbol = EXCLUDED.require_symbol,
+			history_size = EXCLUDED.history_size,
+			max_age_days = EXCLUDED.max_age_days,
+			check_breached = EXCLUDED.check_breached,
+			updated_at = EXCLUDED.updated_at
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		policy.TenantID,
+		policy.MinLength,
+		policy.RequireUppercase,
+		policy.RequireLowercase,
+		policy.RequireDigit,
+		policy.RequireSymbol,
+		policy.HistorySize,
+		policy.MaxAgeDays,
+		policy.CheckBreached,
+		policy.CreatedAt,
+		policy.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save password policy: %w", err)
+	}
+
+	return nil
+}
+
+// GetPasswordHistory retrieves the hashes of the user's most recent passwords, newest first
+func (r *PostgreSQLAuthRepository) GetPasswordHistory(ctx context.Context, userID string, limit int) ([]string, error) {
+	query := `
+		SELECT password_hash
+		FROM password_history
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2
+	`
+
+	hashes := []string{}
+	if err := r.db.SelectContext(ctx, &hashes, query, userID, limit); err != nil {
+		return nil, fmt.Errorf("failed to get password history: %w", err)
+	}
+
+	return hashes, nil
+}
+
+// RecordPasswordChange adds a password hash to the user's history, prunes the history to
+// MaxPasswordHistory entries and resets the password age in a single transaction
+func (r *PostgreSQLAuthRepository) RecordPasswordChange(ctx context.Context, userID string, passwordHash string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+
+	insertQuery := `INSERT INTO password_history (user_id, password_hash, created_at) VALUES ($1, $2, $3)`
+	if _, err := tx.ExecContext(ctx, insertQuery, userID, passwordHash, now); err != nil {
+		return fmt.Errorf("failed to insert password history: %w", err)
+	}
+
+	pruneQuery := `
+		DELETE FROM password_history
+		WHERE user_id = $1
+		  AND id NOT IN (
+			SELECT id FROM password_history
+			WHERE user_id = $1
+			ORDER BY created_at DESC
+			LIMIT $2
+		  )
+	`
+	if _, err := tx.ExecContext(ctx, pruneQuery, userID, domain.MaxPasswordHistory); err != nil {
+		return fmt.Errorf("failed to prune password history: %w", err)
+	}
+
+	userQuery := `UPDATE users SET password_changed_at = $2, must_change_password = false WHERE id = $1`
+	if _, err := tx.ExecContext(ctx, userQuery, userID, now); err != nil {
+		return fmt.Errorf("failed to update password age: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// SetMustChangePassword sets whether the user must change the password before using the platform
+func (r *PostgreSQLAuthRepository) SetMustChangePassword(ctx context.Context, userID string, mustChange bool) error {
+	query := `UPDATE users SET must_change_password = $2, updated_at = $3 WHERE id = $1`
+
+	result, err := r.db.ExecContext(ctx, query, userID, mustChange, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to set must change password: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ports.ErrUserNotFound
+	}
+
+	return nil
+}
+
+// Utility operations
+
+// EmailExists checks if an email address is already registered
+func (r *PostgreSQLAuthRepository) EmailExists(ctx context.Context, email string) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM users WHERE email = $1)`
+
+	var exists bool
+	err := r.db.GetContext(ctx, &exists, query, email)
+	if err != nil {
+		return false, fmt.Errorf("failed to check email existence: %w", err)
+	}
+
+	return exists, nil
+}
+
+// EmailExistsExcludingUser checks if an email exists for any user except the specified one
+func (r *PostgreSQLAuthRepository) EmailExistsExcludingUser(ctx context.Context, email string, userID string) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM users WHERE email = $1 AND id != $2)`
+
+	var exists bool
+	err := r.db.GetContext(ctx, &exists, query, email, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to check email existence: %w", err)
+	}
+
+	return exists, nil
+}
+
+// GetFirstActiveMembership retrieves the first active tenant membership for a user
+// This is used during login to determine the user's tenant when they don't have
+// a tenant_id set in their user record
+func (r *PostgreSQLAuthRepository) GetFirstActiveMembership(ctx context.Context, userID string) (*domain.UserMembership, error) {
+	query := `
+		SELECT tenant_id, role, status
+		FROM tenant_memberships
+		WHERE user_id = $1 AND status = 'active'
+		ORDER BY joined_at DESC
+		LIMIT 1
+	`
+
+	var membership domain.UserMembership
+	err := r.db.GetContext(ctx, &membership, query, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			// No active membership found, this is OK
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get first active membership: %w", err)
+	}
+
+	return &membership, nil
+}
+
+// GetActiveMembership retrieves a user's active membership in a specific tenant
+func (r *PostgreSQLAuthRepository) GetActiveMembership(ctx context.Context, userID string, tenantID string) (*domain.UserMembership, error) {
+	query := `
+		SELECT tenant_id, role, status
+		FROM tenant_memberships
+		WHERE user_id = $1 AND tenant_id = $2 AND status = 'active'
+	`
+
+	var membership domain.UserMembership
+	err := r.db.GetContext(ctx, &membership, query, userID, tenantID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get active membership: %w", err)
+	}
+
+	return &membership, nil
+}
+
+// Tenant role operations
+
+// tenantRoleColumns lists the columns selected for a custom tenant role
+const tenantRoleColumns = `id, tenant_id, name, description, permissions, created_at, updated_at`
+
+// ListTenantRoles retrieves the custom roles defined by a tenant, ordered by name
+func (r *PostgreSQLAuthRepository) ListTenantRoles(ctx context.Context, tenantID string) ([]*domain.TenantRole, error) {
+	query := `SELECT ` + tenantRoleColumns + ` FROM tenant_roles WHERE tenant_id = $1 ORDER BY LOWER(name)`
+
+	roles := []*domain.TenantRole{}
+	if err := r.db.SelectContext(ctx, &roles, query, tenantID); err != nil {
+		return nil, fmt.Errorf("failed to list tenant roles: %w", err)
+	}
+
+	return roles, nil
+}
+
+// GetTenantRole retrieves a custom role of a tenant
+func (r *PostgreSQLAuthRepository) GetTenantRole(ctx context.Context, tenantID string, roleID string) (*domain.TenantRole, error) {
+	query := `SELECT ` + tenantRoleColumns + ` FROM tenant_roles WHERE id = $1 AND tenant_id = $2`
+
+	var role domain.TenantRole
+	err := r.db.GetContext(ctx, &role, query, roleID, tenantID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ports.ErrTenantRoleNotFound
+		}
+		return nil, fmt.Errorf("failed to get tenant role: %w", err)
+	}
+
+	return &role, nil
+}
+
+// CreateTenantRole persists a new custom role
+func (r *PostgreSQLAuthRepository) CreateTenantRole(ctx context.Context, role *domain.TenantRole) error {
+	query := `
+		INSERT INTO tenant_roles (id, tenant_id, name, description, permissions, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		role.ID,
+		role.TenantID,
+		role.Name,
+		role.Description,
+		role.Permissions,
+		role.CreatedAt,
+		role.UpdatedAt,
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ports.ErrTenantRoleExists
+		}
+		return fmt.Errorf("failed to create tenant role: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateTenantRole updates the name, description and permissions of a custom role
+func (r *PostgreSQLAuthRepository) UpdateTenantRole(ctx context.Context, role *domain.TenantRole) error {
+	query := `
+		UPDATE tenant_roles
+		SET name = $3, description = $4, permissions = $5, updated_at = $6
+		WHERE id = $1 AND tenant_id = $2
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		role.ID,
+		role.TenantID,
+		role.Name,
+		role.Description,
+		role.Permissions,
+		role.UpdatedAt,
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ports.ErrTenantRoleExists
+		}
+		return fmt.Errorf("failed to update tenant role: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ports.ErrTenantRoleNotFound
+	}
+
+	return nil
+}
+
+// DeleteTenantRole removes a custom role; the memberships holding it are unassigned by the foreign key
+func (r *PostgreSQLAuthRepository) DeleteTenantRole(ctx context.Context, tenantID string, roleID string) error {
+	query := `DELETE FROM tenant_roles WHERE id = $1 AND tenant_id = $2`
+
+	result, err := r.db.ExecContext(ctx, query, roleID, tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to delete tenant role: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ports.ErrTenantRoleNotFound
+	}
+
+	return nil
+}
+
+// SetMembershipCustomRole assigns a custom role to a user's membership in a tenant, or removes it when roleID is nil
+func (r *PostgreSQLAuthRepository) SetMembershipCustomRole(ctx context.Context, userID string, tenantID string, roleID *string) error {
+	query := `
+		UPDATE tenant_memberships
+		SET custom_role_id = $3
+		WHERE user_id = $1 AND tenant_id = $2
+	`
+
+	result, err := r.db.ExecContext(ctx, query, userID, tenantID, roleID)
+	if err != nil {
+		return fmt.Errorf("failed to set membership custom role: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ports.ErrUserNotFound
+	}
+
+	return nil
+}
+
+// isUniqueViolation checks if an error was caused by a unique constraint
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
 }
 
 // PostgreSQLUserRepository implements UserRepository interface for PostgreSQL
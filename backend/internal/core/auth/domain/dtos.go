@@ -2,42 +2,62 @@ package domain
 
 import "time"
 
+// SessionMetadata describes the client a refresh token is issued to.
+// It is filled by the controller from the request, never from the JSON body.
+type SessionMetadata struct {
+	UserAgent string `json:"-"`
+	IPAddress string `json:"-"`
+}
+
 // RegisterDTO represents the data required to register a new user
 type RegisterDTO struct {
 	Email    string `json:"email" validate:"required,email,max=255"`
 	Password string `json:"password" validate:"required,min=8,max=72"`
 	FullName string `json:"full_name" validate:"required,min=2,max=255"`
 	Role     string `json:"role,omitempty" validate:"omitempty,oneof=student instructor admin"`
+	SessionMetadata
 }
 
 // LoginDTO represents the data required to login
 type LoginDTO struct {
 	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required"`
+	SessionMetadata
 }
 
 // AuthResponse represents the response after successful authentication
+// When a second factor is required, only the MFA fields are populated and the
+// client must complete the login with the returned MFA token
 type AuthResponse struct {
-	AccessToken  string   `json:"access_token"`
-	TokenType    string   `json:"token_type"`
-	ExpiresIn    int      `json:"expires_in"` // Seconds until expiration
-	RefreshToken string   `json:"refresh_token,omitempty"`
-	User         *UserDTO `json:"user"`
+	AccessToken           string   `json:"access_token"`
+	TokenType             string   `json:"token_type"`
+	ExpiresIn             int      `json:"expires_in"` // Seconds until expiration
+	RefreshToken          string   `json:"refresh_token,omitempty"`
+	User                  *UserDTO `json:"user"`
+	MFARequired           bool     `json:"mfa_required,omitempty"`
+	MFAEnrollmentRequired bool     `json:"mfa_enrollment_required,omitempty"` // Tenant policy requires MFA but user hasn't enrolled yet
+	MFAToken              string   `json:"mfa_token,omitempty"`
+	RecoveryCodes         []string `json:"recovery_codes,omitempty"`        // Only returned when MFA was enrolled during login
+	MFAMethods            []string `json:"mfa_methods,omitempty"`           // Second factors the user can complete the login with: totp, webauthn
+	AccountLinkRequired   bool     `json:"account_link_required,omitempty"` // A single sign-on login matched an existing account that must confirm the link
+	AccountLinkToken      string   `json:"account_link_token,omitempty"`
+	AccountLinkMethods    []string `json:"account_link_methods,omitempty"` // Local credentials that can confirm the link: password, totp
 }
 
 // UserDTO represents a user without sensitive information
 type UserDTO struct {
-	ID               string    `json:"id"`
-	TenantID         *string   `json:"tenant_id,omitempty"` // Nullable - user might not have a tenant yet
-	Email            string    `json:"email"`
-	FullName         string    `json:"full_name"`
-	Role             string    `json:"role"`               // Primary role for backwards compatibility
-	Roles            []string  `json:"roles"`              // All assigned roles
-	ActiveRole       string    `json:"active_role"`        // Currently active role
-	HasMultipleRoles bool      `json:"has_multiple_roles"` // Indicates if user has more than one role
-	IsVerified       bool      `json:"is_verified"`
-	CreatedAt        time.Time `json:"created_at"`
-	UpdatedAt        time.Time `json:"updated_at"`
+	ID                 string    `json:"id"`
+	TenantID           *string   `json:"tenant_id,omitempty"` // Nullable - user might not have a tenant yet
+	Email              string    `json:"email"`
+	FullName           string    `json:"full_name"`
+	Role               string    `json:"role"`               // Primary role for backwards compatibility
+	Roles              []string  `json:"roles"`              // All assigned roles
+	ActiveRole         string    `json:"active_role"`        // Currently active role
+	HasMultipleRoles   bool      `json:"has_multiple_roles"` // Indicates if user has more than one role
+	IsVerified         bool      `json:"is_verified"`
+	MustChangePassword bool      `json:"must_change_password"` // Forced by an admin or password expiry; only a password change is allowed
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
 }
 
 // ToUserDTO converts a User entity to UserDTO
@@ -58,17 +78,18 @@ func ToUserDTO(user *User) *UserDTO {
 	}
 
 	return &UserDTO{
-		ID:               user.ID,
-		TenantID:         user.TenantID,
-		Email:            user.Email,
-		FullName:         user.FullName,
-		Role:             roles[0], // Primary role for frontend compatibility
-		Roles:            roles,
-		ActiveRole:       activeRole,
-		HasMultipleRoles: len(roles) > 1,
-		IsVerified:       user.IsVerified,
-		CreatedAt:        user.CreatedAt,
-		UpdatedAt:        user.UpdatedAt,
+		ID:                 user.ID,
+		TenantID:           user.TenantID,
+		Email:              user.Email,
+		FullName:           user.FullName,
+		Role:               roles[0], // Primary role for frontend compatibility
+		Roles:              roles,
+		ActiveRole:         activeRole,
+		HasMultipleRoles:   len(roles) > 1,
+		IsVerified:         user.IsVerified,
+		MustChangePassword: user.MustChangePassword,
+		CreatedAt:          user.CreatedAt,
+		UpdatedAt:          user.UpdatedAt,
 	}
 }
 
@@ -85,6 +106,24 @@ type ResendVerificationDTO struct {
 // ForgotPasswordDTO represents the data required to request password reset
 type ForgotPasswordDTO struct {
 	Email string `json:"email" validate:"required,email"`
+	SessionMetadata
+}
+
+// MagicLinkRequestDTO represents the data required to request a login link by email
+type MagicLinkRequestDTO struct {
+	Email string `json:"email" validate:"required,email"`
+	SessionMetadata
+}
+
+// MagicLinkLoginDTO represents the token of a login link sent by email
+type MagicLinkLoginDTO struct {
+	Token string `json:"token" validate:"required"`
+	SessionMetadata
+}
+
+// UnlockAccountDTO represents the data required to lift a lockout with the emailed link
+type UnlockAccountDTO struct {
+	Token string `json:"token" validate:"required"`
 }
 
 // ResetPasswordDTO represents the data required to reset password
@@ -102,6 +141,7 @@ type ChangePasswordDTO struct {
 // RefreshTokenDTO represents the data required to refresh access token
 type RefreshTokenDTO struct {
 	RefreshToken string `json:"refresh_token" validate:"required"`
+	SessionMetadata
 }
 
 // UpdateProfileDTO represents the data that can be updated in user profile
@@ -143,6 +183,232 @@ type SwitchRoleResponse struct {
 	User        *UserDTO `json:"user"`
 }
 
+// MFAEnrollmentResponse represents the data needed to add the account to an authenticator app
+type MFAEnrollmentResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+// MFACodeDTO represents a TOTP code submitted by an authenticated user
+type MFACodeDTO struct {
+	Code string `json:"code" validate:"required,len=6,numeric"`
+}
+
+// MFARecoveryCodesResponse represents a freshly generated set of recovery codes
+type MFARecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// MFAStatusResponse represents the MFA status of the authenticated user
+type MFAStatusResponse struct {
+	Enabled                bool       `json:"enabled"`
+	EnabledAt              *time.Time `json:"enabled_at,omitempty"`
+	RecoveryCodesRemaining int        `json:"recovery_codes_remaining"`
+	Required               bool       `json:"required"` // Required by the tenant policy
+	Passkeys               int        `json:"passkeys"` // Registered WebAuthn credentials, also usable as second factor
+}
+
+// MFAChallengeDTO represents the MFA token returned by the first login step
+type MFAChallengeDTO struct {
+	MFAToken string `json:"mfa_token" validate:"required"`
+}
+
+// MFALoginDTO represents the second login step, using either a TOTP code or a recovery code
+type MFALoginDTO struct {
+	MFAToken     string `json:"mfa_token" validate:"required"`
+	Code         string `json:"code,omitempty" validate:"required_without=RecoveryCode,omitempty,len=6,numeric"`
+	RecoveryCode string `json:"recovery_code,omitempty" validate:"required_without=Code,omitempty,max=32"`
+	SessionMetadata
+}
+
+// WebAuthnOptionsResponse wraps the options passed to navigator.credentials.create()
+// or navigator.credentials.get(); binary values are base64url encoded
+type WebAuthnOptionsResponse struct {
+	PublicKey interface{} `json:"publicKey"`
+}
+
+// WebAuthnAttestationDTO represents the response of navigator.credentials.create()
+type WebAuthnAttestationDTO struct {
+	ClientDataJSON    string   `json:"clientDataJSON" validate:"required"`
+	AttestationObject string   `json:"attestationObject" validate:"required"`
+	Transports        []string `json:"transports,omitempty" validate:"max=10,dive,max=32"`
+}
+
+// WebAuthnAssertionDTO represents the response of navigator.credentials.get()
+type WebAuthnAssertionDTO struct {
+	ClientDataJSON    string `json:"clientDataJSON" validate:"required"`
+	AuthenticatorData string `json:"authenticatorData" validate:"required"`
+	Signature         string `json:"signature" validate:"required"`
+	UserHandle        string `json:"userHandle,omitempty"`
+}
+
+// WebAuthnRegistrationDTO represents a new credential to register, as serialized by
+// PublicKeyCredential.toJSON(), with a name to recognize it
+type WebAuthnRegistrationDTO struct {
+	Name     string                 `json:"name" validate:"required,min=1,max=100"`
+	ID       string                 `json:"id" validate:"required,max=1400"`
+	Response WebAuthnAttestationDTO `json:"response"`
+}
+
+// WebAuthnLoginBeginDTO represents the start of a passkey login. Without an email the
+// user picks any passkey registered for the site.
+type WebAuthnLoginBeginDTO struct {
+	Email string `json:"email,omitempty" validate:"omitempty,email"`
+}
+
+// WebAuthnLoginDTO represents a passkey login, as serialized by PublicKeyCredential.toJSON()
+type WebAuthnLoginDTO struct {
+	ID       string               `json:"id" validate:"required,max=1400"`
+	Response WebAuthnAssertionDTO `json:"response"`
+	SessionMetadata
+}
+
+// WebAuthnMFALoginDTO represents the second login step completed with a passkey or security key
+type WebAuthnMFALoginDTO struct {
+	MFAToken string               `json:"mfa_token" validate:"required"`
+	ID       string               `json:"id" validate:"required,max=1400"`
+	Response WebAuthnAssertionDTO `json:"response"`
+	SessionMetadata
+}
+
+// MFAPolicyDTO represents the tenant roles that must use MFA
+type MFAPolicyDTO struct {
+	RequiredRoles []string `json:"required_roles" validate:"dive,oneof=student instructor admin"`
+}
+
+// TenantRoleDTO represents the settings of a custom tenant role
+type TenantRoleDTO struct {
+	Name        string   `json:"name" validate:"required,max=50"`
+	Description string   `json:"description" validate:"max=255"`
+	Permissions []string `json:"permissions" validate:"required,min=1,dive,required"` // e.g. submission.grade, analytics.view
+}
+
+// AssignTenantRoleDTO represents the custom role assigned to a tenant member
+type AssignTenantRoleDTO struct {
+	RoleID *string `json:"role_id" validate:"omitempty,uuid"` // Removes the custom role when empty
+}
+
+// PermissionCatalogResponse represents the permissions that can be granted to custom roles
+// and those granted by each built-in role
+type PermissionCatalogResponse struct {
+	Permissions  []PermissionInfo        `json:"permissions"`
+	BuiltInRoles map[string][]Permission `json:"built_in_roles"`
+}
+
+// MagicLinkSettingsDTO represents whether a tenant allows login with links sent by email
+type MagicLinkSettingsDTO struct {
+	Enabled bool `json:"enabled"`
+}
+
+// PasswordPolicyDTO represents the password policy settings of a tenant
+type PasswordPolicyDTO struct {
+	MinLength        int  `json:"min_length" validate:"min=8,max=72"`
+	RequireUppercase bool `json:"require_uppercase"`
+	RequireLowercase bool `json:"require_lowercase"`
+	RequireDigit     bool `json:"require_digit"`
+	RequireSymbol    bool `json:"require_symbol"`
+	HistorySize      int  `json:"history_size" validate:"min=0,max=24"`
+	MaxAgeDays       int  `json:"max_age_days" validate:"min=0,max=3650"`
+	CheckBreached    bool `json:"check_breached"`
+}
+
+// OIDCProviderDTO represents the OIDC identity provider settings of a tenant
+type OIDCProviderDTO struct {
+	Issuer       string            `json:"issuer" validate:"required,url,max=500"`
+	ClientID     string            `json:"client_id" validate:"required,max=255"`
+	ClientSecret string            `json:"client_secret,omitempty" validate:"omitempty,max=1000"` // Keeps the stored secret when empty
+	RedirectURI  string            `json:"redirect_uri" validate:"required,url,max=500"`
+	Scopes       []string          `json:"scopes,omitempty" validate:"omitempty,dive,required,max=100"`
+	RoleClaim    string            `json:"role_claim,omitempty" validate:"omitempty,max=100"`
+	RoleMapping  map[string]string `json:"role_mapping,omitempty" validate:"omitempty,dive,keys,required,max=255,endkeys,oneof=student instructor admin"`
+	DefaultRole  string            `json:"default_role,omitempty" validate:"omitempty,oneof=student instructor admin"`
+	Enabled      bool              `json:"enabled"`
+}
+
+// OIDCAuthorizeResponse represents the URL that starts a login at the identity provider
+type OIDCAuthorizeResponse struct {
+	AuthorizationURL string `json:"authorization_url"`
+	State            string `json:"state"`
+}
+
+// OIDCCallbackDTO represents the parameters the identity provider redirected back with
+type OIDCCallbackDTO struct {
+	State string `json:"state" validate:"required,max=255"`
+	Code  string `json:"code" validate:"required,max=2048"`
+	SessionMetadata
+}
+
+// SAMLProviderDTO represents the SAML identity provider settings of a tenant
+type SAMLProviderDTO struct {
+	IdPEntityID    string            `json:"idp_entity_id" validate:"required,max=500"`
+	IdPSSOURL      string            `json:"idp_sso_url" validate:"required,url,max=500"`
+	IdPCertificate string            `json:"idp_certificate" validate:"required,max=20000"` // One or more PEM certificates
+	EmailAttribute string            `json:"email_attribute,omitempty" validate:"omitempty,max=255"`
+	NameAttribute  string            `json:"name_attribute,omitempty" validate:"omitempty,max=255"`
+	RoleAttribute  string            `json:"role_attribute,omitempty" validate:"omitempty,max=255"`
+	RoleMapping    map[string]string `json:"role_mapping,omitempty" validate:"omitempty,dive,keys,required,max=255,endkeys,oneof=student instructor admin"`
+	DefaultRole    string            `json:"default_role,omitempty" validate:"omitempty,oneof=student instructor admin"`
+	Enabled        bool              `json:"enabled"`
+}
+
+// SAMLAuthorizeResponse represents the URL that starts a login at the SAML identity provider
+type SAMLAuthorizeResponse struct {
+	AuthorizationURL string `json:"authorization_url"`
+}
+
+// SAMLResponseDTO represents the response posted by the identity provider to the ACS endpoint
+type SAMLResponseDTO struct {
+	TenantID     string `json:"-"`
+	SAMLResponse string `json:"SAMLResponse" form:"SAMLResponse"`
+}
+
+// SAMLExchangeDTO represents the one-time code the frontend exchanges for tokens
+type SAMLExchangeDTO struct {
+	Code string `json:"code" validate:"required,max=255"`
+	SessionMetadata
+}
+
+// FederatedLinkDTO confirms the link of a single sign-on login to an existing account
+// with the account's password, a TOTP code or a recovery code
+type FederatedLinkDTO struct {
+	LinkToken    string `json:"link_token" validate:"required,max=255"`
+	Password     string `json:"password,omitempty" validate:"omitempty,max=128"`
+	Code         string `json:"code,omitempty" validate:"omitempty,len=6,numeric"`
+	RecoveryCode string `json:"recovery_code,omitempty" validate:"omitempty,max=32"`
+	SessionMetadata
+}
+
+// CreateAPITokenDTO represents the request to create a personal access token or tenant API key
+type CreateAPITokenDTO struct {
+	Name      string     `json:"name" validate:"required,max=100"`
+	Scopes    []string   `json:"scopes" validate:"required,min=1,max=30,dive,required,max=50"` // e.g. courses:read, enrollments:write
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`                                         // Optional, never expires when empty
+}
+
+// APITokenCreatedResponse represents a new token; the plaintext token is only returned once
+type APITokenCreatedResponse struct {
+	Token string `json:"token"`
+	*APIToken
+}
+
+// StartImpersonationDTO represents an admin's request to act as another user
+type StartImpersonationDTO struct {
+	Reason string `json:"reason" validate:"required,min=3,max=500"` // Recorded in the audit trail, e.g. the support ticket
+	SessionMetadata
+}
+
+// ImpersonationResponse represents the access token issued to act as the target user.
+// There is no refresh token: the session ends when the token expires.
+type ImpersonationResponse struct {
+	AccessToken    string    `json:"access_token"`
+	TokenType      string    `json:"token_type"`
+	ExpiresIn      int       `json:"expires_in"`
+	ExpiresAt      time.Time `json:"expires_at"`
+	SessionID      string    `json:"session_id"`
+	ImpersonatorID string    `json:"impersonator_id"`
+	User           *UserDTO  `json:"user"`
+}
+
 // UserListFilters represents filters for listing users
 type UserListFilters struct {
 	Role       string `json:"role,omitempty" validate:"omitempty,oneof=student instructor admin"`
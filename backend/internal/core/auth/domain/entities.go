@@ -1,8 +1,13 @@
 package domain
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/lib/pq"
 )
@@ -24,6 +29,9 @@ type User struct {
 	IsVerified   bool           `json:"is_verified" db:"is_verified"`
 	CreatedAt    time.Time      `json:"created_at" db:"created_at"`
 	UpdatedAt    time.Time      `json:"updated_at" db:"updated_at"`
+	// Password age tracking, used by the tenant password policy
+	PasswordChangedAt  *time.Time `json:"password_changed_at,omitempty" db:"password_changed_at"`
+	MustChangePassword bool       `json:"must_change_password" db:"must_change_password"`
 }
 
 // UserRole defines the possible user roles in the system
@@ -62,6 +70,112 @@ func GetRoleHierarchy(role UserRole) int {
 	}
 }
 
+// Permission identifies an action that can be granted to tenant roles.
+// Managing members, custom roles and tenant settings is not a permission: those routes
+// require the admin role, since they would let a custom role grant itself more access.
+type Permission string
+
+const (
+	PermissionCourseView        Permission = "course.view"
+	PermissionCourseEdit        Permission = "course.edit"
+	PermissionCoursePublish     Permission = "course.publish"
+	PermissionCourseDelete      Permission = "course.delete"
+	PermissionAssessmentEdit    Permission = "assessment.edit"
+	PermissionSubmissionView    Permission = "submission.view"
+	PermissionSubmissionGrade   Permission = "submission.grade"
+	PermissionEnrollmentManage  Permission = "enrollment.manage"
+	PermissionAnalyticsView     Permission = "analytics.view"
+	PermissionCertificateManage Permission = "certificate.manage"
+)
+
+// PermissionInfo describes a permission of the catalog
+type PermissionInfo struct {
+	Name        Permission `json:"name"`
+	Description string     `json:"description"`
+}
+
+// PermissionCatalog lists every permission that can be granted, in display order
+var PermissionCatalog = []PermissionInfo{
+	{PermissionCourseView, "View courses and their content"},
+	{PermissionCourseEdit, "Create and edit courses, modules and lessons"},
+	{PermissionCoursePublish, "Publish and unpublish courses"},
+	{PermissionCourseDelete, "Delete courses"},
+	{PermissionAssessmentEdit, "Create and edit quizzes, assignments and rubrics"},
+	{PermissionSubmissionView, "View quiz attempts and assignment submissions of learners"},
+	{PermissionSubmissionGrade, "Grade submissions and return them with feedback"},
+	{PermissionEnrollmentManage, "Enroll learners and review enrollment requests"},
+	{PermissionAnalyticsView, "View course and learner analytics"},
+	{PermissionCertificateManage, "Issue and revoke certificates"},
+}
+
+// IsValidPermission checks if a permission is part of the catalog
+func IsValidPermission(permission string) bool {
+	for _, info := range PermissionCatalog {
+		if string(info.Name) == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// GetRolePermissions returns the permissions granted by a built-in role
+func GetRolePermissions(role UserRole) []Permission {
+	switch role {
+	case RoleSuperAdmin, RoleAdmin:
+		permissions := make([]Permission, len(PermissionCatalog))
+		for i, info := range PermissionCatalog {
+			permissions[i] = info.Name
+		}
+		return permissions
+	case RoleInstructor:
+		return []Permission{
+			PermissionCourseView,
+			PermissionCourseEdit,
+			PermissionCoursePublish,
+			PermissionAssessmentEdit,
+			PermissionSubmissionView,
+			PermissionSubmissionGrade,
+			PermissionEnrollmentManage,
+			PermissionAnalyticsView,
+			PermissionCertificateManage,
+		}
+	case RoleStudent:
+		return []Permission{PermissionCourseView}
+	default:
+		return nil
+	}
+}
+
+// ResolvePermissions returns the permissions of a tenant membership: those of its built-in
+// role plus those of the custom role assigned to it, if any
+func ResolvePermissions(role string, customPermissions []string) []string {
+	permissions := make([]string, 0, len(PermissionCatalog))
+	seen := make(map[string]bool)
+	for _, permission := range GetRolePermissions(UserRole(role)) {
+		seen[string(permission)] = true
+		permissions = append(permissions, string(permission))
+	}
+	for _, permission := range customPermissions {
+		if !seen[permission] && IsValidPermission(permission) {
+			seen[permission] = true
+			permissions = append(permissions, permission)
+		}
+	}
+	return permissions
+}
+
+// TenantRole represents a role defined by a tenant, such as "teaching assistant".
+// Members keep their built-in role and gain the permissions of the custom role assigned to them.
+type TenantRole struct {
+	ID          string         `json:"id" db:"id"`
+	TenantID    string         `json:"tenant_id" db:"tenant_id"`
+	Name        string         `json:"name" db:"name"`
+	Description string         `json:"description" db:"description"`
+	Permissions pq.StringArray `json:"permissions" db:"permissions"`
+	CreatedAt   time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at" db:"updated_at"`
+}
+
 // VerificationToken represents an email verification token
 type VerificationToken struct {
 	ID        string    `json:"id" db:"id"`
@@ -101,14 +215,46 @@ func (prt *PasswordResetToken) IsValid() bool {
 	return !prt.IsExpired() && !prt.IsUsed()
 }
 
+// MagicLinkToken represents a single-use passwordless login link sent by email
+type MagicLinkToken struct {
+	ID        string    `json:"id" db:"id"`
+	UserID    string    `json:"user_id" db:"user_id"`
+	TenantID  string    `json:"tenant_id" db:"tenant_id"` // Tenant the link was requested for
+	TokenHash string    `json:"-" db:"token_hash"`        // SHA-256 of the token sent by email
+	ExpiresAt time.Time `json:"expires_at" db:"expires_at"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// IsExpired checks if the magic link has expired
+func (mlt *MagicLinkToken) IsExpired() bool {
+	return time.Now().After(mlt.ExpiresAt)
+}
+
 // RefreshToken represents a JWT refresh token
+// Tokens are rotated on every refresh: the new token keeps the family of the
+// login that created it and points to the token it replaced
 type RefreshToken struct {
-	ID        string     `json:"id" db:"id"`
-	UserID    string     `json:"user_id" db:"user_id"`
-	Token     string     `json:"token" db:"token"`
-	ExpiresAt time.Time  `json:"expires_at" db:"expires_at"`
-	RevokedAt *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
-	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	ID         string     `json:"id" db:"id"`
+	UserID     string     `json:"user_id" db:"user_id"`
+	Token      string     `json:"token" db:"token"`
+	FamilyID   string     `json:"family_id" db:"family_id"`
+	ParentID   *string    `json:"parent_id,omitempty" db:"parent_id"` // Nullable - first token of a family
+	UserAgent  string     `json:"user_agent" db:"user_agent"`
+	IPAddress  string     `json:"ip_address" db:"ip_address"`
+	ExpiresAt  time.Time  `json:"expires_at" db:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty" db:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+}
+
+// Session represents an active login on a device, backed by a refresh token family
+type Session struct {
+	ID         string    `json:"id" db:"id"` // Refresh token family ID
+	UserAgent  string    `json:"user_agent" db:"user_agent"`
+	IPAddress  string    `json:"ip_address" db:"ip_address"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`     // When the user logged in
+	LastUsedAt time.Time `json:"last_used_at" db:"last_used_at"` // Last login or token refresh
+	ExpiresAt  time.Time `json:"expires_at" db:"expires_at"`
 }
 
 // IsExpired checks if the refresh token has expired
@@ -126,6 +272,310 @@ func (rt *RefreshToken) IsValid() bool {
 	return !rt.IsExpired() && !rt.IsRevoked()
 }
 
+// UserMFA represents a user's TOTP multi-factor authentication enrollment
+type UserMFA struct {
+	UserID       string     `json:"user_id" db:"user_id"`
+	Secret       string     `json:"-" db:"secret"` // Never expose in JSON
+	Enabled      bool       `json:"enabled" db:"enabled"`
+	LastUsedStep int64      `json:"-" db:"last_used_step"` // Last accepted TOTP time step (replay protection)
+	EnabledAt    *time.Time `json:"enabled_at,omitempty" db:"enabled_at"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// MFARecoveryCode represents a single-use MFA recovery code
+type MFARecoveryCode struct {
+	ID        string     `json:"id" db:"id"`
+	UserID    string     `json:"user_id" db:"user_id"`
+	CodeHash  string     `json:"-" db:"code_hash"`
+	UsedAt    *time.Time `json:"used_at,omitempty" db:"used_at"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+}
+
+// MFAChallenge represents the short-lived token issued by the first login step
+// when the user still has to provide a second factor
+type MFAChallenge struct {
+	ID                 string    `json:"id" db:"id"`
+	UserID             string    `json:"user_id" db:"user_id"`
+	TokenHash          string    `json:"-" db:"token_hash"` // SHA-256 of the token returned to the client
+	Attempts           int       `json:"attempts" db:"attempts"`
+	EnrollmentRequired bool      `json:"enrollment_required" db:"enrollment_required"` // Only set for users without a second factor; allows enrolling TOTP
+	ExpiresAt          time.Time `json:"expires_at" db:"expires_at"`
+	CreatedAt          time.Time `json:"created_at" db:"created_at"`
+}
+
+// IsExpired checks if the MFA challenge has expired
+func (c *MFAChallenge) IsExpired() bool {
+	return time.Now().After(c.ExpiresAt)
+}
+
+// WebAuthnCredential represents a passkey or security key registered by a user
+type WebAuthnCredential struct {
+	ID             string         `json:"id" db:"id"`
+	UserID         string         `json:"user_id" db:"user_id"`
+	CredentialID   string         `json:"credential_id" db:"credential_id"` // base64url, as sent by the browser
+	PublicKey      []byte         `json:"-" db:"public_key"`                // COSE_Key
+	SignCount      int64          `json:"-" db:"sign_count"`
+	AAGUID         string         `json:"aaguid" db:"aaguid"` // Authenticator model
+	Transports     pq.StringArray `json:"transports" db:"transports"`
+	BackupEligible bool           `json:"backup_eligible" db:"backup_eligible"` // Synced passkey
+	Name           string         `json:"name" db:"name"`
+	LastUsedAt     *time.Time     `json:"last_used_at,omitempty" db:"last_used_at"`
+	CreatedAt      time.Time      `json:"created_at" db:"created_at"`
+}
+
+// WebAuthnCeremony identifies what a WebAuthn challenge was issued for
+type WebAuthnCeremony string
+
+const (
+	// WebAuthnRegistration registers a new credential for an authenticated user
+	WebAuthnRegistration WebAuthnCeremony = "registration"
+	// WebAuthnLogin signs in with a passkey instead of a password
+	WebAuthnLogin WebAuthnCeremony = "login"
+	// WebAuthnMFA uses a credential as second factor after the password
+	WebAuthnMFA WebAuthnCeremony = "mfa"
+)
+
+// WebAuthnChallenge represents a pending WebAuthn ceremony
+type WebAuthnChallenge struct {
+	ID        string           `json:"id" db:"id"`
+	UserID    *string          `json:"user_id,omitempty" db:"user_id"` // Nil for usernameless logins
+	Challenge string           `json:"challenge" db:"challenge"`
+	Ceremony  WebAuthnCeremony `json:"ceremony" db:"ceremony"`
+	ExpiresAt time.Time        `json:"expires_at" db:"expires_at"`
+	CreatedAt time.Time        `json:"created_at" db:"created_at"`
+}
+
+// IsExpired checks if the WebAuthn challenge has expired
+func (c *WebAuthnChallenge) IsExpired() bool {
+	return time.Now().After(c.ExpiresAt)
+}
+
+// OIDCProvider represents the OpenID Connect identity provider configured for a tenant
+type OIDCProvider struct {
+	TenantID     string            `json:"tenant_id" db:"tenant_id"`
+	Issuer       string            `json:"issuer" db:"issuer"`
+	ClientID     string            `json:"client_id" db:"client_id"`
+	ClientSecret string            `json:"-" db:"client_secret"` // Never expose in JSON
+	RedirectURI  string            `json:"redirect_uri" db:"redirect_uri"`
+	Scopes       pq.StringArray    `json:"scopes" db:"scopes"`
+	RoleClaim    string            `json:"role_claim" db:"role_claim"`     // Claim holding groups/roles, empty to always use DefaultRole
+	RoleMapping  map[string]string `json:"role_mapping" db:"-"`            // Claim value -> membership role (stored as JSONB)
+	DefaultRole  string            `json:"default_role" db:"default_role"` // Role used when no claim value is mapped
+	Enabled      bool              `json:"enabled" db:"enabled"`
+	CreatedAt    time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time         `json:"updated_at" db:"updated_at"`
+}
+
+// OIDCAuthRequest represents an authorization request waiting for the provider callback
+type OIDCAuthRequest struct {
+	ID           string    `json:"id" db:"id"`
+	TenantID     string    `json:"tenant_id" db:"tenant_id"`
+	State        string    `json:"state" db:"state"`
+	Nonce        string    `json:"-" db:"nonce"`
+	CodeVerifier string    `json:"-" db:"code_verifier"` // PKCE verifier
+	ExpiresAt    time.Time `json:"expires_at" db:"expires_at"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+}
+
+// IsExpired checks if the authorization request has expired
+func (r *OIDCAuthRequest) IsExpired() bool {
+	return time.Now().After(r.ExpiresAt)
+}
+
+// UserIdentity links a user to their subject at an external identity provider
+type UserIdentity struct {
+	ID          string     `json:"id" db:"id"`
+	UserID      string     `json:"user_id" db:"user_id"`
+	TenantID    string     `json:"tenant_id" db:"tenant_id"`
+	Issuer      string     `json:"issuer" db:"issuer"`
+	Subject     string     `json:"subject" db:"subject"`
+	Email       string     `json:"email" db:"email"`
+	LastLoginAt *time.Time `json:"last_login_at,omitempty" db:"last_login_at"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+}
+
+// FederatedLinkRequest holds a first single sign-on login whose email matches an existing
+// account, until the user confirms the link with their local password or second factor
+type FederatedLinkRequest struct {
+	ID         string    `json:"id" db:"id"`
+	TokenHash  string    `json:"-" db:"token_hash"` // SHA-256 of the token returned to the client
+	UserID     string    `json:"user_id" db:"user_id"`
+	TenantID   string    `json:"tenant_id" db:"tenant_id"`
+	Issuer     string    `json:"issuer" db:"issuer"`
+	Subject    string    `json:"subject" db:"subject"`
+	Email      string    `json:"email" db:"email"`
+	Role       string    `json:"role" db:"role"`
+	RoleMapped bool      `json:"role_mapped" db:"role_mapped"`
+	Attempts   int       `json:"attempts" db:"attempts"`
+	ExpiresAt  time.Time `json:"expires_at" db:"expires_at"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+// IsExpired checks if the link request has expired
+func (r *FederatedLinkRequest) IsExpired() bool {
+	return time.Now().After(r.ExpiresAt)
+}
+
+// SAMLProvider represents the SAML 2.0 identity provider configured for a tenant
+type SAMLProvider struct {
+	TenantID       string            `json:"tenant_id" db:"tenant_id"`
+	IdPEntityID    string            `json:"idp_entity_id" db:"idp_entity_id"`
+	IdPSSOURL      string            `json:"idp_sso_url" db:"idp_sso_url"`
+	IdPCertificate string            `json:"idp_certificate" db:"idp_certificate"` // PEM encoded signing certificates
+	EmailAttribute string            `json:"email_attribute" db:"email_attribute"` // Empty to use the NameID
+	NameAttribute  string            `json:"name_attribute" db:"name_attribute"`
+	RoleAttribute  string            `json:"role_attribute" db:"role_attribute"` // Attribute holding groups/roles, empty to always use DefaultRole
+	RoleMapping    map[string]string `json:"role_mapping" db:"-"`                // Attribute value -> membership role (stored as JSONB)
+	DefaultRole    string            `json:"default_role" db:"default_role"`     // Role used when no attribute value is mapped
+	Enabled        bool              `json:"enabled" db:"enabled"`
+	CreatedAt      time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at" db:"updated_at"`
+
+	// Service provider endpoints to register at the identity provider
+	SPEntityID string `json:"sp_entity_id" db:"-"`
+	ACSURL     string `json:"acs_url" db:"-"`
+}
+
+// SAMLAuthRequest represents an AuthnRequest waiting for the identity provider response
+type SAMLAuthRequest struct {
+	ID        string    `json:"id" db:"id"` // AuthnRequest ID, echoed back in InResponseTo
+	TenantID  string    `json:"tenant_id" db:"tenant_id"`
+	ExpiresAt time.Time `json:"expires_at" db:"expires_at"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// IsExpired checks if the authentication request has expired
+func (r *SAMLAuthRequest) IsExpired() bool {
+	return time.Now().After(r.ExpiresAt)
+}
+
+// SAMLLoginCode represents the single-use code that hands a SAML login over to the frontend
+type SAMLLoginCode struct {
+	ID        string    `json:"id" db:"id"`
+	Code      string    `json:"-" db:"code"`
+	UserID    string    `json:"user_id" db:"user_id"`
+	TenantID  string    `json:"tenant_id" db:"tenant_id"`
+	ExpiresAt time.Time `json:"expires_at" db:"expires_at"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// IsExpired checks if the login code has expired
+func (c *SAMLLoginCode) IsExpired() bool {
+	return time.Now().After(c.ExpiresAt)
+}
+
+// APITokenType distinguishes personal access tokens from tenant API keys
+type APITokenType string
+
+const (
+	// APITokenPersonal is a personal access token that acts as the user who created it
+	APITokenPersonal APITokenType = "personal"
+	// APITokenTenant is a tenant API key managed by the tenant admins
+	APITokenTenant APITokenType = "tenant"
+)
+
+// Prefixes of the plaintext tokens, used to tell them apart from JWTs
+const (
+	PersonalAccessTokenPrefix = "stg_pat_"
+	APIKeyPrefix              = "stg_key_"
+)
+
+// APITokenResources lists the resources that API tokens can be scoped to.
+// A scope is "<resource>:read" or "<resource>:write"; write also grants read.
+var APITokenResources = []string{
+	"analytics",
+	"assignments",
+	"categories",
+	"certificates",
+	"courses",
+	"enrollments",
+	"lessons",
+	"media",
+	"modules",
+	"notifications",
+	"progress",
+	"quizzes",
+	"reviews",
+	"rubrics",
+	"scim",
+	"users",
+}
+
+// APIToken represents a personal access token or a tenant API key.
+// Only the SHA-256 hash of the token is stored.
+type APIToken struct {
+	ID          string         `json:"id" db:"id"`
+	Type        APITokenType   `json:"type" db:"type"`
+	TenantID    string         `json:"tenant_id" db:"tenant_id"`
+	UserID      string         `json:"user_id" db:"user_id"` // Owner of a personal token, creator of an API key
+	Name        string         `json:"name" db:"name"`
+	TokenHash   string         `json:"-" db:"token_hash"`
+	TokenPrefix string         `json:"token_prefix" db:"token_prefix"` // Type prefix and start of the ID, to recognize the token
+	Scopes      pq.StringArray `json:"scopes" db:"scopes"`
+	ExpiresAt   *time.Time     `json:"expires_at,omitempty" db:"expires_at"` // Nullable - never expires
+	LastUsedAt  *time.Time     `json:"last_used_at,omitempty" db:"last_used_at"`
+	LastUsedIP  *string        `json:"last_used_ip,omitempty" db:"last_used_ip"`
+	RevokedAt   *time.Time     `json:"revoked_at,omitempty" db:"revoked_at"`
+	CreatedAt   time.Time      `json:"created_at" db:"created_at"`
+}
+
+// IsExpired checks if the token has an expiry date in the past
+func (t *APIToken) IsExpired() bool {
+	return t.ExpiresAt != nil && time.Now().After(*t.ExpiresAt)
+}
+
+// IsRevoked checks if the token has been revoked
+func (t *APIToken) IsRevoked() bool {
+	return t.RevokedAt != nil
+}
+
+// IsValid checks if the token can be used (not expired and not revoked)
+func (t *APIToken) IsValid() bool {
+	return !t.IsExpired() && !t.IsRevoked()
+}
+
+// HasScope checks if the token grants a scope; "<resource>:write" also grants "<resource>:read"
+func (t *APIToken) HasScope(scope string) bool {
+	resource, action, ok := strings.Cut(scope, ":")
+	if !ok {
+		return false
+	}
+
+	for _, granted := range t.Scopes {
+		if granted == scope || (action == "read" && granted == resource+":write") {
+			return true
+		}
+	}
+	return false
+}
+
+// IsValidAPITokenScope checks if a scope names a known resource and a read or write action
+func IsValidAPITokenScope(scope string) bool {
+	resource, action, ok := strings.Cut(scope, ":")
+	if !ok || (action != "read" && action != "write") {
+		return false
+	}
+
+	for _, known := range APITokenResources {
+		if resource == known {
+			return true
+		}
+	}
+	return false
+}
+
+// IsAPIToken reports whether a bearer token is a personal access token or API key rather than a JWT
+func IsAPIToken(token string) bool {
+	return strings.HasPrefix(token, PersonalAccessTokenPrefix) || strings.HasPrefix(token, APIKeyPrefix)
+}
+
+// HashAPIToken returns the hex encoded SHA-256 hash under which a token is stored
+func HashAPIToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
 // SanitizeUser returns a User without sensitive information
 func (u *User) SanitizeUser() *User {
 	return &User{
@@ -236,6 +686,121 @@ func (u *User) InitializeRoles() {
 	}
 }
 
+// ImpersonationSession represents a time-boxed session in which an admin acts as another user of the tenant
+type ImpersonationSession struct {
+	ID             string     `json:"id" db:"id"`
+	TenantID       string     `json:"tenant_id" db:"tenant_id"`
+	ImpersonatorID *string    `json:"impersonator_id" db:"impersonator_id"` // Nil once the user is deleted
+	TargetUserID   *string    `json:"target_user_id" db:"target_user_id"`   // Nil once the user is deleted
+	Reason         string     `json:"reason" db:"reason"`
+	TokenID        string     `json:"-" db:"token_id"` // jti of the impersonation access token
+	IPAddress      string     `json:"ip_address" db:"ip_address"`
+	UserAgent      string     `json:"user_agent" db:"user_agent"`
+	ExpiresAt      time.Time  `json:"expires_at" db:"expires_at"`
+	EndedAt        *time.Time `json:"ended_at,omitempty" db:"ended_at"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+}
+
+// IsActive checks if the session was not stopped and has not expired
+func (s *ImpersonationSession) IsActive() bool {
+	return s.EndedAt == nil && time.Now().Before(s.ExpiresAt)
+}
+
+// ImpersonationEventType identifies an entry of the impersonation audit trail
+type ImpersonationEventType string
+
+const (
+	// ImpersonationStarted is recorded when an admin starts impersonating a user
+	ImpersonationStarted ImpersonationEventType = "start"
+	// ImpersonationStopped is recorded when the session is stopped
+	ImpersonationStopped ImpersonationEventType = "stop"
+	// ImpersonationRequest is recorded for every request made while impersonating
+	ImpersonationRequest ImpersonationEventType = "request"
+)
+
+// ImpersonationEvent represents an entry of the impersonation audit trail
+type ImpersonationEvent struct {
+	ID         string                 `json:"id" db:"id"`
+	SessionID  string                 `json:"session_id" db:"session_id"`
+	Type       ImpersonationEventType `json:"type" db:"type"`
+	Method     string                 `json:"method,omitempty" db:"method"`
+	Path       string                 `json:"path,omitempty" db:"path"`
+	StatusCode int                    `json:"status_code,omitempty" db:"status_code"` // Request events only
+	IPAddress  string                 `json:"ip_address" db:"ip_address"`
+	CreatedAt  time.Time              `json:"created_at" db:"created_at"`
+}
+
+// PasswordPolicy represents the password requirements of a tenant
+type PasswordPolicy struct {
+	TenantID         string    `json:"-" db:"tenant_id"`
+	MinLength        int       `json:"min_length" db:"min_length"`
+	RequireUppercase bool      `json:"require_uppercase" db:"require_uppercase"`
+	RequireLowercase bool      `json:"require_lowercase" db:"require_lowercase"`
+	RequireDigit     bool      `json:"require_digit" db:"require_digit"`
+	RequireSymbol    bool      `json:"require_symbol" db:"require_symbol"`
+	HistorySize      int       `json:"history_size" db:"history_size"` // Previous passwords that cannot be reused
+	MaxAgeDays       int       `json:"max_age_days" db:"max_age_days"` // 0 means passwords never expire
+	CheckBreached    bool      `json:"check_breached" db:"check_breached"`
+	CreatedAt        time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// MaxPasswordHistory is the number of previous password hashes kept per user
+const MaxPasswordHistory = 24
+
+// DefaultPasswordPolicy returns the policy of tenants that did not configure one
+func DefaultPasswordPolicy() *PasswordPolicy {
+	return &PasswordPolicy{
+		MinLength:     8,
+		CheckBreached: true,
+	}
+}
+
+// Violations returns the requirements the password does not meet:
+// "min_length", "uppercase", "lowercase", "digit" or "symbol"
+func (p *PasswordPolicy) Violations(password string) []string {
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			hasSymbol = true
+		}
+	}
+
+	violations := []string{}
+	if utf8.RuneCountInString(password) < p.MinLength {
+		violations = append(violations, "min_length")
+	}
+	if p.RequireUppercase && !hasUpper {
+		violations = append(violations, "uppercase")
+	}
+	if p.RequireLowercase && !hasLower {
+		violations = append(violations, "lowercase")
+	}
+	if p.RequireDigit && !hasDigit {
+		violations = append(violations, "digit")
+	}
+	if p.RequireSymbol && !hasSymbol {
+		violations = append(violations, "symbol")
+	}
+	return violations
+}
+
+// IsPasswordExpired checks if a password set at changedAt exceeded the maximum age.
+// Passwords with an unknown change date never expire.
+func (p *PasswordPolicy) IsPasswordExpired(changedAt *time.Time, now time.Time) bool {
+	if p.MaxAgeDays <= 0 || changedAt == nil {
+		return false
+	}
+	return now.After(changedAt.AddDate(0, 0, p.MaxAgeDays))
+}
+
 // UserMembership represents a user's membership in a tenant
 // This is used to retrieve tenant information during login when the user
 // doesn't have a direct tenant_id in their user record
@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"strings"
 	"testing"
 	"time"
 )
@@ -29,6 +30,47 @@ func TestIsValidRole(t *testing.T) {
 	}
 }
 
+func TestIsValidPermission(t *testing.T) {
+	if !IsValidPermission("submission.grade") {
+		t.Error("Expected submission.grade to be a valid permission")
+	}
+	if IsValidPermission("submission.delete") {
+		t.Error("Expected submission.delete not to be a valid permission")
+	}
+	if IsValidPermission("admin") {
+		t.Error("Expected a role name not to be a valid permission")
+	}
+}
+
+func TestResolvePermissions(t *testing.T) {
+	tests := []struct {
+		name     string
+		role     string
+		custom   []string
+		expected string
+	}{
+		{"Student", "student", nil, "course.view"},
+		{"Student with teaching assistant role", "student", []string{"submission.view", "submission.grade"}, "course.view,submission.view,submission.grade"},
+		{"Duplicates and unknown permissions are dropped", "student", []string{"course.view", "analytics.view", "everything"}, "course.view,analytics.view"},
+		{"Custom role without built-in role", "", []string{"analytics.view"}, "analytics.view"},
+		{"Unknown role", "guest", nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := strings.Join(ResolvePermissions(tt.role, tt.custom), ",")
+			if result != tt.expected {
+				t.Errorf("ResolvePermissions(%q, %v) = %s, want %s", tt.role, tt.custom, result, tt.expected)
+			}
+		})
+	}
+
+	// Admins are granted the whole catalog
+	if len(ResolvePermissions("admin", nil)) != len(PermissionCatalog) {
+		t.Error("Expected admin to be granted every permission")
+	}
+}
+
 func TestVerificationToken_IsExpired(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -176,6 +218,32 @@ func TestPasswordResetToken_IsValid(t *testing.T) {
 	}
 }
 
+func TestMagicLinkToken_IsExpired(t *testing.T) {
+	t.Run("Valid", func(t *testing.T) {
+		token := &MagicLinkToken{
+			TokenHash: HashAPIToken("magic-token"),
+			ExpiresAt: time.Now().Add(15 * time.Minute),
+			CreatedAt: time.Now(),
+		}
+
+		if token.IsExpired() {
+			t.Error("Expected token not to be expired")
+		}
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		token := &MagicLinkToken{
+			TokenHash: HashAPIToken("magic-token"),
+			ExpiresAt: time.Now().Add(-1 * time.Minute),
+			CreatedAt: time.Now().Add(-16 * time.Minute),
+		}
+
+		if !token.IsExpired() {
+			t.Error("Expected token to be expired")
+		}
+	})
+}
+
 func TestRefreshToken_IsRevoked(t *testing.T) {
 	t.Run("Not revoked", func(t *testing.T) {
 		token := &RefreshToken{
@@ -364,7 +432,147 @@ func TestUser_CanManageCourses(t *testing.T) {
 	}
 }
 
+func TestAPIToken_IsValid(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name     string
+		token    *APIToken
+		expected bool
+	}{
+		{"Token without expiry", &APIToken{}, true},
+		{"Token not yet expired", &APIToken{ExpiresAt: timePtr(now.Add(time.Hour))}, true},
+		{"Expired token", &APIToken{ExpiresAt: timePtr(now.Add(-time.Hour))}, false},
+		{"Revoked token", &APIToken{RevokedAt: timePtr(now)}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := tt.token.IsValid(); result != tt.expected {
+				t.Errorf("IsValid() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestAPIToken_HasScope(t *testing.T) {
+	token := &APIToken{Scopes: []string{"courses:read", "enrollments:write"}}
+
+	tests := []struct {
+		scope    string
+		expected bool
+	}{
+		{"courses:read", true},
+		{"courses:write", false},
+		{"enrollments:read", true},
+		{"enrollments:write", true},
+		{"lessons:read", false},
+		{"courses", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.scope, func(t *testing.T) {
+			if result := token.HasScope(tt.scope); result != tt.expected {
+				t.Errorf("HasScope(%q) = %v, want %v", tt.scope, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestIsValidAPITokenScope(t *testing.T) {
+	tests := []struct {
+		scope    string
+		expected bool
+	}{
+		{"courses:read", true},
+		{"enrollments:write", true},
+		{"courses:delete", false},
+		{"tenants:read", false},
+		{"courses", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.scope, func(t *testing.T) {
+			if result := IsValidAPITokenScope(tt.scope); result != tt.expected {
+				t.Errorf("IsValidAPITokenScope(%q) = %v, want %v", tt.scope, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestIsAPIToken(t *testing.T) {
+	if !IsAPIToken(PersonalAccessTokenPrefix + "abc") {
+		t.Error("Expected personal access token to be recognized")
+	}
+	if !IsAPIToken(APIKeyPrefix + "abc") {
+		t.Error("Expected API key to be recognized")
+	}
+	if IsAPIToken("eyJhbGciOiJIUzI1NiJ9.e30.sig") {
+		t.Error("Expected JWT not to be recognized as API token")
+	}
+}
+
 // Helper function to create a pointer to time.Time
+func TestPasswordPolicy_Violations(t *testing.T) {
+	strict := &PasswordPolicy{
+		MinLength:        10,
+		RequireUppercase: true,
+		RequireLowercase: true,
+		RequireDigit:     true,
+		RequireSymbol:    true,
+	}
+
+	tests := []struct {
+		name     string
+		policy   *PasswordPolicy
+		password string
+		expected []string
+	}{
+		{"Default policy accepts 8 characters", DefaultPasswordPolicy(), "abcdefgh", []string{}},
+		{"Default policy rejects short password", DefaultPasswordPolicy(), "abcdefg", []string{"min_length"}},
+		{"Length counts characters, not bytes", &PasswordPolicy{MinLength: 8}, "contraseña", []string{}},
+		{"Strict policy accepts complex password", strict, "Correct-Horse1", []string{}},
+		{"Strict policy reports every missing class", strict, "short", []string{"min_length", "uppercase", "digit", "symbol"}},
+		{"Missing symbol", strict, "CorrectHorse1", []string{"symbol"}},
+		{"Missing uppercase", strict, "correct-horse1", []string{"uppercase"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.policy.Violations(tt.password)
+			if strings.Join(result, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("Violations(%q) = %v, want %v", tt.password, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPasswordPolicy_IsPasswordExpired(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name       string
+		maxAgeDays int
+		changedAt  *time.Time
+		expected   bool
+	}{
+		{"No maximum age", 0, timePtr(now.AddDate(-5, 0, 0)), false},
+		{"Unknown change date", 90, nil, false},
+		{"Recent password", 90, timePtr(now.AddDate(0, 0, -30)), false},
+		{"Old password", 90, timePtr(now.AddDate(0, 0, -91)), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := &PasswordPolicy{MaxAgeDays: tt.maxAgeDays}
+			if result := policy.IsPasswordExpired(tt.changedAt, now); result != tt.expected {
+				t.Errorf("IsPasswordExpired() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
 func timePtr(t time.Time) *time.Time {
 	return &t
 }
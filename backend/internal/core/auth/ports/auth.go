@@ -2,6 +2,7 @@ package ports
 
 import (
 	"context"
+	"time"
 
 	"github.com/DanielIturra1610/stegmaier-landing/internal/core/auth/domain"
 )
@@ -15,6 +16,13 @@ type EmailService interface {
 
 	// SendPasswordResetEmail sends a password reset email with reset link.
 	SendPasswordResetEmail(ctx context.Context, to, userName, resetToken string) error
+
+	// SendAccountLockedEmail notifies a user that repeated failed logins locked the account,
+	// with a link to unlock it.
+	SendAccountLockedEmail(ctx context.Context, to, userName, unlockToken string, lockedFor time.Duration) error
+
+	// SendMagicLinkEmail sends a single-use link that logs the user in without a password.
+	SendMagicLinkEmail(ctx context.Context, to, userName, loginToken string, expiresIn time.Duration) error
 }
 
 // AuthRepository defines the interface for authentication data persistence.
@@ -92,6 +100,29 @@ type AuthRepository interface {
 	// This is useful when a user is deleted or changes their password.
 	DeletePasswordResetTokensByUserID(ctx context.Context, userID string) error
 
+	// Magic link operations
+
+	// CreateMagicLinkToken persists a new passwordless login token.
+	// Returns an error if the operation fails.
+	CreateMagicLinkToken(ctx context.Context, token *domain.MagicLinkToken) error
+
+	// ConsumeMagicLinkToken removes a login token by the hash of its value and returns it, so
+	// that each link works once.
+	// Returns ErrTokenNotFound if the token doesn't exist or was already used.
+	ConsumeMagicLinkToken(ctx context.Context, tokenHash string) (*domain.MagicLinkToken, error)
+
+	// DeleteMagicLinkTokensByUserID removes all login tokens for a user.
+	// This is used to invalidate previous links when a new one is requested.
+	DeleteMagicLinkTokensByUserID(ctx context.Context, userID string) error
+
+	// GetTenantMagicLinkEnabled checks if a tenant allows login with links sent by email.
+	// Returns ErrTenantNotFound if the tenant doesn't exist.
+	GetTenantMagicLinkEnabled(ctx context.Context, tenantID string) (bool, error)
+
+	// UpdateTenantMagicLinkEnabled enables or disables login with links sent by email in a tenant.
+	// Returns ErrTenantNotFound if the tenant doesn't exist.
+	UpdateTenantMagicLinkEnabled(ctx context.Context, tenantID string, enabled bool) error
+
 	// Refresh token operations
 
 	// CreateRefreshToken persists a new refresh token.
@@ -106,6 +137,21 @@ type AuthRepository interface {
 	// Returns an error if the token doesn't exist or if the operation fails.
 	RevokeRefreshToken(ctx context.Context, tokenID string) error
 
+	// RotateRefreshToken revokes the parent token and persists its replacement atomically.
+	// Returns ErrTokenRevoked if the parent was already revoked (e.g. used concurrently).
+	RotateRefreshToken(ctx context.Context, parentID string, token *domain.RefreshToken) error
+
+	// IsRefreshTokenRotated checks whether a refresh token was already exchanged for a new one.
+	IsRefreshTokenRotated(ctx context.Context, tokenID string) (bool, error)
+
+	// ListUserSessions retrieves the active sessions of a user, one per refresh token family,
+	// with the device metadata of the latest token and the time the family was created.
+	ListUserSessions(ctx context.Context, userID string) ([]*domain.Session, error)
+
+	// RevokeUserSession revokes every token of a session (refresh token family) owned by the user.
+	// Returns ErrSessionNotFound if the session doesn't exist, is already revoked or belongs to another user.
+	RevokeUserSession(ctx context.Context, userID string, sessionID string) error
+
 	// RevokeAllUserRefreshTokens revokes all refresh tokens for a specific user.
 	// This is useful for "logout from all devices" functionality.
 	RevokeAllUserRefreshTokens(ctx context.Context, userID string) error
@@ -114,6 +160,249 @@ type AuthRepository interface {
 	// This should be called periodically to clean up old tokens.
 	DeleteExpiredRefreshTokens(ctx context.Context) error
 
+	// Multi-factor authentication operations
+
+	// GetUserMFA retrieves a user's TOTP enrollment.
+	// Returns ErrMFANotEnrolled if the user has never started an enrollment.
+	GetUserMFA(ctx context.Context, userID string) (*domain.UserMFA, error)
+
+	// SaveUserMFA creates or replaces a user's TOTP enrollment.
+	// This is used when starting a new (not yet enabled) enrollment.
+	SaveUserMFA(ctx context.Context, mfa *domain.UserMFA) error
+
+	// EnableUserMFA marks a user's TOTP enrollment as enabled.
+	// Returns ErrMFANotEnrolled if the user has no enrollment.
+	EnableUserMFA(ctx context.Context, userID string) error
+
+	// UpdateMFALastUsedStep records the last accepted TOTP time step.
+	// Returns ErrMFACodeInvalid if the step is not newer than the stored one (replayed code).
+	UpdateMFALastUsedStep(ctx context.Context, userID string, step int64) error
+
+	// DeleteUserMFA removes a user's TOTP enrollment and recovery codes.
+	DeleteUserMFA(ctx context.Context, userID string) error
+
+	// ReplaceRecoveryCodes deletes existing recovery codes and stores the given hashes.
+	ReplaceRecoveryCodes(ctx context.Context, userID string, codeHashes []string) error
+
+	// UseRecoveryCode marks an unused recovery code as used.
+	// Returns ErrMFARecoveryCodeInvalid if no unused code matches the hash.
+	UseRecoveryCode(ctx context.Context, userID string, codeHash string) error
+
+	// CountUnusedRecoveryCodes returns the number of recovery codes still available.
+	CountUnusedRecoveryCodes(ctx context.Context, userID string) (int, error)
+
+	// CreateMFAChallenge persists a new login MFA challenge.
+	CreateMFAChallenge(ctx context.Context, challenge *domain.MFAChallenge) error
+
+	// GetMFAChallenge retrieves a login MFA challenge by the SHA-256 hash of its token.
+	// Returns ErrTokenNotFound if the challenge doesn't exist.
+	GetMFAChallenge(ctx context.Context, tokenHash string) (*domain.MFAChallenge, error)
+
+	// IncrementMFAChallengeAttempts increments the failed attempts counter of a challenge.
+	IncrementMFAChallengeAttempts(ctx context.Context, challengeID string) error
+
+	// DeleteMFAChallenge removes a login MFA challenge by its ID.
+	DeleteMFAChallenge(ctx context.Context, challengeID string) error
+
+	// GetTenantMFARequiredRoles retrieves the membership roles that must use MFA in a tenant.
+	// Returns ErrTenantNotFound if the tenant doesn't exist.
+	GetTenantMFARequiredRoles(ctx context.Context, tenantID string) ([]string, error)
+
+	// UpdateTenantMFARequiredRoles replaces the membership roles that must use MFA in a tenant.
+	// Returns ErrTenantNotFound if the tenant doesn't exist.
+	UpdateTenantMFARequiredRoles(ctx context.Context, tenantID string, roles []string) error
+
+	// OpenID Connect operations
+
+	// GetOIDCProvider retrieves the OIDC identity provider configured for a tenant.
+	// Returns ErrOIDCNotConfigured if the tenant has none.
+	GetOIDCProvider(ctx context.Context, tenantID string) (*domain.OIDCProvider, error)
+
+	// SaveOIDCProvider creates or replaces the OIDC identity provider of a tenant.
+	SaveOIDCProvider(ctx context.Context, provider *domain.OIDCProvider) error
+
+	// DeleteOIDCProvider removes the OIDC identity provider of a tenant.
+	// Returns ErrOIDCNotConfigured if the tenant has none.
+	DeleteOIDCProvider(ctx context.Context, tenantID string) error
+
+	// CreateOIDCAuthRequest persists a pending authorization request (state, nonce, PKCE verifier).
+	CreateOIDCAuthRequest(ctx context.Context, request *domain.OIDCAuthRequest) error
+
+	// ConsumeOIDCAuthRequest retrieves and deletes a pending authorization request by its state,
+	// so each request can only be completed once.
+	// Returns ErrOIDCStateInvalid if no request matches the state.
+	ConsumeOIDCAuthRequest(ctx context.Context, state string) (*domain.OIDCAuthRequest, error)
+
+	// GetUserIdentity retrieves the user linked to a subject at an identity provider.
+	// Returns nil, nil if the identity is not linked to any user.
+	GetUserIdentity(ctx context.Context, issuer string, subject string) (*domain.UserIdentity, error)
+
+	// CreateUserIdentity links an existing user to a subject at an identity provider.
+	CreateUserIdentity(ctx context.Context, identity *domain.UserIdentity) error
+
+	// UpdateUserIdentityLogin records a login through an external identity.
+	UpdateUserIdentityLogin(ctx context.Context, identityID string, email string) error
+
+	// CreateFederatedUser creates a user provisioned by an identity provider together
+	// with its identity link and an active tenant membership with the given role.
+	CreateFederatedUser(ctx context.Context, user *domain.User, identity *domain.UserIdentity, role string) error
+
+	// EnsureMembership creates an active membership with the given role if the user
+	// has none in the tenant, and returns the user's membership whatever its status.
+	EnsureMembership(ctx context.Context, userID string, tenantID string, role string) (*domain.UserMembership, error)
+
+	// UpdateMembershipRole changes the role of a user's membership in a tenant.
+	UpdateMembershipRole(ctx context.Context, userID string, tenantID string, role string) error
+
+	// UpdateUserTenant sets the tenant a user is currently working in.
+	UpdateUserTenant(ctx context.Context, userID string, tenantID string) error
+
+	// HasTenantMembership checks whether a user has a membership in a tenant, whatever its status.
+	HasTenantMembership(ctx context.Context, userID string, tenantID string) (bool, error)
+
+	// IsEmailDomainVerified checks whether a tenant has verified ownership of an email domain.
+	IsEmailDomainVerified(ctx context.Context, tenantID string, emailDomain string) (bool, error)
+
+	// CreateFederatedLinkRequest persists a single sign-on login waiting for the user to confirm the link.
+	CreateFederatedLinkRequest(ctx context.Context, request *domain.FederatedLinkRequest) error
+
+	// GetFederatedLinkRequest retrieves a link request by the SHA-256 hash of its token.
+	// Returns ErrTokenNotFound if no request matches.
+	GetFederatedLinkRequest(ctx context.Context, tokenHash string) (*domain.FederatedLinkRequest, error)
+
+	// IncrementFederatedLinkAttempts increments the failed confirmation attempts of a link request.
+	IncrementFederatedLinkAttempts(ctx context.Context, requestID string) error
+
+	// DeleteFederatedLinkRequest removes a link request by its ID.
+	DeleteFederatedLinkRequest(ctx context.Context, requestID string) error
+
+	// SAML operations
+
+	// GetSAMLProvider retrieves the SAML identity provider configured for a tenant.
+	// Returns ErrSAMLNotConfigured if the tenant has none.
+	GetSAMLProvider(ctx context.Context, tenantID string) (*domain.SAMLProvider, error)
+
+	// SaveSAMLProvider creates or replaces the SAML identity provider of a tenant.
+	SaveSAMLProvider(ctx context.Context, provider *domain.SAMLProvider) error
+
+	// DeleteSAMLProvider removes the SAML identity provider of a tenant.
+	// Returns ErrSAMLNotConfigured if the tenant has none.
+	DeleteSAMLProvider(ctx context.Context, tenantID string) error
+
+	// CreateSAMLAuthRequest persists the ID of a pending AuthnRequest.
+	CreateSAMLAuthRequest(ctx context.Context, request *domain.SAMLAuthRequest) error
+
+	// ConsumeSAMLAuthRequest retrieves and deletes a pending AuthnRequest by its ID,
+	// so each request can only be answered once.
+	// Returns ErrSAMLRequestInvalid if no request matches the ID.
+	ConsumeSAMLAuthRequest(ctx context.Context, requestID string) (*domain.SAMLAuthRequest, error)
+
+	// CreateSAMLLoginCode persists a single-use login code.
+	CreateSAMLLoginCode(ctx context.Context, code *domain.SAMLLoginCode) error
+
+	// ConsumeSAMLLoginCode retrieves and deletes a login code.
+	// Returns ErrSAMLLoginCodeInvalid if the code doesn't exist.
+	ConsumeSAMLLoginCode(ctx context.Context, code string) (*domain.SAMLLoginCode, error)
+
+	// WebAuthn operations
+
+	// CreateWebAuthnCredential persists a newly registered passkey or security key.
+	CreateWebAuthnCredential(ctx context.Context, credential *domain.WebAuthnCredential) error
+
+	// GetWebAuthnCredential retrieves a credential by its base64url credential ID.
+	// Returns ErrWebAuthnCredentialNotFound if the credential is not registered.
+	GetWebAuthnCredential(ctx context.Context, credentialID string) (*domain.WebAuthnCredential, error)
+
+	// ListWebAuthnCredentials lists the credentials registered by a user, oldest first.
+	ListWebAuthnCredentials(ctx context.Context, userID string) ([]*domain.WebAuthnCredential, error)
+
+	// CountWebAuthnCredentials returns the number of credentials registered by a user.
+	CountWebAuthnCredentials(ctx context.Context, userID string) (int, error)
+
+	// UpdateWebAuthnCredentialUse stores the signature counter and time of the latest use of a credential.
+	UpdateWebAuthnCredentialUse(ctx context.Context, id string, signCount int64) error
+
+	// DeleteWebAuthnCredential removes one of the user's credentials.
+	// Returns ErrWebAuthnCredentialNotFound if the user has no such credential.
+	DeleteWebAuthnCredential(ctx context.Context, userID string, id string) error
+
+	// CreateWebAuthnChallenge persists the challenge of a pending ceremony.
+	CreateWebAuthnChallenge(ctx context.Context, challenge *domain.WebAuthnChallenge) error
+
+	// ConsumeWebAuthnChallenge retrieves and deletes a pending challenge of the given ceremony,
+	// so each challenge can only be answered once.
+	// Returns ErrWebAuthnChallengeInvalid if no challenge matches.
+	ConsumeWebAuthnChallenge(ctx context.Context, challenge string, ceremony domain.WebAuthnCeremony) (*domain.WebAuthnChallenge, error)
+
+	// Impersonation operations
+
+	// CreateImpersonationSession persists a new impersonation session.
+	CreateImpersonationSession(ctx context.Context, session *domain.ImpersonationSession) error
+
+	// GetImpersonationSessionByTokenID retrieves the session of an impersonation access token by its jti.
+	// Returns ErrImpersonationSessionInvalid if no session matches.
+	GetImpersonationSessionByTokenID(ctx context.Context, tokenID string) (*domain.ImpersonationSession, error)
+
+	// EndImpersonationSession marks an active session as stopped.
+	// Returns ErrImpersonationSessionInvalid if the session doesn't exist or was already stopped.
+	EndImpersonationSession(ctx context.Context, sessionID string) error
+
+	// ListImpersonationSessions lists the impersonation sessions of a tenant, newest first.
+	ListImpersonationSessions(ctx context.Context, tenantID string) ([]*domain.ImpersonationSession, error)
+
+	// RecordImpersonationEvent adds an entry to the impersonation audit trail.
+	RecordImpersonationEvent(ctx context.Context, event *domain.ImpersonationEvent) error
+
+	// ListImpersonationEvents lists the audit trail of a session of the tenant, oldest first.
+	// Returns ErrImpersonationSessionNotFound if the tenant has no such session.
+	ListImpersonationEvents(ctx context.Context, tenantID string, sessionID string) ([]*domain.ImpersonationEvent, error)
+
+	// API token operations
+
+	// CreateAPIToken persists a personal access token or tenant API key.
+	CreateAPIToken(ctx context.Context, token *domain.APIToken) error
+
+	// GetAPITokenByHash retrieves a token by the hash of its plaintext value.
+	// Returns ErrAPITokenInvalid if no token matches.
+	GetAPITokenByHash(ctx context.Context, tokenHash string) (*domain.APIToken, error)
+
+	// ListPersonalAccessTokens lists the non-revoked personal access tokens of a user, newest first.
+	ListPersonalAccessTokens(ctx context.Context, userID string) ([]*domain.APIToken, error)
+
+	// ListTenantAPIKeys lists the non-revoked API keys of a tenant, newest first.
+	ListTenantAPIKeys(ctx context.Context, tenantID string) ([]*domain.APIToken, error)
+
+	// RevokePersonalAccessToken revokes one of the user's personal access tokens.
+	// Returns ErrAPITokenNotFound if the user has no such active token.
+	RevokePersonalAccessToken(ctx context.Context, userID string, tokenID string) error
+
+	// RevokeTenantAPIKey revokes one of the tenant's API keys.
+	// Returns ErrAPITokenNotFound if the tenant has no such active key.
+	RevokeTenantAPIKey(ctx context.Context, tenantID string, tokenID string) error
+
+	// RecordAPITokenUse stores the time and client address of the latest use of a token.
+	RecordAPITokenUse(ctx context.Context, tokenID string, ipAddress string) error
+
+	// Password policy operations
+
+	// GetTenantPasswordPolicy retrieves the password policy of a tenant.
+	// Returns nil, nil if the tenant uses the default policy.
+	GetTenantPasswordPolicy(ctx context.Context, tenantID string) (*domain.PasswordPolicy, error)
+
+	// UpsertTenantPasswordPolicy creates or replaces the password policy of a tenant.
+	UpsertTenantPasswordPolicy(ctx context.Context, policy *domain.PasswordPolicy) error
+
+	// GetPasswordHistory retrieves the hashes of the user's most recent passwords, newest first.
+	GetPasswordHistory(ctx context.Context, userID string, limit int) ([]string, error)
+
+	// RecordPasswordChange adds the new password hash to the user's history, keeping the
+	// last MaxPasswordHistory hashes, and resets the password age and forced change flag.
+	RecordPasswordChange(ctx context.Context, userID string, passwordHash string) error
+
+	// SetMustChangePassword sets whether the user must change the password before using the platform.
+	// Returns ErrUserNotFound if the user doesn't exist.
+	SetMustChangePassword(ctx context.Context, userID string, mustChange bool) error
+
 	// Utility operations
 
 	// EmailExists checks if an email address is already registered.
@@ -128,6 +417,35 @@ type AuthRepository interface {
 	// This is used during login to determine the user's tenant when they don't have
 	// a tenant_id set in their user record. Returns nil, nil if no membership exists.
 	GetFirstActiveMembership(ctx context.Context, userID string) (*domain.UserMembership, error)
+
+	// GetActiveMembership retrieves a user's active membership in a specific tenant.
+	// Returns nil, nil if the user has no active membership in the tenant.
+	GetActiveMembership(ctx context.Context, userID string, tenantID string) (*domain.UserMembership, error)
+
+	// Tenant role operations
+
+	// ListTenantRoles retrieves the custom roles defined by a tenant, ordered by name.
+	ListTenantRoles(ctx context.Context, tenantID string) ([]*domain.TenantRole, error)
+
+	// GetTenantRole retrieves a custom role of a tenant.
+	// Returns ErrTenantRoleNotFound if the role doesn't exist in the tenant.
+	GetTenantRole(ctx context.Context, tenantID string, roleID string) (*domain.TenantRole, error)
+
+	// CreateTenantRole persists a new custom role.
+	// Returns ErrTenantRoleExists if the tenant already has a role with the same name.
+	CreateTenantRole(ctx context.Context, role *domain.TenantRole) error
+
+	// UpdateTenantRole updates the name, description and permissions of a custom role.
+	// Returns ErrTenantRoleNotFound or ErrTenantRoleExists on failure.
+	UpdateTenantRole(ctx context.Context, role *domain.TenantRole) error
+
+	// DeleteTenantRole removes a custom role; members holding it keep their built-in role.
+	// Returns ErrTenantRoleNotFound if the role doesn't exist in the tenant.
+	DeleteTenantRole(ctx context.Context, tenantID string, roleID string) error
+
+	// SetMembershipCustomRole assigns a custom role to a user's membership in a tenant, or removes it when roleID is nil.
+	// Returns ErrUserNotFound if the user is not a member of the tenant.
+	SetMembershipCustomRole(ctx context.Context, userID string, tenantID string, roleID *string) error
 }
 
 // UserRepository defines extended user management operations.
@@ -187,6 +505,9 @@ type AuthService interface {
 	// Login authenticates a user with email and password.
 	// It validates credentials, checks if the account is verified,
 	// generates JWT access and refresh tokens.
+	// When the user has MFA enabled (or the tenant requires it for their role),
+	// no tokens are issued; the response carries a short-lived MFA token instead
+	// that must be completed with VerifyMFALogin.
 	// Returns ErrInvalidCredentials if credentials are incorrect,
 	// or ErrAccountNotVerified if the email hasn't been verified.
 	Login(ctx context.Context, tenantID string, dto *domain.LoginDTO) (*domain.AuthResponse, error)
@@ -197,6 +518,8 @@ type AuthService interface {
 
 	// RefreshAccessToken generates a new access token using a valid refresh token.
 	// This allows users to maintain their session without re-authenticating.
+	// The refresh token is rotated on every call; presenting an already rotated
+	// token revokes every refresh token of the user and returns ErrRefreshTokenReused.
 	// Returns ErrRefreshTokenInvalid if the refresh token is invalid or expired.
 	RefreshAccessToken(ctx context.Context, dto *domain.RefreshTokenDTO) (*domain.AuthResponse, error)
 
@@ -205,6 +528,16 @@ type AuthService interface {
 	// (e.g., when password is changed or suspicious activity is detected).
 	RevokeAllSessions(ctx context.Context, userID string) error
 
+	// Session management operations
+
+	// ListSessions returns the user's active sessions with device information
+	// (user agent, IP address, login time and last use).
+	ListSessions(ctx context.Context, userID string) ([]*domain.Session, error)
+
+	// RevokeSession logs out a single device by revoking its session.
+	// Returns ErrSessionNotFound if the session doesn't belong to the user.
+	RevokeSession(ctx context.Context, userID string, sessionID string) error
+
 	// Email verification operations
 
 	// VerifyEmail verifies a user's email address using a verification token.
@@ -234,6 +567,34 @@ type AuthService interface {
 	// Returns ErrCurrentPasswordIncorrect if the current password is wrong.
 	ChangePassword(ctx context.Context, userID string, dto *domain.ChangePasswordDTO) error
 
+	// Account lockout operations
+
+	// UnlockAccount lifts a lockout with the token from the account locked email.
+	// Returns ErrUnlockTokenInvalid if the token is unknown or expired.
+	UnlockAccount(ctx context.Context, dto *domain.UnlockAccountDTO) error
+
+	// UnlockUser lets a tenant admin lift the lockout and failed login delays of a member.
+	// Returns ErrUserNotFound if the user is not a member of the tenant.
+	UnlockUser(ctx context.Context, tenantID string, userID string) error
+
+	// Magic link operations
+
+	// RequestMagicLink emails a single-use login link if the tenant enabled magic links.
+	// Like ForgotPassword, it returns success even if the email doesn't exist.
+	// Returns ErrMagicLinkDisabled if the tenant doesn't allow magic links.
+	RequestMagicLink(ctx context.Context, tenantID string, dto *domain.MagicLinkRequestDTO) error
+
+	// LoginWithMagicLink redeems a login link and completes the login like Login does,
+	// returning an MFA challenge if the user must provide a second factor.
+	// Returns ErrMagicLinkInvalid if the link is unknown, already used or expired.
+	LoginWithMagicLink(ctx context.Context, dto *domain.MagicLinkLoginDTO) (*domain.AuthResponse, error)
+
+	// GetMagicLinkSettings returns whether the tenant allows magic link login.
+	GetMagicLinkSettings(ctx context.Context, tenantID string) (*domain.MagicLinkSettingsDTO, error)
+
+	// UpdateMagicLinkSettings enables or disables magic link login in the tenant.
+	UpdateMagicLinkSettings(ctx context.Context, tenantID string, dto *domain.MagicLinkSettingsDTO) (*domain.MagicLinkSettingsDTO, error)
+
 	// User profile operations
 
 	// GetCurrentUser retrieves the authenticated user's profile information.
@@ -252,6 +613,234 @@ type AuthService interface {
 	// updates the active role, and generates a new JWT token with the new active role.
 	// Returns ErrRoleNotAssigned if the role is not assigned to the user.
 	SwitchRole(ctx context.Context, userID string, tenantID string, dto *domain.SwitchRoleDTO) (*domain.SwitchRoleResponse, error)
+
+	// Multi-factor authentication operations
+
+	// VerifyMFALogin completes a login that returned an MFA challenge.
+	// It accepts either a TOTP code or a single-use recovery code. When the user
+	// enrolled during login (tenant policy), the first valid code enables MFA and
+	// the response includes the new recovery codes.
+	// Returns ErrMFAChallengeInvalid, ErrMFAChallengeExpired or ErrMFACodeInvalid on failure,
+	// and ErrMFANotEnrolled for a pending enrollment on a challenge that wasn't issued to enroll.
+	VerifyMFALogin(ctx context.Context, dto *domain.MFALoginDTO) (*domain.AuthResponse, error)
+
+	// EnrollMFAWithChallenge starts a TOTP enrollment using a login MFA challenge.
+	// This is used when the tenant requires MFA and the user has no second factor yet; other
+	// challenges return ErrMFAChallengeInvalid.
+	EnrollMFAWithChallenge(ctx context.Context, dto *domain.MFAChallengeDTO) (*domain.MFAEnrollmentResponse, error)
+
+	// GetMFAStatus returns the MFA status of the authenticated user.
+	GetMFAStatus(ctx context.Context, userID string, tenantID string) (*domain.MFAStatusResponse, error)
+
+	// EnrollMFA generates a new TOTP secret and otpauth URI for the user.
+	// MFA is not active until EnableMFA is called with a valid code.
+	// Returns ErrMFAAlreadyEnabled if MFA is already active.
+	EnrollMFA(ctx context.Context, userID string) (*domain.MFAEnrollmentResponse, error)
+
+	// EnableMFA activates a pending enrollment after verifying a code from the
+	// authenticator app, and returns a fresh set of single-use recovery codes.
+	// Returns ErrMFANotEnrolled if EnrollMFA wasn't called first.
+	EnableMFA(ctx context.Context, userID string, dto *domain.MFACodeDTO) (*domain.MFARecoveryCodesResponse, error)
+
+	// DisableMFA removes the user's TOTP enrollment after verifying a current code.
+	DisableMFA(ctx context.Context, userID string, dto *domain.MFACodeDTO) error
+
+	// RegenerateRecoveryCodes replaces the user's recovery codes after verifying a current code.
+	RegenerateRecoveryCodes(ctx context.Context, userID string, dto *domain.MFACodeDTO) (*domain.MFARecoveryCodesResponse, error)
+
+	// GetMFAPolicy returns the tenant roles that must use MFA.
+	GetMFAPolicy(ctx context.Context, tenantID string) (*domain.MFAPolicyDTO, error)
+
+	// UpdateMFAPolicy updates the tenant roles that must use MFA.
+	UpdateMFAPolicy(ctx context.Context, tenantID string, dto *domain.MFAPolicyDTO) (*domain.MFAPolicyDTO, error)
+
+	// WebAuthn operations
+
+	// BeginWebAuthnRegistration returns the options to create a new passkey for the user.
+	BeginWebAuthnRegistration(ctx context.Context, userID string) (*domain.WebAuthnOptionsResponse, error)
+
+	// FinishWebAuthnRegistration verifies the new credential and stores it for the user.
+	// Returns ErrWebAuthnChallengeInvalid, ErrWebAuthnVerificationFailed or ErrWebAuthnCredentialExists on failure.
+	FinishWebAuthnRegistration(ctx context.Context, userID string, dto *domain.WebAuthnRegistrationDTO) (*domain.WebAuthnCredential, error)
+
+	// ListWebAuthnCredentials lists the passkeys and security keys registered by the user.
+	ListWebAuthnCredentials(ctx context.Context, userID string) ([]*domain.WebAuthnCredential, error)
+
+	// DeleteWebAuthnCredential removes one of the user's credentials.
+	DeleteWebAuthnCredential(ctx context.Context, userID string, credentialID string) error
+
+	// BeginWebAuthnLogin returns the options for a passwordless login. When an email is
+	// given, only that user's credentials are offered; otherwise the browser lets the
+	// user pick any passkey stored for the site.
+	BeginWebAuthnLogin(ctx context.Context, tenantID string, dto *domain.WebAuthnLoginBeginDTO) (*domain.WebAuthnOptionsResponse, error)
+
+	// FinishWebAuthnLogin verifies a passkey assertion and issues the normal access/refresh
+	// token pair. The passkey is both factors, so no MFA challenge follows.
+	// Returns ErrWebAuthnChallengeInvalid or ErrWebAuthnVerificationFailed on failure.
+	FinishWebAuthnLogin(ctx context.Context, tenantID string, dto *domain.WebAuthnLoginDTO) (*domain.AuthResponse, error)
+
+	// BeginWebAuthnMFA returns the options to complete a login MFA challenge with one of
+	// the user's credentials. Returns ErrMFANotEnrolled if the user has none.
+	BeginWebAuthnMFA(ctx context.Context, dto *domain.MFAChallengeDTO) (*domain.WebAuthnOptionsResponse, error)
+
+	// FinishWebAuthnMFA completes a login MFA challenge with a credential assertion.
+	// Returns ErrMFAChallengeInvalid, ErrMFAChallengeExpired or ErrWebAuthnVerificationFailed on failure.
+	FinishWebAuthnMFA(ctx context.Context, dto *domain.WebAuthnMFALoginDTO) (*domain.AuthResponse, error)
+
+	// OpenID Connect operations
+
+	// BeginOIDCLogin starts a login with the tenant's identity provider using the
+	// authorization code flow with PKCE, and returns the URL the browser must visit.
+	// Returns ErrOIDCNotConfigured if the tenant has no enabled provider.
+	BeginOIDCLogin(ctx context.Context, tenantID string) (*domain.OIDCAuthorizeResponse, error)
+
+	// CompleteOIDCLogin handles the provider callback: it exchanges the code, validates
+	// the ID token, creates or links the user and tenant membership on first login,
+	// and issues the normal access/refresh token pair.
+	// Returns ErrOIDCStateInvalid or ErrOIDCAuthenticationFailed on failure.
+	CompleteOIDCLogin(ctx context.Context, dto *domain.OIDCCallbackDTO) (*domain.AuthResponse, error)
+
+	// GetOIDCProvider returns the tenant's identity provider configuration (without the client secret).
+	GetOIDCProvider(ctx context.Context, tenantID string) (*domain.OIDCProvider, error)
+
+	// UpdateOIDCProvider creates or updates the tenant's identity provider configuration.
+	// The stored client secret is kept when the DTO doesn't include one.
+	UpdateOIDCProvider(ctx context.Context, tenantID string, dto *domain.OIDCProviderDTO) (*domain.OIDCProvider, error)
+
+	// DeleteOIDCProvider removes the tenant's identity provider configuration.
+	DeleteOIDCProvider(ctx context.Context, tenantID string) error
+
+	// SAML operations
+
+	// GetSAMLMetadata returns the service provider metadata to register at the tenant's IdP.
+	// Returns ErrSAMLNotConfigured if the tenant has no provider.
+	GetSAMLMetadata(ctx context.Context, tenantID string) ([]byte, error)
+
+	// BeginSAMLLogin creates an AuthnRequest for the tenant's identity provider and
+	// returns the URL the browser must visit (HTTP-Redirect binding).
+	// Returns ErrSAMLNotConfigured if the tenant has no enabled provider.
+	BeginSAMLLogin(ctx context.Context, tenantID string) (*domain.SAMLAuthorizeResponse, error)
+
+	// CompleteSAMLLogin validates the response posted to the ACS endpoint, creates or links
+	// the user and tenant membership on first login, and returns the frontend URL the browser
+	// is sent to. The URL carries a one-time login code, or an error code if the login failed.
+	CompleteSAMLLogin(ctx context.Context, dto *domain.SAMLResponseDTO) string
+
+	// ExchangeSAMLLoginCode exchanges a one-time login code for the normal access/refresh token pair.
+	// Returns ErrSAMLLoginCodeInvalid if the code is unknown, already used or expired.
+	ExchangeSAMLLoginCode(ctx context.Context, dto *domain.SAMLExchangeDTO) (*domain.AuthResponse, error)
+
+	// ConfirmFederatedLink links a single sign-on identity to the existing account with the same
+	// email once the user proves they own it with their password, a TOTP code or a recovery code,
+	// and issues tokens. Returns ErrFederatedLinkInvalid if the link token is unknown, exhausted
+	// or expired, and ErrInvalidCredentials or an MFA error if the credential is wrong.
+	ConfirmFederatedLink(ctx context.Context, dto *domain.FederatedLinkDTO) (*domain.AuthResponse, error)
+
+	// GetSAMLProvider returns the tenant's SAML identity provider configuration
+	// together with the service provider endpoints to register at the IdP.
+	GetSAMLProvider(ctx context.Context, tenantID string) (*domain.SAMLProvider, error)
+
+	// UpdateSAMLProvider creates or updates the tenant's SAML identity provider configuration.
+	// Returns ErrSAMLCertificateInvalid if the certificate can't be parsed.
+	UpdateSAMLProvider(ctx context.Context, tenantID string, dto *domain.SAMLProviderDTO) (*domain.SAMLProvider, error)
+
+	// DeleteSAMLProvider removes the tenant's SAML identity provider configuration.
+	DeleteSAMLProvider(ctx context.Context, tenantID string) error
+
+	// Impersonation operations
+
+	// StartImpersonation lets a tenant admin act as another member of the tenant. It issues a
+	// time-boxed access token for the target user carrying the admin as impersonator, and
+	// records the start in the audit trail. Admins can't impersonate superadmins, other
+	// admins (only superadmins can) or themselves.
+	// Returns ErrImpersonationNotAllowed or ErrUserNotFound on failure.
+	StartImpersonation(ctx context.Context, tenantID string, impersonatorID string, impersonatorRole string, targetUserID string, dto *domain.StartImpersonationDTO) (*domain.ImpersonationResponse, error)
+
+	// StopImpersonation ends an impersonation session, invalidating its access token.
+	// Returns ErrImpersonationSessionInvalid if the session already ended.
+	StopImpersonation(ctx context.Context, sessionID string, session domain.SessionMetadata) error
+
+	// ListImpersonationSessions lists the impersonation sessions of the tenant.
+	ListImpersonationSessions(ctx context.Context, tenantID string) ([]*domain.ImpersonationSession, error)
+
+	// ListImpersonationEvents returns the audit trail of an impersonation session of the tenant.
+	ListImpersonationEvents(ctx context.Context, tenantID string, sessionID string) ([]*domain.ImpersonationEvent, error)
+
+	// API token operations
+
+	// CreatePersonalAccessToken creates a scoped token that acts as the user in the tenant.
+	// The plaintext token is only returned here.
+	// Returns ErrAPITokenScopeInvalid or ErrAPITokenExpiryInvalid for invalid settings,
+	// and ErrTenantMismatch if the user has no active membership in the tenant.
+	CreatePersonalAccessToken(ctx context.Context, userID string, tenantID string, dto *domain.CreateAPITokenDTO) (*domain.APITokenCreatedResponse, error)
+
+	// ListPersonalAccessTokens lists the active personal access tokens of a user.
+	ListPersonalAccessTokens(ctx context.Context, userID string) ([]*domain.APIToken, error)
+
+	// RevokePersonalAccessToken revokes one of the user's personal access tokens.
+	RevokePersonalAccessToken(ctx context.Context, userID string, tokenID string) error
+
+	// CreateTenantAPIKey creates a scoped API key for integrations of the tenant.
+	// The plaintext key is only returned here.
+	CreateTenantAPIKey(ctx context.Context, tenantID string, adminID string, dto *domain.CreateAPITokenDTO) (*domain.APITokenCreatedResponse, error)
+
+	// ListTenantAPIKeys lists the active API keys of the tenant.
+	ListTenantAPIKeys(ctx context.Context, tenantID string) ([]*domain.APIToken, error)
+
+	// RevokeTenantAPIKey revokes one of the tenant's API keys.
+	RevokeTenantAPIKey(ctx context.Context, tenantID string, tokenID string) error
+
+	// Password policy operations
+
+	// GetPasswordPolicy returns the password policy of the tenant, or the default policy.
+	GetPasswordPolicy(ctx context.Context, tenantID string) (*domain.PasswordPolicy, error)
+
+	// UpdatePasswordPolicy creates or replaces the password policy of the tenant.
+	UpdatePasswordPolicy(ctx context.Context, tenantID string, dto *domain.PasswordPolicyDTO) (*domain.PasswordPolicy, error)
+
+	// Tenant role operations
+
+	// GetPermissionCatalog returns the permissions that can be granted and those of the built-in roles.
+	GetPermissionCatalog(ctx context.Context) *domain.PermissionCatalogResponse
+
+	// ListTenantRoles lists the custom roles of the tenant.
+	ListTenantRoles(ctx context.Context, tenantID string) ([]*domain.TenantRole, error)
+
+	// CreateTenantRole defines a custom role in the tenant.
+	// Returns ErrPermissionInvalid if a permission is not in the catalog.
+	CreateTenantRole(ctx context.Context, tenantID string, dto *domain.TenantRoleDTO) (*domain.TenantRole, error)
+
+	// UpdateTenantRole replaces the settings of a custom role of the tenant.
+	UpdateTenantRole(ctx context.Context, tenantID string, roleID string, dto *domain.TenantRoleDTO) (*domain.TenantRole, error)
+
+	// DeleteTenantRole removes a custom role of the tenant.
+	DeleteTenantRole(ctx context.Context, tenantID string, roleID string) error
+
+	// AssignTenantRole assigns a custom role to a member of the tenant, or removes it.
+	// Returns ErrUserNotFound if the user is not a member of the tenant.
+	AssignTenantRole(ctx context.Context, tenantID string, userID string, dto *domain.AssignTenantRoleDTO) error
+}
+
+// PasswordPolicyService applies the tenant password policy to new passwords.
+// It is shared by AuthService and the user management services so that every way of
+// setting a password follows the same rules.
+type PasswordPolicyService interface {
+	// GetPolicy returns the password policy of the tenant, or the default policy when the
+	// tenant didn't configure one or tenantID is empty.
+	GetPolicy(ctx context.Context, tenantID string) (*domain.PasswordPolicy, error)
+
+	// ValidatePassword checks a new password against the tenant policy, the user's recent
+	// passwords (skipped when userID is empty, e.g. on registration) and the breached
+	// password dataset.
+	// Returns ErrPasswordTooWeak, ErrPasswordReused or ErrPasswordBreached.
+	ValidatePassword(ctx context.Context, tenantID string, userID string, password string) error
+
+	// RecordPasswordChange stores the new password hash in the user's history and resets
+	// the password age. Call it after the new hash was saved.
+	RecordPasswordChange(ctx context.Context, userID string, passwordHash string) error
+
+	// IsPasswordExpired checks if the user's password exceeded the tenant maximum age.
+	IsPasswordExpired(ctx context.Context, tenantID string, user *domain.User) (bool, error)
 }
 
 // UserManagementService defines the interface for user management operations.
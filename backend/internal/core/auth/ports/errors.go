@@ -10,8 +10,14 @@ var (
 	// ErrAccountNotVerified is returned when user tries to login without verifying email
 	ErrAccountNotVerified = errors.New("account not verified, please check your email")
 
-	// ErrAccountLocked is returned when user account is locked
-	ErrAccountLocked = errors.New("account is locked, please contact support")
+	// ErrAccountLocked is returned when user account is temporarily locked after repeated failed logins
+	ErrAccountLocked = errors.New("account is temporarily locked, check your email to unlock it")
+
+	// ErrTooManyLoginAttempts is returned when an account or client must wait before trying again
+	ErrTooManyLoginAttempts = errors.New("too many failed attempts, please try again later")
+
+	// ErrUnlockTokenInvalid is returned when an account unlock token is unknown or expired
+	ErrUnlockTokenInvalid = errors.New("invalid or expired unlock token")
 
 	// ErrAccountDisabled is returned when user account is disabled
 	ErrAccountDisabled = errors.New("account is disabled")
@@ -49,10 +55,137 @@ var (
 	// ErrRefreshTokenInvalid is returned when refresh token is invalid
 	ErrRefreshTokenInvalid = errors.New("invalid refresh token")
 
+	// ErrRefreshTokenReused is returned when an already rotated refresh token is presented again
+	ErrRefreshTokenReused = errors.New("refresh token reuse detected")
+
+	// ErrSessionNotFound is returned when a session doesn't exist or belongs to another user
+	ErrSessionNotFound = errors.New("session not found")
+
 	// ErrTokenGenerationFailed is returned when token generation fails
 	ErrTokenGenerationFailed = errors.New("failed to generate token")
 )
 
+// Multi-factor authentication errors
+var (
+	// ErrMFANotEnrolled is returned when the user has not enrolled an authenticator
+	ErrMFANotEnrolled = errors.New("multi-factor authentication is not enrolled")
+
+	// ErrMFAAlreadyEnabled is returned when trying to enroll while MFA is already active
+	ErrMFAAlreadyEnabled = errors.New("multi-factor authentication is already enabled")
+
+	// ErrMFACodeInvalid is returned when a TOTP code is wrong, expired or replayed
+	ErrMFACodeInvalid = errors.New("invalid authentication code")
+
+	// ErrMFARecoveryCodeInvalid is returned when a recovery code is wrong or already used
+	ErrMFARecoveryCodeInvalid = errors.New("invalid or already used recovery code")
+
+	// ErrMFAChallengeInvalid is returned when the login MFA challenge token is unknown or exhausted
+	ErrMFAChallengeInvalid = errors.New("invalid MFA challenge")
+
+	// ErrMFAChallengeExpired is returned when the login MFA challenge token has expired
+	ErrMFAChallengeExpired = errors.New("MFA challenge has expired, please login again")
+)
+
+// OpenID Connect errors
+var (
+	// ErrOIDCNotConfigured is returned when the tenant has no enabled OIDC identity provider
+	ErrOIDCNotConfigured = errors.New("single sign-on is not configured for this tenant")
+
+	// ErrOIDCStateInvalid is returned when the callback state is unknown, already used or expired
+	ErrOIDCStateInvalid = errors.New("invalid or expired single sign-on request")
+
+	// ErrOIDCAuthenticationFailed is returned when the code exchange or ID token validation fails
+	ErrOIDCAuthenticationFailed = errors.New("single sign-on authentication failed")
+
+	// ErrOIDCAccountConflict is returned when a local account with the same email exists
+	// but the tenant's identity provider can't speak for it, so it can't be linked safely
+	ErrOIDCAccountConflict = errors.New("an account with this email already exists")
+
+	// ErrFederatedLinkInvalid is returned when an account link token is unknown, exhausted or expired
+	ErrFederatedLinkInvalid = errors.New("invalid or expired account link request")
+)
+
+// SAML errors
+var (
+	// ErrSAMLNotConfigured is returned when the tenant has no enabled SAML identity provider
+	ErrSAMLNotConfigured = errors.New("SAML single sign-on is not configured for this tenant")
+
+	// ErrSAMLCertificateInvalid is returned when the identity provider certificate can't be parsed
+	ErrSAMLCertificateInvalid = errors.New("invalid identity provider certificate")
+
+	// ErrSAMLRequestInvalid is returned when a response doesn't answer a pending request of the tenant
+	ErrSAMLRequestInvalid = errors.New("invalid or expired SAML request")
+
+	// ErrSAMLAuthenticationFailed is returned when the response or its assertion fails validation
+	ErrSAMLAuthenticationFailed = errors.New("SAML authentication failed")
+
+	// ErrSAMLLoginCodeInvalid is returned when a login code is unknown, already used or expired
+	ErrSAMLLoginCodeInvalid = errors.New("invalid or expired login code")
+)
+
+// WebAuthn errors
+var (
+	// ErrWebAuthnChallengeInvalid is returned when a ceremony doesn't answer a pending challenge
+	ErrWebAuthnChallengeInvalid = errors.New("invalid or expired passkey challenge")
+
+	// ErrWebAuthnVerificationFailed is returned when the authenticator response fails validation
+	ErrWebAuthnVerificationFailed = errors.New("passkey verification failed")
+
+	// ErrWebAuthnCredentialNotFound is returned when a credential doesn't exist or belongs to another user
+	ErrWebAuthnCredentialNotFound = errors.New("passkey not found")
+
+	// ErrWebAuthnCredentialExists is returned when the credential is already registered
+	ErrWebAuthnCredentialExists = errors.New("passkey is already registered")
+)
+
+// Impersonation errors
+var (
+	// ErrImpersonationNotAllowed is returned when the admin may not act as the target user
+	ErrImpersonationNotAllowed = errors.New("impersonation of this user is not allowed")
+
+	// ErrImpersonationSessionInvalid is returned when an impersonation session was stopped, expired or doesn't exist
+	ErrImpersonationSessionInvalid = errors.New("impersonation session has ended")
+
+	// ErrImpersonationSessionNotFound is returned when an impersonation session doesn't exist in the tenant
+	ErrImpersonationSessionNotFound = errors.New("impersonation session not found")
+)
+
+// Magic link errors
+var (
+	// ErrMagicLinkDisabled is returned when the tenant doesn't allow login with links sent by email
+	ErrMagicLinkDisabled = errors.New("magic link login is not enabled for this tenant")
+
+	// ErrMagicLinkInvalid is returned when a login link is unknown, already used or expired
+	ErrMagicLinkInvalid = errors.New("invalid or expired login link")
+)
+
+// Tenant role errors
+var (
+	// ErrTenantRoleNotFound is returned when a custom role doesn't exist in the tenant
+	ErrTenantRoleNotFound = errors.New("role not found")
+
+	// ErrTenantRoleExists is returned when the tenant already has a role with the same name
+	ErrTenantRoleExists = errors.New("a role with this name already exists")
+
+	// ErrPermissionInvalid is returned when a role grants a permission that is not in the catalog
+	ErrPermissionInvalid = errors.New("unknown permission")
+)
+
+// API token errors
+var (
+	// ErrAPITokenNotFound is returned when a personal access token or API key doesn't exist
+	ErrAPITokenNotFound = errors.New("API token not found")
+
+	// ErrAPITokenInvalid is returned when a token is unknown, expired or revoked
+	ErrAPITokenInvalid = errors.New("invalid, expired or revoked API token")
+
+	// ErrAPITokenScopeInvalid is returned when a requested scope is not a known resource:action pair
+	ErrAPITokenScopeInvalid = errors.New("invalid API token scope")
+
+	// ErrAPITokenExpiryInvalid is returned when the requested expiry date is not in the future
+	ErrAPITokenExpiryInvalid = errors.New("API token expiry must be in the future")
+)
+
 // Email verification errors
 var (
 	// ErrVerificationTokenInvalid is returned when verification token is invalid
@@ -70,7 +203,7 @@ var (
 
 // Password errors
 var (
-	// ErrPasswordTooWeak is returned when password doesn't meet security requirements
+	// ErrPasswordTooWeak is returned when password doesn't meet security requirements or the tenant password policy
 	ErrPasswordTooWeak = errors.New("password doesn't meet security requirements")
 
 	// ErrPasswordTooLong is returned when password exceeds maximum length
@@ -79,6 +212,12 @@ var (
 	// ErrPasswordSameAsOld is returned when new password is same as old password
 	ErrPasswordSameAsOld = errors.New("new password must be different from old password")
 
+	// ErrPasswordReused is returned when a password matches one of the user's recent passwords
+	ErrPasswordReused = errors.New("password was used recently")
+
+	// ErrPasswordBreached is returned when a password appears in the breached password dataset
+	ErrPasswordBreached = errors.New("password appears in a known data breach")
+
 	// ErrCurrentPasswordIncorrect is returned when current password is wrong
 	ErrCurrentPasswordIncorrect = errors.New("current password is incorrect")
 
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/auth/domain"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/auth/ports"
+	"github.com/google/uuid"
+)
+
+// apiTokenDisplayIDLength is the number of characters of the token ID shown after the type prefix
+// in listings. No character of the secret part is stored.
+const apiTokenDisplayIDLength = 8
+
+// CreatePersonalAccessToken creates a scoped token that acts as the user in the tenant
+func (s *AuthServiceImpl) CreatePersonalAccessToken(ctx context.Context, userID string, tenantID string, dto *domain.CreateAPITokenDTO) (*domain.APITokenCreatedResponse, error) {
+	return s.createAPIToken(ctx, domain.APITokenPersonal, domain.PersonalAccessTokenPrefix, userID, tenantID, dto)
+}
+
+// ListPersonalAccessTokens lists the active personal access tokens of a user
+func (s *AuthServiceImpl) ListPersonalAccessTokens(ctx context.Context, userID string) ([]*domain.APIToken, error) {
+	tokens, err := s.repo.ListPersonalAccessTokens(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list personal access tokens: %w", err)
+	}
+
+	return tokens, nil
+}
+
+// RevokePersonalAccessToken revokes one of the user's personal access tokens
+func (s *AuthServiceImpl) RevokePersonalAccessToken(ctx context.Context, userID string, tokenID string) error {
+	if _, err := uuid.Parse(tokenID); err != nil {
+		return ports.ErrAPITokenNotFound
+	}
+
+	return s.repo.RevokePersonalAccessToken(ctx, userID, tokenID)
+}
+
+// CreateTenantAPIKey creates a scoped API key for integrations of the tenant.
+// The key acts with the tenant role of the admin who created it.
+func (s *AuthServiceImpl) CreateTenantAPIKey(ctx context.Context, tenantID string, adminID string, dto *domain.CreateAPITokenDTO) (*domain.APITokenCreatedResponse, error) {
+	return s.createAPIToken(ctx, domain.APITokenTenant, domain.APIKeyPrefix, adminID, tenantID, dto)
+}
+
+// ListTenantAPIKeys lists the active API keys of the tenant
+func (s *AuthServiceImpl) ListTenantAPIKeys(ctx context.Context, tenantID string) ([]*domain.APIToken, error) {
+	keys, err := s.repo.ListTenantAPIKeys(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tenant API keys: %w", err)
+	}
+
+	return keys, nil
+}
+
+// RevokeTenantAPIKey revokes one of the tenant's API keys
+func (s *AuthServiceImpl) RevokeTenantAPIKey(ctx context.Context, tenantID string, tokenID string) error {
+	if _, err := uuid.Parse(tokenID); err != nil {
+		return ports.ErrAPITokenNotFound
+	}
+
+	return s.repo.RevokeTenantAPIKey(ctx, tenantID, tokenID)
+}
+
+// createAPIToken validates the requested scopes and expiry, generates the plaintext token
+// and stores its hash. The user must be an active member of the tenant, since the token
+// is authorized with the user's role in it.
+func (s *AuthServiceImpl) createAPIToken(ctx context.Context, tokenType domain.APITokenType, prefix string, userID string, tenantID string, dto *domain.CreateAPITokenDTO) (*domain.APITokenCreatedResponse, error) {
+	if err := s.validator.Struct(dto); err != nil {
+		return nil, ports.ErrInvalidInput
+	}
+
+	for _, scope := range dto.Scopes {
+		if !domain.IsValidAPITokenScope(scope) {
+			return nil, ports.ErrAPITokenScopeInvalid
+		}
+	}
+
+	now := time.Now()
+	if dto.ExpiresAt != nil && !dto.ExpiresAt.After(now) {
+		return nil, ports.ErrAPITokenExpiryInvalid
+	}
+
+	if tenantID == "" {
+		return nil, ports.ErrTenantMismatch
+	}
+
+	membership, err := s.repo.GetActiveMembership(ctx, userID, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get membership: %w", err)
+	}
+	if membership == nil {
+		return nil, ports.ErrTenantMismatch
+	}
+
+	plaintext := prefix + s.generateSecureToken()
+	tokenID := uuid.New().String()
+	token := &domain.APIToken{
+		ID:          tokenID,
+		Type:        tokenType,
+		TenantID:    tenantID,
+		UserID:      userID,
+		Name:        dto.Name,
+		TokenHash:   domain.HashAPIToken(plaintext),
+		TokenPrefix: prefix + tokenID[:apiTokenDisplayIDLength],
+		Scopes:      dto.Scopes,
+		ExpiresAt:   dto.ExpiresAt,
+		CreatedAt:   now,
+	}
+
+	if err := s.repo.CreateAPIToken(ctx, token); err != nil {
+		return nil, fmt.Errorf("failed to create API token: %w", err)
+	}
+
+	fmt.Printf("INFO: Created %s API token %s for user %s in tenant %s\n", tokenType, token.ID, userID, tenantID)
+
+	return &domain.APITokenCreatedResponse{
+		Token:    plaintext,
+		APIToken: token,
+	}, nil
+}
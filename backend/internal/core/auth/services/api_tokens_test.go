@@ -0,0 +1,39 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/auth/domain"
+)
+
+func TestCreateAPITokenStoresNoSecretCharacters(t *testing.T) {
+	repo := newStubAuthRepository(newTestUser())
+	repo.memberships[authUserID] = &domain.UserMembership{TenantID: authTenantID, Role: "admin", Status: "active"}
+	service := newTestAuthService(t, repo)
+
+	created, err := service.CreatePersonalAccessToken(context.Background(), authUserID, authTenantID, &domain.CreateAPITokenDTO{
+		Name:   "CI",
+		Scopes: []string{"courses:read"},
+	})
+	if err != nil {
+		t.Fatalf("CreatePersonalAccessToken failed: %v", err)
+	}
+
+	stored := repo.apiTokens[created.APIToken.ID]
+	if stored == nil {
+		t.Fatalf("Expected the token to be stored")
+	}
+	if stored.TokenHash != domain.HashAPIToken(created.Token) {
+		t.Errorf("Expected the hash of the plaintext token to be stored")
+	}
+
+	expected := domain.PersonalAccessTokenPrefix + stored.ID[:apiTokenDisplayIDLength]
+	if stored.TokenPrefix != expected {
+		t.Errorf("Expected prefix %q, got %q", expected, stored.TokenPrefix)
+	}
+	if strings.HasPrefix(created.Token, stored.TokenPrefix) {
+		t.Errorf("Expected the prefix to hold no character of the secret, got %q", stored.TokenPrefix)
+	}
+}
@@ -4,13 +4,18 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/DanielIturra1610/stegmaier-landing/internal/core/auth/domain"
 	"github.com/DanielIturra1610/stegmaier-landing/internal/core/auth/ports"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/shared/cache"
 	"github.com/DanielIturra1610/stegmaier-landing/internal/shared/hasher"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/shared/oidc"
 	"github.com/DanielIturra1610/stegmaier-landing/internal/shared/tokens"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/shared/webauthn"
 	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
 )
@@ -26,14 +31,44 @@ type AuthServiceImpl struct {
 	refreshExpiry time.Duration
 	verifyExpiry  time.Duration
 	resetExpiry   time.Duration
+	// MFA settings
+	mfaChallengeExpiry time.Duration
+	mfaIssuer          string
+	// OpenID Connect settings
+	oidcClient        *oidc.Client
+	oidcRequestExpiry time.Duration
+	// SAML settings
+	baseURL           string
+	samlRequestExpiry time.Duration
+	// Brute-force protection, nil when disabled
+	throttle *loginThrottle
+	// Tenant password policy
+	passwordPolicy ports.PasswordPolicyService
+	// WebAuthn relying party
+	webAuthn webauthn.Config
+	// Lifetime of impersonation access tokens
+	impersonationExpiry time.Duration
+	// Lifetime of passwordless login links
+	magicLinkExpiry time.Duration
 }
 
 // AuthServiceConfig holds configuration for AuthService
 type AuthServiceConfig struct {
-	AccessTokenExpiry  time.Duration
-	RefreshTokenExpiry time.Duration
-	VerifyTokenExpiry  time.Duration
-	ResetTokenExpiry   time.Duration
+	AccessTokenExpiry   time.Duration
+	RefreshTokenExpiry  time.Duration
+	VerifyTokenExpiry   time.Duration
+	ResetTokenExpiry    time.Duration
+	MFAChallengeExpiry  time.Duration // Lifetime of the token returned by the first login step
+	MFAIssuer           string        // Issuer shown in authenticator apps
+	OIDCRequestExpiry   time.Duration // Time allowed to complete a login at the identity provider
+	SAMLRequestExpiry   time.Duration // Time allowed to answer a SAML AuthnRequest
+	BaseURL             string        // Public URL used to build the SAML endpoints and frontend callback
+	LoginAttemptCache   cache.Cache   // Shared store of failed attempt counters; nil keeps them in memory per replica
+	LoginThrottle       LoginThrottlePolicy
+	PasswordPolicy      ports.PasswordPolicyService // Shared with the user management services; nil uses the default policy without breach check
+	WebAuthn            webauthn.Config             // Relying party of passkey ceremonies; Timeout is also the challenge lifetime
+	ImpersonationExpiry time.Duration               // Lifetime of the access token issued when an admin acts as another user
+	MagicLinkExpiry     time.Duration               // Lifetime of the login links sent by email
 }
 
 // NewAuthService creates a new instance of AuthService
@@ -44,6 +79,11 @@ func NewAuthService(
 	emailService ports.EmailService,
 	config AuthServiceConfig,
 ) ports.AuthService {
+	passwordPolicy := config.PasswordPolicy
+	if passwordPolicy == nil {
+		passwordPolicy = NewPasswordPolicyService(repo, hasher, nil)
+	}
+
 	return &AuthServiceImpl{
 		repo:          repo,
 		hasher:        hasher,
@@ -54,6 +94,25 @@ func NewAuthService(
 		refreshExpiry: config.RefreshTokenExpiry,
 		verifyExpiry:  config.VerifyTokenExpiry,
 		resetExpiry:   config.ResetTokenExpiry,
+
+		mfaChallengeExpiry: config.MFAChallengeExpiry,
+		mfaIssuer:          config.MFAIssuer,
+
+		oidcClient:        oidc.NewClient(nil),
+		oidcRequestExpiry: config.OIDCRequestExpiry,
+
+		baseURL:           strings.TrimSuffix(config.BaseURL, "/"),
+		samlRequestExpiry: config.SAMLRequestExpiry,
+
+		throttle: newLoginThrottle(config.LoginAttemptCache, config.LoginThrottle),
+
+		passwordPolicy: passwordPolicy,
+
+		webAuthn: config.WebAuthn,
+
+		impersonationExpiry: config.ImpersonationExpiry,
+
+		magicLinkExpiry: config.MagicLinkExpiry,
 	}
 }
 
@@ -77,6 +136,11 @@ func (s *AuthServiceImpl) Register(ctx context.Context, tenantID string, dto *do
 		return nil, ports.ErrEmailAlreadyExists
 	}
 
+	// Enforce the tenant password policy
+	if err := s.passwordPolicy.ValidatePassword(ctx, tenantID, "", dto.Password); err != nil {
+		return nil, err
+	}
+
 	// Hash password
 	passwordHash, err := s.hasher.Hash(dto.Password)
 	if err != nil {
@@ -108,6 +172,7 @@ func (s *AuthServiceImpl) Register(ctx context.Context, tenantID string, dto *do
 	if err := s.repo.CreateUser(ctx, user); err != nil {
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
+	s.recordPasswordChange(ctx, user)
 
 	// Generate verification token
 	verifyToken := &domain.VerificationToken{
@@ -138,7 +203,7 @@ func (s *AuthServiceImpl) Register(ctx context.Context, tenantID string, dto *do
 		return nil, fmt.Errorf("failed to generate access token: %w", err)
 	}
 
-	refreshTokenStr, err := s.createRefreshToken(ctx, user)
+	refreshTokenStr, err := s.createRefreshToken(ctx, user, dto.SessionMetadata)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create refresh token: %w", err)
 	}
@@ -159,10 +224,15 @@ func (s *AuthServiceImpl) Login(ctx context.Context, tenantID string, dto *domai
 		return nil, ports.ErrInvalidInput
 	}
 
+	// Refuse locked accounts and blocked clients before checking the password
+	if err := s.throttle.check(ctx, dto.Email, dto.IPAddress); err != nil {
+		return nil, err
+	}
+
 	// Get user by email
 	user, err := s.repo.GetUserByEmail(ctx, dto.Email)
 	if err != nil {
-		return nil, ports.ErrInvalidCredentials
+		return nil, s.recordFailedLogin(ctx, dto.Email, dto.IPAddress, nil)
 	}
 
 	// Verify tenant matches ONLY if tenantID was provided in the request
@@ -173,32 +243,29 @@ func (s *AuthServiceImpl) Login(ctx context.Context, tenantID string, dto *domai
 
 	// Verify password
 	if err := s.hasher.Compare(user.PasswordHash, dto.Password); err != nil {
-		return nil, ports.ErrInvalidCredentials
+		return nil, s.recordFailedLogin(ctx, dto.Email, dto.IPAddress, user)
 	}
+	s.throttle.reset(ctx, user.Email)
 
 	// Check if user is verified
 	if !user.IsVerified {
 		return nil, ports.ErrAccountNotVerified
 	}
 
-	// Generate JWT tokens
-	accessToken, err := s.generateAccessToken(user)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate access token: %w", err)
-	}
+	// Passwords older than the tenant maximum age must be changed before anything else
+	s.checkPasswordExpiry(ctx, tenantID, user)
 
-	refreshTokenStr, err := s.createRefreshToken(ctx, user)
+	// Require a second factor if the user enrolled MFA or the tenant policy demands it
+	mfaResponse, err := s.beginMFAChallenge(ctx, tenantID, user)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create refresh token: %w", err)
+		return nil, err
+	}
+	if mfaResponse != nil {
+		return mfaResponse, nil
 	}
 
-	return &domain.AuthResponse{
-		AccessToken:  accessToken,
-		TokenType:    "Bearer",
-		ExpiresIn:    int(s.accessExpiry.Seconds()),
-		RefreshToken: refreshTokenStr,
-		User:         domain.ToUserDTO(user),
-	}, nil
+	// Generate JWT tokens
+	return s.issueTokens(ctx, user, dto.SessionMetadata)
 }
 
 // Logout invalidates a user's refresh token
@@ -235,6 +302,11 @@ func (s *AuthServiceImpl) RefreshAccessToken(ctx context.Context, dto *domain.Re
 		return nil, ports.ErrRefreshTokenInvalid
 	}
 
+	// A revoked token that was already rotated is being replayed
+	if token.IsRevoked() {
+		return nil, s.handleRefreshTokenReuse(ctx, token)
+	}
+
 	// Validate token
 	if !token.IsValid() {
 		return nil, ports.ErrRefreshTokenInvalid
@@ -252,11 +324,21 @@ func (s *AuthServiceImpl) RefreshAccessToken(ctx context.Context, dto *domain.Re
 		return nil, fmt.Errorf("failed to generate access token: %w", err)
 	}
 
+	// Rotate refresh token
+	refreshTokenStr, err := s.rotateRefreshToken(ctx, token, dto.SessionMetadata)
+	if err != nil {
+		if errors.Is(err, ports.ErrTokenRevoked) {
+			// Another request rotated the same token first
+			return nil, s.handleRefreshTokenReuse(ctx, token)
+		}
+		return nil, fmt.Errorf("failed to rotate refresh token: %w", err)
+	}
+
 	return &domain.AuthResponse{
 		AccessToken:  accessToken,
 		TokenType:    "Bearer",
 		ExpiresIn:    int(s.accessExpiry.Seconds()),
-		RefreshToken: dto.RefreshToken,
+		RefreshToken: refreshTokenStr,
 		User:         domain.ToUserDTO(user),
 	}, nil
 }
@@ -381,9 +463,20 @@ func (s *AuthServiceImpl) ForgotPassword(ctx context.Context, tenantID string, d
 		return ports.ErrInvalidInput
 	}
 
+	// Limit reset requests per account and client
+	allowed, err := s.throttle.allowPasswordReset(ctx, dto.Email, dto.IPAddress)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return nil
+	}
+
 	// Get user by email
 	user, err := s.repo.GetUserByEmail(ctx, dto.Email)
 	if err != nil {
+		// Requests for unknown emails count against the client
+		s.throttle.recordIPFailure(ctx, dto.IPAddress)
 		// Return success even if user not found (security best practice)
 		return nil
 	}
@@ -448,6 +541,11 @@ func (s *AuthServiceImpl) ResetPassword(ctx context.Context, dto *domain.ResetPa
 		return ports.ErrUserNotFound
 	}
 
+	// Enforce the tenant password policy
+	if err := s.passwordPolicy.ValidatePassword(ctx, stringPtrValue(user.TenantID), user.ID, dto.NewPassword); err != nil {
+		return err
+	}
+
 	// Hash new password
 	newPasswordHash, err := s.hasher.Hash(dto.NewPassword)
 	if err != nil {
@@ -462,6 +560,8 @@ func (s *AuthServiceImpl) ResetPassword(ctx context.Context, dto *domain.ResetPa
 		return fmt.Errorf("failed to update user password: %w", err)
 	}
 
+	s.recordPasswordChange(ctx, user)
+
 	// Mark token as used
 	if err := s.repo.MarkPasswordResetTokenAsUsed(ctx, token.ID); err != nil {
 		fmt.Printf("Warning: failed to mark reset token as used: %v\n", err)
@@ -472,6 +572,9 @@ func (s *AuthServiceImpl) ResetPassword(ctx context.Context, dto *domain.ResetPa
 		fmt.Printf("Warning: failed to revoke refresh tokens: %v\n", err)
 	}
 
+	// Proving access to the email also lifts a lockout
+	s.throttle.reset(ctx, user.Email)
+
 	return nil
 }
 
@@ -498,6 +601,11 @@ func (s *AuthServiceImpl) ChangePassword(ctx context.Context, userID string, dto
 		return ports.ErrPasswordSameAsOld
 	}
 
+	// Enforce the tenant password policy
+	if err := s.passwordPolicy.ValidatePassword(ctx, stringPtrValue(user.TenantID), user.ID, dto.NewPassword); err != nil {
+		return err
+	}
+
 	// Hash new password
 	newPasswordHash, err := s.hasher.Hash(dto.NewPassword)
 	if err != nil {
@@ -511,6 +619,7 @@ func (s *AuthServiceImpl) ChangePassword(ctx context.Context, userID string, dto
 	if err := s.repo.UpdateUser(ctx, user); err != nil {
 		return fmt.Errorf("failed to update user password: %w", err)
 	}
+	s.recordPasswordChange(ctx, user)
 
 	// Revoke all refresh tokens except current session
 	if err := s.repo.RevokeAllUserRefreshTokens(ctx, user.ID); err != nil {
@@ -619,6 +728,25 @@ func (s *AuthServiceImpl) SwitchRole(ctx context.Context, userID string, tenantI
 	}, nil
 }
 
+// ListSessions returns the active sessions (one per refresh token family) of a user
+func (s *AuthServiceImpl) ListSessions(ctx context.Context, userID string) ([]*domain.Session, error) {
+	sessions, err := s.repo.ListUserSessions(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	return sessions, nil
+}
+
+// RevokeSession revokes a single session, logging out the device that holds it
+func (s *AuthServiceImpl) RevokeSession(ctx context.Context, userID string, sessionID string) error {
+	if _, err := uuid.Parse(sessionID); err != nil {
+		return ports.ErrSessionNotFound
+	}
+
+	return s.repo.RevokeUserSession(ctx, userID, sessionID)
+}
+
 // Helper methods
 
 // stringPtrEquals safely compares a *string with a string
@@ -659,17 +787,46 @@ func (s *AuthServiceImpl) generateAccessToken(user *domain.User) (string, error)
 	return token, nil
 }
 
+// issueTokens generates the access and refresh token pair that completes a login
+func (s *AuthServiceImpl) issueTokens(ctx context.Context, user *domain.User, session domain.SessionMetadata) (*domain.AuthResponse, error) {
+	accessToken, err := s.generateAccessToken(user)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate access token: %w", err)
+	}
+
+	refreshTokenStr, err := s.createRefreshToken(ctx, user, session)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create refresh token: %w", err)
+	}
+
+	return &domain.AuthResponse{
+		AccessToken:  accessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(s.accessExpiry.Seconds()),
+		RefreshToken: refreshTokenStr,
+		User:         domain.ToUserDTO(user),
+	}, nil
+}
+
 // createRefreshToken creates and stores a refresh token for a user
-func (s *AuthServiceImpl) createRefreshToken(ctx context.Context, user *domain.User) (string, error) {
+// Each new login starts its own token family
+func (s *AuthServiceImpl) createRefreshToken(ctx context.Context, user *domain.User, session domain.SessionMetadata) (string, error) {
 	tokenStr := s.generateSecureToken()
+	tokenID := uuid.New().String()
+	now := time.Now()
 
 	refreshToken := &domain.RefreshToken{
-		ID:        uuid.New().String(),
-		UserID:    user.ID,
-		Token:     tokenStr,
-		ExpiresAt: time.Now().Add(s.refreshExpiry),
-		RevokedAt: nil,
-		CreatedAt: time.Now(),
+		ID:         tokenID,
+		UserID:     user.ID,
+		Token:      tokenStr,
+		FamilyID:   tokenID,
+		ParentID:   nil,
+		UserAgent:  session.UserAgent,
+		IPAddress:  session.IPAddress,
+		ExpiresAt:  now.Add(s.refreshExpiry),
+		RevokedAt:  nil,
+		LastUsedAt: &now,
+		CreatedAt:  now,
 	}
 
 	if err := s.repo.CreateRefreshToken(ctx, refreshToken); err != nil {
@@ -679,6 +836,58 @@ func (s *AuthServiceImpl) createRefreshToken(ctx context.Context, user *domain.U
 	return tokenStr, nil
 }
 
+// rotateRefreshToken replaces a refresh token with a new one in the same family
+// The session metadata is refreshed with the client that performed the rotation
+func (s *AuthServiceImpl) rotateRefreshToken(ctx context.Context, parent *domain.RefreshToken, session domain.SessionMetadata) (string, error) {
+	tokenStr := s.generateSecureToken()
+	parentID := parent.ID
+	now := time.Now()
+
+	refreshToken := &domain.RefreshToken{
+		ID:         uuid.New().String(),
+		UserID:     parent.UserID,
+		Token:      tokenStr,
+		FamilyID:   parent.FamilyID,
+		ParentID:   &parentID,
+		UserAgent:  session.UserAgent,
+		IPAddress:  session.IPAddress,
+		ExpiresAt:  now.Add(s.refreshExpiry),
+		RevokedAt:  nil,
+		LastUsedAt: &now,
+		CreatedAt:  now,
+	}
+
+	if err := s.repo.RotateRefreshToken(ctx, parent.ID, refreshToken); err != nil {
+		return "", err
+	}
+
+	return tokenStr, nil
+}
+
+// handleRefreshTokenReuse revokes every session of the user when a rotated token is
+// presented again: either the legitimate client or an attacker holds a stolen copy, and
+// whoever stole it may have taken the other sessions' tokens as well.
+// Tokens revoked by logout (never rotated) are simply rejected.
+func (s *AuthServiceImpl) handleRefreshTokenReuse(ctx context.Context, token *domain.RefreshToken) error {
+	rotated, err := s.repo.IsRefreshTokenRotated(ctx, token.ID)
+	if err != nil {
+		fmt.Printf("Warning: failed to check refresh token rotation: %v\n", err)
+		return ports.ErrRefreshTokenInvalid
+	}
+
+	if !rotated {
+		return ports.ErrRefreshTokenInvalid
+	}
+
+	fmt.Printf("Warning: refresh token reuse detected for user %s (family %s), revoking all of the user's sessions\n", token.UserID, token.FamilyID)
+
+	if err := s.repo.RevokeAllUserRefreshTokens(ctx, token.UserID); err != nil {
+		fmt.Printf("Warning: failed to revoke the refresh tokens of user %s: %v\n", token.UserID, err)
+	}
+
+	return ports.ErrRefreshTokenReused
+}
+
 // generateSecureToken generates a cryptographically secure random token
 func (s *AuthServiceImpl) generateSecureToken() string {
 	bytes := make([]byte, 32)
@@ -691,10 +900,11 @@ func (s *AuthServiceImpl) generateSecureToken() string {
 
 // UserManagementServiceImpl implements the UserManagementService interface
 type UserManagementServiceImpl struct {
-	authRepo  ports.AuthRepository
-	userRepo  ports.UserRepository
-	hasher    hasher.PasswordHasher
-	validator *validator.Validate
+	authRepo       ports.AuthRepository
+	userRepo       ports.UserRepository
+	hasher         hasher.PasswordHasher
+	passwordPolicy ports.PasswordPolicyService
+	validator      *validator.Validate
 }
 
 // NewUserManagementService creates a new instance of UserManagementService
@@ -702,12 +912,14 @@ func NewUserManagementService(
 	authRepo ports.AuthRepository,
 	userRepo ports.UserRepository,
 	hasher hasher.PasswordHasher,
+	passwordPolicy ports.PasswordPolicyService,
 ) ports.UserManagementService {
 	return &UserManagementServiceImpl{
-		authRepo:  authRepo,
-		userRepo:  userRepo,
-		hasher:    hasher,
-		validator: validator.New(),
+		authRepo:       authRepo,
+		userRepo:       userRepo,
+		hasher:         hasher,
+		passwordPolicy: passwordPolicy,
+		validator:      validator.New(),
 	}
 }
 
@@ -731,6 +943,11 @@ func (s *UserManagementServiceImpl) CreateUser(ctx context.Context, tenantID str
 		return nil, ports.ErrEmailAlreadyExists
 	}
 
+	// Enforce the tenant password policy
+	if err := s.passwordPolicy.ValidatePassword(ctx, tenantID, "", dto.Password); err != nil {
+		return nil, err
+	}
+
 	// Hash password
 	passwordHash, err := s.hasher.Hash(dto.Password)
 	if err != nil {
@@ -771,6 +988,10 @@ func (s *UserManagementServiceImpl) CreateUser(ctx context.Context, tenantID str
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
 
+	if err := s.passwordPolicy.RecordPasswordChange(ctx, user.ID, user.PasswordHash); err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+
 	return domain.ToUserDTO(user), nil
 }
 
@@ -1036,6 +1257,11 @@ func (s *UserManagementServiceImpl) ResetUserPassword(ctx context.Context, tenan
 		return ports.ErrTenantMismatch
 	}
 
+	// Enforce the tenant password policy
+	if err := s.passwordPolicy.ValidatePassword(ctx, tenantID, user.ID, newPassword); err != nil {
+		return err
+	}
+
 	// Hash new password
 	newPasswordHash, err := s.hasher.Hash(newPassword)
 	if err != nil {
@@ -1050,6 +1276,10 @@ func (s *UserManagementServiceImpl) ResetUserPassword(ctx context.Context, tenan
 		return fmt.Errorf("failed to update password: %w", err)
 	}
 
+	if err := s.passwordPolicy.RecordPasswordChange(ctx, user.ID, user.PasswordHash); err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+
 	// Revoke all refresh tokens for security
 	if err := s.authRepo.RevokeAllUserRefreshTokens(ctx, userID); err != nil {
 		fmt.Printf("Warning: failed to revoke refresh tokens: %v\n", err)
@@ -1063,7 +1293,8 @@ func (s *UserManagementServiceImpl) ResetUserPassword(ctx context.Context, tenan
 	return nil
 }
 
-// ForcePasswordChange flags a user account to require password change on next login
+// ForcePasswordChange flags a user account to require a password change and ends the
+// user's sessions, so the next login can only change the password
 func (s *UserManagementServiceImpl) ForcePasswordChange(ctx context.Context, tenantID string, userID string) error {
 	// Get user
 	user, err := s.authRepo.GetUserByID(ctx, userID)
@@ -1076,8 +1307,11 @@ func (s *UserManagementServiceImpl) ForcePasswordChange(ctx context.Context, ten
 		return ports.ErrTenantMismatch
 	}
 
-	// TODO: Add ForcePasswordChange field to User entity
-	// For now, we can revoke all sessions which will force re-login
+	if err := s.authRepo.SetMustChangePassword(ctx, userID, true); err != nil {
+		return fmt.Errorf("failed to force password change: %w", err)
+	}
+
+	// Revoke all sessions so that the flag applies right away
 	if err := s.authRepo.RevokeAllUserRefreshTokens(ctx, userID); err != nil {
 		return fmt.Errorf("failed to revoke sessions: %w", err)
 	}
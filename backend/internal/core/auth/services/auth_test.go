@@ -0,0 +1,446 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/auth/domain"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/auth/ports"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/shared/tokens"
+)
+
+const (
+	authTenantID = "11111111-1111-1111-1111-111111111111"
+	authUserID   = "22222222-2222-2222-2222-222222222222"
+	authEmail    = "ana@example.com"
+	authPassword = "correct horse battery staple"
+)
+
+// stubAuthRepository keeps users, MFA enrollments, challenges, refresh tokens, federated
+// identities, API tokens and login links in memory; the other methods are not used
+type stubAuthRepository struct {
+	ports.AuthRepository
+	users           map[string]*domain.User
+	memberships     map[string]*domain.UserMembership // user ID -> membership in authTenantID
+	mfa             map[string]*domain.UserMFA
+	recoveryCodes   map[string][]string
+	challenges      map[string]*domain.MFAChallenge
+	refreshTokens   map[string]*domain.RefreshToken
+	rotated         map[string]bool
+	mfaRoles        []string
+	identities      map[string]*domain.UserIdentity // issuer + " " + subject -> identity
+	linkRequests    map[string]*domain.FederatedLinkRequest
+	verifiedDomains map[string]bool // email domains verified by authTenantID
+	apiTokens       map[string]*domain.APIToken
+	passkeys        map[string]int                    // user ID -> registered WebAuthn credentials
+	magicLinks      map[string]*domain.MagicLinkToken // token hash -> link
+}
+
+func newStubAuthRepository(users ...*domain.User) *stubAuthRepository {
+	repo := &stubAuthRepository{
+		users:           make(map[string]*domain.User),
+		memberships:     make(map[string]*domain.UserMembership),
+		mfa:             make(map[string]*domain.UserMFA),
+		recoveryCodes:   make(map[string][]string),
+		challenges:      make(map[string]*domain.MFAChallenge),
+		refreshTokens:   make(map[string]*domain.RefreshToken),
+		rotated:         make(map[string]bool),
+		identities:      make(map[string]*domain.UserIdentity),
+		linkRequests:    make(map[string]*domain.FederatedLinkRequest),
+		verifiedDomains: make(map[string]bool),
+		apiTokens:       make(map[string]*domain.APIToken),
+		magicLinks:      make(map[string]*domain.MagicLinkToken),
+	}
+	for _, user := range users {
+		repo.users[user.ID] = user
+	}
+	return repo
+}
+
+func (r *stubAuthRepository) GetUserByID(ctx context.Context, userID string) (*domain.User, error) {
+	if user, ok := r.users[userID]; ok {
+		copied := *user
+		return &copied, nil
+	}
+	return nil, ports.ErrUserNotFound
+}
+
+func (r *stubAuthRepository) GetUserByEmail(ctx context.Context, email string) (*domain.User, error) {
+	for _, user := range r.users {
+		if user.Email == email {
+			copied := *user
+			return &copied, nil
+		}
+	}
+	return nil, ports.ErrUserNotFound
+}
+
+func (r *stubAuthRepository) UpdateUser(ctx context.Context, user *domain.User) error {
+	copied := *user
+	r.users[user.ID] = &copied
+	return nil
+}
+
+func (r *stubAuthRepository) SetMustChangePassword(ctx context.Context, userID string, mustChange bool) error {
+	r.users[userID].MustChangePassword = mustChange
+	return nil
+}
+
+func (r *stubAuthRepository) GetActiveMembership(ctx context.Context, userID, tenantID string) (*domain.UserMembership, error) {
+	if membership, ok := r.memberships[userID]; ok && membership.TenantID == tenantID {
+		return membership, nil
+	}
+	return nil, nil
+}
+
+func (r *stubAuthRepository) GetFirstActiveMembership(ctx context.Context, userID string) (*domain.UserMembership, error) {
+	return r.memberships[userID], nil
+}
+
+func (r *stubAuthRepository) GetTenantMFARequiredRoles(ctx context.Context, tenantID string) ([]string, error) {
+	return r.mfaRoles, nil
+}
+
+func (r *stubAuthRepository) CountWebAuthnCredentials(ctx context.Context, userID string) (int, error) {
+	return r.passkeys[userID], nil
+}
+
+func (r *stubAuthRepository) GetUserMFA(ctx context.Context, userID string) (*domain.UserMFA, error) {
+	if mfa, ok := r.mfa[userID]; ok {
+		copied := *mfa
+		return &copied, nil
+	}
+	return nil, ports.ErrMFANotEnrolled
+}
+
+func (r *stubAuthRepository) SaveUserMFA(ctx context.Context, mfa *domain.UserMFA) error {
+	copied := *mfa
+	r.mfa[mfa.UserID] = &copied
+	return nil
+}
+
+func (r *stubAuthRepository) EnableUserMFA(ctx context.Context, userID string) error {
+	mfa, ok := r.mfa[userID]
+	if !ok {
+		return ports.ErrMFANotEnrolled
+	}
+	now := time.Now()
+	mfa.Enabled = true
+	mfa.EnabledAt = &now
+	return nil
+}
+
+func (r *stubAuthRepository) UpdateMFALastUsedStep(ctx context.Context, userID string, step int64) error {
+	mfa := r.mfa[userID]
+	if step <= mfa.LastUsedStep {
+		return ports.ErrMFACodeInvalid
+	}
+	mfa.LastUsedStep = step
+	return nil
+}
+
+func (r *stubAuthRepository) ReplaceRecoveryCodes(ctx context.Context, userID string, codeHashes []string) error {
+	r.recoveryCodes[userID] = append([]string(nil), codeHashes...)
+	return nil
+}
+
+func (r *stubAuthRepository) UseRecoveryCode(ctx context.Context, userID string, codeHash string) error {
+	codes := r.recoveryCodes[userID]
+	for i, code := range codes {
+		if code == codeHash {
+			r.recoveryCodes[userID] = append(codes[:i:i], codes[i+1:]...)
+			return nil
+		}
+	}
+	return ports.ErrMFARecoveryCodeInvalid
+}
+
+func (r *stubAuthRepository) CreateMFAChallenge(ctx context.Context, challenge *domain.MFAChallenge) error {
+	copied := *challenge
+	r.challenges[challenge.ID] = &copied
+	return nil
+}
+
+func (r *stubAuthRepository) GetMFAChallenge(ctx context.Context, tokenHash string) (*domain.MFAChallenge, error) {
+	for _, challenge := range r.challenges {
+		if challenge.TokenHash == tokenHash {
+			copied := *challenge
+			return &copied, nil
+		}
+	}
+	return nil, ports.ErrTokenNotFound
+}
+
+func (r *stubAuthRepository) IncrementMFAChallengeAttempts(ctx context.Context, challengeID string) error {
+	r.challenges[challengeID].Attempts++
+	return nil
+}
+
+func (r *stubAuthRepository) DeleteMFAChallenge(ctx context.Context, challengeID string) error {
+	delete(r.challenges, challengeID)
+	return nil
+}
+
+func (r *stubAuthRepository) CreateRefreshToken(ctx context.Context, token *domain.RefreshToken) error {
+	copied := *token
+	r.refreshTokens[token.ID] = &copied
+	return nil
+}
+
+func (r *stubAuthRepository) GetRefreshToken(ctx context.Context, token string) (*domain.RefreshToken, error) {
+	for _, refreshToken := range r.refreshTokens {
+		if refreshToken.Token == token {
+			copied := *refreshToken
+			return &copied, nil
+		}
+	}
+	return nil, ports.ErrTokenNotFound
+}
+
+func (r *stubAuthRepository) RevokeRefreshToken(ctx context.Context, tokenID string) error {
+	now := time.Now()
+	r.refreshTokens[tokenID].RevokedAt = &now
+	return nil
+}
+
+func (r *stubAuthRepository) RotateRefreshToken(ctx context.Context, parentID string, token *domain.RefreshToken) error {
+	parent := r.refreshTokens[parentID]
+	if parent.IsRevoked() {
+		return ports.ErrTokenRevoked
+	}
+	now := time.Now()
+	parent.RevokedAt = &now
+	r.rotated[parentID] = true
+	return r.CreateRefreshToken(ctx, token)
+}
+
+func (r *stubAuthRepository) IsRefreshTokenRotated(ctx context.Context, tokenID string) (bool, error) {
+	return r.rotated[tokenID], nil
+}
+
+func (r *stubAuthRepository) RevokeAllUserRefreshTokens(ctx context.Context, userID string) error {
+	now := time.Now()
+	for _, token := range r.refreshTokens {
+		if token.UserID == userID && token.RevokedAt == nil {
+			token.RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+func (r *stubAuthRepository) GetUserIdentity(ctx context.Context, issuer string, subject string) (*domain.UserIdentity, error) {
+	return r.identities[issuer+" "+subject], nil
+}
+
+func (r *stubAuthRepository) CreateUserIdentity(ctx context.Context, identity *domain.UserIdentity) error {
+	copied := *identity
+	r.identities[identity.Issuer+" "+identity.Subject] = &copied
+	return nil
+}
+
+func (r *stubAuthRepository) UpdateUserIdentityLogin(ctx context.Context, identityID string, email string) error {
+	return nil
+}
+
+func (r *stubAuthRepository) CreateFederatedUser(ctx context.Context, user *domain.User, identity *domain.UserIdentity, role string) error {
+	r.users[user.ID] = user
+	r.memberships[user.ID] = &domain.UserMembership{TenantID: identity.TenantID, Role: role, Status: "active"}
+	return r.CreateUserIdentity(ctx, identity)
+}
+
+func (r *stubAuthRepository) EnsureMembership(ctx context.Context, userID string, tenantID string, role string) (*domain.UserMembership, error) {
+	if _, ok := r.memberships[userID]; !ok {
+		r.memberships[userID] = &domain.UserMembership{TenantID: tenantID, Role: role, Status: "active"}
+	}
+	return r.memberships[userID], nil
+}
+
+func (r *stubAuthRepository) UpdateMembershipRole(ctx context.Context, userID string, tenantID string, role string) error {
+	r.memberships[userID].Role = role
+	return nil
+}
+
+func (r *stubAuthRepository) UpdateUserTenant(ctx context.Context, userID string, tenantID string) error {
+	r.users[userID].TenantID = &tenantID
+	return nil
+}
+
+func (r *stubAuthRepository) HasTenantMembership(ctx context.Context, userID string, tenantID string) (bool, error) {
+	membership, ok := r.memberships[userID]
+	return ok && membership.TenantID == tenantID, nil
+}
+
+func (r *stubAuthRepository) IsEmailDomainVerified(ctx context.Context, tenantID string, emailDomain string) (bool, error) {
+	return tenantID == authTenantID && r.verifiedDomains[emailDomain], nil
+}
+
+func (r *stubAuthRepository) CreateFederatedLinkRequest(ctx context.Context, request *domain.FederatedLinkRequest) error {
+	copied := *request
+	r.linkRequests[request.ID] = &copied
+	return nil
+}
+
+func (r *stubAuthRepository) GetFederatedLinkRequest(ctx context.Context, tokenHash string) (*domain.FederatedLinkRequest, error) {
+	for _, request := range r.linkRequests {
+		if request.TokenHash == tokenHash {
+			copied := *request
+			return &copied, nil
+		}
+	}
+	return nil, ports.ErrTokenNotFound
+}
+
+func (r *stubAuthRepository) IncrementFederatedLinkAttempts(ctx context.Context, requestID string) error {
+	r.linkRequests[requestID].Attempts++
+	return nil
+}
+
+func (r *stubAuthRepository) DeleteFederatedLinkRequest(ctx context.Context, requestID string) error {
+	delete(r.linkRequests, requestID)
+	return nil
+}
+
+func (r *stubAuthRepository) CreateAPIToken(ctx context.Context, token *domain.APIToken) error {
+	r.apiTokens[token.ID] = token
+	return nil
+}
+
+func (r *stubAuthRepository) CreateMagicLinkToken(ctx context.Context, token *domain.MagicLinkToken) error {
+	copied := *token
+	r.magicLinks[token.TokenHash] = &copied
+	return nil
+}
+
+func (r *stubAuthRepository) ConsumeMagicLinkToken(ctx context.Context, tokenHash string) (*domain.MagicLinkToken, error) {
+	magicLink, ok := r.magicLinks[tokenHash]
+	if !ok {
+		return nil, ports.ErrTokenNotFound
+	}
+	delete(r.magicLinks, tokenHash)
+	return magicLink, nil
+}
+
+func (r *stubAuthRepository) DeleteMagicLinkTokensByUserID(ctx context.Context, userID string) error {
+	for tokenHash, magicLink := range r.magicLinks {
+		if magicLink.UserID == userID {
+			delete(r.magicLinks, tokenHash)
+		}
+	}
+	return nil
+}
+
+func (r *stubAuthRepository) GetTenantMagicLinkEnabled(ctx context.Context, tenantID string) (bool, error) {
+	return true, nil
+}
+
+// stubHasher stores passwords as they are
+type stubHasher struct{}
+
+func (stubHasher) Hash(password string) (string, error) {
+	return "hashed:" + password, nil
+}
+
+func (stubHasher) Compare(hashedPassword, password string) error {
+	if hashedPassword != "hashed:"+password {
+		return ports.ErrInvalidCredentials
+	}
+	return nil
+}
+
+// stubTokenService issues the user ID as access token
+type stubTokenService struct{}
+
+func (stubTokenService) Generate(claims *tokens.Claims) (string, error) {
+	return "access:" + claims.UserID, nil
+}
+
+func (stubTokenService) Validate(tokenString string) (*tokens.Claims, error) {
+	return nil, ports.ErrInvalidToken
+}
+
+func (stubTokenService) Refresh(tokenString string) (string, error) {
+	return "", ports.ErrInvalidToken
+}
+
+// stubPasswordPolicy accepts every password and never expires them
+type stubPasswordPolicy struct {
+	ports.PasswordPolicyService
+}
+
+func (stubPasswordPolicy) IsPasswordExpired(ctx context.Context, tenantID string, user *domain.User) (bool, error) {
+	return false, nil
+}
+
+// newTestUser returns a verified student of authTenantID whose password is authPassword
+func newTestUser() *domain.User {
+	tenantID := authTenantID
+	return &domain.User{
+		ID:           authUserID,
+		TenantID:     &tenantID,
+		Email:        authEmail,
+		PasswordHash: "hashed:" + authPassword,
+		FullName:     "Ana Rojas",
+		Roles:        []string{string(domain.RoleStudent)},
+		ActiveRole:   string(domain.RoleStudent),
+		IsVerified:   true,
+	}
+}
+
+// newTestAuthService builds an AuthService backed by repo without email, lockout or breach checks
+func newTestAuthService(t *testing.T, repo *stubAuthRepository) *AuthServiceImpl {
+	t.Helper()
+	return NewAuthService(repo, stubHasher{}, stubTokenService{}, nil, AuthServiceConfig{
+		AccessTokenExpiry:  15 * time.Minute,
+		RefreshTokenExpiry: 24 * time.Hour,
+		MFAChallengeExpiry: 5 * time.Minute,
+		MFAIssuer:          "Stegmaier",
+		PasswordPolicy:     stubPasswordPolicy{},
+	}).(*AuthServiceImpl)
+}
+
+func TestRefreshTokenReuseRevokesAllSessions(t *testing.T) {
+	ctx := context.Background()
+	repo := newStubAuthRepository(newTestUser())
+	service := newTestAuthService(t, repo)
+
+	laptop := login(t, service)
+	phone := login(t, service)
+
+	rotated, err := service.RefreshAccessToken(ctx, &domain.RefreshTokenDTO{RefreshToken: laptop.RefreshToken})
+	if err != nil {
+		t.Fatalf("RefreshAccessToken failed: %v", err)
+	}
+
+	// Presenting the rotated token again means it was stolen
+	_, err = service.RefreshAccessToken(ctx, &domain.RefreshTokenDTO{RefreshToken: laptop.RefreshToken})
+	if !errors.Is(err, ports.ErrRefreshTokenReused) {
+		t.Fatalf("Expected ErrRefreshTokenReused, got %v", err)
+	}
+
+	for name, token := range map[string]string{"rotated": rotated.RefreshToken, "other session": phone.RefreshToken} {
+		if _, err := service.RefreshAccessToken(ctx, &domain.RefreshTokenDTO{RefreshToken: token}); !errors.Is(err, ports.ErrRefreshTokenInvalid) {
+			t.Errorf("Expected the %s token to be revoked, got %v", name, err)
+		}
+	}
+}
+
+func TestRevokedRefreshTokenIsNotReuse(t *testing.T) {
+	ctx := context.Background()
+	repo := newStubAuthRepository(newTestUser())
+	service := newTestAuthService(t, repo)
+
+	loggedOut := login(t, service)
+	other := login(t, service)
+	if err := service.Logout(ctx, authUserID, loggedOut.RefreshToken); err != nil {
+		t.Fatalf("Logout failed: %v", err)
+	}
+
+	if _, err := service.RefreshAccessToken(ctx, &domain.RefreshTokenDTO{RefreshToken: loggedOut.RefreshToken}); !errors.Is(err, ports.ErrRefreshTokenInvalid) {
+		t.Errorf("Expected ErrRefreshTokenInvalid, got %v", err)
+	}
+	if _, err := service.RefreshAccessToken(ctx, &domain.RefreshTokenDTO{RefreshToken: other.RefreshToken}); err != nil {
+		t.Errorf("Expected the other session to stay valid, got %v", err)
+	}
+}
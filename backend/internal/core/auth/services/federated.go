@@ -0,0 +1,340 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/auth/domain"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/auth/ports"
+	"github.com/google/uuid"
+)
+
+// federatedIdentity is a user identity asserted by a tenant identity provider (OIDC or SAML)
+type federatedIdentity struct {
+	protocol      string // Shown in log messages
+	tenantID      string
+	issuer        string
+	subject       string
+	email         string
+	emailVerified bool
+	fullName      string
+}
+
+// federatedLogin resolves the local user of an external identity and makes sure it has
+// an active membership with the mapped role in the tenant. First logins provision the
+// user into the tenant like TenantService.CreateUserInTenant: a verified account holding
+// the role and an active membership. When the email belongs to an existing account, no user
+// is returned: the response asks the user to confirm the link with ConfirmFederatedLink.
+func (s *AuthServiceImpl) federatedLogin(ctx context.Context, external *federatedIdentity, role string, mapped bool) (*domain.User, *domain.AuthResponse, error) {
+	// Returning user
+	identity, err := s.repo.GetUserIdentity(ctx, external.issuer, external.subject)
+	if err != nil {
+		return nil, nil, err
+	}
+	if identity != nil {
+		user, err := s.repo.GetUserByID(ctx, identity.UserID)
+		if err != nil {
+			return nil, nil, ports.ErrUserNotFound
+		}
+		if err := s.repo.UpdateUserIdentityLogin(ctx, identity.ID, external.email); err != nil {
+			fmt.Printf("Warning: failed to record %s login for user %s: %v\n", external.protocol, user.ID, err)
+		}
+		if err := s.syncFederatedMembership(ctx, user, external.tenantID, role, mapped); err != nil {
+			return nil, nil, err
+		}
+		return user, nil, nil
+	}
+
+	external.email = strings.TrimSpace(external.email)
+	if external.email == "" {
+		fmt.Printf("Warning: %s provider %s returned no email for subject %s\n", external.protocol, external.issuer, external.subject)
+		return nil, nil, ports.ErrOIDCAuthenticationFailed
+	}
+
+	// Existing local account
+	user, err := s.repo.GetUserByEmail(ctx, external.email)
+	if err == nil {
+		link, err := s.beginFederatedLink(ctx, external, user, role, mapped)
+		return nil, link, err
+	}
+	if !errors.Is(err, ports.ErrUserNotFound) {
+		return nil, nil, fmt.Errorf("failed to get user by email: %w", err)
+	}
+
+	user, err = s.provisionFederatedUser(ctx, external, role)
+	return user, nil, err
+}
+
+// provisionFederatedUser creates the user and its membership on the first login of an
+// identity whose email has no account yet
+func (s *AuthServiceImpl) provisionFederatedUser(ctx context.Context, external *federatedIdentity, role string) (*domain.User, error) {
+	// The user gets an unusable random password
+	passwordHash, err := s.hasher.Hash(s.generateSecureToken())
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	fullName := strings.TrimSpace(external.fullName)
+	if fullName == "" {
+		fullName = external.email
+	}
+
+	now := time.Now()
+	tenantID := external.tenantID
+	user := &domain.User{
+		ID:           uuid.New().String(),
+		TenantID:     &tenantID,
+		Email:        external.email,
+		PasswordHash: passwordHash,
+		FullName:     fullName,
+		Roles:        []string{role},
+		ActiveRole:   role,
+		IsVerified:   true,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	identity := &domain.UserIdentity{
+		ID:          uuid.New().String(),
+		UserID:      user.ID,
+		TenantID:    external.tenantID,
+		Issuer:      external.issuer,
+		Subject:     external.subject,
+		Email:       external.email,
+		LastLoginAt: &now,
+		CreatedAt:   now,
+	}
+
+	if err := s.repo.CreateFederatedUser(ctx, user, identity, role); err != nil {
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	fmt.Printf("INFO: Provisioned user %s from %s provider %s\n", user.ID, external.protocol, external.issuer)
+	return user, nil
+}
+
+// beginFederatedLink checks that an identity may be linked to the existing account with the
+// same email and stores a link request the user must confirm with a local credential.
+// A tenant's identity provider only speaks for accounts of its tenant: the user must already
+// be a member, with an email the provider verified, or the email must be on a domain the
+// tenant verified. Superadmin accounts are never linked.
+func (s *AuthServiceImpl) beginFederatedLink(ctx context.Context, external *federatedIdentity, user *domain.User, role string, mapped bool) (*domain.AuthResponse, error) {
+	if user.HasRoleInList(domain.RoleSuperAdmin) {
+		fmt.Printf("Warning: refused to link %s identity %s to superadmin %s\n", external.protocol, external.subject, user.ID)
+		return nil, ports.ErrOIDCAccountConflict
+	}
+
+	domainVerified := false
+	if at := strings.LastIndex(external.email, "@"); at >= 0 {
+		verified, err := s.repo.IsEmailDomainVerified(ctx, external.tenantID, external.email[at+1:])
+		if err != nil {
+			return nil, err
+		}
+		domainVerified = verified
+	}
+
+	if !domainVerified {
+		member, err := s.repo.HasTenantMembership(ctx, user.ID, external.tenantID)
+		if err != nil {
+			return nil, err
+		}
+		if !member || !external.emailVerified {
+			fmt.Printf("Warning: refused to link %s identity %s to user %s outside tenant %s\n", external.protocol, external.subject, user.ID, external.tenantID)
+			return nil, ports.ErrOIDCAccountConflict
+		}
+	}
+
+	methods := []string{"password"}
+	if _, err := s.getEnabledMFA(ctx, user.ID); err == nil {
+		methods = append(methods, "totp")
+	} else if !errors.Is(err, ports.ErrMFANotEnrolled) {
+		return nil, err
+	}
+
+	// Only the hash is stored; the token is returned to the client once
+	token := s.generateSecureToken()
+	now := time.Now()
+	request := &domain.FederatedLinkRequest{
+		ID:         uuid.New().String(),
+		TokenHash:  domain.HashAPIToken(token),
+		UserID:     user.ID,
+		TenantID:   external.tenantID,
+		Issuer:     external.issuer,
+		Subject:    external.subject,
+		Email:      external.email,
+		Role:       role,
+		RoleMapped: mapped,
+		ExpiresAt:  now.Add(s.mfaChallengeExpiry),
+		CreatedAt:  now,
+	}
+
+	if err := s.repo.CreateFederatedLinkRequest(ctx, request); err != nil {
+		return nil, fmt.Errorf("failed to create federated link request: %w", err)
+	}
+
+	return &domain.AuthResponse{
+		AccountLinkRequired: true,
+		AccountLinkToken:    token,
+		AccountLinkMethods:  methods,
+	}, nil
+}
+
+// ConfirmFederatedLink links a single sign-on identity to an existing account once the user
+// proves they own it, and issues tokens
+func (s *AuthServiceImpl) ConfirmFederatedLink(ctx context.Context, dto *domain.FederatedLinkDTO) (*domain.AuthResponse, error) {
+	// Validate DTO
+	if err := s.validator.Struct(dto); err != nil {
+		return nil, ports.ErrInvalidInput
+	}
+	if dto.Password == "" && dto.Code == "" && dto.RecoveryCode == "" {
+		return nil, ports.ErrInvalidInput
+	}
+
+	request, err := s.getValidFederatedLinkRequest(ctx, dto.LinkToken)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.repo.GetUserByID(ctx, request.UserID)
+	if err != nil {
+		return nil, ports.ErrUserNotFound
+	}
+
+	// Verify the local credential; passwords count towards the account lockout like a login
+	switch {
+	case dto.Password != "":
+		if err = s.throttle.check(ctx, user.Email, dto.IPAddress); err != nil {
+			return nil, err
+		}
+		if s.hasher.Compare(user.PasswordHash, dto.Password) != nil {
+			err = s.recordFailedLogin(ctx, user.Email, dto.IPAddress, user)
+		} else {
+			s.throttle.reset(ctx, user.Email)
+		}
+	case dto.RecoveryCode != "":
+		if _, err = s.getEnabledMFA(ctx, user.ID); err == nil {
+			err = s.repo.UseRecoveryCode(ctx, user.ID, hashRecoveryCode(dto.RecoveryCode))
+		}
+	default:
+		var mfa *domain.UserMFA
+		if mfa, err = s.getEnabledMFA(ctx, user.ID); err == nil {
+			err = s.verifyTOTPCode(ctx, mfa, dto.Code)
+		}
+	}
+	if err != nil {
+		if incErr := s.repo.IncrementFederatedLinkAttempts(ctx, request.ID); incErr != nil {
+			fmt.Printf("Warning: failed to record federated link attempt: %v\n", incErr)
+		}
+		return nil, err
+	}
+
+	// Link requests are single-use
+	if err := s.repo.DeleteFederatedLinkRequest(ctx, request.ID); err != nil {
+		fmt.Printf("Warning: failed to delete federated link request: %v\n", err)
+	}
+
+	now := time.Now()
+	identity := &domain.UserIdentity{
+		ID:          uuid.New().String(),
+		UserID:      user.ID,
+		TenantID:    request.TenantID,
+		Issuer:      request.Issuer,
+		Subject:     request.Subject,
+		Email:       request.Email,
+		LastLoginAt: &now,
+		CreatedAt:   now,
+	}
+	if err := s.repo.CreateUserIdentity(ctx, identity); err != nil {
+		return nil, fmt.Errorf("failed to link user identity: %w", err)
+	}
+
+	if !user.IsVerified {
+		user.IsVerified = true
+		user.UpdatedAt = now
+		if err := s.repo.UpdateUser(ctx, user); err != nil {
+			fmt.Printf("Warning: failed to mark user %s as verified: %v\n", user.ID, err)
+		}
+	}
+
+	if err := s.syncFederatedMembership(ctx, user, request.TenantID, request.Role, request.RoleMapped); err != nil {
+		return nil, err
+	}
+
+	fmt.Printf("INFO: Linked identity %s of %s to existing user %s\n", request.Subject, request.Issuer, user.ID)
+	return s.issueTokens(ctx, user, dto.SessionMetadata)
+}
+
+// getValidFederatedLinkRequest retrieves a link request and checks it can still be used
+func (s *AuthServiceImpl) getValidFederatedLinkRequest(ctx context.Context, token string) (*domain.FederatedLinkRequest, error) {
+	request, err := s.repo.GetFederatedLinkRequest(ctx, domain.HashAPIToken(token))
+	if err != nil {
+		if errors.Is(err, ports.ErrTokenNotFound) {
+			return nil, ports.ErrFederatedLinkInvalid
+		}
+		return nil, fmt.Errorf("failed to get federated link request: %w", err)
+	}
+
+	if request.IsExpired() || request.Attempts >= mfaMaxChallengeAttempts {
+		_ = s.repo.DeleteFederatedLinkRequest(ctx, request.ID)
+		return nil, ports.ErrFederatedLinkInvalid
+	}
+
+	return request, nil
+}
+
+// syncFederatedMembership makes sure an existing user has an active membership in the tenant,
+// applies the role mapped by the identity provider and switches the user to the tenant
+func (s *AuthServiceImpl) syncFederatedMembership(ctx context.Context, user *domain.User, tenantID string, role string, mapped bool) error {
+	membership, err := s.repo.EnsureMembership(ctx, user.ID, tenantID, role)
+	if err != nil {
+		return err
+	}
+
+	// Memberships disabled by a tenant admin are not reactivated by the identity provider
+	if membership.Status != "active" {
+		return ports.ErrAccountDisabled
+	}
+
+	// The identity provider is the source of truth for mapped roles
+	if mapped && membership.Role != role {
+		if err := s.repo.UpdateMembershipRole(ctx, user.ID, tenantID, role); err != nil {
+			return err
+		}
+	}
+
+	if !stringPtrEquals(user.TenantID, tenantID) {
+		if err := s.repo.UpdateUserTenant(ctx, user.ID, tenantID); err != nil {
+			return err
+		}
+		user.TenantID = &tenantID
+	}
+
+	return nil
+}
+
+// mapFederatedRole resolves the membership role from the group or role values asserted
+// by the identity provider. When several values are mapped the most privileged role wins.
+// The returned flag reports whether any value was mapped, otherwise defaultRole is returned.
+func mapFederatedRole(values []string, mapping map[string]string, defaultRole string) (string, bool) {
+	if defaultRole == "" {
+		defaultRole = string(domain.RoleStudent)
+	}
+
+	role := ""
+	for _, value := range values {
+		mappedRole, ok := mapping[value]
+		if !ok {
+			continue
+		}
+		if role == "" || domain.GetRoleHierarchy(domain.UserRole(mappedRole)) > domain.GetRoleHierarchy(domain.UserRole(role)) {
+			role = mappedRole
+		}
+	}
+
+	if role == "" {
+		return defaultRole, false
+	}
+	return role, true
+}
@@ -0,0 +1,220 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/auth/domain"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/auth/ports"
+)
+
+const testIssuer = "https://idp.example.com"
+
+// newTestIdentity returns an identity of authTenantID's provider asserting authEmail
+func newTestIdentity(emailVerified bool) *federatedIdentity {
+	return &federatedIdentity{
+		protocol:      "OIDC",
+		tenantID:      authTenantID,
+		issuer:        testIssuer,
+		subject:       "idp-subject",
+		email:         authEmail,
+		emailVerified: emailVerified,
+		fullName:      "Ana Rojas",
+	}
+}
+
+// beginTestLink runs a federated login that must ask to confirm the link to the existing user
+func beginTestLink(t *testing.T, service *AuthServiceImpl) *domain.AuthResponse {
+	t.Helper()
+	user, link, err := service.federatedLogin(context.Background(), newTestIdentity(true), "instructor", true)
+	if err != nil {
+		t.Fatalf("federatedLogin failed: %v", err)
+	}
+	if user != nil || link == nil || !link.AccountLinkRequired || link.AccountLinkToken == "" || link.AccessToken != "" {
+		t.Fatalf("Expected an account link request without tokens, got %+v", link)
+	}
+	return link
+}
+
+func TestFederatedLoginProvisionsNewUser(t *testing.T) {
+	repo := newStubAuthRepository()
+	service := newTestAuthService(t, repo)
+
+	user, link, err := service.federatedLogin(context.Background(), newTestIdentity(false), "student", false)
+	if err != nil {
+		t.Fatalf("federatedLogin failed: %v", err)
+	}
+	if link != nil || user == nil || user.Email != authEmail || !user.IsVerified {
+		t.Fatalf("Expected a provisioned user, got %+v %+v", user, link)
+	}
+	if identity := repo.identities[testIssuer+" idp-subject"]; identity == nil || identity.UserID != user.ID {
+		t.Errorf("Expected the identity to be linked to the new user, got %+v", identity)
+	}
+}
+
+func TestFederatedLinkRules(t *testing.T) {
+	tests := []struct {
+		name           string
+		member         bool
+		domainVerified bool
+		emailVerified  bool
+		superadmin     bool
+		expectLink     bool
+	}{
+		{name: "Member with verified email", member: true, emailVerified: true, expectLink: true},
+		{name: "Member without verified email", member: true},
+		{name: "Other tenant user with verified email", emailVerified: true},
+		{name: "Email on verified tenant domain", domainVerified: true, expectLink: true},
+		{name: "Superadmin", member: true, domainVerified: true, emailVerified: true, superadmin: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := newTestUser()
+			otherTenant := "33333333-3333-3333-3333-333333333333"
+			user.TenantID = &otherTenant
+			if tt.superadmin {
+				user.Roles = append(user.Roles, string(domain.RoleSuperAdmin))
+			}
+			repo := newStubAuthRepository(user)
+			if tt.member {
+				repo.memberships[authUserID] = &domain.UserMembership{TenantID: authTenantID, Role: "student", Status: "active"}
+			}
+			repo.verifiedDomains["example.com"] = tt.domainVerified
+			service := newTestAuthService(t, repo)
+
+			_, link, err := service.federatedLogin(context.Background(), newTestIdentity(tt.emailVerified), "admin", true)
+			if tt.expectLink {
+				if err != nil || link == nil || !link.AccountLinkRequired {
+					t.Fatalf("Expected an account link request, got %+v, %v", link, err)
+				}
+			} else if !errors.Is(err, ports.ErrOIDCAccountConflict) {
+				t.Fatalf("Expected ErrOIDCAccountConflict, got %+v, %v", link, err)
+			}
+
+			// Nothing is linked or granted before the user confirms
+			if len(repo.identities) != 0 {
+				t.Errorf("Expected no linked identity, got %d", len(repo.identities))
+			}
+			if membership := repo.memberships[authUserID]; !tt.member && membership != nil {
+				t.Errorf("Expected no membership before confirmation, got %+v", membership)
+			}
+			if *repo.users[authUserID].TenantID != otherTenant {
+				t.Error("Expected the user tenant to be unchanged before confirmation")
+			}
+		})
+	}
+}
+
+func TestConfirmFederatedLinkWithPassword(t *testing.T) {
+	ctx := context.Background()
+	repo := newStubAuthRepository(newTestUser())
+	repo.memberships[authUserID] = &domain.UserMembership{TenantID: authTenantID, Role: "student", Status: "active"}
+	service := newTestAuthService(t, repo)
+
+	link := beginTestLink(t, service)
+	if len(link.AccountLinkMethods) != 1 || link.AccountLinkMethods[0] != "password" {
+		t.Errorf("Expected only the password method, got %v", link.AccountLinkMethods)
+	}
+	for _, request := range repo.linkRequests {
+		if request.TokenHash != domain.HashAPIToken(link.AccountLinkToken) {
+			t.Errorf("Expected the request to store the token hash, got %q", request.TokenHash)
+		}
+	}
+
+	_, err := service.ConfirmFederatedLink(ctx, &domain.FederatedLinkDTO{LinkToken: link.AccountLinkToken, Password: "wrong password"})
+	if !errors.Is(err, ports.ErrInvalidCredentials) {
+		t.Fatalf("Expected ErrInvalidCredentials, got %v", err)
+	}
+	if len(repo.identities) != 0 {
+		t.Fatal("Expected no identity to be linked after a wrong password")
+	}
+
+	tokens, err := service.ConfirmFederatedLink(ctx, &domain.FederatedLinkDTO{LinkToken: link.AccountLinkToken, Password: authPassword})
+	if err != nil {
+		t.Fatalf("ConfirmFederatedLink failed: %v", err)
+	}
+	if tokens.AccessToken == "" || tokens.RefreshToken == "" {
+		t.Errorf("Expected tokens, got %+v", tokens)
+	}
+	if identity := repo.identities[testIssuer+" idp-subject"]; identity == nil || identity.UserID != authUserID {
+		t.Errorf("Expected the identity to be linked, got %+v", identity)
+	}
+	if role := repo.memberships[authUserID].Role; role != "instructor" {
+		t.Errorf("Expected the mapped role to be applied, got %s", role)
+	}
+
+	// Link tokens are single-use, and later logins find the linked identity
+	_, err = service.ConfirmFederatedLink(ctx, &domain.FederatedLinkDTO{LinkToken: link.AccountLinkToken, Password: authPassword})
+	if !errors.Is(err, ports.ErrFederatedLinkInvalid) {
+		t.Errorf("Expected ErrFederatedLinkInvalid for a used link token, got %v", err)
+	}
+	user, next, err := service.federatedLogin(ctx, newTestIdentity(true), "instructor", true)
+	if err != nil || next != nil || user == nil || user.ID != authUserID {
+		t.Errorf("Expected the linked user, got %+v %+v %v", user, next, err)
+	}
+}
+
+func TestConfirmFederatedLinkWithTOTP(t *testing.T) {
+	ctx := context.Background()
+	repo := newStubAuthRepository(newTestUser())
+	repo.verifiedDomains["example.com"] = true
+	secret := enrollTestMFA(t, repo, authUserID)
+	service := newTestAuthService(t, repo)
+
+	link := beginTestLink(t, service)
+	if len(link.AccountLinkMethods) != 2 || link.AccountLinkMethods[1] != "totp" {
+		t.Errorf("Expected the password and totp methods, got %v", link.AccountLinkMethods)
+	}
+
+	if _, err := service.ConfirmFederatedLink(ctx, &domain.FederatedLinkDTO{LinkToken: link.AccountLinkToken, Code: currentCode(t, secret)}); err != nil {
+		t.Fatalf("ConfirmFederatedLink failed: %v", err)
+	}
+	if membership := repo.memberships[authUserID]; membership == nil || membership.TenantID != authTenantID {
+		t.Errorf("Expected a membership in the tenant, got %+v", membership)
+	}
+}
+
+func TestConfirmFederatedLinkLimits(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Too many attempts", func(t *testing.T) {
+		repo := newStubAuthRepository(newTestUser())
+		repo.verifiedDomains["example.com"] = true
+		service := newTestAuthService(t, repo)
+
+		link := beginTestLink(t, service)
+		for i := 0; i < mfaMaxChallengeAttempts; i++ {
+			service.ConfirmFederatedLink(ctx, &domain.FederatedLinkDTO{LinkToken: link.AccountLinkToken, Password: "wrong password"})
+		}
+
+		_, err := service.ConfirmFederatedLink(ctx, &domain.FederatedLinkDTO{LinkToken: link.AccountLinkToken, Password: authPassword})
+		if !errors.Is(err, ports.ErrFederatedLinkInvalid) {
+			t.Errorf("Expected ErrFederatedLinkInvalid, got %v", err)
+		}
+		if len(repo.identities) != 0 {
+			t.Error("Expected no identity to be linked")
+		}
+	})
+
+	t.Run("TOTP code without enrollment", func(t *testing.T) {
+		repo := newStubAuthRepository(newTestUser())
+		repo.verifiedDomains["example.com"] = true
+		service := newTestAuthService(t, repo)
+
+		link := beginTestLink(t, service)
+		_, err := service.ConfirmFederatedLink(ctx, &domain.FederatedLinkDTO{LinkToken: link.AccountLinkToken, Code: "123456"})
+		if !errors.Is(err, ports.ErrMFANotEnrolled) {
+			t.Errorf("Expected ErrMFANotEnrolled, got %v", err)
+		}
+	})
+
+	t.Run("Missing credential", func(t *testing.T) {
+		service := newTestAuthService(t, newStubAuthRepository(newTestUser()))
+		_, err := service.ConfirmFederatedLink(ctx, &domain.FederatedLinkDTO{LinkToken: "token"})
+		if !errors.Is(err, ports.ErrInvalidInput) {
+			t.Errorf("Expected ErrInvalidInput, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/auth/domain"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/auth/ports"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/shared/tokens"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// StartImpersonation lets a tenant admin act as another member of the tenant
+func (s *AuthServiceImpl) StartImpersonation(ctx context.Context, tenantID string, impersonatorID string, impersonatorRole string, targetUserID string, dto *domain.StartImpersonationDTO) (*domain.ImpersonationResponse, error) {
+	// Validate DTO
+	if err := s.validator.Struct(dto); err != nil {
+		return nil, ports.ErrInvalidInput
+	}
+
+	if targetUserID == impersonatorID {
+		return nil, ports.ErrImpersonationNotAllowed
+	}
+
+	// Get target user
+	user, err := s.repo.GetUserByID(ctx, targetUserID)
+	if err != nil {
+		return nil, ports.ErrUserNotFound
+	}
+
+	// Only members of the admin's tenant can be impersonated
+	membership, err := s.repo.GetActiveMembership(ctx, user.ID, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get membership: %w", err)
+	}
+	if membership == nil {
+		return nil, ports.ErrUserNotFound
+	}
+
+	// Nobody can act as a superadmin, and only superadmins can act as an admin
+	if user.HasRoleInList(domain.RoleSuperAdmin) || membership.Role == string(domain.RoleSuperAdmin) {
+		return nil, ports.ErrImpersonationNotAllowed
+	}
+	if membership.Role == string(domain.RoleAdmin) && impersonatorRole != string(domain.RoleSuperAdmin) {
+		return nil, ports.ErrImpersonationNotAllowed
+	}
+
+	// The token acts with the target's role in the tenant and can't be refreshed
+	now := time.Now()
+	claims := &tokens.Claims{
+		UserID:         user.ID,
+		TenantID:       tenantID,
+		Email:          user.Email,
+		Role:           membership.Role,
+		ActiveRole:     membership.Role,
+		Roles:          []string{membership.Role},
+		ImpersonatorID: impersonatorID,
+	}
+	claims.ExpiresAt = jwt.NewNumericDate(now.Add(s.impersonationExpiry))
+
+	accessToken, err := s.tokenService.Generate(claims)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate access token: %w", err)
+	}
+
+	session := &domain.ImpersonationSession{
+		ID:             uuid.New().String(),
+		TenantID:       tenantID,
+		ImpersonatorID: &impersonatorID,
+		TargetUserID:   &user.ID,
+		Reason:         dto.Reason,
+		TokenID:        claims.ID, // Set by Generate
+		IPAddress:      dto.IPAddress,
+		UserAgent:      dto.UserAgent,
+		ExpiresAt:      claims.ExpiresAt.Time,
+		CreatedAt:      now,
+	}
+	if err := s.repo.CreateImpersonationSession(ctx, session); err != nil {
+		return nil, err
+	}
+
+	s.recordImpersonationEvent(ctx, session.ID, domain.ImpersonationStarted, dto.IPAddress)
+
+	fmt.Printf("INFO: User %s started impersonating user %s in tenant %s (session %s): %s\n",
+		impersonatorID, user.ID, tenantID, session.ID, dto.Reason)
+
+	return &domain.ImpersonationResponse{
+		AccessToken:    accessToken,
+		TokenType:      "Bearer",
+		ExpiresIn:      int(time.Until(session.ExpiresAt).Seconds()),
+		ExpiresAt:      session.ExpiresAt,
+		SessionID:      session.ID,
+		ImpersonatorID: impersonatorID,
+		User:           domain.ToUserDTO(user),
+	}, nil
+}
+
+// StopImpersonation ends an impersonation session, invalidating its access token
+func (s *AuthServiceImpl) StopImpersonation(ctx context.Context, sessionID string, session domain.SessionMetadata) error {
+	if err := s.repo.EndImpersonationSession(ctx, sessionID); err != nil {
+		return err
+	}
+
+	s.recordImpersonationEvent(ctx, sessionID, domain.ImpersonationStopped, session.IPAddress)
+
+	fmt.Printf("INFO: Impersonation session %s stopped\n", sessionID)
+
+	return nil
+}
+
+// ListImpersonationSessions lists the impersonation sessions of the tenant
+func (s *AuthServiceImpl) ListImpersonationSessions(ctx context.Context, tenantID string) ([]*domain.ImpersonationSession, error) {
+	return s.repo.ListImpersonationSessions(ctx, tenantID)
+}
+
+// ListImpersonationEvents returns the audit trail of an impersonation session of the tenant
+func (s *AuthServiceImpl) ListImpersonationEvents(ctx context.Context, tenantID string, sessionID string) ([]*domain.ImpersonationEvent, error) {
+	if _, err := uuid.Parse(sessionID); err != nil {
+		return nil, ports.ErrImpersonationSessionNotFound
+	}
+
+	return s.repo.ListImpersonationEvents(ctx, tenantID, sessionID)
+}
+
+// recordImpersonationEvent adds a start or stop entry to the audit trail. Failures are
+// logged since the session itself was already changed.
+func (s *AuthServiceImpl) recordImpersonationEvent(ctx context.Context, sessionID string, eventType domain.ImpersonationEventType, ipAddress string) {
+	event := &domain.ImpersonationEvent{
+		ID:        uuid.New().String(),
+		SessionID: sessionID,
+		Type:      eventType,
+		IPAddress: ipAddress,
+		CreatedAt: time.Now(),
+	}
+
+	if err := s.repo.RecordImpersonationEvent(ctx, event); err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+}
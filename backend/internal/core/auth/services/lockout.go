@@ -0,0 +1,77 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/auth/domain"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/auth/ports"
+)
+
+// recordFailedLogin counts a failed login and returns the error for the caller.
+// When the failure locks the account, the user is emailed a link to unlock it.
+func (s *AuthServiceImpl) recordFailedLogin(ctx context.Context, email string, ipAddress string, user *domain.User) error {
+	if !s.throttle.recordFailure(ctx, email, ipAddress) {
+		return ports.ErrInvalidCredentials
+	}
+
+	if user != nil {
+		s.sendUnlockEmail(ctx, user)
+	}
+	return ports.ErrAccountLocked
+}
+
+// sendUnlockEmail emails the user a one-time link that lifts the lockout
+func (s *AuthServiceImpl) sendUnlockEmail(ctx context.Context, user *domain.User) {
+	if s.emailService == nil {
+		return
+	}
+
+	unlockToken := s.generateSecureToken()
+	if err := s.throttle.createUnlockToken(ctx, user.Email, unlockToken); err != nil {
+		fmt.Printf("Warning: failed to create unlock token for user %s: %v\n", user.ID, err)
+		return
+	}
+
+	if err := s.emailService.SendAccountLockedEmail(ctx, user.Email, user.FullName, unlockToken, s.throttle.policy.LockoutDuration); err != nil {
+		fmt.Printf("Warning: failed to send account locked email to user %s: %v\n", user.ID, err)
+	}
+}
+
+// UnlockAccount lifts a lockout with the token from the account locked email
+func (s *AuthServiceImpl) UnlockAccount(ctx context.Context, dto *domain.UnlockAccountDTO) error {
+	if err := s.validator.Struct(dto); err != nil {
+		return ports.ErrInvalidInput
+	}
+
+	email, err := s.throttle.redeemUnlockToken(ctx, dto.Token)
+	if err != nil {
+		return err
+	}
+
+	s.throttle.reset(ctx, email)
+	fmt.Printf("INFO: Account %s unlocked from email link\n", email)
+
+	return nil
+}
+
+// UnlockUser lets a tenant admin lift the lockout and failed login delays of a member
+func (s *AuthServiceImpl) UnlockUser(ctx context.Context, tenantID string, userID string) error {
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return ports.ErrUserNotFound
+	}
+
+	membership, err := s.repo.GetActiveMembership(ctx, user.ID, tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to get membership: %w", err)
+	}
+	if membership == nil {
+		return ports.ErrUserNotFound
+	}
+
+	s.throttle.reset(ctx, user.Email)
+	fmt.Printf("INFO: Account %s unlocked by tenant %s admin\n", user.ID, tenantID)
+
+	return nil
+}
@@ -0,0 +1,265 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/auth/ports"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/shared/cache"
+)
+
+// LoginThrottlePolicy configures the brute-force and credential-stuffing protection of
+// Login, ForgotPassword and RequestMagicLink. Failed attempts are counted per account (email) and per
+// client IP within FailureWindow.
+type LoginThrottlePolicy struct {
+	FailureWindow time.Duration // Time after the first failure before the counters reset
+
+	// Progressive delays: from DelayThreshold account failures on, the next attempt must
+	// wait BaseDelay, doubling with each further failure up to MaxDelay
+	DelayThreshold int
+	BaseDelay      time.Duration
+	MaxDelay       time.Duration
+
+	// Temporary lockout: after MaxFailedLogins account failures the account is locked
+	// for LockoutDuration and an unlock email is sent to the user
+	MaxFailedLogins int
+	LockoutDuration time.Duration
+
+	// Credential stuffing: after MaxFailedLoginsPerIP failures from one IP, across any
+	// accounts, the IP is blocked for LockoutDuration
+	MaxFailedLoginsPerIP int
+
+	// Password reset and magic link requests allowed per account within FailureWindow
+	MaxPasswordResetsPerAccount int
+	MaxMagicLinksPerAccount     int
+}
+
+// throttleStore is the subset of cache.Cache used by loginThrottle
+type throttleStore interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Exists(ctx context.Context, key string) (bool, error)
+	Increment(ctx context.Context, key string) (int64, error)
+	Expire(ctx context.Context, key string, ttl time.Duration) error
+	DeleteMulti(ctx context.Context, keys []string) error
+}
+
+// loginThrottle keeps the failed attempt counters in the shared cache so that the limits
+// apply across replicas. Cache errors are logged and let the request through, so a cache
+// outage does not lock every user out.
+type loginThrottle struct {
+	cache  throttleStore
+	policy LoginThrottlePolicy
+}
+
+// newLoginThrottle falls back to counters kept in memory when no cache is configured.
+// The limits then apply per replica, which is still far better than no limit at all.
+func newLoginThrottle(c cache.Cache, policy LoginThrottlePolicy) *loginThrottle {
+	if c == nil {
+		fmt.Printf("Warning: no cache configured for login throttling, failed attempts are counted in memory and the limits apply per replica\n")
+		return &loginThrottle{cache: newMemoryThrottleStore(), policy: policy}
+	}
+	return &loginThrottle{cache: c, policy: policy}
+}
+
+// Cache keys. Accounts are keyed by normalized email so that attempts against unknown
+// emails are throttled the same way as attempts against existing accounts.
+func accountFailuresKey(email string) string { return "auth:login:failures:account:" + email }
+func accountDelayKey(email string) string    { return "auth:login:delay:account:" + email }
+func accountLockKey(email string) string     { return "auth:login:lock:account:" + email }
+func ipFailuresKey(ip string) string         { return "auth:login:failures:ip:" + ip }
+func ipLockKey(ip string) string             { return "auth:login:lock:ip:" + ip }
+func passwordResetsKey(email string) string  { return "auth:password-reset:requests:" + email }
+func magicLinksKey(email string) string      { return "auth:magic-link:requests:" + email }
+
+// unlockTokenKey stores unlock tokens by hash, like the other one-time tokens
+func unlockTokenKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return "auth:login:unlock:" + hex.EncodeToString(sum[:])
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// check returns ErrAccountLocked or ErrTooManyLoginAttempts if the account or the client
+// IP may not attempt a login right now
+func (t *loginThrottle) check(ctx context.Context, email string, ip string) error {
+	if t == nil {
+		return nil
+	}
+	email = normalizeEmail(email)
+
+	if t.exists(ctx, accountLockKey(email)) {
+		return ports.ErrAccountLocked
+	}
+	if t.exists(ctx, accountDelayKey(email)) {
+		return ports.ErrTooManyLoginAttempts
+	}
+	if ip != "" && t.exists(ctx, ipLockKey(ip)) {
+		return ports.ErrTooManyLoginAttempts
+	}
+	return nil
+}
+
+// recordFailure counts a failed login for the account and the client IP and applies the
+// progressive delay, account lockout and IP block. It reports whether the account was
+// locked by this failure.
+func (t *loginThrottle) recordFailure(ctx context.Context, email string, ip string) bool {
+	if t == nil {
+		return false
+	}
+	email = normalizeEmail(email)
+
+	t.recordIPFailure(ctx, ip)
+
+	failures := t.increment(ctx, accountFailuresKey(email))
+	if t.policy.MaxFailedLogins > 0 && failures >= int64(t.policy.MaxFailedLogins) {
+		fmt.Printf("Warning: locking account %s for %s after %d failed logins\n", email, t.policy.LockoutDuration, failures)
+		t.set(ctx, accountLockKey(email), t.policy.LockoutDuration)
+		t.delete(ctx, accountFailuresKey(email), accountDelayKey(email))
+		return true
+	}
+
+	if t.policy.DelayThreshold > 0 && failures >= int64(t.policy.DelayThreshold) {
+		t.set(ctx, accountDelayKey(email), t.delay(failures))
+	}
+	return false
+}
+
+// recordIPFailure counts a failure for the client IP and blocks it at MaxFailedLoginsPerIP
+func (t *loginThrottle) recordIPFailure(ctx context.Context, ip string) {
+	if t == nil || ip == "" {
+		return
+	}
+
+	failures := t.increment(ctx, ipFailuresKey(ip))
+	if t.policy.MaxFailedLoginsPerIP > 0 && failures >= int64(t.policy.MaxFailedLoginsPerIP) {
+		fmt.Printf("Warning: blocking IP %s for %s after %d failed attempts\n", ip, t.policy.LockoutDuration, failures)
+		t.set(ctx, ipLockKey(ip), t.policy.LockoutDuration)
+		t.delete(ctx, ipFailuresKey(ip))
+	}
+}
+
+// delay returns the wait imposed after the given number of account failures
+func (t *loginThrottle) delay(failures int64) time.Duration {
+	delay := t.policy.BaseDelay
+	for i := int64(t.policy.DelayThreshold); i < failures && delay < t.policy.MaxDelay; i++ {
+		delay *= 2
+	}
+	if t.policy.MaxDelay > 0 && delay > t.policy.MaxDelay {
+		delay = t.policy.MaxDelay
+	}
+	return delay
+}
+
+// reset clears the failure counters, delay and lockout of an account
+func (t *loginThrottle) reset(ctx context.Context, email string) {
+	if t == nil {
+		return
+	}
+	email = normalizeEmail(email)
+	t.delete(ctx, accountFailuresKey(email), accountDelayKey(email), accountLockKey(email))
+}
+
+// allowPasswordReset counts a password reset request. It returns ErrTooManyLoginAttempts
+// if the client IP is blocked, and false if the account exceeded its reset requests, in
+// which case the request should be silently ignored to avoid revealing the account.
+func (t *loginThrottle) allowPasswordReset(ctx context.Context, email string, ip string) (bool, error) {
+	if t == nil {
+		return true, nil
+	}
+	email = normalizeEmail(email)
+	return t.allowEmailRequest(ctx, "password reset", email, passwordResetsKey(email), t.policy.MaxPasswordResetsPerAccount, ip)
+}
+
+// allowMagicLink counts a magic link request, with the same rules as allowPasswordReset
+func (t *loginThrottle) allowMagicLink(ctx context.Context, email string, ip string) (bool, error) {
+	if t == nil {
+		return true, nil
+	}
+	email = normalizeEmail(email)
+	return t.allowEmailRequest(ctx, "magic link", email, magicLinksKey(email), t.policy.MaxMagicLinksPerAccount, ip)
+}
+
+// allowEmailRequest counts a request that emails the account in the counter at key,
+// allowing up to limit requests per FailureWindow
+func (t *loginThrottle) allowEmailRequest(ctx context.Context, kind string, email string, key string, limit int, ip string) (bool, error) {
+	if ip != "" && t.exists(ctx, ipLockKey(ip)) {
+		return false, ports.ErrTooManyLoginAttempts
+	}
+
+	requests := t.increment(ctx, key)
+	if limit > 0 && requests > int64(limit) {
+		fmt.Printf("Warning: ignoring %s request %d for %s from %s\n", kind, requests, email, ip)
+		// Repeated requests count against the client IP like failed logins
+		t.recordIPFailure(ctx, ip)
+		return false, nil
+	}
+	return true, nil
+}
+
+// createUnlockToken stores a one-time token that lifts the lockout of an account
+func (t *loginThrottle) createUnlockToken(ctx context.Context, email string, token string) error {
+	return t.cache.Set(ctx, unlockTokenKey(token), []byte(normalizeEmail(email)), t.policy.LockoutDuration)
+}
+
+// redeemUnlockToken consumes an unlock token and returns the email of the locked account
+func (t *loginThrottle) redeemUnlockToken(ctx context.Context, token string) (string, error) {
+	if t == nil {
+		return "", ports.ErrUnlockTokenInvalid
+	}
+
+	key := unlockTokenKey(token)
+	value, err := t.cache.Get(ctx, key)
+	if err != nil {
+		if errors.Is(err, cache.ErrCacheMiss) {
+			return "", ports.ErrUnlockTokenInvalid
+		}
+		return "", fmt.Errorf("failed to get unlock token: %w", err)
+	}
+	t.delete(ctx, key)
+
+	return string(value), nil
+}
+
+// increment counts an event in a counter that expires FailureWindow after its first event
+func (t *loginThrottle) increment(ctx context.Context, key string) int64 {
+	count, err := t.cache.Increment(ctx, key)
+	if err != nil {
+		fmt.Printf("Warning: failed to increment login counter %s: %v\n", key, err)
+		return 0
+	}
+	if count == 1 {
+		if err := t.cache.Expire(ctx, key, t.policy.FailureWindow); err != nil {
+			fmt.Printf("Warning: failed to set expiry of login counter %s: %v\n", key, err)
+		}
+	}
+	return count
+}
+
+func (t *loginThrottle) exists(ctx context.Context, key string) bool {
+	exists, err := t.cache.Exists(ctx, key)
+	if err != nil {
+		fmt.Printf("Warning: failed to check login throttle key %s: %v\n", key, err)
+		return false
+	}
+	return exists
+}
+
+func (t *loginThrottle) set(ctx context.Context, key string, ttl time.Duration) {
+	if err := t.cache.Set(ctx, key, []byte("1"), ttl); err != nil {
+		fmt.Printf("Warning: failed to set login throttle key %s: %v\n", key, err)
+	}
+}
+
+func (t *loginThrottle) delete(ctx context.Context, keys ...string) {
+	if err := t.cache.DeleteMulti(ctx, keys); err != nil {
+		fmt.Printf("Warning: failed to clear login throttle keys: %v\n", err)
+	}
+}
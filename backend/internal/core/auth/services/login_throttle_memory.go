@@ -0,0 +1,125 @@
+package services
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/DanielIturra1610/stegmaier-landing/internal/shared/cache"
+)
+
+// memoryThrottleSweepSize is the number of keys above which writes drop expired entries
+const memoryThrottleSweepSize = 10000
+
+// memoryThrottleStore keeps the login throttle counters in process memory when no shared
+// cache is configured. Keys expire like in Redis.
+type memoryThrottleStore struct {
+	mu      sync.Mutex
+	entries map[string]*memoryThrottleEntry
+	now     func() time.Time
+}
+
+type memoryThrottleEntry struct {
+	value     []byte
+	expiresAt time.Time // Zero - never expires
+}
+
+func newMemoryThrottleStore() *memoryThrottleStore {
+	return &memoryThrottleStore{
+		entries: make(map[string]*memoryThrottleEntry),
+		now:     time.Now,
+	}
+}
+
+// entry returns the live entry at key, dropping it if it expired. Callers hold mu.
+func (s *memoryThrottleStore) entry(key string) *memoryThrottleEntry {
+	entry, ok := s.entries[key]
+	if !ok {
+		return nil
+	}
+	if !entry.expiresAt.IsZero() && !s.now().Before(entry.expiresAt) {
+		delete(s.entries, key)
+		return nil
+	}
+	return entry
+}
+
+// put stores an entry, sweeping expired ones when the store grows large. Callers hold mu.
+func (s *memoryThrottleStore) put(key string, entry *memoryThrottleEntry) {
+	if len(s.entries) >= memoryThrottleSweepSize {
+		for k := range s.entries {
+			s.entry(k)
+		}
+	}
+	s.entries[key] = entry
+}
+
+func (s *memoryThrottleStore) expiry(ttl time.Duration) time.Time {
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	return s.now().Add(ttl)
+}
+
+func (s *memoryThrottleStore) Get(ctx context.Context, key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry := s.entry(key)
+	if entry == nil {
+		return nil, cache.ErrCacheMiss
+	}
+	return entry.value, nil
+}
+
+func (s *memoryThrottleStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.put(key, &memoryThrottleEntry{value: value, expiresAt: s.expiry(ttl)})
+	return nil
+}
+
+func (s *memoryThrottleStore) Exists(ctx context.Context, key string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.entry(key) != nil, nil
+}
+
+func (s *memoryThrottleStore) Increment(ctx context.Context, key string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry := s.entry(key)
+	if entry == nil {
+		entry = &memoryThrottleEntry{}
+		s.put(key, entry)
+	}
+
+	count, _ := strconv.ParseInt(string(entry.value), 10, 64)
+	count++
+	entry.value = []byte(strconv.FormatInt(count, 10))
+	return count, nil
+}
+
+func (s *memoryThrottleStore) Expire(ctx context.Context, key string, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry := s.entry(key); entry != nil {
+		entry.expiresAt = s.expiry(ttl)
+	}
+	return nil
+}
+
+func (s *memoryThrottleStore) DeleteMulti(ctx context.Context, keys []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, key := range keys {
+		delete(s.entries, key)
+	}
+	return nil
+}
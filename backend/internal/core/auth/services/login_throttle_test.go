@@ -0,0 +1,210 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/auth/domain"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/auth/ports"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/shared/cache"
+)
+
+// fakeCache keeps values and the TTL they were last given in memory. Keys never expire;
+// the tests check the TTLs instead. The other methods are not used.
+type fakeCache struct {
+	cache.Cache
+	values map[string][]byte
+	ttls   map[string]time.Duration
+}
+
+func newFakeCache() *fakeCache {
+	return &fakeCache{values: make(map[string][]byte), ttls: make(map[string]time.Duration)}
+}
+
+func (c *fakeCache) Get(ctx context.Context, key string) ([]byte, error) {
+	value, ok := c.values[key]
+	if !ok {
+		return nil, cache.ErrCacheMiss
+	}
+	return value, nil
+}
+
+func (c *fakeCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	c.values[key] = value
+	c.ttls[key] = ttl
+	return nil
+}
+
+func (c *fakeCache) Exists(ctx context.Context, key string) (bool, error) {
+	_, ok := c.values[key]
+	return ok, nil
+}
+
+func (c *fakeCache) Increment(ctx context.Context, key string) (int64, error) {
+	count, _ := strconv.ParseInt(string(c.values[key]), 10, 64)
+	count++
+	c.values[key] = []byte(strconv.FormatInt(count, 10))
+	return count, nil
+}
+
+func (c *fakeCache) Expire(ctx context.Context, key string, ttl time.Duration) error {
+	c.ttls[key] = ttl
+	return nil
+}
+
+func (c *fakeCache) DeleteMulti(ctx context.Context, keys []string) error {
+	for _, key := range keys {
+		delete(c.values, key)
+		delete(c.ttls, key)
+	}
+	return nil
+}
+
+var testThrottlePolicy = LoginThrottlePolicy{
+	FailureWindow:        15 * time.Minute,
+	DelayThreshold:       3,
+	BaseDelay:            time.Second,
+	MaxDelay:             4 * time.Second,
+	MaxFailedLogins:      8,
+	LockoutDuration:      30 * time.Minute,
+	MaxFailedLoginsPerIP: 20,
+}
+
+const throttleIP = "203.0.113.7"
+
+func TestLoginThrottleDelayDoubles(t *testing.T) {
+	ctx := context.Background()
+	c := newFakeCache()
+	throttle := newLoginThrottle(c, testThrottlePolicy)
+
+	expected := []time.Duration{0, 0, time.Second, 2 * time.Second, 4 * time.Second, 4 * time.Second}
+	for i, delay := range expected {
+		if locked := throttle.recordFailure(ctx, authEmail, throttleIP); locked {
+			t.Fatalf("Failure %d: expected no lockout", i+1)
+		}
+
+		ttl, delayed := c.ttls[accountDelayKey(authEmail)]
+		if delay == 0 {
+			if delayed {
+				t.Errorf("Failure %d: expected no delay, got %s", i+1, ttl)
+			}
+			continue
+		}
+		if ttl != delay {
+			t.Errorf("Failure %d: expected delay %s, got %s", i+1, delay, ttl)
+		}
+	}
+
+	if c.ttls[accountFailuresKey(authEmail)] != testThrottlePolicy.FailureWindow {
+		t.Errorf("Expected the failure counter to expire after the failure window")
+	}
+	if err := throttle.check(ctx, "  Ana@Example.com ", ""); !errors.Is(err, ports.ErrTooManyLoginAttempts) {
+		t.Errorf("Expected the delayed account to be refused regardless of case, got %v", err)
+	}
+}
+
+func TestLoginThrottleLocksAccountAtThreshold(t *testing.T) {
+	ctx := context.Background()
+	c := newFakeCache()
+	throttle := newLoginThrottle(c, testThrottlePolicy)
+
+	for i := 1; i < testThrottlePolicy.MaxFailedLogins; i++ {
+		if throttle.recordFailure(ctx, authEmail, "") {
+			t.Fatalf("Failure %d: expected no lockout before the threshold", i)
+		}
+	}
+	if !throttle.recordFailure(ctx, authEmail, "") {
+		t.Fatalf("Expected the account to be locked at the threshold")
+	}
+
+	if c.ttls[accountLockKey(authEmail)] != testThrottlePolicy.LockoutDuration {
+		t.Errorf("Expected the lock to last the lockout duration, got %s", c.ttls[accountLockKey(authEmail)])
+	}
+	if _, ok := c.values[accountFailuresKey(authEmail)]; ok {
+		t.Errorf("Expected the failure counter to be cleared by the lockout")
+	}
+	if err := throttle.check(ctx, authEmail, ""); !errors.Is(err, ports.ErrAccountLocked) {
+		t.Errorf("Expected ErrAccountLocked, got %v", err)
+	}
+}
+
+func TestLoginThrottleBlocksIP(t *testing.T) {
+	ctx := context.Background()
+	policy := testThrottlePolicy
+	policy.MaxFailedLoginsPerIP = 3
+	throttle := newLoginThrottle(newFakeCache(), policy)
+
+	// One failure per account, so no account is delayed or locked
+	for i := 0; i < policy.MaxFailedLoginsPerIP; i++ {
+		throttle.recordFailure(ctx, "user"+strconv.Itoa(i)+"@example.com", throttleIP)
+	}
+
+	if err := throttle.check(ctx, "other@example.com", throttleIP); !errors.Is(err, ports.ErrTooManyLoginAttempts) {
+		t.Errorf("Expected the IP to be blocked, got %v", err)
+	}
+	if err := throttle.check(ctx, "other@example.com", "198.51.100.1"); err != nil {
+		t.Errorf("Expected other IPs to be allowed, got %v", err)
+	}
+	if allowed, err := throttle.allowPasswordReset(ctx, "other@example.com", throttleIP); allowed || !errors.Is(err, ports.ErrTooManyLoginAttempts) {
+		t.Errorf("Expected password resets from the blocked IP to be refused, got %v %v", allowed, err)
+	}
+}
+
+func TestLoginResetsThrottleOnSuccess(t *testing.T) {
+	ctx := context.Background()
+	c := newFakeCache()
+	repo := newStubAuthRepository(newTestUser())
+	service := NewAuthService(repo, stubHasher{}, stubTokenService{}, nil, AuthServiceConfig{
+		AccessTokenExpiry:  15 * time.Minute,
+		RefreshTokenExpiry: 24 * time.Hour,
+		MFAChallengeExpiry: 5 * time.Minute,
+		PasswordPolicy:     stubPasswordPolicy{},
+		LoginAttemptCache:  c,
+		LoginThrottle:      testThrottlePolicy,
+	}).(*AuthServiceImpl)
+
+	for i := 0; i < testThrottlePolicy.DelayThreshold-1; i++ {
+		_, err := service.Login(ctx, authTenantID, &domain.LoginDTO{Email: authEmail, Password: "wrong-password"})
+		if !errors.Is(err, ports.ErrInvalidCredentials) {
+			t.Fatalf("Expected ErrInvalidCredentials, got %v", err)
+		}
+	}
+	if string(c.values[accountFailuresKey(authEmail)]) != strconv.Itoa(testThrottlePolicy.DelayThreshold-1) {
+		t.Fatalf("Expected the failures to be counted, got %q", c.values[accountFailuresKey(authEmail)])
+	}
+
+	login(t, service)
+
+	if _, ok := c.values[accountFailuresKey(authEmail)]; ok {
+		t.Errorf("Expected a successful login to reset the failure counter")
+	}
+
+	// The counter starts over: the next failure is not delayed
+	service.Login(ctx, authTenantID, &domain.LoginDTO{Email: authEmail, Password: "wrong-password"})
+	if err := service.throttle.check(ctx, authEmail, ""); err != nil {
+		t.Errorf("Expected no delay after the reset, got %v", err)
+	}
+}
+
+func TestLoginThrottleWithoutCache(t *testing.T) {
+	ctx := context.Background()
+	throttle := newLoginThrottle(nil, testThrottlePolicy)
+
+	for i := 0; i < testThrottlePolicy.MaxFailedLogins; i++ {
+		throttle.recordFailure(ctx, authEmail, throttleIP)
+	}
+	if err := throttle.check(ctx, authEmail, ""); !errors.Is(err, ports.ErrAccountLocked) {
+		t.Fatalf("Expected the in-memory fallback to lock the account, got %v", err)
+	}
+
+	// Keys expire like in the shared cache
+	store := throttle.cache.(*memoryThrottleStore)
+	now := time.Now()
+	store.now = func() time.Time { return now.Add(testThrottlePolicy.LockoutDuration) }
+	if err := throttle.check(ctx, authEmail, ""); err != nil {
+		t.Errorf("Expected the lock to expire, got %v", err)
+	}
+}
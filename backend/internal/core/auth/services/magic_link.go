@@ -0,0 +1,183 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/auth/domain"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/auth/ports"
+	"github.com/google/uuid"
+)
+
+// RequestMagicLink emails a single-use login link to a member of a tenant that enabled
+// magic links. Like ForgotPassword, it succeeds for unknown emails.
+func (s *AuthServiceImpl) RequestMagicLink(ctx context.Context, tenantID string, dto *domain.MagicLinkRequestDTO) error {
+	// Validate DTO
+	if err := s.validator.Struct(dto); err != nil {
+		return ports.ErrInvalidInput
+	}
+
+	if err := s.checkMagicLinkEnabled(ctx, tenantID); err != nil {
+		return err
+	}
+
+	// Limit link requests per account and client
+	allowed, err := s.throttle.allowMagicLink(ctx, dto.Email, dto.IPAddress)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return nil
+	}
+
+	// Get user by email
+	user, err := s.repo.GetUserByEmail(ctx, dto.Email)
+	if err != nil {
+		// Requests for unknown emails count against the client
+		s.throttle.recordIPFailure(ctx, dto.IPAddress)
+		return nil
+	}
+
+	// Only members of the tenant get a link
+	if user.TenantID != nil {
+		if !stringPtrEquals(user.TenantID, tenantID) {
+			return nil
+		}
+	} else {
+		membership, err := s.repo.GetActiveMembership(ctx, user.ID, tenantID)
+		if err != nil {
+			return fmt.Errorf("failed to get membership: %w", err)
+		}
+		if membership == nil {
+			return nil
+		}
+	}
+
+	if s.emailService == nil {
+		fmt.Printf("Warning: email service not configured, magic link for user %s not sent\n", user.ID)
+		return nil
+	}
+
+	// A new link replaces the ones sent before
+	if err := s.repo.DeleteMagicLinkTokensByUserID(ctx, user.ID); err != nil {
+		fmt.Printf("Warning: failed to delete old magic link tokens: %v\n", err)
+	}
+
+	// Only the hash of the token is stored
+	token := s.generateSecureToken()
+	now := time.Now()
+	magicLink := &domain.MagicLinkToken{
+		ID:        uuid.New().String(),
+		UserID:    user.ID,
+		TenantID:  tenantID,
+		TokenHash: domain.HashAPIToken(token),
+		ExpiresAt: now.Add(s.magicLinkExpiry),
+		CreatedAt: now,
+	}
+
+	if err := s.repo.CreateMagicLinkToken(ctx, magicLink); err != nil {
+		return fmt.Errorf("failed to create magic link token: %w", err)
+	}
+
+	if err := s.emailService.SendMagicLinkEmail(ctx, user.Email, user.FullName, token, s.magicLinkExpiry); err != nil {
+		fmt.Printf("Warning: failed to send magic link email to user %s: %v\n", user.ID, err)
+	}
+
+	return nil
+}
+
+// LoginWithMagicLink redeems a login link. The link replaces the password only, so
+// users who must provide a second factor get an MFA challenge like in Login.
+func (s *AuthServiceImpl) LoginWithMagicLink(ctx context.Context, dto *domain.MagicLinkLoginDTO) (*domain.AuthResponse, error) {
+	// Validate DTO
+	if err := s.validator.Struct(dto); err != nil {
+		return nil, ports.ErrInvalidInput
+	}
+
+	// Consume the token first so that each link works only once
+	magicLink, err := s.repo.ConsumeMagicLinkToken(ctx, domain.HashAPIToken(dto.Token))
+	if err != nil {
+		if errors.Is(err, ports.ErrTokenNotFound) {
+			s.throttle.recordIPFailure(ctx, dto.IPAddress)
+			return nil, ports.ErrMagicLinkInvalid
+		}
+		return nil, err
+	}
+	if magicLink.IsExpired() {
+		return nil, ports.ErrMagicLinkInvalid
+	}
+
+	// Links stop working as soon as the tenant disables magic links
+	if err := s.checkMagicLinkEnabled(ctx, magicLink.TenantID); err != nil {
+		return nil, err
+	}
+
+	// Get user
+	user, err := s.repo.GetUserByID(ctx, magicLink.UserID)
+	if err != nil {
+		return nil, ports.ErrMagicLinkInvalid
+	}
+
+	// Check if user is verified
+	if !user.IsVerified {
+		return nil, ports.ErrAccountNotVerified
+	}
+
+	// Proving access to the email also lifts a lockout
+	s.throttle.reset(ctx, user.Email)
+
+	// Require a second factor if the user enrolled MFA or the tenant policy demands it
+	mfaResponse, err := s.beginMFAChallenge(ctx, magicLink.TenantID, user)
+	if err != nil {
+		return nil, err
+	}
+	if mfaResponse != nil {
+		return mfaResponse, nil
+	}
+
+	fmt.Printf("INFO: User %s logged in with a magic link\n", user.ID)
+
+	// Generate JWT tokens
+	return s.issueTokens(ctx, user, dto.SessionMetadata)
+}
+
+// GetMagicLinkSettings returns whether the tenant allows magic link login
+func (s *AuthServiceImpl) GetMagicLinkSettings(ctx context.Context, tenantID string) (*domain.MagicLinkSettingsDTO, error) {
+	enabled, err := s.repo.GetTenantMagicLinkEnabled(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.MagicLinkSettingsDTO{Enabled: enabled}, nil
+}
+
+// UpdateMagicLinkSettings enables or disables magic link login in the tenant
+func (s *AuthServiceImpl) UpdateMagicLinkSettings(ctx context.Context, tenantID string, dto *domain.MagicLinkSettingsDTO) (*domain.MagicLinkSettingsDTO, error) {
+	if err := s.repo.UpdateTenantMagicLinkEnabled(ctx, tenantID, dto.Enabled); err != nil {
+		return nil, err
+	}
+
+	return &domain.MagicLinkSettingsDTO{Enabled: dto.Enabled}, nil
+}
+
+// checkMagicLinkEnabled returns ErrMagicLinkDisabled unless the tenant enabled magic links
+func (s *AuthServiceImpl) checkMagicLinkEnabled(ctx context.Context, tenantID string) error {
+	if tenantID == "" {
+		return ports.ErrMagicLinkDisabled
+	}
+
+	enabled, err := s.repo.GetTenantMagicLinkEnabled(ctx, tenantID)
+	if err != nil {
+		if errors.Is(err, ports.ErrTenantNotFound) {
+			return ports.ErrMagicLinkDisabled
+		}
+		return err
+	}
+	if !enabled {
+		return ports.ErrMagicLinkDisabled
+	}
+
+	return nil
+}
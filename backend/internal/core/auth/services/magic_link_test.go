@@ -0,0 +1,109 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/auth/domain"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/auth/ports"
+)
+
+// stubMagicLinkEmails records the tokens of the login links sent by email
+type stubMagicLinkEmails struct {
+	ports.EmailService
+	tokens []string
+}
+
+func (e *stubMagicLinkEmails) SendMagicLinkEmail(ctx context.Context, to, userName, loginToken string, expiresIn time.Duration) error {
+	e.tokens = append(e.tokens, loginToken)
+	return nil
+}
+
+// requestMagicLink requests a login link for authEmail and returns the token sent by email
+func requestMagicLink(t *testing.T, service *AuthServiceImpl, emails *stubMagicLinkEmails) string {
+	t.Helper()
+	if err := service.RequestMagicLink(context.Background(), authTenantID, &domain.MagicLinkRequestDTO{Email: authEmail}); err != nil {
+		t.Fatalf("RequestMagicLink failed: %v", err)
+	}
+	if len(emails.tokens) == 0 {
+		t.Fatal("Expected a login link to be sent")
+	}
+	return emails.tokens[len(emails.tokens)-1]
+}
+
+func newTestMagicLinkService(t *testing.T, repo *stubAuthRepository) (*AuthServiceImpl, *stubMagicLinkEmails) {
+	t.Helper()
+	emails := &stubMagicLinkEmails{}
+	service := newTestAuthService(t, repo)
+	service.emailService = emails
+	service.magicLinkExpiry = 15 * time.Minute
+	return service, emails
+}
+
+func TestMagicLinkStoresTokenHash(t *testing.T) {
+	repo := newStubAuthRepository(newTestUser())
+	service, emails := newTestMagicLinkService(t, repo)
+
+	token := requestMagicLink(t, service, emails)
+
+	if len(repo.magicLinks) != 1 {
+		t.Fatalf("Expected one stored login link, got %d", len(repo.magicLinks))
+	}
+	for tokenHash, magicLink := range repo.magicLinks {
+		if tokenHash == token || tokenHash != domain.HashAPIToken(token) {
+			t.Errorf("Expected the SHA-256 hash of the token to be stored, got %q", tokenHash)
+		}
+		if magicLink.UserID != authUserID || magicLink.TenantID != authTenantID {
+			t.Errorf("Expected the link of %s in %s, got %+v", authUserID, authTenantID, magicLink)
+		}
+	}
+}
+
+func TestMagicLinkWorksOnce(t *testing.T) {
+	ctx := context.Background()
+	repo := newStubAuthRepository(newTestUser())
+	service, emails := newTestMagicLinkService(t, repo)
+
+	token := requestMagicLink(t, service, emails)
+
+	response, err := service.LoginWithMagicLink(ctx, &domain.MagicLinkLoginDTO{Token: token})
+	if err != nil {
+		t.Fatalf("LoginWithMagicLink failed: %v", err)
+	}
+	if response.AccessToken == "" || response.RefreshToken == "" {
+		t.Errorf("Expected tokens to be issued, got %+v", response)
+	}
+
+	if _, err := service.LoginWithMagicLink(ctx, &domain.MagicLinkLoginDTO{Token: token}); !errors.Is(err, ports.ErrMagicLinkInvalid) {
+		t.Errorf("Expected ErrMagicLinkInvalid for a used link, got %v", err)
+	}
+}
+
+func TestMagicLinkReplacesPreviousLinks(t *testing.T) {
+	repo := newStubAuthRepository(newTestUser())
+	service, emails := newTestMagicLinkService(t, repo)
+
+	first := requestMagicLink(t, service, emails)
+	second := requestMagicLink(t, service, emails)
+
+	if _, err := service.LoginWithMagicLink(context.Background(), &domain.MagicLinkLoginDTO{Token: first}); !errors.Is(err, ports.ErrMagicLinkInvalid) {
+		t.Errorf("Expected ErrMagicLinkInvalid for a replaced link, got %v", err)
+	}
+	if _, err := service.LoginWithMagicLink(context.Background(), &domain.MagicLinkLoginDTO{Token: second}); err != nil {
+		t.Errorf("Expected the latest link to work, got %v", err)
+	}
+}
+
+func TestMagicLinkExpires(t *testing.T) {
+	repo := newStubAuthRepository(newTestUser())
+	service, emails := newTestMagicLinkService(t, repo)
+
+	token := requestMagicLink(t, service, emails)
+	repo.magicLinks[domain.HashAPIToken(token)].ExpiresAt = time.Now().Add(-time.Minute)
+
+	if _, err := service.LoginWithMagicLink(context.Background(), &domain.MagicLinkLoginDTO{Token: token}); !errors.Is(err, ports.ErrMagicLinkInvalid) {
+		t.Errorf("Expected ErrMagicLinkInvalid for an expired link, got %v", err)
+	}
+}
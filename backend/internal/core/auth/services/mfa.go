@@ -0,0 +1,545 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/auth/domain"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/auth/ports"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/shared/totp"
+	"github.com/google/uuid"
+)
+
+const (
+	// mfaRecoveryCodeCount is the number of recovery codes generated per enrollment
+	mfaRecoveryCodeCount = 10
+
+	// mfaMaxChallengeAttempts is the number of wrong codes accepted before a challenge is discarded
+	mfaMaxChallengeAttempts = 5
+)
+
+// VerifyMFALogin completes a login that returned an MFA challenge
+func (s *AuthServiceImpl) VerifyMFALogin(ctx context.Context, dto *domain.MFALoginDTO) (*domain.AuthResponse, error) {
+	// Validate DTO
+	if err := s.validator.Struct(dto); err != nil {
+		return nil, ports.ErrInvalidInput
+	}
+	if dto.Code == "" && dto.RecoveryCode == "" {
+		return nil, ports.ErrInvalidInput
+	}
+
+	// Get challenge
+	challenge, err := s.getValidMFAChallenge(ctx, dto.MFAToken)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get user
+	user, err := s.repo.GetUserByID(ctx, challenge.UserID)
+	if err != nil {
+		return nil, ports.ErrUserNotFound
+	}
+
+	// Get enrollment (may be pending when the tenant policy forced enrollment during login)
+	mfa, err := s.repo.GetUserMFA(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only challenges issued to enroll the first second factor accept a pending enrollment
+	if !mfa.Enabled && !challenge.EnrollmentRequired {
+		return nil, ports.ErrMFANotEnrolled
+	}
+
+	// Verify second factor
+	if dto.RecoveryCode != "" {
+		if !mfa.Enabled {
+			err = ports.ErrMFARecoveryCodeInvalid
+		} else {
+			err = s.repo.UseRecoveryCode(ctx, user.ID, hashRecoveryCode(dto.RecoveryCode))
+		}
+	} else {
+		err = s.verifyTOTPCode(ctx, mfa, dto.Code)
+	}
+	if err != nil {
+		if errors.Is(err, ports.ErrMFACodeInvalid) || errors.Is(err, ports.ErrMFARecoveryCodeInvalid) {
+			if incErr := s.repo.IncrementMFAChallengeAttempts(ctx, challenge.ID); incErr != nil {
+				fmt.Printf("Warning: failed to record MFA attempt: %v\n", incErr)
+			}
+		}
+		return nil, err
+	}
+
+	// First valid code of an enrollment started during login activates MFA
+	var recoveryCodes []string
+	if !mfa.Enabled {
+		recoveryCodes, err = s.activateMFA(ctx, user.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Challenges are single-use
+	if err := s.repo.DeleteMFAChallenge(ctx, challenge.ID); err != nil {
+		fmt.Printf("Warning: failed to delete MFA challenge: %v\n", err)
+	}
+
+	response, err := s.issueTokens(ctx, user, dto.SessionMetadata)
+	if err != nil {
+		return nil, err
+	}
+	response.RecoveryCodes = recoveryCodes
+
+	return response, nil
+}
+
+// EnrollMFAWithChallenge starts a TOTP enrollment using a login MFA challenge. Only challenges
+// issued because the tenant policy requires MFA from a user without a second factor can enroll;
+// otherwise the password alone would be enough to set up a new factor.
+func (s *AuthServiceImpl) EnrollMFAWithChallenge(ctx context.Context, dto *domain.MFAChallengeDTO) (*domain.MFAEnrollmentResponse, error) {
+	// Validate DTO
+	if err := s.validator.Struct(dto); err != nil {
+		return nil, ports.ErrInvalidInput
+	}
+
+	// Get challenge
+	challenge, err := s.getValidMFAChallenge(ctx, dto.MFAToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if !challenge.EnrollmentRequired {
+		return nil, ports.ErrMFAChallengeInvalid
+	}
+
+	// Get user
+	user, err := s.repo.GetUserByID(ctx, challenge.UserID)
+	if err != nil {
+		return nil, ports.ErrUserNotFound
+	}
+
+	// The user may have registered a passkey since the challenge was issued
+	passkeys, err := s.repo.CountWebAuthnCredentials(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if passkeys > 0 {
+		return nil, ports.ErrMFAChallengeInvalid
+	}
+
+	return s.startMFAEnrollment(ctx, user)
+}
+
+// GetMFAStatus returns the MFA status of the authenticated user
+func (s *AuthServiceImpl) GetMFAStatus(ctx context.Context, userID string, tenantID string) (*domain.MFAStatusResponse, error) {
+	// Get user
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, ports.ErrUserNotFound
+	}
+
+	required, err := s.isMFARequired(ctx, user, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	passkeys, err := s.repo.CountWebAuthnCredentials(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &domain.MFAStatusResponse{Required: required, Passkeys: passkeys}
+
+	mfa, err := s.repo.GetUserMFA(ctx, userID)
+	if err != nil {
+		if errors.Is(err, ports.ErrMFANotEnrolled) {
+			return status, nil
+		}
+		return nil, fmt.Errorf("failed to get MFA enrollment: %w", err)
+	}
+
+	if mfa.Enabled {
+		remaining, err := s.repo.CountUnusedRecoveryCodes(ctx, userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count recovery codes: %w", err)
+		}
+		status.Enabled = true
+		status.EnabledAt = mfa.EnabledAt
+		status.RecoveryCodesRemaining = remaining
+	}
+
+	return status, nil
+}
+
+// EnrollMFA generates a new TOTP secret and otpauth URI for the user
+func (s *AuthServiceImpl) EnrollMFA(ctx context.Context, userID string) (*domain.MFAEnrollmentResponse, error) {
+	// Get user
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, ports.ErrUserNotFound
+	}
+
+	return s.startMFAEnrollment(ctx, user)
+}
+
+// EnableMFA activates a pending enrollment after verifying a code from the authenticator app
+func (s *AuthServiceImpl) EnableMFA(ctx context.Context, userID string, dto *domain.MFACodeDTO) (*domain.MFARecoveryCodesResponse, error) {
+	// Validate DTO
+	if err := s.validator.Struct(dto); err != nil {
+		return nil, ports.ErrInvalidInput
+	}
+
+	mfa, err := s.repo.GetUserMFA(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if mfa.Enabled {
+		return nil, ports.ErrMFAAlreadyEnabled
+	}
+
+	if err := s.verifyTOTPCode(ctx, mfa, dto.Code); err != nil {
+		return nil, err
+	}
+
+	recoveryCodes, err := s.activateMFA(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.MFARecoveryCodesResponse{RecoveryCodes: recoveryCodes}, nil
+}
+
+// DisableMFA removes the user's TOTP enrollment after verifying a current code
+func (s *AuthServiceImpl) DisableMFA(ctx context.Context, userID string, dto *domain.MFACodeDTO) error {
+	// Validate DTO
+	if err := s.validator.Struct(dto); err != nil {
+		return ports.ErrInvalidInput
+	}
+
+	mfa, err := s.getEnabledMFA(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.verifyTOTPCode(ctx, mfa, dto.Code); err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteUserMFA(ctx, userID); err != nil {
+		return fmt.Errorf("failed to disable MFA: %w", err)
+	}
+
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces the user's recovery codes after verifying a current code
+func (s *AuthServiceImpl) RegenerateRecoveryCodes(ctx context.Context, userID string, dto *domain.MFACodeDTO) (*domain.MFARecoveryCodesResponse, error) {
+	// Validate DTO
+	if err := s.validator.Struct(dto); err != nil {
+		return nil, ports.ErrInvalidInput
+	}
+
+	mfa, err := s.getEnabledMFA(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.verifyTOTPCode(ctx, mfa, dto.Code); err != nil {
+		return nil, err
+	}
+
+	recoveryCodes, err := s.generateRecoveryCodes(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.MFARecoveryCodesResponse{RecoveryCodes: recoveryCodes}, nil
+}
+
+// GetMFAPolicy returns the tenant roles that must use MFA
+func (s *AuthServiceImpl) GetMFAPolicy(ctx context.Context, tenantID string) (*domain.MFAPolicyDTO, error) {
+	roles, err := s.repo.GetTenantMFARequiredRoles(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	if roles == nil {
+		roles = []string{}
+	}
+
+	return &domain.MFAPolicyDTO{RequiredRoles: roles}, nil
+}
+
+// UpdateMFAPolicy updates the tenant roles that must use MFA
+func (s *AuthServiceImpl) UpdateMFAPolicy(ctx context.Context, tenantID string, dto *domain.MFAPolicyDTO) (*domain.MFAPolicyDTO, error) {
+	// Validate DTO
+	if err := s.validator.Struct(dto); err != nil {
+		return nil, ports.ErrInvalidInput
+	}
+
+	// Remove duplicates while keeping order
+	roles := make([]string, 0, len(dto.RequiredRoles))
+	seen := make(map[string]bool)
+	for _, role := range dto.RequiredRoles {
+		if !seen[role] {
+			seen[role] = true
+			roles = append(roles, role)
+		}
+	}
+
+	if err := s.repo.UpdateTenantMFARequiredRoles(ctx, tenantID, roles); err != nil {
+		return nil, err
+	}
+
+	return &domain.MFAPolicyDTO{RequiredRoles: roles}, nil
+}
+
+// MFA helper methods
+
+// beginMFAChallenge returns an MFA challenge response when the user must provide
+// a second factor, or nil when the login can be completed with the password alone
+func (s *AuthServiceImpl) beginMFAChallenge(ctx context.Context, tenantID string, user *domain.User) (*domain.AuthResponse, error) {
+	var methods []string
+	if _, err := s.getEnabledMFA(ctx, user.ID); err == nil {
+		methods = append(methods, "totp")
+	} else if !errors.Is(err, ports.ErrMFANotEnrolled) {
+		return nil, err
+	}
+
+	// Registered passkeys and security keys also count as a second factor
+	passkeys, err := s.repo.CountWebAuthnCredentials(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if passkeys > 0 {
+		methods = append(methods, "webauthn")
+	}
+
+	enabled := len(methods) > 0
+
+	if !enabled {
+		required, err := s.isMFARequired(ctx, user, tenantID)
+		if err != nil {
+			return nil, err
+		}
+		if !required {
+			return nil, nil
+		}
+	}
+
+	// Only the hash is stored; the token is returned to the client once
+	token := s.generateSecureToken()
+	challenge := &domain.MFAChallenge{
+		ID:                 uuid.New().String(),
+		UserID:             user.ID,
+		TokenHash:          domain.HashAPIToken(token),
+		Attempts:           0,
+		EnrollmentRequired: !enabled,
+		ExpiresAt:          time.Now().Add(s.mfaChallengeExpiry),
+		CreatedAt:          time.Now(),
+	}
+
+	if err := s.repo.CreateMFAChallenge(ctx, challenge); err != nil {
+		return nil, fmt.Errorf("failed to create MFA challenge: %w", err)
+	}
+
+	return &domain.AuthResponse{
+		MFARequired:           true,
+		MFAEnrollmentRequired: !enabled,
+		MFAToken:              token,
+		MFAMethods:            methods,
+	}, nil
+}
+
+// isMFARequired checks whether the tenant policy requires MFA for the user's role.
+// The membership role in the tenant takes precedence over the user's global roles.
+func (s *AuthServiceImpl) isMFARequired(ctx context.Context, user *domain.User, tenantID string) (bool, error) {
+	tenantID, err := s.resolveUserTenant(ctx, user, tenantID)
+	if err != nil || tenantID == "" {
+		return false, err
+	}
+
+	requiredRoles, err := s.repo.GetTenantMFARequiredRoles(ctx, tenantID)
+	if err != nil {
+		if errors.Is(err, ports.ErrTenantNotFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get tenant MFA policy: %w", err)
+	}
+
+	if len(requiredRoles) == 0 {
+		return false, nil
+	}
+
+	membership, err := s.repo.GetActiveMembership(ctx, user.ID, tenantID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get membership: %w", err)
+	}
+
+	for _, role := range requiredRoles {
+		if membership != nil {
+			if membership.Role == role {
+				return true, nil
+			}
+		} else if user.HasRoleInList(domain.UserRole(role)) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// resolveUserTenant determines the tenant a login applies to: the requested tenant,
+// the user's own tenant, or their most recent active membership
+func (s *AuthServiceImpl) resolveUserTenant(ctx context.Context, user *domain.User, tenantID string) (string, error) {
+	if tenantID != "" {
+		return tenantID, nil
+	}
+
+	if user.TenantID != nil {
+		return *user.TenantID, nil
+	}
+
+	membership, err := s.repo.GetFirstActiveMembership(ctx, user.ID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get membership: %w", err)
+	}
+	if membership == nil {
+		return "", nil
+	}
+
+	return membership.TenantID, nil
+}
+
+// getValidMFAChallenge retrieves a challenge and checks it can still be used
+func (s *AuthServiceImpl) getValidMFAChallenge(ctx context.Context, token string) (*domain.MFAChallenge, error) {
+	challenge, err := s.repo.GetMFAChallenge(ctx, domain.HashAPIToken(token))
+	if err != nil {
+		if errors.Is(err, ports.ErrTokenNotFound) {
+			return nil, ports.ErrMFAChallengeInvalid
+		}
+		return nil, fmt.Errorf("failed to get MFA challenge: %w", err)
+	}
+
+	if challenge.IsExpired() {
+		_ = s.repo.DeleteMFAChallenge(ctx, challenge.ID)
+		return nil, ports.ErrMFAChallengeExpired
+	}
+
+	if challenge.Attempts >= mfaMaxChallengeAttempts {
+		_ = s.repo.DeleteMFAChallenge(ctx, challenge.ID)
+		return nil, ports.ErrMFAChallengeInvalid
+	}
+
+	return challenge, nil
+}
+
+// getEnabledMFA retrieves the user's enrollment, returning ErrMFANotEnrolled unless it's active
+func (s *AuthServiceImpl) getEnabledMFA(ctx context.Context, userID string) (*domain.UserMFA, error) {
+	mfa, err := s.repo.GetUserMFA(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !mfa.Enabled {
+		return nil, ports.ErrMFANotEnrolled
+	}
+
+	return mfa, nil
+}
+
+// startMFAEnrollment stores a new pending TOTP secret for the user
+func (s *AuthServiceImpl) startMFAEnrollment(ctx context.Context, user *domain.User) (*domain.MFAEnrollmentResponse, error) {
+	if _, err := s.getEnabledMFA(ctx, user.ID); err == nil {
+		return nil, ports.ErrMFAAlreadyEnabled
+	} else if !errors.Is(err, ports.ErrMFANotEnrolled) {
+		return nil, err
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate MFA secret: %w", err)
+	}
+
+	mfa := &domain.UserMFA{
+		UserID:       user.ID,
+		Secret:       secret,
+		Enabled:      false,
+		LastUsedStep: 0,
+		EnabledAt:    nil,
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
+	}
+
+	if err := s.repo.SaveUserMFA(ctx, mfa); err != nil {
+		return nil, fmt.Errorf("failed to save MFA enrollment: %w", err)
+	}
+
+	return &domain.MFAEnrollmentResponse{
+		Secret:     secret,
+		OTPAuthURI: totp.ProvisioningURI(secret, s.mfaIssuer, user.Email),
+	}, nil
+}
+
+// activateMFA enables a pending enrollment and generates its recovery codes
+func (s *AuthServiceImpl) activateMFA(ctx context.Context, userID string) ([]string, error) {
+	if err := s.repo.EnableUserMFA(ctx, userID); err != nil {
+		return nil, fmt.Errorf("failed to enable MFA: %w", err)
+	}
+
+	return s.generateRecoveryCodes(ctx, userID)
+}
+
+// verifyTOTPCode validates a TOTP code and records its time step so it can't be replayed
+func (s *AuthServiceImpl) verifyTOTPCode(ctx context.Context, mfa *domain.UserMFA, code string) error {
+	step, ok := totp.Validate(mfa.Secret, code, time.Now(), totp.DefaultSkew)
+	if !ok || step <= mfa.LastUsedStep {
+		return ports.ErrMFACodeInvalid
+	}
+
+	if err := s.repo.UpdateMFALastUsedStep(ctx, mfa.UserID, step); err != nil {
+		return err
+	}
+	mfa.LastUsedStep = step
+
+	return nil
+}
+
+// generateRecoveryCodes replaces the user's recovery codes with a new random set
+// Only hashes are stored; the plain codes are shown to the user once
+func (s *AuthServiceImpl) generateRecoveryCodes(ctx context.Context, userID string) ([]string, error) {
+	codes := make([]string, 0, mfaRecoveryCodeCount)
+	hashes := make([]string, 0, mfaRecoveryCodeCount)
+
+	for i := 0; i < mfaRecoveryCodeCount; i++ {
+		bytes := make([]byte, 5)
+		if _, err := rand.Read(bytes); err != nil {
+			return nil, fmt.Errorf("failed to generate recovery code: %w", err)
+		}
+		raw := hex.EncodeToString(bytes)
+		code := raw[:5] + "-" + raw[5:]
+
+		codes = append(codes, code)
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+
+	if err := s.repo.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, fmt.Errorf("failed to store recovery codes: %w", err)
+	}
+
+	return codes, nil
+}
+
+// hashRecoveryCode normalizes and hashes a recovery code for storage and lookup
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
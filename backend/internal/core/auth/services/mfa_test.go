@@ -0,0 +1,289 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/auth/domain"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/auth/ports"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/shared/totp"
+)
+
+// enrollTestMFA stores an enabled TOTP enrollment for the user and returns its secret
+func enrollTestMFA(t *testing.T, repo *stubAuthRepository, userID string) string {
+	t.Helper()
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo.mfa[userID] = &domain.UserMFA{UserID: userID, Secret: secret, Enabled: true}
+	return secret
+}
+
+// currentCode returns the TOTP code of secret for now
+func currentCode(t *testing.T, secret string) string {
+	t.Helper()
+	code, err := totp.GenerateCode(secret, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code
+}
+
+func login(t *testing.T, service *AuthServiceImpl) *domain.AuthResponse {
+	t.Helper()
+	response, err := service.Login(context.Background(), authTenantID, &domain.LoginDTO{Email: authEmail, Password: authPassword})
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+	return response
+}
+
+func TestLoginWithoutMFA(t *testing.T) {
+	repo := newStubAuthRepository(newTestUser())
+	service := newTestAuthService(t, repo)
+
+	response := login(t, service)
+	if response.MFARequired || response.AccessToken == "" || response.RefreshToken == "" {
+		t.Errorf("Expected tokens without MFA, got %+v", response)
+	}
+	if len(repo.challenges) != 0 {
+		t.Errorf("Expected no MFA challenge, got %d", len(repo.challenges))
+	}
+}
+
+func TestMFALoginChallenge(t *testing.T) {
+	ctx := context.Background()
+	repo := newStubAuthRepository(newTestUser())
+	secret := enrollTestMFA(t, repo, authUserID)
+	service := newTestAuthService(t, repo)
+
+	response := login(t, service)
+	if !response.MFARequired || response.MFAEnrollmentRequired || response.AccessToken != "" {
+		t.Fatalf("Expected an MFA challenge without tokens, got %+v", response)
+	}
+	if len(response.MFAMethods) != 1 || response.MFAMethods[0] != "totp" {
+		t.Errorf("Expected the totp method, got %v", response.MFAMethods)
+	}
+
+	// Only the hash of the token is stored
+	if len(repo.challenges) != 1 {
+		t.Fatalf("Expected one MFA challenge, got %d", len(repo.challenges))
+	}
+	for _, challenge := range repo.challenges {
+		if challenge.TokenHash == response.MFAToken || challenge.TokenHash != domain.HashAPIToken(response.MFAToken) {
+			t.Errorf("Expected the challenge to store the token hash, got %q", challenge.TokenHash)
+		}
+	}
+
+	// A wrong code counts as an attempt
+	wrongCode := "000000"
+	if wrongCode == currentCode(t, secret) {
+		wrongCode = "111111"
+	}
+	_, err := service.VerifyMFALogin(ctx, &domain.MFALoginDTO{MFAToken: response.MFAToken, Code: wrongCode})
+	if !errors.Is(err, ports.ErrMFACodeInvalid) {
+		t.Errorf("Expected ErrMFACodeInvalid, got %v", err)
+	}
+	for _, challenge := range repo.challenges {
+		if challenge.Attempts != 1 {
+			t.Errorf("Expected 1 attempt, got %d", challenge.Attempts)
+		}
+	}
+
+	tokens, err := service.VerifyMFALogin(ctx, &domain.MFALoginDTO{MFAToken: response.MFAToken, Code: currentCode(t, secret)})
+	if err != nil {
+		t.Fatalf("VerifyMFALogin failed: %v", err)
+	}
+	if tokens.AccessToken == "" || tokens.RefreshToken == "" || tokens.RecoveryCodes != nil {
+		t.Errorf("Expected tokens without recovery codes, got %+v", tokens)
+	}
+
+	// Challenges are single-use
+	_, err = service.VerifyMFALogin(ctx, &domain.MFALoginDTO{MFAToken: response.MFAToken, Code: currentCode(t, secret)})
+	if !errors.Is(err, ports.ErrMFAChallengeInvalid) {
+		t.Errorf("Expected ErrMFAChallengeInvalid for a used challenge, got %v", err)
+	}
+}
+
+func TestMFALoginRejectsReplayedCode(t *testing.T) {
+	ctx := context.Background()
+	repo := newStubAuthRepository(newTestUser())
+	secret := enrollTestMFA(t, repo, authUserID)
+	service := newTestAuthService(t, repo)
+
+	code := currentCode(t, secret)
+	if _, err := service.VerifyMFALogin(ctx, &domain.MFALoginDTO{MFAToken: login(t, service).MFAToken, Code: code}); err != nil {
+		t.Fatalf("VerifyMFALogin failed: %v", err)
+	}
+
+	response := login(t, service)
+	if _, err := service.VerifyMFALogin(ctx, &domain.MFALoginDTO{MFAToken: response.MFAToken, Code: code}); !errors.Is(err, ports.ErrMFACodeInvalid) {
+		t.Errorf("Expected ErrMFACodeInvalid for a replayed code, got %v", err)
+	}
+	for _, challenge := range repo.challenges {
+		if challenge.Attempts != 1 {
+			t.Errorf("Expected the replayed code to count as an attempt, got %d", challenge.Attempts)
+		}
+	}
+}
+
+func TestMFALoginWithRecoveryCode(t *testing.T) {
+	ctx := context.Background()
+	repo := newStubAuthRepository(newTestUser())
+	enrollTestMFA(t, repo, authUserID)
+	service := newTestAuthService(t, repo)
+
+	codes, err := service.generateRecoveryCodes(ctx, authUserID)
+	if err != nil {
+		t.Fatalf("generateRecoveryCodes failed: %v", err)
+	}
+
+	// Recovery codes are accepted without the dash, but only once
+	if _, err := service.VerifyMFALogin(ctx, &domain.MFALoginDTO{MFAToken: login(t, service).MFAToken, RecoveryCode: strings.ReplaceAll(codes[0], "-", "")}); err != nil {
+		t.Fatalf("VerifyMFALogin failed: %v", err)
+	}
+	_, err = service.VerifyMFALogin(ctx, &domain.MFALoginDTO{MFAToken: login(t, service).MFAToken, RecoveryCode: codes[0]})
+	if !errors.Is(err, ports.ErrMFARecoveryCodeInvalid) {
+		t.Errorf("Expected ErrMFARecoveryCodeInvalid for a used recovery code, got %v", err)
+	}
+	if len(repo.recoveryCodes[authUserID]) != mfaRecoveryCodeCount-1 {
+		t.Errorf("Expected %d recovery codes left, got %d", mfaRecoveryCodeCount-1, len(repo.recoveryCodes[authUserID]))
+	}
+}
+
+func TestMFAChallengeLimits(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Expired challenge", func(t *testing.T) {
+		repo := newStubAuthRepository(newTestUser())
+		secret := enrollTestMFA(t, repo, authUserID)
+		service := newTestAuthService(t, repo)
+
+		response := login(t, service)
+		for _, challenge := range repo.challenges {
+			challenge.ExpiresAt = time.Now().Add(-time.Second)
+		}
+
+		_, err := service.VerifyMFALogin(ctx, &domain.MFALoginDTO{MFAToken: response.MFAToken, Code: currentCode(t, secret)})
+		if !errors.Is(err, ports.ErrMFAChallengeExpired) {
+			t.Errorf("Expected ErrMFAChallengeExpired, got %v", err)
+		}
+		if len(repo.challenges) != 0 {
+			t.Error("Expected the expired challenge to be deleted")
+		}
+	})
+
+	t.Run("Too many attempts", func(t *testing.T) {
+		repo := newStubAuthRepository(newTestUser())
+		secret := enrollTestMFA(t, repo, authUserID)
+		service := newTestAuthService(t, repo)
+
+		response := login(t, service)
+		for _, challenge := range repo.challenges {
+			challenge.Attempts = mfaMaxChallengeAttempts
+		}
+
+		_, err := service.VerifyMFALogin(ctx, &domain.MFALoginDTO{MFAToken: response.MFAToken, Code: currentCode(t, secret)})
+		if !errors.Is(err, ports.ErrMFAChallengeInvalid) {
+			t.Errorf("Expected ErrMFAChallengeInvalid, got %v", err)
+		}
+	})
+
+	t.Run("Unknown token", func(t *testing.T) {
+		service := newTestAuthService(t, newStubAuthRepository(newTestUser()))
+		_, err := service.VerifyMFALogin(ctx, &domain.MFALoginDTO{MFAToken: "unknown", Code: "123456"})
+		if !errors.Is(err, ports.ErrMFAChallengeInvalid) {
+			t.Errorf("Expected ErrMFAChallengeInvalid, got %v", err)
+		}
+	})
+}
+
+func TestMFAEnrollmentRequiredByPolicy(t *testing.T) {
+	ctx := context.Background()
+	repo := newStubAuthRepository(newTestUser())
+	repo.mfaRoles = []string{"admin"}
+	service := newTestAuthService(t, repo)
+
+	// The membership role decides, not the global role
+	if response := login(t, service); response.MFARequired {
+		t.Fatalf("Expected no MFA for a student, got %+v", response)
+	}
+	repo.memberships[authUserID] = &domain.UserMembership{TenantID: authTenantID, Role: "admin", Status: "active"}
+
+	response := login(t, service)
+	if !response.MFARequired || !response.MFAEnrollmentRequired || len(response.MFAMethods) != 0 {
+		t.Fatalf("Expected a required enrollment, got %+v", response)
+	}
+
+	enrollment, err := service.EnrollMFAWithChallenge(ctx, &domain.MFAChallengeDTO{MFAToken: response.MFAToken})
+	if err != nil {
+		t.Fatalf("EnrollMFAWithChallenge failed: %v", err)
+	}
+
+	tokens, err := service.VerifyMFALogin(ctx, &domain.MFALoginDTO{MFAToken: response.MFAToken, Code: currentCode(t, enrollment.Secret)})
+	if err != nil {
+		t.Fatalf("VerifyMFALogin failed: %v", err)
+	}
+	if tokens.AccessToken == "" || len(tokens.RecoveryCodes) != mfaRecoveryCodeCount {
+		t.Errorf("Expected tokens and %d recovery codes, got %+v", mfaRecoveryCodeCount, tokens)
+	}
+	if !repo.mfa[authUserID].Enabled {
+		t.Error("Expected the enrollment to be enabled")
+	}
+}
+
+func TestMFAEnrollmentWithPasskeyChallenge(t *testing.T) {
+	ctx := context.Background()
+	repo := newStubAuthRepository(newTestUser())
+	repo.passkeys = map[string]int{authUserID: 1}
+	service := newTestAuthService(t, repo)
+
+	// The password alone returns a challenge that must be completed with the passkey
+	response := login(t, service)
+	if !response.MFARequired || response.MFAEnrollmentRequired || len(response.MFAMethods) != 1 || response.MFAMethods[0] != "webauthn" {
+		t.Fatalf("Expected a passkey challenge, got %+v", response)
+	}
+
+	if _, err := service.EnrollMFAWithChallenge(ctx, &domain.MFAChallengeDTO{MFAToken: response.MFAToken}); !errors.Is(err, ports.ErrMFAChallengeInvalid) {
+		t.Fatalf("Expected ErrMFAChallengeInvalid, got %v", err)
+	}
+	if _, ok := repo.mfa[authUserID]; ok {
+		t.Fatal("Expected no TOTP enrollment to be started")
+	}
+
+	// A pending enrollment started from the account settings doesn't complete the challenge either
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo.mfa[authUserID] = &domain.UserMFA{UserID: authUserID, Secret: secret}
+	_, err = service.VerifyMFALogin(ctx, &domain.MFALoginDTO{MFAToken: response.MFAToken, Code: currentCode(t, secret)})
+	if !errors.Is(err, ports.ErrMFANotEnrolled) {
+		t.Errorf("Expected ErrMFANotEnrolled, got %v", err)
+	}
+	if repo.mfa[authUserID].Enabled {
+		t.Error("Expected the pending enrollment to stay disabled")
+	}
+}
+
+func TestMFAEnrollmentRequiredAfterPasskeyRegistered(t *testing.T) {
+	ctx := context.Background()
+	repo := newStubAuthRepository(newTestUser())
+	repo.mfaRoles = []string{"admin"}
+	repo.memberships[authUserID] = &domain.UserMembership{TenantID: authTenantID, Role: "admin", Status: "active"}
+	service := newTestAuthService(t, repo)
+
+	response := login(t, service)
+	if !response.MFAEnrollmentRequired {
+		t.Fatalf("Expected a required enrollment, got %+v", response)
+	}
+
+	repo.passkeys = map[string]int{authUserID: 1}
+	if _, err := service.EnrollMFAWithChallenge(ctx, &domain.MFAChallengeDTO{MFAToken: response.MFAToken}); !errors.Is(err, ports.ErrMFAChallengeInvalid) {
+		t.Errorf("Expected ErrMFAChallengeInvalid, got %v", err)
+	}
+}
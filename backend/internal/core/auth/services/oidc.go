@@ -0,0 +1,227 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/auth/domain"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/auth/ports"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/shared/oidc"
+	"github.com/google/uuid"
+)
+
+// defaultOIDCScopes are requested when the tenant doesn't configure scopes
+var defaultOIDCScopes = []string{"openid", "email", "profile"}
+
+// BeginOIDCLogin starts a login with the tenant's identity provider
+func (s *AuthServiceImpl) BeginOIDCLogin(ctx context.Context, tenantID string) (*domain.OIDCAuthorizeResponse, error) {
+	if tenantID == "" {
+		return nil, ports.ErrInvalidInput
+	}
+
+	provider, err := s.getEnabledOIDCProvider(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	// state binds the callback to this request, nonce binds the ID token,
+	// and the PKCE verifier binds the code exchange
+	values := make([]string, 3)
+	for i := range values {
+		if values[i], err = oidc.GenerateRandomString(); err != nil {
+			return nil, err
+		}
+	}
+	state, nonce, codeVerifier := values[0], values[1], values[2]
+
+	authURL, err := s.oidcClient.AuthCodeURL(ctx, oidcConfig(provider), state, nonce, codeVerifier)
+	if err != nil {
+		fmt.Printf("Warning: failed to build OIDC authorization URL for tenant %s: %v\n", tenantID, err)
+		return nil, ports.ErrServiceUnavailable
+	}
+
+	now := time.Now()
+	request := &domain.OIDCAuthRequest{
+		ID:           uuid.New().String(),
+		TenantID:     tenantID,
+		State:        state,
+		Nonce:        nonce,
+		CodeVerifier: codeVerifier,
+		ExpiresAt:    now.Add(s.oidcRequestExpiry),
+		CreatedAt:    now,
+	}
+
+	if err := s.repo.CreateOIDCAuthRequest(ctx, request); err != nil {
+		return nil, fmt.Errorf("failed to create OIDC authorization request: %w", err)
+	}
+
+	return &domain.OIDCAuthorizeResponse{
+		AuthorizationURL: authURL,
+		State:            state,
+	}, nil
+}
+
+// CompleteOIDCLogin handles the identity provider callback and issues our tokens.
+// MFA is left to the identity provider, so no local MFA challenge is started.
+func (s *AuthServiceImpl) CompleteOIDCLogin(ctx context.Context, dto *domain.OIDCCallbackDTO) (*domain.AuthResponse, error) {
+	// Validate DTO
+	if err := s.validator.Struct(dto); err != nil {
+		return nil, ports.ErrInvalidInput
+	}
+
+	// Authorization requests are single-use
+	request, err := s.repo.ConsumeOIDCAuthRequest(ctx, dto.State)
+	if err != nil {
+		return nil, err
+	}
+	if request.IsExpired() {
+		return nil, ports.ErrOIDCStateInvalid
+	}
+
+	provider, err := s.getEnabledOIDCProvider(ctx, request.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	cfg := oidcConfig(provider)
+
+	token, err := s.oidcClient.Exchange(ctx, cfg, dto.Code, request.CodeVerifier)
+	if err != nil {
+		fmt.Printf("Warning: OIDC code exchange failed for tenant %s: %v\n", request.TenantID, err)
+		return nil, ports.ErrOIDCAuthenticationFailed
+	}
+
+	idToken, err := s.oidcClient.VerifyIDToken(ctx, cfg, token.IDToken, request.Nonce)
+	if err != nil {
+		fmt.Printf("Warning: OIDC ID token rejected for tenant %s: %v\n", request.TenantID, err)
+		return nil, ports.ErrOIDCAuthenticationFailed
+	}
+
+	role, mapped := mapOIDCRole(provider, idToken.Claims)
+
+	user, link, err := s.federatedLogin(ctx, &federatedIdentity{
+		protocol:      "OIDC",
+		tenantID:      provider.TenantID,
+		issuer:        provider.Issuer,
+		subject:       idToken.Subject,
+		email:         idToken.Email,
+		emailVerified: idToken.EmailVerified,
+		fullName:      idToken.Name,
+	}, role, mapped)
+	if err != nil {
+		return nil, err
+	}
+	if link != nil {
+		return link, nil
+	}
+
+	return s.issueTokens(ctx, user, dto.SessionMetadata)
+}
+
+// GetOIDCProvider returns the tenant's identity provider configuration
+func (s *AuthServiceImpl) GetOIDCProvider(ctx context.Context, tenantID string) (*domain.OIDCProvider, error) {
+	return s.repo.GetOIDCProvider(ctx, tenantID)
+}
+
+// UpdateOIDCProvider creates or updates the tenant's identity provider configuration
+func (s *AuthServiceImpl) UpdateOIDCProvider(ctx context.Context, tenantID string, dto *domain.OIDCProviderDTO) (*domain.OIDCProvider, error) {
+	// Validate DTO
+	if err := s.validator.Struct(dto); err != nil {
+		return nil, ports.ErrInvalidInput
+	}
+
+	now := time.Now()
+	provider, err := s.repo.GetOIDCProvider(ctx, tenantID)
+	if err != nil {
+		if !errors.Is(err, ports.ErrOIDCNotConfigured) {
+			return nil, err
+		}
+		provider = &domain.OIDCProvider{
+			TenantID:  tenantID,
+			CreatedAt: now,
+		}
+	}
+
+	provider.Issuer = strings.TrimSuffix(dto.Issuer, "/")
+	provider.ClientID = dto.ClientID
+	if dto.ClientSecret != "" {
+		provider.ClientSecret = dto.ClientSecret
+	}
+	provider.RedirectURI = dto.RedirectURI
+	provider.Scopes = dto.Scopes
+	if len(provider.Scopes) == 0 {
+		provider.Scopes = defaultOIDCScopes
+	}
+	provider.RoleClaim = dto.RoleClaim
+	provider.RoleMapping = dto.RoleMapping
+	provider.DefaultRole = dto.DefaultRole
+	if provider.DefaultRole == "" {
+		provider.DefaultRole = string(domain.RoleStudent)
+	}
+	provider.Enabled = dto.Enabled
+	provider.UpdatedAt = now
+
+	if err := s.repo.SaveOIDCProvider(ctx, provider); err != nil {
+		return nil, fmt.Errorf("failed to save OIDC provider: %w", err)
+	}
+
+	return provider, nil
+}
+
+// DeleteOIDCProvider removes the tenant's identity provider configuration
+func (s *AuthServiceImpl) DeleteOIDCProvider(ctx context.Context, tenantID string) error {
+	return s.repo.DeleteOIDCProvider(ctx, tenantID)
+}
+
+// Helper methods
+
+// getEnabledOIDCProvider retrieves the tenant provider, treating a disabled one as not configured
+func (s *AuthServiceImpl) getEnabledOIDCProvider(ctx context.Context, tenantID string) (*domain.OIDCProvider, error) {
+	provider, err := s.repo.GetOIDCProvider(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !provider.Enabled {
+		return nil, ports.ErrOIDCNotConfigured
+	}
+
+	return provider, nil
+}
+
+// oidcConfig converts a tenant provider into the client configuration
+func oidcConfig(provider *domain.OIDCProvider) oidc.Config {
+	return oidc.Config{
+		Issuer:       provider.Issuer,
+		ClientID:     provider.ClientID,
+		ClientSecret: provider.ClientSecret,
+		RedirectURL:  provider.RedirectURI,
+		Scopes:       provider.Scopes,
+	}
+}
+
+// mapOIDCRole resolves the membership role from the configured claim,
+// which may be a single value or a list (e.g. groups)
+func mapOIDCRole(provider *domain.OIDCProvider, claims map[string]interface{}) (string, bool) {
+	if provider.RoleClaim == "" || len(provider.RoleMapping) == 0 {
+		return mapFederatedRole(nil, nil, provider.DefaultRole)
+	}
+
+	var values []string
+	switch claim := claims[provider.RoleClaim].(type) {
+	case string:
+		values = []string{claim}
+	case []interface{}:
+		for _, value := range claim {
+			if str, ok := value.(string); ok {
+				values = append(values, str)
+			}
+		}
+	case []string:
+		values = claim
+	}
+
+	return mapFederatedRole(values, provider.RoleMapping, provider.DefaultRole)
+}
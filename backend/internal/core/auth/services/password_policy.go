@@ -0,0 +1,193 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/auth/domain"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/auth/ports"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/shared/breach"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/shared/hasher"
+)
+
+// PasswordPolicyServiceImpl implements the PasswordPolicyService interface
+type PasswordPolicyServiceImpl struct {
+	repo    ports.AuthRepository
+	hasher  hasher.PasswordHasher
+	checker breach.Checker // nil disables the breached password check
+}
+
+// NewPasswordPolicyService creates a new instance of PasswordPolicyService
+func NewPasswordPolicyService(
+	repo ports.AuthRepository,
+	hasher hasher.PasswordHasher,
+	checker breach.Checker,
+) ports.PasswordPolicyService {
+	return &PasswordPolicyServiceImpl{
+		repo:    repo,
+		hasher:  hasher,
+		checker: checker,
+	}
+}
+
+// GetPolicy returns the password policy of the tenant, or the default policy
+func (s *PasswordPolicyServiceImpl) GetPolicy(ctx context.Context, tenantID string) (*domain.PasswordPolicy, error) {
+	if tenantID == "" {
+		return domain.DefaultPasswordPolicy(), nil
+	}
+
+	policy, err := s.repo.GetTenantPasswordPolicy(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get password policy: %w", err)
+	}
+	if policy == nil {
+		policy = domain.DefaultPasswordPolicy()
+		policy.TenantID = tenantID
+	}
+
+	return policy, nil
+}
+
+// ValidatePassword checks a new password against the tenant policy, the user's recent
+// passwords and the breached password dataset
+func (s *PasswordPolicyServiceImpl) ValidatePassword(ctx context.Context, tenantID string, userID string, password string) error {
+	policy, err := s.GetPolicy(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+
+	if violations := policy.Violations(password); len(violations) > 0 {
+		fmt.Printf("INFO: Password rejected by policy of tenant %s: %s\n", tenantID, strings.Join(violations, ", "))
+		return ports.ErrPasswordTooWeak
+	}
+
+	if userID != "" && policy.HistorySize > 0 {
+		hashes, err := s.repo.GetPasswordHistory(ctx, userID, policy.HistorySize)
+		if err != nil {
+			return fmt.Errorf("failed to get password history: %w", err)
+		}
+		for _, hash := range hashes {
+			if s.hasher.Compare(hash, password) == nil {
+				return ports.ErrPasswordReused
+			}
+		}
+	}
+
+	if policy.CheckBreached && s.checker != nil {
+		breached, err := s.checker.IsBreached(ctx, password)
+		if err != nil {
+			// Don't block password changes when the dataset is unavailable
+			fmt.Printf("Warning: failed to check breached passwords: %v\n", err)
+		} else if breached {
+			return ports.ErrPasswordBreached
+		}
+	}
+
+	return nil
+}
+
+// RecordPasswordChange stores the new password hash in the user's history
+func (s *PasswordPolicyServiceImpl) RecordPasswordChange(ctx context.Context, userID string, passwordHash string) error {
+	if err := s.repo.RecordPasswordChange(ctx, userID, passwordHash); err != nil {
+		return fmt.Errorf("failed to record password change: %w", err)
+	}
+	return nil
+}
+
+// IsPasswordExpired checks if the user's password exceeded the tenant maximum age
+func (s *PasswordPolicyServiceImpl) IsPasswordExpired(ctx context.Context, tenantID string, user *domain.User) (bool, error) {
+	policy, err := s.GetPolicy(ctx, tenantID)
+	if err != nil {
+		return false, err
+	}
+
+	return policy.IsPasswordExpired(user.PasswordChangedAt, time.Now()), nil
+}
+
+// GetPasswordPolicy returns the password policy of the tenant, or the default policy
+func (s *AuthServiceImpl) GetPasswordPolicy(ctx context.Context, tenantID string) (*domain.PasswordPolicy, error) {
+	return s.passwordPolicy.GetPolicy(ctx, tenantID)
+}
+
+// UpdatePasswordPolicy creates or replaces the password policy of the tenant.
+// The new requirements apply to the next password change; existing passwords only
+// become invalid through MaxAgeDays.
+func (s *AuthServiceImpl) UpdatePasswordPolicy(ctx context.Context, tenantID string, dto *domain.PasswordPolicyDTO) (*domain.PasswordPolicy, error) {
+	if err := s.validator.Struct(dto); err != nil {
+		return nil, ports.ErrInvalidInput
+	}
+
+	current, err := s.passwordPolicy.GetPolicy(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	policy := &domain.PasswordPolicy{
+		TenantID:         tenantID,
+		MinLength:        dto.MinLength,
+		RequireUppercase: dto.RequireUppercase,
+		RequireLowercase: dto.RequireLowercase,
+		RequireDigit:     dto.RequireDigit,
+		RequireSymbol:    dto.RequireSymbol,
+		HistorySize:      dto.HistorySize,
+		MaxAgeDays:       dto.MaxAgeDays,
+		CheckBreached:    dto.CheckBreached,
+		CreatedAt:        current.CreatedAt,
+		UpdatedAt:        now,
+	}
+	if policy.CreatedAt.IsZero() {
+		policy.CreatedAt = now
+	}
+
+	if err := s.repo.UpsertTenantPasswordPolicy(ctx, policy); err != nil {
+		return nil, fmt.Errorf("failed to update password policy: %w", err)
+	}
+
+	fmt.Printf("INFO: Updated password policy of tenant %s\n", tenantID)
+
+	return policy, nil
+}
+
+// recordPasswordChange adds the user's new password to the history. Failures are logged
+// since the password itself was already changed.
+func (s *AuthServiceImpl) recordPasswordChange(ctx context.Context, user *domain.User) {
+	if err := s.passwordPolicy.RecordPasswordChange(ctx, user.ID, user.PasswordHash); err != nil {
+		fmt.Printf("Warning: %v\n", err)
+		return
+	}
+
+	now := time.Now()
+	user.PasswordChangedAt = &now
+	user.MustChangePassword = false
+}
+
+// checkPasswordExpiry flags the user for a forced password change when the password
+// exceeded the maximum age of the tenant
+func (s *AuthServiceImpl) checkPasswordExpiry(ctx context.Context, tenantID string, user *domain.User) {
+	if user.MustChangePassword {
+		return
+	}
+	if tenantID == "" {
+		tenantID = stringPtrValue(user.TenantID)
+	}
+
+	expired, err := s.passwordPolicy.IsPasswordExpired(ctx, tenantID, user)
+	if err != nil {
+		fmt.Printf("Warning: failed to check password expiry of user %s: %v\n", user.ID, err)
+		return
+	}
+	if !expired {
+		return
+	}
+
+	if err := s.repo.SetMustChangePassword(ctx, user.ID, true); err != nil {
+		fmt.Printf("Warning: failed to force password change of user %s: %v\n", user.ID, err)
+		return
+	}
+	user.MustChangePassword = true
+
+	fmt.Printf("INFO: Password of user %s expired, forcing a password change\n", user.ID)
+}
@@ -0,0 +1,163 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/auth/domain"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/auth/ports"
+	"github.com/google/uuid"
+)
+
+// GetPermissionCatalog returns the permissions that can be granted to custom roles and
+// those granted by each built-in role
+func (s *AuthServiceImpl) GetPermissionCatalog(ctx context.Context) *domain.PermissionCatalogResponse {
+	builtInRoles := make(map[string][]domain.Permission)
+	for _, role := range []domain.UserRole{domain.RoleStudent, domain.RoleInstructor, domain.RoleAdmin} {
+		builtInRoles[string(role)] = domain.GetRolePermissions(role)
+	}
+
+	return &domain.PermissionCatalogResponse{
+		Permissions:  domain.PermissionCatalog,
+		BuiltInRoles: builtInRoles,
+	}
+}
+
+// ListTenantRoles lists the custom roles of the tenant
+func (s *AuthServiceImpl) ListTenantRoles(ctx context.Context, tenantID string) ([]*domain.TenantRole, error) {
+	return s.repo.ListTenantRoles(ctx, tenantID)
+}
+
+// CreateTenantRole defines a custom role in the tenant
+func (s *AuthServiceImpl) CreateTenantRole(ctx context.Context, tenantID string, dto *domain.TenantRoleDTO) (*domain.TenantRole, error) {
+	name, permissions, err := s.validateTenantRole(dto)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	role := &domain.TenantRole{
+		ID:          uuid.New().String(),
+		TenantID:    tenantID,
+		Name:        name,
+		Description: strings.TrimSpace(dto.Description),
+		Permissions: permissions,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if err := s.repo.CreateTenantRole(ctx, role); err != nil {
+		return nil, err
+	}
+
+	fmt.Printf("INFO: Role %q created in tenant %s with permissions %v\n", role.Name, tenantID, permissions)
+
+	return role, nil
+}
+
+// UpdateTenantRole replaces the settings of a custom role of the tenant
+func (s *AuthServiceImpl) UpdateTenantRole(ctx context.Context, tenantID string, roleID string, dto *domain.TenantRoleDTO) (*domain.TenantRole, error) {
+	name, permissions, err := s.validateTenantRole(dto)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := uuid.Parse(roleID); err != nil {
+		return nil, ports.ErrTenantRoleNotFound
+	}
+
+	role, err := s.repo.GetTenantRole(ctx, tenantID, roleID)
+	if err != nil {
+		return nil, err
+	}
+
+	role.Name = name
+	role.Description = strings.TrimSpace(dto.Description)
+	role.Permissions = permissions
+	role.UpdatedAt = time.Now()
+
+	if err := s.repo.UpdateTenantRole(ctx, role); err != nil {
+		return nil, err
+	}
+
+	fmt.Printf("INFO: Role %q updated in tenant %s with permissions %v\n", role.Name, tenantID, permissions)
+
+	return role, nil
+}
+
+// DeleteTenantRole removes a custom role of the tenant. Members holding it keep their built-in role.
+func (s *AuthServiceImpl) DeleteTenantRole(ctx context.Context, tenantID string, roleID string) error {
+	if _, err := uuid.Parse(roleID); err != nil {
+		return ports.ErrTenantRoleNotFound
+	}
+
+	return s.repo.DeleteTenantRole(ctx, tenantID, roleID)
+}
+
+// AssignTenantRole assigns a custom role to a member of the tenant, or removes it
+func (s *AuthServiceImpl) AssignTenantRole(ctx context.Context, tenantID string, userID string, dto *domain.AssignTenantRoleDTO) error {
+	// Validate DTO
+	if err := s.validator.Struct(dto); err != nil {
+		return ports.ErrInvalidInput
+	}
+
+	if _, err := uuid.Parse(userID); err != nil {
+		return ports.ErrUserNotFound
+	}
+
+	// Only roles of the same tenant can be assigned
+	var roleID *string
+	if dto.RoleID != nil && *dto.RoleID != "" {
+		role, err := s.repo.GetTenantRole(ctx, tenantID, *dto.RoleID)
+		if err != nil {
+			return err
+		}
+		roleID = &role.ID
+	}
+
+	if err := s.repo.SetMembershipCustomRole(ctx, userID, tenantID, roleID); err != nil {
+		return err
+	}
+
+	if roleID != nil {
+		fmt.Printf("INFO: Role %s assigned to user %s in tenant %s\n", *roleID, userID, tenantID)
+	} else {
+		fmt.Printf("INFO: Custom role removed from user %s in tenant %s\n", userID, tenantID)
+	}
+
+	return nil
+}
+
+// validateTenantRole checks a role definition and returns its trimmed name and its
+// permissions without duplicates
+func (s *AuthServiceImpl) validateTenantRole(dto *domain.TenantRoleDTO) (string, []string, error) {
+	if err := s.validator.Struct(dto); err != nil {
+		return "", nil, ports.ErrInvalidInput
+	}
+
+	name := strings.TrimSpace(dto.Name)
+	if name == "" {
+		return "", nil, ports.ErrInvalidInput
+	}
+
+	// Custom roles can't shadow the built-in roles
+	if domain.IsValidRole(strings.ToLower(name)) {
+		return "", nil, ports.ErrTenantRoleExists
+	}
+
+	permissions := make([]string, 0, len(dto.Permissions))
+	seen := make(map[string]bool)
+	for _, permission := range dto.Permissions {
+		if !domain.IsValidPermission(permission) {
+			return "", nil, ports.ErrPermissionInvalid
+		}
+		if !seen[permission] {
+			seen[permission] = true
+			permissions = append(permissions, permission)
+		}
+	}
+
+	return name, permissions, nil
+}
@@ -0,0 +1,349 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/auth/domain"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/auth/ports"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/shared/saml"
+	"github.com/google/uuid"
+)
+
+// samlLoginCodeExpiry is the time the frontend has to exchange a login code after the ACS redirect
+const samlLoginCodeExpiry = time.Minute
+
+// samlCallbackPath is the frontend route that receives the login code or error after the ACS redirect
+const samlCallbackPath = "/auth/saml/callback"
+
+// GetSAMLMetadata returns the service provider metadata of the tenant
+func (s *AuthServiceImpl) GetSAMLMetadata(ctx context.Context, tenantID string) ([]byte, error) {
+	if _, err := uuid.Parse(tenantID); err != nil {
+		return nil, ports.ErrSAMLNotConfigured
+	}
+
+	if _, err := s.repo.GetSAMLProvider(ctx, tenantID); err != nil {
+		return nil, err
+	}
+
+	sp := &saml.ServiceProvider{
+		EntityID: s.samlEntityID(tenantID),
+		ACSURL:   s.samlACSURL(tenantID),
+	}
+
+	return sp.Metadata()
+}
+
+// BeginSAMLLogin starts an SP-initiated login with the tenant's SAML identity provider
+func (s *AuthServiceImpl) BeginSAMLLogin(ctx context.Context, tenantID string) (*domain.SAMLAuthorizeResponse, error) {
+	if tenantID == "" {
+		return nil, ports.ErrInvalidInput
+	}
+
+	provider, err := s.getEnabledSAMLProvider(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	sp, err := s.samlServiceProvider(provider)
+	if err != nil {
+		return nil, err
+	}
+
+	requestID, err := saml.NewRequestID()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	authURL, err := sp.AuthnRequestURL(requestID, "", now)
+	if err != nil {
+		fmt.Printf("Warning: failed to build SAML request URL for tenant %s: %v\n", tenantID, err)
+		return nil, ports.ErrServiceUnavailable
+	}
+
+	request := &domain.SAMLAuthRequest{
+		ID:        requestID,
+		TenantID:  tenantID,
+		ExpiresAt: now.Add(s.samlRequestExpiry),
+		CreatedAt: now,
+	}
+
+	if err := s.repo.CreateSAMLAuthRequest(ctx, request); err != nil {
+		return nil, fmt.Errorf("failed to create SAML authentication request: %w", err)
+	}
+
+	return &domain.SAMLAuthorizeResponse{
+		AuthorizationURL: authURL,
+	}, nil
+}
+
+// CompleteSAMLLogin handles the response posted to the ACS endpoint and returns the
+// frontend URL carrying a one-time login code, an account link token when the login must be
+// confirmed with ConfirmFederatedLink, or an error code if the login failed
+func (s *AuthServiceImpl) CompleteSAMLLogin(ctx context.Context, dto *domain.SAMLResponseDTO) string {
+	code, link, err := s.completeSAMLLogin(ctx, dto)
+	if err != nil {
+		fmt.Printf("Warning: SAML login failed for tenant %s: %v\n", dto.TenantID, err)
+		return s.samlCallbackURL("error", samlErrorCode(err))
+	}
+	if link != nil {
+		return s.samlCallbackURL("link_token", link.AccountLinkToken)
+	}
+
+	return s.samlCallbackURL("code", code)
+}
+
+// ExchangeSAMLLoginCode exchanges a one-time login code for our tokens.
+// MFA is left to the identity provider, so no local MFA challenge is started.
+func (s *AuthServiceImpl) ExchangeSAMLLoginCode(ctx context.Context, dto *domain.SAMLExchangeDTO) (*domain.AuthResponse, error) {
+	// Validate DTO
+	if err := s.validator.Struct(dto); err != nil {
+		return nil, ports.ErrInvalidInput
+	}
+
+	// Login codes are single-use
+	loginCode, err := s.repo.ConsumeSAMLLoginCode(ctx, dto.Code)
+	if err != nil {
+		return nil, err
+	}
+	if loginCode.IsExpired() {
+		return nil, ports.ErrSAMLLoginCodeInvalid
+	}
+
+	user, err := s.repo.GetUserByID(ctx, loginCode.UserID)
+	if err != nil {
+		return nil, ports.ErrUserNotFound
+	}
+
+	// The tokens must carry the tenant the user signed in to
+	if !stringPtrEquals(user.TenantID, loginCode.TenantID) {
+		return nil, ports.ErrSAMLLoginCodeInvalid
+	}
+
+	return s.issueTokens(ctx, user, dto.SessionMetadata)
+}
+
+// GetSAMLProvider returns the tenant's SAML identity provider configuration
+func (s *AuthServiceImpl) GetSAMLProvider(ctx context.Context, tenantID string) (*domain.SAMLProvider, error) {
+	provider, err := s.repo.GetSAMLProvider(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	provider.SPEntityID = s.samlEntityID(tenantID)
+	provider.ACSURL = s.samlACSURL(tenantID)
+
+	return provider, nil
+}
+
+// UpdateSAMLProvider creates or updates the tenant's SAML identity provider configuration
+func (s *AuthServiceImpl) UpdateSAMLProvider(ctx context.Context, tenantID string, dto *domain.SAMLProviderDTO) (*domain.SAMLProvider, error) {
+	// Validate DTO
+	if err := s.validator.Struct(dto); err != nil {
+		return nil, ports.ErrInvalidInput
+	}
+
+	if _, err := saml.ParseCertificatesPEM(dto.IdPCertificate); err != nil {
+		return nil, ports.ErrSAMLCertificateInvalid
+	}
+
+	now := time.Now()
+	provider, err := s.repo.GetSAMLProvider(ctx, tenantID)
+	if err != nil {
+		if !errors.Is(err, ports.ErrSAMLNotConfigured) {
+			return nil, err
+		}
+		provider = &domain.SAMLProvider{
+			TenantID:  tenantID,
+			CreatedAt: now,
+		}
+	}
+
+	provider.IdPEntityID = strings.TrimSpace(dto.IdPEntityID)
+	provider.IdPSSOURL = dto.IdPSSOURL
+	provider.IdPCertificate = strings.TrimSpace(dto.IdPCertificate)
+	provider.EmailAttribute = dto.EmailAttribute
+	provider.NameAttribute = dto.NameAttribute
+	provider.RoleAttribute = dto.RoleAttribute
+	provider.RoleMapping = dto.RoleMapping
+	provider.DefaultRole = dto.DefaultRole
+	if provider.DefaultRole == "" {
+		provider.DefaultRole = string(domain.RoleStudent)
+	}
+	provider.Enabled = dto.Enabled
+	provider.UpdatedAt = now
+
+	if err := s.repo.SaveSAMLProvider(ctx, provider); err != nil {
+		return nil, fmt.Errorf("failed to save SAML provider: %w", err)
+	}
+
+	provider.SPEntityID = s.samlEntityID(tenantID)
+	provider.ACSURL = s.samlACSURL(tenantID)
+
+	return provider, nil
+}
+
+// DeleteSAMLProvider removes the tenant's SAML identity provider configuration
+func (s *AuthServiceImpl) DeleteSAMLProvider(ctx context.Context, tenantID string) error {
+	return s.repo.DeleteSAMLProvider(ctx, tenantID)
+}
+
+// Helper methods
+
+// completeSAMLLogin validates the response, resolves the user and creates a login code,
+// or returns the account link response when the login matched an existing account
+func (s *AuthServiceImpl) completeSAMLLogin(ctx context.Context, dto *domain.SAMLResponseDTO) (string, *domain.AuthResponse, error) {
+	if _, err := uuid.Parse(dto.TenantID); err != nil || dto.SAMLResponse == "" {
+		return "", nil, ports.ErrInvalidInput
+	}
+
+	provider, err := s.getEnabledSAMLProvider(ctx, dto.TenantID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	sp, err := s.samlServiceProvider(provider)
+	if err != nil {
+		return "", nil, err
+	}
+
+	assertion, err := sp.ParseResponse(dto.SAMLResponse, time.Now())
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: %v", ports.ErrSAMLAuthenticationFailed, err)
+	}
+
+	// Only SP-initiated logins are accepted: the response must answer a pending
+	// request of this tenant, and each request can only be answered once
+	if assertion.InResponseTo == "" {
+		return "", nil, ports.ErrSAMLRequestInvalid
+	}
+	request, err := s.repo.ConsumeSAMLAuthRequest(ctx, assertion.InResponseTo)
+	if err != nil {
+		return "", nil, err
+	}
+	if request.IsExpired() || request.TenantID != dto.TenantID {
+		return "", nil, ports.ErrSAMLRequestInvalid
+	}
+
+	email := assertion.NameID
+	if provider.EmailAttribute != "" {
+		email = assertion.Attribute(provider.EmailAttribute)
+	}
+	email = strings.TrimSpace(email)
+	if email == "" || !strings.Contains(email, "@") {
+		return "", nil, fmt.Errorf("%w: no email in assertion", ports.ErrSAMLAuthenticationFailed)
+	}
+
+	fullName := ""
+	if provider.NameAttribute != "" {
+		fullName = assertion.Attribute(provider.NameAttribute)
+	}
+
+	var roleValues []string
+	if provider.RoleAttribute != "" {
+		roleValues = assertion.Attributes[provider.RoleAttribute]
+	}
+	role, mapped := mapFederatedRole(roleValues, provider.RoleMapping, provider.DefaultRole)
+
+	// SAML has no email verification claim, so existing accounts are only linked when the
+	// email is on a domain the tenant verified
+	user, link, err := s.federatedLogin(ctx, &federatedIdentity{
+		protocol: "SAML",
+		tenantID: provider.TenantID,
+		issuer:   provider.IdPEntityID,
+		subject:  assertion.NameID,
+		email:    email,
+		fullName: fullName,
+	}, role, mapped)
+	if err != nil {
+		return "", nil, err
+	}
+	if link != nil {
+		return "", link, nil
+	}
+
+	now := time.Now()
+	loginCode := &domain.SAMLLoginCode{
+		ID:        uuid.New().String(),
+		Code:      s.generateSecureToken(),
+		UserID:    user.ID,
+		TenantID:  provider.TenantID,
+		ExpiresAt: now.Add(samlLoginCodeExpiry),
+		CreatedAt: now,
+	}
+
+	if err := s.repo.CreateSAMLLoginCode(ctx, loginCode); err != nil {
+		return "", nil, fmt.Errorf("failed to create SAML login code: %w", err)
+	}
+
+	return loginCode.Code, nil, nil
+}
+
+// getEnabledSAMLProvider retrieves the tenant provider, treating a disabled one as not configured
+func (s *AuthServiceImpl) getEnabledSAMLProvider(ctx context.Context, tenantID string) (*domain.SAMLProvider, error) {
+	provider, err := s.repo.GetSAMLProvider(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !provider.Enabled {
+		return nil, ports.ErrSAMLNotConfigured
+	}
+
+	return provider, nil
+}
+
+// samlServiceProvider builds the service provider of a tenant from its provider settings
+func (s *AuthServiceImpl) samlServiceProvider(provider *domain.SAMLProvider) (*saml.ServiceProvider, error) {
+	certificates, err := saml.ParseCertificatesPEM(provider.IdPCertificate)
+	if err != nil {
+		fmt.Printf("Warning: invalid SAML certificate for tenant %s: %v\n", provider.TenantID, err)
+		return nil, ports.ErrSAMLCertificateInvalid
+	}
+
+	return &saml.ServiceProvider{
+		EntityID:        s.samlEntityID(provider.TenantID),
+		ACSURL:          s.samlACSURL(provider.TenantID),
+		IdPEntityID:     provider.IdPEntityID,
+		IdPSSOURL:       provider.IdPSSOURL,
+		IdPCertificates: certificates,
+	}, nil
+}
+
+// samlEntityID returns the service provider entity ID of a tenant (its metadata URL)
+func (s *AuthServiceImpl) samlEntityID(tenantID string) string {
+	return s.baseURL + "/api/v1/auth/saml/" + tenantID + "/metadata"
+}
+
+// samlACSURL returns the Assertion Consumer Service URL of a tenant
+func (s *AuthServiceImpl) samlACSURL(tenantID string) string {
+	return s.baseURL + "/api/v1/auth/saml/" + tenantID + "/acs"
+}
+
+// samlCallbackURL returns the frontend callback URL with a single query parameter
+func (s *AuthServiceImpl) samlCallbackURL(param, value string) string {
+	return s.baseURL + samlCallbackPath + "?" + url.Values{param: {value}}.Encode()
+}
+
+// samlErrorCode converts a login error into the code passed to the frontend callback
+func samlErrorCode(err error) string {
+	switch {
+	case errors.Is(err, ports.ErrSAMLNotConfigured):
+		return "not_configured"
+	case errors.Is(err, ports.ErrSAMLRequestInvalid):
+		return "request_expired"
+	case errors.Is(err, ports.ErrAccountDisabled):
+		return "account_disabled"
+	case errors.Is(err, ports.ErrOIDCAccountConflict):
+		return "account_conflict"
+	case errors.Is(err, ports.ErrSAMLAuthenticationFailed), errors.Is(err, ports.ErrInvalidInput):
+		return "authentication_failed"
+	default:
+		return "server_error"
+	}
+}
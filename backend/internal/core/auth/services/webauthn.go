@@ -0,0 +1,416 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/auth/domain"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/auth/ports"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/shared/webauthn"
+	"github.com/google/uuid"
+)
+
+// BeginWebAuthnRegistration returns the options to create a new passkey for the user
+func (s *AuthServiceImpl) BeginWebAuthnRegistration(ctx context.Context, userID string) (*domain.WebAuthnOptionsResponse, error) {
+	// Get user
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, ports.ErrUserNotFound
+	}
+
+	// Don't let the authenticator register a second credential for the same account
+	credentials, err := s.repo.ListWebAuthnCredentials(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	challenge, err := s.createWebAuthnChallenge(ctx, &user.ID, domain.WebAuthnRegistration)
+	if err != nil {
+		return nil, err
+	}
+
+	options := s.webAuthn.CreationOptions(challenge, webauthn.User{
+		ID:          []byte(user.ID),
+		Name:        user.Email,
+		DisplayName: user.FullName,
+	}, credentialDescriptors(credentials))
+
+	return &domain.WebAuthnOptionsResponse{PublicKey: options}, nil
+}
+
+// FinishWebAuthnRegistration verifies the new credential and stores it for the user
+func (s *AuthServiceImpl) FinishWebAuthnRegistration(ctx context.Context, userID string, dto *domain.WebAuthnRegistrationDTO) (*domain.WebAuthnCredential, error) {
+	// Validate DTO
+	if err := s.validator.Struct(dto); err != nil {
+		return nil, ports.ErrInvalidInput
+	}
+
+	clientDataJSON, err := webauthn.DecodeBase64URL(dto.Response.ClientDataJSON)
+	if err != nil {
+		return nil, ports.ErrInvalidInput
+	}
+	attestationObject, err := webauthn.DecodeBase64URL(dto.Response.AttestationObject)
+	if err != nil {
+		return nil, ports.ErrInvalidInput
+	}
+
+	pending, err := s.consumeWebAuthnChallenge(ctx, clientDataJSON, domain.WebAuthnRegistration, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// User verification is preferred but not required, so security keys without a PIN
+	// can still be registered as second factor
+	verified, err := s.webAuthn.VerifyRegistration(pending.Challenge, clientDataJSON, attestationObject, false)
+	if err != nil {
+		fmt.Printf("INFO: Rejected WebAuthn registration of user %s: %v\n", userID, err)
+		return nil, ports.ErrWebAuthnVerificationFailed
+	}
+
+	credentialID := webauthn.EncodeBase64URL(verified.ID)
+	if _, err := s.repo.GetWebAuthnCredential(ctx, credentialID); err == nil {
+		return nil, ports.ErrWebAuthnCredentialExists
+	} else if !errors.Is(err, ports.ErrWebAuthnCredentialNotFound) {
+		return nil, err
+	}
+
+	credential := &domain.WebAuthnCredential{
+		ID:             uuid.New().String(),
+		UserID:         userID,
+		CredentialID:   credentialID,
+		PublicKey:      verified.PublicKey,
+		SignCount:      int64(verified.SignCount),
+		AAGUID:         formatAAGUID(verified.AAGUID),
+		Transports:     dto.Response.Transports,
+		BackupEligible: verified.BackupEligible,
+		Name:           strings.TrimSpace(dto.Name),
+		CreatedAt:      time.Now(),
+	}
+	if credential.Transports == nil {
+		credential.Transports = []string{}
+	}
+
+	if err := s.repo.CreateWebAuthnCredential(ctx, credential); err != nil {
+		return nil, err
+	}
+
+	fmt.Printf("INFO: Registered WebAuthn credential %s for user %s\n", credential.ID, userID)
+
+	return credential, nil
+}
+
+// ListWebAuthnCredentials lists the passkeys and security keys registered by the user
+func (s *AuthServiceImpl) ListWebAuthnCredentials(ctx context.Context, userID string) ([]*domain.WebAuthnCredential, error) {
+	return s.repo.ListWebAuthnCredentials(ctx, userID)
+}
+
+// DeleteWebAuthnCredential removes one of the user's credentials
+func (s *AuthServiceImpl) DeleteWebAuthnCredential(ctx context.Context, userID string, credentialID string) error {
+	if _, err := uuid.Parse(credentialID); err != nil {
+		return ports.ErrWebAuthnCredentialNotFound
+	}
+
+	return s.repo.DeleteWebAuthnCredential(ctx, userID, credentialID)
+}
+
+// BeginWebAuthnLogin returns the options for a passwordless login
+func (s *AuthServiceImpl) BeginWebAuthnLogin(ctx context.Context, tenantID string, dto *domain.WebAuthnLoginBeginDTO) (*domain.WebAuthnOptionsResponse, error) {
+	// Validate DTO
+	if err := s.validator.Struct(dto); err != nil {
+		return nil, ports.ErrInvalidInput
+	}
+
+	// Unknown emails get the same options as a usernameless login so that
+	// the response doesn't reveal which accounts exist
+	var userID *string
+	var allow []webauthn.CredentialDescriptor
+	if dto.Email != "" {
+		user, err := s.repo.GetUserByEmail(ctx, dto.Email)
+		if err == nil {
+			credentials, err := s.repo.ListWebAuthnCredentials(ctx, user.ID)
+			if err != nil {
+				return nil, err
+			}
+			if len(credentials) > 0 {
+				userID = &user.ID
+				allow = credentialDescriptors(credentials)
+			}
+		}
+	}
+
+	challenge, err := s.createWebAuthnChallenge(ctx, userID, domain.WebAuthnLogin)
+	if err != nil {
+		return nil, err
+	}
+
+	options := s.webAuthn.RequestOptions(challenge, allow, webauthn.UserVerificationRequired)
+
+	return &domain.WebAuthnOptionsResponse{PublicKey: options}, nil
+}
+
+// FinishWebAuthnLogin verifies a passkey assertion and issues the access/refresh token pair
+func (s *AuthServiceImpl) FinishWebAuthnLogin(ctx context.Context, tenantID string, dto *domain.WebAuthnLoginDTO) (*domain.AuthResponse, error) {
+	// Validate DTO
+	if err := s.validator.Struct(dto); err != nil {
+		return nil, ports.ErrInvalidInput
+	}
+
+	clientDataJSON, err := webauthn.DecodeBase64URL(dto.Response.ClientDataJSON)
+	if err != nil {
+		return nil, ports.ErrInvalidInput
+	}
+
+	pending, err := s.consumeWebAuthnChallenge(ctx, clientDataJSON, domain.WebAuthnLogin, "")
+	if err != nil {
+		return nil, err
+	}
+
+	credential, err := s.repo.GetWebAuthnCredential(ctx, dto.ID)
+	if err != nil {
+		if errors.Is(err, ports.ErrWebAuthnCredentialNotFound) {
+			s.throttle.recordIPFailure(ctx, dto.IPAddress)
+			return nil, ports.ErrWebAuthnVerificationFailed
+		}
+		return nil, err
+	}
+
+	// A login started with an email can only be completed by that user
+	if pending.UserID != nil && *pending.UserID != credential.UserID {
+		return nil, ports.ErrWebAuthnVerificationFailed
+	}
+
+	// Discoverable credentials return the user handle set at registration
+	if dto.Response.UserHandle != "" {
+		userHandle, err := webauthn.DecodeBase64URL(dto.Response.UserHandle)
+		if err != nil || string(userHandle) != credential.UserID {
+			return nil, ports.ErrWebAuthnVerificationFailed
+		}
+	}
+
+	// Get user
+	user, err := s.repo.GetUserByID(ctx, credential.UserID)
+	if err != nil {
+		return nil, ports.ErrWebAuthnVerificationFailed
+	}
+
+	// Locked accounts and blocked clients can't sign in with a passkey either
+	if err := s.throttle.check(ctx, user.Email, dto.IPAddress); err != nil {
+		return nil, err
+	}
+
+	// The passkey replaces both the password and the second factor, so the user
+	// must have been verified by the authenticator (PIN or biometrics)
+	if err := s.verifyWebAuthnAssertion(ctx, credential, pending.Challenge, clientDataJSON, &dto.Response, true); err != nil {
+		s.throttle.recordIPFailure(ctx, dto.IPAddress)
+		return nil, err
+	}
+	s.throttle.reset(ctx, user.Email)
+
+	// Verify tenant matches ONLY if tenantID was provided in the request
+	if tenantID != "" && user.TenantID != nil && !stringPtrEquals(user.TenantID, tenantID) {
+		return nil, ports.ErrTenantMismatch
+	}
+
+	// Check if user is verified
+	if !user.IsVerified {
+		return nil, ports.ErrAccountNotVerified
+	}
+
+	return s.issueTokens(ctx, user, dto.SessionMetadata)
+}
+
+// BeginWebAuthnMFA returns the options to complete a login MFA challenge with a credential
+func (s *AuthServiceImpl) BeginWebAuthnMFA(ctx context.Context, dto *domain.MFAChallengeDTO) (*domain.WebAuthnOptionsResponse, error) {
+	// Validate DTO
+	if err := s.validator.Struct(dto); err != nil {
+		return nil, ports.ErrInvalidInput
+	}
+
+	// Get challenge
+	mfaChallenge, err := s.getValidMFAChallenge(ctx, dto.MFAToken)
+	if err != nil {
+		return nil, err
+	}
+
+	credentials, err := s.repo.ListWebAuthnCredentials(ctx, mfaChallenge.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if len(credentials) == 0 {
+		return nil, ports.ErrMFANotEnrolled
+	}
+
+	challenge, err := s.createWebAuthnChallenge(ctx, &mfaChallenge.UserID, domain.WebAuthnMFA)
+	if err != nil {
+		return nil, err
+	}
+
+	options := s.webAuthn.RequestOptions(challenge, credentialDescriptors(credentials), webauthn.UserVerificationPreferred)
+
+	return &domain.WebAuthnOptionsResponse{PublicKey: options}, nil
+}
+
+// FinishWebAuthnMFA completes a login MFA challenge with a credential assertion
+func (s *AuthServiceImpl) FinishWebAuthnMFA(ctx context.Context, dto *domain.WebAuthnMFALoginDTO) (*domain.AuthResponse, error) {
+	// Validate DTO
+	if err := s.validator.Struct(dto); err != nil {
+		return nil, ports.ErrInvalidInput
+	}
+
+	clientDataJSON, err := webauthn.DecodeBase64URL(dto.Response.ClientDataJSON)
+	if err != nil {
+		return nil, ports.ErrInvalidInput
+	}
+
+	// Get challenge
+	mfaChallenge, err := s.getValidMFAChallenge(ctx, dto.MFAToken)
+	if err != nil {
+		return nil, err
+	}
+
+	pending, err := s.consumeWebAuthnChallenge(ctx, clientDataJSON, domain.WebAuthnMFA, mfaChallenge.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get user
+	user, err := s.repo.GetUserByID(ctx, mfaChallenge.UserID)
+	if err != nil {
+		return nil, ports.ErrUserNotFound
+	}
+
+	// The credential must belong to the user who entered the password; as a second
+	// factor, user presence is enough
+	credential, err := s.repo.GetWebAuthnCredential(ctx, dto.ID)
+	if err == nil && credential.UserID != user.ID {
+		err = ports.ErrWebAuthnCredentialNotFound
+	}
+	if err == nil {
+		err = s.verifyWebAuthnAssertion(ctx, credential, pending.Challenge, clientDataJSON, &dto.Response, false)
+	}
+	if err != nil {
+		if errors.Is(err, ports.ErrWebAuthnCredentialNotFound) || errors.Is(err, ports.ErrWebAuthnVerificationFailed) {
+			if incErr := s.repo.IncrementMFAChallengeAttempts(ctx, mfaChallenge.ID); incErr != nil {
+				fmt.Printf("Warning: failed to record MFA attempt: %v\n", incErr)
+			}
+			return nil, ports.ErrWebAuthnVerificationFailed
+		}
+		return nil, err
+	}
+
+	// Challenges are single-use
+	if err := s.repo.DeleteMFAChallenge(ctx, mfaChallenge.ID); err != nil {
+		fmt.Printf("Warning: failed to delete MFA challenge: %v\n", err)
+	}
+
+	return s.issueTokens(ctx, user, dto.SessionMetadata)
+}
+
+// WebAuthn helper methods
+
+// createWebAuthnChallenge stores a new challenge for a ceremony and returns it
+func (s *AuthServiceImpl) createWebAuthnChallenge(ctx context.Context, userID *string, ceremony domain.WebAuthnCeremony) (string, error) {
+	challenge, err := webauthn.GenerateChallenge()
+	if err != nil {
+		return "", err
+	}
+
+	pending := &domain.WebAuthnChallenge{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		Challenge: challenge,
+		Ceremony:  ceremony,
+		ExpiresAt: time.Now().Add(s.webAuthn.Timeout),
+		CreatedAt: time.Now(),
+	}
+
+	if err := s.repo.CreateWebAuthnChallenge(ctx, pending); err != nil {
+		return "", err
+	}
+
+	return challenge, nil
+}
+
+// consumeWebAuthnChallenge retrieves the pending ceremony a response answers. When userID
+// is set, the ceremony must have been started for that user.
+func (s *AuthServiceImpl) consumeWebAuthnChallenge(ctx context.Context, clientDataJSON []byte, ceremony domain.WebAuthnCeremony, userID string) (*domain.WebAuthnChallenge, error) {
+	challenge, err := webauthn.ChallengeFromClientData(clientDataJSON)
+	if err != nil {
+		return nil, ports.ErrWebAuthnChallengeInvalid
+	}
+
+	pending, err := s.repo.ConsumeWebAuthnChallenge(ctx, challenge, ceremony)
+	if err != nil {
+		return nil, err
+	}
+
+	if pending.IsExpired() {
+		return nil, ports.ErrWebAuthnChallengeInvalid
+	}
+	if userID != "" && (pending.UserID == nil || *pending.UserID != userID) {
+		return nil, ports.ErrWebAuthnChallengeInvalid
+	}
+
+	return pending, nil
+}
+
+// verifyWebAuthnAssertion checks an assertion against the stored credential and records its use
+func (s *AuthServiceImpl) verifyWebAuthnAssertion(ctx context.Context, credential *domain.WebAuthnCredential, challenge string, clientDataJSON []byte, response *domain.WebAuthnAssertionDTO, requireUserVerification bool) error {
+	authenticatorData, err := webauthn.DecodeBase64URL(response.AuthenticatorData)
+	if err != nil {
+		return ports.ErrWebAuthnVerificationFailed
+	}
+	signature, err := webauthn.DecodeBase64URL(response.Signature)
+	if err != nil {
+		return ports.ErrWebAuthnVerificationFailed
+	}
+
+	signCount, err := s.webAuthn.VerifyAssertion(
+		challenge,
+		credential.PublicKey,
+		uint32(credential.SignCount),
+		clientDataJSON,
+		authenticatorData,
+		signature,
+		requireUserVerification,
+	)
+	if err != nil {
+		if errors.Is(err, webauthn.ErrSignCountInvalid) {
+			fmt.Printf("Warning: WebAuthn credential %s of user %s may be cloned: %v\n", credential.ID, credential.UserID, err)
+		} else {
+			fmt.Printf("INFO: Rejected WebAuthn assertion of user %s: %v\n", credential.UserID, err)
+		}
+		return ports.ErrWebAuthnVerificationFailed
+	}
+
+	if err := s.repo.UpdateWebAuthnCredentialUse(ctx, credential.ID, int64(signCount)); err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+
+	return nil
+}
+
+// credentialDescriptors describes stored credentials for the ceremony options
+func credentialDescriptors(credentials []*domain.WebAuthnCredential) []webauthn.CredentialDescriptor {
+	descriptors := make([]webauthn.CredentialDescriptor, 0, len(credentials))
+	for _, credential := range credentials {
+		id, err := webauthn.DecodeBase64URL(credential.CredentialID)
+		if err != nil {
+			continue
+		}
+		descriptors = append(descriptors, webauthn.NewCredentialDescriptor(id, credential.Transports))
+	}
+	return descriptors
+}
+
+// formatAAGUID formats the authenticator model identifier as a UUID
+func formatAAGUID(aaguid []byte) string {
+	id, err := uuid.FromBytes(aaguid)
+	if err != nil {
+		return ""
+	}
+	return id.String()
+}
@@ -4,16 +4,38 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/DanielIturra1610/stegmaier-landing/internal/core/certificates/domain"
 	"github.com/DanielIturra1610/stegmaier-landing/internal/core/certificates/ports"
+	tenantdomain "github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/domain"
+	tenantports "github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/ports"
 	"github.com/jung-kurt/gofpdf"
 	"github.com/google/uuid"
 )
 
+// maxLogoBytes bounds the size of the tenant logos drawn on certificates
+const maxLogoBytes = 2 << 20
+
+// rgbColor represents a color of the certificate layout
+type rgbColor struct {
+	r, g, b int
+}
+
+// defaultAccentColor is the color of the border, title and course name of unbranded certificates
+var defaultAccentColor = rgbColor{41, 128, 185} // Blue
+
 // PDFGenerator implements the CertificateGenerator interface using gofpdf
 type PDFGenerator struct {
+	// Tenant branding: institution name, accent color, logo and support contact
+	branding   tenantports.BrandingProvider
+	httpClient *http.Client
+
 	// Configuration fields
 	pageWidth  float64
 	pageHeight float64
@@ -25,9 +47,12 @@ type PDFGenerator struct {
 	}
 }
 
-// NewPDFGenerator creates a new PDF generator with default settings
-func NewPDFGenerator() ports.CertificateGenerator {
+// NewPDFGenerator creates a new PDF generator with default settings. Certificates carry the
+// branding of their tenant; a nil branding provider means the platform branding.
+func NewPDFGenerator(branding tenantports.BrandingProvider) ports.CertificateGenerator {
 	gen := &PDFGenerator{
+		branding:   branding,
+		httpClient: &http.Client{Timeout: 5 * time.Second},
 		pageWidth:  297.0, // A4 landscape width in mm
 		pageHeight: 210.0, // A4 landscape height in mm
 	}
@@ -53,16 +78,32 @@ func (g *PDFGenerator) GeneratePDF(ctx context.Context, certificate *domain.Cert
 	rightMargin := 20.0
 	topMargin := 30.0
 
+	brand := g.brandingFor(ctx, certificate)
+	accent := defaultAccentColor
+	if brand != nil {
+		if color, ok := parseHexColor(brand.Palette.Primary); ok {
+			accent = color
+		}
+	}
+
 	// Add decorative border
-	g.addDecorativeBorder(pdf)
+	g.addDecorativeBorder(pdf, accent)
+
+	// Add tenant logo (top-left corner)
+	if brand != nil && brand.LogoURL != "" {
+		g.addLogo(ctx, pdf, brand.LogoURL, leftMargin, 18, 16)
+	}
 
 	// Add header logo/institution name
 	pdf.SetFont("Arial", "B", 16)
 	pdf.SetTextColor(44, 62, 80) // Dark blue-gray
 	pdf.SetXY(leftMargin, topMargin)
 
-	// Institution name from data or default
+	// Institution name from data, tenant branding or default
 	institutionName := "Stegmaier Learning Platform"
+	if brand != nil && brand.TenantName != "" {
+		institutionName = brand.TenantName
+	}
 	if name, ok := data["institution_name"].(string); ok && name != "" {
 		institutionName = name
 	}
@@ -70,12 +111,12 @@ func (g *PDFGenerator) GeneratePDF(ctx context.Context, certificate *domain.Cert
 
 	// Add "Certificate of Completion" title
 	pdf.SetFont("Arial", "B", float64(g.fontSize.title))
-	pdf.SetTextColor(41, 128, 185) // Blue
+	pdf.SetTextColor(accent.r, accent.g, accent.b)
 	pdf.SetXY(leftMargin, topMargin+20)
 	pdf.CellFormat(g.pageWidth-leftMargin-rightMargin, 15, "Certificate of Completion", "", 0, "C", false, 0, "")
 
 	// Add decorative line
-	pdf.SetDrawColor(41, 128, 185)
+	pdf.SetDrawColor(accent.r, accent.g, accent.b)
 	pdf.SetLineWidth(0.5)
 	lineY := topMargin + 37
 	lineMargin := 80.0
@@ -107,7 +148,7 @@ func (g *PDFGenerator) GeneratePDF(ctx context.Context, certificate *domain.Cert
 
 	// Add course name (emphasized)
 	pdf.SetFont("Arial", "B", 20)
-	pdf.SetTextColor(41, 128, 185)
+	pdf.SetTextColor(accent.r, accent.g, accent.b)
 	pdf.SetXY(leftMargin, lineY+63)
 
 	// Get course name from data (required parameter)
@@ -187,6 +228,14 @@ func (g *PDFGenerator) GeneratePDF(ctx context.Context, certificate *domain.Cert
 	generatedText := fmt.Sprintf("Generated: %s", time.Now().Format("2006-01-02 15:04:05"))
 	pdf.CellFormat(60, 5, generatedText, "", 0, "R", false, 0, "")
 
+	// Add support contact of the tenant (bottom-left corner)
+	if brand != nil {
+		if contact := supportLine(brand.Support); contact != "" {
+			pdf.SetXY(leftMargin, g.pageHeight-10)
+			pdf.CellFormat(g.pageWidth/2, 5, contact, "", 0, "L", false, 0, "")
+		}
+	}
+
 	// Output PDF to bytes using a buffer
 	var buf bytes.Buffer
 	err := pdf.Output(&buf)
@@ -233,10 +282,108 @@ func (g *PDFGenerator) ValidateTemplate(ctx context.Context, templatePath string
 	return nil
 }
 
+// brandingFor retrieves the branding of the tenant of a certificate. Certificates are still
+// generated with the platform branding if it can't be retrieved.
+func (g *PDFGenerator) brandingFor(ctx context.Context, certificate *domain.Certificate) *tenantdomain.TenantBranding {
+	if g.branding == nil {
+		return nil
+	}
+
+	brand, err := g.branding.GetTenantBranding(ctx, certificate.TenantID.String())
+	if err != nil {
+		log.Printf("⚠️  Failed to get branding of tenant %s for certificate: %v", certificate.TenantID, err)
+		return nil
+	}
+	return brand
+}
+
+// addLogo draws the image at logoURL with the given height. The certificate is generated without
+// the logo if it can't be downloaded or is not a PNG, JPEG or GIF image.
+func (g *PDFGenerator) addLogo(ctx context.Context, pdf *gofpdf.Fpdf, logoURL string, x, y, h float64) {
+	if !strings.HasPrefix(logoURL, "https://") && !strings.HasPrefix(logoURL, "http://") {
+		return
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, logoURL, nil)
+	if err != nil {
+		return
+	}
+	resp, err := g.httpClient.Do(req)
+	if err != nil {
+		log.Printf("⚠️  Failed to download certificate logo %s: %v", logoURL, err)
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		log.Printf("⚠️  Failed to download certificate logo %s: status %d", logoURL, resp.StatusCode)
+		return
+	}
+
+	logo, err := io.ReadAll(io.LimitReader(resp.Body, maxLogoBytes+1))
+	if err != nil || len(logo) > maxLogoBytes {
+		log.Printf("⚠️  Certificate logo %s is unreadable or larger than %d bytes", logoURL, maxLogoBytes)
+		return
+	}
+
+	var imageType string
+	switch http.DetectContentType(logo) {
+	case "image/png":
+		imageType = "PNG"
+	case "image/jpeg":
+		imageType = "JPG"
+	case "image/gif":
+		imageType = "GIF"
+	default:
+		log.Printf("⚠️  Certificate logo %s is not a PNG, JPEG or GIF image", logoURL)
+		return
+	}
+
+	options := gofpdf.ImageOptions{ImageType: imageType}
+	pdf.RegisterImageOptionsReader(logoURL, options, bytes.NewReader(logo))
+	if pdf.Err() {
+		// An image gofpdf can't parse must not fail the whole certificate
+		log.Printf("⚠️  Failed to register certificate logo %s: %v", logoURL, pdf.Error())
+		pdf.ClearError()
+		return
+	}
+	pdf.ImageOptions(logoURL, x, y, 0, h, false, options, 0, "")
+}
+
+// supportLine formats the support contact of a tenant for the footer of its certificates
+func supportLine(support tenantdomain.SupportContact) string {
+	var parts []string
+	for _, part := range []string{support.Email, support.Phone, support.URL} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "Support: " + strings.Join(parts, " | ")
+}
+
+// parseHexColor parses a #rrggbb or #rgb color
+func parseHexColor(hex string) (rgbColor, bool) {
+	hex = strings.TrimPrefix(hex, "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) != 6 {
+		return rgbColor{}, false
+	}
+
+	value, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return rgbColor{}, false
+	}
+	return rgbColor{int(value>>16&0xff), int(value>>8&0xff), int(value&0xff)}, true
+}
+
 // addDecorativeBorder adds a decorative border to the certificate
-func (g *PDFGenerator) addDecorativeBorder(pdf *gofpdf.Fpdf) {
+func (g *PDFGenerator) addDecorativeBorder(pdf *gofpdf.Fpdf, accent rgbColor) {
 	// Outer border (thicker)
-	pdf.SetDrawColor(41, 128, 185) // Blue
+	pdf.SetDrawColor(accent.r, accent.g, accent.b)
 	pdf.SetLineWidth(1.5)
 	margin := 10.0
 	pdf.Rect(margin, margin, g.pageWidth-2*margin, g.pageHeight-2*margin, "D")
@@ -247,15 +394,15 @@ func (g *PDFGenerator) addDecorativeBorder(pdf *gofpdf.Fpdf) {
 	pdf.Rect(innerMargin, innerMargin, g.pageWidth-2*innerMargin, g.pageHeight-2*innerMargin, "D")
 
 	// Add corner decorations
-	g.addCornerDecorations(pdf)
+	g.addCornerDecorations(pdf, accent)
 }
 
 // addCornerDecorations adds decorative elements to corners
-func (g *PDFGenerator) addCornerDecorations(pdf *gofpdf.Fpdf) {
+func (g *PDFGenerator) addCornerDecorations(pdf *gofpdf.Fpdf, accent rgbColor) {
 	cornerSize := 15.0
 	cornerMargin := 15.0
 
-	pdf.SetDrawColor(41, 128, 185)
+	pdf.SetDrawColor(accent.r, accent.g, accent.b)
 	pdf.SetLineWidth(1.0)
 
 	// Top-left corner
@@ -1,8 +1,11 @@
 package controllers
 
 import (
+	authdomain "github.com/DanielIturra1610/stegmaier-landing/internal/core/auth/domain"
 	"github.com/DanielIturra1610/stegmaier-landing/internal/core/certificates/domain"
 	"github.com/DanielIturra1610/stegmaier-landing/internal/core/certificates/ports"
+	featureports "github.com/DanielIturra1610/stegmaier-landing/internal/core/features/ports"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/middleware"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 )
@@ -325,6 +328,10 @@ func (c *CertificateController) GenerateCertificate(ctx *fiber.Ctx) error {
 			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 				"error": "course progress must be completed before generating certificate",
 			})
+		case featureports.ErrFeatureDisabled:
+			return ctx.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "certificates are not enabled for this tenant",
+			})
 		default:
 			return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 				"error": "failed to generate certificate",
@@ -1220,35 +1227,37 @@ func (c *CertificateController) SetDefaultTemplate(ctx *fiber.Ctx) error {
 // RegisterRoutes registers all certificate routes
 // NOTE: Middlewares (Auth, Tenant, Membership) are applied in server.go before calling this
 func (c *CertificateController) RegisterRoutes(router fiber.Router) {
+	manage := middleware.RequirePermission(authdomain.PermissionCertificateManage)
+
 	// Student Certificate Routes - register directly to inherit middlewares
 	router.Get("/certificates/my", c.GetMyCertificates)
 	router.Get("/certificates/my/courses/:courseID", c.GetMyCertificate)
 
 	// Certificate Management Routes (Admin/Instructor)
-	router.Post("/certificates", c.GenerateCertificate)
+	router.Post("/certificates", manage, c.GenerateCertificate)
 	router.Get("/certificates/:certificateID", c.GetCertificate)
-	router.Delete("/certificates/:certificateID", c.DeleteCertificate)
+	router.Delete("/certificates/:certificateID", manage, c.DeleteCertificate)
 
 	// Certificate operations
 	router.Get("/certificates/:certificateID/download", c.DownloadCertificate)
-	router.Post("/certificates/:certificateID/revoke", c.RevokeCertificate)
+	router.Post("/certificates/:certificateID/revoke", manage, c.RevokeCertificate)
 
 	// List certificates by course or user
-	router.Get("/certificates/courses/:courseID", c.ListCourseCertificates)
-	router.Get("/certificates/users/:userID", c.ListUserCertificates)
+	router.Get("/certificates/courses/:courseID", manage, c.ListCourseCertificates)
+	router.Get("/certificates/users/:userID", manage, c.ListUserCertificates)
 
 	// Statistics
-	router.Get("/certificates/statistics", c.GetCertificateStatistics)
-	router.Get("/certificates/courses/:courseID/statistics", c.GetCourseStatistics)
+	router.Get("/certificates/statistics", manage, c.GetCertificateStatistics)
+	router.Get("/certificates/courses/:courseID/statistics", manage, c.GetCourseStatistics)
 
 	// Public verification endpoint
 	router.Post("/certificates/verify", c.VerifyCertificate)
 
 	// Certificate Template Routes (Admin only)
-	router.Post("/certificates/templates", c.CreateTemplate)
-	router.Get("/certificates/templates", c.ListTemplates)
-	router.Get("/certificates/templates/:templateID", c.GetTemplate)
-	router.Put("/certificates/templates/:templateID", c.UpdateTemplate)
-	router.Delete("/certificates/templates/:templateID", c.DeleteTemplate)
-	router.Post("/certificates/templates/:templateID/set-default", c.SetDefaultTemplate)
+	router.Post("/certificates/templates", manage, c.CreateTemplate)
+	router.Get("/certificates/templates", manage, c.ListTemplates)
+	router.Get("/certificates/templates/:templateID", manage, c.GetTemplate)
+	router.Put("/certificates/templates/:templateID", manage, c.UpdateTemplate)
+	router.Delete("/certificates/templates/:templateID", manage, c.DeleteTemplate)
+	router.Post("/certificates/templates/:templateID/set-default", manage, c.SetDefaultTemplate)
 }
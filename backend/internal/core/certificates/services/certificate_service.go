@@ -8,6 +8,8 @@ import (
 
 	"github.com/DanielIturra1610/stegmaier-landing/internal/core/certificates/domain"
 	"github.com/DanielIturra1610/stegmaier-landing/internal/core/certificates/ports"
+	featuredomain "github.com/DanielIturra1610/stegmaier-landing/internal/core/features/domain"
+	featureports "github.com/DanielIturra1610/stegmaier-landing/internal/core/features/ports"
 	"github.com/google/uuid"
 )
 
@@ -16,6 +18,7 @@ type CertificateService struct {
 	repo      ports.CertificateRepository
 	generator ports.CertificateGenerator
 	storage   ports.CertificateStorage
+	features  featureports.FeatureFlagService
 	baseURL   string
 }
 
@@ -24,12 +27,14 @@ func NewCertificateService(
 	repo ports.CertificateRepository,
 	generator ports.CertificateGenerator,
 	storage ports.CertificateStorage,
+	features featureports.FeatureFlagService,
 	baseURL string,
 ) ports.CertificateService {
 	return &CertificateService{
 		repo:      repo,
 		generator: generator,
 		storage:   storage,
+		features:  features,
 		baseURL:   baseURL,
 	}
 }
@@ -170,6 +175,10 @@ func (s *CertificateService) VerifyMyCertificate(ctx context.Context, certificat
 func (s *CertificateService) GenerateCertificate(ctx context.Context, tenantID uuid.UUID, req *domain.GenerateCertificateRequest) (*domain.CertificateResponse, error) {
 	log.Printf("[CertificateService] GenerateCertificate - userID: %s, courseID: %s", req.UserID, req.CourseID)
 
+	if err := s.requireCertificates(ctx, tenantID); err != nil {
+		return nil, err
+	}
+
 	// Validate request
 	if err := req.Validate(); err != nil {
 		return nil, err
@@ -631,6 +640,10 @@ func (s *CertificateService) RegenerateCertificate(ctx context.Context, certific
 func (s *CertificateService) BulkGenerateCertificates(ctx context.Context, courseID, tenantID uuid.UUID) (int, error) {
 	log.Printf("[CertificateService] BulkGenerateCertificates - courseID: %s", courseID)
 
+	if err := s.requireCertificates(ctx, tenantID); err != nil {
+		return 0, err
+	}
+
 	// This is a placeholder implementation
 	// In a real system, this would:
 	// 1. Get all enrollments for the course
@@ -647,6 +660,15 @@ func (s *CertificateService) BulkGenerateCertificates(ctx context.Context, cours
 // Helper Methods
 // ============================================================
 
+// requireCertificates checks that the certificates feature is on for the tenant. Only issuing
+// is checked: certificates issued before the feature was turned off can still be verified.
+func (s *CertificateService) requireCertificates(ctx context.Context, tenantID uuid.UUID) error {
+	if s.features == nil {
+		return nil
+	}
+	return s.features.RequireFeature(ctx, tenantID.String(), featuredomain.FeatureCertificates)
+}
+
 // getTemplateForCertificate gets the template to use for a certificate
 func (s *CertificateService) getTemplateForCertificate(ctx context.Context, cert *domain.Certificate, tenantID uuid.UUID) (*domain.CertificateTemplate, error) {
 	// If certificate has a specific template, use it
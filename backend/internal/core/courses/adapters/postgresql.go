@@ -768,6 +768,105 @@ func (r *PostgreSQLCourseRepository) GetPublishedCourses(ctx context.Context, te
 	return courses, totalCount, nil
 }
 
+// courseInstructorRow represents a course co-instructor row from the database
+type courseInstructorRow struct {
+	CourseID  uuid.UUID `db:"course_id"`
+	TenantID  uuid.UUID `db:"tenant_id"`
+	UserID    uuid.UUID `db:"user_id"`
+	AddedBy   uuid.UUID `db:"added_by"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// ListCourseInstructors retrieves the co-instructors of a course
+func (r *PostgreSQLCourseRepository) ListCourseInstructors(ctx context.Context, courseID, tenantID uuid.UUID) ([]*domain.CourseInstructor, error) {
+	query := `
+		SELECT course_id, tenant_id, user_id, added_by, created_at
+		FROM course_instructors
+		WHERE course_id = $1 AND tenant_id = $2
+		ORDER BY created_at ASC
+	`
+
+	var rows []courseInstructorRow
+	err := r.db.SelectContext(ctx, &rows, query, courseID, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list course instructors: %w", err)
+	}
+
+	instructors := make([]*domain.CourseInstructor, len(rows))
+	for i, row := range rows {
+		instructors[i] = &domain.CourseInstructor{
+			CourseID:  row.CourseID,
+			TenantID:  row.TenantID,
+			UserID:    row.UserID,
+			AddedBy:   row.AddedBy,
+			CreatedAt: row.CreatedAt,
+		}
+	}
+
+	return instructors, nil
+}
+
+// AddCourseInstructor adds a co-instructor to a course
+func (r *PostgreSQLCourseRepository) AddCourseInstructor(ctx context.Context, instructor *domain.CourseInstructor) error {
+	query := `
+		INSERT INTO course_instructors (course_id, tenant_id, user_id, added_by, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (course_id, user_id) DO NOTHING
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		instructor.CourseID, instructor.TenantID, instructor.UserID, instructor.AddedBy, instructor.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to add course instructor: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ports.ErrCourseInstructorExists
+	}
+
+	return nil
+}
+
+// RemoveCourseInstructor removes a co-instructor from a course
+func (r *PostgreSQLCourseRepository) RemoveCourseInstructor(ctx context.Context, courseID, userID, tenantID uuid.UUID) error {
+	query := `DELETE FROM course_instructors WHERE course_id = $1 AND user_id = $2 AND tenant_id = $3`
+
+	result, err := r.db.ExecContext(ctx, query, courseID, userID, tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to remove course instructor: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ports.ErrCourseInstructorNotFound
+	}
+
+	return nil
+}
+
+// IsCourseInstructor checks if a user is a co-instructor of a course
+func (r *PostgreSQLCourseRepository) IsCourseInstructor(ctx context.Context, courseID, userID, tenantID uuid.UUID) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM course_instructors WHERE course_id = $1 AND user_id = $2 AND tenant_id = $3)`
+
+	var exists bool
+	err := r.db.GetContext(ctx, &exists, query, courseID, userID, tenantID)
+	if err != nil {
+		return false, fmt.Errorf("failed to check course instructor: %w", err)
+	}
+
+	return exists, nil
+}
+
 // PostgreSQLCourseCategoryRepository is a PostgreSQL implementation of CourseCategoryRepository
 type PostgreSQLCourseCategoryRepository struct {
 	db *sqlx.DB
@@ -113,6 +113,11 @@ type RateCourseRequest struct {
 	Rating   float64   `json:"rating" validate:"required,min=0,max=5"`
 }
 
+// AddCourseInstructorRequest represents a request to add a co-instructor to a course
+type AddCourseInstructorRequest struct {
+	UserID uuid.UUID `json:"userId" validate:"required"`
+}
+
 // ============================================================
 // Response DTOs
 // ============================================================
@@ -374,6 +379,14 @@ func (r *RateCourseRequest) Validate() error {
 	return nil
 }
 
+// Validate validates the AddCourseInstructorRequest
+func (r *AddCourseInstructorRequest) Validate() error {
+	if r.UserID == uuid.Nil {
+		return errors.New("user ID is required")
+	}
+	return nil
+}
+
 // ============================================================
 // Utility Methods
 // ============================================================
@@ -3,6 +3,7 @@ package domain
 import (
 	"time"
 
+	authdomain "github.com/DanielIturra1610/stegmaier-landing/internal/core/auth/domain"
 	"github.com/google/uuid"
 )
 
@@ -80,6 +81,30 @@ type CourseCategory struct {
 	UpdatedAt   time.Time  `json:"updatedAt"`
 }
 
+// CourseInstructor is a co-instructor added to a course. Co-instructors can change the
+// course and its content like the course instructor, but can't manage other co-instructors.
+type CourseInstructor struct {
+	CourseID  uuid.UUID `json:"courseId"`
+	TenantID  uuid.UUID `json:"tenantId"`
+	UserID    uuid.UUID `json:"userId"`
+	AddedBy   uuid.UUID `json:"addedBy"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// CourseActor is the user performing an action on a course, with their role in the tenant
+type CourseActor struct {
+	UserID uuid.UUID
+	Role   string
+}
+
+// NewCourseActor creates a new course actor
+func NewCourseActor(userID uuid.UUID, role string) CourseActor {
+	return CourseActor{
+		UserID: userID,
+		Role:   role,
+	}
+}
+
 // NewCourse creates a new course with default values
 func NewCourse(tenantID, instructorID uuid.UUID, title, slug, description string, level CourseLevel) *Course {
 	now := time.Now().UTC()
@@ -246,3 +271,13 @@ func (c *CourseCategory) Deactivate() {
 	c.IsActive = false
 	c.UpdateTimestamp()
 }
+
+// IsAdmin returns true if the actor can change any course of the tenant
+func (a CourseActor) IsAdmin() bool {
+	return authdomain.GetRoleHierarchy(authdomain.UserRole(a.Role)) >= authdomain.GetRoleHierarchy(authdomain.RoleAdmin)
+}
+
+// CanTeach returns true if the role of the actor allows teaching courses of the tenant
+func (a CourseActor) CanTeach() bool {
+	return authdomain.GetRoleHierarchy(authdomain.UserRole(a.Role)) >= authdomain.GetRoleHierarchy(authdomain.RoleInstructor)
+}
@@ -3,6 +3,7 @@ package ports
 import (
 	"context"
 
+	authdomain "github.com/DanielIturra1610/stegmaier-landing/internal/core/auth/domain"
 	"github.com/DanielIturra1610/stegmaier-landing/internal/core/courses/domain"
 	"github.com/google/uuid"
 )
@@ -59,6 +60,37 @@ type CourseRepository interface {
 
 	// GetPublishedCourses retrieves all published courses
 	GetPublishedCourses(ctx context.Context, tenantID uuid.UUID, page, pageSize int) ([]*domain.Course, int, error)
+
+	// ListCourseInstructors retrieves the co-instructors of a course
+	ListCourseInstructors(ctx context.Context, courseID, tenantID uuid.UUID) ([]*domain.CourseInstructor, error)
+
+	// AddCourseInstructor adds a co-instructor to a course
+	AddCourseInstructor(ctx context.Context, instructor *domain.CourseInstructor) error
+
+	// RemoveCourseInstructor removes a co-instructor from a course
+	RemoveCourseInstructor(ctx context.Context, courseID, userID, tenantID uuid.UUID) error
+
+	// IsCourseInstructor checks if a user is a co-instructor of a course
+	IsCourseInstructor(ctx context.Context, courseID, userID, tenantID uuid.UUID) (bool, error)
+}
+
+// CoursePolicy decides who can change a course and the modules, lessons, quizzes and
+// assignments that belong to it. Services call it before any change to course content.
+type CoursePolicy interface {
+	// CanManageCourse returns nil if the actor is an admin, the course instructor or a co-instructor
+	CanManageCourse(ctx context.Context, courseID, tenantID uuid.UUID, actor domain.CourseActor) error
+
+	// CanManageInstructors returns nil if the actor is an admin or the course instructor
+	CanManageInstructors(ctx context.Context, courseID, tenantID uuid.UUID, actor domain.CourseActor) error
+
+	// CanTeachCourse returns nil if the actor may create a course taught by instructorID
+	CanTeachCourse(actor domain.CourseActor, instructorID uuid.UUID) error
+}
+
+// TenantMemberReader reads the memberships of users in tenants (satisfied by the auth repository)
+type TenantMemberReader interface {
+	// GetActiveMembership returns the active membership of a user in a tenant, or nil if there is none
+	GetActiveMembership(ctx context.Context, userID, tenantID string) (*authdomain.UserMembership, error)
 }
 
 // CourseCategoryRepository defines the interface for course category data access
@@ -115,22 +147,22 @@ type CourseService interface {
 	ListCourses(ctx context.Context, tenantID uuid.UUID, req *domain.ListCoursesRequest) (*domain.ListCoursesResponse, error)
 
 	// CreateCourse creates a new course
-	CreateCourse(ctx context.Context, tenantID uuid.UUID, req *domain.CreateCourseRequest) (*domain.CourseDetailResponse, error)
+	CreateCourse(ctx context.Context, tenantID uuid.UUID, actor domain.CourseActor, req *domain.CreateCourseRequest) (*domain.CourseDetailResponse, error)
 
 	// UpdateCourse updates an existing course
-	UpdateCourse(ctx context.Context, courseID, tenantID uuid.UUID, req *domain.UpdateCourseRequest) (*domain.CourseDetailResponse, error)
+	UpdateCourse(ctx context.Context, courseID, tenantID uuid.UUID, actor domain.CourseActor, req *domain.UpdateCourseRequest) (*domain.CourseDetailResponse, error)
 
 	// DeleteCourse soft deletes a course
-	DeleteCourse(ctx context.Context, courseID, tenantID uuid.UUID) error
+	DeleteCourse(ctx context.Context, courseID, tenantID uuid.UUID, actor domain.CourseActor) error
 
 	// PublishCourse publishes a course (instructor/admin only)
-	PublishCourse(ctx context.Context, courseID, tenantID uuid.UUID) error
+	PublishCourse(ctx context.Context, courseID, tenantID uuid.UUID, actor domain.CourseActor) error
 
 	// UnpublishCourse unpublishes a course (instructor/admin only)
-	UnpublishCourse(ctx context.Context, courseID, tenantID uuid.UUID) error
+	UnpublishCourse(ctx context.Context, courseID, tenantID uuid.UUID, actor domain.CourseActor) error
 
 	// ArchiveCourse archives a course (instructor/admin only)
-	ArchiveCourse(ctx context.Context, courseID, tenantID uuid.UUID) error
+	ArchiveCourse(ctx context.Context, courseID, tenantID uuid.UUID, actor domain.CourseActor) error
 
 	// GetCoursesByInstructor retrieves all courses by an instructor
 	GetCoursesByInstructor(ctx context.Context, instructorID, tenantID uuid.UUID, page, pageSize int) (*domain.ListCoursesResponse, error)
@@ -149,6 +181,15 @@ type CourseService interface {
 
 	// GetPublishedCourses retrieves all published courses
 	GetPublishedCourses(ctx context.Context, tenantID uuid.UUID, page, pageSize int) (*domain.ListCoursesResponse, error)
+
+	// ListCourseInstructors retrieves the co-instructors of a course
+	ListCourseInstructors(ctx context.Context, courseID, tenantID uuid.UUID) ([]*domain.CourseInstructor, error)
+
+	// AddCourseInstructor adds a co-instructor to a course (course instructor/admin only)
+	AddCourseInstructor(ctx context.Context, courseID, tenantID uuid.UUID, actor domain.CourseActor, req *domain.AddCourseInstructorRequest) (*domain.CourseInstructor, error)
+
+	// RemoveCourseInstructor removes a co-instructor from a course (course instructor/admin only)
+	RemoveCourseInstructor(ctx context.Context, courseID, userID, tenantID uuid.UUID, actor domain.CourseActor) error
 }
 
 // CourseCategoryService defines the business logic interface for category management
@@ -149,6 +149,15 @@ var (
 	ErrInsufficientPermissions = errors.New("insufficient permissions")
 )
 
+// Service errors - Co-instructors
+var (
+	// ErrCourseInstructorNotFound is returned when a user is not a co-instructor of the course
+	ErrCourseInstructorNotFound = errors.New("course co-instructor not found")
+
+	// ErrCourseInstructorExists is returned when a user already teaches the course
+	ErrCourseInstructorExists = errors.New("user already teaches this course")
+)
+
 // Service errors - Validation
 var (
 	// ErrInvalidInput is returned when input validation fails
@@ -196,7 +205,8 @@ func NewCourseError(op string, err error, msg string) *CourseError {
 // IsNotFoundError checks if an error is a not found error
 func IsNotFoundError(err error) bool {
 	return errors.Is(err, ErrCourseNotFound) ||
-		errors.Is(err, ErrCategoryNotFound)
+		errors.Is(err, ErrCategoryNotFound) ||
+		errors.Is(err, ErrCourseInstructorNotFound)
 }
 
 // IsAlreadyExistsError checks if an error is an already exists error
@@ -205,7 +215,8 @@ func IsAlreadyExistsError(err error) bool {
 		errors.Is(err, ErrCourseSlugExists) ||
 		errors.Is(err, ErrCategoryAlreadyExists) ||
 		errors.Is(err, ErrCategorySlugExists) ||
-		errors.Is(err, ErrAlreadyEnrolled)
+		errors.Is(err, ErrAlreadyEnrolled) ||
+		errors.Is(err, ErrCourseInstructorExists)
 }
 
 // IsValidationError checks if an error is a validation error
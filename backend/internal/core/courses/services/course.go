@@ -2,9 +2,11 @@ package services
 
 import (
 	"context"
+	"time"
 
 	"github.com/DanielIturra1610/stegmaier-landing/internal/core/courses/domain"
 	"github.com/DanielIturra1610/stegmaier-landing/internal/core/courses/ports"
+	tenantports "github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/ports"
 	"github.com/google/uuid"
 )
 
@@ -12,16 +14,25 @@ import (
 type CourseServiceImpl struct {
 	courseRepo   ports.CourseRepository
 	categoryRepo ports.CourseCategoryRepository
+	policy       ports.CoursePolicy
+	members      ports.TenantMemberReader
+	quota        tenantports.QuotaService
 }
 
-// NewCourseService creates a new course service instance
+// NewCourseService creates a new course service instance. A nil quota doesn't limit the
+// number of courses.
 func NewCourseService(
 	courseRepo ports.CourseRepository,
 	categoryRepo ports.CourseCategoryRepository,
+	members ports.TenantMemberReader,
+	quota tenantports.QuotaService,
 ) ports.CourseService {
 	return &CourseServiceImpl{
 		courseRepo:   courseRepo,
 		categoryRepo: categoryRepo,
+		policy:       NewCoursePolicy(courseRepo),
+		members:      members,
+		quota:        quota,
 	}
 }
 
@@ -72,12 +83,24 @@ func (s *CourseServiceImpl) ListCourses(ctx context.Context, tenantID uuid.UUID,
 }
 
 // CreateCourse creates a new course
-func (s *CourseServiceImpl) CreateCourse(ctx context.Context, tenantID uuid.UUID, req *domain.CreateCourseRequest) (*domain.CourseDetailResponse, error) {
+func (s *CourseServiceImpl) CreateCourse(ctx context.Context, tenantID uuid.UUID, actor domain.CourseActor, req *domain.CreateCourseRequest) (*domain.CourseDetailResponse, error) {
 	// Validate request
 	if err := req.Validate(); err != nil {
 		return nil, ports.NewCourseError("CreateCourse", err, "invalid course data")
 	}
 
+	// Check that the actor can create a course for the instructor
+	if err := s.policy.CanTeachCourse(actor, req.InstructorID); err != nil {
+		return nil, err
+	}
+
+	// Check the course limit of the tenant plan
+	if s.quota != nil {
+		if err := s.quota.CheckCourseQuota(ctx, tenantID.String()); err != nil {
+			return nil, err
+		}
+	}
+
 	// Check if slug already exists
 	exists, err := s.courseRepo.SlugExists(ctx, req.Slug, tenantID, nil)
 	if err != nil {
@@ -135,7 +158,7 @@ func (s *CourseServiceImpl) CreateCourse(ctx context.Context, tenantID uuid.UUID
 }
 
 // UpdateCourse updates an existing course
-func (s *CourseServiceImpl) UpdateCourse(ctx context.Context, courseID, tenantID uuid.UUID, req *domain.UpdateCourseRequest) (*domain.CourseDetailResponse, error) {
+func (s *CourseServiceImpl) UpdateCourse(ctx context.Context, courseID, tenantID uuid.UUID, actor domain.CourseActor, req *domain.UpdateCourseRequest) (*domain.CourseDetailResponse, error) {
 	// Validate request
 	if err := req.Validate(); err != nil {
 		return nil, ports.NewCourseError("UpdateCourse", err, "invalid course data")
@@ -146,6 +169,11 @@ func (s *CourseServiceImpl) UpdateCourse(ctx context.Context, courseID, tenantID
 		return nil, ports.NewCourseError("UpdateCourse", ports.ErrInvalidInput, "no updates provided")
 	}
 
+	// Check that the actor can change the course
+	if err := s.policy.CanManageCourse(ctx, courseID, tenantID, actor); err != nil {
+		return nil, err
+	}
+
 	// Get existing course
 	course, err := s.courseRepo.GetCourse(ctx, courseID, tenantID)
 	if err != nil {
@@ -219,7 +247,12 @@ func (s *CourseServiceImpl) UpdateCourse(ctx context.Context, courseID, tenantID
 }
 
 // DeleteCourse soft deletes a course
-func (s *CourseServiceImpl) DeleteCourse(ctx context.Context, courseID, tenantID uuid.UUID) error {
+func (s *CourseServiceImpl) DeleteCourse(ctx context.Context, courseID, tenantID uuid.UUID, actor domain.CourseActor) error {
+	// Check that the actor can change the course
+	if err := s.policy.CanManageCourse(ctx, courseID, tenantID, actor); err != nil {
+		return err
+	}
+
 	// Check if course exists
 	course, err := s.courseRepo.GetCourse(ctx, courseID, tenantID)
 	if err != nil {
@@ -247,7 +280,12 @@ func (s *CourseServiceImpl) DeleteCourse(ctx context.Context, courseID, tenantID
 }
 
 // PublishCourse publishes a course (instructor/admin only)
-func (s *CourseServiceImpl) PublishCourse(ctx context.Context, courseID, tenantID uuid.UUID) error {
+func (s *CourseServiceImpl) PublishCourse(ctx context.Context, courseID, tenantID uuid.UUID, actor domain.CourseActor) error {
+	// Check that the actor can change the course
+	if err := s.policy.CanManageCourse(ctx, courseID, tenantID, actor); err != nil {
+		return err
+	}
+
 	// Get course
 	course, err := s.courseRepo.GetCourse(ctx, courseID, tenantID)
 	if err != nil {
@@ -278,7 +316,12 @@ func (s *CourseServiceImpl) PublishCourse(ctx context.Context, courseID, tenantI
 }
 
 // UnpublishCourse unpublishes a course (instructor/admin only)
-func (s *CourseServiceImpl) UnpublishCourse(ctx context.Context, courseID, tenantID uuid.UUID) error {
+func (s *CourseServiceImpl) UnpublishCourse(ctx context.Context, courseID, tenantID uuid.UUID, actor domain.CourseActor) error {
+	// Check that the actor can change the course
+	if err := s.policy.CanManageCourse(ctx, courseID, tenantID, actor); err != nil {
+		return err
+	}
+
 	// Get course
 	course, err := s.courseRepo.GetCourse(ctx, courseID, tenantID)
 	if err != nil {
@@ -304,7 +347,12 @@ func (s *CourseServiceImpl) UnpublishCourse(ctx context.Context, courseID, tenan
 }
 
 // ArchiveCourse archives a course (instructor/admin only)
-func (s *CourseServiceImpl) ArchiveCourse(ctx context.Context, courseID, tenantID uuid.UUID) error {
+func (s *CourseServiceImpl) ArchiveCourse(ctx context.Context, courseID, tenantID uuid.UUID, actor domain.CourseActor) error {
+	// Check that the actor can change the course
+	if err := s.policy.CanManageCourse(ctx, courseID, tenantID, actor); err != nil {
+		return err
+	}
+
 	// Get course
 	course, err := s.courseRepo.GetCourse(ctx, courseID, tenantID)
 	if err != nil {
@@ -471,3 +519,73 @@ func (s *CourseServiceImpl) GetPublishedCourses(ctx context.Context, tenantID uu
 
 	return domain.CoursesToListResponse(courses, total, page, pageSize), nil
 }
+
+// ListCourseInstructors retrieves the co-instructors of a course
+func (s *CourseServiceImpl) ListCourseInstructors(ctx context.Context, courseID, tenantID uuid.UUID) ([]*domain.CourseInstructor, error) {
+	// Check if course exists
+	if _, err := s.courseRepo.GetCourse(ctx, courseID, tenantID); err != nil {
+		return nil, err
+	}
+
+	instructors, err := s.courseRepo.ListCourseInstructors(ctx, courseID, tenantID)
+	if err != nil {
+		return nil, ports.NewCourseError("ListCourseInstructors", err, "failed to list course instructors")
+	}
+
+	return instructors, nil
+}
+
+// AddCourseInstructor adds a co-instructor to a course (course instructor/admin only)
+func (s *CourseServiceImpl) AddCourseInstructor(ctx context.Context, courseID, tenantID uuid.UUID, actor domain.CourseActor, req *domain.AddCourseInstructorRequest) (*domain.CourseInstructor, error) {
+	// Validate request
+	if err := req.Validate(); err != nil {
+		return nil, ports.ErrInvalidInput
+	}
+
+	// Check that the actor can manage the co-instructors
+	if err := s.policy.CanManageInstructors(ctx, courseID, tenantID, actor); err != nil {
+		return nil, err
+	}
+
+	// The course instructor already teaches the course
+	course, err := s.courseRepo.GetCourse(ctx, courseID, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if course.InstructorID == req.UserID {
+		return nil, ports.ErrCourseInstructorExists
+	}
+
+	// Only instructors and admins of the tenant can teach its courses
+	membership, err := s.members.GetActiveMembership(ctx, req.UserID.String(), tenantID.String())
+	if err != nil {
+		return nil, ports.NewCourseError("AddCourseInstructor", err, "failed to get membership")
+	}
+	if membership == nil || !domain.NewCourseActor(req.UserID, membership.Role).CanTeach() {
+		return nil, ports.ErrInvalidInstructor
+	}
+
+	instructor := &domain.CourseInstructor{
+		CourseID:  courseID,
+		TenantID:  tenantID,
+		UserID:    req.UserID,
+		AddedBy:   actor.UserID,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	if err := s.courseRepo.AddCourseInstructor(ctx, instructor); err != nil {
+		return nil, err
+	}
+
+	return instructor, nil
+}
+
+// RemoveCourseInstructor removes a co-instructor from a course (course instructor/admin only)
+func (s *CourseServiceImpl) RemoveCourseInstructor(ctx context.Context, courseID, userID, tenantID uuid.UUID, actor domain.CourseActor) error {
+	// Check that the actor can manage the co-instructors
+	if err := s.policy.CanManageInstructors(ctx, courseID, tenantID, actor); err != nil {
+		return err
+	}
+
+	return s.courseRepo.RemoveCourseInstructor(ctx, courseID, userID, tenantID)
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"context"
+
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/courses/domain"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/courses/ports"
+	"github.com/google/uuid"
+)
+
+// CoursePolicyImpl implements the CoursePolicy interface
+type CoursePolicyImpl struct {
+	courseRepo ports.CourseRepository
+}
+
+// NewCoursePolicy creates a new course policy instance
+func NewCoursePolicy(courseRepo ports.CourseRepository) ports.CoursePolicy {
+	return &CoursePolicyImpl{
+		courseRepo: courseRepo,
+	}
+}
+
+// CanManageCourse checks if the actor can change the course and its content
+func (p *CoursePolicyImpl) CanManageCourse(ctx context.Context, courseID, tenantID uuid.UUID, actor domain.CourseActor) error {
+	if actor.IsAdmin() {
+		return nil
+	}
+
+	course, err := p.courseRepo.GetCourse(ctx, courseID, tenantID)
+	if err != nil {
+		return err
+	}
+
+	if course.InstructorID == actor.UserID {
+		return nil
+	}
+
+	isCoInstructor, err := p.courseRepo.IsCourseInstructor(ctx, courseID, actor.UserID, tenantID)
+	if err != nil {
+		return err
+	}
+	if !isCoInstructor {
+		return ports.ErrNotCourseOwner
+	}
+
+	return nil
+}
+
+// CanManageInstructors checks if the actor can add or remove co-instructors of the course
+func (p *CoursePolicyImpl) CanManageInstructors(ctx context.Context, courseID, tenantID uuid.UUID, actor domain.CourseActor) error {
+	if actor.IsAdmin() {
+		return nil
+	}
+
+	course, err := p.courseRepo.GetCourse(ctx, courseID, tenantID)
+	if err != nil {
+		return err
+	}
+
+	if course.InstructorID != actor.UserID {
+		return ports.ErrNotCourseOwner
+	}
+
+	return nil
+}
+
+// CanTeachCourse checks if the actor can create a course taught by the given instructor.
+// Only admins can create courses on behalf of other instructors.
+func (p *CoursePolicyImpl) CanTeachCourse(actor domain.CourseActor, instructorID uuid.UUID) error {
+	if actor.IsAdmin() || actor.UserID == instructorID {
+		return nil
+	}
+
+	return ports.ErrNotCourseOwner
+}
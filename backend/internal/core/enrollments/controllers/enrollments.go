@@ -3,8 +3,11 @@ package controllers
 import (
 	"strconv"
 
+	authdomain "github.com/DanielIturra1610/stegmaier-landing/internal/core/auth/domain"
 	"github.com/DanielIturra1610/stegmaier-landing/internal/core/enrollments/domain"
 	"github.com/DanielIturra1610/stegmaier-landing/internal/core/enrollments/ports"
+	tenantports "github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/ports"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/middleware"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 )
@@ -106,6 +109,10 @@ func (c *EnrollmentController) EnrollInCourse(ctx *fiber.Ctx) error {
 			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 				"error": "course is not published",
 			})
+		case tenantports.ErrLearnerLimitReached:
+			return ctx.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "the tenant reached the active learner limit of its plan",
+			})
 		default:
 			return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 				"error": "failed to enroll in course",
@@ -1541,6 +1548,10 @@ func (c *EnrollmentController) ApproveEnrollmentRequest(ctx *fiber.Ctx) error {
 			return ctx.Status(fiber.StatusConflict).JSON(fiber.Map{
 				"error": "course is full",
 			})
+		case tenantports.ErrLearnerLimitReached:
+			return ctx.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "the tenant reached the active learner limit of its plan",
+			})
 		default:
 			return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 				"error": "failed to approve enrollment request",
@@ -1739,6 +1750,8 @@ func (c *EnrollmentController) ProcessExpiredEnrollments(ctx *fiber.Ctx) error {
 // RegisterRoutes registers all enrollment routes
 // NOTE: Middlewares (Auth, Tenant, Membership) are applied in server.go before calling this
 func (c *EnrollmentController) RegisterRoutes(router fiber.Router) {
+	manage := middleware.RequirePermission(authdomain.PermissionEnrollmentManage)
+
 	// Student enrollment operations - register directly to inherit middlewares
 	router.Post("/enrollments/enroll", c.EnrollInCourse)
 
@@ -1752,20 +1765,20 @@ func (c *EnrollmentController) RegisterRoutes(router fiber.Router) {
 	router.Post("/enrollments/courses/:courseID/access", c.RecordCourseAccess)
 
 	// Enrollment management (instructor/admin)
-	router.Get("/enrollments/:enrollmentID", c.GetEnrollment)
-	router.Get("/enrollments", c.ListEnrollments)
-	router.Get("/enrollments/courses/:courseID", c.GetCourseEnrollments)
-	router.Put("/enrollments/:enrollmentID/progress", c.UpdateEnrollmentProgress)
-	router.Post("/enrollments/:enrollmentID/complete", c.CompleteEnrollment)
-	router.Post("/enrollments/:enrollmentID/cancel", c.CancelEnrollment)
-	router.Post("/enrollments/:enrollmentID/extend", c.ExtendEnrollment)
-	router.Delete("/enrollments/:enrollmentID", c.DeleteEnrollment)
+	router.Get("/enrollments/:enrollmentID", manage, c.GetEnrollment)
+	router.Get("/enrollments", manage, c.ListEnrollments)
+	router.Get("/enrollments/courses/:courseID", manage, c.GetCourseEnrollments)
+	router.Put("/enrollments/:enrollmentID/progress", manage, c.UpdateEnrollmentProgress)
+	router.Post("/enrollments/:enrollmentID/complete", manage, c.CompleteEnrollment)
+	router.Post("/enrollments/:enrollmentID/cancel", manage, c.CancelEnrollment)
+	router.Post("/enrollments/:enrollmentID/extend", manage, c.ExtendEnrollment)
+	router.Delete("/enrollments/:enrollmentID", manage, c.DeleteEnrollment)
 
 	// Statistics
-	router.Get("/enrollments/courses/:courseID/stats", c.GetCourseEnrollmentStats)
+	router.Get("/enrollments/courses/:courseID/stats", manage, c.GetCourseEnrollmentStats)
 
 	// Bulk operations
-	router.Post("/enrollments/process-expired", c.ProcessExpiredEnrollments)
+	router.Post("/enrollments/process-expired", manage, c.ProcessExpiredEnrollments)
 
 	// Enrollment request operations
 	router.Post("/enrollment-requests", c.RequestEnrollment)
@@ -1773,9 +1786,9 @@ func (c *EnrollmentController) RegisterRoutes(router fiber.Router) {
 	router.Post("/enrollment-requests/:requestID/cancel", c.CancelEnrollmentRequest)
 
 	// Instructor/admin operations
-	router.Get("/enrollment-requests/:requestID", c.GetEnrollmentRequest)
-	router.Get("/enrollment-requests", c.ListEnrollmentRequests)
-	router.Get("/enrollment-requests/courses/:courseID/pending", c.GetPendingEnrollmentRequests)
-	router.Post("/enrollment-requests/:requestID/approve", c.ApproveEnrollmentRequest)
-	router.Post("/enrollment-requests/:requestID/reject", c.RejectEnrollmentRequest)
+	router.Get("/enrollment-requests/:requestID", manage, c.GetEnrollmentRequest)
+	router.Get("/enrollment-requests", manage, c.ListEnrollmentRequests)
+	router.Get("/enrollment-requests/courses/:courseID/pending", manage, c.GetPendingEnrollmentRequests)
+	router.Post("/enrollment-requests/:requestID/approve", manage, c.ApproveEnrollmentRequest)
+	router.Post("/enrollment-requests/:requestID/reject", manage, c.RejectEnrollmentRequest)
 }
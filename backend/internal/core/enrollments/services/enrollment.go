@@ -7,18 +7,22 @@ import (
 
 	"github.com/DanielIturra1610/stegmaier-landing/internal/core/enrollments/domain"
 	"github.com/DanielIturra1610/stegmaier-landing/internal/core/enrollments/ports"
+	tenantports "github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/ports"
 	"github.com/google/uuid"
 )
 
 // EnrollmentService implements the EnrollmentService interface
 type EnrollmentService struct {
-	repo ports.EnrollmentRepository
+	repo  ports.EnrollmentRepository
+	quota tenantports.QuotaService
 }
 
-// NewEnrollmentService creates a new enrollment service
-func NewEnrollmentService(repo ports.EnrollmentRepository) ports.EnrollmentService {
+// NewEnrollmentService creates a new enrollment service. A nil quota doesn't limit the
+// number of active learners.
+func NewEnrollmentService(repo ports.EnrollmentRepository, quota tenantports.QuotaService) ports.EnrollmentService {
 	return &EnrollmentService{
-		repo: repo,
+		repo:  repo,
+		quota: quota,
 	}
 }
 
@@ -47,6 +51,10 @@ func (s *EnrollmentService) EnrollInCourse(ctx context.Context, userID, tenantID
 		return nil, ports.ErrAlreadyEnrolled
 	}
 
+	if err := s.checkLearnerQuota(ctx, userID, tenantID); err != nil {
+		return nil, err
+	}
+
 	// Create enrollment
 	enrollment := domain.NewEnrollment(tenantID, userID, req.CourseID, req.ExpiresAt)
 
@@ -583,6 +591,10 @@ func (s *EnrollmentService) ApproveEnrollmentRequest(ctx context.Context, reques
 		return nil, ports.ErrAlreadyEnrolled
 	}
 
+	if err := s.checkLearnerQuota(ctx, request.UserID, tenantID); err != nil {
+		return nil, err
+	}
+
 	// Approve request
 	request.Approve(reviewerID)
 	if err := s.repo.UpdateEnrollmentRequest(ctx, request); err != nil {
@@ -601,6 +613,21 @@ func (s *EnrollmentService) ApproveEnrollmentRequest(ctx context.Context, reques
 	return domain.EnrollmentToResponse(enrollment), nil
 }
 
+// checkLearnerQuota checks that a user who is not an active learner yet can take a learner
+// seat of the tenant plan
+func (s *EnrollmentService) checkLearnerQuota(ctx context.Context, userID, tenantID uuid.UUID) error {
+	if s.quota == nil {
+		return nil
+	}
+
+	if err := s.quota.CheckLearnerQuota(ctx, tenantID.String(), userID.String()); err != nil {
+		log.Printf("[EnrollmentService] Learner quota check failed: %v", err)
+		return err
+	}
+
+	return nil
+}
+
 // RejectEnrollmentRequest rejects an enrollment request
 func (s *EnrollmentService) RejectEnrollmentRequest(ctx context.Context, requestID, reviewerID, tenantID uuid.UUID, reason string) error {
 	log.Printf("[EnrollmentService] RejectEnrollmentRequest - requestID: %s, reviewerID: %s", requestID, reviewerID)
@@ -0,0 +1,167 @@
+package adapters
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/features/domain"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/features/ports"
+	tenantports "github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/ports"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// PostgresFeatureFlagRepository implements FeatureFlagRepository on the control database
+type PostgresFeatureFlagRepository struct {
+	db *sqlx.DB
+}
+
+// NewPostgresFeatureFlagRepository creates a new feature flag repository
+func NewPostgresFeatureFlagRepository(db *sqlx.DB) ports.FeatureFlagRepository {
+	return &PostgresFeatureFlagRepository{db: db}
+}
+
+const flagColumns = `id, key, COALESCE(description, '') AS description, enabled, rollout_percentage, created_at, updated_at`
+
+// ListFlags retrieves the flags ordered by key
+func (r *PostgresFeatureFlagRepository) ListFlags(ctx context.Context) ([]*domain.FeatureFlag, error) {
+	flags := []*domain.FeatureFlag{}
+	query := `SELECT ` + flagColumns + ` FROM feature_flags ORDER BY key`
+	if err := r.db.SelectContext(ctx, &flags, query); err != nil {
+		return nil, fmt.Errorf("failed to list feature flags: %w", err)
+	}
+	return flags, nil
+}
+
+// GetFlag retrieves a flag by key
+func (r *PostgresFeatureFlagRepository) GetFlag(ctx context.Context, key string) (*domain.FeatureFlag, error) {
+	var flag domain.FeatureFlag
+	query := `SELECT ` + flagColumns + ` FROM feature_flags WHERE key = $1`
+	if err := r.db.GetContext(ctx, &flag, query, key); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ports.ErrFeatureFlagNotFound
+		}
+		return nil, fmt.Errorf("failed to get feature flag: %w", err)
+	}
+	return &flag, nil
+}
+
+// CreateFlag inserts a flag
+func (r *PostgresFeatureFlagRepository) CreateFlag(ctx context.Context, flag *domain.FeatureFlag) error {
+	query := `
+		INSERT INTO feature_flags (id, key, description, enabled, rollout_percentage, created_at, updated_at)
+		VALUES (:id, :key, :description, :enabled, :rollout_percentage, :created_at, :updated_at)
+	`
+
+	if _, err := r.db.NamedExecContext(ctx, query, flag); err != nil {
+		if isUniqueViolation(err) {
+			return ports.ErrFeatureFlagExists
+		}
+		return fmt.Errorf("failed to create feature flag: %w", err)
+	}
+	return nil
+}
+
+// UpdateFlag replaces the description, default and rollout percentage of a flag
+func (r *PostgresFeatureFlagRepository) UpdateFlag(ctx context.Context, flag *domain.FeatureFlag) error {
+	query := `
+		UPDATE feature_flags
+		SET description = :description, enabled = :enabled, rollout_percentage = :rollout_percentage
+		WHERE key = :key
+	`
+
+	result, err := r.db.NamedExecContext(ctx, query, flag)
+	if err != nil {
+		return fmt.Errorf("failed to update feature flag: %w", err)
+	}
+	return requireRow(result, ports.ErrFeatureFlagNotFound)
+}
+
+// DeleteFlag deletes a flag with its tenant overrides
+func (r *PostgresFeatureFlagRepository) DeleteFlag(ctx context.Context, key string) error {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM feature_flags WHERE key = $1", key)
+	if err != nil {
+		return fmt.Errorf("failed to delete feature flag: %w", err)
+	}
+	return requireRow(result, ports.ErrFeatureFlagNotFound)
+}
+
+// ListTenantOverrides retrieves the overrides of a tenant ordered by flag key
+func (r *PostgresFeatureFlagRepository) ListTenantOverrides(ctx context.Context, tenantID string) ([]*domain.TenantFeatureOverride, error) {
+	overrides := []*domain.TenantFeatureOverride{}
+	query := `
+		SELECT f.key AS flag_key, o.tenant_id, o.enabled, o.created_at, o.updated_at
+		FROM tenant_feature_overrides o
+		JOIN feature_flags f ON f.id = o.flag_id
+		WHERE o.tenant_id = $1
+		ORDER BY f.key
+	`
+	if err := r.db.SelectContext(ctx, &overrides, query, tenantID); err != nil {
+		return nil, fmt.Errorf("failed to list tenant feature overrides: %w", err)
+	}
+	return overrides, nil
+}
+
+// SetTenantOverride creates or replaces the override of a flag for a tenant
+func (r *PostgresFeatureFlagRepository) SetTenantOverride(ctx context.Context, override *domain.TenantFeatureOverride) error {
+	query := `
+		INSERT INTO tenant_feature_overrides (flag_id, tenant_id, enabled, created_at, updated_at)
+		SELECT id, $2, $3, $4, $5 FROM feature_flags WHERE key = $1
+		ON CONFLICT (flag_id, tenant_id) DO UPDATE SET enabled = EXCLUDED.enabled
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		override.FlagKey,
+		override.TenantID,
+		override.Enabled,
+		override.CreatedAt,
+		override.UpdatedAt,
+	)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return tenantports.ErrTenantNotFound
+		}
+		return fmt.Errorf("failed to set tenant feature override: %w", err)
+	}
+	return requireRow(result, ports.ErrFeatureFlagNotFound)
+}
+
+// DeleteTenantOverride deletes the override of a flag for a tenant
+func (r *PostgresFeatureFlagRepository) DeleteTenantOverride(ctx context.Context, tenantID, key string) error {
+	query := `
+		DELETE FROM tenant_feature_overrides
+		WHERE tenant_id = $1 AND flag_id = (SELECT id FROM feature_flags WHERE key = $2)
+	`
+
+	result, err := r.db.ExecContext(ctx, query, tenantID, key)
+	if err != nil {
+		return fmt.Errorf("failed to delete tenant feature override: %w", err)
+	}
+	return requireRow(result, ports.ErrFeatureOverrideNotFound)
+}
+
+// requireRow returns notFound if a statement affected no rows
+func requireRow(result sql.Result, notFound error) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return notFound
+	}
+	return nil
+}
+
+// isUniqueViolation checks if an error was caused by a unique constraint
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+// isForeignKeyViolation checks if an error was caused by a foreign key constraint
+func isForeignKeyViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23503"
+}
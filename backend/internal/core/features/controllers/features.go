@@ -0,0 +1,260 @@
+package controllers
+
+import (
+	"errors"
+
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/features/domain"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/features/ports"
+	tenantports "github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/ports"
+	"github.com/gofiber/fiber/v2"
+)
+
+// FeatureFlagController handles feature flag HTTP requests
+type FeatureFlagController struct {
+	featureService ports.FeatureFlagService
+}
+
+// NewFeatureFlagController creates a new feature flag controller
+func NewFeatureFlagController(featureService ports.FeatureFlagService) *FeatureFlagController {
+	return &FeatureFlagController{
+		featureService: featureService,
+	}
+}
+
+// GetCurrentTenantFeatures retrieves the state of the flags for the current tenant
+// @Summary Get current tenant features
+// @Description Get which features are on for the current tenant
+// @Tags features
+// @Produce json
+// @Success 200 {array} domain.FeatureState
+// @Failure 400 {object} fiber.Map
+// @Failure 401 {object} fiber.Map
+// @Router /api/v1/features [get]
+func (c *FeatureFlagController) GetCurrentTenantFeatures(ctx *fiber.Ctx) error {
+	tenantID, ok := ctx.Locals("tenant_id").(string)
+	if !ok || tenantID == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "Tenant ID is required")
+	}
+
+	states, err := c.featureService.GetTenantFeatures(ctx.Context(), tenantID)
+	if err != nil {
+		return featureErrorResponse(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Features retrieved successfully",
+		"data":    states,
+	})
+}
+
+// ListFlags retrieves the feature flags
+// @Summary List feature flags
+// @Description Get the feature flags with their global defaults and rollout percentages (superadmin only)
+// @Tags superadmin
+// @Produce json
+// @Success 200 {array} domain.FeatureFlag
+// @Failure 403 {object} fiber.Map
+// @Router /api/v1/superadmin/features [get]
+func (c *FeatureFlagController) ListFlags(ctx *fiber.Ctx) error {
+	flags, err := c.featureService.ListFlags(ctx.Context())
+	if err != nil {
+		return featureErrorResponse(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Feature flags retrieved successfully",
+		"data":    flags,
+	})
+}
+
+// CreateFlag defines a feature flag
+// @Summary Create feature flag
+// @Description Define a feature flag with its global default and rollout percentage (superadmin only)
+// @Tags superadmin
+// @Accept json
+// @Produce json
+// @Param flag body domain.CreateFeatureFlagDTO true "Flag data"
+// @Success 201 {object} domain.FeatureFlag
+// @Failure 400 {object} fiber.Map
+// @Failure 403 {object} fiber.Map
+// @Failure 409 {object} fiber.Map
+// @Router /api/v1/superadmin/features [post]
+func (c *FeatureFlagController) CreateFlag(ctx *fiber.Ctx) error {
+	var dto domain.CreateFeatureFlagDTO
+	if err := ctx.BodyParser(&dto); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	flag, err := c.featureService.CreateFlag(ctx.Context(), &dto)
+	if err != nil {
+		return featureErrorResponse(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Feature flag created successfully",
+		"data":    flag,
+	})
+}
+
+// UpdateFlag changes the global default and rollout percentage of a feature flag
+// @Summary Update feature flag
+// @Description Change the global default and rollout percentage of a feature flag; they apply to every tenant right away (superadmin only)
+// @Tags superadmin
+// @Accept json
+// @Produce json
+// @Param key path string true "Flag key"
+// @Param flag body domain.UpdateFeatureFlagDTO true "Flag data"
+// @Success 200 {object} domain.FeatureFlag
+// @Failure 400 {object} fiber.Map
+// @Failure 403 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
+// @Router /api/v1/superadmin/features/{key} [put]
+func (c *FeatureFlagController) UpdateFlag(ctx *fiber.Ctx) error {
+	var dto domain.UpdateFeatureFlagDTO
+	if err := ctx.BodyParser(&dto); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	flag, err := c.featureService.UpdateFlag(ctx.Context(), ctx.Params("key"), &dto)
+	if err != nil {
+		return featureErrorResponse(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Feature flag updated successfully",
+		"data":    flag,
+	})
+}
+
+// DeleteFlag deletes a feature flag
+// @Summary Delete feature flag
+// @Description Delete a feature flag with its tenant overrides; what it gates is turned off (superadmin only)
+// @Tags superadmin
+// @Produce json
+// @Param key path string true "Flag key"
+// @Success 200 {object} fiber.Map
+// @Failure 403 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
+// @Router /api/v1/superadmin/features/{key} [delete]
+func (c *FeatureFlagController) DeleteFlag(ctx *fiber.Ctx) error {
+	if err := c.featureService.DeleteFlag(ctx.Context(), ctx.Params("key")); err != nil {
+		return featureErrorResponse(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Feature flag deleted successfully",
+	})
+}
+
+// GetTenantFeatures retrieves the state of the flags for a tenant with its overrides
+// @Summary Get tenant features
+// @Description Get which features are on for a tenant, what decided each one, and the overrides of the tenant (superadmin only)
+// @Tags superadmin
+// @Produce json
+// @Param tenantId path string true "Tenant ID"
+// @Success 200 {object} fiber.Map
+// @Failure 403 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
+// @Router /api/v1/superadmin/tenants/{tenantId}/features [get]
+func (c *FeatureFlagController) GetTenantFeatures(ctx *fiber.Ctx) error {
+	tenantID := ctx.Params("tenantId")
+
+	overrides, err := c.featureService.ListTenantOverrides(ctx.Context(), tenantID)
+	if err != nil {
+		return featureErrorResponse(ctx, err)
+	}
+
+	states, err := c.featureService.GetTenantFeatures(ctx.Context(), tenantID)
+	if err != nil {
+		return featureErrorResponse(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Tenant features retrieved successfully",
+		"data": fiber.Map{
+			"features":  states,
+			"overrides": overrides,
+		},
+	})
+}
+
+// SetTenantOverride turns a feature flag on or off for a tenant
+// @Summary Set tenant feature override
+// @Description Turn a feature flag on or off for a tenant regardless of its default, rollout and the plan of the tenant (superadmin only)
+// @Tags superadmin
+// @Accept json
+// @Produce json
+// @Param tenantId path string true "Tenant ID"
+// @Param key path string true "Flag key"
+// @Param override body domain.TenantFeatureOverrideDTO true "Override"
+// @Success 200 {object} domain.TenantFeatureOverride
+// @Failure 400 {object} fiber.Map
+// @Failure 403 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
+// @Router /api/v1/superadmin/tenants/{tenantId}/features/{key} [put]
+func (c *FeatureFlagController) SetTenantOverride(ctx *fiber.Ctx) error {
+	var dto domain.TenantFeatureOverrideDTO
+	if err := ctx.BodyParser(&dto); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	override, err := c.featureService.SetTenantOverride(ctx.Context(), ctx.Params("tenantId"), ctx.Params("key"), &dto)
+	if err != nil {
+		return featureErrorResponse(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Tenant feature override saved successfully",
+		"data":    override,
+	})
+}
+
+// DeleteTenantOverride makes a tenant follow the default of a feature flag again
+// @Summary Delete tenant feature override
+// @Description Remove the override of a feature flag for a tenant (superadmin only)
+// @Tags superadmin
+// @Produce json
+// @Param tenantId path string true "Tenant ID"
+// @Param key path string true "Flag key"
+// @Success 200 {object} fiber.Map
+// @Failure 403 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
+// @Router /api/v1/superadmin/tenants/{tenantId}/features/{key} [delete]
+func (c *FeatureFlagController) DeleteTenantOverride(ctx *fiber.Ctx) error {
+	if err := c.featureService.DeleteTenantOverride(ctx.Context(), ctx.Params("tenantId"), ctx.Params("key")); err != nil {
+		return featureErrorResponse(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Tenant feature override removed successfully",
+	})
+}
+
+// featureErrorResponse maps feature flag errors to HTTP responses
+func featureErrorResponse(ctx *fiber.Ctx, err error) error {
+	status := fiber.StatusInternalServerError
+	switch {
+	case errors.Is(err, ports.ErrFeatureFlagNotFound), errors.Is(err, ports.ErrFeatureOverrideNotFound),
+		errors.Is(err, tenantports.ErrTenantNotFound):
+		status = fiber.StatusNotFound
+	case errors.Is(err, ports.ErrFeatureFlagExists):
+		status = fiber.StatusConflict
+	case errors.Is(err, ports.ErrFeatureFlagInvalid):
+		status = fiber.StatusBadRequest
+	case errors.Is(err, ports.ErrFeatureDisabled):
+		status = fiber.StatusForbidden
+	}
+
+	return ctx.Status(status).JSON(fiber.Map{
+		"success": false,
+		"message": err.Error(),
+	})
+}
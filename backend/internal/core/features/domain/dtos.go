@@ -0,0 +1,21 @@
+package domain
+
+// CreateFeatureFlagDTO represents the request to create a flag
+type CreateFeatureFlagDTO struct {
+	Key               string `json:"key" validate:"required,min=2,max=100"`
+	Description       string `json:"description" validate:"max=500"`
+	Enabled           bool   `json:"enabled"`
+	RolloutPercentage int    `json:"rollout_percentage" validate:"min=0,max=100"`
+}
+
+// UpdateFeatureFlagDTO represents the request to change the default and rollout of a flag
+type UpdateFeatureFlagDTO struct {
+	Description       string `json:"description" validate:"max=500"`
+	Enabled           bool   `json:"enabled"`
+	RolloutPercentage int    `json:"rollout_percentage" validate:"min=0,max=100"`
+}
+
+// TenantFeatureOverrideDTO represents the request to turn a flag on or off for a tenant
+type TenantFeatureOverrideDTO struct {
+	Enabled bool `json:"enabled"`
+}
@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"hash/fnv"
+	"time"
+)
+
+// Keys of the flags that gate subsystems. They match the plan features of the same name.
+const (
+	FeaturePeerReview   = "peer_review"
+	FeatureReviews      = "reviews"
+	FeatureCertificates = "certificates"
+)
+
+// Sources of the state of a flag for a tenant
+const (
+	FeatureSourceOverride = "override"
+	FeatureSourcePlan     = "plan"
+	FeatureSourceDefault  = "default"
+	FeatureSourceRollout  = "rollout"
+)
+
+// FeatureFlag represents a flag with its global default and rollout percentage
+type FeatureFlag struct {
+	ID                string    `json:"id" db:"id"`
+	Key               string    `json:"key" db:"key"`
+	Description       string    `json:"description,omitempty" db:"description"`
+	Enabled           bool      `json:"enabled" db:"enabled"`
+	RolloutPercentage int       `json:"rollout_percentage" db:"rollout_percentage"`
+	CreatedAt         time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// RolloutBucket places a tenant in one of 100 buckets. The bucket is stable for a flag and
+// tenant, so raising the percentage only adds tenants, and differs between flags, so the same
+// tenants don't always get new features first.
+func (f *FeatureFlag) RolloutBucket(tenantID string) int {
+	h := fnv.New32a()
+	h.Write([]byte(f.Key + ":" + tenantID))
+	return int(h.Sum32() % 100)
+}
+
+// DefaultFor returns the state of the flag for a tenant without overrides, and its source
+func (f *FeatureFlag) DefaultFor(tenantID string) (bool, string) {
+	if f.Enabled {
+		return true, FeatureSourceDefault
+	}
+	if f.RolloutPercentage > 0 {
+		return f.RolloutBucket(tenantID) < f.RolloutPercentage, FeatureSourceRollout
+	}
+	return false, FeatureSourceDefault
+}
+
+// TenantFeatureOverride represents a flag turned on or off for a single tenant
+type TenantFeatureOverride struct {
+	FlagKey   string    `json:"flag_key" db:"flag_key"`
+	TenantID  string    `json:"tenant_id" db:"tenant_id"`
+	Enabled   bool      `json:"enabled" db:"enabled"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// FeatureState represents whether a flag is on for a tenant and what decided it
+type FeatureState struct {
+	Key     string `json:"key"`
+	Enabled bool   `json:"enabled"`
+	Source  string `json:"source"`
+}
@@ -0,0 +1,12 @@
+package ports
+
+import "errors"
+
+// Feature flag errors
+var (
+	ErrFeatureFlagNotFound     = errors.New("feature flag not found")
+	ErrFeatureFlagExists       = errors.New("feature flag already exists")
+	ErrFeatureFlagInvalid      = errors.New("invalid feature flag")
+	ErrFeatureOverrideNotFound = errors.New("feature flag override not found")
+	ErrFeatureDisabled         = errors.New("feature is not enabled for this tenant")
+)
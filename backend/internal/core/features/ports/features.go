@@ -0,0 +1,49 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/features/domain"
+	tenantdomain "github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/domain"
+)
+
+// FeatureFlagRepository defines the persistence of flags and tenant overrides in the control DB
+type FeatureFlagRepository interface {
+	ListFlags(ctx context.Context) ([]*domain.FeatureFlag, error)
+	GetFlag(ctx context.Context, key string) (*domain.FeatureFlag, error)
+	CreateFlag(ctx context.Context, flag *domain.FeatureFlag) error
+	UpdateFlag(ctx context.Context, flag *domain.FeatureFlag) error
+	DeleteFlag(ctx context.Context, key string) error
+
+	// ListTenantOverrides returns the overrides of a tenant
+	ListTenantOverrides(ctx context.Context, tenantID string) ([]*domain.TenantFeatureOverride, error)
+	// SetTenantOverride creates or replaces the override of a flag for a tenant
+	SetTenantOverride(ctx context.Context, override *domain.TenantFeatureOverride) error
+	DeleteTenantOverride(ctx context.Context, tenantID, key string) error
+}
+
+// TenantPlanReader reads the plan of a tenant, nil if it has none
+type TenantPlanReader interface {
+	GetTenantPlan(ctx context.Context, tenantID string) (*tenantdomain.TenantPlan, error)
+}
+
+// FeatureFlagService defines the management and evaluation of feature flags
+type FeatureFlagService interface {
+	// IsEnabled checks if a flag is on for a tenant. Unknown flags are off.
+	IsEnabled(ctx context.Context, tenantID, key string) (bool, error)
+	// RequireFeature returns ErrFeatureDisabled if a flag is off for a tenant
+	RequireFeature(ctx context.Context, tenantID, key string) error
+	// GetTenantFeatures returns the state of every flag for a tenant
+	GetTenantFeatures(ctx context.Context, tenantID string) ([]domain.FeatureState, error)
+
+	// Flags (superadmin only)
+	ListFlags(ctx context.Context) ([]*domain.FeatureFlag, error)
+	CreateFlag(ctx context.Context, dto *domain.CreateFeatureFlagDTO) (*domain.FeatureFlag, error)
+	UpdateFlag(ctx context.Context, key string, dto *domain.UpdateFeatureFlagDTO) (*domain.FeatureFlag, error)
+	DeleteFlag(ctx context.Context, key string) error
+
+	// Tenant overrides (superadmin only)
+	ListTenantOverrides(ctx context.Context, tenantID string) ([]*domain.TenantFeatureOverride, error)
+	SetTenantOverride(ctx context.Context, tenantID, key string, dto *domain.TenantFeatureOverrideDTO) (*domain.TenantFeatureOverride, error)
+	DeleteTenantOverride(ctx context.Context, tenantID, key string) error
+}
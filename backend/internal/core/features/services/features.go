@@ -0,0 +1,291 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/features/domain"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/features/ports"
+	tenantdomain "github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/domain"
+	tenantports "github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/ports"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/shared/cache"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+// featureCacheTTL bounds how long a tenant keeps the flag states it was evaluated with. Flag and
+// override changes invalidate the cache right away; plan changes apply when it expires.
+const featureCacheTTL = 5 * time.Minute
+
+// featureKeyPattern restricts flag keys to the identifiers used by the code
+var featureKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// FeatureFlagService implements the FeatureFlagService interface
+type FeatureFlagService struct {
+	repo      ports.FeatureFlagRepository
+	plans     ports.TenantPlanReader
+	cache     *cache.CacheHelper
+	validator *validator.Validate
+}
+
+// NewFeatureFlagService creates a new feature flag service. The evaluated flags of each tenant
+// are cached when a cache is given; without one every check reads the control database.
+func NewFeatureFlagService(repo ports.FeatureFlagRepository, plans ports.TenantPlanReader, c cache.Cache) ports.FeatureFlagService {
+	s := &FeatureFlagService{
+		repo:      repo,
+		plans:     plans,
+		validator: validator.New(),
+	}
+	if c != nil {
+		s.cache = cache.NewCacheHelper(c)
+	}
+	return s
+}
+
+// tenantFeaturesKey is the cache key of the evaluated flags of a tenant
+func tenantFeaturesKey(tenantID string) string { return "features:tenant:" + tenantID }
+
+// ============================================================
+// Evaluation
+// ============================================================
+
+// IsEnabled checks if a flag is on for a tenant. Unknown flags are off.
+func (s *FeatureFlagService) IsEnabled(ctx context.Context, tenantID, key string) (bool, error) {
+	states, err := s.GetTenantFeatures(ctx, tenantID)
+	if err != nil {
+		return false, err
+	}
+
+	for _, state := range states {
+		if state.Key == key {
+			return state.Enabled, nil
+		}
+	}
+	return false, nil
+}
+
+// RequireFeature returns ErrFeatureDisabled if a flag is off for a tenant
+func (s *FeatureFlagService) RequireFeature(ctx context.Context, tenantID, key string) error {
+	enabled, err := s.IsEnabled(ctx, tenantID, key)
+	if err != nil {
+		return err
+	}
+	if !enabled {
+		return ports.ErrFeatureDisabled
+	}
+	return nil
+}
+
+// GetTenantFeatures returns the state of every flag for a tenant
+func (s *FeatureFlagService) GetTenantFeatures(ctx context.Context, tenantID string) ([]domain.FeatureState, error) {
+	var states []domain.FeatureState
+	if s.cache != nil && s.cache.GetJSON(ctx, tenantFeaturesKey(tenantID), &states) == nil {
+		return states, nil
+	}
+
+	flags, err := s.repo.ListFlags(ctx)
+	if err != nil {
+		return nil, err
+	}
+	overrides, err := s.repo.ListTenantOverrides(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := s.plans.GetTenantPlan(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	states = evaluateFeatures(tenantID, flags, overrides, plan)
+
+	if s.cache != nil {
+		if err := s.cache.SetJSON(ctx, tenantFeaturesKey(tenantID), states, featureCacheTTL); err != nil {
+			log.Printf("⚠️  [FeatureFlagService] Failed to cache features of tenant %s: %v", tenantID, err)
+		}
+	}
+	return states, nil
+}
+
+// evaluateFeatures decides the state of every flag for a tenant. A tenant override wins; then
+// flags named after a plan feature are off if the plan of the tenant doesn't include it; then
+// the global default and rollout percentage of the flag apply.
+func evaluateFeatures(tenantID string, flags []*domain.FeatureFlag, overrides []*domain.TenantFeatureOverride, plan *tenantdomain.TenantPlan) []domain.FeatureState {
+	overridden := make(map[string]bool, len(overrides))
+	for _, override := range overrides {
+		overridden[override.FlagKey] = override.Enabled
+	}
+
+	states := make([]domain.FeatureState, 0, len(flags))
+	for _, flag := range flags {
+		state := domain.FeatureState{Key: flag.Key}
+
+		if enabled, ok := overridden[flag.Key]; ok {
+			state.Enabled, state.Source = enabled, domain.FeatureSourceOverride
+		} else if plan != nil && tenantdomain.IsValidPlanFeature(flag.Key) && !plan.HasFeature(flag.Key) {
+			state.Enabled, state.Source = false, domain.FeatureSourcePlan
+		} else {
+			state.Enabled, state.Source = flag.DefaultFor(tenantID)
+		}
+
+		states = append(states, state)
+	}
+	return states
+}
+
+// invalidate drops cached evaluations, of one tenant or of every tenant if tenantID is empty
+func (s *FeatureFlagService) invalidate(ctx context.Context, tenantID string) {
+	if s.cache == nil {
+		return
+	}
+
+	var err error
+	if tenantID == "" {
+		err = s.cache.InvalidatePattern(ctx, tenantFeaturesKey("*"))
+	} else {
+		err = s.cache.InvalidateMultiple(ctx, tenantFeaturesKey(tenantID))
+	}
+	if err != nil {
+		log.Printf("⚠️  [FeatureFlagService] Failed to invalidate cached features: %v", err)
+	}
+}
+
+// ============================================================
+// Flags
+// ============================================================
+
+// ListFlags lists the flags with their defaults (superadmin only)
+func (s *FeatureFlagService) ListFlags(ctx context.Context) ([]*domain.FeatureFlag, error) {
+	return s.repo.ListFlags(ctx)
+}
+
+// CreateFlag defines a flag (superadmin only)
+func (s *FeatureFlagService) CreateFlag(ctx context.Context, dto *domain.CreateFeatureFlagDTO) (*domain.FeatureFlag, error) {
+	if err := s.validator.Struct(dto); err != nil {
+		return nil, fmt.Errorf("%w: %v", ports.ErrFeatureFlagInvalid, err)
+	}
+
+	key := strings.ToLower(strings.TrimSpace(dto.Key))
+	if !featureKeyPattern.MatchString(key) {
+		return nil, fmt.Errorf("%w: key must contain only lowercase letters, digits and underscores", ports.ErrFeatureFlagInvalid)
+	}
+
+	now := time.Now()
+	flag := &domain.FeatureFlag{
+		ID:                uuid.New().String(),
+		Key:               key,
+		Description:       strings.TrimSpace(dto.Description),
+		Enabled:           dto.Enabled,
+		RolloutPercentage: dto.RolloutPercentage,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+
+	if err := s.repo.CreateFlag(ctx, flag); err != nil {
+		return nil, err
+	}
+
+	s.invalidate(ctx, "")
+	log.Printf("🚩 [FeatureFlagService] Flag %q created (enabled: %v, rollout: %d%%)", flag.Key, flag.Enabled, flag.RolloutPercentage)
+	return flag, nil
+}
+
+// UpdateFlag changes the default and rollout percentage of a flag (superadmin only)
+func (s *FeatureFlagService) UpdateFlag(ctx context.Context, key string, dto *domain.UpdateFeatureFlagDTO) (*domain.FeatureFlag, error) {
+	if err := s.validator.Struct(dto); err != nil {
+		return nil, fmt.Errorf("%w: %v", ports.ErrFeatureFlagInvalid, err)
+	}
+
+	flag, err := s.repo.GetFlag(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	flag.Description = strings.TrimSpace(dto.Description)
+	flag.Enabled = dto.Enabled
+	flag.RolloutPercentage = dto.RolloutPercentage
+	flag.UpdatedAt = time.Now()
+
+	if err := s.repo.UpdateFlag(ctx, flag); err != nil {
+		return nil, err
+	}
+
+	s.invalidate(ctx, "")
+	log.Printf("🚩 [FeatureFlagService] Flag %q updated (enabled: %v, rollout: %d%%)", flag.Key, flag.Enabled, flag.RolloutPercentage)
+	return flag, nil
+}
+
+// DeleteFlag deletes a flag with its tenant overrides (superadmin only). Unknown flags are off,
+// so whatever the flag gates is turned off for every tenant.
+func (s *FeatureFlagService) DeleteFlag(ctx context.Context, key string) error {
+	if err := s.repo.DeleteFlag(ctx, key); err != nil {
+		return err
+	}
+
+	s.invalidate(ctx, "")
+	log.Printf("🚩 [FeatureFlagService] Flag %q deleted", key)
+	return nil
+}
+
+// ============================================================
+// Tenant overrides
+// ============================================================
+
+// ListTenantOverrides lists the flags turned on or off for a tenant (superadmin only)
+func (s *FeatureFlagService) ListTenantOverrides(ctx context.Context, tenantID string) ([]*domain.TenantFeatureOverride, error) {
+	if _, err := uuid.Parse(tenantID); err != nil {
+		return nil, tenantports.ErrTenantNotFound
+	}
+
+	return s.repo.ListTenantOverrides(ctx, tenantID)
+}
+
+// SetTenantOverride turns a flag on or off for a tenant regardless of its default, rollout and
+// the plan of the tenant (superadmin only)
+func (s *FeatureFlagService) SetTenantOverride(ctx context.Context, tenantID, key string, dto *domain.TenantFeatureOverrideDTO) (*domain.TenantFeatureOverride, error) {
+	if _, err := uuid.Parse(tenantID); err != nil {
+		return nil, tenantports.ErrTenantNotFound
+	}
+
+	now := time.Now()
+	override := &domain.TenantFeatureOverride{
+		FlagKey:   key,
+		TenantID:  tenantID,
+		Enabled:   dto.Enabled,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := s.repo.SetTenantOverride(ctx, override); err != nil {
+		return nil, err
+	}
+
+	s.invalidate(ctx, tenantID)
+	log.Printf("🚩 [FeatureFlagService] Flag %q turned %s for tenant %s", key, onOff(dto.Enabled), tenantID)
+	return override, nil
+}
+
+// DeleteTenantOverride makes a tenant follow the default of a flag again (superadmin only)
+func (s *FeatureFlagService) DeleteTenantOverride(ctx context.Context, tenantID, key string) error {
+	if _, err := uuid.Parse(tenantID); err != nil {
+		return tenantports.ErrTenantNotFound
+	}
+
+	if err := s.repo.DeleteTenantOverride(ctx, tenantID, key); err != nil {
+		return err
+	}
+
+	s.invalidate(ctx, tenantID)
+	log.Printf("🚩 [FeatureFlagService] Override of flag %q removed for tenant %s", key, tenantID)
+	return nil
+}
+
+func onOff(enabled bool) string {
+	if enabled {
+		return "on"
+	}
+	return "off"
+}
@@ -0,0 +1,167 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/features/domain"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/features/ports"
+	tenantdomain "github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/domain"
+)
+
+const featureTenantID = "11111111-1111-1111-1111-111111111111"
+
+// stubFeatureRepository serves fixed flags and overrides; the other methods are not used
+type stubFeatureRepository struct {
+	ports.FeatureFlagRepository
+	flags     []*domain.FeatureFlag
+	overrides []*domain.TenantFeatureOverride
+}
+
+func (r *stubFeatureRepository) ListFlags(ctx context.Context) ([]*domain.FeatureFlag, error) {
+	return r.flags, nil
+}
+
+func (r *stubFeatureRepository) ListTenantOverrides(ctx context.Context, tenantID string) ([]*domain.TenantFeatureOverride, error) {
+	return r.overrides, nil
+}
+
+type stubPlanReader struct {
+	plan *tenantdomain.TenantPlan
+}
+
+func (r *stubPlanReader) GetTenantPlan(ctx context.Context, tenantID string) (*tenantdomain.TenantPlan, error) {
+	return r.plan, nil
+}
+
+func TestEvaluateFeatures(t *testing.T) {
+	flags := []*domain.FeatureFlag{
+		{Key: domain.FeatureCertificates, Enabled: true},
+		{Key: domain.FeatureReviews, Enabled: true},
+		{Key: "new_editor", Enabled: false},
+		{Key: "full_rollout", RolloutPercentage: 100},
+	}
+
+	tests := []struct {
+		name      string
+		overrides []*domain.TenantFeatureOverride
+		plan      *tenantdomain.TenantPlan
+		expected  map[string]domain.FeatureState
+	}{
+		{
+			name: "Global defaults without plan",
+			expected: map[string]domain.FeatureState{
+				domain.FeatureCertificates: {Enabled: true, Source: domain.FeatureSourceDefault},
+				domain.FeatureReviews:      {Enabled: true, Source: domain.FeatureSourceDefault},
+				"new_editor":               {Enabled: false, Source: domain.FeatureSourceDefault},
+				"full_rollout":             {Enabled: true, Source: domain.FeatureSourceRollout},
+			},
+		},
+		{
+			name: "Plan without a plan feature",
+			plan: &tenantdomain.TenantPlan{Features: []string{tenantdomain.PlanFeatureReviews}},
+			expected: map[string]domain.FeatureState{
+				domain.FeatureCertificates: {Enabled: false, Source: domain.FeatureSourcePlan},
+				domain.FeatureReviews:      {Enabled: true, Source: domain.FeatureSourceDefault},
+				"new_editor":               {Enabled: false, Source: domain.FeatureSourceDefault},
+			},
+		},
+		{
+			name: "Overrides win over plan and defaults",
+			overrides: []*domain.TenantFeatureOverride{
+				{FlagKey: domain.FeatureCertificates, Enabled: true},
+				{FlagKey: "new_editor", Enabled: true},
+				{FlagKey: "full_rollout", Enabled: false},
+			},
+			plan: &tenantdomain.TenantPlan{},
+			expected: map[string]domain.FeatureState{
+				domain.FeatureCertificates: {Enabled: true, Source: domain.FeatureSourceOverride},
+				domain.FeatureReviews:      {Enabled: false, Source: domain.FeatureSourcePlan},
+				"new_editor":               {Enabled: true, Source: domain.FeatureSourceOverride},
+				"full_rollout":             {Enabled: false, Source: domain.FeatureSourceOverride},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			states := evaluateFeatures(featureTenantID, flags, tt.overrides, tt.plan)
+			if len(states) != len(flags) {
+				t.Fatalf("Expected %d states, got %d", len(flags), len(states))
+			}
+
+			for _, state := range states {
+				expected, ok := tt.expected[state.Key]
+				if !ok {
+					continue
+				}
+				if state.Enabled != expected.Enabled || state.Source != expected.Source {
+					t.Errorf("%s: expected enabled=%v source=%s, got enabled=%v source=%s",
+						state.Key, expected.Enabled, expected.Source, state.Enabled, state.Source)
+				}
+			}
+		})
+	}
+}
+
+func TestFeatureFlagRolloutIsStable(t *testing.T) {
+	flag := &domain.FeatureFlag{Key: "new_editor"}
+
+	enabledAt := func(percentage int) map[string]bool {
+		flag.RolloutPercentage = percentage
+		enabled := make(map[string]bool)
+		for i := 0; i < 1000; i++ {
+			tenantID := fmt.Sprintf("tenant-%d", i)
+			if on, _ := flag.DefaultFor(tenantID); on {
+				enabled[tenantID] = true
+			}
+		}
+		return enabled
+	}
+
+	if got := len(enabledAt(0)); got != 0 {
+		t.Errorf("Expected no tenants at 0%%, got %d", got)
+	}
+	if got := len(enabledAt(100)); got != 1000 {
+		t.Errorf("Expected every tenant at 100%%, got %d", got)
+	}
+
+	quarter := enabledAt(25)
+	if len(quarter) < 150 || len(quarter) > 350 {
+		t.Errorf("Expected about 250 tenants at 25%%, got %d", len(quarter))
+	}
+
+	// Raising the percentage keeps the tenants that already had the feature
+	half := enabledAt(50)
+	for tenantID := range quarter {
+		if !half[tenantID] {
+			t.Errorf("Expected %s to keep the feature when the rollout grows", tenantID)
+		}
+	}
+}
+
+func TestFeatureFlagServiceRequireFeature(t *testing.T) {
+	repo := &stubFeatureRepository{flags: []*domain.FeatureFlag{
+		{Key: domain.FeaturePeerReview, Enabled: true},
+		{Key: "new_editor", Enabled: false},
+	}}
+	service := NewFeatureFlagService(repo, &stubPlanReader{}, nil)
+
+	tests := []struct {
+		key     string
+		wantErr error
+	}{
+		{key: domain.FeaturePeerReview, wantErr: nil},
+		{key: "new_editor", wantErr: ports.ErrFeatureDisabled},
+		{key: "unknown", wantErr: ports.ErrFeatureDisabled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if err := service.RequireFeature(context.Background(), featureTenantID, tt.key); err != tt.wantErr {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package ports
 import (
 	"context"
 
+	coursedomain "github.com/DanielIturra1610/stegmaier-landing/internal/core/courses/domain"
 	"github.com/DanielIturra1610/stegmaier-landing/internal/core/lessons/domain"
 	"github.com/google/uuid"
 )
@@ -42,11 +43,12 @@ type LessonRepository interface {
 // LessonService defines the interface for lesson business logic
 type LessonService interface {
 	// Lesson management (instructor/admin)
-	CreateLesson(ctx context.Context, tenantID uuid.UUID, req *domain.CreateLessonRequest) (*domain.LessonDetailResponse, error)
-	UpdateLesson(ctx context.Context, lessonID, tenantID uuid.UUID, req *domain.UpdateLessonRequest) (*domain.LessonDetailResponse, error)
-	UpdateLessonVideo(ctx context.Context, lessonID, tenantID, mediaID uuid.UUID, videoURL string) (*domain.LessonDetailResponse, error)
-	DeleteLesson(ctx context.Context, lessonID, tenantID uuid.UUID) error
-	ReorderLessons(ctx context.Context, courseID, tenantID uuid.UUID, req *domain.ReorderLessonsRequest) error
+	CreateLesson(ctx context.Context, tenantID uuid.UUID, actor coursedomain.CourseActor, req *domain.CreateLessonRequest) (*domain.LessonDetailResponse, error)
+	UpdateLesson(ctx context.Context, lessonID, tenantID uuid.UUID, actor coursedomain.CourseActor, req *domain.UpdateLessonRequest) (*domain.LessonDetailResponse, error)
+	UpdateLessonVideo(ctx context.Context, lessonID, tenantID, mediaID uuid.UUID, actor coursedomain.CourseActor, videoURL string) (*domain.LessonDetailResponse, error)
+	DeleteLesson(ctx context.Context, lessonID, tenantID uuid.UUID, actor coursedomain.CourseActor) error
+	ReorderLessons(ctx context.Context, courseID, tenantID uuid.UUID, actor coursedomain.CourseActor, req *domain.ReorderLessonsRequest) error
+	CanManageLesson(ctx context.Context, lessonID, tenantID uuid.UUID, actor coursedomain.CourseActor) error
 
 	// Lesson retrieval (public/authenticated)
 	GetLesson(ctx context.Context, lessonID, tenantID uuid.UUID, userID *uuid.UUID) (*domain.LessonDetailResponse, error)
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"time"
 
+	coursedomain "github.com/DanielIturra1610/stegmaier-landing/internal/core/courses/domain"
+	courseports "github.com/DanielIturra1610/stegmaier-landing/internal/core/courses/ports"
 	"github.com/DanielIturra1610/stegmaier-landing/internal/core/lessons/domain"
 	"github.com/DanielIturra1610/stegmaier-landing/internal/core/lessons/ports"
 	moduleports "github.com/DanielIturra1610/stegmaier-landing/internal/core/modules/ports"
@@ -13,28 +15,32 @@ import (
 
 // LessonServiceImpl implements the LessonService interface
 type LessonServiceImpl struct {
-	lessonRepo ports.LessonRepository
-	moduleRepo moduleports.ModuleRepository
+	lessonRepo   ports.LessonRepository
+	moduleRepo   moduleports.ModuleRepository
+	coursePolicy courseports.CoursePolicy
 	// TODO: Add CourseRepository when implementing enrollment checks
 }
 
 // NewLessonService creates a new lesson service instance
-func NewLessonService(lessonRepo ports.LessonRepository, moduleRepo moduleports.ModuleRepository) ports.LessonService {
+func NewLessonService(lessonRepo ports.LessonRepository, moduleRepo moduleports.ModuleRepository, coursePolicy courseports.CoursePolicy) ports.LessonService {
 	return &LessonServiceImpl{
-		lessonRepo: lessonRepo,
-		moduleRepo: moduleRepo,
+		lessonRepo:   lessonRepo,
+		moduleRepo:   moduleRepo,
+		coursePolicy: coursePolicy,
 	}
 }
 
 // CreateLesson creates a new lesson
-func (s *LessonServiceImpl) CreateLesson(ctx context.Context, tenantID uuid.UUID, req *domain.CreateLessonRequest) (*domain.LessonDetailResponse, error) {
+func (s *LessonServiceImpl) CreateLesson(ctx context.Context, tenantID uuid.UUID, actor coursedomain.CourseActor, req *domain.CreateLessonRequest) (*domain.LessonDetailResponse, error) {
 	// Validate request
 	if err := req.Validate(); err != nil {
 		return nil, ports.NewLessonError("CreateLesson", err, "invalid lesson data")
 	}
 
-	// TODO: Validate that course exists and instructor has access
-	// This will require CourseRepository dependency
+	// Validate that course exists and the user can change it
+	if err := s.coursePolicy.CanManageCourse(ctx, req.CourseID, tenantID, actor); err != nil {
+		return nil, err
+	}
 
 	// Get max order index if not provided
 	if req.OrderIndex == 0 {
@@ -91,7 +97,7 @@ func (s *LessonServiceImpl) CreateLesson(ctx context.Context, tenantID uuid.UUID
 }
 
 // UpdateLesson updates an existing lesson
-func (s *LessonServiceImpl) UpdateLesson(ctx context.Context, lessonID, tenantID uuid.UUID, req *domain.UpdateLessonRequest) (*domain.LessonDetailResponse, error) {
+func (s *LessonServiceImpl) UpdateLesson(ctx context.Context, lessonID, tenantID uuid.UUID, actor coursedomain.CourseActor, req *domain.UpdateLessonRequest) (*domain.LessonDetailResponse, error) {
 	// Validate request
 	if err := req.Validate(); err != nil {
 		return nil, ports.NewLessonError("UpdateLesson", err, "invalid lesson data")
@@ -108,8 +114,10 @@ func (s *LessonServiceImpl) UpdateLesson(ctx context.Context, lessonID, tenantID
 		return nil, ports.NewLessonError("UpdateLesson", ports.ErrLessonDeleted, "lesson has been deleted")
 	}
 
-	// TODO: Validate that instructor has access to this lesson
-	// This will require CourseRepository to check ownership
+	// Validate that the user can change the course of the lesson
+	if err := s.coursePolicy.CanManageCourse(ctx, lesson.CourseID, tenantID, actor); err != nil {
+		return nil, err
+	}
 
 	// Update fields if provided
 	if req.ModuleID != nil {
@@ -175,7 +183,7 @@ func (s *LessonServiceImpl) UpdateLesson(ctx context.Context, lessonID, tenantID
 }
 
 // UpdateLessonVideo updates a lesson with uploaded video information
-func (s *LessonServiceImpl) UpdateLessonVideo(ctx context.Context, lessonID, tenantID, mediaID uuid.UUID, videoURL string) (*domain.LessonDetailResponse, error) {
+func (s *LessonServiceImpl) UpdateLessonVideo(ctx context.Context, lessonID, tenantID, mediaID uuid.UUID, actor coursedomain.CourseActor, videoURL string) (*domain.LessonDetailResponse, error) {
 	// Get existing lesson
 	lesson, err := s.lessonRepo.GetByID(ctx, lessonID, tenantID)
 	if err != nil {
@@ -187,6 +195,11 @@ func (s *LessonServiceImpl) UpdateLessonVideo(ctx context.Context, lessonID, ten
 		return nil, ports.NewLessonError("UpdateLessonVideo", ports.ErrLessonDeleted, "lesson has been deleted")
 	}
 
+	// Validate that the user can change the course of the lesson
+	if err := s.coursePolicy.CanManageCourse(ctx, lesson.CourseID, tenantID, actor); err != nil {
+		return nil, err
+	}
+
 	// Update media_id and video_url
 	lesson.MediaID = &mediaID
 	lesson.VideoURL = &videoURL
@@ -206,18 +219,12 @@ func (s *LessonServiceImpl) UpdateLessonVideo(ctx context.Context, lessonID, ten
 }
 
 // DeleteLesson soft deletes a lesson
-func (s *LessonServiceImpl) DeleteLesson(ctx context.Context, lessonID, tenantID uuid.UUID) error {
-	// Check if lesson exists
-	exists, err := s.lessonRepo.Exists(ctx, lessonID, tenantID)
-	if err != nil {
-		return ports.NewLessonError("DeleteLesson", err, "failed to check lesson existence")
-	}
-	if !exists {
-		return ports.NewLessonError("DeleteLesson", ports.ErrLessonNotFound, "lesson not found")
+func (s *LessonServiceImpl) DeleteLesson(ctx context.Context, lessonID, tenantID uuid.UUID, actor coursedomain.CourseActor) error {
+	// Validate that the lesson exists and the user can change its course
+	if err := s.CanManageLesson(ctx, lessonID, tenantID, actor); err != nil {
+		return err
 	}
 
-	// TODO: Validate that instructor has access to this lesson
-
 	// Delete lesson
 	if err := s.lessonRepo.Delete(ctx, lessonID, tenantID); err != nil {
 		return ports.NewLessonError("DeleteLesson", ports.ErrLessonDeletionFailed, err.Error())
@@ -227,13 +234,16 @@ func (s *LessonServiceImpl) DeleteLesson(ctx context.Context, lessonID, tenantID
 }
 
 // ReorderLessons reorders lessons within a course
-func (s *LessonServiceImpl) ReorderLessons(ctx context.Context, courseID, tenantID uuid.UUID, req *domain.ReorderLessonsRequest) error {
+func (s *LessonServiceImpl) ReorderLessons(ctx context.Context, courseID, tenantID uuid.UUID, actor coursedomain.CourseActor, req *domain.ReorderLessonsRequest) error {
 	// Validate request
 	if err := req.Validate(); err != nil {
 		return ports.NewLessonError("ReorderLessons", err, "invalid reorder request")
 	}
 
-	// TODO: Validate that instructor has access to this course
+	// Validate that the user can change the course
+	if err := s.coursePolicy.CanManageCourse(ctx, courseID, tenantID, actor); err != nil {
+		return err
+	}
 
 	// Verify all lessons belong to the course
 	for _, order := range req.LessonOrders {
@@ -254,6 +264,19 @@ func (s *LessonServiceImpl) ReorderLessons(ctx context.Context, courseID, tenant
 	return nil
 }
 
+// CanManageLesson checks that a lesson exists and the user can change its course
+func (s *LessonServiceImpl) CanManageLesson(ctx context.Context, lessonID, tenantID uuid.UUID, actor coursedomain.CourseActor) error {
+	lesson, err := s.lessonRepo.GetByID(ctx, lessonID, tenantID)
+	if err != nil {
+		return err
+	}
+	if lesson.DeletedAt != nil {
+		return ports.ErrLessonNotFound
+	}
+
+	return s.coursePolicy.CanManageCourse(ctx, lesson.CourseID, tenantID, actor)
+}
+
 // GetLesson retrieves a lesson by ID
 func (s *LessonServiceImpl) GetLesson(ctx context.Context, lessonID, tenantID uuid.UUID, userID *uuid.UUID) (*domain.LessonDetailResponse, error) {
 	// Get lesson
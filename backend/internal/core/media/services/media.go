@@ -1,11 +1,13 @@
 package services
 
 import (
+	"context"
 	"fmt"
 	"io"
 
 	"github.com/DanielIturra1610/stegmaier-landing/internal/core/media/domain"
 	"github.com/DanielIturra1610/stegmaier-landing/internal/core/media/ports"
+	tenantports "github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/ports"
 	"github.com/google/uuid"
 )
 
@@ -13,13 +15,16 @@ import (
 type MediaService struct {
 	repo    ports.MediaRepository
 	storage ports.StorageService
+	quota   tenantports.QuotaService
 }
 
-// NewMediaService crea una nueva instancia del servicio
-func NewMediaService(repo ports.MediaRepository, storage ports.StorageService) ports.MediaService {
+// NewMediaService crea una nueva instancia del servicio. Con quota nil no se limita el
+// almacenamiento del tenant.
+func NewMediaService(repo ports.MediaRepository, storage ports.StorageService, quota tenantports.QuotaService) ports.MediaService {
 	return &MediaService{
 		repo:    repo,
 		storage: storage,
+		quota:   quota,
 	}
 }
 
@@ -35,6 +40,13 @@ func (s *MediaService) UploadMedia(req domain.UploadMediaRequest, fileReader io.
 		return nil, domain.ErrInvalidMimeType
 	}
 
+	// Verificar que el archivo cabe en el almacenamiento del plan del tenant
+	if s.quota != nil {
+		if err := s.quota.CheckStorageQuota(context.Background(), req.TenantID.String(), req.FileSize); err != nil {
+			return nil, err
+		}
+	}
+
 	// Generar nombre único de archivo
 	fileName := s.storage.GenerateFileName(req.OriginalName)
 
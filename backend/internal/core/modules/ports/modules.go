@@ -1,6 +1,7 @@
 package ports
 
 import (
+	coursedomain "github.com/DanielIturra1610/stegmaier-landing/internal/core/courses/domain"
 	"github.com/DanielIturra1610/stegmaier-landing/internal/core/modules/domain"
 	"github.com/google/uuid"
 )
@@ -36,20 +37,20 @@ type ModuleRepository interface {
 // ModuleService define la lógica de negocio para Modules
 type ModuleService interface {
 	// CRUD operations
-	CreateModule(tenantID, userID uuid.UUID, req domain.CreateModuleRequest) (*domain.ModuleResponse, error)
+	CreateModule(tenantID uuid.UUID, actor coursedomain.CourseActor, req domain.CreateModuleRequest) (*domain.ModuleResponse, error)
 	GetModule(tenantID, moduleID uuid.UUID) (*domain.ModuleResponse, error)
 	GetModuleWithLessons(tenantID, moduleID uuid.UUID) (*domain.ModuleWithLessonsResponse, error)
 	GetCourseModules(tenantID, courseID uuid.UUID) (*domain.ModuleListResponse, error)
 	GetCourseModulesWithProgress(tenantID, courseID, userID uuid.UUID) (*domain.CourseModulesResponse, error)
-	UpdateModule(tenantID, userID, moduleID uuid.UUID, req domain.UpdateModuleRequest) (*domain.ModuleResponse, error)
-	DeleteModule(tenantID, userID, moduleID uuid.UUID) error
+	UpdateModule(tenantID uuid.UUID, actor coursedomain.CourseActor, moduleID uuid.UUID, req domain.UpdateModuleRequest) (*domain.ModuleResponse, error)
+	DeleteModule(tenantID uuid.UUID, actor coursedomain.CourseActor, moduleID uuid.UUID) error
 
 	// Publishing operations
-	PublishModule(tenantID, userID, moduleID uuid.UUID) (*domain.ModuleResponse, error)
-	UnpublishModule(tenantID, userID, moduleID uuid.UUID) (*domain.ModuleResponse, error)
+	PublishModule(tenantID uuid.UUID, actor coursedomain.CourseActor, moduleID uuid.UUID) (*domain.ModuleResponse, error)
+	UnpublishModule(tenantID uuid.UUID, actor coursedomain.CourseActor, moduleID uuid.UUID) (*domain.ModuleResponse, error)
 
 	// Ordering operations
-	ReorderModules(tenantID, userID, courseID uuid.UUID, req domain.ReorderModulesRequest) error
+	ReorderModules(tenantID uuid.UUID, actor coursedomain.CourseActor, courseID uuid.UUID, req domain.ReorderModulesRequest) error
 
 	// Progress operations
 	GetModuleProgress(tenantID, moduleID, userID uuid.UUID) (*domain.ModuleProgressResponse, error)
@@ -1,6 +1,10 @@
 package services
 
 import (
+	"context"
+
+	coursedomain "github.com/DanielIturra1610/stegmaier-landing/internal/core/courses/domain"
+	courseports "github.com/DanielIturra1610/stegmaier-landing/internal/core/courses/ports"
 	"github.com/DanielIturra1610/stegmaier-landing/internal/core/modules/domain"
 	"github.com/DanielIturra1610/stegmaier-landing/internal/core/modules/ports"
 	"github.com/google/uuid"
@@ -8,22 +12,28 @@ import (
 
 // ModuleService implementa la lógica de negocio para Modules
 type ModuleService struct {
-	repo ports.ModuleRepository
+	repo         ports.ModuleRepository
+	coursePolicy courseports.CoursePolicy
 }
 
 // NewModuleService crea una nueva instancia del servicio
-func NewModuleService(repo ports.ModuleRepository) ports.ModuleService {
+func NewModuleService(repo ports.ModuleRepository, coursePolicy courseports.CoursePolicy) ports.ModuleService {
 	return &ModuleService{
-		repo: repo,
+		repo:         repo,
+		coursePolicy: coursePolicy,
 	}
 }
 
 // CreateModule crea un nuevo módulo
-func (s *ModuleService) CreateModule(tenantID, userID uuid.UUID, req domain.CreateModuleRequest) (*domain.ModuleResponse, error) {
+func (s *ModuleService) CreateModule(tenantID uuid.UUID, actor coursedomain.CourseActor, req domain.CreateModuleRequest) (*domain.ModuleResponse, error) {
 	if err := req.Validate(); err != nil {
 		return nil, err
 	}
 
+	if err := s.checkCourseAccess(tenantID, req.CourseID, actor); err != nil {
+		return nil, err
+	}
+
 	// Si no se especifica orden, obtener el siguiente
 	if req.Order == 0 {
 		maxOrder, err := s.repo.GetMaxOrder(tenantID, req.CourseID)
@@ -42,7 +52,7 @@ func (s *ModuleService) CreateModule(tenantID, userID uuid.UUID, req domain.Crea
 		Order:       req.Order,
 		IsPublished: req.IsPublished,
 		Duration:    req.Duration,
-		CreatedBy:   userID,
+		CreatedBy:   actor.UserID,
 	}
 
 	if err := s.repo.Create(module); err != nil {
@@ -135,7 +145,7 @@ func (s *ModuleService) GetCourseModulesWithProgress(tenantID, courseID, userID
 }
 
 // UpdateModule actualiza un módulo
-func (s *ModuleService) UpdateModule(tenantID, userID, moduleID uuid.UUID, req domain.UpdateModuleRequest) (*domain.ModuleResponse, error) {
+func (s *ModuleService) UpdateModule(tenantID uuid.UUID, actor coursedomain.CourseActor, moduleID uuid.UUID, req domain.UpdateModuleRequest) (*domain.ModuleResponse, error) {
 	if err := req.Validate(); err != nil {
 		return nil, err
 	}
@@ -145,6 +155,10 @@ func (s *ModuleService) UpdateModule(tenantID, userID, moduleID uuid.UUID, req d
 		return nil, err
 	}
 
+	if err := s.checkCourseAccess(tenantID, module.CourseID, actor); err != nil {
+		return nil, err
+	}
+
 	// Actualizar campos
 	if req.Title != nil {
 		module.Title = *req.Title
@@ -171,7 +185,16 @@ func (s *ModuleService) UpdateModule(tenantID, userID, moduleID uuid.UUID, req d
 }
 
 // DeleteModule elimina un módulo
-func (s *ModuleService) DeleteModule(tenantID, userID, moduleID uuid.UUID) error {
+func (s *ModuleService) DeleteModule(tenantID uuid.UUID, actor coursedomain.CourseActor, moduleID uuid.UUID) error {
+	module, err := s.repo.GetByID(tenantID, moduleID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.checkCourseAccess(tenantID, module.CourseID, actor); err != nil {
+		return err
+	}
+
 	// Verificar que no tenga lecciones
 	lessonCount, err := s.repo.CountLessonsByModuleID(tenantID, moduleID)
 	if err != nil {
@@ -186,12 +209,16 @@ func (s *ModuleService) DeleteModule(tenantID, userID, moduleID uuid.UUID) error
 }
 
 // PublishModule publica un módulo
-func (s *ModuleService) PublishModule(tenantID, userID, moduleID uuid.UUID) (*domain.ModuleResponse, error) {
+func (s *ModuleService) PublishModule(tenantID uuid.UUID, actor coursedomain.CourseActor, moduleID uuid.UUID) (*domain.ModuleResponse, error) {
 	module, err := s.repo.GetByID(tenantID, moduleID)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := s.checkCourseAccess(tenantID, module.CourseID, actor); err != nil {
+		return nil, err
+	}
+
 	module.IsPublished = true
 	if err := s.repo.Update(module); err != nil {
 		return nil, err
@@ -202,12 +229,16 @@ func (s *ModuleService) PublishModule(tenantID, userID, moduleID uuid.UUID) (*do
 }
 
 // UnpublishModule despublica un módulo
-func (s *ModuleService) UnpublishModule(tenantID, userID, moduleID uuid.UUID) (*domain.ModuleResponse, error) {
+func (s *ModuleService) UnpublishModule(tenantID uuid.UUID, actor coursedomain.CourseActor, moduleID uuid.UUID) (*domain.ModuleResponse, error) {
 	module, err := s.repo.GetByID(tenantID, moduleID)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := s.checkCourseAccess(tenantID, module.CourseID, actor); err != nil {
+		return nil, err
+	}
+
 	module.IsPublished = false
 	if err := s.repo.Update(module); err != nil {
 		return nil, err
@@ -218,11 +249,15 @@ func (s *ModuleService) UnpublishModule(tenantID, userID, moduleID uuid.UUID) (*
 }
 
 // ReorderModules reordena los módulos de un curso
-func (s *ModuleService) ReorderModules(tenantID, userID, courseID uuid.UUID, req domain.ReorderModulesRequest) error {
+func (s *ModuleService) ReorderModules(tenantID uuid.UUID, actor coursedomain.CourseActor, courseID uuid.UUID, req domain.ReorderModulesRequest) error {
 	if err := req.Validate(); err != nil {
 		return err
 	}
 
+	if err := s.checkCourseAccess(tenantID, courseID, actor); err != nil {
+		return err
+	}
+
 	return s.repo.ReorderModules(tenantID, courseID, req.ModuleOrders)
 }
 
@@ -284,6 +319,11 @@ func (s *ModuleService) UpdateModuleProgress(tenantID, moduleID, userID uuid.UUI
 // Helper Methods
 // ============================================================
 
+// checkCourseAccess verifica que el usuario pueda modificar el curso al que pertenecen los módulos
+func (s *ModuleService) checkCourseAccess(tenantID, courseID uuid.UUID, actor coursedomain.CourseActor) error {
+	return s.coursePolicy.CanManageCourse(context.Background(), courseID, tenantID, actor)
+}
+
 // toResponse convierte un módulo a ModuleResponse
 func (s *ModuleService) toResponse(module *domain.Module, lessonCount int) *domain.ModuleResponse {
 	return &domain.ModuleResponse{
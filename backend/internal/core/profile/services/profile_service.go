@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"fmt"
 
 	authPorts "github.com/DanielIturra1610/stegmaier-landing/internal/core/auth/ports"
 	"github.com/DanielIturra1610/stegmaier-landing/internal/core/profile/domain"
@@ -20,11 +21,12 @@ func stringPtrValue(ptr *string) string {
 
 // ProfileServiceImpl implements the ProfileService interface
 type ProfileServiceImpl struct {
-	profileRepo  ports.ProfileRepository
-	authRepo     authPorts.AuthRepository
-	userRepo     authPorts.UserRepository
-	fileStorage  ports.FileStorageService
-	passwordHash hasher.PasswordHasher
+	profileRepo    ports.ProfileRepository
+	authRepo       authPorts.AuthRepository
+	userRepo       authPorts.UserRepository
+	fileStorage    ports.FileStorageService
+	passwordHash   hasher.PasswordHasher
+	passwordPolicy authPorts.PasswordPolicyService
 }
 
 // NewProfileService creates a new profile service instance
@@ -34,13 +36,15 @@ func NewProfileService(
 	userRepo authPorts.UserRepository,
 	fileStorage ports.FileStorageService,
 	passwordHash hasher.PasswordHasher,
+	passwordPolicy authPorts.PasswordPolicyService,
 ) ports.ProfileService {
 	return &ProfileServiceImpl{
-		profileRepo:  profileRepo,
-		authRepo:     authRepo,
-		userRepo:     userRepo,
-		fileStorage:  fileStorage,
-		passwordHash: passwordHash,
+		profileRepo:    profileRepo,
+		authRepo:       authRepo,
+		userRepo:       userRepo,
+		fileStorage:    fileStorage,
+		passwordHash:   passwordHash,
+		passwordPolicy: passwordPolicy,
 	}
 }
 
@@ -123,6 +127,11 @@ func (s *ProfileServiceImpl) ChangePassword(ctx context.Context, userID, tenantI
 		return ports.NewProfileError("ChangePassword", ports.ErrInvalidPassword, "current password is incorrect")
 	}
 
+	// Enforce the tenant password policy (policy errors are returned as-is, like in AuthService)
+	if err := s.passwordPolicy.ValidatePassword(ctx, tenantID.String(), user.ID, req.NewPassword); err != nil {
+		return err
+	}
+
 	// Hash new password
 	newPasswordHash, err := s.passwordHash.Hash(req.NewPassword)
 	if err != nil {
@@ -134,6 +143,10 @@ func (s *ProfileServiceImpl) ChangePassword(ctx context.Context, userID, tenantI
 		return ports.NewProfileError("ChangePassword", ports.ErrUpdateFailed, err.Error())
 	}
 
+	if err := s.passwordPolicy.RecordPasswordChange(ctx, user.ID, newPasswordHash); err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+
 	return nil
 }
 
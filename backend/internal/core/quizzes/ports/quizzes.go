@@ -3,6 +3,7 @@ package ports
 import (
 	"context"
 
+	coursedomain "github.com/DanielIturra1610/stegmaier-landing/internal/core/courses/domain"
 	"github.com/DanielIturra1610/stegmaier-landing/internal/core/quizzes/domain"
 	"github.com/google/uuid"
 )
@@ -61,21 +62,21 @@ type QuizRepository interface {
 // QuizService defines the interface for quiz business logic
 type QuizService interface {
 	// Quiz management
-	CreateQuiz(ctx context.Context, tenantID uuid.UUID, req *domain.CreateQuizRequest) (*domain.QuizResponse, error)
-	UpdateQuiz(ctx context.Context, quizID, tenantID uuid.UUID, req *domain.UpdateQuizRequest) (*domain.QuizResponse, error)
-	DeleteQuiz(ctx context.Context, quizID, tenantID uuid.UUID) error
+	CreateQuiz(ctx context.Context, tenantID uuid.UUID, actor coursedomain.CourseActor, req *domain.CreateQuizRequest) (*domain.QuizResponse, error)
+	UpdateQuiz(ctx context.Context, quizID, tenantID uuid.UUID, actor coursedomain.CourseActor, req *domain.UpdateQuizRequest) (*domain.QuizResponse, error)
+	DeleteQuiz(ctx context.Context, quizID, tenantID uuid.UUID, actor coursedomain.CourseActor) error
 	GetQuiz(ctx context.Context, quizID, tenantID uuid.UUID, includeQuestions bool) (interface{}, error)
 	GetQuizzesByCourse(ctx context.Context, courseID, tenantID uuid.UUID, page, pageSize int) (*domain.PaginatedQuizResponse, error)
 	GetQuizByLesson(ctx context.Context, lessonID, tenantID uuid.UUID) (*domain.QuizDetailResponse, error)
 
 	// Question management
-	CreateQuestion(ctx context.Context, tenantID uuid.UUID, req *domain.CreateQuestionRequest) (*domain.QuestionDetailResponse, error)
-	UpdateQuestion(ctx context.Context, questionID, tenantID uuid.UUID, req *domain.UpdateQuestionRequest) (*domain.QuestionResponse, error)
-	DeleteQuestion(ctx context.Context, questionID, tenantID uuid.UUID) error
-	ReorderQuestions(ctx context.Context, quizID, tenantID uuid.UUID, req *domain.ReorderQuestionsRequest) error
+	CreateQuestion(ctx context.Context, tenantID uuid.UUID, actor coursedomain.CourseActor, req *domain.CreateQuestionRequest) (*domain.QuestionDetailResponse, error)
+	UpdateQuestion(ctx context.Context, questionID, tenantID uuid.UUID, actor coursedomain.CourseActor, req *domain.UpdateQuestionRequest) (*domain.QuestionResponse, error)
+	DeleteQuestion(ctx context.Context, questionID, tenantID uuid.UUID, actor coursedomain.CourseActor) error
+	ReorderQuestions(ctx context.Context, quizID, tenantID uuid.UUID, actor coursedomain.CourseActor, req *domain.ReorderQuestionsRequest) error
 
 	// Question option management
-	UpdateQuestionOption(ctx context.Context, optionID, tenantID uuid.UUID, req *domain.UpdateQuestionOptionRequest) (*domain.QuestionOptionResponse, error)
+	UpdateQuestionOption(ctx context.Context, optionID, tenantID uuid.UUID, actor coursedomain.CourseActor, req *domain.UpdateQuestionOptionRequest) (*domain.QuestionOptionResponse, error)
 
 	// Quiz taking
 	StartQuizAttempt(ctx context.Context, userID, tenantID uuid.UUID, req *domain.StartQuizRequest) (*domain.QuizAttemptResponse, error)
@@ -6,6 +6,8 @@ import (
 	"math"
 	"time"
 
+	coursedomain "github.com/DanielIturra1610/stegmaier-landing/internal/core/courses/domain"
+	courseports "github.com/DanielIturra1610/stegmaier-landing/internal/core/courses/ports"
 	"github.com/DanielIturra1610/stegmaier-landing/internal/core/quizzes/domain"
 	"github.com/DanielIturra1610/stegmaier-landing/internal/core/quizzes/ports"
 	"github.com/google/uuid"
@@ -13,13 +15,15 @@ import (
 
 // QuizServiceImpl implements the QuizService interface
 type QuizServiceImpl struct {
-	quizRepo ports.QuizRepository
+	quizRepo     ports.QuizRepository
+	coursePolicy courseports.CoursePolicy
 }
 
 // NewQuizService creates a new quiz service
-func NewQuizService(quizRepo ports.QuizRepository) ports.QuizService {
+func NewQuizService(quizRepo ports.QuizRepository, coursePolicy courseports.CoursePolicy) ports.QuizService {
 	return &QuizServiceImpl{
-		quizRepo: quizRepo,
+		quizRepo:     quizRepo,
+		coursePolicy: coursePolicy,
 	}
 }
 
@@ -27,11 +31,16 @@ func NewQuizService(quizRepo ports.QuizRepository) ports.QuizService {
 // Quiz management
 // ============================================================================
 
-func (s *QuizServiceImpl) CreateQuiz(ctx context.Context, tenantID uuid.UUID, req *domain.CreateQuizRequest) (*domain.QuizResponse, error) {
+func (s *QuizServiceImpl) CreateQuiz(ctx context.Context, tenantID uuid.UUID, actor coursedomain.CourseActor, req *domain.CreateQuizRequest) (*domain.QuizResponse, error) {
 	if err := req.Validate(); err != nil {
 		return nil, ports.NewQuizError("CreateQuiz", err, "invalid quiz data")
 	}
 
+	// Validate that the user can change the course
+	if err := s.coursePolicy.CanManageCourse(ctx, req.CourseID, tenantID, actor); err != nil {
+		return nil, err
+	}
+
 	quiz := &domain.Quiz{
 		ID:                 uuid.New(),
 		TenantID:           tenantID,
@@ -60,7 +69,7 @@ func (s *QuizServiceImpl) CreateQuiz(ctx context.Context, tenantID uuid.UUID, re
 	return response, nil
 }
 
-func (s *QuizServiceImpl) UpdateQuiz(ctx context.Context, quizID, tenantID uuid.UUID, req *domain.UpdateQuizRequest) (*domain.QuizResponse, error) {
+func (s *QuizServiceImpl) UpdateQuiz(ctx context.Context, quizID, tenantID uuid.UUID, actor coursedomain.CourseActor, req *domain.UpdateQuizRequest) (*domain.QuizResponse, error) {
 	if err := req.Validate(); err != nil {
 		return nil, ports.NewQuizError("UpdateQuiz", err, "invalid quiz data")
 	}
@@ -71,6 +80,11 @@ func (s *QuizServiceImpl) UpdateQuiz(ctx context.Context, quizID, tenantID uuid.
 		return nil, ports.NewQuizError("UpdateQuiz", err, "failed to get quiz")
 	}
 
+	// Validate that the user can change the course of the quiz
+	if err := s.coursePolicy.CanManageCourse(ctx, quiz.CourseID, tenantID, actor); err != nil {
+		return nil, err
+	}
+
 	// Update fields
 	if req.Title != nil {
 		quiz.Title = *req.Title
@@ -114,7 +128,11 @@ func (s *QuizServiceImpl) UpdateQuiz(ctx context.Context, quizID, tenantID uuid.
 	return response, nil
 }
 
-func (s *QuizServiceImpl) DeleteQuiz(ctx context.Context, quizID, tenantID uuid.UUID) error {
+func (s *QuizServiceImpl) DeleteQuiz(ctx context.Context, quizID, tenantID uuid.UUID, actor coursedomain.CourseActor) error {
+	if err := s.checkQuizAccess(ctx, "DeleteQuiz", quizID, tenantID, actor); err != nil {
+		return err
+	}
+
 	if err := s.quizRepo.DeleteQuiz(ctx, quizID, tenantID); err != nil {
 		return ports.NewQuizError("DeleteQuiz", err, "failed to delete quiz")
 	}
@@ -246,11 +264,15 @@ func (s *QuizServiceImpl) GetQuizByLesson(ctx context.Context, lessonID, tenantI
 // Question management
 // ============================================================================
 
-func (s *QuizServiceImpl) CreateQuestion(ctx context.Context, tenantID uuid.UUID, req *domain.CreateQuestionRequest) (*domain.QuestionDetailResponse, error) {
+func (s *QuizServiceImpl) CreateQuestion(ctx context.Context, tenantID uuid.UUID, actor coursedomain.CourseActor, req *domain.CreateQuestionRequest) (*domain.QuestionDetailResponse, error) {
 	if err := req.Validate(); err != nil {
 		return nil, ports.NewQuizError("CreateQuestion", err, "invalid question data")
 	}
 
+	if err := s.checkQuizAccess(ctx, "CreateQuestion", req.QuizID, tenantID, actor); err != nil {
+		return nil, err
+	}
+
 	// Get max order index if not provided
 	if req.OrderIndex == 0 {
 		maxOrder, err := s.quizRepo.GetMaxQuestionOrderIndex(ctx, req.QuizID, tenantID)
@@ -314,7 +336,7 @@ func (s *QuizServiceImpl) CreateQuestion(ctx context.Context, tenantID uuid.UUID
 	return response, nil
 }
 
-func (s *QuizServiceImpl) UpdateQuestion(ctx context.Context, questionID, tenantID uuid.UUID, req *domain.UpdateQuestionRequest) (*domain.QuestionResponse, error) {
+func (s *QuizServiceImpl) UpdateQuestion(ctx context.Context, questionID, tenantID uuid.UUID, actor coursedomain.CourseActor, req *domain.UpdateQuestionRequest) (*domain.QuestionResponse, error) {
 	if err := req.Validate(); err != nil {
 		return nil, ports.NewQuizError("UpdateQuestion", err, "invalid question data")
 	}
@@ -325,6 +347,10 @@ func (s *QuizServiceImpl) UpdateQuestion(ctx context.Context, questionID, tenant
 		return nil, ports.NewQuizError("UpdateQuestion", err, "failed to get question")
 	}
 
+	if err := s.checkQuizAccess(ctx, "UpdateQuestion", question.QuizID, tenantID, actor); err != nil {
+		return nil, err
+	}
+
 	// Update fields
 	if req.Text != nil {
 		question.Text = *req.Text
@@ -350,7 +376,16 @@ func (s *QuizServiceImpl) UpdateQuestion(ctx context.Context, questionID, tenant
 	return response, nil
 }
 
-func (s *QuizServiceImpl) DeleteQuestion(ctx context.Context, questionID, tenantID uuid.UUID) error {
+func (s *QuizServiceImpl) DeleteQuestion(ctx context.Context, questionID, tenantID uuid.UUID, actor coursedomain.CourseActor) error {
+	question, err := s.quizRepo.GetQuestionByID(ctx, questionID, tenantID)
+	if err != nil {
+		return ports.NewQuizError("DeleteQuestion", err, "failed to get question")
+	}
+
+	if err := s.checkQuizAccess(ctx, "DeleteQuestion", question.QuizID, tenantID, actor); err != nil {
+		return err
+	}
+
 	// Delete options first
 	if err := s.quizRepo.DeleteOptionsByQuestionID(ctx, questionID, tenantID); err != nil {
 		return ports.NewQuizError("DeleteQuestion", err, "failed to delete options")
@@ -364,11 +399,15 @@ func (s *QuizServiceImpl) DeleteQuestion(ctx context.Context, questionID, tenant
 	return nil
 }
 
-func (s *QuizServiceImpl) ReorderQuestions(ctx context.Context, quizID, tenantID uuid.UUID, req *domain.ReorderQuestionsRequest) error {
+func (s *QuizServiceImpl) ReorderQuestions(ctx context.Context, quizID, tenantID uuid.UUID, actor coursedomain.CourseActor, req *domain.ReorderQuestionsRequest) error {
 	if len(req.Orders) == 0 {
 		return ports.NewQuizError("ReorderQuestions", ports.ErrInvalidOrderIndex, "no orders provided")
 	}
 
+	if err := s.checkQuizAccess(ctx, "ReorderQuestions", quizID, tenantID, actor); err != nil {
+		return err
+	}
+
 	if err := s.quizRepo.ReorderQuestions(ctx, quizID, tenantID, req.Orders); err != nil {
 		return ports.NewQuizError("ReorderQuestions", ports.ErrReorderFailed, err.Error())
 	}
@@ -380,13 +419,22 @@ func (s *QuizServiceImpl) ReorderQuestions(ctx context.Context, quizID, tenantID
 // Question option management
 // ============================================================================
 
-func (s *QuizServiceImpl) UpdateQuestionOption(ctx context.Context, optionID, tenantID uuid.UUID, req *domain.UpdateQuestionOptionRequest) (*domain.QuestionOptionResponse, error) {
+func (s *QuizServiceImpl) UpdateQuestionOption(ctx context.Context, optionID, tenantID uuid.UUID, actor coursedomain.CourseActor, req *domain.UpdateQuestionOptionRequest) (*domain.QuestionOptionResponse, error) {
 	// Get existing option
 	option, err := s.quizRepo.GetOptionByID(ctx, optionID, tenantID)
 	if err != nil {
 		return nil, ports.NewQuizError("UpdateQuestionOption", err, "failed to get option")
 	}
 
+	question, err := s.quizRepo.GetQuestionByID(ctx, option.QuestionID, tenantID)
+	if err != nil {
+		return nil, ports.NewQuizError("UpdateQuestionOption", err, "failed to get question")
+	}
+
+	if err := s.checkQuizAccess(ctx, "UpdateQuestionOption", question.QuizID, tenantID, actor); err != nil {
+		return nil, err
+	}
+
 	// Update fields
 	if req.Text != nil {
 		option.Text = *req.Text
@@ -802,3 +850,13 @@ func (s *QuizServiceImpl) GetQuizAttempts(ctx context.Context, quizID, tenantID
 		TotalPages: totalPages,
 	}, nil
 }
+
+// checkQuizAccess validates that the quiz exists and the user can change its course
+func (s *QuizServiceImpl) checkQuizAccess(ctx context.Context, op string, quizID, tenantID uuid.UUID, actor coursedomain.CourseActor) error {
+	quiz, err := s.quizRepo.GetQuizByID(ctx, quizID, tenantID)
+	if err != nil {
+		return ports.NewQuizError(op, err, "failed to get quiz")
+	}
+
+	return s.coursePolicy.CanManageCourse(ctx, quiz.CourseID, tenantID, actor)
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"time"
 
+	featuredomain "github.com/DanielIturra1610/stegmaier-landing/internal/core/features/domain"
+	featureports "github.com/DanielIturra1610/stegmaier-landing/internal/core/features/ports"
 	"github.com/DanielIturra1610/stegmaier-landing/internal/core/reviews/domain"
 	"github.com/DanielIturra1610/stegmaier-landing/internal/core/reviews/ports"
 	"github.com/google/uuid"
@@ -20,23 +22,37 @@ type CourseRatingSync interface {
 type ReviewService struct {
 	repo             ports.ReviewRepository
 	courseRatingSync CourseRatingSync
+	features         featureports.FeatureFlagService
 	// TODO: Add EnrollmentRepository for validation
 }
 
 // NewReviewService crea una nueva instancia del servicio
-func NewReviewService(repo ports.ReviewRepository, courseRatingSync CourseRatingSync) ports.ReviewService {
+func NewReviewService(repo ports.ReviewRepository, courseRatingSync CourseRatingSync, features featureports.FeatureFlagService) ports.ReviewService {
 	return &ReviewService{
 		repo:             repo,
 		courseRatingSync: courseRatingSync,
+		features:         features,
 	}
 }
 
+// requireReviews verifica que el feature flag de reviews esté activo para el tenant
+func (s *ReviewService) requireReviews(tenantID uuid.UUID) error {
+	if s.features == nil {
+		return nil
+	}
+	return s.features.RequireFeature(context.Background(), tenantID.String(), featuredomain.FeatureReviews)
+}
+
 // ============================================================================
 // CRUD Operations
 // ============================================================================
 
 // CreateReview crea una nueva review
 func (s *ReviewService) CreateReview(tenantID, userID uuid.UUID, req domain.CreateReviewRequest) (*domain.ReviewResponse, error) {
+	if err := s.requireReviews(tenantID); err != nil {
+		return nil, err
+	}
+
 	// Validate request
 	if err := req.Validate(); err != nil {
 		return nil, err
@@ -84,6 +100,10 @@ func (s *ReviewService) CreateReview(tenantID, userID uuid.UUID, req domain.Crea
 
 // GetReview obtiene una review por ID
 func (s *ReviewService) GetReview(tenantID, reviewID uuid.UUID) (*domain.ReviewResponse, error) {
+	if err := s.requireReviews(tenantID); err != nil {
+		return nil, err
+	}
+
 	review, err := s.repo.GetByID(tenantID, reviewID)
 	if err != nil {
 		return nil, err
@@ -101,6 +121,10 @@ func (s *ReviewService) GetReview(tenantID, reviewID uuid.UUID) (*domain.ReviewR
 
 // GetUserReviewForCourse obtiene la review de un usuario para un curso
 func (s *ReviewService) GetUserReviewForCourse(tenantID, userID, courseID uuid.UUID) (*domain.ReviewResponse, error) {
+	if err := s.requireReviews(tenantID); err != nil {
+		return nil, err
+	}
+
 	review, err := s.repo.GetByUserAndCourse(tenantID, userID, courseID)
 	if err != nil {
 		return nil, err
@@ -119,6 +143,10 @@ func (s *ReviewService) GetUserReviewForCourse(tenantID, userID, courseID uuid.U
 
 // UpdateReview actualiza una review existente
 func (s *ReviewService) UpdateReview(tenantID, userID, reviewID uuid.UUID, req domain.UpdateReviewRequest) (*domain.ReviewResponse, error) {
+	if err := s.requireReviews(tenantID); err != nil {
+		return nil, err
+	}
+
 	// Validate request
 	if err := req.Validate(); err != nil {
 		return nil, err
@@ -174,6 +202,10 @@ func (s *ReviewService) UpdateReview(tenantID, userID, reviewID uuid.UUID, req d
 
 // DeleteReview elimina una review
 func (s *ReviewService) DeleteReview(tenantID, userID, reviewID uuid.UUID) error {
+	if err := s.requireReviews(tenantID); err != nil {
+		return err
+	}
+
 	// Get existing review
 	review, err := s.repo.GetByID(tenantID, reviewID)
 	if err != nil {
@@ -202,6 +234,10 @@ func (s *ReviewService) DeleteReview(tenantID, userID, reviewID uuid.UUID) error
 
 // GetCourseReviews obtiene las reviews de un curso
 func (s *ReviewService) GetCourseReviews(tenantID, courseID uuid.UUID, userID *uuid.UUID, page, pageSize int, sortBy string) (*domain.ReviewListResponse, error) {
+	if err := s.requireReviews(tenantID); err != nil {
+		return nil, err
+	}
+
 	// Get reviews
 	reviews, total, err := s.repo.GetCourseReviews(tenantID, courseID, page, pageSize, sortBy)
 	if err != nil {
@@ -239,6 +275,10 @@ func (s *ReviewService) GetCourseReviews(tenantID, courseID uuid.UUID, userID *u
 
 // GetUserReviews obtiene las reviews de un usuario
 func (s *ReviewService) GetUserReviews(tenantID, userID uuid.UUID, page, pageSize int) (*domain.ReviewListResponse, error) {
+	if err := s.requireReviews(tenantID); err != nil {
+		return nil, err
+	}
+
 	// Get reviews
 	reviews, total, err := s.repo.GetUserReviews(tenantID, userID, page, pageSize)
 	if err != nil {
@@ -267,6 +307,10 @@ func (s *ReviewService) GetUserReviews(tenantID, userID uuid.UUID, page, pageSiz
 
 // GetCourseRating obtiene el rating agregado de un curso
 func (s *ReviewService) GetCourseRating(tenantID, courseID uuid.UUID) (*domain.CourseRatingResponse, error) {
+	if err := s.requireReviews(tenantID); err != nil {
+		return nil, err
+	}
+
 	rating, err := s.repo.GetCourseRating(tenantID, courseID)
 	if err != nil {
 		return nil, err
@@ -295,6 +339,10 @@ func (s *ReviewService) GetCourseRating(tenantID, courseID uuid.UUID) (*domain.C
 
 // VoteReview registra un voto de utilidad en una review
 func (s *ReviewService) VoteReview(tenantID, userID uuid.UUID, req domain.VoteReviewRequest) error {
+	if err := s.requireReviews(tenantID); err != nil {
+		return err
+	}
+
 	// Validate request
 	if err := req.Validate(); err != nil {
 		return err
@@ -338,6 +386,10 @@ func (s *ReviewService) VoteReview(tenantID, userID uuid.UUID, req domain.VoteRe
 
 // RemoveVote elimina el voto de un usuario en una review
 func (s *ReviewService) RemoveVote(tenantID, userID, reviewID uuid.UUID) error {
+	if err := s.requireReviews(tenantID); err != nil {
+		return err
+	}
+
 	return s.repo.DeleteHelpfulVote(tenantID, reviewID, userID)
 }
 
@@ -347,6 +399,10 @@ func (s *ReviewService) RemoveVote(tenantID, userID, reviewID uuid.UUID) error {
 
 // ReportReview crea un reporte de una review
 func (s *ReviewService) ReportReview(tenantID, userID uuid.UUID, req domain.ReportReviewRequest) (*domain.ReviewReportResponse, error) {
+	if err := s.requireReviews(tenantID); err != nil {
+		return nil, err
+	}
+
 	// Validate request
 	if err := req.Validate(); err != nil {
 		return nil, err
@@ -393,6 +449,10 @@ func (s *ReviewService) ReportReview(tenantID, userID uuid.UUID, req domain.Repo
 
 // GetReviewReports obtiene los reportes de una review
 func (s *ReviewService) GetReviewReports(tenantID, reviewID uuid.UUID) ([]domain.ReviewReportResponse, error) {
+	if err := s.requireReviews(tenantID); err != nil {
+		return nil, err
+	}
+
 	reports, err := s.repo.GetReportsByReview(tenantID, reviewID)
 	if err != nil {
 		return nil, err
@@ -408,6 +468,10 @@ func (s *ReviewService) GetReviewReports(tenantID, reviewID uuid.UUID) ([]domain
 
 // GetPendingReports obtiene los reportes pendientes
 func (s *ReviewService) GetPendingReports(tenantID uuid.UUID, page, pageSize int) ([]domain.ReviewReportResponse, int, error) {
+	if err := s.requireReviews(tenantID); err != nil {
+		return nil, 0, err
+	}
+
 	reports, total, err := s.repo.GetPendingReports(tenantID, page, pageSize)
 	if err != nil {
 		return nil, 0, err
@@ -423,6 +487,10 @@ func (s *ReviewService) GetPendingReports(tenantID uuid.UUID, page, pageSize int
 
 // UpdateReportStatus actualiza el estado de un reporte (solo admins)
 func (s *ReviewService) UpdateReportStatus(tenantID, adminID, reportID uuid.UUID, status domain.ReportStatus) error {
+	if err := s.requireReviews(tenantID); err != nil {
+		return err
+	}
+
 	// TODO: Validate admin permissions
 
 	// Get report
@@ -458,6 +526,10 @@ func (s *ReviewService) UpdateReportStatus(tenantID, adminID, reportID uuid.UUID
 
 // DeleteReviewByAdmin elimina una review (solo admins)
 func (s *ReviewService) DeleteReviewByAdmin(tenantID, adminID, reviewID uuid.UUID) error {
+	if err := s.requireReviews(tenantID); err != nil {
+		return err
+	}
+
 	// TODO: Validate admin permissions
 
 	// Get review
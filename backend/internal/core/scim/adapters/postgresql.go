@@ -0,0 +1,322 @@
+package adapters
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/scim/domain"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/scim/ports"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// PostgresSCIMRepository implements SCIMRepository on the control database
+type PostgresSCIMRepository struct {
+	db *sqlx.DB
+}
+
+// NewPostgresSCIMRepository creates a new SCIM repository
+func NewPostgresSCIMRepository(db *sqlx.DB) ports.SCIMRepository {
+	return &PostgresSCIMRepository{db: db}
+}
+
+// privilegedRoles are the user roles SCIM never manages
+var privilegedRoles = pq.StringArray{"admin", "superadmin"}
+
+// memberQuery reads the members of a tenant with their users, limited to the provisioned role
+// and to users that are not admins or superadmins
+const memberQuery = `
+	SELECT u.id AS user_id, tm.id AS membership_id, u.email, COALESCE(u.full_name, '') AS full_name,
+		tm.external_id, tm.created_by_scim, tm.role, tm.status, tm.created_at,
+		GREATEST(tm.updated_at, u.updated_at) AS updated_at
+	FROM tenant_memberships tm
+	INNER JOIN users u ON u.id = tm.user_id
+	WHERE tm.tenant_id = $1 AND tm.role = $2 AND NOT (COALESCE(u.roles, '{}') && $3::TEXT[])`
+
+// ListMembers retrieves the members of a tenant ordered by the time they joined
+func (r *PostgresSCIMRepository) ListMembers(ctx context.Context, tenantID string, lookup domain.MemberLookup) ([]*domain.Member, error) {
+	query := memberQuery
+	args := []interface{}{tenantID, domain.ProvisionedRole, privilegedRoles}
+	if lookup.Email != "" {
+		args = append(args, lookup.Email)
+		query += fmt.Sprintf(" AND LOWER(u.email) = LOWER($%d)", len(args))
+	}
+	if lookup.ExternalID != "" {
+		args = append(args, lookup.ExternalID)
+		query += fmt.Sprintf(" AND tm.external_id = $%d", len(args))
+	}
+	query += " ORDER BY tm.created_at, u.id"
+
+	members := []*domain.Member{}
+	if err := r.db.SelectContext(ctx, &members, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to list members: %w", err)
+	}
+	return members, nil
+}
+
+// GetMember retrieves a member of a tenant by user ID
+func (r *PostgresSCIMRepository) GetMember(ctx context.Context, tenantID, userID string) (*domain.Member, error) {
+	var member domain.Member
+	if err := r.db.GetContext(ctx, &member, memberQuery+" AND u.id = $4", tenantID, domain.ProvisionedRole, privilegedRoles, userID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ports.ErrResourceNotFound
+		}
+		return nil, fmt.Errorf("failed to get member: %w", err)
+	}
+	return &member, nil
+}
+
+// SetMemberExternalID sets or clears the external ID of a member
+func (r *PostgresSCIMRepository) SetMemberExternalID(ctx context.Context, tenantID, userID string, externalID *string) error {
+	query := `UPDATE tenant_memberships SET external_id = $1, updated_at = NOW() WHERE tenant_id = $2 AND user_id = $3`
+
+	result, err := r.db.ExecContext(ctx, query, externalID, tenantID, userID)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return fmt.Errorf("%w: another user has externalId %s", ports.ErrUniqueness, *externalID)
+		}
+		return fmt.Errorf("failed to set external ID: %w", err)
+	}
+	return requireRow(result, ports.ErrResourceNotFound)
+}
+
+// MarkMemberCreatedBySCIM records that the user of a membership was created by SCIM
+func (r *PostgresSCIMRepository) MarkMemberCreatedBySCIM(ctx context.Context, tenantID, userID string) error {
+	query := `UPDATE tenant_memberships SET created_by_scim = true, updated_at = NOW() WHERE tenant_id = $1 AND user_id = $2`
+
+	result, err := r.db.ExecContext(ctx, query, tenantID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to mark member as created by SCIM: %w", err)
+	}
+	return requireRow(result, ports.ErrResourceNotFound)
+}
+
+// DeleteMember deletes the membership of a user and removes it from the cohorts of the tenant.
+// Only memberships with the provisioned role are deleted.
+func (r *PostgresSCIMRepository) DeleteMember(ctx context.Context, tenantID, userID string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		DELETE FROM tenant_cohort_members
+		WHERE user_id = $1 AND cohort_id IN (SELECT id FROM tenant_cohorts WHERE tenant_id = $2)
+	`
+	if _, err := tx.ExecContext(ctx, query, userID, tenantID); err != nil {
+		return fmt.Errorf("failed to remove member from cohorts: %w", err)
+	}
+
+	query = `
+		DELETE FROM tenant_memberships tm
+		USING users u
+		WHERE u.id = tm.user_id AND tm.tenant_id = $1 AND tm.user_id = $2
+			AND tm.role = $3 AND NOT (COALESCE(u.roles, '{}') && $4::TEXT[])
+	`
+	result, err := tx.ExecContext(ctx, query, tenantID, userID, domain.ProvisionedRole, privilegedRoles)
+	if err != nil {
+		return fmt.Errorf("failed to delete membership: %w", err)
+	}
+	if err := requireRow(result, ports.ErrResourceNotFound); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit member deletion: %w", err)
+	}
+	return nil
+}
+
+// IsUnmanagedUser checks if a user is an admin or superadmin, or a member of the tenant with a
+// role other than the provisioned one
+func (r *PostgresSCIMRepository) IsUnmanagedUser(ctx context.Context, tenantID, userID string) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM users WHERE id = $2 AND COALESCE(roles, '{}') && $4::TEXT[]
+		) OR EXISTS (
+			SELECT 1 FROM tenant_memberships WHERE tenant_id = $1 AND user_id = $2 AND role <> $3
+		)
+	`
+	var unmanaged bool
+	if err := r.db.GetContext(ctx, &unmanaged, query, tenantID, userID, domain.ProvisionedRole, privilegedRoles); err != nil {
+		return false, fmt.Errorf("failed to check user roles: %w", err)
+	}
+	return unmanaged, nil
+}
+
+// CountUserMemberships counts the memberships of a user in any status
+func (r *PostgresSCIMRepository) CountUserMemberships(ctx context.Context, userID string) (int, error) {
+	var count int
+	if err := r.db.GetContext(ctx, &count, `SELECT COUNT(*) FROM tenant_memberships WHERE user_id = $1`, userID); err != nil {
+		return 0, fmt.Errorf("failed to count memberships: %w", err)
+	}
+	return count, nil
+}
+
+// UpdateUserEmail changes the email of a user and marks it as unverified
+func (r *PostgresSCIMRepository) UpdateUserEmail(ctx context.Context, userID, email string) error {
+	query := `UPDATE users SET email = $1, is_verified = false, updated_at = NOW() WHERE id = $2`
+	result, err := r.db.ExecContext(ctx, query, email, userID)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return fmt.Errorf("%w: another user has userName %s", ports.ErrUniqueness, email)
+		}
+		return fmt.Errorf("failed to update user email: %w", err)
+	}
+	return requireRow(result, ports.ErrResourceNotFound)
+}
+
+const cohortColumns = `id, tenant_id, display_name, external_id, course_ids::text[] AS course_ids, created_at, updated_at`
+
+// ListCohorts retrieves the cohorts of a tenant ordered by name
+func (r *PostgresSCIMRepository) ListCohorts(ctx context.Context, tenantID string) ([]*domain.Cohort, error) {
+	cohorts := []*domain.Cohort{}
+	query := `SELECT ` + cohortColumns + ` FROM tenant_cohorts WHERE tenant_id = $1 ORDER BY LOWER(display_name)`
+	if err := r.db.SelectContext(ctx, &cohorts, query, tenantID); err != nil {
+		return nil, fmt.Errorf("failed to list cohorts: %w", err)
+	}
+	if len(cohorts) == 0 {
+		return cohorts, nil
+	}
+
+	var rows []struct {
+		CohortID string `db:"cohort_id"`
+		UserID   string `db:"user_id"`
+	}
+	query = `
+		SELECT cm.cohort_id, cm.user_id
+		FROM tenant_cohort_members cm
+		INNER JOIN tenant_cohorts c ON c.id = cm.cohort_id
+		WHERE c.tenant_id = $1
+		ORDER BY cm.created_at, cm.user_id
+	`
+	if err := r.db.SelectContext(ctx, &rows, query, tenantID); err != nil {
+		return nil, fmt.Errorf("failed to list cohort members: %w", err)
+	}
+
+	byID := make(map[string]*domain.Cohort, len(cohorts))
+	for _, cohort := range cohorts {
+		cohort.MemberIDs = []string{}
+		byID[cohort.ID] = cohort
+	}
+	for _, row := range rows {
+		if cohort, ok := byID[row.CohortID]; ok {
+			cohort.MemberIDs = append(cohort.MemberIDs, row.UserID)
+		}
+	}
+
+	return cohorts, nil
+}
+
+// GetCohort retrieves a cohort of a tenant with its members
+func (r *PostgresSCIMRepository) GetCohort(ctx context.Context, tenantID, cohortID string) (*domain.Cohort, error) {
+	var cohort domain.Cohort
+	query := `SELECT ` + cohortColumns + ` FROM tenant_cohorts WHERE tenant_id = $1 AND id = $2`
+	if err := r.db.GetContext(ctx, &cohort, query, tenantID, cohortID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ports.ErrResourceNotFound
+		}
+		return nil, fmt.Errorf("failed to get cohort: %w", err)
+	}
+
+	cohort.MemberIDs = []string{}
+	query = `SELECT user_id FROM tenant_cohort_members WHERE cohort_id = $1 ORDER BY created_at, user_id`
+	if err := r.db.SelectContext(ctx, &cohort.MemberIDs, query, cohort.ID); err != nil {
+		return nil, fmt.Errorf("failed to list cohort members: %w", err)
+	}
+
+	return &cohort, nil
+}
+
+// CreateCohort inserts a cohort with its members
+func (r *PostgresSCIMRepository) CreateCohort(ctx context.Context, cohort *domain.Cohort) error {
+	if cohort.CourseIDs == nil {
+		cohort.CourseIDs = pq.StringArray{}
+	}
+	return r.saveCohort(ctx, cohort, `
+		INSERT INTO tenant_cohorts (id, tenant_id, display_name, external_id, course_ids, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5::uuid[], $6, $7)
+	`, cohort.ID, cohort.TenantID, cohort.DisplayName, cohort.ExternalID, cohort.CourseIDs, cohort.CreatedAt, cohort.UpdatedAt)
+}
+
+// UpdateCohort saves a cohort and replaces its members
+func (r *PostgresSCIMRepository) UpdateCohort(ctx context.Context, cohort *domain.Cohort) error {
+	if cohort.CourseIDs == nil {
+		cohort.CourseIDs = pq.StringArray{}
+	}
+	return r.saveCohort(ctx, cohort, `
+		UPDATE tenant_cohorts
+		SET display_name = $3, external_id = $4, course_ids = $5::uuid[], updated_at = $6
+		WHERE id = $1 AND tenant_id = $2
+	`, cohort.ID, cohort.TenantID, cohort.DisplayName, cohort.ExternalID, cohort.CourseIDs, cohort.UpdatedAt)
+}
+
+// saveCohort runs the statement that saves a cohort and replaces its members in a transaction
+func (r *PostgresSCIMRepository) saveCohort(ctx context.Context, cohort *domain.Cohort, query string, args ...interface{}) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return fmt.Errorf("%w: another group has the displayName or externalId", ports.ErrUniqueness)
+		}
+		return fmt.Errorf("failed to save cohort: %w", err)
+	}
+	if err := requireRow(result, ports.ErrResourceNotFound); err != nil {
+		return err
+	}
+
+	memberIDs := pq.StringArray{}
+	memberIDs = append(memberIDs, cohort.MemberIDs...)
+	if _, err := tx.ExecContext(ctx, `DELETE FROM tenant_cohort_members WHERE cohort_id = $1 AND NOT (user_id = ANY($2::uuid[]))`,
+		cohort.ID, memberIDs); err != nil {
+		return fmt.Errorf("failed to remove cohort members: %w", err)
+	}
+	query = `
+		INSERT INTO tenant_cohort_members (cohort_id, user_id)
+		SELECT $1, UNNEST($2::uuid[])
+		ON CONFLICT (cohort_id, user_id) DO NOTHING
+	`
+	if _, err := tx.ExecContext(ctx, query, cohort.ID, memberIDs); err != nil {
+		return fmt.Errorf("failed to add cohort members: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit cohort: %w", err)
+	}
+	return nil
+}
+
+// DeleteCohort deletes a cohort; its members keep their enrollments
+func (r *PostgresSCIMRepository) DeleteCohort(ctx context.Context, tenantID, cohortID string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM tenant_cohorts WHERE tenant_id = $1 AND id = $2`, tenantID, cohortID)
+	if err != nil {
+		return fmt.Errorf("failed to delete cohort: %w", err)
+	}
+	return requireRow(result, ports.ErrResourceNotFound)
+}
+
+// requireRow returns notFound if a statement affected no rows
+func requireRow(result sql.Result, notFound error) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return notFound
+	}
+	return nil
+}
+
+// isUniqueViolation checks if an error was caused by a unique constraint
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
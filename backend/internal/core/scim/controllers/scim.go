@@ -0,0 +1,470 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/scim/domain"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/scim/ports"
+	tenantports "github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/ports"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// contentType is the media type of SCIM requests and responses
+const contentType = "application/scim+json"
+
+// SCIMController handles the SCIM 2.0 requests of identity providers. Requests are
+// authenticated by SCIMAuthMiddleware, which sets the tenant of the API key.
+type SCIMController struct {
+	scimService ports.SCIMService
+}
+
+// NewSCIMController creates a new SCIM controller
+func NewSCIMController(scimService ports.SCIMService) *SCIMController {
+	return &SCIMController{
+		scimService: scimService,
+	}
+}
+
+// GetServiceProviderConfig describes the SCIM features the API supports
+// @Summary Get SCIM service provider config
+// @Tags scim
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /scim/v2/ServiceProviderConfig [get]
+func (c *SCIMController) GetServiceProviderConfig(ctx *fiber.Ctx) error {
+	return ctx.Status(fiber.StatusOK).JSON(c.scimService.GetServiceProviderConfig(), contentType)
+}
+
+// ListResourceTypes describes the resources the API provisions
+// @Summary List SCIM resource types
+// @Tags scim
+// @Produce json
+// @Success 200 {object} domain.ListResponse
+// @Router /scim/v2/ResourceTypes [get]
+func (c *SCIMController) ListResourceTypes(ctx *fiber.Ctx) error {
+	return ctx.Status(fiber.StatusOK).JSON(c.scimService.ListResourceTypes(), contentType)
+}
+
+// ============================================================
+// Users
+// ============================================================
+
+// ListUsers lists the members of the tenant
+// @Summary List SCIM users
+// @Description List the members of the tenant of the API key, optionally filtered and paginated
+// @Tags scim
+// @Produce json
+// @Param filter query string false "SCIM filter, e.g. userName eq \"ana@example.com\""
+// @Param startIndex query int false "1-based index of the first result"
+// @Param count query int false "Page size (max 200)"
+// @Success 200 {object} domain.ListResponse
+// @Failure 400 {object} domain.Error
+// @Failure 401 {object} domain.Error
+// @Router /scim/v2/Users [get]
+func (c *SCIMController) ListUsers(ctx *fiber.Ctx) error {
+	query, err := parseListQuery(ctx)
+	if err != nil {
+		return scimErrorResponse(ctx, err)
+	}
+
+	response, err := c.scimService.ListUsers(ctx.Context(), tenantID(ctx), query)
+	if err != nil {
+		return scimErrorResponse(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response, contentType)
+}
+
+// GetUser retrieves a member of the tenant
+// @Summary Get SCIM user
+// @Tags scim
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} domain.User
+// @Failure 404 {object} domain.Error
+// @Router /scim/v2/Users/{id} [get]
+func (c *SCIMController) GetUser(ctx *fiber.Ctx) error {
+	userID, err := resourceID(ctx)
+	if err != nil {
+		return scimErrorResponse(ctx, err)
+	}
+
+	user, err := c.scimService.GetUser(ctx.Context(), tenantID(ctx), userID)
+	if err != nil {
+		return scimErrorResponse(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(user, contentType)
+}
+
+// CreateUser adds a user to the tenant
+// @Summary Create SCIM user
+// @Description Add a user to the tenant as a student, creating its account if it has none
+// @Tags scim
+// @Accept json
+// @Produce json
+// @Param user body domain.User true "User"
+// @Success 201 {object} domain.User
+// @Failure 400 {object} domain.Error
+// @Failure 403 {object} domain.Error
+// @Failure 409 {object} domain.Error
+// @Router /scim/v2/Users [post]
+func (c *SCIMController) CreateUser(ctx *fiber.Ctx) error {
+	var user domain.User
+	if err := parseBody(ctx, &user); err != nil {
+		return scimErrorResponse(ctx, err)
+	}
+
+	created, err := c.scimService.CreateUser(ctx.Context(), tenantID(ctx), &user)
+	if err != nil {
+		return scimErrorResponse(ctx, err)
+	}
+
+	ctx.Location(created.Meta.Location)
+	return ctx.Status(fiber.StatusCreated).JSON(created, contentType)
+}
+
+// ReplaceUser updates a member of the tenant
+// @Summary Replace SCIM user
+// @Tags scim
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param user body domain.User true "User"
+// @Success 200 {object} domain.User
+// @Failure 400 {object} domain.Error
+// @Failure 404 {object} domain.Error
+// @Router /scim/v2/Users/{id} [put]
+func (c *SCIMController) ReplaceUser(ctx *fiber.Ctx) error {
+	userID, err := resourceID(ctx)
+	if err != nil {
+		return scimErrorResponse(ctx, err)
+	}
+	var user domain.User
+	if err := parseBody(ctx, &user); err != nil {
+		return scimErrorResponse(ctx, err)
+	}
+
+	updated, err := c.scimService.ReplaceUser(ctx.Context(), tenantID(ctx), userID, &user)
+	if err != nil {
+		return scimErrorResponse(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(updated, contentType)
+}
+
+// PatchUser applies PATCH operations to a member of the tenant
+// @Summary Patch SCIM user
+// @Tags scim
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param patch body domain.PatchRequest true "PATCH operations"
+// @Success 200 {object} domain.User
+// @Failure 400 {object} domain.Error
+// @Failure 404 {object} domain.Error
+// @Router /scim/v2/Users/{id} [patch]
+func (c *SCIMController) PatchUser(ctx *fiber.Ctx) error {
+	userID, err := resourceID(ctx)
+	if err != nil {
+		return scimErrorResponse(ctx, err)
+	}
+	var patch domain.PatchRequest
+	if err := parseBody(ctx, &patch); err != nil {
+		return scimErrorResponse(ctx, err)
+	}
+
+	updated, err := c.scimService.PatchUser(ctx.Context(), tenantID(ctx), userID, &patch)
+	if err != nil {
+		return scimErrorResponse(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(updated, contentType)
+}
+
+// DeleteUser removes a user from the tenant
+// @Summary Delete SCIM user
+// @Description Remove a user from the tenant and its cohorts; the account and its progress are kept
+// @Tags scim
+// @Param id path string true "User ID"
+// @Success 204
+// @Failure 404 {object} domain.Error
+// @Router /scim/v2/Users/{id} [delete]
+func (c *SCIMController) DeleteUser(ctx *fiber.Ctx) error {
+	userID, err := resourceID(ctx)
+	if err != nil {
+		return scimErrorResponse(ctx, err)
+	}
+
+	if err := c.scimService.DeleteUser(ctx.Context(), tenantID(ctx), userID); err != nil {
+		return scimErrorResponse(ctx, err)
+	}
+
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
+
+// ============================================================
+// Groups
+// ============================================================
+
+// ListGroups lists the cohorts of the tenant
+// @Summary List SCIM groups
+// @Description List the cohorts of the tenant of the API key, optionally filtered and paginated
+// @Tags scim
+// @Produce json
+// @Param filter query string false "SCIM filter, e.g. displayName eq \"2024 interns\""
+// @Param startIndex query int false "1-based index of the first result"
+// @Param count query int false "Page size (max 200)"
+// @Success 200 {object} domain.ListResponse
+// @Failure 400 {object} domain.Error
+// @Router /scim/v2/Groups [get]
+func (c *SCIMController) ListGroups(ctx *fiber.Ctx) error {
+	query, err := parseListQuery(ctx)
+	if err != nil {
+		return scimErrorResponse(ctx, err)
+	}
+
+	response, err := c.scimService.ListGroups(ctx.Context(), tenantID(ctx), query)
+	if err != nil {
+		return scimErrorResponse(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response, contentType)
+}
+
+// GetGroup retrieves a cohort of the tenant
+// @Summary Get SCIM group
+// @Tags scim
+// @Produce json
+// @Param id path string true "Cohort ID"
+// @Success 200 {object} domain.Group
+// @Failure 404 {object} domain.Error
+// @Router /scim/v2/Groups/{id} [get]
+func (c *SCIMController) GetGroup(ctx *fiber.Ctx) error {
+	groupID, err := resourceID(ctx)
+	if err != nil {
+		return scimErrorResponse(ctx, err)
+	}
+
+	group, err := c.scimService.GetGroup(ctx.Context(), tenantID(ctx), groupID)
+	if err != nil {
+		return scimErrorResponse(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(group, contentType)
+}
+
+// CreateGroup creates a cohort in the tenant
+// @Summary Create SCIM group
+// @Description Create a cohort; its active members are enrolled in the courses of the cohort extension
+// @Tags scim
+// @Accept json
+// @Produce json
+// @Param group body domain.Group true "Group"
+// @Success 201 {object} domain.Group
+// @Failure 400 {object} domain.Error
+// @Failure 409 {object} domain.Error
+// @Router /scim/v2/Groups [post]
+func (c *SCIMController) CreateGroup(ctx *fiber.Ctx) error {
+	var group domain.Group
+	if err := parseBody(ctx, &group); err != nil {
+		return scimErrorResponse(ctx, err)
+	}
+
+	created, err := c.scimService.CreateGroup(ctx.Context(), tenantID(ctx), &group)
+	if err != nil {
+		return scimErrorResponse(ctx, err)
+	}
+
+	ctx.Location(created.Meta.Location)
+	return ctx.Status(fiber.StatusCreated).JSON(created, contentType)
+}
+
+// ReplaceGroup updates a cohort of the tenant
+// @Summary Replace SCIM group
+// @Tags scim
+// @Accept json
+// @Produce json
+// @Param id path string true "Cohort ID"
+// @Param group body domain.Group true "Group"
+// @Success 200 {object} domain.Group
+// @Failure 400 {object} domain.Error
+// @Failure 404 {object} domain.Error
+// @Router /scim/v2/Groups/{id} [put]
+func (c *SCIMController) ReplaceGroup(ctx *fiber.Ctx) error {
+	groupID, err := resourceID(ctx)
+	if err != nil {
+		return scimErrorResponse(ctx, err)
+	}
+	var group domain.Group
+	if err := parseBody(ctx, &group); err != nil {
+		return scimErrorResponse(ctx, err)
+	}
+
+	updated, err := c.scimService.ReplaceGroup(ctx.Context(), tenantID(ctx), groupID, &group)
+	if err != nil {
+		return scimErrorResponse(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(updated, contentType)
+}
+
+// PatchGroup applies PATCH operations to a cohort of the tenant
+// @Summary Patch SCIM group
+// @Tags scim
+// @Accept json
+// @Produce json
+// @Param id path string true "Cohort ID"
+// @Param patch body domain.PatchRequest true "PATCH operations"
+// @Success 200 {object} domain.Group
+// @Failure 400 {object} domain.Error
+// @Failure 404 {object} domain.Error
+// @Router /scim/v2/Groups/{id} [patch]
+func (c *SCIMController) PatchGroup(ctx *fiber.Ctx) error {
+	groupID, err := resourceID(ctx)
+	if err != nil {
+		return scimErrorResponse(ctx, err)
+	}
+	var patch domain.PatchRequest
+	if err := parseBody(ctx, &patch); err != nil {
+		return scimErrorResponse(ctx, err)
+	}
+
+	updated, err := c.scimService.PatchGroup(ctx.Context(), tenantID(ctx), groupID, &patch)
+	if err != nil {
+		return scimErrorResponse(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(updated, contentType)
+}
+
+// DeleteGroup deletes a cohort of the tenant
+// @Summary Delete SCIM group
+// @Description Delete a cohort; its members keep their enrollments
+// @Tags scim
+// @Param id path string true "Cohort ID"
+// @Success 204
+// @Failure 404 {object} domain.Error
+// @Router /scim/v2/Groups/{id} [delete]
+func (c *SCIMController) DeleteGroup(ctx *fiber.Ctx) error {
+	groupID, err := resourceID(ctx)
+	if err != nil {
+		return scimErrorResponse(ctx, err)
+	}
+
+	if err := c.scimService.DeleteGroup(ctx.Context(), tenantID(ctx), groupID); err != nil {
+		return scimErrorResponse(ctx, err)
+	}
+
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
+
+// ============================================================
+// Helpers
+// ============================================================
+
+// tenantID returns the tenant of the API key that authenticated the request
+func tenantID(ctx *fiber.Ctx) string {
+	tenantID, _ := ctx.Locals("tenant_id").(string)
+	return tenantID
+}
+
+// resourceID returns the ID of the resource of the request. IDs that aren't UUIDs name no resource.
+func resourceID(ctx *fiber.Ctx) (string, error) {
+	id := ctx.Params("id")
+	if _, err := uuid.Parse(id); err != nil {
+		return "", ports.ErrResourceNotFound
+	}
+	return id, nil
+}
+
+// parseBody decodes the JSON body of a request. Identity providers send application/scim+json,
+// which BodyParser doesn't decode.
+func parseBody(ctx *fiber.Ctx, v interface{}) error {
+	if err := json.Unmarshal(ctx.Body(), v); err != nil {
+		return fmt.Errorf("%w: %v", ports.ErrInvalidSyntax, err)
+	}
+	return nil
+}
+
+// parseListQuery reads the query parameters of a list request
+func parseListQuery(ctx *fiber.Ctx) (*domain.ListQuery, error) {
+	query := &domain.ListQuery{
+		Filter:             ctx.Query("filter"),
+		Attributes:         splitAttributes(ctx.Query("attributes")),
+		ExcludedAttributes: splitAttributes(ctx.Query("excludedAttributes")),
+	}
+
+	if value := ctx.Query("startIndex"); value != "" {
+		startIndex, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: startIndex must be a number", ports.ErrInvalidValue)
+		}
+		query.StartIndex = startIndex
+	}
+	if value := ctx.Query("count"); value != "" {
+		count, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: count must be a number", ports.ErrInvalidValue)
+		}
+		query.Count = &count
+	}
+
+	return query, nil
+}
+
+// splitAttributes splits a comma separated list of attributes
+func splitAttributes(value string) []string {
+	var attributes []string
+	for _, attribute := range strings.Split(value, ",") {
+		if attribute = strings.TrimSpace(attribute); attribute != "" {
+			attributes = append(attributes, attribute)
+		}
+	}
+	return attributes
+}
+
+// scimErrorResponse maps SCIM service errors to SCIM error responses
+func scimErrorResponse(ctx *fiber.Ctx, err error) error {
+	status := fiber.StatusInternalServerError
+	scimType := ""
+	switch {
+	case errors.Is(err, ports.ErrResourceNotFound):
+		status = fiber.StatusNotFound
+	case errors.Is(err, ports.ErrUniqueness):
+		status, scimType = fiber.StatusConflict, "uniqueness"
+	case errors.Is(err, ports.ErrInvalidFilter):
+		status, scimType = fiber.StatusBadRequest, "invalidFilter"
+	case errors.Is(err, ports.ErrInvalidPath):
+		status, scimType = fiber.StatusBadRequest, "invalidPath"
+	case errors.Is(err, ports.ErrNoTarget):
+		status, scimType = fiber.StatusBadRequest, "noTarget"
+	case errors.Is(err, ports.ErrInvalidValue):
+		status, scimType = fiber.StatusBadRequest, "invalidValue"
+	case errors.Is(err, ports.ErrInvalidSyntax):
+		status, scimType = fiber.StatusBadRequest, "invalidSyntax"
+	case errors.Is(err, ports.ErrMutability):
+		status, scimType = fiber.StatusBadRequest, "mutability"
+	case errors.Is(err, tenantports.ErrLearnerLimitReached):
+		status = fiber.StatusForbidden
+	}
+
+	detail := err.Error()
+	if status == fiber.StatusInternalServerError {
+		log.Printf("❌ [SCIMController] %s %s failed: %v", ctx.Method(), ctx.Path(), err)
+		detail = "Internal server error"
+	}
+
+	return ctx.Status(status).JSON(domain.Error{
+		Schemas:  []string{domain.SchemaError},
+		Status:   strconv.Itoa(status),
+		ScimType: scimType,
+		Detail:   detail,
+	}, contentType)
+}
@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// ProvisionedRole is the role of the members managed through SCIM. Members with other roles,
+// and admin and superadmin users, are never read or changed by SCIM.
+const ProvisionedRole = "student"
+
+// Member represents a member of a tenant with its user, as provisioned through SCIM
+type Member struct {
+	UserID        string    `db:"user_id"`
+	MembershipID  string    `db:"membership_id"`
+	Email         string    `db:"email"`
+	FullName      string    `db:"full_name"`
+	ExternalID    *string   `db:"external_id"`
+	CreatedBySCIM bool      `db:"created_by_scim"`
+	Role          string    `db:"role"`
+	Status        string    `db:"status"`
+	CreatedAt     time.Time `db:"created_at"`
+	UpdatedAt     time.Time `db:"updated_at"`
+}
+
+// IsActive checks if the member can access the tenant
+func (m *Member) IsActive() bool {
+	return m.Status == "active"
+}
+
+// MemberLookup narrows the members read from the repository. Empty fields match every member.
+type MemberLookup struct {
+	Email      string
+	ExternalID string
+}
+
+// Cohort represents a group of members of a tenant that are enrolled in the same courses.
+// Users that join a cohort are enrolled in its courses; users that leave it keep their
+// enrollments and progress.
+type Cohort struct {
+	ID          string         `db:"id"`
+	TenantID    string         `db:"tenant_id"`
+	DisplayName string         `db:"display_name"`
+	ExternalID  *string        `db:"external_id"`
+	CourseIDs   pq.StringArray `db:"course_ids"`
+	MemberIDs   []string       `db:"-"`
+	CreatedAt   time.Time      `db:"created_at"`
+	UpdatedAt   time.Time      `db:"updated_at"`
+}
+
+// HasMember checks if a user is in the cohort
+func (c *Cohort) HasMember(userID string) bool {
+	for _, memberID := range c.MemberIDs {
+		if memberID == userID {
+			return true
+		}
+	}
+	return false
+}
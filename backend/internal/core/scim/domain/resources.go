@@ -0,0 +1,153 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Schemas of the SCIM resources and messages (RFC 7643 and RFC 7644)
+const (
+	SchemaUser                  = "urn:ietf:params:scim:schemas:core:2.0:User"
+	SchemaGroup                 = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	SchemaServiceProviderConfig = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+	SchemaResourceType          = "urn:ietf:params:scim:schemas:core:2.0:ResourceType"
+	SchemaListResponse          = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	SchemaPatchOp               = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	SchemaError                 = "urn:ietf:params:scim:api:messages:2.0:Error"
+
+	// SchemaCohort extends groups with the courses their members are enrolled in
+	SchemaCohort = "urn:stegmaier:params:scim:schemas:extension:cohort:2.0:Group"
+)
+
+// Types of the SCIM resources
+const (
+	ResourceTypeUser  = "User"
+	ResourceTypeGroup = "Group"
+)
+
+// Boolean is a SCIM boolean. Some identity providers send booleans as the strings "True" and
+// "False", which it accepts as well.
+type Boolean bool
+
+// UnmarshalJSON decodes a JSON boolean or a string holding one
+func (b *Boolean) UnmarshalJSON(data []byte) error {
+	var value bool
+	if err := json.Unmarshal(data, &value); err == nil {
+		*b = Boolean(value)
+		return nil
+	}
+
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return fmt.Errorf("invalid boolean %s", data)
+	}
+	switch strings.ToLower(text) {
+	case "true":
+		*b = true
+	case "false":
+		*b = false
+	default:
+		return fmt.Errorf("invalid boolean %q", text)
+	}
+	return nil
+}
+
+// Meta holds the metadata of a resource
+type Meta struct {
+	ResourceType string     `json:"resourceType"`
+	Created      *time.Time `json:"created,omitempty"`
+	LastModified *time.Time `json:"lastModified,omitempty"`
+	Location     string     `json:"location,omitempty"`
+}
+
+// Name holds the components of the name of a user
+type Name struct {
+	Formatted  string `json:"formatted,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+	GivenName  string `json:"givenName,omitempty"`
+}
+
+// MultiValue is a value of a multi-valued attribute such as emails, groups or members
+type MultiValue struct {
+	Value   string  `json:"value"`
+	Display string  `json:"display,omitempty"`
+	Type    string  `json:"type,omitempty"`
+	Primary Boolean `json:"primary,omitempty"`
+	Ref     string  `json:"$ref,omitempty"`
+}
+
+// User is the SCIM representation of a member of a tenant. The userName is the email of the
+// user and the groups are its cohorts.
+type User struct {
+	Schemas     []string     `json:"schemas"`
+	ID          string       `json:"id,omitempty"`
+	ExternalID  string       `json:"externalId,omitempty"`
+	UserName    string       `json:"userName"`
+	Name        *Name        `json:"name,omitempty"`
+	DisplayName string       `json:"displayName,omitempty"`
+	Emails      []MultiValue `json:"emails,omitempty"`
+	Active      *Boolean     `json:"active,omitempty"`
+	// Password is only read when the user is created, and never returned
+	Password string       `json:"password,omitempty"`
+	Groups   []MultiValue `json:"groups,omitempty"`
+	Meta     *Meta        `json:"meta,omitempty"`
+}
+
+// CohortExtension holds the attributes of a group that are specific to cohorts
+type CohortExtension struct {
+	CourseIDs []string `json:"courseIds"`
+}
+
+// Group is the SCIM representation of a cohort
+type Group struct {
+	Schemas     []string         `json:"schemas"`
+	ID          string           `json:"id,omitempty"`
+	ExternalID  string           `json:"externalId,omitempty"`
+	DisplayName string           `json:"displayName"`
+	Members     []MultiValue     `json:"members,omitempty"`
+	Cohort      *CohortExtension `json:"urn:stegmaier:params:scim:schemas:extension:cohort:2.0:Group,omitempty"`
+	Meta        *Meta            `json:"meta,omitempty"`
+}
+
+// ListQuery holds the query parameters of a list request. Count is nil when the client didn't
+// ask for a page size.
+type ListQuery struct {
+	Filter             string
+	StartIndex         int
+	Count              *int
+	Attributes         []string
+	ExcludedAttributes []string
+}
+
+// ListResponse is the result of a list request. Resources are the JSON representation of the
+// resources, reduced to the requested attributes.
+type ListResponse struct {
+	Schemas      []string                 `json:"schemas"`
+	TotalResults int                      `json:"totalResults"`
+	StartIndex   int                      `json:"startIndex"`
+	ItemsPerPage int                      `json:"itemsPerPage"`
+	Resources    []map[string]interface{} `json:"Resources"`
+}
+
+// PatchOperation is an operation of a PATCH request
+type PatchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path,omitempty"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// PatchRequest is the body of a PATCH request
+type PatchRequest struct {
+	Schemas    []string         `json:"schemas"`
+	Operations []PatchOperation `json:"Operations"`
+}
+
+// Error is the body of a SCIM error response
+type Error struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	ScimType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail,omitempty"`
+}
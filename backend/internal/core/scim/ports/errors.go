@@ -0,0 +1,15 @@
+package ports
+
+import "errors"
+
+// SCIM errors. Each one is reported with the scimType of the same name (RFC 7644, section 3.12).
+var (
+	ErrResourceNotFound = errors.New("resource not found")
+	ErrUniqueness       = errors.New("resource already exists")
+	ErrInvalidFilter    = errors.New("invalid filter")
+	ErrInvalidPath      = errors.New("invalid path")
+	ErrNoTarget         = errors.New("path matches no value")
+	ErrInvalidValue     = errors.New("invalid value")
+	ErrInvalidSyntax    = errors.New("invalid request")
+	ErrMutability       = errors.New("attribute can't be modified")
+)
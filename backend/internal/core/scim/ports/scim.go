@@ -0,0 +1,72 @@
+package ports
+
+import (
+	"context"
+
+	authdomain "github.com/DanielIturra1610/stegmaier-landing/internal/core/auth/domain"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/scim/domain"
+)
+
+// SCIMRepository defines the persistence of provisioned members and cohorts in the control DB
+type SCIMRepository interface {
+	// ListMembers returns the members of a tenant, of any status, that match a lookup. Only
+	// members with the provisioned role that are not admin or superadmin users are returned,
+	// here and in GetMember and DeleteMember.
+	ListMembers(ctx context.Context, tenantID string, lookup domain.MemberLookup) ([]*domain.Member, error)
+	// GetMember returns ErrResourceNotFound if the user is not a member of the tenant
+	GetMember(ctx context.Context, tenantID, userID string) (*domain.Member, error)
+	// SetMemberExternalID returns ErrUniqueness if another member has the external ID
+	SetMemberExternalID(ctx context.Context, tenantID, userID string, externalID *string) error
+	// MarkMemberCreatedBySCIM records that SCIM created the user account of a member, which lets
+	// SCIM change its email
+	MarkMemberCreatedBySCIM(ctx context.Context, tenantID, userID string) error
+	// DeleteMember removes a user from a tenant and its cohorts; the user account is kept
+	DeleteMember(ctx context.Context, tenantID, userID string) error
+	// IsUnmanagedUser checks if a user is one SCIM must not provision in the tenant
+	IsUnmanagedUser(ctx context.Context, tenantID, userID string) (bool, error)
+	// CountUserMemberships counts the tenants a user is a member of
+	CountUserMemberships(ctx context.Context, userID string) (int, error)
+	// UpdateUserEmail marks the new email as unverified. It returns ErrUniqueness if another user
+	// has the email.
+	UpdateUserEmail(ctx context.Context, userID, email string) error
+
+	// ListCohorts returns the cohorts of a tenant with their members
+	ListCohorts(ctx context.Context, tenantID string) ([]*domain.Cohort, error)
+	// GetCohort returns ErrResourceNotFound if the tenant has no such cohort
+	GetCohort(ctx context.Context, tenantID, cohortID string) (*domain.Cohort, error)
+	// CreateCohort returns ErrUniqueness if the tenant has a cohort with the name or external ID
+	CreateCohort(ctx context.Context, cohort *domain.Cohort) error
+	// UpdateCohort saves the name, external ID, courses and members of a cohort
+	UpdateCohort(ctx context.Context, cohort *domain.Cohort) error
+	DeleteCohort(ctx context.Context, tenantID, cohortID string) error
+}
+
+// AccountVerifier revokes the sessions of users whose email changes and sends the new address a
+// verification email. AuthService implements it.
+type AccountVerifier interface {
+	RevokeAllSessions(ctx context.Context, userID string) error
+	ResendVerification(ctx context.Context, dto *authdomain.ResendVerificationDTO) error
+}
+
+// SCIMService defines the provisioning of the members and cohorts of a tenant through SCIM 2.0
+type SCIMService interface {
+	// Discovery
+	GetServiceProviderConfig() map[string]interface{}
+	ListResourceTypes() *domain.ListResponse
+
+	// Users
+	ListUsers(ctx context.Context, tenantID string, query *domain.ListQuery) (*domain.ListResponse, error)
+	GetUser(ctx context.Context, tenantID, userID string) (*domain.User, error)
+	CreateUser(ctx context.Context, tenantID string, user *domain.User) (*domain.User, error)
+	ReplaceUser(ctx context.Context, tenantID, userID string, user *domain.User) (*domain.User, error)
+	PatchUser(ctx context.Context, tenantID, userID string, patch *domain.PatchRequest) (*domain.User, error)
+	DeleteUser(ctx context.Context, tenantID, userID string) error
+
+	// Groups
+	ListGroups(ctx context.Context, tenantID string, query *domain.ListQuery) (*domain.ListResponse, error)
+	GetGroup(ctx context.Context, tenantID, groupID string) (*domain.Group, error)
+	CreateGroup(ctx context.Context, tenantID string, group *domain.Group) (*domain.Group, error)
+	ReplaceGroup(ctx context.Context, tenantID, groupID string, group *domain.Group) (*domain.Group, error)
+	PatchGroup(ctx context.Context, tenantID, groupID string, patch *domain.PatchRequest) (*domain.Group, error)
+	DeleteGroup(ctx context.Context, tenantID, groupID string) error
+}
@@ -0,0 +1,543 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/scim/ports"
+)
+
+// caseExactAttributes are the attributes compared case-sensitively by filters; the other
+// strings are compared ignoring case (RFC 7643, section 7)
+var caseExactAttributes = map[string]bool{
+	"id":            true,
+	"externalid":    true,
+	"members.value": true,
+	"groups.value":  true,
+	"meta.location": true,
+}
+
+// filterOperators are the comparison operators of filters
+var filterOperators = map[string]bool{
+	"eq": true, "ne": true, "co": true, "sw": true, "ew": true,
+	"gt": true, "ge": true, "lt": true, "le": true, "pr": true,
+}
+
+// attrPath is an attribute reference such as userName, name.givenName or
+// urn:ietf:params:scim:schemas:core:2.0:User:emails
+type attrPath struct {
+	schema string
+	name   string
+	sub    string
+}
+
+// parseAttrPath parses an attribute reference
+func parseAttrPath(s string) (attrPath, error) {
+	var path attrPath
+	if i := strings.LastIndex(s, ":"); i >= 0 {
+		path.schema, s = s[:i], s[i+1:]
+	}
+	path.name, path.sub, _ = strings.Cut(s, ".")
+	if !isAttrName(path.name) || (path.sub != "" && !isAttrName(path.sub)) || strings.Contains(path.sub, ".") {
+		return attrPath{}, fmt.Errorf("invalid attribute %q", s)
+	}
+	return path, nil
+}
+
+// isAttrName checks if a string is a valid attribute name (RFC 7643, section 2.1)
+func isAttrName(s string) bool {
+	if s == "$ref" {
+		return true
+	}
+	for i, r := range s {
+		if r > unicode.MaxASCII || !(unicode.IsLetter(r) || (i > 0 && (unicode.IsDigit(r) || r == '-' || r == '_'))) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// String returns the attribute reference without its schema
+func (p attrPath) String() string {
+	if p.sub == "" {
+		return p.name
+	}
+	return p.name + "." + p.sub
+}
+
+// container returns the object holding the attribute: the extension named by the schema of the
+// reference, or the resource itself for core attributes
+func (p attrPath) container(resource map[string]interface{}) map[string]interface{} {
+	if p.schema != "" {
+		if key, ok := lookupKey(resource, p.schema); ok {
+			if extension, ok := resource[key].(map[string]interface{}); ok {
+				return extension
+			}
+		}
+	}
+	return resource
+}
+
+// values returns the values the attribute reference points to. Multi-valued attributes give
+// one value per item; items that are objects give their value sub-attribute.
+func (p attrPath) values(resource map[string]interface{}) []interface{} {
+	container := p.container(resource)
+	key, ok := lookupKey(container, p.name)
+	if !ok {
+		return nil
+	}
+
+	var values []interface{}
+	collect := func(v interface{}) {
+		if object, ok := v.(map[string]interface{}); ok {
+			sub := p.sub
+			if sub == "" {
+				sub = "value"
+			}
+			if key, ok := lookupKey(object, sub); ok {
+				v = object[key]
+			} else {
+				v = nil
+			}
+		}
+		if v != nil {
+			values = append(values, v)
+		}
+	}
+
+	if items, ok := container[key].([]interface{}); ok {
+		for _, item := range items {
+			collect(item)
+		}
+	} else {
+		collect(container[key])
+	}
+	return values
+}
+
+// lookupKey finds the key of an object that matches an attribute name, ignoring case
+func lookupKey(object map[string]interface{}, name string) (string, bool) {
+	if _, ok := object[name]; ok {
+		return name, true
+	}
+	for key := range object {
+		if strings.EqualFold(key, name) {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+// filterNode is a node of a parsed filter. Resources are matched in their JSON representation;
+// prefix is the attribute holding the resource when it is an item of a multi-valued attribute.
+type filterNode interface {
+	matches(resource map[string]interface{}, prefix string) bool
+}
+
+type logicalFilter struct {
+	and         bool
+	left, right filterNode
+}
+
+func (f *logicalFilter) matches(resource map[string]interface{}, prefix string) bool {
+	if f.and {
+		return f.left.matches(resource, prefix) && f.right.matches(resource, prefix)
+	}
+	return f.left.matches(resource, prefix) || f.right.matches(resource, prefix)
+}
+
+type notFilter struct {
+	filter filterNode
+}
+
+func (f *notFilter) matches(resource map[string]interface{}, prefix string) bool {
+	return !f.filter.matches(resource, prefix)
+}
+
+// valuePathFilter matches resources with an item of a multi-valued attribute that matches a
+// filter, e.g. emails[type eq "work" and value co "@example.com"]
+type valuePathFilter struct {
+	path   attrPath
+	filter filterNode
+}
+
+func (f *valuePathFilter) matches(resource map[string]interface{}, prefix string) bool {
+	container := f.path.container(resource)
+	key, ok := lookupKey(container, f.path.name)
+	if !ok {
+		return false
+	}
+
+	items, ok := container[key].([]interface{})
+	if !ok {
+		items = []interface{}{container[key]}
+	}
+	for _, item := range items {
+		if object, ok := item.(map[string]interface{}); ok && f.filter.matches(object, prefix+f.path.name+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// compareFilter compares the values of an attribute with a literal
+type compareFilter struct {
+	path  attrPath
+	op    string
+	value interface{}
+}
+
+func (f *compareFilter) matches(resource map[string]interface{}, prefix string) bool {
+	values := f.path.values(resource)
+	if f.op == "pr" {
+		for _, v := range values {
+			if s, ok := v.(string); !ok || s != "" {
+				return true
+			}
+		}
+		return false
+	}
+	if len(values) == 0 {
+		return (f.op == "eq" && f.value == nil) || (f.op == "ne" && f.value != nil)
+	}
+
+	// Items of multi-valued attributes are compared by their value sub-attribute
+	name := prefix + f.path.String()
+	if f.path.sub == "" && prefix == "" {
+		container := f.path.container(resource)
+		if key, ok := lookupKey(container, f.path.name); ok {
+			if _, ok := container[key].([]interface{}); ok {
+				name += ".value"
+			}
+		}
+	}
+	caseExact := caseExactAttributes[strings.ToLower(name)]
+
+	for _, v := range values {
+		if compareValues(v, f.op, f.value, caseExact) {
+			return true
+		}
+	}
+	return false
+}
+
+// compareValues applies a comparison operator to a value of a resource and a literal. Values of
+// different types never match.
+func compareValues(actual interface{}, op string, expected interface{}, caseExact bool) bool {
+	switch expected := expected.(type) {
+	case nil:
+		return op == "ne"
+	case bool:
+		actual, ok := actual.(bool)
+		if !ok {
+			return false
+		}
+		switch op {
+		case "eq":
+			return actual == expected
+		case "ne":
+			return actual != expected
+		}
+		return false
+	case float64:
+		actual, ok := actual.(float64)
+		if !ok {
+			return false
+		}
+		return compareOrdered(op, actual < expected, actual == expected)
+	case string:
+		actual, ok := actual.(string)
+		if !ok {
+			return false
+		}
+		switch op {
+		case "gt", "ge", "lt", "le":
+			// Dates are compared as instants
+			actualTime, err1 := time.Parse(time.RFC3339Nano, actual)
+			expectedTime, err2 := time.Parse(time.RFC3339Nano, expected)
+			if err1 == nil && err2 == nil {
+				return compareOrdered(op, actualTime.Before(expectedTime), actualTime.Equal(expectedTime))
+			}
+		}
+		if !caseExact {
+			actual, expected = strings.ToLower(actual), strings.ToLower(expected)
+		}
+		switch op {
+		case "co":
+			return strings.Contains(actual, expected)
+		case "sw":
+			return strings.HasPrefix(actual, expected)
+		case "ew":
+			return strings.HasSuffix(actual, expected)
+		}
+		return compareOrdered(op, actual < expected, actual == expected)
+	}
+	return false
+}
+
+// compareOrdered applies an equality or ordering operator given how two values compare
+func compareOrdered(op string, less, equal bool) bool {
+	switch op {
+	case "eq":
+		return equal
+	case "ne":
+		return !equal
+	case "gt":
+		return !less && !equal
+	case "ge":
+		return !less
+	case "lt":
+		return less
+	case "le":
+		return less || equal
+	}
+	return false
+}
+
+// parseFilter parses a filter (RFC 7644, section 3.4.2.2). An empty filter returns nil.
+func parseFilter(filter string) (filterNode, error) {
+	if strings.TrimSpace(filter) == "" {
+		return nil, nil
+	}
+
+	tokens, err := tokenizeFilter(filter)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ports.ErrInvalidFilter, err)
+	}
+	p := &filterParser{tokens: tokens}
+	node, err := p.parseOr()
+	if err == nil && p.pos < len(p.tokens) {
+		err = fmt.Errorf("unexpected %q", p.tokens[p.pos].text)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ports.ErrInvalidFilter, err)
+	}
+	return node, nil
+}
+
+// filterToken is a token of a filter: a parenthesis or bracket, a string literal or a word
+type filterToken struct {
+	text   string
+	quoted bool
+}
+
+// tokenizeFilter splits a filter into tokens
+func tokenizeFilter(filter string) ([]filterToken, error) {
+	var tokens []filterToken
+	for i := 0; i < len(filter); {
+		c := filter[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case strings.IndexByte("()[]", c) >= 0:
+			tokens = append(tokens, filterToken{text: string(c)})
+			i++
+		case c == '"':
+			j := i + 1
+			for ; j < len(filter) && filter[j] != '"'; j++ {
+				if filter[j] == '\\' {
+					j++
+				}
+			}
+			if j >= len(filter) {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			var text string
+			if err := json.Unmarshal([]byte(filter[i:j+1]), &text); err != nil {
+				return nil, fmt.Errorf("invalid string %s", filter[i:j+1])
+			}
+			tokens = append(tokens, filterToken{text: text, quoted: true})
+			i = j + 1
+		default:
+			j := i
+			for ; j < len(filter) && strings.IndexByte(" \t\n\r()[]\"", filter[j]) < 0; j++ {
+			}
+			tokens = append(tokens, filterToken{text: filter[i:j]})
+			i = j
+		}
+	}
+	return tokens, nil
+}
+
+// filterParser is a recursive descent parser of filters. "not" binds tighter than "and",
+// which binds tighter than "or".
+type filterParser struct {
+	tokens []filterToken
+	pos    int
+}
+
+// peekWord checks if the next token is a word, ignoring case
+func (p *filterParser) peekWord(word string) bool {
+	return p.pos < len(p.tokens) && !p.tokens[p.pos].quoted && strings.EqualFold(p.tokens[p.pos].text, word)
+}
+
+// next returns the next token
+func (p *filterParser) next() (filterToken, error) {
+	if p.pos >= len(p.tokens) {
+		return filterToken{}, fmt.Errorf("unexpected end of filter")
+	}
+	token := p.tokens[p.pos]
+	p.pos++
+	return token, nil
+}
+
+// expect consumes a parenthesis or bracket
+func (p *filterParser) expect(text string) error {
+	token, err := p.next()
+	if err != nil {
+		return err
+	}
+	if token.quoted || token.text != text {
+		return fmt.Errorf("expected %q, got %q", text, token.text)
+	}
+	return nil
+}
+
+func (p *filterParser) parseOr() (filterNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekWord("or") {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalFilter{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (filterNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekWord("and") {
+		p.pos++
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalFilter{and: true, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseUnary() (filterNode, error) {
+	if p.peekWord("not") {
+		p.pos++
+		if err := p.expect("("); err != nil {
+			return nil, err
+		}
+		filter, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return &notFilter{filter: filter}, nil
+	}
+
+	token, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	if token.quoted {
+		return nil, fmt.Errorf("expected an attribute, got %q", token.text)
+	}
+	if token.text == "(" {
+		filter, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return filter, nil
+	}
+
+	path, err := parseAttrPath(token.text)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.pos < len(p.tokens) && !p.tokens[p.pos].quoted && p.tokens[p.pos].text == "[" {
+		p.pos++
+		filter, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+		if path.sub != "" {
+			return nil, fmt.Errorf("invalid attribute %q", token.text)
+		}
+		return &valuePathFilter{path: path, filter: filter}, nil
+	}
+
+	opToken, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	op := strings.ToLower(opToken.text)
+	if opToken.quoted || !filterOperators[op] {
+		return nil, fmt.Errorf("invalid operator %q", opToken.text)
+	}
+	if op == "pr" {
+		return &compareFilter{path: path, op: op}, nil
+	}
+
+	valueToken, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	value, err := filterLiteral(valueToken)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := value.(string); !ok && (op == "co" || op == "sw" || op == "ew") {
+		return nil, fmt.Errorf("operator %s needs a string", op)
+	}
+	return &compareFilter{path: path, op: op, value: value}, nil
+}
+
+// filterLiteral decodes the value compared by a filter: a string, number, boolean or null
+func filterLiteral(token filterToken) (interface{}, error) {
+	if token.quoted {
+		return token.text, nil
+	}
+	switch strings.ToLower(token.text) {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	}
+	var number float64
+	if err := json.Unmarshal([]byte(token.text), &number); err != nil {
+		return nil, fmt.Errorf("invalid value %q", token.text)
+	}
+	return number, nil
+}
+
+// filterEquality returns the attribute and string value of a filter that is a single eq
+// comparison, so that the repository can narrow what it reads
+func filterEquality(filter filterNode) (string, string, bool) {
+	compare, ok := filter.(*compareFilter)
+	if !ok || compare.op != "eq" {
+		return "", "", false
+	}
+	value, ok := compare.value.(string)
+	if !ok {
+		return "", "", false
+	}
+	return strings.ToLower(compare.path.String()), value, true
+}
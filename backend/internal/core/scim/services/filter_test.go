@@ -0,0 +1,135 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/scim/ports"
+)
+
+// filterUser is the JSON representation of a user the filters are matched against
+const filterUser = `{
+	"schemas": ["urn:ietf:params:scim:schemas:core:2.0:User"],
+	"id": "2819c223-7f76-453a-919d-413861904646",
+	"externalId": "00u1abcd",
+	"userName": "Ana.Rojas@example.com",
+	"name": {"givenName": "Ana", "familyName": "Rojas"},
+	"emails": [
+		{"value": "ana.rojas@example.com", "type": "work", "primary": true},
+		{"value": "ana@home.example", "type": "home"}
+	],
+	"active": true,
+	"groups": [{"value": "a1b2c3d4-0000-4000-8000-000000000001", "display": "Interns"}],
+	"meta": {"resourceType": "User", "lastModified": "2024-05-01T10:00:00Z"}
+}`
+
+func decodeResource(t *testing.T, data string) map[string]interface{} {
+	t.Helper()
+	var resource map[string]interface{}
+	if err := json.Unmarshal([]byte(data), &resource); err != nil {
+		t.Fatalf("invalid resource: %v", err)
+	}
+	return resource
+}
+
+func TestParseFilterMatches(t *testing.T) {
+	user := decodeResource(t, filterUser)
+
+	tests := []struct {
+		filter   string
+		expected bool
+	}{
+		{`userName eq "ana.rojas@example.com"`, true},
+		{`USERNAME Eq "ANA.ROJAS@EXAMPLE.COM"`, true},
+		{`userName eq "someone@example.com"`, false},
+		{`userName ne "someone@example.com"`, true},
+		{`urn:ietf:params:scim:schemas:core:2.0:User:userName sw "ana."`, true},
+		{`externalId eq "00u1abcd"`, true},
+		{`externalId eq "00U1ABCD"`, false},
+		{`name.familyName co "roj"`, true},
+		{`name.givenName ew "x"`, false},
+		{`emails.value eq "ana@home.example"`, true},
+		{`emails eq "ana@home.example"`, true},
+		{`emails[type eq "work" and value co "rojas"]`, true},
+		{`emails[type eq "work" and value co "home"]`, false},
+		{`groups.value eq "A1B2C3D4-0000-4000-8000-000000000001"`, false},
+		{`groups.value eq "a1b2c3d4-0000-4000-8000-000000000001"`, true},
+		{`active eq true`, true},
+		{`active eq false`, false},
+		{`title pr`, false},
+		{`externalId pr`, true},
+		{`title eq null`, true},
+		{`meta.lastModified gt "2024-04-30T00:00:00Z"`, true},
+		{`meta.lastModified lt "2024-05-01T09:00:00-03:00"`, true},
+		{`userName eq "nobody@example.com" or active eq true`, true},
+		{`not (active eq true) or userName sw "z"`, false},
+		{`(userName sw "a" or userName sw "b") and not (externalId eq "x")`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			filter, err := parseFilter(tt.filter)
+			if err != nil {
+				t.Fatalf("parseFilter(%q) failed: %v", tt.filter, err)
+			}
+			if result := filter.matches(user, ""); result != tt.expected {
+				t.Errorf("%q matched = %v, want %v", tt.filter, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseFilterErrors(t *testing.T) {
+	filters := []string{
+		`userName`,
+		`userName eq`,
+		`userName like "ana"`,
+		`userName eq "ana`,
+		`(userName eq "ana"`,
+		`emails[type eq "work"`,
+		`emails[type eq "home"].value eq "ana@home.example"`,
+		`userName eq "ana" and`,
+		`userName eq ana`,
+	}
+
+	for _, filter := range filters {
+		t.Run(filter, func(t *testing.T) {
+			if _, err := parseFilter(filter); !errors.Is(err, ports.ErrInvalidFilter) {
+				t.Errorf("parseFilter(%q) = %v, want ErrInvalidFilter", filter, err)
+			}
+		})
+	}
+
+	if filter, err := parseFilter("  "); err != nil || filter != nil {
+		t.Errorf("Expected an empty filter to match everything, got %v, %v", filter, err)
+	}
+}
+
+func TestFilterEquality(t *testing.T) {
+	tests := []struct {
+		filter    string
+		attribute string
+		value     string
+		ok        bool
+	}{
+		{`userName eq "ana@example.com"`, "username", "ana@example.com", true},
+		{`urn:ietf:params:scim:schemas:core:2.0:User:externalId eq "00u1"`, "externalid", "00u1", true},
+		{`userName sw "ana"`, "", "", false},
+		{`userName eq "ana@example.com" and active eq true`, "", "", false},
+		{`active eq true`, "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			filter, err := parseFilter(tt.filter)
+			if err != nil {
+				t.Fatalf("parseFilter(%q) failed: %v", tt.filter, err)
+			}
+			attribute, value, ok := filterEquality(filter)
+			if attribute != tt.attribute || value != tt.value || ok != tt.ok {
+				t.Errorf("filterEquality(%q) = %q, %q, %v, want %q, %q, %v", tt.filter, attribute, value, ok, tt.attribute, tt.value, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,400 @@
+package services
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/scim/domain"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/scim/ports"
+)
+
+// readOnlyAttributes can't be changed by PATCH operations
+var readOnlyAttributes = map[string]bool{
+	"id":      true,
+	"meta":    true,
+	"schemas": true,
+}
+
+// patchPath is the target of a PATCH operation: an attribute, optionally narrowed to the items
+// of a multi-valued attribute that match a filter and to one of their sub-attributes, e.g.
+// members[value eq "2819c223"] or emails[type eq "work"].value
+type patchPath struct {
+	attr   attrPath
+	filter filterNode
+	sub    string
+}
+
+// parsePatchPath parses the path of a PATCH operation
+func parsePatchPath(path string) (*patchPath, error) {
+	head, rest, hasFilter := strings.Cut(path, "[")
+	attr, err := parseAttrPath(head)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ports.ErrInvalidPath, err)
+	}
+	target := &patchPath{attr: attr}
+	if !hasFilter {
+		return target, nil
+	}
+
+	end := strings.LastIndex(rest, "]")
+	if end < 0 || attr.sub != "" {
+		return nil, fmt.Errorf("%w: %q", ports.ErrInvalidPath, path)
+	}
+	target.filter, err = parseFilter(rest[:end])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q: %v", ports.ErrInvalidPath, path, err)
+	}
+	if target.filter == nil {
+		return nil, fmt.Errorf("%w: %q has an empty filter", ports.ErrInvalidPath, path)
+	}
+
+	if tail := rest[end+1:]; tail != "" {
+		if !strings.HasPrefix(tail, ".") || !isAttrName(tail[1:]) {
+			return nil, fmt.Errorf("%w: %q", ports.ErrInvalidPath, path)
+		}
+		target.sub = tail[1:]
+	}
+	return target, nil
+}
+
+// applyPatch applies the operations of a PATCH request, in order, to the JSON representation of
+// a resource (RFC 7644, section 3.5.2). Operation names are not case sensitive, and a value
+// without a path may name attributes by their path, as some identity providers send them.
+func applyPatch(resource map[string]interface{}, operations []domain.PatchOperation) error {
+	if len(operations) == 0 {
+		return fmt.Errorf("%w: no operations", ports.ErrInvalidSyntax)
+	}
+
+	for i, operation := range operations {
+		if err := applyPatchOperation(resource, operation); err != nil {
+			return fmt.Errorf("operation %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// applyPatchOperation applies one operation of a PATCH request
+func applyPatchOperation(resource map[string]interface{}, operation domain.PatchOperation) error {
+	op := strings.ToLower(operation.Op)
+	if op != "add" && op != "replace" && op != "remove" {
+		return fmt.Errorf("%w: invalid op %q", ports.ErrInvalidSyntax, operation.Op)
+	}
+
+	if operation.Path != "" {
+		path, err := parsePatchPath(operation.Path)
+		if err != nil {
+			return err
+		}
+		return applyPatchPath(resource, op, path, operation.Value)
+	}
+
+	if op == "remove" {
+		return fmt.Errorf("%w: remove needs a path", ports.ErrNoTarget)
+	}
+	values, ok := operation.Value.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("%w: value must be an object when there is no path", ports.ErrInvalidValue)
+	}
+
+	for key, value := range values {
+		// Extension attributes are grouped under the schema of the extension
+		if extension, ok := value.(map[string]interface{}); ok && strings.HasPrefix(strings.ToLower(key), "urn:") {
+			for name, value := range extension {
+				path, err := parsePatchPath(key + ":" + name)
+				if err != nil {
+					return err
+				}
+				if err := applyPatchPath(resource, op, path, value); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		path, err := parsePatchPath(key)
+		if err != nil {
+			return err
+		}
+		if readOnlyAttributes[strings.ToLower(path.attr.name)] && path.attr.schema == "" {
+			// Resources sent back whole keep their read-only attributes
+			continue
+		}
+		if err := applyPatchPath(resource, op, path, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyPatchPath applies an operation to the target of a path
+func applyPatchPath(resource map[string]interface{}, op string, path *patchPath, value interface{}) error {
+	if readOnlyAttributes[strings.ToLower(path.attr.name)] {
+		return fmt.Errorf("%w: %s is read-only", ports.ErrMutability, path.attr.name)
+	}
+	if op != "remove" && value == nil {
+		return fmt.Errorf("%w: %s needs a value", ports.ErrInvalidValue, op)
+	}
+
+	container := path.attr.container(resource)
+	if path.attr.schema != "" && !isCoreSchema(path.attr.schema) && sameMap(container, resource) {
+		if op == "remove" {
+			return nil
+		}
+		container = map[string]interface{}{}
+		resource[path.attr.schema] = container
+	}
+
+	key, exists := lookupKey(container, path.attr.name)
+	if !exists {
+		key = path.attr.name
+	}
+
+	if path.filter != nil {
+		return patchMatchingItems(container, key, op, path, value)
+	}
+
+	if path.attr.sub != "" {
+		return patchSubAttribute(container, key, op, path.attr.sub, value)
+	}
+
+	switch op {
+	case "remove":
+		items, isList := container[key].([]interface{})
+		if value == nil || !isList {
+			delete(container, key)
+			return nil
+		}
+		// Items to remove may be given as the value, as some identity providers do
+		container[key] = removeItems(items, toList(value))
+	case "add":
+		switch current := container[key].(type) {
+		case []interface{}:
+			container[key] = addItems(current, toList(value))
+		case map[string]interface{}:
+			object, ok := value.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("%w: %s needs an object", ports.ErrInvalidValue, path.attr.name)
+			}
+			mergeObject(current, object)
+		default:
+			container[key] = value
+		}
+	case "replace":
+		current, isObject := container[key].(map[string]interface{})
+		if object, ok := value.(map[string]interface{}); ok && isObject {
+			mergeObject(current, object)
+		} else {
+			container[key] = value
+		}
+	}
+	return nil
+}
+
+// patchSubAttribute applies an operation to a sub-attribute of a complex attribute, or of every
+// item of a multi-valued attribute
+func patchSubAttribute(container map[string]interface{}, key, op, sub string, value interface{}) error {
+	var objects []map[string]interface{}
+	switch current := container[key].(type) {
+	case []interface{}:
+		for _, item := range current {
+			if object, ok := item.(map[string]interface{}); ok {
+				objects = append(objects, object)
+			}
+		}
+	case map[string]interface{}:
+		objects = append(objects, current)
+	case nil:
+		if op == "remove" {
+			return nil
+		}
+		object := map[string]interface{}{}
+		container[key] = object
+		objects = append(objects, object)
+	default:
+		return fmt.Errorf("%w: %s has no sub-attributes", ports.ErrInvalidPath, key)
+	}
+
+	for _, object := range objects {
+		setSubAttribute(object, op, sub, value)
+	}
+	return nil
+}
+
+// patchMatchingItems applies an operation to the items of a multi-valued attribute that match
+// the filter of a path
+func patchMatchingItems(container map[string]interface{}, key, op string, path *patchPath, value interface{}) error {
+	items, _ := container[key].([]interface{})
+
+	var kept []interface{}
+	matched := 0
+	for _, item := range items {
+		object, ok := item.(map[string]interface{})
+		if !ok || !path.filter.matches(object, path.attr.name+".") {
+			kept = append(kept, item)
+			continue
+		}
+		matched++
+
+		switch {
+		case op == "remove" && path.sub == "":
+			continue
+		case path.sub != "":
+			setSubAttribute(object, op, path.sub, value)
+		default:
+			replacement, ok := value.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("%w: %s needs an object", ports.ErrInvalidValue, path.attr.name)
+			}
+			if op == "add" {
+				mergeObject(object, replacement)
+			} else {
+				item = replacement
+			}
+		}
+		kept = append(kept, item)
+	}
+
+	if matched == 0 {
+		if op == "remove" {
+			return nil
+		}
+		// Adding to an item that doesn't exist yet, e.g. emails[type eq "work"].value, creates it
+		item, ok := itemFromFilter(path.filter)
+		if op != "add" || !ok {
+			return fmt.Errorf("%w: no %s matches the filter", ports.ErrNoTarget, path.attr.name)
+		}
+		if path.sub != "" {
+			item[path.sub] = value
+		} else if object, ok := value.(map[string]interface{}); ok {
+			mergeObject(item, object)
+		} else {
+			return fmt.Errorf("%w: %s needs an object", ports.ErrInvalidValue, path.attr.name)
+		}
+		kept = append(kept, item)
+	}
+
+	if kept == nil {
+		delete(container, key)
+	} else {
+		container[key] = kept
+	}
+	return nil
+}
+
+// itemFromFilter builds the item described by a filter made of eq comparisons joined by and
+func itemFromFilter(filter filterNode) (map[string]interface{}, bool) {
+	switch filter := filter.(type) {
+	case *compareFilter:
+		if filter.op != "eq" || filter.path.sub != "" || filter.value == nil {
+			return nil, false
+		}
+		return map[string]interface{}{filter.path.name: filter.value}, true
+	case *logicalFilter:
+		if !filter.and {
+			return nil, false
+		}
+		left, ok := itemFromFilter(filter.left)
+		if !ok {
+			return nil, false
+		}
+		right, ok := itemFromFilter(filter.right)
+		if !ok {
+			return nil, false
+		}
+		mergeObject(left, right)
+		return left, true
+	}
+	return nil, false
+}
+
+// setSubAttribute sets or removes a sub-attribute of an object
+func setSubAttribute(object map[string]interface{}, op, sub string, value interface{}) {
+	key, ok := lookupKey(object, sub)
+	if !ok {
+		key = sub
+	}
+	if op == "remove" {
+		delete(object, key)
+	} else {
+		object[key] = value
+	}
+}
+
+// mergeObject sets the attributes of src on dst, matching their names ignoring case
+func mergeObject(dst, src map[string]interface{}) {
+	for name, value := range src {
+		key, ok := lookupKey(dst, name)
+		if !ok {
+			key = name
+		}
+		dst[key] = value
+	}
+}
+
+// addItems appends to a multi-valued attribute the values it doesn't have yet
+func addItems(items, values []interface{}) []interface{} {
+	for _, value := range values {
+		if indexOfItem(items, value) < 0 {
+			items = append(items, value)
+		}
+	}
+	return items
+}
+
+// removeItems removes values from a multi-valued attribute
+func removeItems(items, values []interface{}) []interface{} {
+	kept := []interface{}{}
+	for _, item := range items {
+		if indexOfItem(values, item) < 0 {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
+
+// indexOfItem finds a value in a multi-valued attribute. Items with a value sub-attribute, such
+// as members, are the same when their values are.
+func indexOfItem(items []interface{}, value interface{}) int {
+	for i, item := range items {
+		if itemValue(item) == itemValue(value) && itemValue(item) != nil {
+			return i
+		}
+		if reflect.DeepEqual(item, value) {
+			return i
+		}
+	}
+	return -1
+}
+
+// itemValue returns the value sub-attribute of an item, or nil
+func itemValue(item interface{}) interface{} {
+	object, ok := item.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	if key, ok := lookupKey(object, "value"); ok {
+		if value, ok := object[key].(string); ok {
+			return value
+		}
+	}
+	return nil
+}
+
+// toList returns the items of a value that is a list, or the value as the only item
+func toList(value interface{}) []interface{} {
+	if items, ok := value.([]interface{}); ok {
+		return items
+	}
+	return []interface{}{value}
+}
+
+// isCoreSchema checks if a schema is the schema of a core resource
+func isCoreSchema(schema string) bool {
+	return strings.EqualFold(schema, domain.SchemaUser) || strings.EqualFold(schema, domain.SchemaGroup)
+}
+
+// sameMap checks if two maps are the same object
+func sameMap(a, b map[string]interface{}) bool {
+	return reflect.ValueOf(a).Pointer() == reflect.ValueOf(b).Pointer()
+}
@@ -0,0 +1,216 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/scim/domain"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/scim/ports"
+)
+
+// patchGroup is the JSON representation of a group the operations are applied to
+const patchGroup = `{
+	"schemas": ["urn:ietf:params:scim:schemas:core:2.0:Group", "urn:stegmaier:params:scim:schemas:extension:cohort:2.0:Group"],
+	"id": "a1b2c3d4-0000-4000-8000-000000000001",
+	"displayName": "Interns",
+	"members": [
+		{"value": "u1", "display": "Ana Rojas"},
+		{"value": "u2", "display": "Luis Soto"}
+	],
+	"urn:stegmaier:params:scim:schemas:extension:cohort:2.0:Group": {"courseIds": ["c1"]}
+}`
+
+// patchUser is the JSON representation of a user the operations are applied to
+const patchUser = `{
+	"schemas": ["urn:ietf:params:scim:schemas:core:2.0:User"],
+	"id": "u1",
+	"userName": "ana@example.com",
+	"name": {"givenName": "Ana", "familyName": "Rojas"},
+	"emails": [{"value": "ana@example.com", "type": "work", "primary": true}],
+	"active": true
+}`
+
+func decodeOperations(t *testing.T, data string) []domain.PatchOperation {
+	t.Helper()
+	var operations []domain.PatchOperation
+	if err := json.Unmarshal([]byte(data), &operations); err != nil {
+		t.Fatalf("invalid operations: %v", err)
+	}
+	return operations
+}
+
+func TestApplyPatch(t *testing.T) {
+	tests := []struct {
+		name       string
+		resource   string
+		operations string
+		expected   map[string]interface{}
+	}{
+		{
+			name:       "Replace without path with string booleans",
+			resource:   patchUser,
+			operations: `[{"op": "Replace", "value": {"active": "False", "id": "ignored"}}]`,
+			expected:   map[string]interface{}{"active": "False", "id": "u1"},
+		},
+		{
+			name:       "Replace sub-attribute",
+			resource:   patchUser,
+			operations: `[{"op": "replace", "path": "name.givenName", "value": "Ana María"}]`,
+			expected: map[string]interface{}{
+				"name": map[string]interface{}{"givenName": "Ana María", "familyName": "Rojas"},
+			},
+		},
+		{
+			name:       "Replace complex attribute merges it",
+			resource:   patchUser,
+			operations: `[{"op": "replace", "path": "name", "value": {"familyName": "Rojas Vega"}}]`,
+			expected: map[string]interface{}{
+				"name": map[string]interface{}{"givenName": "Ana", "familyName": "Rojas Vega"},
+			},
+		},
+		{
+			name:       "Replace attribute named by its path without path",
+			resource:   patchUser,
+			operations: `[{"op": "replace", "value": {"name.familyName": "Vega", "externalId": "00u1"}}]`,
+			expected: map[string]interface{}{
+				"name":       map[string]interface{}{"givenName": "Ana", "familyName": "Vega"},
+				"externalId": "00u1",
+			},
+		},
+		{
+			name:       "Replace value of filtered item",
+			resource:   patchUser,
+			operations: `[{"op": "replace", "path": "emails[type eq \"work\"].value", "value": "ana.rojas@example.com"}]`,
+			expected: map[string]interface{}{
+				"emails": []interface{}{
+					map[string]interface{}{"value": "ana.rojas@example.com", "type": "work", "primary": true},
+				},
+			},
+		},
+		{
+			name:       "Add to filtered item that doesn't exist creates it",
+			resource:   patchUser,
+			operations: `[{"op": "add", "path": "emails[type eq \"home\"].value", "value": "ana@home.example"}]`,
+			expected: map[string]interface{}{
+				"emails": []interface{}{
+					map[string]interface{}{"value": "ana@example.com", "type": "work", "primary": true},
+					map[string]interface{}{"value": "ana@home.example", "type": "home"},
+				},
+			},
+		},
+		{
+			name:       "Add members skips existing ones",
+			resource:   patchGroup,
+			operations: `[{"op": "add", "path": "members", "value": [{"value": "u2"}, {"value": "u3"}]}]`,
+			expected: map[string]interface{}{
+				"members": []interface{}{
+					map[string]interface{}{"value": "u1", "display": "Ana Rojas"},
+					map[string]interface{}{"value": "u2", "display": "Luis Soto"},
+					map[string]interface{}{"value": "u3"},
+				},
+			},
+		},
+		{
+			name:       "Remove filtered member",
+			resource:   patchGroup,
+			operations: `[{"op": "remove", "path": "members[value eq \"u1\"]"}]`,
+			expected: map[string]interface{}{
+				"members": []interface{}{
+					map[string]interface{}{"value": "u2", "display": "Luis Soto"},
+				},
+			},
+		},
+		{
+			name:       "Remove members given as value",
+			resource:   patchGroup,
+			operations: `[{"op": "Remove", "path": "members", "value": [{"value": "u2"}]}]`,
+			expected: map[string]interface{}{
+				"members": []interface{}{
+					map[string]interface{}{"value": "u1", "display": "Ana Rojas"},
+				},
+			},
+		},
+		{
+			name:       "Remove filtered member that doesn't exist",
+			resource:   patchGroup,
+			operations: `[{"op": "remove", "path": "members[value eq \"u9\"]"}]`,
+			expected: map[string]interface{}{
+				"members": []interface{}{
+					map[string]interface{}{"value": "u1", "display": "Ana Rojas"},
+					map[string]interface{}{"value": "u2", "display": "Luis Soto"},
+				},
+			},
+		},
+		{
+			name:       "Replace extension attribute by path",
+			resource:   patchGroup,
+			operations: `[{"op": "replace", "path": "urn:stegmaier:params:scim:schemas:extension:cohort:2.0:Group:courseIds", "value": ["c2", "c3"]}]`,
+			expected: map[string]interface{}{
+				"urn:stegmaier:params:scim:schemas:extension:cohort:2.0:Group": map[string]interface{}{
+					"courseIds": []interface{}{"c2", "c3"},
+				},
+			},
+		},
+		{
+			name:       "Add extension attribute without path",
+			resource:   patchGroup,
+			operations: `[{"op": "add", "value": {"urn:stegmaier:params:scim:schemas:extension:cohort:2.0:Group": {"courseIds": ["c2"]}}}]`,
+			expected: map[string]interface{}{
+				"urn:stegmaier:params:scim:schemas:extension:cohort:2.0:Group": map[string]interface{}{
+					"courseIds": []interface{}{"c1", "c2"},
+				},
+			},
+		},
+		{
+			name:       "Operations apply in order",
+			resource:   patchGroup,
+			operations: `[{"op": "remove", "path": "members"}, {"op": "add", "path": "members", "value": [{"value": "u3"}]}, {"op": "replace", "path": "displayName", "value": "Interns 2024"}]`,
+			expected: map[string]interface{}{
+				"displayName": "Interns 2024",
+				"members":     []interface{}{map[string]interface{}{"value": "u3"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resource := decodeResource(t, tt.resource)
+			if err := applyPatch(resource, decodeOperations(t, tt.operations)); err != nil {
+				t.Fatalf("applyPatch failed: %v", err)
+			}
+			for key, expected := range tt.expected {
+				if !reflect.DeepEqual(resource[key], expected) {
+					t.Errorf("%s = %#v, want %#v", key, resource[key], expected)
+				}
+			}
+		})
+	}
+}
+
+func TestApplyPatchErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		operations string
+		expected   error
+	}{
+		{"No operations", `[]`, ports.ErrInvalidSyntax},
+		{"Unknown op", `[{"op": "move", "path": "displayName", "value": "x"}]`, ports.ErrInvalidSyntax},
+		{"Read-only attribute", `[{"op": "replace", "path": "id", "value": "x"}]`, ports.ErrMutability},
+		{"Remove without path", `[{"op": "remove"}]`, ports.ErrNoTarget},
+		{"Value without path must be an object", `[{"op": "add", "value": ["x"]}]`, ports.ErrInvalidValue},
+		{"Add without value", `[{"op": "add", "path": "displayName"}]`, ports.ErrInvalidValue},
+		{"Invalid path", `[{"op": "replace", "path": "members[value eq]", "value": "x"}]`, ports.ErrInvalidPath},
+		{"Replace filtered item that doesn't exist", `[{"op": "replace", "path": "members[value eq \"u9\"].display", "value": "x"}]`, ports.ErrNoTarget},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resource := decodeResource(t, patchGroup)
+			if err := applyPatch(resource, decodeOperations(t, tt.operations)); !errors.Is(err, tt.expected) {
+				t.Errorf("applyPatch = %v, want %v", err, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,1007 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	authdomain "github.com/DanielIturra1610/stegmaier-landing/internal/core/auth/domain"
+	authports "github.com/DanielIturra1610/stegmaier-landing/internal/core/auth/ports"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/scim/domain"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/scim/ports"
+	tenantdomain "github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/domain"
+	tenantports "github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/ports"
+	userdomain "github.com/DanielIturra1610/stegmaier-landing/internal/core/user/domain"
+	userports "github.com/DanielIturra1610/stegmaier-landing/internal/core/user/ports"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+const (
+	// defaultListCount is the page size of list requests that don't ask for one
+	defaultListCount = 100
+
+	// maxListCount is the largest page size of list requests
+	maxListCount = 200
+)
+
+// SCIMService implements the SCIMService interface
+type SCIMService struct {
+	repo        ports.SCIMRepository
+	tenantRepo  tenantports.TenantRepository
+	userService userports.UserManagementService
+	accounts    ports.AccountVerifier
+	enroller    tenantports.MemberEnroller
+	quota       tenantports.QuotaService
+	validator   *validator.Validate
+	baseURL     string
+}
+
+// NewSCIMService creates a new SCIM service. baseURL is the public URL of the API, used in the
+// location of the resources.
+func NewSCIMService(
+	repo ports.SCIMRepository,
+	tenantRepo tenantports.TenantRepository,
+	userService userports.UserManagementService,
+	accounts ports.AccountVerifier,
+	enroller tenantports.MemberEnroller,
+	quota tenantports.QuotaService,
+	baseURL string,
+) ports.SCIMService {
+	return &SCIMService{
+		repo:        repo,
+		tenantRepo:  tenantRepo,
+		userService: userService,
+		accounts:    accounts,
+		enroller:    enroller,
+		quota:       quota,
+		validator:   validator.New(),
+		baseURL:     strings.TrimSuffix(baseURL, "/"),
+	}
+}
+
+// ============================================================
+// Discovery
+// ============================================================
+
+// GetServiceProviderConfig describes the SCIM features the API supports
+func (s *SCIMService) GetServiceProviderConfig() map[string]interface{} {
+	return map[string]interface{}{
+		"schemas":        []string{domain.SchemaServiceProviderConfig},
+		"patch":          map[string]interface{}{"supported": true},
+		"bulk":           map[string]interface{}{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":         map[string]interface{}{"supported": true, "maxResults": maxListCount},
+		"changePassword": map[string]interface{}{"supported": false},
+		"sort":           map[string]interface{}{"supported": false},
+		"etag":           map[string]interface{}{"supported": false},
+		"authenticationSchemes": []map[string]interface{}{{
+			"type":        "oauthbearertoken",
+			"name":        "API key",
+			"description": "Tenant API key with the scim:read and scim:write scopes, sent as a bearer token",
+			"primary":     true,
+		}},
+		"meta": map[string]interface{}{
+			"resourceType": "ServiceProviderConfig",
+			"location":     s.baseURL + "/scim/v2/ServiceProviderConfig",
+		},
+	}
+}
+
+// ListResourceTypes describes the resources the API provisions
+func (s *SCIMService) ListResourceTypes() *domain.ListResponse {
+	resourceTypes := []map[string]interface{}{
+		{
+			"schemas":  []string{domain.SchemaResourceType},
+			"id":       domain.ResourceTypeUser,
+			"name":     domain.ResourceTypeUser,
+			"endpoint": "/Users",
+			"schema":   domain.SchemaUser,
+			"meta": map[string]interface{}{
+				"resourceType": "ResourceType",
+				"location":     s.baseURL + "/scim/v2/ResourceTypes/" + domain.ResourceTypeUser,
+			},
+		},
+		{
+			"schemas":     []string{domain.SchemaResourceType},
+			"id":          domain.ResourceTypeGroup,
+			"name":        domain.ResourceTypeGroup,
+			"endpoint":    "/Groups",
+			"description": "Cohorts of members enrolled in the same courses",
+			"schema":      domain.SchemaGroup,
+			"schemaExtensions": []map[string]interface{}{
+				{"schema": domain.SchemaCohort, "required": false},
+			},
+			"meta": map[string]interface{}{
+				"resourceType": "ResourceType",
+				"location":     s.baseURL + "/scim/v2/ResourceTypes/" + domain.ResourceTypeGroup,
+			},
+		},
+	}
+
+	return &domain.ListResponse{
+		Schemas:      []string{domain.SchemaListResponse},
+		TotalResults: len(resourceTypes),
+		StartIndex:   1,
+		ItemsPerPage: len(resourceTypes),
+		Resources:    resourceTypes,
+	}
+}
+
+// ============================================================
+// Users
+// ============================================================
+
+// ListUsers lists the members of a tenant that match a filter. Lookups by userName or externalId,
+// the ones identity providers run before provisioning a user, are narrowed in the database.
+func (s *SCIMService) ListUsers(ctx context.Context, tenantID string, query *domain.ListQuery) (*domain.ListResponse, error) {
+	filter, err := parseFilter(query.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var lookup domain.MemberLookup
+	if attribute, value, ok := filterEquality(filter); ok {
+		switch attribute {
+		case "username":
+			lookup.Email = value
+		case "externalid":
+			lookup.ExternalID = value
+		}
+	}
+
+	members, err := s.repo.ListMembers(ctx, tenantID, lookup)
+	if err != nil {
+		return nil, err
+	}
+	cohorts, err := s.repo.ListCohorts(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := []map[string]interface{}{}
+	for _, member := range members {
+		resource, err := toResource(s.toUser(member, cohorts))
+		if err != nil {
+			return nil, err
+		}
+		if filter == nil || filter.matches(resource, "") {
+			resources = append(resources, resource)
+		}
+	}
+
+	return listResponse(resources, query), nil
+}
+
+// GetUser retrieves a member of a tenant
+func (s *SCIMService) GetUser(ctx context.Context, tenantID, userID string) (*domain.User, error) {
+	member, err := s.repo.GetMember(ctx, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+	cohorts, err := s.repo.ListCohorts(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return s.toUser(member, cohorts), nil
+}
+
+// CreateUser adds a user to a tenant as a student. Users that don't have an account get one, with
+// the password of the resource or a random one when there is none (e.g. when they sign in with
+// SSO); users that have an account keep their password and email. Admins and members of the
+// tenant with other roles are refused.
+func (s *SCIMService) CreateUser(ctx context.Context, tenantID string, user *domain.User) (*domain.User, error) {
+	email, err := s.userEmail(user)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.repo.ListMembers(ctx, tenantID, domain.MemberLookup{Email: email})
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) > 0 {
+		return nil, fmt.Errorf("%w: userName %s", ports.ErrUniqueness, email)
+	}
+	if user.ExternalID != "" {
+		existing, err := s.repo.ListMembers(ctx, tenantID, domain.MemberLookup{ExternalID: user.ExternalID})
+		if err != nil {
+			return nil, err
+		}
+		if len(existing) > 0 {
+			return nil, fmt.Errorf("%w: externalId %s", ports.ErrUniqueness, user.ExternalID)
+		}
+	}
+
+	userID, err := s.tenantRepo.GetUserIDByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	if userID != "" {
+		// Admins and members with other roles are managed in the tenant, not by the identity provider
+		unmanaged, err := s.repo.IsUnmanagedUser(ctx, tenantID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if unmanaged {
+			return nil, fmt.Errorf("%w: userName %s belongs to a user that is not provisioned through SCIM", ports.ErrUniqueness, email)
+		}
+	}
+
+	active := user.Active == nil || bool(*user.Active)
+	if active {
+		if err := s.quota.CheckLearnerQuota(ctx, tenantID, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	createdBySCIM := userID == ""
+	if createdBySCIM {
+		password := user.Password
+		if password == "" {
+			if password, err = generatePassword(); err != nil {
+				return nil, fmt.Errorf("failed to generate password: %w", err)
+			}
+		}
+
+		fullName := memberFullName(user, nil)
+		if len(fullName) < 2 {
+			fullName, _, _ = strings.Cut(email, "@")
+		}
+
+		created, err := s.userService.CreateUser(ctx, &userdomain.CreateUserDTO{
+			Email:    email,
+			Password: password,
+			FullName: fullName,
+			Roles:    []string{domain.ProvisionedRole},
+			TenantID: tenantID,
+		})
+		if err != nil {
+			if errors.Is(err, authports.ErrPasswordTooWeak) || errors.Is(err, authports.ErrPasswordBreached) {
+				return nil, fmt.Errorf("%w: %v", ports.ErrInvalidValue, err)
+			}
+			return nil, fmt.Errorf("failed to create user: %w", err)
+		}
+		userID = created.ID
+	}
+
+	status := "inactive"
+	if active {
+		status = "active"
+	}
+	now := time.Now()
+	membership := &tenantdomain.TenantMembership{
+		UserID:    userID,
+		TenantID:  tenantID,
+		Role:      domain.ProvisionedRole,
+		Status:    status,
+		InvitedAt: &now,
+		JoinedAt:  &now,
+	}
+	if err := s.tenantRepo.CreateMembership(ctx, membership); err != nil {
+		return nil, fmt.Errorf("failed to create membership: %w", err)
+	}
+	if createdBySCIM {
+		if err := s.repo.MarkMemberCreatedBySCIM(ctx, tenantID, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	if user.ExternalID != "" {
+		if err := s.repo.SetMemberExternalID(ctx, tenantID, userID, &user.ExternalID); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.GetUser(ctx, tenantID, userID)
+}
+
+// ReplaceUser updates a member from the whole resource. Attributes the resource leaves out, such
+// as active, keep their values.
+func (s *SCIMService) ReplaceUser(ctx context.Context, tenantID, userID string, user *domain.User) (*domain.User, error) {
+	member, err := s.repo.GetMember(ctx, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.updateMember(ctx, tenantID, member, user); err != nil {
+		return nil, err
+	}
+	return s.GetUser(ctx, tenantID, userID)
+}
+
+// PatchUser applies the operations of a PATCH request to a member
+func (s *SCIMService) PatchUser(ctx context.Context, tenantID, userID string, patch *domain.PatchRequest) (*domain.User, error) {
+	member, err := s.repo.GetMember(ctx, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	resource, err := toResource(s.toUser(member, nil))
+	if err != nil {
+		return nil, err
+	}
+	if err := applyPatch(resource, patch.Operations); err != nil {
+		return nil, err
+	}
+	var user domain.User
+	if err := fromResource(resource, &user); err != nil {
+		return nil, err
+	}
+
+	if err := s.updateMember(ctx, tenantID, member, &user); err != nil {
+		return nil, err
+	}
+	return s.GetUser(ctx, tenantID, userID)
+}
+
+// DeleteUser removes a user from a tenant and its cohorts. The account, its enrollments and
+// progress are kept, so that the user can be provisioned again.
+func (s *SCIMService) DeleteUser(ctx context.Context, tenantID, userID string) error {
+	return s.repo.DeleteMember(ctx, tenantID, userID)
+}
+
+// updateMember applies the changes of a user resource to a member
+func (s *SCIMService) updateMember(ctx context.Context, tenantID string, member *domain.Member, user *domain.User) error {
+	email, err := s.userEmail(user)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(email, member.Email) {
+		// The email is the login of the user, so SCIM only changes the email of the accounts it
+		// created; the email of accounts that existed before they were provisioned is their
+		// owner's. It is also shared by every tenant of the user, so it can't change once the
+		// user belongs to others.
+		if !member.CreatedBySCIM {
+			return fmt.Errorf("%w: userName of a user that was not created through SCIM", ports.ErrMutability)
+		}
+		count, err := s.repo.CountUserMemberships(ctx, member.UserID)
+		if err != nil {
+			return err
+		}
+		if count > 1 {
+			return fmt.Errorf("%w: userName of a user that belongs to other tenants", ports.ErrMutability)
+		}
+		if err := s.changeEmail(ctx, member.UserID, email); err != nil {
+			return err
+		}
+	}
+
+	if fullName := memberFullName(user, s.toUser(member, nil)); fullName != "" && fullName != member.FullName {
+		if _, err := s.userService.UpdateUser(ctx, member.UserID, &userdomain.UpdateUserDTO{FullName: &fullName}); err != nil {
+			return fmt.Errorf("failed to update user name: %w", err)
+		}
+	}
+
+	var externalID *string
+	if user.ExternalID != "" {
+		externalID = &user.ExternalID
+	}
+	if !equalStrings(externalID, member.ExternalID) {
+		if err := s.repo.SetMemberExternalID(ctx, tenantID, member.UserID, externalID); err != nil {
+			return err
+		}
+	}
+
+	if user.Active == nil || bool(*user.Active) == member.IsActive() {
+		return nil
+	}
+	if !*user.Active {
+		return s.tenantRepo.UpdateMembershipStatus(ctx, member.MembershipID, "inactive")
+	}
+
+	if err := s.quota.CheckLearnerQuota(ctx, tenantID, member.UserID); err != nil {
+		return err
+	}
+	if err := s.tenantRepo.UpdateMembershipStatus(ctx, member.MembershipID, "active"); err != nil {
+		return err
+	}
+
+	// Users enrolled while they were inactive missed the courses of their cohorts
+	cohorts, err := s.repo.ListCohorts(ctx, tenantID)
+	if err != nil {
+		log.Printf("⚠️  [SCIMService] Failed to list cohorts of tenant %s: %v", tenantID, err)
+		return nil
+	}
+	for _, cohort := range cohorts {
+		if cohort.HasMember(member.UserID) {
+			s.enrollInCourses(ctx, tenantID, []string{member.UserID}, cohort.CourseIDs)
+		}
+	}
+	return nil
+}
+
+// changeEmail changes the login email of a user. The sessions of the user are revoked first, and
+// the new address must be verified before the user can sign in again.
+func (s *SCIMService) changeEmail(ctx context.Context, userID, email string) error {
+	if err := s.accounts.RevokeAllSessions(ctx, userID); err != nil {
+		return err
+	}
+	if err := s.repo.UpdateUserEmail(ctx, userID, email); err != nil {
+		return err
+	}
+
+	// The address is changed and unverified either way; the user can ask for the email again
+	if err := s.accounts.ResendVerification(ctx, &authdomain.ResendVerificationDTO{Email: email}); err != nil {
+		log.Printf("⚠️  [SCIMService] Failed to send the verification email of user %s: %v", userID, err)
+	}
+	return nil
+}
+
+// userEmail returns the email of a user resource, which is its userName
+func (s *SCIMService) userEmail(user *domain.User) (string, error) {
+	email := strings.TrimSpace(user.UserName)
+	if err := s.validator.Var(email, "required,email"); err != nil {
+		return "", fmt.Errorf("%w: userName must be an email address", ports.ErrInvalidValue)
+	}
+	return email, nil
+}
+
+// toUser builds the user resource of a member. Cohorts are listed as the groups of the user.
+func (s *SCIMService) toUser(member *domain.Member, cohorts []*domain.Cohort) *domain.User {
+	givenName, familyName, _ := strings.Cut(member.FullName, " ")
+	active := domain.Boolean(member.IsActive())
+	created, lastModified := member.CreatedAt, member.UpdatedAt
+
+	user := &domain.User{
+		Schemas:  []string{domain.SchemaUser},
+		ID:       member.UserID,
+		UserName: member.Email,
+		Name: &domain.Name{
+			Formatted:  member.FullName,
+			GivenName:  givenName,
+			FamilyName: strings.TrimSpace(familyName),
+		},
+		DisplayName: member.FullName,
+		Emails:      []domain.MultiValue{{Value: member.Email, Type: "work", Primary: true}},
+		Active:      &active,
+		Meta: &domain.Meta{
+			ResourceType: domain.ResourceTypeUser,
+			Created:      &created,
+			LastModified: &lastModified,
+			Location:     s.location(domain.ResourceTypeUser, member.UserID),
+		},
+	}
+	if member.ExternalID != nil {
+		user.ExternalID = *member.ExternalID
+	}
+
+	for _, cohort := range cohorts {
+		if cohort.HasMember(member.UserID) {
+			user.Groups = append(user.Groups, domain.MultiValue{
+				Value:   cohort.ID,
+				Display: cohort.DisplayName,
+				Type:    "direct",
+				Ref:     s.location(domain.ResourceTypeGroup, cohort.ID),
+			})
+		}
+	}
+
+	return user
+}
+
+// memberFullName returns the full name a user resource asks for. The name is read from
+// name.givenName and name.familyName, name.formatted and displayName, in that order; the first
+// one that differs from the current resource wins, so that changing any of them takes effect.
+func memberFullName(user, current *domain.User) string {
+	names := userNames(user)
+	if current != nil {
+		currentNames := userNames(current)
+		for i, name := range names {
+			if name != "" && name != currentNames[i] {
+				return name
+			}
+		}
+	}
+	for _, name := range names {
+		if name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// userNames returns the names of a user resource, in order of precedence
+func userNames(user *domain.User) [3]string {
+	var names [3]string
+	if user.Name != nil {
+		names[0] = strings.TrimSpace(strings.TrimSpace(user.Name.GivenName) + " " + strings.TrimSpace(user.Name.FamilyName))
+		names[1] = strings.TrimSpace(user.Name.Formatted)
+	}
+	names[2] = strings.TrimSpace(user.DisplayName)
+	return names
+}
+
+// ============================================================
+// Groups
+// ============================================================
+
+// ListGroups lists the cohorts of a tenant that match a filter
+func (s *SCIMService) ListGroups(ctx context.Context, tenantID string, query *domain.ListQuery) (*domain.ListResponse, error) {
+	filter, err := parseFilter(query.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	cohorts, err := s.repo.ListCohorts(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	members, err := s.membersByID(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := []map[string]interface{}{}
+	for _, cohort := range cohorts {
+		resource, err := toResource(s.toGroup(cohort, members))
+		if err != nil {
+			return nil, err
+		}
+		if filter == nil || filter.matches(resource, "") {
+			resources = append(resources, resource)
+		}
+	}
+
+	return listResponse(resources, query), nil
+}
+
+// GetGroup retrieves a cohort of a tenant
+func (s *SCIMService) GetGroup(ctx context.Context, tenantID, groupID string) (*domain.Group, error) {
+	cohort, err := s.repo.GetCohort(ctx, tenantID, groupID)
+	if err != nil {
+		return nil, err
+	}
+	members, err := s.membersByID(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return s.toGroup(cohort, members), nil
+}
+
+// CreateGroup creates a cohort and enrolls its active members in its courses
+func (s *SCIMService) CreateGroup(ctx context.Context, tenantID string, group *domain.Group) (*domain.Group, error) {
+	members, err := s.membersByID(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	cohort := &domain.Cohort{
+		ID:        uuid.New().String(),
+		TenantID:  tenantID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.applyGroup(ctx, cohort, group, members); err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateCohort(ctx, cohort); err != nil {
+		return nil, err
+	}
+
+	s.enrollInCourses(ctx, tenantID, activeMembers(cohort.MemberIDs, members), cohort.CourseIDs)
+
+	return s.toGroup(cohort, members), nil
+}
+
+// ReplaceGroup updates a cohort from the whole resource. The courses are kept when the resource
+// leaves out the cohort extension.
+func (s *SCIMService) ReplaceGroup(ctx context.Context, tenantID, groupID string, group *domain.Group) (*domain.Group, error) {
+	cohort, err := s.repo.GetCohort(ctx, tenantID, groupID)
+	if err != nil {
+		return nil, err
+	}
+	members, err := s.membersByID(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return s.updateCohort(ctx, cohort, group, members)
+}
+
+// PatchGroup applies the operations of a PATCH request to a cohort
+func (s *SCIMService) PatchGroup(ctx context.Context, tenantID, groupID string, patch *domain.PatchRequest) (*domain.Group, error) {
+	cohort, err := s.repo.GetCohort(ctx, tenantID, groupID)
+	if err != nil {
+		return nil, err
+	}
+	members, err := s.membersByID(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	resource, err := toResource(s.toGroup(cohort, members))
+	if err != nil {
+		return nil, err
+	}
+	if err := applyPatch(resource, patch.Operations); err != nil {
+		return nil, err
+	}
+	var group domain.Group
+	if err := fromResource(resource, &group); err != nil {
+		return nil, err
+	}
+
+	return s.updateCohort(ctx, cohort, &group, members)
+}
+
+// DeleteGroup deletes a cohort. Its members keep their enrollments.
+func (s *SCIMService) DeleteGroup(ctx context.Context, tenantID, groupID string) error {
+	return s.repo.DeleteCohort(ctx, tenantID, groupID)
+}
+
+// updateCohort saves the changes of a group resource to a cohort. Active members that joined
+// are enrolled in the courses of the cohort, and the active members in the courses it gained;
+// members that left keep their enrollments.
+func (s *SCIMService) updateCohort(ctx context.Context, cohort *domain.Cohort, group *domain.Group, members map[string]*domain.Member) (*domain.Group, error) {
+	previous := *cohort
+	updated := *cohort
+	if err := s.applyGroup(ctx, &updated, group, members); err != nil {
+		return nil, err
+	}
+	updated.UpdatedAt = time.Now()
+	if err := s.repo.UpdateCohort(ctx, &updated); err != nil {
+		return nil, err
+	}
+
+	var joined []string
+	for _, userID := range updated.MemberIDs {
+		if !previous.HasMember(userID) {
+			joined = append(joined, userID)
+		}
+	}
+	var newCourses []string
+	for _, courseID := range updated.CourseIDs {
+		if !containsString(previous.CourseIDs, courseID) {
+			newCourses = append(newCourses, courseID)
+		}
+	}
+
+	s.enrollInCourses(ctx, updated.TenantID, activeMembers(joined, members), updated.CourseIDs)
+	var stayed []string
+	for _, userID := range updated.MemberIDs {
+		if previous.HasMember(userID) {
+			stayed = append(stayed, userID)
+		}
+	}
+	s.enrollInCourses(ctx, updated.TenantID, activeMembers(stayed, members), newCourses)
+
+	return s.toGroup(&updated, members), nil
+}
+
+// applyGroup sets the attributes of a group resource on a cohort, checking that its members
+// belong to the tenant and its courses exist
+func (s *SCIMService) applyGroup(ctx context.Context, cohort *domain.Cohort, group *domain.Group, members map[string]*domain.Member) error {
+	displayName := strings.TrimSpace(group.DisplayName)
+	if displayName == "" {
+		return fmt.Errorf("%w: displayName is required", ports.ErrInvalidValue)
+	}
+	cohort.DisplayName = displayName
+
+	cohort.ExternalID = nil
+	if group.ExternalID != "" {
+		externalID := group.ExternalID
+		cohort.ExternalID = &externalID
+	}
+
+	memberIDs := []string{}
+	for _, member := range group.Members {
+		if _, ok := members[member.Value]; !ok {
+			return fmt.Errorf("%w: %q is not a user of the tenant", ports.ErrInvalidValue, member.Value)
+		}
+		if !containsString(memberIDs, member.Value) {
+			memberIDs = append(memberIDs, member.Value)
+		}
+	}
+	cohort.MemberIDs = memberIDs
+
+	if group.Cohort == nil {
+		return nil
+	}
+	courseIDs := []string{}
+	for _, courseID := range group.Cohort.CourseIDs {
+		if containsString(courseIDs, courseID) {
+			continue
+		}
+		if err := s.validator.Var(courseID, "uuid"); err != nil {
+			return fmt.Errorf("%w: %q is not a course ID", ports.ErrInvalidValue, courseID)
+		}
+		exists, err := s.enroller.CourseExists(ctx, cohort.TenantID, courseID)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("%w: course %s not found", ports.ErrInvalidValue, courseID)
+		}
+		courseIDs = append(courseIDs, courseID)
+	}
+	cohort.CourseIDs = courseIDs
+	return nil
+}
+
+// enrollInCourses enrolls users in courses. Failures are logged rather than returned: the change
+// that triggered the enrollments is already saved, and an admin can enroll the users by hand.
+func (s *SCIMService) enrollInCourses(ctx context.Context, tenantID string, userIDs, courseIDs []string) {
+	for _, userID := range userIDs {
+		for _, courseID := range courseIDs {
+			if _, err := s.enroller.EnrollMember(ctx, tenantID, userID, courseID); err != nil {
+				log.Printf("⚠️  [SCIMService] Failed to enroll user %s in course %s of tenant %s: %v", userID, courseID, tenantID, err)
+			}
+		}
+	}
+}
+
+// membersByID reads the members of a tenant indexed by user ID
+func (s *SCIMService) membersByID(ctx context.Context, tenantID string) (map[string]*domain.Member, error) {
+	members, err := s.repo.ListMembers(ctx, tenantID, domain.MemberLookup{})
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]*domain.Member, len(members))
+	for _, member := range members {
+		byID[member.UserID] = member
+	}
+	return byID, nil
+}
+
+// toGroup builds the group resource of a cohort
+func (s *SCIMService) toGroup(cohort *domain.Cohort, members map[string]*domain.Member) *domain.Group {
+	created, lastModified := cohort.CreatedAt, cohort.UpdatedAt
+
+	group := &domain.Group{
+		Schemas:     []string{domain.SchemaGroup, domain.SchemaCohort},
+		ID:          cohort.ID,
+		DisplayName: cohort.DisplayName,
+		Members:     []domain.MultiValue{},
+		Cohort:      &domain.CohortExtension{CourseIDs: append([]string{}, cohort.CourseIDs...)},
+		Meta: &domain.Meta{
+			ResourceType: domain.ResourceTypeGroup,
+			Created:      &created,
+			LastModified: &lastModified,
+			Location:     s.location(domain.ResourceTypeGroup, cohort.ID),
+		},
+	}
+	if cohort.ExternalID != nil {
+		group.ExternalID = *cohort.ExternalID
+	}
+
+	for _, userID := range cohort.MemberIDs {
+		value := domain.MultiValue{
+			Value: userID,
+			Type:  domain.ResourceTypeUser,
+			Ref:   s.location(domain.ResourceTypeUser, userID),
+		}
+		if member, ok := members[userID]; ok {
+			value.Display = member.FullName
+		}
+		group.Members = append(group.Members, value)
+	}
+
+	return group
+}
+
+// activeMembers returns the users that are active members of the tenant
+func activeMembers(userIDs []string, members map[string]*domain.Member) []string {
+	var active []string
+	for _, userID := range userIDs {
+		if member, ok := members[userID]; ok && member.IsActive() {
+			active = append(active, userID)
+		}
+	}
+	return active
+}
+
+// ============================================================
+// Helpers
+// ============================================================
+
+// location returns the URL of a resource
+func (s *SCIMService) location(resourceType, id string) string {
+	return s.baseURL + "/scim/v2/" + resourceType + "s/" + id
+}
+
+// listResponse returns a page of the resources that matched a list request, reduced to the
+// requested attributes. startIndex is 1-based; a count of 0 only reports the total.
+func listResponse(resources []map[string]interface{}, query *domain.ListQuery) *domain.ListResponse {
+	startIndex := query.StartIndex
+	if startIndex < 1 {
+		startIndex = 1
+	}
+	count := defaultListCount
+	if query.Count != nil {
+		count = *query.Count
+	}
+	if count < 0 {
+		count = 0
+	}
+	if count > maxListCount {
+		count = maxListCount
+	}
+
+	page := []map[string]interface{}{}
+	for i := startIndex - 1; i < len(resources) && len(page) < count; i++ {
+		page = append(page, projectAttributes(resources[i], query.Attributes, query.ExcludedAttributes))
+	}
+
+	return &domain.ListResponse{
+		Schemas:      []string{domain.SchemaListResponse},
+		TotalResults: len(resources),
+		StartIndex:   startIndex,
+		ItemsPerPage: len(page),
+		Resources:    page,
+	}
+}
+
+// projectAttributes reduces a resource to the requested attributes, or removes the excluded
+// ones. The id and schemas are always returned.
+func projectAttributes(resource map[string]interface{}, attributes, excluded []string) map[string]interface{} {
+	if len(attributes) > 0 {
+		projected := map[string]interface{}{}
+		for _, name := range []string{"schemas", "id"} {
+			if value, ok := resource[name]; ok {
+				projected[name] = value
+			}
+		}
+		for _, attribute := range attributes {
+			copyAttribute(projected, resource, attribute)
+		}
+		return projected
+	}
+
+	for _, attribute := range excluded {
+		removeAttribute(resource, attribute)
+	}
+	return resource
+}
+
+// copyAttribute copies an attribute, or a sub-attribute, of a resource to its projection
+func copyAttribute(projected, resource map[string]interface{}, attribute string) {
+	// A whole extension is named by its schema
+	if key, ok := lookupKey(resource, attribute); ok {
+		projected[key] = resource[key]
+		return
+	}
+
+	path, err := parseAttrPath(attribute)
+	if err != nil {
+		return
+	}
+	source := path.container(resource)
+	target := projected
+	if !sameMap(source, resource) {
+		extensionKey, _ := lookupKey(resource, path.schema)
+		extension, ok := projected[extensionKey].(map[string]interface{})
+		if !ok {
+			extension = map[string]interface{}{}
+			projected[extensionKey] = extension
+		}
+		target = extension
+	}
+
+	key, ok := lookupKey(source, path.name)
+	if !ok {
+		return
+	}
+	if path.sub == "" {
+		target[key] = source[key]
+		return
+	}
+
+	subOf := func(value interface{}) map[string]interface{} {
+		object, _ := value.(map[string]interface{})
+		reduced := map[string]interface{}{}
+		if subKey, ok := lookupKey(object, path.sub); ok {
+			reduced[subKey] = object[subKey]
+		}
+		return reduced
+	}
+	switch value := source[key].(type) {
+	case map[string]interface{}:
+		reduced, _ := target[key].(map[string]interface{})
+		if reduced == nil {
+			reduced = map[string]interface{}{}
+		}
+		mergeObject(reduced, subOf(value))
+		target[key] = reduced
+	case []interface{}:
+		reduced := make([]interface{}, 0, len(value))
+		for _, item := range value {
+			reduced = append(reduced, subOf(item))
+		}
+		target[key] = reduced
+	}
+}
+
+// removeAttribute removes an attribute, or a sub-attribute, from a resource
+func removeAttribute(resource map[string]interface{}, attribute string) {
+	if key, ok := lookupKey(resource, attribute); ok {
+		if !strings.EqualFold(key, "id") && !strings.EqualFold(key, "schemas") {
+			delete(resource, key)
+		}
+		return
+	}
+
+	path, err := parseAttrPath(attribute)
+	if err != nil {
+		return
+	}
+	container := path.container(resource)
+	key, ok := lookupKey(container, path.name)
+	if !ok {
+		return
+	}
+	if path.sub == "" {
+		if !sameMap(container, resource) || (!strings.EqualFold(key, "id") && !strings.EqualFold(key, "schemas")) {
+			delete(container, key)
+		}
+		return
+	}
+
+	switch value := container[key].(type) {
+	case map[string]interface{}:
+		setSubAttribute(value, "remove", path.sub, nil)
+	case []interface{}:
+		for _, item := range value {
+			if object, ok := item.(map[string]interface{}); ok {
+				setSubAttribute(object, "remove", path.sub, nil)
+			}
+		}
+	}
+}
+
+// toResource returns the JSON representation of a resource
+func toResource(v interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode resource: %w", err)
+	}
+	var resource map[string]interface{}
+	if err := json.Unmarshal(data, &resource); err != nil {
+		return nil, fmt.Errorf("failed to encode resource: %w", err)
+	}
+	return resource, nil
+}
+
+// fromResource decodes the JSON representation of a resource changed by a PATCH request
+func fromResource(resource map[string]interface{}, v interface{}) error {
+	data, err := json.Marshal(resource)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ports.ErrInvalidValue, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("%w: %v", ports.ErrInvalidValue, err)
+	}
+	return nil
+}
+
+// generatePassword generates the password of users provisioned without one. It satisfies the
+// password policies and is never shown; users sign in with SSO or reset their password.
+func generatePassword() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b) + "Aa1!", nil
+}
+
+// containsString checks if a list holds a string
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// equalStrings checks if two optional strings are equal
+func equalStrings(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
@@ -0,0 +1,304 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	authdomain "github.com/DanielIturra1610/stegmaier-landing/internal/core/auth/domain"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/scim/domain"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/scim/ports"
+	tenantdomain "github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/domain"
+	tenantports "github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/ports"
+	userdomain "github.com/DanielIturra1610/stegmaier-landing/internal/core/user/domain"
+	userports "github.com/DanielIturra1610/stegmaier-landing/internal/core/user/ports"
+)
+
+func TestMemberFullName(t *testing.T) {
+	current := &domain.User{
+		Name:        &domain.Name{GivenName: "Ana", FamilyName: "Rojas", Formatted: "Ana Rojas"},
+		DisplayName: "Ana Rojas",
+	}
+
+	tests := []struct {
+		name     string
+		user     *domain.User
+		current  *domain.User
+		expected string
+	}{
+		{"Given and family names", &domain.User{Name: &domain.Name{GivenName: "Ana", FamilyName: "Rojas"}, DisplayName: "A. Rojas"}, nil, "Ana Rojas"},
+		{"Formatted name", &domain.User{Name: &domain.Name{Formatted: "Ana Rojas Vega"}, DisplayName: "A. Rojas"}, nil, "Ana Rojas Vega"},
+		{"Display name", &domain.User{DisplayName: "A. Rojas"}, nil, "A. Rojas"},
+		{"No name", &domain.User{}, nil, ""},
+		{"Changed given name wins", &domain.User{Name: &domain.Name{GivenName: "Ana María", FamilyName: "Rojas", Formatted: "Ana Rojas"}, DisplayName: "Ana Rojas"}, current, "Ana María Rojas"},
+		{"Changed display name wins", &domain.User{Name: &domain.Name{GivenName: "Ana", FamilyName: "Rojas", Formatted: "Ana Rojas"}, DisplayName: "Ana R."}, current, "Ana R."},
+		{"Unchanged name", current, current, "Ana Rojas"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := memberFullName(tt.user, tt.current); result != tt.expected {
+				t.Errorf("memberFullName = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestListResponse(t *testing.T) {
+	resources := func() []map[string]interface{} {
+		var resources []map[string]interface{}
+		for _, id := range []string{"u1", "u2", "u3"} {
+			resources = append(resources, map[string]interface{}{
+				"schemas":  []interface{}{domain.SchemaUser},
+				"id":       id,
+				"userName": id + "@example.com",
+				"name":     map[string]interface{}{"givenName": "Ana", "familyName": "Rojas"},
+				"emails":   []interface{}{map[string]interface{}{"value": id + "@example.com", "type": "work"}},
+			})
+		}
+		return resources
+	}
+	count := func(n int) *int { return &n }
+
+	t.Run("Pagination", func(t *testing.T) {
+		response := listResponse(resources(), &domain.ListQuery{StartIndex: 2, Count: count(1)})
+		if response.TotalResults != 3 || response.StartIndex != 2 || response.ItemsPerPage != 1 || response.Resources[0]["id"] != "u2" {
+			t.Errorf("Unexpected page: %+v", response)
+		}
+	})
+
+	t.Run("Count of zero only reports the total", func(t *testing.T) {
+		response := listResponse(resources(), &domain.ListQuery{Count: count(0)})
+		if response.TotalResults != 3 || response.StartIndex != 1 || len(response.Resources) != 0 {
+			t.Errorf("Unexpected page: %+v", response)
+		}
+	})
+
+	t.Run("Start index past the end", func(t *testing.T) {
+		response := listResponse(resources(), &domain.ListQuery{StartIndex: 10})
+		if response.TotalResults != 3 || len(response.Resources) != 0 {
+			t.Errorf("Unexpected page: %+v", response)
+		}
+	})
+
+	t.Run("Requested attributes", func(t *testing.T) {
+		response := listResponse(resources(), &domain.ListQuery{Attributes: []string{"userName", "name.familyName", "emails.value"}})
+		expected := map[string]interface{}{
+			"schemas":  []interface{}{domain.SchemaUser},
+			"id":       "u1",
+			"userName": "u1@example.com",
+			"name":     map[string]interface{}{"familyName": "Rojas"},
+			"emails":   []interface{}{map[string]interface{}{"value": "u1@example.com"}},
+		}
+		if !reflect.DeepEqual(response.Resources[0], expected) {
+			t.Errorf("Resource = %#v, want %#v", response.Resources[0], expected)
+		}
+	})
+
+	t.Run("Excluded attributes", func(t *testing.T) {
+		response := listResponse(resources(), &domain.ListQuery{ExcludedAttributes: []string{"emails", "name.givenName", "id"}})
+		expected := map[string]interface{}{
+			"schemas":  []interface{}{domain.SchemaUser},
+			"id":       "u1",
+			"userName": "u1@example.com",
+			"name":     map[string]interface{}{"familyName": "Rojas"},
+		}
+		if !reflect.DeepEqual(response.Resources[0], expected) {
+			t.Errorf("Resource = %#v, want %#v", response.Resources[0], expected)
+		}
+	})
+}
+
+const scimTenantID = "6f1c2a54-4b1e-4d8e-9a57-3c1d2b7e9f10"
+
+// scimRepository keeps the provisioned members in memory; the other methods are not used.
+// unmanaged holds the users SCIM must not provision.
+type scimRepository struct {
+	ports.SCIMRepository
+	members   map[string]*domain.Member
+	unmanaged map[string]bool
+	calls     *[]string
+}
+
+func (r *scimRepository) ListMembers(ctx context.Context, tenantID string, lookup domain.MemberLookup) ([]*domain.Member, error) {
+	members := []*domain.Member{}
+	for _, member := range r.members {
+		if lookup.Email == "" || member.Email == lookup.Email {
+			members = append(members, member)
+		}
+	}
+	return members, nil
+}
+
+func (r *scimRepository) GetMember(ctx context.Context, tenantID, userID string) (*domain.Member, error) {
+	member, ok := r.members[userID]
+	if !ok {
+		return nil, ports.ErrResourceNotFound
+	}
+	copied := *member
+	return &copied, nil
+}
+
+func (r *scimRepository) IsUnmanagedUser(ctx context.Context, tenantID, userID string) (bool, error) {
+	return r.unmanaged[userID], nil
+}
+
+func (r *scimRepository) MarkMemberCreatedBySCIM(ctx context.Context, tenantID, userID string) error {
+	r.members[userID].CreatedBySCIM = true
+	return nil
+}
+
+func (r *scimRepository) CountUserMemberships(ctx context.Context, userID string) (int, error) {
+	return 1, nil
+}
+
+func (r *scimRepository) UpdateUserEmail(ctx context.Context, userID, email string) error {
+	*r.calls = append(*r.calls, "update email "+email)
+	r.members[userID].Email = email
+	return nil
+}
+
+func (r *scimRepository) ListCohorts(ctx context.Context, tenantID string) ([]*domain.Cohort, error) {
+	return nil, nil
+}
+
+// scimTenantRepository resolves user IDs by email and adds the memberships to a scimRepository
+type scimTenantRepository struct {
+	tenantports.TenantRepository
+	users map[string]string
+	repo  *scimRepository
+}
+
+func (r *scimTenantRepository) GetUserIDByEmail(ctx context.Context, email string) (string, error) {
+	return r.users[email], nil
+}
+
+func (r *scimTenantRepository) CreateMembership(ctx context.Context, membership *tenantdomain.TenantMembership) error {
+	for email, userID := range r.users {
+		if userID == membership.UserID {
+			r.repo.members[userID] = &domain.Member{
+				UserID: userID, MembershipID: "membership-" + userID, Email: email,
+				Role: membership.Role, Status: membership.Status,
+			}
+		}
+	}
+	return nil
+}
+
+// scimUserService creates users in a scimTenantRepository
+type scimUserService struct {
+	userports.UserManagementService
+	tenantRepo *scimTenantRepository
+}
+
+func (s *scimUserService) CreateUser(ctx context.Context, dto *userdomain.CreateUserDTO) (*authdomain.User, error) {
+	userID := "user-" + dto.Email
+	s.tenantRepo.users[dto.Email] = userID
+	return &authdomain.User{ID: userID, Email: dto.Email}, nil
+}
+
+// scimQuota never limits the learners of the tenant
+type scimQuota struct {
+	tenantports.QuotaService
+}
+
+func (q scimQuota) CheckLearnerQuota(ctx context.Context, tenantID, userID string) error {
+	return nil
+}
+
+// scimAccounts records the sessions revoked and the verification emails sent
+type scimAccounts struct {
+	calls *[]string
+}
+
+func (a *scimAccounts) RevokeAllSessions(ctx context.Context, userID string) error {
+	*a.calls = append(*a.calls, "revoke sessions "+userID)
+	return nil
+}
+
+func (a *scimAccounts) ResendVerification(ctx context.Context, dto *authdomain.ResendVerificationDTO) error {
+	*a.calls = append(*a.calls, "verify "+dto.Email)
+	return nil
+}
+
+func newTestSCIMService(members ...*domain.Member) (*SCIMService, *scimRepository, *scimTenantRepository, *[]string) {
+	calls := &[]string{}
+	repo := &scimRepository{members: make(map[string]*domain.Member), unmanaged: make(map[string]bool), calls: calls}
+	for _, member := range members {
+		repo.members[member.UserID] = member
+	}
+	tenantRepo := &scimTenantRepository{users: make(map[string]string), repo: repo}
+	users := &scimUserService{tenantRepo: tenantRepo}
+	service := NewSCIMService(repo, tenantRepo, users, &scimAccounts{calls: calls}, nil, scimQuota{}, "https://api.example.com").(*SCIMService)
+	return service, repo, tenantRepo, calls
+}
+
+func TestPatchUserNameRevokesSessionsAndVerifiesEmail(t *testing.T) {
+	now := time.Now()
+	service, _, _, calls := newTestSCIMService(&domain.Member{
+		UserID: "user-1", MembershipID: "membership-1", Email: "ana@example.com", FullName: "Ana Rojas",
+		CreatedBySCIM: true, Role: domain.ProvisionedRole, Status: "active", CreatedAt: now, UpdatedAt: now,
+	})
+
+	user, err := service.PatchUser(context.Background(), scimTenantID, "user-1", &domain.PatchRequest{
+		Operations: []domain.PatchOperation{{Op: "replace", Path: "userName", Value: "ana.rojas@example.com"}},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if user.UserName != "ana.rojas@example.com" {
+		t.Errorf("Expected the new userName, got %s", user.UserName)
+	}
+
+	expected := []string{"revoke sessions user-1", "update email ana.rojas@example.com", "verify ana.rojas@example.com"}
+	if !reflect.DeepEqual(*calls, expected) {
+		t.Errorf("Expected %v, got %v", expected, *calls)
+	}
+}
+
+func TestCreateUserRefusesUnmanagedUsers(t *testing.T) {
+	service, repo, tenantRepo, _ := newTestSCIMService()
+	tenantRepo.users["admin@example.com"] = "admin-1"
+	repo.unmanaged["admin-1"] = true
+
+	_, err := service.CreateUser(context.Background(), scimTenantID, &domain.User{UserName: "admin@example.com"})
+	if !errors.Is(err, ports.ErrUniqueness) {
+		t.Errorf("Expected ErrUniqueness, got %v", err)
+	}
+}
+
+func TestPatchUserNameOfLinkedAccount(t *testing.T) {
+	ctx := context.Background()
+	service, repo, tenantRepo, calls := newTestSCIMService()
+	renameUserName := &domain.PatchRequest{
+		Operations: []domain.PatchOperation{{Op: "replace", Path: "userName", Value: "attacker@example.com"}},
+	}
+
+	// A self-registered account without memberships is linked to the tenant
+	tenantRepo.users["ana@example.com"] = "user-1"
+	if _, err := service.CreateUser(ctx, scimTenantID, &domain.User{UserName: "ana@example.com"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if repo.members["user-1"].CreatedBySCIM {
+		t.Fatalf("Expected the linked account not to be marked as created by SCIM")
+	}
+
+	_, err := service.PatchUser(ctx, scimTenantID, "user-1", renameUserName)
+	if !errors.Is(err, ports.ErrMutability) {
+		t.Errorf("Expected ErrMutability, got %v", err)
+	}
+	if len(*calls) != 0 || repo.members["user-1"].Email != "ana@example.com" {
+		t.Errorf("Expected the email and sessions of the account to be left as they are, got %v", *calls)
+	}
+
+	// Accounts created through SCIM can be renamed
+	created, err := service.CreateUser(ctx, scimTenantID, &domain.User{UserName: "luis@example.com"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := service.PatchUser(ctx, scimTenantID, created.ID, renameUserName); err != nil {
+		t.Errorf("Expected the email of an account created through SCIM to change, got %v", err)
+	}
+}
@@ -0,0 +1,471 @@
+package adapters
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/ports"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/shared/database"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// migrationsTable is the table where golang-migrate records the schema version; archives
+// carry the version in their header instead
+const migrationsTable = "schema_migrations"
+
+// PostgresTenantArchiveRepository implements the TenantArchiveRepository interface
+type PostgresTenantArchiveRepository struct {
+	manager *database.Manager
+}
+
+// NewPostgresTenantArchiveRepository creates a new PostgreSQL tenant archive repository
+func NewPostgresTenantArchiveRepository(manager *database.Manager) *PostgresTenantArchiveRepository {
+	return &PostgresTenantArchiveRepository{
+		manager: manager,
+	}
+}
+
+// OpenTenantData connects to the database of a tenant, whatever the status of the tenant
+func (r *PostgresTenantArchiveRepository) OpenTenantData(ctx context.Context, databaseName string) (ports.TenantData, error) {
+	db, err := r.manager.OpenTenantDatabase(databaseName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &postgresTenantData{db: db}, nil
+}
+
+// postgresTenantData implements the TenantData interface
+type postgresTenantData struct {
+	db *sqlx.DB
+}
+
+// SchemaVersion returns the migration version of the tenant database and whether it is dirty
+func (d *postgresTenantData) SchemaVersion(ctx context.Context) (uint, bool, error) {
+	var version uint
+	var dirty bool
+	err := d.db.QueryRowContext(ctx, "SELECT version, dirty FROM "+migrationsTable+" LIMIT 1").Scan(&version, &dirty)
+	if err == sql.ErrNoRows {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to get schema version: %w", err)
+	}
+
+	return version, dirty, nil
+}
+
+// ListTables returns the tables of the tenant schema, referenced tables before the tables referencing them
+func (d *postgresTenantData) ListTables(ctx context.Context) ([]string, error) {
+	var tables []string
+	err := d.db.SelectContext(ctx, &tables, `
+		SELECT table_name
+		FROM information_schema.tables
+		WHERE table_schema = 'public' AND table_type = 'BASE TABLE' AND table_name <> $1
+	`, migrationsTable)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+
+	foreignKeys, err := listForeignKeys(ctx, d.db)
+	if err != nil {
+		return nil, err
+	}
+
+	references := make(map[string][]string)
+	for table, keys := range foreignKeys {
+		for _, key := range keys {
+			references[table] = append(references[table], key.referenced)
+		}
+	}
+
+	return sortTablesByReferences(tables, references), nil
+}
+
+// foreignKey is a foreign key of a tenant table
+type foreignKey struct {
+	referenced string
+	columns    []string
+	// Whether every column of the key accepts NULL
+	nullable bool
+}
+
+// listForeignKeys returns the foreign keys of the tenant schema by table
+func listForeignKeys(ctx context.Context, q sqlx.QueryerContext) (map[string][]foreignKey, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT child.relname, parent.relname, array_agg(a.attname::text ORDER BY k.n), bool_and(NOT a.attnotnull)
+		FROM pg_constraint c
+		INNER JOIN pg_class child ON child.oid = c.conrelid
+		INNER JOIN pg_class parent ON parent.oid = c.confrelid
+		INNER JOIN pg_namespace n ON n.oid = child.relnamespace
+		CROSS JOIN LATERAL unnest(c.conkey) WITH ORDINALITY AS k(attnum, n)
+		INNER JOIN pg_attribute a ON a.attrelid = c.conrelid AND a.attnum = k.attnum
+		WHERE c.contype = 'f' AND n.nspname = 'public'
+		GROUP BY c.oid, child.relname, parent.relname
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list foreign keys: %w", err)
+	}
+	defer rows.Close()
+
+	foreignKeys := make(map[string][]foreignKey)
+	for rows.Next() {
+		var table string
+		var key foreignKey
+		if err := rows.Scan(&table, &key.referenced, pq.Array(&key.columns), &key.nullable); err != nil {
+			return nil, fmt.Errorf("failed to scan foreign key: %w", err)
+		}
+		foreignKeys[table] = append(foreignKeys[table], key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating foreign keys: %w", err)
+	}
+
+	return foreignKeys, nil
+}
+
+// listPrimaryKeys returns the primary key columns of the tables of the tenant schema
+func listPrimaryKeys(ctx context.Context, q sqlx.QueryerContext) (map[string][]string, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT t.relname, array_agg(a.attname::text ORDER BY k.n)
+		FROM pg_constraint c
+		INNER JOIN pg_class t ON t.oid = c.conrelid
+		INNER JOIN pg_namespace n ON n.oid = t.relnamespace
+		CROSS JOIN LATERAL unnest(c.conkey) WITH ORDINALITY AS k(attnum, n)
+		INNER JOIN pg_attribute a ON a.attrelid = c.conrelid AND a.attnum = k.attnum
+		WHERE c.contype = 'p' AND n.nspname = 'public'
+		GROUP BY t.relname
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list primary keys: %w", err)
+	}
+	defer rows.Close()
+
+	primaryKeys := make(map[string][]string)
+	for rows.Next() {
+		var table string
+		var columns []string
+		if err := rows.Scan(&table, pq.Array(&columns)); err != nil {
+			return nil, fmt.Errorf("failed to scan primary key: %w", err)
+		}
+		primaryKeys[table] = columns
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating primary keys: %w", err)
+	}
+
+	return primaryKeys, nil
+}
+
+// sortTablesByReferences orders tables so that referenced tables come first. Tables are
+// otherwise sorted by name, and tables in reference cycles are appended by name; the import
+// sets their references to tables inserted later in a second pass.
+func sortTablesByReferences(tables []string, references map[string][]string) []string {
+	sorted := make([]string, 0, len(tables))
+	pending := append([]string(nil), tables...)
+	sort.Strings(pending)
+
+	placed := make(map[string]bool)
+	known := make(map[string]bool)
+	for _, table := range tables {
+		known[table] = true
+	}
+
+	for len(pending) > 0 {
+		remaining := pending[:0]
+		for _, table := range pending {
+			ready := true
+			for _, referenced := range references[table] {
+				// Self references and references outside the schema don't constrain the order
+				if referenced != table && known[referenced] && !placed[referenced] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				sorted = append(sorted, table)
+				placed[table] = true
+			} else {
+				remaining = append(remaining, table)
+			}
+		}
+
+		if len(remaining) == len(pending) {
+			// Reference cycle
+			sorted = append(sorted, remaining...)
+			break
+		}
+		pending = remaining
+	}
+
+	return sorted
+}
+
+// BeginExport starts the read-only transaction in which the rows of the tables are read. The
+// transaction is repeatable read, so that every table is exported from the same snapshot and
+// rows written during the export don't break references between tables.
+func (d *postgresTenantData) BeginExport(ctx context.Context) (ports.TenantDataExport, error) {
+	tx, err := d.db.BeginTxx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	return &postgresTenantDataExport{tx: tx}, nil
+}
+
+// postgresTenantDataExport implements the TenantDataExport interface
+type postgresTenantDataExport struct {
+	tx *sqlx.Tx
+}
+
+// ExportRows calls fn with each row of a table encoded as a JSON object. Rows are sorted by
+// creation when the table has a created_at column, so that rows referencing other rows of
+// the same table usually come after them.
+func (e *postgresTenantDataExport) ExportRows(ctx context.Context, table string, fn func(row json.RawMessage) error) error {
+	var hasCreatedAt bool
+	err := e.tx.GetContext(ctx, &hasCreatedAt, `
+		SELECT EXISTS (
+			SELECT 1 FROM information_schema.columns
+			WHERE table_schema = 'public' AND table_name = $1 AND column_name = 'created_at'
+		)
+	`, table)
+	if err != nil {
+		return fmt.Errorf("failed to get columns of %s: %w", table, err)
+	}
+
+	query := "SELECT row_to_json(t)::text FROM " + pq.QuoteIdentifier(table) + " t"
+	if hasCreatedAt {
+		query += " ORDER BY t.created_at"
+	}
+
+	rows, err := e.tx.QueryContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to export %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row []byte
+		if err := rows.Scan(&row); err != nil {
+			return fmt.Errorf("failed to scan row of %s: %w", table, err)
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// Close ends the export transaction; nothing was written in it
+func (e *postgresTenantDataExport) Close() error {
+	return e.tx.Rollback()
+}
+
+// BeginImport starts the transaction in which the rows of an archive are inserted
+func (d *postgresTenantData) BeginImport(ctx context.Context) (ports.TenantDataImport, error) {
+	foreignKeys, err := listForeignKeys(ctx, d.db)
+	if err != nil {
+		return nil, err
+	}
+	primaryKeys, err := listPrimaryKeys(ctx, d.db)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := d.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	return &postgresTenantDataImport{
+		tx:          tx,
+		foreignKeys: foreignKeys,
+		primaryKeys: primaryKeys,
+		imported:    make(map[string]bool),
+	}, nil
+}
+
+// Close closes the connection to the tenant database
+func (d *postgresTenantData) Close() error {
+	return d.db.Close()
+}
+
+// postgresTenantDataImport implements the TenantDataImport interface
+type postgresTenantDataImport struct {
+	tx          *sqlx.Tx
+	foreignKeys map[string][]foreignKey
+	primaryKeys map[string][]string
+	// Tables whose rows are all inserted, and the table being inserted
+	imported map[string]bool
+	current  string
+	// References set after all tables are inserted, in insertion order
+	patches []*referencePatch
+}
+
+// referencePatch holds the references of rows of a table to rows inserted later: the
+// primary key and the referencing columns of each row
+type referencePatch struct {
+	table   string
+	columns []string
+	rows    []json.RawMessage
+}
+
+// InsertRows inserts rows encoded as JSON objects in a table. Tables must be inserted one
+// after the other. Foreign keys are not deferrable, so references to tables not inserted
+// yet (reference cycles, and rows of the same table in later batches) are inserted as NULL
+// and set by Commit.
+func (i *postgresTenantDataImport) InsertRows(ctx context.Context, table string, rows []json.RawMessage) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	if table != i.current {
+		if i.current != "" {
+			i.imported[i.current] = true
+		}
+		i.current = table
+	}
+
+	if columns := i.pendingReferenceColumns(table); len(columns) > 0 {
+		primaryKey := i.primaryKeys[table]
+		if len(primaryKey) == 0 {
+			return fmt.Errorf("failed to import rows of %s: references to rows inserted later need a primary key", table)
+		}
+		deferred, patches, err := deferReferences(rows, columns, primaryKey)
+		if err != nil {
+			return fmt.Errorf("failed to import rows of %s: %w", table, err)
+		}
+		rows = deferred
+		if len(patches) > 0 {
+			i.patches = append(i.patches, &referencePatch{table: table, columns: columns, rows: patches})
+		}
+	}
+
+	identifier := pq.QuoteIdentifier(table)
+	query := "INSERT INTO " + identifier + " SELECT * FROM json_populate_recordset(NULL::" + identifier + ", $1::json)"
+
+	if _, err := i.tx.ExecContext(ctx, query, jsonArray(rows)); err != nil {
+		return fmt.Errorf("failed to import rows of %s: %w", table, err)
+	}
+
+	return nil
+}
+
+// pendingReferenceColumns returns the columns of the foreign keys of a table referencing
+// tables not completely inserted yet. Keys with NOT NULL columns are left to the database.
+func (i *postgresTenantDataImport) pendingReferenceColumns(table string) []string {
+	var columns []string
+	for _, key := range i.foreignKeys[table] {
+		if key.nullable && !i.imported[key.referenced] {
+			columns = append(columns, key.columns...)
+		}
+	}
+	return columns
+}
+
+// deferReferences sets the given columns of rows to NULL. It returns the rows to insert and,
+// for the rows with a reference, the primary key and the original value of the columns.
+func deferReferences(rows []json.RawMessage, columns, primaryKey []string) ([]json.RawMessage, []json.RawMessage, error) {
+	deferred := make([]json.RawMessage, len(rows))
+	var patches []json.RawMessage
+
+	for j, row := range rows {
+		var values map[string]json.RawMessage
+		if err := json.Unmarshal(row, &values); err != nil {
+			return nil, nil, fmt.Errorf("invalid row: %w", err)
+		}
+
+		patch := make(map[string]json.RawMessage, len(primaryKey)+len(columns))
+		for _, column := range columns {
+			if value, ok := values[column]; ok && string(value) != "null" {
+				patch[column] = value
+				values[column] = json.RawMessage("null")
+			}
+		}
+		if len(patch) == 0 {
+			deferred[j] = row
+			continue
+		}
+		for _, column := range primaryKey {
+			patch[column] = values[column]
+		}
+
+		var err error
+		if deferred[j], err = json.Marshal(values); err != nil {
+			return nil, nil, err
+		}
+		encoded, err := json.Marshal(patch)
+		if err != nil {
+			return nil, nil, err
+		}
+		patches = append(patches, encoded)
+	}
+
+	return deferred, patches, nil
+}
+
+// jsonArray joins rows encoded as JSON into a JSON array
+func jsonArray(rows []json.RawMessage) string {
+	var array bytes.Buffer
+	array.WriteByte('[')
+	for j, row := range rows {
+		if j > 0 {
+			array.WriteByte(',')
+		}
+		array.Write(row)
+	}
+	array.WriteByte(']')
+	return array.String()
+}
+
+// Commit sets the references deferred by InsertRows and commits the imported rows
+func (i *postgresTenantDataImport) Commit() error {
+	ctx := context.Background()
+	for _, patch := range i.patches {
+		if err := i.applyPatch(ctx, patch); err != nil {
+			return err
+		}
+	}
+	i.patches = nil
+
+	return i.tx.Commit()
+}
+
+// applyPatch sets the deferred references of rows of a table, matched by primary key
+func (i *postgresTenantDataImport) applyPatch(ctx context.Context, patch *referencePatch) error {
+	identifier := pq.QuoteIdentifier(patch.table)
+
+	assignments := make([]string, len(patch.columns))
+	for j, column := range patch.columns {
+		quoted := pq.QuoteIdentifier(column)
+		// Columns missing from a patch row are NULL in the original row too
+		assignments[j] = quoted + " = v." + quoted
+	}
+	conditions := make([]string, len(i.primaryKeys[patch.table]))
+	for j, column := range i.primaryKeys[patch.table] {
+		quoted := pq.QuoteIdentifier(column)
+		conditions[j] = "t." + quoted + " = v." + quoted
+	}
+
+	query := "UPDATE " + identifier + " t SET " + strings.Join(assignments, ", ") +
+		" FROM json_populate_recordset(NULL::" + identifier + ", $1::json) v" +
+		" WHERE " + strings.Join(conditions, " AND ")
+
+	if _, err := i.tx.ExecContext(ctx, query, jsonArray(patch.rows)); err != nil {
+		return fmt.Errorf("failed to set references of %s: %w", patch.table, err)
+	}
+
+	return nil
+}
+
+// Rollback discards the imported rows
+func (i *postgresTenantDataImport) Rollback() error {
+	return i.tx.Rollback()
+}
@@ -0,0 +1,109 @@
+package adapters
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestSortTablesByReferences(t *testing.T) {
+	tables := []string{"lessons", "courses", "modules", "media_folders", "quiz_answers", "quizzes", "quiz_questions"}
+	references := map[string][]string{
+		"lessons":        {"courses", "modules"},
+		"modules":        {"courses"},
+		"media_folders":  {"media_folders"},
+		"quiz_questions": {"quizzes"},
+		"quiz_answers":   {"quiz_questions"},
+		"quizzes":        {"courses", "users"}, // users is not a tenant table
+	}
+
+	expected := []string{"courses", "media_folders", "modules", "quizzes", "lessons", "quiz_questions", "quiz_answers"}
+	if sorted := sortTablesByReferences(tables, references); !reflect.DeepEqual(sorted, expected) {
+		t.Errorf("Expected %v, got %v", expected, sorted)
+	}
+}
+
+func TestSortTablesByReferencesWithCycle(t *testing.T) {
+	tables := []string{"b", "a", "c"}
+	references := map[string][]string{
+		"a": {"b"},
+		"b": {"a"},
+	}
+
+	expected := []string{"c", "a", "b"}
+	if sorted := sortTablesByReferences(tables, references); !reflect.DeepEqual(sorted, expected) {
+		t.Errorf("Expected %v, got %v", expected, sorted)
+	}
+}
+
+func TestPendingReferenceColumns(t *testing.T) {
+	i := &postgresTenantDataImport{
+		foreignKeys: map[string][]foreignKey{
+			"a": {
+				{referenced: "b", columns: []string{"b_id"}, nullable: true},
+				{referenced: "c", columns: []string{"c_id"}, nullable: true},
+				{referenced: "a", columns: []string{"parent_id"}, nullable: true},
+				{referenced: "d", columns: []string{"d_id"}, nullable: false},
+			},
+		},
+		imported: map[string]bool{"c": true},
+	}
+
+	// b is in a cycle with a and inserted later, a references itself
+	expected := []string{"b_id", "parent_id"}
+	if columns := i.pendingReferenceColumns("a"); !reflect.DeepEqual(columns, expected) {
+		t.Errorf("Expected %v, got %v", expected, columns)
+	}
+	if columns := i.pendingReferenceColumns("c"); len(columns) != 0 {
+		t.Errorf("Expected no columns for a table without foreign keys, got %v", columns)
+	}
+}
+
+func TestDeferReferences(t *testing.T) {
+	rows := []json.RawMessage{
+		json.RawMessage(`{"id":"1","title":"A","b_id":"b1","parent_id":null}`),
+		json.RawMessage(`{"id":"2","title":"B","b_id":null,"parent_id":null}`),
+		json.RawMessage(`{"id":"3","title":"C","b_id":null,"parent_id":"1"}`),
+	}
+
+	deferred, patches, err := deferReferences(rows, []string{"b_id", "parent_id"}, []string{"id"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expectedRows := []map[string]any{
+		{"id": "1", "title": "A", "b_id": nil, "parent_id": nil},
+		{"id": "2", "title": "B", "b_id": nil, "parent_id": nil},
+		{"id": "3", "title": "C", "b_id": nil, "parent_id": nil},
+	}
+	if len(deferred) != len(expectedRows) {
+		t.Fatalf("Expected %d rows, got %d", len(expectedRows), len(deferred))
+	}
+	for j, row := range deferred {
+		var got map[string]any
+		if err := json.Unmarshal(row, &got); err != nil {
+			t.Fatalf("Invalid row %s: %v", row, err)
+		}
+		if !reflect.DeepEqual(got, expectedRows[j]) {
+			t.Errorf("Row %d: expected %v, got %v", j, expectedRows[j], got)
+		}
+	}
+
+	// Rows without references need no patch
+	expectedPatches := []map[string]any{
+		{"id": "1", "b_id": "b1"},
+		{"id": "3", "parent_id": "1"},
+	}
+	if len(patches) != len(expectedPatches) {
+		t.Fatalf("Expected %d patches, got %d", len(expectedPatches), len(patches))
+	}
+	for j, patch := range patches {
+		var got map[string]any
+		if err := json.Unmarshal(patch, &got); err != nil {
+			t.Fatalf("Invalid patch %s: %v", patch, err)
+		}
+		if !reflect.DeepEqual(got, expectedPatches[j]) {
+			t.Errorf("Patch %d: expected %v, got %v", j, expectedPatches[j], got)
+		}
+	}
+}
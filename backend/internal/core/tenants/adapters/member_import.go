@@ -0,0 +1,110 @@
+package adapters
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	authdomain "github.com/DanielIturra1610/stegmaier-landing/internal/core/auth/domain"
+	authports "github.com/DanielIturra1610/stegmaier-landing/internal/core/auth/ports"
+	enrollmentdomain "github.com/DanielIturra1610/stegmaier-landing/internal/core/enrollments/domain"
+	enrollmentports "github.com/DanielIturra1610/stegmaier-landing/internal/core/enrollments/ports"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/shared/database"
+	"github.com/google/uuid"
+)
+
+// MemberEnrollmentAdapter implements the MemberEnroller interface with the enrollment service,
+// so that imported enrollments follow the same rules (learner quota) as the others
+type MemberEnrollmentAdapter struct {
+	manager     *database.Manager
+	enrollments enrollmentports.EnrollmentService
+}
+
+// NewMemberEnrollmentAdapter creates a new member enrollment adapter
+func NewMemberEnrollmentAdapter(manager *database.Manager, enrollments enrollmentports.EnrollmentService) *MemberEnrollmentAdapter {
+	return &MemberEnrollmentAdapter{
+		manager:     manager,
+		enrollments: enrollments,
+	}
+}
+
+// CourseExists checks if a course of the tenant exists and is not deleted
+func (a *MemberEnrollmentAdapter) CourseExists(ctx context.Context, tenantID, courseID string) (bool, error) {
+	db, err := a.manager.GetTenantConnection(tenantID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get tenant connection: %w", err)
+	}
+
+	query := `SELECT COUNT(*) FROM courses WHERE id = $1 AND tenant_id = $2 AND deleted_at IS NULL`
+
+	var n int
+	if err := db.QueryRowContext(ctx, query, courseID, tenantID).Scan(&n); err != nil {
+		return false, fmt.Errorf("failed to check course: %w", err)
+	}
+
+	return n > 0, nil
+}
+
+// EnrollMember enrolls a user in a course. It returns false if the user was already enrolled.
+func (a *MemberEnrollmentAdapter) EnrollMember(ctx context.Context, tenantID, userID, courseID string) (bool, error) {
+	tenantUUID, err := uuid.Parse(tenantID)
+	if err != nil {
+		return false, fmt.Errorf("invalid tenant ID: %w", err)
+	}
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return false, fmt.Errorf("invalid user ID: %w", err)
+	}
+	courseUUID, err := uuid.Parse(courseID)
+	if err != nil {
+		return false, fmt.Errorf("invalid course ID: %w", err)
+	}
+
+	_, err = a.enrollments.EnrollInCourse(ctx, userUUID, tenantUUID, &enrollmentdomain.EnrollInCourseRequest{CourseID: courseUUID})
+	if errors.Is(err, enrollmentports.ErrAlreadyEnrolled) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// PasswordResetTokenIssuer implements the InvitationTokenIssuer interface with password reset
+// tokens, so that invited users choose their password on the reset password page
+type PasswordResetTokenIssuer struct {
+	authRepo authports.AuthRepository
+}
+
+// NewPasswordResetTokenIssuer creates a new password reset token issuer
+func NewPasswordResetTokenIssuer(authRepo authports.AuthRepository) *PasswordResetTokenIssuer {
+	return &PasswordResetTokenIssuer{
+		authRepo: authRepo,
+	}
+}
+
+// IssuePasswordSetupToken creates a single-use token the user sets their password with
+func (i *PasswordResetTokenIssuer) IssuePasswordSetupToken(ctx context.Context, userID string, expiresIn time.Duration) (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	now := time.Now()
+	token := &authdomain.PasswordResetToken{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		Token:     hex.EncodeToString(b),
+		ExpiresAt: now.Add(expiresIn),
+		CreatedAt: now,
+	}
+	if err := i.authRepo.CreatePasswordResetToken(ctx, token); err != nil {
+		return "", fmt.Errorf("failed to create password setup token: %w", err)
+	}
+
+	return token.Token, nil
+}
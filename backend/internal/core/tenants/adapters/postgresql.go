@@ -3,13 +3,17 @@ package adapters
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/domain"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/ports"
 	"github.com/DanielIturra1610/stegmaier-landing/internal/shared/database"
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 // PostgresTenantRepository implements the TenantRepository interface
@@ -583,3 +587,1269 @@ func (r *PostgresTenantRepository) GetTenantMembersWithUsers(ctx context.Context
 
 	return members, nil
 }
+
+// tenantDomainColumns lists the columns read by the custom domain queries
+const tenantDomainColumns = `id, tenant_id, domain, verification_token, verified_at, created_by, created_at, updated_at`
+
+// rowScanner is implemented by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanTenantDomain scans a row of tenant_domains
+func scanTenantDomain(row rowScanner) (*domain.TenantDomain, error) {
+	var tenantDomain domain.TenantDomain
+	var verifiedAt sql.NullTime
+	var createdBy sql.NullString
+
+	err := row.Scan(
+		&tenantDomain.ID,
+		&tenantDomain.TenantID,
+		&tenantDomain.Domain,
+		&tenantDomain.VerificationToken,
+		&verifiedAt,
+		&createdBy,
+		&tenantDomain.CreatedAt,
+		&tenantDomain.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if verifiedAt.Valid {
+		tenantDomain.VerifiedAt = &verifiedAt.Time
+	}
+	if createdBy.Valid {
+		tenantDomain.CreatedBy = &createdBy.String
+	}
+
+	return &tenantDomain, nil
+}
+
+// ListTenantDomains retrieves the custom domains of a tenant
+func (r *PostgresTenantRepository) ListTenantDomains(ctx context.Context, tenantID string) ([]*domain.TenantDomain, error) {
+	query := `SELECT ` + tenantDomainColumns + `
+		FROM tenant_domains
+		WHERE tenant_id = $1
+		ORDER BY created_at ASC
+	`
+
+	rows, err := r.controlDB.QueryContext(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant domains: %w", err)
+	}
+	defer rows.Close()
+
+	domains := make([]*domain.TenantDomain, 0)
+	for rows.Next() {
+		tenantDomain, err := scanTenantDomain(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan tenant domain: %w", err)
+		}
+		domains = append(domains, tenantDomain)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tenant domains: %w", err)
+	}
+
+	return domains, nil
+}
+
+// GetTenantDomain retrieves a custom domain of a tenant
+func (r *PostgresTenantRepository) GetTenantDomain(ctx context.Context, tenantID, domainID string) (*domain.TenantDomain, error) {
+	query := `SELECT ` + tenantDomainColumns + `
+		FROM tenant_domains
+		WHERE id = $1 AND tenant_id = $2
+	`
+
+	tenantDomain, err := scanTenantDomain(r.controlDB.QueryRowContext(ctx, query, domainID, tenantID))
+	if err == sql.ErrNoRows {
+		return nil, ports.ErrTenantDomainNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant domain: %w", err)
+	}
+
+	return tenantDomain, nil
+}
+
+// CreateTenantDomain registers a custom domain for a tenant
+func (r *PostgresTenantRepository) CreateTenantDomain(ctx context.Context, tenantDomain *domain.TenantDomain) error {
+	query := `
+		INSERT INTO tenant_domains (id, tenant_id, domain, verification_token, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	_, err := r.controlDB.ExecContext(ctx, query,
+		tenantDomain.ID,
+		tenantDomain.TenantID,
+		tenantDomain.Domain,
+		tenantDomain.VerificationToken,
+		tenantDomain.CreatedBy,
+		tenantDomain.CreatedAt,
+		tenantDomain.UpdatedAt,
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ports.ErrTenantDomainExists
+		}
+		return fmt.Errorf("failed to create tenant domain: %w", err)
+	}
+
+	return nil
+}
+
+// MarkTenantDomainVerified records that the ownership of a custom domain was proven
+func (r *PostgresTenantRepository) MarkTenantDomainVerified(ctx context.Context, tenantID, domainID string) error {
+	query := `
+		UPDATE tenant_domains
+		SET verified_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND tenant_id = $2
+	`
+
+	result, err := r.controlDB.ExecContext(ctx, query, domainID, tenantID)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ports.ErrTenantDomainExists
+		}
+		return fmt.Errorf("failed to verify tenant domain: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ports.ErrTenantDomainNotFound
+	}
+
+	return nil
+}
+
+// DeleteTenantDomain removes a custom domain of a tenant
+func (r *PostgresTenantRepository) DeleteTenantDomain(ctx context.Context, tenantID, domainID string) error {
+	result, err := r.controlDB.ExecContext(ctx, "DELETE FROM tenant_domains WHERE id = $1 AND tenant_id = $2", domainID, tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to delete tenant domain: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ports.ErrTenantDomainNotFound
+	}
+
+	return nil
+}
+
+// tenantLifecycleColumns lists the columns scanned by scanTenantLifecycle
+const tenantLifecycleColumns = `id, name, slug, database_name, status, status_reason, status_changed_at,
+		deletion_scheduled_at, deleted_at, created_at`
+
+// scanTenantLifecycle scans the lifecycle columns of a row of tenants
+func scanTenantLifecycle(row rowScanner) (*domain.TenantLifecycle, error) {
+	var tenant domain.TenantLifecycle
+	var statusReason sql.NullString
+	var statusChangedAt, deletionScheduledAt, deletedAt sql.NullTime
+
+	err := row.Scan(
+		&tenant.ID,
+		&tenant.Name,
+		&tenant.Slug,
+		&tenant.DatabaseName,
+		&tenant.Status,
+		&statusReason,
+		&statusChangedAt,
+		&deletionScheduledAt,
+		&deletedAt,
+		&tenant.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if statusReason.Valid {
+		tenant.StatusReason = &statusReason.String
+	}
+	if statusChangedAt.Valid {
+		tenant.StatusChangedAt = &statusChangedAt.Time
+	}
+	if deletionScheduledAt.Valid {
+		tenant.DeletionScheduledAt = &deletionScheduledAt.Time
+	}
+	if deletedAt.Valid {
+		tenant.DeletedAt = &deletedAt.Time
+	}
+
+	return &tenant, nil
+}
+
+// queryTenantLifecycles runs a query selecting tenantLifecycleColumns
+func (r *PostgresTenantRepository) queryTenantLifecycles(ctx context.Context, query string, args ...interface{}) ([]*domain.TenantLifecycle, error) {
+	rows, err := r.controlDB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tenants: %w", err)
+	}
+	defer rows.Close()
+
+	tenants := make([]*domain.TenantLifecycle, 0)
+	for rows.Next() {
+		tenant, err := scanTenantLifecycle(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan tenant: %w", err)
+		}
+		tenants = append(tenants, tenant)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tenants: %w", err)
+	}
+
+	return tenants, nil
+}
+
+// ListTenantLifecycles retrieves the lifecycle state of all tenants, or of those with a status
+func (r *PostgresTenantRepository) ListTenantLifecycles(ctx context.Context, status string) ([]*domain.TenantLifecycle, error) {
+	query := `SELECT ` + tenantLifecycleColumns + `
+		FROM tenants
+		WHERE $1 = '' OR status = $1
+		ORDER BY created_at DESC
+	`
+
+	return r.queryTenantLifecycles(ctx, query, status)
+}
+
+// GetTenantLifecycle retrieves the lifecycle state of a tenant
+func (r *PostgresTenantRepository) GetTenantLifecycle(ctx context.Context, tenantID string) (*domain.TenantLifecycle, error) {
+	query := `SELECT ` + tenantLifecycleColumns + `
+		FROM tenants
+		WHERE id = $1
+	`
+
+	tenant, err := scanTenantLifecycle(r.controlDB.QueryRowContext(ctx, query, tenantID))
+	if err == sql.ErrNoRows {
+		return nil, ports.ErrTenantNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant: %w", err)
+	}
+
+	return tenant, nil
+}
+
+// UpdateTenantStatus changes the status of a tenant if its current status is one of fromStatuses.
+// The check and the update run in one statement so that concurrent changes can't both succeed.
+func (r *PostgresTenantRepository) UpdateTenantStatus(ctx context.Context, tenantID string, fromStatuses []string, status string, reason *string, deletionScheduledAt *time.Time) error {
+	query := `
+		UPDATE tenants
+		SET status = $2, status_reason = $3, deletion_scheduled_at = $4,
+		    status_changed_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND status = ANY($5)
+	`
+
+	result, err := r.controlDB.ExecContext(ctx, query, tenantID, status, reason, deletionScheduledAt, pq.Array(fromStatuses))
+	if err != nil {
+		return fmt.Errorf("failed to update tenant status: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ports.ErrTenantStatusConflict
+	}
+
+	return nil
+}
+
+// ListTenantsDueForDeletion retrieves the tenants whose deletion grace period ended, and the
+// deleted tenants whose database could not be dropped yet
+func (r *PostgresTenantRepository) ListTenantsDueForDeletion(ctx context.Context, now time.Time) ([]*domain.TenantLifecycle, error) {
+	query := `SELECT ` + tenantLifecycleColumns + `
+		FROM tenants
+		WHERE (status = 'pending_deletion' AND deletion_scheduled_at <= $1)
+		   OR (status = 'deleted' AND deletion_scheduled_at IS NOT NULL AND deleted_at IS NULL)
+		ORDER BY deletion_scheduled_at ASC
+	`
+
+	return r.queryTenantLifecycles(ctx, query, now)
+}
+
+// MarkTenantDeleted marks a tenant due for deletion as deleted and removes its memberships,
+// its custom domains and the references of users to it. The tenant row is kept as a tombstone.
+func (r *PostgresTenantRepository) MarkTenantDeleted(ctx context.Context, tenantID string, now time.Time) error {
+	tx, err := r.controlDB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Tenants reactivated in the meantime are no longer due
+	result, err := tx.ExecContext(ctx, `
+		UPDATE tenants
+		SET status = 'deleted', status_changed_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND status = 'pending_deletion' AND deletion_scheduled_at <= $2
+	`, tenantID, now)
+	if err != nil {
+		return fmt.Errorf("failed to mark tenant deleted: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ports.ErrTenantStatusConflict
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM tenant_memberships WHERE tenant_id = $1", tenantID); err != nil {
+		return fmt.Errorf("failed to delete memberships: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM tenant_domains WHERE tenant_id = $1", tenantID); err != nil {
+		return fmt.Errorf("failed to delete tenant domains: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, "UPDATE users SET tenant_id = NULL, updated_at = NOW() WHERE tenant_id = $1", tenantID); err != nil {
+		return fmt.Errorf("failed to detach users from tenant: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// MarkTenantDatabaseDropped records that the database of a deleted tenant was dropped
+func (r *PostgresTenantRepository) MarkTenantDatabaseDropped(ctx context.Context, tenantID string) error {
+	query := `
+		UPDATE tenants
+		SET deleted_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND status = 'deleted'
+	`
+
+	if _, err := r.controlDB.ExecContext(ctx, query, tenantID); err != nil {
+		return fmt.Errorf("failed to mark tenant database dropped: %w", err)
+	}
+
+	return nil
+}
+
+// isUniqueViolation checks if an error was caused by a unique constraint
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+// GetTenantDetails retrieves the data a tenant was created with
+func (r *PostgresTenantRepository) GetTenantDetails(ctx context.Context, tenantID string) (*domain.CreateTenantDTO, error) {
+	query := `
+		SELECT name, slug, description, email, phone, address, website
+		FROM tenants
+		WHERE id = $1
+	`
+
+	var dto domain.CreateTenantDTO
+	var description, email, phone, address, website sql.NullString
+	err := r.controlDB.QueryRowContext(ctx, query, tenantID).Scan(
+		&dto.Name,
+		&dto.Slug,
+		&description,
+		&email,
+		&phone,
+		&address,
+		&website,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ports.ErrTenantNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant details: %w", err)
+	}
+
+	dto.Description = description.String
+	dto.Email = email.String
+	dto.Phone = phone.String
+	if address.Valid {
+		dto.Address = &address.String
+	}
+	if website.Valid {
+		dto.Website = &website.String
+	}
+
+	return &dto, nil
+}
+
+// cloneJobColumns lists the columns scanned by scanCloneJob
+const cloneJobColumns = `id, source_tenant_id, target_tenant_id, target_name, target_slug, learner_data,
+		status, current_step, tables_total, tables_done, rows_copied, files_copied, error,
+		requested_by, started_at, completed_at, created_at, updated_at`
+
+// scanCloneJob scans a row of tenant_clone_jobs
+func scanCloneJob(row rowScanner) (*domain.TenantCloneJob, error) {
+	var job domain.TenantCloneJob
+	var targetTenantID, currentStep, jobError, requestedBy sql.NullString
+	var startedAt, completedAt sql.NullTime
+
+	err := row.Scan(
+		&job.ID,
+		&job.SourceTenantID,
+		&targetTenantID,
+		&job.TargetName,
+		&job.TargetSlug,
+		&job.LearnerData,
+		&job.Status,
+		&currentStep,
+		&job.TablesTotal,
+		&job.TablesDone,
+		&job.RowsCopied,
+		&job.FilesCopied,
+		&jobError,
+		&requestedBy,
+		&startedAt,
+		&completedAt,
+		&job.CreatedAt,
+		&job.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if targetTenantID.Valid {
+		job.TargetTenantID = &targetTenantID.String
+	}
+	if currentStep.Valid {
+		job.CurrentStep = &currentStep.String
+	}
+	if jobError.Valid {
+		job.Error = &jobError.String
+	}
+	if requestedBy.Valid {
+		job.RequestedBy = &requestedBy.String
+	}
+	if startedAt.Valid {
+		job.StartedAt = &startedAt.Time
+	}
+	if completedAt.Valid {
+		job.CompletedAt = &completedAt.Time
+	}
+
+	return &job, nil
+}
+
+// CreateCloneJob creates a tenant clone job
+func (r *PostgresTenantRepository) CreateCloneJob(ctx context.Context, job *domain.TenantCloneJob) error {
+	query := `
+		INSERT INTO tenant_clone_jobs (id, source_tenant_id, target_name, target_slug, learner_data, status,
+			requested_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+
+	_, err := r.controlDB.ExecContext(ctx, query,
+		job.ID,
+		job.SourceTenantID,
+		job.TargetName,
+		job.TargetSlug,
+		job.LearnerData,
+		job.Status,
+		job.RequestedBy,
+		job.CreatedAt,
+		job.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create clone job: %w", err)
+	}
+
+	return nil
+}
+
+// GetCloneJob retrieves a tenant clone job
+func (r *PostgresTenantRepository) GetCloneJob(ctx context.Context, jobID string) (*domain.TenantCloneJob, error) {
+	query := `SELECT ` + cloneJobColumns + `
+		FROM tenant_clone_jobs
+		WHERE id = $1
+	`
+
+	job, err := scanCloneJob(r.controlDB.QueryRowContext(ctx, query, jobID))
+	if err == sql.ErrNoRows {
+		return nil, ports.ErrCloneJobNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get clone job: %w", err)
+	}
+
+	return job, nil
+}
+
+// ListCloneJobs retrieves the clone jobs of a source tenant, newest first
+func (r *PostgresTenantRepository) ListCloneJobs(ctx context.Context, sourceTenantID string) ([]*domain.TenantCloneJob, error) {
+	query := `SELECT ` + cloneJobColumns + `
+		FROM tenant_clone_jobs
+		WHERE source_tenant_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.controlDB.QueryContext(ctx, query, sourceTenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list clone jobs: %w", err)
+	}
+	defer rows.Close()
+
+	jobs := make([]*domain.TenantCloneJob, 0)
+	for rows.Next() {
+		job, err := scanCloneJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan clone job: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating clone jobs: %w", err)
+	}
+
+	return jobs, nil
+}
+
+// UpdateCloneJob saves the target, status and progress of a clone job
+func (r *PostgresTenantRepository) UpdateCloneJob(ctx context.Context, job *domain.TenantCloneJob) error {
+	query := `
+		UPDATE tenant_clone_jobs
+		SET target_tenant_id = $2, status = $3, current_step = $4, tables_total = $5, tables_done = $6,
+		    rows_copied = $7, files_copied = $8, error = $9, started_at = $10, completed_at = $11
+		WHERE id = $1
+	`
+
+	result, err := r.controlDB.ExecContext(ctx, query,
+		job.ID,
+		job.TargetTenantID,
+		job.Status,
+		job.CurrentStep,
+		job.TablesTotal,
+		job.TablesDone,
+		job.RowsCopied,
+		job.FilesCopied,
+		job.Error,
+		job.StartedAt,
+		job.CompletedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update clone job: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ports.ErrCloneJobNotFound
+	}
+
+	return nil
+}
+
+// FailStaleCloneJobs fails the unfinished clone jobs not updated since before
+func (r *PostgresTenantRepository) FailStaleCloneJobs(ctx context.Context, before time.Time, message string) (int64, error) {
+	query := `
+		UPDATE tenant_clone_jobs
+		SET status = 'failed', error = $2, completed_at = NOW()
+		WHERE status IN ('pending', 'running') AND updated_at < $1
+	`
+
+	result, err := r.controlDB.ExecContext(ctx, query, before, message)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fail stale clone jobs: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected, nil
+}
+
+// memberImportJobColumns lists the columns scanned by scanMemberImportJob
+const memberImportJobColumns = `id, tenant_id, file_name, dry_run, status, rows_total, rows_processed,
+		users_created, members_added, members_unchanged, enrollments_created, invitations_sent,
+		rows_failed, row_errors, error, requested_by, started_at, completed_at, created_at, updated_at`
+
+// scanMemberImportJob scans a row of member_import_jobs
+func scanMemberImportJob(row rowScanner) (*domain.MemberImportJob, error) {
+	var job domain.MemberImportJob
+	var rowErrors []byte
+	var jobError, requestedBy sql.NullString
+	var startedAt, completedAt sql.NullTime
+
+	err := row.Scan(
+		&job.ID,
+		&job.TenantID,
+		&job.FileName,
+		&job.DryRun,
+		&job.Status,
+		&job.RowsTotal,
+		&job.RowsProcessed,
+		&job.UsersCreated,
+		&job.MembersAdded,
+		&job.MembersUnchanged,
+		&job.EnrollmentsCreated,
+		&job.InvitationsSent,
+		&job.RowsFailed,
+		&rowErrors,
+		&jobError,
+		&requestedBy,
+		&startedAt,
+		&completedAt,
+		&job.CreatedAt,
+		&job.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	job.RowErrors = make([]domain.MemberImportRowError, 0)
+	if len(rowErrors) > 0 {
+		if err := json.Unmarshal(rowErrors, &job.RowErrors); err != nil {
+			return nil, fmt.Errorf("failed to decode row errors: %w", err)
+		}
+	}
+	if jobError.Valid {
+		job.Error = &jobError.String
+	}
+	if requestedBy.Valid {
+		job.RequestedBy = &requestedBy.String
+	}
+	if startedAt.Valid {
+		job.StartedAt = &startedAt.Time
+	}
+	if completedAt.Valid {
+		job.CompletedAt = &completedAt.Time
+	}
+
+	return &job, nil
+}
+
+// CreateMemberImportJob creates a member import job
+func (r *PostgresTenantRepository) CreateMemberImportJob(ctx context.Context, job *domain.MemberImportJob) error {
+	query := `
+		INSERT INTO member_import_jobs (id, tenant_id, file_name, dry_run, status, rows_total,
+			requested_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+
+	_, err := r.controlDB.ExecContext(ctx, query,
+		job.ID,
+		job.TenantID,
+		job.FileName,
+		job.DryRun,
+		job.Status,
+		job.RowsTotal,
+		job.RequestedBy,
+		job.CreatedAt,
+		job.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create member import job: %w", err)
+	}
+
+	return nil
+}
+
+// GetMemberImportJob retrieves a member import job
+func (r *PostgresTenantRepository) GetMemberImportJob(ctx context.Context, jobID string) (*domain.MemberImportJob, error) {
+	query := `SELECT ` + memberImportJobColumns + `
+		FROM member_import_jobs
+		WHERE id = $1
+	`
+
+	job, err := scanMemberImportJob(r.controlDB.QueryRowContext(ctx, query, jobID))
+	if err == sql.ErrNoRows {
+		return nil, ports.ErrMemberImportJobNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get member import job: %w", err)
+	}
+
+	return job, nil
+}
+
+// ListMemberImportJobs retrieves the member import jobs of a tenant, newest first
+func (r *PostgresTenantRepository) ListMemberImportJobs(ctx context.Context, tenantID string) ([]*domain.MemberImportJob, error) {
+	query := `SELECT ` + memberImportJobColumns + `
+		FROM member_import_jobs
+		WHERE tenant_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.controlDB.QueryContext(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list member import jobs: %w", err)
+	}
+	defer rows.Close()
+
+	jobs := make([]*domain.MemberImportJob, 0)
+	for rows.Next() {
+		job, err := scanMemberImportJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan member import job: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating member import jobs: %w", err)
+	}
+
+	return jobs, nil
+}
+
+// UpdateMemberImportJob saves the status, progress and row errors of a member import job
+func (r *PostgresTenantRepository) UpdateMemberImportJob(ctx context.Context, job *domain.MemberImportJob) error {
+	rowErrors, err := json.Marshal(job.RowErrors)
+	if err != nil {
+		return fmt.Errorf("failed to encode row errors: %w", err)
+	}
+	if job.RowErrors == nil {
+		rowErrors = []byte("[]")
+	}
+
+	query := `
+		UPDATE member_import_jobs
+		SET status = $2, rows_total = $3, rows_processed = $4, users_created = $5, members_added = $6,
+		    members_unchanged = $7, enrollments_created = $8, invitations_sent = $9, rows_failed = $10,
+		    row_errors = $11, error = $12, started_at = $13, completed_at = $14
+		WHERE id = $1
+	`
+
+	result, err := r.controlDB.ExecContext(ctx, query,
+		job.ID,
+		job.Status,
+		job.RowsTotal,
+		job.RowsProcessed,
+		job.UsersCreated,
+		job.MembersAdded,
+		job.MembersUnchanged,
+		job.EnrollmentsCreated,
+		job.InvitationsSent,
+		job.RowsFailed,
+		rowErrors,
+		job.Error,
+		job.StartedAt,
+		job.CompletedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update member import job: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ports.ErrMemberImportJobNotFound
+	}
+
+	return nil
+}
+
+// FailStaleMemberImportJobs fails the unfinished member import jobs not updated since before
+func (r *PostgresTenantRepository) FailStaleMemberImportJobs(ctx context.Context, before time.Time, message string) (int64, error) {
+	query := `
+		UPDATE member_import_jobs
+		SET status = 'failed', error = $2, completed_at = NOW()
+		WHERE status IN ('pending', 'running') AND updated_at < $1
+	`
+
+	result, err := r.controlDB.ExecContext(ctx, query, before, message)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fail stale member import jobs: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected, nil
+}
+
+// GetUserIDByEmail retrieves the ID of the user with an email, ignoring case. It returns ""
+// if there is none.
+func (r *PostgresTenantRepository) GetUserIDByEmail(ctx context.Context, email string) (string, error) {
+	query := `SELECT id FROM users WHERE LOWER(email) = LOWER($1)`
+
+	var userID string
+	err := r.controlDB.QueryRowContext(ctx, query, email).Scan(&userID)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get user by email: %w", err)
+	}
+
+	return userID, nil
+}
+
+// GetMemberInvitation retrieves the invitation of a member added by an import. It returns nil
+// if there is none.
+func (r *PostgresTenantRepository) GetMemberInvitation(ctx context.Context, tenantID, email string) (*domain.MemberInvitation, error) {
+	query := `
+		SELECT tenant_id, email, set_password, sent_at, created_at, updated_at
+		FROM member_invitations
+		WHERE tenant_id = $1 AND email = $2
+	`
+
+	var invitation domain.MemberInvitation
+	var sentAt sql.NullTime
+	err := r.controlDB.QueryRowContext(ctx, query, tenantID, email).Scan(
+		&invitation.TenantID,
+		&invitation.Email,
+		&invitation.SetPassword,
+		&sentAt,
+		&invitation.CreatedAt,
+		&invitation.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get member invitation: %w", err)
+	}
+	if sentAt.Valid {
+		invitation.SentAt = &sentAt.Time
+	}
+
+	return &invitation, nil
+}
+
+// SaveMemberInvitation creates or replaces the invitation of a member, as not sent yet. A user
+// created by an earlier import still chooses a password from the new invitation.
+func (r *PostgresTenantRepository) SaveMemberInvitation(ctx context.Context, invitation *domain.MemberInvitation) error {
+	query := `
+		INSERT INTO member_invitations (tenant_id, email, set_password, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (tenant_id, email) DO UPDATE
+		SET set_password = member_invitations.set_password OR EXCLUDED.set_password, sent_at = NULL
+	`
+
+	_, err := r.controlDB.ExecContext(ctx, query,
+		invitation.TenantID,
+		invitation.Email,
+		invitation.SetPassword,
+		invitation.CreatedAt,
+		invitation.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save member invitation: %w", err)
+	}
+
+	return nil
+}
+
+// MarkMemberInvitationSent records that the invitation of a member was sent
+func (r *PostgresTenantRepository) MarkMemberInvitationSent(ctx context.Context, tenantID, email string, sentAt time.Time) error {
+	query := `UPDATE member_invitations SET sent_at = $3 WHERE tenant_id = $1 AND email = $2`
+
+	if _, err := r.controlDB.ExecContext(ctx, query, tenantID, email, sentAt); err != nil {
+		return fmt.Errorf("failed to mark member invitation sent: %w", err)
+	}
+
+	return nil
+}
+
+// planColumns lists the columns scanned by scanPlan
+const planColumns = `id, code, name, description, max_active_learners, max_courses, max_storage_gb,
+		features, is_default, created_at, updated_at`
+
+// scanPlan scans a row of tenant_plans
+func scanPlan(row rowScanner) (*domain.TenantPlan, error) {
+	var plan domain.TenantPlan
+	var description sql.NullString
+	var maxActiveLearners, maxCourses, maxStorageGB sql.NullInt64
+	var features pq.StringArray
+
+	err := row.Scan(
+		&plan.ID,
+		&plan.Code,
+		&plan.Name,
+		&description,
+		&maxActiveLearners,
+		&maxCourses,
+		&maxStorageGB,
+		&features,
+		&plan.IsDefault,
+		&plan.CreatedAt,
+		&plan.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	plan.Description = description.String
+	plan.MaxActiveLearners = nullIntPtr(maxActiveLearners)
+	plan.MaxCourses = nullIntPtr(maxCourses)
+	plan.MaxStorageGB = nullIntPtr(maxStorageGB)
+	plan.Features = []string(features)
+	if plan.Features == nil {
+		plan.Features = []string{}
+	}
+
+	return &plan, nil
+}
+
+// nullIntPtr converts a nullable integer column to a pointer
+func nullIntPtr(n sql.NullInt64) *int {
+	if !n.Valid {
+		return nil
+	}
+	v := int(n.Int64)
+	return &v
+}
+
+// ListPlans retrieves all plans
+func (r *PostgresTenantRepository) ListPlans(ctx context.Context) ([]*domain.TenantPlan, error) {
+	query := `SELECT ` + planColumns + `
+		FROM tenant_plans
+		ORDER BY name ASC
+	`
+
+	rows, err := r.controlDB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list plans: %w", err)
+	}
+	defer rows.Close()
+
+	plans := make([]*domain.TenantPlan, 0)
+	for rows.Next() {
+		plan, err := scanPlan(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan plan: %w", err)
+		}
+		plans = append(plans, plan)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating plans: %w", err)
+	}
+
+	return plans, nil
+}
+
+// GetPlan retrieves a plan
+func (r *PostgresTenantRepository) GetPlan(ctx context.Context, planID string) (*domain.TenantPlan, error) {
+	query := `SELECT ` + planColumns + `
+		FROM tenant_plans
+		WHERE id = $1
+	`
+
+	plan, err := scanPlan(r.controlDB.QueryRowContext(ctx, query, planID))
+	if err == sql.ErrNoRows {
+		return nil, ports.ErrPlanNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get plan: %w", err)
+	}
+
+	return plan, nil
+}
+
+// CreatePlan creates a plan. If the plan is the default, the previous default plan is unset.
+func (r *PostgresTenantRepository) CreatePlan(ctx context.Context, plan *domain.TenantPlan) error {
+	query := `
+		INSERT INTO tenant_plans (id, code, name, description, max_active_learners, max_courses, max_storage_gb,
+			features, is_default, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+
+	return r.savePlan(ctx, plan, query,
+		plan.ID,
+		plan.Code,
+		plan.Name,
+		plan.Description,
+		plan.MaxActiveLearners,
+		plan.MaxCourses,
+		plan.MaxStorageGB,
+		pq.Array(plan.Features),
+		plan.IsDefault,
+		plan.CreatedAt,
+		plan.UpdatedAt,
+	)
+}
+
+// UpdatePlan replaces the settings of a plan. If the plan is the default, the previous default
+// plan is unset.
+func (r *PostgresTenantRepository) UpdatePlan(ctx context.Context, plan *domain.TenantPlan) error {
+	query := `
+		UPDATE tenant_plans
+		SET code = $2, name = $3, description = $4, max_active_learners = $5, max_courses = $6,
+		    max_storage_gb = $7, features = $8, is_default = $9
+		WHERE id = $1
+	`
+
+	return r.savePlan(ctx, plan, query,
+		plan.ID,
+		plan.Code,
+		plan.Name,
+		plan.Description,
+		plan.MaxActiveLearners,
+		plan.MaxCourses,
+		plan.MaxStorageGB,
+		pq.Array(plan.Features),
+		plan.IsDefault,
+	)
+}
+
+// savePlan runs the insert or update of a plan in a transaction that unsets the previous default plan
+func (r *PostgresTenantRepository) savePlan(ctx context.Context, plan *domain.TenantPlan, query string, args ...interface{}) error {
+	tx, err := r.controlDB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if plan.IsDefault {
+		if _, err := tx.ExecContext(ctx, "UPDATE tenant_plans SET is_default = false WHERE is_default AND id <> $1", plan.ID); err != nil {
+			return fmt.Errorf("failed to unset default plan: %w", err)
+		}
+	}
+
+	result, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ports.ErrPlanExists
+		}
+		return fmt.Errorf("failed to save plan: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ports.ErrPlanNotFound
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// DeletePlan deletes a plan that no tenant is on
+func (r *PostgresTenantRepository) DeletePlan(ctx context.Context, planID string) error {
+	result, err := r.controlDB.ExecContext(ctx, "DELETE FROM tenant_plans WHERE id = $1", planID)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return ports.ErrPlanInUse
+		}
+		return fmt.Errorf("failed to delete plan: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ports.ErrPlanNotFound
+	}
+
+	return nil
+}
+
+// GetTenantPlan retrieves the plan of a tenant, or the default plan if the tenant has none.
+// It returns nil if neither exists.
+func (r *PostgresTenantRepository) GetTenantPlan(ctx context.Context, tenantID string) (*domain.TenantPlan, error) {
+	query := `SELECT ` + planColumns + `
+		FROM tenant_plans
+		WHERE id = (SELECT plan_id FROM tenants WHERE id = $1)
+		   OR (is_default AND NOT EXISTS (SELECT 1 FROM tenants WHERE id = $1 AND plan_id IS NOT NULL))
+		ORDER BY is_default ASC
+		LIMIT 1
+	`
+
+	plan, err := scanPlan(r.controlDB.QueryRowContext(ctx, query, tenantID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant plan: %w", err)
+	}
+
+	return plan, nil
+}
+
+// SetTenantPlan puts a tenant on a plan, or on the default plan if planID is nil
+func (r *PostgresTenantRepository) SetTenantPlan(ctx context.Context, tenantID string, planID *string) error {
+	query := `
+		UPDATE tenants
+		SET plan_id = $2, updated_at = NOW()
+		WHERE id = $1
+	`
+
+	result, err := r.controlDB.ExecContext(ctx, query, tenantID, planID)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return ports.ErrPlanNotFound
+		}
+		return fmt.Errorf("failed to set tenant plan: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ports.ErrTenantNotFound
+	}
+
+	return nil
+}
+
+// GetTenantBranding retrieves the branding of a tenant, with empty settings if it has none
+func (r *PostgresTenantRepository) GetTenantBranding(ctx context.Context, tenantID string) (*domain.TenantBranding, error) {
+	query := `
+		SELECT t.id, t.name, t.slug,
+		       b.logo_media_id, b.logo_url, b.favicon_url, b.palette, b.custom_css_url,
+		       b.email_sender_name, b.email_header, b.email_footer,
+		       b.support_email, b.support_phone, b.support_url, b.updated_at
+		FROM tenants t
+		LEFT JOIN tenant_branding b ON b.tenant_id = t.id
+		WHERE t.id = $1
+	`
+
+	var (
+		branding                               domain.TenantBranding
+		logoMediaID                            sql.NullString
+		logoURL, faviconURL, customCSSURL      sql.NullString
+		senderName, emailHeader, emailFooter   sql.NullString
+		supportEmail, supportPhone, supportURL sql.NullString
+		palette                                []byte
+		updatedAt                              sql.NullTime
+	)
+
+	err := r.controlDB.QueryRowContext(ctx, query, tenantID).Scan(
+		&branding.TenantID, &branding.TenantName, &branding.TenantSlug,
+		&logoMediaID, &logoURL, &faviconURL, &palette, &customCSSURL,
+		&senderName, &emailHeader, &emailFooter,
+		&supportEmail, &supportPhone, &supportURL, &updatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ports.ErrTenantNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant branding: %w", err)
+	}
+
+	if logoMediaID.Valid {
+		branding.LogoMediaID = &logoMediaID.String
+	}
+	if len(palette) > 0 {
+		if err := json.Unmarshal(palette, &branding.Palette); err != nil {
+			return nil, fmt.Errorf("failed to decode branding palette: %w", err)
+		}
+	}
+	if updatedAt.Valid {
+		branding.UpdatedAt = &updatedAt.Time
+	}
+	branding.LogoURL = logoURL.String
+	branding.FaviconURL = faviconURL.String
+	branding.CustomCSSURL = customCSSURL.String
+	branding.EmailSenderName = senderName.String
+	branding.EmailHeader = emailHeader.String
+	branding.EmailFooter = emailFooter.String
+	branding.Support = domain.SupportContact{
+		Email: supportEmail.String,
+		Phone: supportPhone.String,
+		URL:   supportURL.String,
+	}
+
+	return &branding, nil
+}
+
+// SaveTenantBranding creates or replaces the branding of a tenant
+func (r *PostgresTenantRepository) SaveTenantBranding(ctx context.Context, branding *domain.TenantBranding) error {
+	palette, err := json.Marshal(branding.Palette)
+	if err != nil {
+		return fmt.Errorf("failed to encode branding palette: %w", err)
+	}
+
+	query := `
+		INSERT INTO tenant_branding (
+			tenant_id, logo_media_id, logo_url, favicon_url, palette, custom_css_url,
+			email_sender_name, email_header, email_footer,
+			support_email, support_phone, support_url
+		)
+		VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), $5, NULLIF($6, ''),
+			NULLIF($7, ''), NULLIF($8, ''), NULLIF($9, ''),
+			NULLIF($10, ''), NULLIF($11, ''), NULLIF($12, ''))
+		ON CONFLICT (tenant_id) DO UPDATE SET
+			logo_media_id = EXCLUDED.logo_media_id,
+			logo_url = EXCLUDED.logo_url,
+			favicon_url = EXCLUDED.favicon_url,
+			palette = EXCLUDED.palette,
+			custom_css_url = EXCLUDED.custom_css_url,
+			email_sender_name = EXCLUDED.email_sender_name,
+			email_header = EXCLUDED.email_header,
+			email_footer = EXCLUDED.email_footer,
+			support_email = EXCLUDED.support_email,
+			support_phone = EXCLUDED.support_phone,
+			support_url = EXCLUDED.support_url
+		RETURNING updated_at
+	`
+
+	var updatedAt time.Time
+	err = r.controlDB.QueryRowContext(ctx, query,
+		branding.TenantID, branding.LogoMediaID, branding.LogoURL, branding.FaviconURL, palette, branding.CustomCSSURL,
+		branding.EmailSenderName, branding.EmailHeader, branding.EmailFooter,
+		branding.Support.Email, branding.Support.Phone, branding.Support.URL,
+	).Scan(&updatedAt)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return ports.ErrTenantNotFound
+		}
+		return fmt.Errorf("failed to save tenant branding: %w", err)
+	}
+
+	branding.UpdatedAt = &updatedAt
+	return nil
+}
+
+// GetLogoURL retrieves the URL of a ready, public image in the media library of a tenant. Only
+// public files can be logos since the branding is shown before login and in emails.
+func (r *PostgresTenantRepository) GetLogoURL(ctx context.Context, tenantID, mediaID string) (string, error) {
+	db, err := r.manager.GetTenantConnection(tenantID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get tenant connection: %w", err)
+	}
+
+	query := `
+		SELECT url
+		FROM media
+		WHERE id = $1 AND tenant_id = $2
+		  AND media_type = 'image' AND status = 'ready' AND visibility = 'public'
+		  AND deleted_at IS NULL
+	`
+
+	var url string
+	err = db.QueryRowContext(ctx, query, mediaID, tenantID).Scan(&url)
+	if err == sql.ErrNoRows {
+		return "", fmt.Errorf("%w: logo must be a public image of the media library", ports.ErrBrandingInvalid)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get logo media: %w", err)
+	}
+
+	return url, nil
+}
+
+// isForeignKeyViolation checks if an error was caused by a foreign key constraint
+func isForeignKeyViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23503"
+}
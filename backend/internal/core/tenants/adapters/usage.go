@@ -0,0 +1,91 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/DanielIturra1610/stegmaier-landing/internal/shared/database"
+)
+
+// PostgresTenantUsageRepository implements the TenantUsageRepository interface
+type PostgresTenantUsageRepository struct {
+	manager *database.Manager
+}
+
+// NewPostgresTenantUsageRepository creates a new PostgreSQL tenant usage repository
+func NewPostgresTenantUsageRepository(manager *database.Manager) *PostgresTenantUsageRepository {
+	return &PostgresTenantUsageRepository{
+		manager: manager,
+	}
+}
+
+// count runs a query returning a single number in the database of a tenant
+func (r *PostgresTenantUsageRepository) count(ctx context.Context, tenantID, query string, args ...interface{}) (int64, error) {
+	db, err := r.manager.GetTenantConnection(tenantID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get tenant connection: %w", err)
+	}
+
+	var n int64
+	if err := db.QueryRowContext(ctx, query, args...).Scan(&n); err != nil {
+		return 0, err
+	}
+
+	return n, nil
+}
+
+// CountActiveLearners counts the users with a pending or active enrollment
+func (r *PostgresTenantUsageRepository) CountActiveLearners(ctx context.Context, tenantID string) (int64, error) {
+	query := `
+		SELECT COUNT(DISTINCT user_id)
+		FROM enrollments
+		WHERE tenant_id = $1 AND status IN ('pending', 'active')
+	`
+
+	n, err := r.count(ctx, tenantID, query, tenantID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count active learners: %w", err)
+	}
+
+	return n, nil
+}
+
+// IsActiveLearner checks if a user has a pending or active enrollment
+func (r *PostgresTenantUsageRepository) IsActiveLearner(ctx context.Context, tenantID, userID string) (bool, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM enrollments
+		WHERE tenant_id = $1 AND user_id = $2 AND status IN ('pending', 'active')
+	`
+
+	n, err := r.count(ctx, tenantID, query, tenantID, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to check active learner: %w", err)
+	}
+
+	return n > 0, nil
+}
+
+// CountCourses counts the courses that are not deleted
+func (r *PostgresTenantUsageRepository) CountCourses(ctx context.Context, tenantID string) (int64, error) {
+	query := `SELECT COUNT(*) FROM courses WHERE tenant_id = $1 AND deleted_at IS NULL`
+
+	n, err := r.count(ctx, tenantID, query, tenantID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count courses: %w", err)
+	}
+
+	return n, nil
+}
+
+// GetStorageBytes sums the size of the media files that are not deleted
+func (r *PostgresTenantUsageRepository) GetStorageBytes(ctx context.Context, tenantID string) (int64, error) {
+	query := `SELECT COALESCE(SUM(file_size), 0) FROM media WHERE tenant_id = $1 AND deleted_at IS NULL`
+
+	n, err := r.count(ctx, tenantID, query, tenantID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get storage usage: %w", err)
+	}
+
+	return n, nil
+}
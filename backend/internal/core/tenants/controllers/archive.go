@@ -0,0 +1,125 @@
+package controllers
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/domain"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/ports"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/shared/database"
+	"github.com/gofiber/fiber/v2"
+)
+
+// ExportTenantArchive streams an archive of the database and media files of a tenant
+// @Summary Export tenant archive
+// @Description Download a gzip-compressed archive of every table and media file of a tenant (superadmin only)
+// @Tags superadmin
+// @Produce application/gzip
+// @Param tenantId path string true "Tenant ID"
+// @Success 200 {file} file
+// @Failure 403 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
+// @Failure 409 {object} fiber.Map
+// @Router /api/v1/superadmin/tenants/{tenantId}/export [get]
+func (c *TenantController) ExportTenantArchive(ctx *fiber.Ctx) error {
+	tenant, err := c.tenantService.GetTenantLifecycle(ctx.Context(), ctx.Params("tenantId"))
+	if err != nil {
+		return lifecycleErrorResponse(ctx, err)
+	}
+	if tenant.Status == database.TenantStatusDeleted {
+		return lifecycleErrorResponse(ctx, ports.ErrTenantStatusConflict)
+	}
+
+	ctx.Set(fiber.HeaderContentType, "application/gzip")
+	ctx.Set(fiber.HeaderContentDisposition,
+		fmt.Sprintf(`attachment; filename="%s-%s.jsonl.gz"`, tenant.Slug, time.Now().Format("20060102")))
+
+	// The archive is written after the handler returns, so errors can only be logged.
+	// Archives cut short lack their end record and are rejected on import.
+	ctx.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		if _, err := c.tenantService.ExportTenantArchive(context.Background(), tenant.ID, w); err != nil {
+			log.Printf("❌ Failed to export tenant %s (%s): %v", tenant.Slug, tenant.ID, err)
+		}
+		if err := w.Flush(); err != nil {
+			log.Printf("❌ Failed to send archive of tenant %s (%s): %v", tenant.Slug, tenant.ID, err)
+		}
+	})
+
+	return nil
+}
+
+// ImportTenantArchive creates a tenant from an archive. Uploads are bound by the request body
+// limit of the server; larger archives are imported with stegctl tenant import.
+// @Summary Import tenant archive
+// @Description Create a tenant with a new database and fill it with the content of an exported archive (superadmin only). Archives over 10MB must be imported with `stegctl tenant import`.
+// @Tags superadmin
+// @Accept multipart/form-data
+// @Produce json
+// @Param archive formData file true "Tenant archive"
+// @Param name formData string true "Tenant name"
+// @Param slug formData string true "Tenant slug"
+// @Param email formData string true "Tenant email"
+// @Param phone formData string true "Tenant phone"
+// @Param description formData string false "Tenant description"
+// @Success 201 {object} domain.ImportTenantArchiveResponse
+// @Failure 400 {object} fiber.Map
+// @Failure 403 {object} fiber.Map
+// @Failure 413 {object} fiber.Map
+// @Failure 422 {object} fiber.Map
+// @Router /api/v1/superadmin/tenants/import [post]
+func (c *TenantController) ImportTenantArchive(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals("userID").(string)
+	if !ok || userID == "" {
+		return fiber.NewError(fiber.StatusUnauthorized, "Unauthorized")
+	}
+
+	file, err := ctx.FormFile("archive")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Archive file is required",
+		})
+	}
+
+	archive, err := file.Open()
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to read archive file",
+		})
+	}
+	defer archive.Close()
+
+	dto := domain.CreateTenantDTO{
+		Name:        ctx.FormValue("name"),
+		Slug:        ctx.FormValue("slug"),
+		Description: ctx.FormValue("description"),
+		Email:       ctx.FormValue("email"),
+		Phone:       ctx.FormValue("phone"),
+	}
+
+	result, err := c.tenantService.ImportTenantArchive(ctx.Context(), &dto, archive, userID)
+	if err != nil {
+		status := fiber.StatusBadRequest
+		switch {
+		case errors.Is(err, ports.ErrArchiveInvalid), errors.Is(err, ports.ErrArchiveUnsupported):
+			status = fiber.StatusUnprocessableEntity
+		case errors.Is(err, ports.ErrArchiveStorageUnavailable):
+			status = fiber.StatusServiceUnavailable
+		}
+		return ctx.Status(status).JSON(fiber.Map{
+			"success": false,
+			"message": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Tenant imported successfully",
+		"data":    result,
+	})
+}
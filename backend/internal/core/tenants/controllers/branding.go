@@ -0,0 +1,116 @@
+package controllers
+
+import (
+	"errors"
+
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/domain"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/ports"
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetTenantBranding retrieves the branding of the current tenant
+// @Summary Get tenant branding
+// @Description Get the logo, colors, email texts and support contact of the current tenant (admin only)
+// @Tags tenants
+// @Produce json
+// @Success 200 {object} domain.TenantBranding
+// @Failure 401 {object} fiber.Map
+// @Failure 403 {object} fiber.Map
+// @Router /api/v1/tenants/branding [get]
+func (c *TenantController) GetTenantBranding(ctx *fiber.Ctx) error {
+	userID, tenantID, err := getTenantAdminContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	branding, err := c.tenantService.GetTenantBranding(ctx.Context(), tenantID, userID)
+	if err != nil {
+		return brandingErrorResponse(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Branding retrieved successfully",
+		"data":    branding,
+	})
+}
+
+// UpdateTenantBranding replaces the branding of the current tenant
+// @Summary Update tenant branding
+// @Description Replace the logo, colors, email texts and support contact of the current tenant; the logo must be a public image of its media library (admin only)
+// @Tags tenants
+// @Accept json
+// @Produce json
+// @Param branding body domain.TenantBrandingDTO true "Branding"
+// @Success 200 {object} domain.TenantBranding
+// @Failure 400 {object} fiber.Map
+// @Failure 401 {object} fiber.Map
+// @Failure 403 {object} fiber.Map
+// @Router /api/v1/tenants/branding [put]
+func (c *TenantController) UpdateTenantBranding(ctx *fiber.Ctx) error {
+	userID, tenantID, err := getTenantAdminContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	var dto domain.TenantBrandingDTO
+	if err := ctx.BodyParser(&dto); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	branding, err := c.tenantService.UpdateTenantBranding(ctx.Context(), tenantID, &dto, userID)
+	if err != nil {
+		return brandingErrorResponse(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Branding updated successfully",
+		"data":    branding,
+	})
+}
+
+// GetTenantConfig retrieves the public configuration of the tenant of the request
+// @Summary Get tenant config
+// @Description Get the name, logo, colors and support contact of a tenant, identified by custom domain, subdomain, X-Tenant-ID header or tenant_id query, to theme its pages before login
+// @Tags tenants
+// @Produce json
+// @Param tenant_id query string false "Tenant ID"
+// @Success 200 {object} domain.PublicTenantConfig
+// @Failure 404 {object} fiber.Map
+// @Router /api/v1/tenant-config [get]
+func (c *TenantController) GetTenantConfig(ctx *fiber.Ctx) error {
+	tenantID, ok := ctx.Locals("tenant_id").(string)
+	if !ok || tenantID == "" {
+		return fiber.NewError(fiber.StatusNotFound, "Tenant not found")
+	}
+
+	config, err := c.tenantService.GetPublicTenantConfig(ctx.Context(), tenantID)
+	if err != nil {
+		return brandingErrorResponse(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Tenant config retrieved successfully",
+		"data":    config,
+	})
+}
+
+// brandingErrorResponse maps tenant branding errors to HTTP responses
+func brandingErrorResponse(ctx *fiber.Ctx, err error) error {
+	status := fiber.StatusInternalServerError
+	switch {
+	case errors.Is(err, ports.ErrTenantAdminRequired):
+		status = fiber.StatusForbidden
+	case errors.Is(err, ports.ErrTenantNotFound):
+		status = fiber.StatusNotFound
+	case errors.Is(err, ports.ErrBrandingInvalid):
+		status = fiber.StatusBadRequest
+	}
+
+	return ctx.Status(status).JSON(fiber.Map{
+		"success": false,
+		"message": err.Error(),
+	})
+}
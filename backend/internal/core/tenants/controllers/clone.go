@@ -0,0 +1,204 @@
+package controllers
+
+import (
+	"errors"
+
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/domain"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/ports"
+	"github.com/gofiber/fiber/v2"
+)
+
+// CloneTenant starts a clone of the course content of the current tenant
+// @Summary Clone tenant
+// @Description Create a new tenant with a copy of the course content of the current tenant, e.g. a sandbox. Learner data is excluded or anonymized. The copy runs in the background (admin only)
+// @Tags tenants
+// @Accept json
+// @Produce json
+// @Param clone body domain.CloneTenantDTO true "New tenant name, slug and learner data option"
+// @Success 202 {object} domain.TenantCloneJob
+// @Failure 400 {object} fiber.Map
+// @Failure 401 {object} fiber.Map
+// @Failure 403 {object} fiber.Map
+// @Failure 409 {object} fiber.Map
+// @Router /api/v1/tenants/clone [post]
+func (c *TenantController) CloneTenant(ctx *fiber.Ctx) error {
+	userID, tenantID, err := getTenantAdminContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	var dto domain.CloneTenantDTO
+	if err := ctx.BodyParser(&dto); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	job, err := c.tenantService.CloneTenant(ctx.Context(), tenantID, &dto, userID)
+	if err != nil {
+		return cloneErrorResponse(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"success": true,
+		"message": "Tenant clone started",
+		"data":    job,
+	})
+}
+
+// ListTenantCloneJobs retrieves the clone jobs of the current tenant
+// @Summary List tenant clone jobs
+// @Description Get the clone jobs of the current tenant with their progress, newest first (admin only)
+// @Tags tenants
+// @Produce json
+// @Success 200 {array} domain.TenantCloneJob
+// @Failure 401 {object} fiber.Map
+// @Failure 403 {object} fiber.Map
+// @Router /api/v1/tenants/clone-jobs [get]
+func (c *TenantController) ListTenantCloneJobs(ctx *fiber.Ctx) error {
+	userID, tenantID, err := getTenantAdminContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	jobs, err := c.tenantService.ListTenantCloneJobs(ctx.Context(), tenantID, userID)
+	if err != nil {
+		return cloneErrorResponse(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Clone jobs retrieved successfully",
+		"data":    jobs,
+	})
+}
+
+// GetTenantCloneJob retrieves a clone job of the current tenant
+// @Summary Get tenant clone job
+// @Description Get the status and progress of a clone job of the current tenant (admin only)
+// @Tags tenants
+// @Produce json
+// @Param id path string true "Clone job ID"
+// @Success 200 {object} domain.TenantCloneJob
+// @Failure 401 {object} fiber.Map
+// @Failure 403 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
+// @Router /api/v1/tenants/clone-jobs/{id} [get]
+func (c *TenantController) GetTenantCloneJob(ctx *fiber.Ctx) error {
+	userID, tenantID, err := getTenantAdminContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	job, err := c.tenantService.GetTenantCloneJob(ctx.Context(), tenantID, ctx.Params("id"), userID)
+	if err != nil {
+		return cloneErrorResponse(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Clone job retrieved successfully",
+		"data":    job,
+	})
+}
+
+// StartTenantClone starts a clone of the course content of a tenant
+// @Summary Clone tenant
+// @Description Create a new tenant with a copy of the course content of an active tenant. The copy runs in the background (superadmin only)
+// @Tags superadmin
+// @Accept json
+// @Produce json
+// @Param tenantId path string true "Tenant ID"
+// @Param clone body domain.CloneTenantDTO true "New tenant name, slug and learner data option"
+// @Success 202 {object} domain.TenantCloneJob
+// @Failure 400 {object} fiber.Map
+// @Failure 403 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
+// @Failure 409 {object} fiber.Map
+// @Router /api/v1/superadmin/tenants/{tenantId}/clone [post]
+func (c *TenantController) StartTenantClone(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals("userID").(string)
+	if !ok || userID == "" {
+		return fiber.NewError(fiber.StatusUnauthorized, "Unauthorized")
+	}
+
+	var dto domain.CloneTenantDTO
+	if err := ctx.BodyParser(&dto); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	job, err := c.tenantService.StartTenantClone(ctx.Context(), ctx.Params("tenantId"), &dto, userID)
+	if err != nil {
+		return cloneErrorResponse(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"success": true,
+		"message": "Tenant clone started",
+		"data":    job,
+	})
+}
+
+// ListCloneJobs retrieves the clone jobs of a tenant
+// @Summary List clone jobs
+// @Description Get the clone jobs of a tenant with their progress, newest first (superadmin only)
+// @Tags superadmin
+// @Produce json
+// @Param tenantId path string true "Tenant ID"
+// @Success 200 {array} domain.TenantCloneJob
+// @Failure 403 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
+// @Router /api/v1/superadmin/tenants/{tenantId}/clone-jobs [get]
+func (c *TenantController) ListCloneJobs(ctx *fiber.Ctx) error {
+	jobs, err := c.tenantService.ListCloneJobs(ctx.Context(), ctx.Params("tenantId"))
+	if err != nil {
+		return cloneErrorResponse(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Clone jobs retrieved successfully",
+		"data":    jobs,
+	})
+}
+
+// GetCloneJob retrieves a clone job
+// @Summary Get clone job
+// @Description Get the status and progress of a clone job (superadmin only)
+// @Tags superadmin
+// @Produce json
+// @Param id path string true "Clone job ID"
+// @Success 200 {object} domain.TenantCloneJob
+// @Failure 403 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
+// @Router /api/v1/superadmin/tenants/clone-jobs/{id} [get]
+func (c *TenantController) GetCloneJob(ctx *fiber.Ctx) error {
+	job, err := c.tenantService.GetCloneJob(ctx.Context(), ctx.Params("id"))
+	if err != nil {
+		return cloneErrorResponse(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Clone job retrieved successfully",
+		"data":    job,
+	})
+}
+
+// cloneErrorResponse maps tenant clone errors to HTTP responses
+func cloneErrorResponse(ctx *fiber.Ctx, err error) error {
+	status := fiber.StatusInternalServerError
+	switch {
+	case errors.Is(err, ports.ErrTenantAdminRequired):
+		status = fiber.StatusForbidden
+	case errors.Is(err, ports.ErrTenantNotFound), errors.Is(err, ports.ErrCloneJobNotFound):
+		status = fiber.StatusNotFound
+	case errors.Is(err, ports.ErrTenantStatusConflict):
+		status = fiber.StatusConflict
+	case errors.Is(err, ports.ErrCloneInvalid):
+		status = fiber.StatusBadRequest
+	}
+
+	return ctx.Status(status).JSON(fiber.Map{
+		"success": false,
+		"message": err.Error(),
+	})
+}
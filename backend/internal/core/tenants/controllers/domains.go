@@ -0,0 +1,178 @@
+package controllers
+
+import (
+	"errors"
+
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/domain"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/ports"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/middleware"
+	"github.com/gofiber/fiber/v2"
+)
+
+// ListTenantDomains retrieves the custom domains of the current tenant
+// @Summary List tenant domains
+// @Description Get the custom domains of the current tenant with their verification records (admin only)
+// @Tags tenants
+// @Produce json
+// @Success 200 {array} domain.TenantDomainResponse
+// @Failure 401 {object} fiber.Map
+// @Failure 403 {object} fiber.Map
+// @Router /api/v1/tenants/domains [get]
+func (c *TenantController) ListTenantDomains(ctx *fiber.Ctx) error {
+	userID, tenantID, err := getTenantAdminContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	domains, err := c.tenantService.ListTenantDomains(ctx.Context(), tenantID, userID)
+	if err != nil {
+		return domainErrorResponse(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Domains retrieved successfully",
+		"data":    domains,
+	})
+}
+
+// AddTenantDomain registers a custom domain for the current tenant
+// @Summary Add tenant domain
+// @Description Register a custom domain; it identifies the tenant once its TXT record is verified (admin only)
+// @Tags tenants
+// @Accept json
+// @Produce json
+// @Param domain body domain.AddTenantDomainDTO true "Domain data"
+// @Success 201 {object} domain.TenantDomainResponse
+// @Failure 400 {object} fiber.Map
+// @Failure 401 {object} fiber.Map
+// @Failure 403 {object} fiber.Map
+// @Failure 409 {object} fiber.Map
+// @Router /api/v1/tenants/domains [post]
+func (c *TenantController) AddTenantDomain(ctx *fiber.Ctx) error {
+	userID, tenantID, err := getTenantAdminContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	var dto domain.AddTenantDomainDTO
+	if err := ctx.BodyParser(&dto); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request body",
+			"error":   err.Error(),
+		})
+	}
+
+	result, err := c.tenantService.AddTenantDomain(ctx.Context(), &dto, tenantID, userID)
+	if err != nil {
+		return domainErrorResponse(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Domain added, publish the verification record to activate it",
+		"data":    result,
+	})
+}
+
+// VerifyTenantDomain checks the verification TXT record of a custom domain
+// @Summary Verify tenant domain
+// @Description Look up the TXT record of a custom domain and activate the domain if it matches (admin only)
+// @Tags tenants
+// @Produce json
+// @Param id path string true "Domain ID"
+// @Success 200 {object} domain.TenantDomainResponse
+// @Failure 401 {object} fiber.Map
+// @Failure 403 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
+// @Failure 409 {object} fiber.Map
+// @Failure 422 {object} fiber.Map
+// @Router /api/v1/tenants/domains/{id}/verify [post]
+func (c *TenantController) VerifyTenantDomain(ctx *fiber.Ctx) error {
+	userID, tenantID, err := getTenantAdminContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	result, err := c.tenantService.VerifyTenantDomain(ctx.Context(), tenantID, ctx.Params("id"), userID)
+	if err != nil {
+		return domainErrorResponse(ctx, err)
+	}
+
+	// Requests to the domain resolve to the tenant from now on
+	middleware.InvalidateCustomDomain(result.Domain)
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Domain verified successfully",
+		"data":    result,
+	})
+}
+
+// DeleteTenantDomain removes a custom domain of the current tenant
+// @Summary Delete tenant domain
+// @Description Remove a custom domain of the current tenant (admin only)
+// @Tags tenants
+// @Produce json
+// @Param id path string true "Domain ID"
+// @Success 200 {object} fiber.Map
+// @Failure 401 {object} fiber.Map
+// @Failure 403 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
+// @Router /api/v1/tenants/domains/{id} [delete]
+func (c *TenantController) DeleteTenantDomain(ctx *fiber.Ctx) error {
+	userID, tenantID, err := getTenantAdminContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	result, err := c.tenantService.DeleteTenantDomain(ctx.Context(), tenantID, ctx.Params("id"), userID)
+	if err != nil {
+		return domainErrorResponse(ctx, err)
+	}
+
+	middleware.InvalidateCustomDomain(result.Domain)
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Domain deleted successfully",
+	})
+}
+
+// getTenantAdminContext returns the authenticated user and the selected tenant
+func getTenantAdminContext(ctx *fiber.Ctx) (string, string, error) {
+	userID, ok := ctx.Locals("userID").(string)
+	if !ok || userID == "" {
+		return "", "", fiber.NewError(fiber.StatusUnauthorized, "Unauthorized")
+	}
+
+	tenantID, ok := ctx.Locals("tenant_id").(string)
+	if !ok || tenantID == "" {
+		return "", "", fiber.NewError(fiber.StatusBadRequest, "No tenant selected")
+	}
+
+	return userID, tenantID, nil
+}
+
+// domainErrorResponse maps custom domain errors to HTTP responses
+func domainErrorResponse(ctx *fiber.Ctx, err error) error {
+	status := fiber.StatusInternalServerError
+	switch {
+	case errors.Is(err, ports.ErrTenantAdminRequired):
+		status = fiber.StatusForbidden
+	case errors.Is(err, ports.ErrTenantDomainNotFound):
+		status = fiber.StatusNotFound
+	case errors.Is(err, ports.ErrTenantDomainExists):
+		status = fiber.StatusConflict
+	case errors.Is(err, ports.ErrTenantDomainInvalid):
+		status = fiber.StatusBadRequest
+	case errors.Is(err, ports.ErrTenantDomainNotVerified):
+		status = fiber.StatusUnprocessableEntity
+	}
+
+	return ctx.Status(status).JSON(fiber.Map{
+		"success": false,
+		"message": err.Error(),
+	})
+}
@@ -0,0 +1,207 @@
+package controllers
+
+import (
+	"errors"
+
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/domain"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/ports"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/middleware"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+// ListTenantLifecycles retrieves the lifecycle state of the tenants
+// @Summary List tenants
+// @Description Get the lifecycle state of all tenants, optionally filtered by status (superadmin only)
+// @Tags superadmin
+// @Produce json
+// @Param status query string false "Tenant status (active, suspended, pending_deletion, deleted)"
+// @Success 200 {array} domain.TenantLifecycle
+// @Failure 400 {object} fiber.Map
+// @Failure 403 {object} fiber.Map
+// @Router /api/v1/superadmin/tenants [get]
+func (c *TenantController) ListTenantLifecycles(ctx *fiber.Ctx) error {
+	tenants, err := c.tenantService.ListTenantLifecycles(ctx.Context(), ctx.Query("status"))
+	if err != nil {
+		return lifecycleErrorResponse(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Tenants retrieved successfully",
+		"data":    tenants,
+	})
+}
+
+// GetTenantLifecycle retrieves the lifecycle state of a tenant
+// @Summary Get tenant status
+// @Description Get the status of a tenant and when its deletion is scheduled (superadmin only)
+// @Tags superadmin
+// @Produce json
+// @Param tenantId path string true "Tenant ID"
+// @Success 200 {object} domain.TenantLifecycle
+// @Failure 403 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
+// @Router /api/v1/superadmin/tenants/{tenantId}/status [get]
+func (c *TenantController) GetTenantLifecycle(ctx *fiber.Ctx) error {
+	tenant, err := c.tenantService.GetTenantLifecycle(ctx.Context(), ctx.Params("tenantId"))
+	if err != nil {
+		return lifecycleErrorResponse(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Tenant status retrieved successfully",
+		"data":    tenant,
+	})
+}
+
+// SuspendTenant blocks all access to an active tenant
+// @Summary Suspend tenant
+// @Description Reject all requests to an active tenant until it is reactivated (superadmin only)
+// @Tags superadmin
+// @Accept json
+// @Produce json
+// @Param tenantId path string true "Tenant ID"
+// @Param status body domain.ChangeTenantStatusDTO false "Reason"
+// @Success 200 {object} domain.TenantLifecycle
+// @Failure 400 {object} fiber.Map
+// @Failure 403 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
+// @Failure 409 {object} fiber.Map
+// @Router /api/v1/superadmin/tenants/{tenantId}/suspend [post]
+func (c *TenantController) SuspendTenant(ctx *fiber.Ctx) error {
+	dto, err := parseChangeTenantStatusDTO(ctx)
+	if err != nil {
+		return err
+	}
+
+	tenant, err := c.tenantService.SuspendTenant(ctx.Context(), ctx.Params("tenantId"), dto)
+	if err != nil {
+		return lifecycleErrorResponse(ctx, err)
+	}
+
+	middleware.InvalidateTenant(tenant.ID)
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Tenant suspended successfully",
+		"data":    tenant,
+	})
+}
+
+// ReactivateTenant restores access to a suspended tenant or cancels its scheduled deletion
+// @Summary Reactivate tenant
+// @Description Reactivate a suspended tenant or a tenant pending deletion (superadmin only)
+// @Tags superadmin
+// @Produce json
+// @Param tenantId path string true "Tenant ID"
+// @Success 200 {object} domain.TenantLifecycle
+// @Failure 403 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
+// @Failure 409 {object} fiber.Map
+// @Router /api/v1/superadmin/tenants/{tenantId}/reactivate [post]
+func (c *TenantController) ReactivateTenant(ctx *fiber.Ctx) error {
+	tenant, err := c.tenantService.ReactivateTenant(ctx.Context(), ctx.Params("tenantId"))
+	if err != nil {
+		return lifecycleErrorResponse(ctx, err)
+	}
+
+	middleware.InvalidateTenant(tenant.ID)
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Tenant reactivated successfully",
+		"data":    tenant,
+	})
+}
+
+// ScheduleTenantDeletion schedules the deletion of a tenant
+// @Summary Schedule tenant deletion
+// @Description Block all access to a tenant and drop its database once the grace period ends (superadmin only)
+// @Tags superadmin
+// @Accept json
+// @Produce json
+// @Param tenantId path string true "Tenant ID"
+// @Param status body domain.ChangeTenantStatusDTO false "Reason"
+// @Success 200 {object} domain.TenantLifecycle
+// @Failure 400 {object} fiber.Map
+// @Failure 403 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
+// @Failure 409 {object} fiber.Map
+// @Router /api/v1/superadmin/tenants/{tenantId}/schedule-deletion [post]
+func (c *TenantController) ScheduleTenantDeletion(ctx *fiber.Ctx) error {
+	dto, err := parseChangeTenantStatusDTO(ctx)
+	if err != nil {
+		return err
+	}
+
+	tenant, err := c.tenantService.ScheduleTenantDeletion(ctx.Context(), ctx.Params("tenantId"), dto)
+	if err != nil {
+		return lifecycleErrorResponse(ctx, err)
+	}
+
+	middleware.InvalidateTenant(tenant.ID)
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Tenant deletion scheduled successfully",
+		"data":    tenant,
+	})
+}
+
+// CheckSchemaDrift compares every tenant database against the tenant migrations
+// @Summary Check tenant schema drift
+// @Description Compare the migration version, dirty flag, tables and indexes of every active tenant against the latest tenant migration (superadmin only)
+// @Tags superadmin
+// @Produce json
+// @Success 200 {object} database.TenantDriftReport
+// @Failure 403 {object} fiber.Map
+// @Failure 500 {object} fiber.Map
+// @Router /api/v1/superadmin/tenants/schema-drift [get]
+func (c *TenantController) CheckSchemaDrift(ctx *fiber.Ctx) error {
+	report, err := c.tenantService.CheckSchemaDrift()
+	if err != nil {
+		return lifecycleErrorResponse(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Tenant schema drift checked successfully",
+		"data":    report,
+	})
+}
+
+// parseChangeTenantStatusDTO parses the optional body of a status change
+func parseChangeTenantStatusDTO(ctx *fiber.Ctx) (*domain.ChangeTenantStatusDTO, error) {
+	var dto domain.ChangeTenantStatusDTO
+	if len(ctx.Body()) == 0 {
+		return &dto, nil
+	}
+
+	if err := ctx.BodyParser(&dto); err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	return &dto, nil
+}
+
+// lifecycleErrorResponse maps tenant lifecycle errors to HTTP responses
+func lifecycleErrorResponse(ctx *fiber.Ctx, err error) error {
+	status := fiber.StatusInternalServerError
+	switch {
+	case errors.Is(err, ports.ErrTenantNotFound):
+		status = fiber.StatusNotFound
+	case errors.Is(err, ports.ErrTenantStatusConflict):
+		status = fiber.StatusConflict
+	case errors.Is(err, ports.ErrTenantStatusInvalid):
+		status = fiber.StatusBadRequest
+	case errors.As(err, new(validator.ValidationErrors)):
+		status = fiber.StatusBadRequest
+	}
+
+	return ctx.Status(status).JSON(fiber.Map{
+		"success": false,
+		"message": err.Error(),
+	})
+}
@@ -0,0 +1,133 @@
+package controllers
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/ports"
+	"github.com/gofiber/fiber/v2"
+)
+
+// ImportMembers starts an import of the members listed in a CSV or XLSX file
+// @Summary Import members
+// @Description Add the members listed in a CSV or XLSX file to the current tenant and enroll them in courses. The file needs a header with email and full_name columns; role and course_ids are optional. New users are created and invited to choose a password; existing members are left as they are. The rows are imported in the background; with dry_run they are only validated (admin only)
+// @Tags tenants
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "CSV or XLSX file"
+// @Param dry_run formData bool false "Only validate the rows and count what would change"
+// @Success 202 {object} domain.MemberImportJob
+// @Failure 400 {object} fiber.Map
+// @Failure 401 {object} fiber.Map
+// @Failure 403 {object} fiber.Map
+// @Router /api/v1/tenants/members/import [post]
+func (c *TenantController) ImportMembers(ctx *fiber.Ctx) error {
+	userID, tenantID, err := getTenantAdminContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	file, err := ctx.FormFile("file")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Import file is required")
+	}
+
+	dryRun := false
+	if value := ctx.FormValue("dry_run"); value != "" {
+		dryRun, err = strconv.ParseBool(value)
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "dry_run must be true or false")
+		}
+	}
+
+	reader, err := file.Open()
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Failed to read import file")
+	}
+	defer reader.Close()
+
+	job, err := c.tenantService.StartMemberImport(ctx.Context(), tenantID, file.Filename, reader, dryRun, userID)
+	if err != nil {
+		return memberImportErrorResponse(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"success": true,
+		"message": "Member import started",
+		"data":    job,
+	})
+}
+
+// ListMemberImportJobs retrieves the member import jobs of the current tenant
+// @Summary List member import jobs
+// @Description Get the member import jobs of the current tenant with their progress, newest first (admin only)
+// @Tags tenants
+// @Produce json
+// @Success 200 {array} domain.MemberImportJob
+// @Failure 401 {object} fiber.Map
+// @Failure 403 {object} fiber.Map
+// @Router /api/v1/tenants/members/imports [get]
+func (c *TenantController) ListMemberImportJobs(ctx *fiber.Ctx) error {
+	userID, tenantID, err := getTenantAdminContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	jobs, err := c.tenantService.ListMemberImportJobs(ctx.Context(), tenantID, userID)
+	if err != nil {
+		return memberImportErrorResponse(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Member import jobs retrieved successfully",
+		"data":    jobs,
+	})
+}
+
+// GetMemberImportJob retrieves a member import job of the current tenant
+// @Summary Get member import job
+// @Description Get the progress, counts and row errors of a member import job of the current tenant (admin only)
+// @Tags tenants
+// @Produce json
+// @Param id path string true "Import job ID"
+// @Success 200 {object} domain.MemberImportJob
+// @Failure 401 {object} fiber.Map
+// @Failure 403 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
+// @Router /api/v1/tenants/members/imports/{id} [get]
+func (c *TenantController) GetMemberImportJob(ctx *fiber.Ctx) error {
+	userID, tenantID, err := getTenantAdminContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	job, err := c.tenantService.GetMemberImportJob(ctx.Context(), tenantID, ctx.Params("id"), userID)
+	if err != nil {
+		return memberImportErrorResponse(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Member import job retrieved successfully",
+		"data":    job,
+	})
+}
+
+// memberImportErrorResponse maps member import errors to HTTP responses
+func memberImportErrorResponse(ctx *fiber.Ctx, err error) error {
+	status := fiber.StatusInternalServerError
+	switch {
+	case errors.Is(err, ports.ErrTenantAdminRequired):
+		status = fiber.StatusForbidden
+	case errors.Is(err, ports.ErrMemberImportJobNotFound):
+		status = fiber.StatusNotFound
+	case errors.Is(err, ports.ErrMemberImportInvalid):
+		status = fiber.StatusBadRequest
+	}
+
+	return ctx.Status(status).JSON(fiber.Map{
+		"success": false,
+		"message": err.Error(),
+	})
+}
@@ -0,0 +1,215 @@
+package controllers
+
+import (
+	"errors"
+
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/domain"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/ports"
+	"github.com/gofiber/fiber/v2"
+)
+
+// ListPlans retrieves the plans
+// @Summary List plans
+// @Description Get the plans with their limits and features (superadmin only)
+// @Tags superadmin
+// @Produce json
+// @Success 200 {array} domain.TenantPlan
+// @Failure 403 {object} fiber.Map
+// @Router /api/v1/superadmin/plans [get]
+func (c *TenantController) ListPlans(ctx *fiber.Ctx) error {
+	plans, err := c.tenantService.ListPlans(ctx.Context())
+	if err != nil {
+		return planErrorResponse(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Plans retrieved successfully",
+		"data":    plans,
+	})
+}
+
+// CreatePlan defines a plan
+// @Summary Create plan
+// @Description Define a plan with its limits (omitted limits are unlimited) and features (superadmin only)
+// @Tags superadmin
+// @Accept json
+// @Produce json
+// @Param plan body domain.TenantPlanDTO true "Plan data"
+// @Success 201 {object} domain.TenantPlan
+// @Failure 400 {object} fiber.Map
+// @Failure 403 {object} fiber.Map
+// @Failure 409 {object} fiber.Map
+// @Router /api/v1/superadmin/plans [post]
+func (c *TenantController) CreatePlan(ctx *fiber.Ctx) error {
+	var dto domain.TenantPlanDTO
+	if err := ctx.BodyParser(&dto); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	plan, err := c.tenantService.CreatePlan(ctx.Context(), &dto)
+	if err != nil {
+		return planErrorResponse(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Plan created successfully",
+		"data":    plan,
+	})
+}
+
+// UpdatePlan replaces the limits and features of a plan
+// @Summary Update plan
+// @Description Replace the limits and features of a plan; they apply to its tenants right away (superadmin only)
+// @Tags superadmin
+// @Accept json
+// @Produce json
+// @Param id path string true "Plan ID"
+// @Param plan body domain.TenantPlanDTO true "Plan data"
+// @Success 200 {object} domain.TenantPlan
+// @Failure 400 {object} fiber.Map
+// @Failure 403 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
+// @Failure 409 {object} fiber.Map
+// @Router /api/v1/superadmin/plans/{id} [put]
+func (c *TenantController) UpdatePlan(ctx *fiber.Ctx) error {
+	var dto domain.TenantPlanDTO
+	if err := ctx.BodyParser(&dto); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	plan, err := c.tenantService.UpdatePlan(ctx.Context(), ctx.Params("id"), &dto)
+	if err != nil {
+		return planErrorResponse(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Plan updated successfully",
+		"data":    plan,
+	})
+}
+
+// DeletePlan deletes a plan
+// @Summary Delete plan
+// @Description Delete a plan that no tenant is on (superadmin only)
+// @Tags superadmin
+// @Produce json
+// @Param id path string true "Plan ID"
+// @Success 200 {object} fiber.Map
+// @Failure 403 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
+// @Failure 409 {object} fiber.Map
+// @Router /api/v1/superadmin/plans/{id} [delete]
+func (c *TenantController) DeletePlan(ctx *fiber.Ctx) error {
+	if err := c.tenantService.DeletePlan(ctx.Context(), ctx.Params("id")); err != nil {
+		return planErrorResponse(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Plan deleted successfully",
+	})
+}
+
+// AssignTenantPlan changes the plan of a tenant
+// @Summary Assign tenant plan
+// @Description Put a tenant on a plan, or on the default plan if plan_id is null (superadmin only)
+// @Tags superadmin
+// @Accept json
+// @Produce json
+// @Param tenantId path string true "Tenant ID"
+// @Param plan body domain.AssignTenantPlanDTO true "Plan ID"
+// @Success 200 {object} domain.TenantUsage
+// @Failure 400 {object} fiber.Map
+// @Failure 403 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
+// @Router /api/v1/superadmin/tenants/{tenantId}/plan [put]
+func (c *TenantController) AssignTenantPlan(ctx *fiber.Ctx) error {
+	var dto domain.AssignTenantPlanDTO
+	if err := ctx.BodyParser(&dto); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	usage, err := c.tenantService.AssignTenantPlan(ctx.Context(), ctx.Params("tenantId"), &dto)
+	if err != nil {
+		return planErrorResponse(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Tenant plan changed successfully",
+		"data":    usage,
+	})
+}
+
+// GetTenantUsage reports the consumption of a tenant against the limits of its plan
+// @Summary Get tenant usage
+// @Description Get the active learners, courses and media storage of a tenant with the limits of its plan (superadmin only)
+// @Tags superadmin
+// @Produce json
+// @Param tenantId path string true "Tenant ID"
+// @Success 200 {object} domain.TenantUsage
+// @Failure 403 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
+// @Router /api/v1/superadmin/tenants/{tenantId}/usage [get]
+func (c *TenantController) GetTenantUsage(ctx *fiber.Ctx) error {
+	usage, err := c.tenantService.GetTenantUsage(ctx.Context(), ctx.Params("tenantId"))
+	if err != nil {
+		return planErrorResponse(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Tenant usage retrieved successfully",
+		"data":    usage,
+	})
+}
+
+// GetCurrentTenantUsage reports the consumption of the current tenant against the limits of its plan
+// @Summary Get current tenant usage
+// @Description Get the active learners, courses and media storage of the current tenant with the limits of its plan (admin only)
+// @Tags tenants
+// @Produce json
+// @Success 200 {object} domain.TenantUsage
+// @Failure 401 {object} fiber.Map
+// @Failure 403 {object} fiber.Map
+// @Router /api/v1/tenants/usage [get]
+func (c *TenantController) GetCurrentTenantUsage(ctx *fiber.Ctx) error {
+	userID, tenantID, err := getTenantAdminContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	usage, err := c.tenantService.GetCurrentTenantUsage(ctx.Context(), tenantID, userID)
+	if err != nil {
+		return planErrorResponse(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Tenant usage retrieved successfully",
+		"data":    usage,
+	})
+}
+
+// planErrorResponse maps plan errors to HTTP responses
+func planErrorResponse(ctx *fiber.Ctx, err error) error {
+	status := fiber.StatusInternalServerError
+	switch {
+	case errors.Is(err, ports.ErrTenantAdminRequired):
+		status = fiber.StatusForbidden
+	case errors.Is(err, ports.ErrPlanNotFound), errors.Is(err, ports.ErrTenantNotFound):
+		status = fiber.StatusNotFound
+	case errors.Is(err, ports.ErrPlanExists), errors.Is(err, ports.ErrPlanInUse):
+		status = fiber.StatusConflict
+	case errors.Is(err, ports.ErrPlanInvalid):
+		status = fiber.StatusBadRequest
+	}
+
+	return ctx.Status(status).JSON(fiber.Map{
+		"success": false,
+		"message": err.Error(),
+	})
+}
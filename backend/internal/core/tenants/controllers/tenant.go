@@ -1,7 +1,10 @@
 package controllers
 
 import (
+	"errors"
+
 	"github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/domain"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/ports"
 	"github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/services"
 	"github.com/gofiber/fiber/v2"
 )
@@ -213,7 +216,11 @@ func (c *TenantController) InviteUser(ctx *fiber.Ctx) error {
 
 	result, err := c.tenantService.InviteUser(ctx.Context(), &dto, tenantID.(string), userID.(string))
 	if err != nil {
-		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+		status := fiber.StatusBadRequest
+		if errors.Is(err, ports.ErrLearnerLimitReached) {
+			status = fiber.StatusForbidden
+		}
+		return ctx.Status(status).JSON(fiber.Map{
 			"success": false,
 			"message": err.Error(),
 		})
@@ -266,7 +273,11 @@ func (c *TenantController) CreateUserInTenant(ctx *fiber.Ctx) error {
 
 	result, err := c.tenantService.CreateUserInTenant(ctx.Context(), &dto, tenantID.(string), userID.(string))
 	if err != nil {
-		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+		status := fiber.StatusBadRequest
+		if errors.Is(err, ports.ErrLearnerLimitReached) {
+			status = fiber.StatusForbidden
+		}
+		return ctx.Status(status).JSON(fiber.Map{
 			"success": false,
 			"message": err.Error(),
 		})
@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ArchiveFormatVersion is the version of the tenant archive format written by this build
+const ArchiveFormatVersion = 1
+
+// Types of the records of a tenant archive
+const (
+	ArchiveRecordHeader    = "header"
+	ArchiveRecordTable     = "table"
+	ArchiveRecordRow       = "row"
+	ArchiveRecordTableEnd  = "table_end"
+	ArchiveRecordBlob      = "blob"
+	ArchiveRecordBlobChunk = "blob_chunk"
+	ArchiveRecordBlobEnd   = "blob_end"
+	ArchiveRecordEnd       = "end"
+)
+
+// ArchiveRecord is a line of a tenant archive. An archive is a gzip-compressed stream of JSON
+// records: a header, then each table (its rows between a table and a table_end record), then
+// each media file (its content in blob_chunk records between a blob and a blob_end record),
+// and an end record. The table_end and blob_end records carry the SHA-256 of the section, so
+// that corrupted or truncated archives are rejected.
+type ArchiveRecord struct {
+	Type string `json:"type"`
+
+	// Header
+	FormatVersion int        `json:"format_version,omitempty"`
+	SchemaVersion uint       `json:"schema_version,omitempty"`
+	TenantID      string     `json:"tenant_id,omitempty"`
+	TenantSlug    string     `json:"tenant_slug,omitempty"`
+	TenantName    string     `json:"tenant_name,omitempty"`
+	CreatedAt     *time.Time `json:"created_at,omitempty"`
+
+	// Tables
+	Table string          `json:"table,omitempty"`
+	Data  json.RawMessage `json:"data,omitempty"`
+
+	// Media files
+	Key         string `json:"key,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	Size        int64  `json:"size,omitempty"`
+	Chunk       []byte `json:"chunk,omitempty"`
+
+	// Section and archive ends
+	Count  int64  `json:"count,omitempty"`
+	SHA256 string `json:"sha256,omitempty"`
+}
+
+// TenantArchiveSummary describes the content of a tenant archive written or imported
+type TenantArchiveSummary struct {
+	TenantID      string           `json:"tenant_id"`
+	TenantSlug    string           `json:"tenant_slug"`
+	SchemaVersion uint             `json:"schema_version"`
+	Tables        map[string]int64 `json:"tables"`
+	Rows          int64            `json:"rows"`
+	Blobs         int64            `json:"blobs"`
+	BlobBytes     int64            `json:"blob_bytes"`
+}
@@ -0,0 +1,72 @@
+package domain
+
+import "time"
+
+// BrandingPalette represents the colors of a tenant as hex strings. Empty colors use the
+// platform defaults.
+type BrandingPalette struct {
+	Primary    string `json:"primary,omitempty" validate:"omitempty,hexcolor"`
+	Secondary  string `json:"secondary,omitempty" validate:"omitempty,hexcolor"`
+	Accent     string `json:"accent,omitempty" validate:"omitempty,hexcolor"`
+	Background string `json:"background,omitempty" validate:"omitempty,hexcolor"`
+	Text       string `json:"text,omitempty" validate:"omitempty,hexcolor"`
+}
+
+// SupportContact represents where the users of a tenant get help
+type SupportContact struct {
+	Email string `json:"email,omitempty" validate:"omitempty,email,max=255"`
+	Phone string `json:"phone,omitempty" validate:"max=50"`
+	URL   string `json:"url,omitempty" validate:"omitempty,url,max=500"`
+}
+
+// TenantBranding represents the white-label settings of a tenant. Tenants that never set their
+// branding have empty settings.
+type TenantBranding struct {
+	TenantID        string          `json:"tenant_id"`
+	TenantName      string          `json:"tenant_name"`
+	TenantSlug      string          `json:"tenant_slug"`
+	LogoMediaID     *string         `json:"logo_media_id"`
+	LogoURL         string          `json:"logo_url,omitempty"`
+	FaviconURL      string          `json:"favicon_url,omitempty"`
+	Palette         BrandingPalette `json:"palette"`
+	CustomCSSURL    string          `json:"custom_css_url,omitempty"`
+	EmailSenderName string          `json:"email_sender_name,omitempty"`
+	EmailHeader     string          `json:"email_header,omitempty"`
+	EmailFooter     string          `json:"email_footer,omitempty"`
+	Support         SupportContact  `json:"support"`
+	UpdatedAt       *time.Time      `json:"updated_at,omitempty"`
+}
+
+// DisplayName returns the name emails and certificates of the tenant are signed with
+func (b *TenantBranding) DisplayName() string {
+	if b.EmailSenderName != "" {
+		return b.EmailSenderName
+	}
+	return b.TenantName
+}
+
+// PublicTenantConfig represents what clients need to theme the pages of a tenant before login
+type PublicTenantConfig struct {
+	TenantID     string          `json:"tenant_id"`
+	Name         string          `json:"name"`
+	Slug         string          `json:"slug"`
+	LogoURL      string          `json:"logo_url,omitempty"`
+	FaviconURL   string          `json:"favicon_url,omitempty"`
+	Palette      BrandingPalette `json:"palette"`
+	CustomCSSURL string          `json:"custom_css_url,omitempty"`
+	Support      SupportContact  `json:"support"`
+}
+
+// PublicConfig returns the settings of the branding that can be shown to anyone
+func (b *TenantBranding) PublicConfig() *PublicTenantConfig {
+	return &PublicTenantConfig{
+		TenantID:     b.TenantID,
+		Name:         b.TenantName,
+		Slug:         b.TenantSlug,
+		LogoURL:      b.LogoURL,
+		FaviconURL:   b.FaviconURL,
+		Palette:      b.Palette,
+		CustomCSSURL: b.CustomCSSURL,
+		Support:      b.Support,
+	}
+}
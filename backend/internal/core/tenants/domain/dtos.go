@@ -1,5 +1,7 @@
 package domain
 
+import "time"
+
 // CreateTenantDTO represents the data needed to create a new tenant
 type CreateTenantDTO struct {
 	Name        string  `json:"name" validate:"required,min=3,max=100"`
@@ -92,3 +94,93 @@ type SelectTenantResponse struct {
 	Token      string `json:"token"` // New JWT with tenant_id populated
 	Message    string `json:"message"`
 }
+
+// AddTenantDomainDTO represents data to register a custom domain for a tenant
+type AddTenantDomainDTO struct {
+	Domain string `json:"domain" validate:"required,fqdn,max=253"`
+}
+
+// TenantDomainResponse represents a custom domain with the DNS record that verifies it
+type TenantDomainResponse struct {
+	ID         string                `json:"id"`
+	Domain     string                `json:"domain"`
+	Verified   bool                  `json:"verified"`
+	VerifiedAt *time.Time            `json:"verified_at,omitempty"`
+	Record     DomainVerificationTXT `json:"verification_record"`
+	CreatedAt  time.Time             `json:"created_at"`
+}
+
+// DomainVerificationTXT describes the TXT record the tenant must publish to verify a domain
+type DomainVerificationTXT struct {
+	Type  string `json:"type"`
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// NewTenantDomainResponse builds the response of a custom domain
+func NewTenantDomainResponse(d *TenantDomain) *TenantDomainResponse {
+	return &TenantDomainResponse{
+		ID:         d.ID,
+		Domain:     d.Domain,
+		Verified:   d.IsVerified(),
+		VerifiedAt: d.VerifiedAt,
+		Record: DomainVerificationTXT{
+			Type:  "TXT",
+			Name:  d.VerificationRecordName(),
+			Value: d.VerificationRecordValue(),
+		},
+		CreatedAt: d.CreatedAt,
+	}
+}
+
+// ChangeTenantStatusDTO represents the request to suspend a tenant or schedule its deletion
+type ChangeTenantStatusDTO struct {
+	Reason string `json:"reason" validate:"max=500"`
+}
+
+// ImportTenantArchiveResponse represents the response after importing a tenant archive
+type ImportTenantArchiveResponse struct {
+	TenantID     string                `json:"tenant_id"`
+	Name         string                `json:"name"`
+	Slug         string                `json:"slug"`
+	DatabaseName string                `json:"database_name"`
+	Archive      *TenantArchiveSummary `json:"archive"`
+}
+
+// CloneTenantDTO represents the request to clone the course content of a tenant into a new tenant
+type CloneTenantDTO struct {
+	Name        string `json:"name" validate:"required,min=3,max=100"`
+	Slug        string `json:"slug" validate:"required,min=3,max=50,alphanum"`
+	LearnerData string `json:"learner_data" validate:"omitempty,oneof=exclude anonymize"`
+}
+
+// TenantPlanDTO represents the request to create or update a plan. Nil limits are unlimited.
+type TenantPlanDTO struct {
+	Code              string   `json:"code" validate:"required,min=2,max=50"`
+	Name              string   `json:"name" validate:"required,min=2,max=100"`
+	Description       string   `json:"description" validate:"max=500"`
+	MaxActiveLearners *int     `json:"max_active_learners" validate:"omitempty,min=0"`
+	MaxCourses        *int     `json:"max_courses" validate:"omitempty,min=0"`
+	MaxStorageGB      *int     `json:"max_storage_gb" validate:"omitempty,min=0"`
+	Features          []string `json:"features"`
+	IsDefault         bool     `json:"is_default"`
+}
+
+// AssignTenantPlanDTO represents the request to change the plan of a tenant. A nil plan ID
+// puts the tenant on the default plan.
+type AssignTenantPlanDTO struct {
+	PlanID *string `json:"plan_id" validate:"omitempty,uuid"`
+}
+
+// TenantBrandingDTO represents the request to replace the branding of a tenant. A nil or empty
+// logo media ID removes the logo.
+type TenantBrandingDTO struct {
+	LogoMediaID     *string         `json:"logo_media_id" validate:"omitempty,uuid"`
+	FaviconURL      string          `json:"favicon_url" validate:"omitempty,url,startswith=https://,max=500"`
+	Palette         BrandingPalette `json:"palette"`
+	CustomCSSURL    string          `json:"custom_css_url" validate:"omitempty,url,startswith=https://,max=500"`
+	EmailSenderName string          `json:"email_sender_name" validate:"max=100"`
+	EmailHeader     string          `json:"email_header" validate:"max=1000"`
+	EmailFooter     string          `json:"email_footer" validate:"max=2000"`
+	Support         SupportContact  `json:"support"`
+}
@@ -62,3 +62,92 @@ type MemberWithUser struct {
 	Verified      bool      `json:"verified"`
 	UserCreatedAt time.Time `json:"user_created_at"`
 }
+
+// DomainVerificationPrefix is the label under which the verification TXT record of a custom domain is published
+const DomainVerificationPrefix = "_stegmaier-verification"
+
+// TenantDomain represents a custom domain that identifies a tenant (e.g. academy.customer.com)
+type TenantDomain struct {
+	ID                string     `json:"id"`
+	TenantID          string     `json:"tenant_id"`
+	Domain            string     `json:"domain"`
+	VerificationToken string     `json:"verification_token"`
+	VerifiedAt        *time.Time `json:"verified_at,omitempty"`
+	CreatedBy         *string    `json:"created_by,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
+
+// IsVerified checks if the ownership of the domain was proven
+func (d *TenantDomain) IsVerified() bool {
+	return d.VerifiedAt != nil
+}
+
+// VerificationRecordName returns the name of the TXT record that proves ownership of the domain
+func (d *TenantDomain) VerificationRecordName() string {
+	return DomainVerificationPrefix + "." + d.Domain
+}
+
+// VerificationRecordValue returns the value expected in the TXT record
+func (d *TenantDomain) VerificationRecordValue() string {
+	return "stegmaier-verification=" + d.VerificationToken
+}
+
+// TenantLifecycle represents the lifecycle state of a tenant, managed by superadmins.
+// Suspended tenants and tenants pending deletion reject requests; the database of a tenant
+// pending deletion is dropped at DeletionScheduledAt unless it is reactivated before.
+type TenantLifecycle struct {
+	ID                  string     `json:"id"`
+	Name                string     `json:"name"`
+	Slug                string     `json:"slug"`
+	DatabaseName        string     `json:"database_name"`
+	Status              string     `json:"status"`
+	StatusReason        *string    `json:"status_reason,omitempty"`
+	StatusChangedAt     *time.Time `json:"status_changed_at,omitempty"`
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at,omitempty"`
+	DeletedAt           *time.Time `json:"deleted_at,omitempty"`
+	CreatedAt           time.Time  `json:"created_at"`
+}
+
+// Statuses of a tenant clone job
+const (
+	CloneJobStatusPending   = "pending"
+	CloneJobStatusRunning   = "running"
+	CloneJobStatusCompleted = "completed"
+	CloneJobStatusFailed    = "failed"
+)
+
+// What a clone does with the learner data of the source tenant (enrollments, progress,
+// attempts, submissions, ...)
+const (
+	CloneLearnerDataExclude   = "exclude"
+	CloneLearnerDataAnonymize = "anonymize"
+)
+
+// TenantCloneJob represents a background job that copies the course content of a tenant
+// into a new tenant, e.g. a sandbox to try course changes
+type TenantCloneJob struct {
+	ID             string     `json:"id"`
+	SourceTenantID string     `json:"source_tenant_id"`
+	TargetTenantID *string    `json:"target_tenant_id,omitempty"`
+	TargetName     string     `json:"target_name"`
+	TargetSlug     string     `json:"target_slug"`
+	LearnerData    string     `json:"learner_data"`
+	Status         string     `json:"status"`
+	CurrentStep    *string    `json:"current_step,omitempty"`
+	TablesTotal    int        `json:"tables_total"`
+	TablesDone     int        `json:"tables_done"`
+	RowsCopied     int64      `json:"rows_copied"`
+	FilesCopied    int        `json:"files_copied"`
+	Error          *string    `json:"error,omitempty"`
+	RequestedBy    *string    `json:"requested_by,omitempty"`
+	StartedAt      *time.Time `json:"started_at,omitempty"`
+	CompletedAt    *time.Time `json:"completed_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// IsFinished checks if the job completed or failed
+func (j *TenantCloneJob) IsFinished() bool {
+	return j.Status == CloneJobStatusCompleted || j.Status == CloneJobStatusFailed
+}
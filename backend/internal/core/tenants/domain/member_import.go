@@ -0,0 +1,79 @@
+package domain
+
+import "time"
+
+// Statuses of a member import job
+const (
+	MemberImportStatusPending   = "pending"
+	MemberImportStatusRunning   = "running"
+	MemberImportStatusCompleted = "completed"
+	MemberImportStatusFailed    = "failed"
+)
+
+// MemberImportRow represents a member listed in an import file
+type MemberImportRow struct {
+	// Line is the line of the row in the file, counting the header
+	Line      int      `json:"line"`
+	Email     string   `json:"email"`
+	FullName  string   `json:"full_name"`
+	Role      string   `json:"role"`
+	CourseIDs []string `json:"course_ids,omitempty"`
+}
+
+// MemberImportRowError represents why a row of an import file could not be imported
+type MemberImportRowError struct {
+	Line    int    `json:"line"`
+	Email   string `json:"email,omitempty"`
+	Message string `json:"message"`
+}
+
+// MemberImportJob represents a background job that adds the members listed in a file to a
+// tenant. Users that already are members are left as they are, so a file can be imported again
+// after fixing the rows that failed. A dry run only validates the rows and counts what would
+// change.
+type MemberImportJob struct {
+	ID                 string                 `json:"id"`
+	TenantID           string                 `json:"tenant_id"`
+	FileName           string                 `json:"file_name"`
+	DryRun             bool                   `json:"dry_run"`
+	Status             string                 `json:"status"`
+	RowsTotal          int                    `json:"rows_total"`
+	RowsProcessed      int                    `json:"rows_processed"`
+	UsersCreated       int                    `json:"users_created"`
+	MembersAdded       int                    `json:"members_added"`
+	MembersUnchanged   int                    `json:"members_unchanged"`
+	EnrollmentsCreated int                    `json:"enrollments_created"`
+	InvitationsSent    int                    `json:"invitations_sent"`
+	RowsFailed         int                    `json:"rows_failed"`
+	RowErrors          []MemberImportRowError `json:"row_errors"`
+	Error              *string                `json:"error,omitempty"`
+	RequestedBy        *string                `json:"requested_by,omitempty"`
+	StartedAt          *time.Time             `json:"started_at,omitempty"`
+	CompletedAt        *time.Time             `json:"completed_at,omitempty"`
+	CreatedAt          time.Time              `json:"created_at"`
+	UpdatedAt          time.Time              `json:"updated_at"`
+}
+
+// IsFinished checks if the job completed or failed
+func (j *MemberImportJob) IsFinished() bool {
+	return j.Status == MemberImportStatusCompleted || j.Status == MemberImportStatusFailed
+}
+
+// MemberInvitation records the invitation of a member added by an import. It is saved before
+// the member is created and marked sent once the email is out, so that importing the member
+// again after a failure sends the invitation that is missing.
+type MemberInvitation struct {
+	TenantID string `json:"tenant_id"`
+	Email    string `json:"email"`
+	// SetPassword is set when the import created the user, whose invitation carries a link
+	// to choose a password
+	SetPassword bool       `json:"set_password"`
+	SentAt      *time.Time `json:"sent_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// IsPending checks if the invitation was not sent yet
+func (i *MemberInvitation) IsPending() bool {
+	return i.SentAt == nil
+}
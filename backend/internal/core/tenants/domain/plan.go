@@ -0,0 +1,92 @@
+package domain
+
+import "time"
+
+// BytesPerGB converts the storage limit of plans to bytes
+const BytesPerGB int64 = 1 << 30
+
+// Features that plans can include
+const (
+	PlanFeatureCertificates  = "certificates"
+	PlanFeaturePeerReview    = "peer_review"
+	PlanFeatureReviews       = "reviews"
+	PlanFeatureCustomDomains = "custom_domains"
+	PlanFeatureCustomRoles   = "custom_roles"
+	PlanFeatureSSO           = "sso"
+	PlanFeatureAPIKeys       = "api_keys"
+)
+
+// PlanFeatures lists the features that plans can include
+var PlanFeatures = []string{
+	PlanFeatureCertificates,
+	PlanFeaturePeerReview,
+	PlanFeatureReviews,
+	PlanFeatureCustomDomains,
+	PlanFeatureCustomRoles,
+	PlanFeatureSSO,
+	PlanFeatureAPIKeys,
+}
+
+// IsValidPlanFeature checks if a feature can be included in plans
+func IsValidPlanFeature(feature string) bool {
+	for _, f := range PlanFeatures {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
+
+// TenantPlan represents the limits and features of the tenants on a plan. Nil limits are unlimited.
+type TenantPlan struct {
+	ID                string    `json:"id"`
+	Code              string    `json:"code"`
+	Name              string    `json:"name"`
+	Description       string    `json:"description,omitempty"`
+	MaxActiveLearners *int      `json:"max_active_learners"`
+	MaxCourses        *int      `json:"max_courses"`
+	MaxStorageGB      *int      `json:"max_storage_gb"`
+	Features          []string  `json:"features"`
+	IsDefault         bool      `json:"is_default"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// HasFeature checks if the plan includes a feature
+func (p *TenantPlan) HasFeature(feature string) bool {
+	for _, f := range p.Features {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
+
+// MaxStorageBytes returns the storage limit of the plan in bytes, or nil if unlimited
+func (p *TenantPlan) MaxStorageBytes() *int64 {
+	if p.MaxStorageGB == nil {
+		return nil
+	}
+	limit := int64(*p.MaxStorageGB) * BytesPerGB
+	return &limit
+}
+
+// QuotaUsage represents the consumption of a limited resource. A nil limit is unlimited.
+type QuotaUsage struct {
+	Used  int64  `json:"used"`
+	Limit *int64 `json:"limit"`
+}
+
+// Allows checks if the resource can grow by amount without exceeding the limit
+func (u QuotaUsage) Allows(amount int64) bool {
+	return u.Limit == nil || u.Used+amount <= *u.Limit
+}
+
+// TenantUsage represents the consumption of a tenant against the limits of its plan
+type TenantUsage struct {
+	TenantID       string      `json:"tenant_id"`
+	Plan           *TenantPlan `json:"plan"`
+	ActiveLearners QuotaUsage  `json:"active_learners"`
+	Courses        QuotaUsage  `json:"courses"`
+	StorageBytes   QuotaUsage  `json:"storage_bytes"`
+}
@@ -0,0 +1,52 @@
+package ports
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+
+	"github.com/google/uuid"
+)
+
+// TenantArchiveRepository gives access to every table of tenant databases, to export and
+// import tenant archives
+type TenantArchiveRepository interface {
+	// OpenTenantData connects to the database of a tenant, whatever the status of the tenant
+	OpenTenantData(ctx context.Context, databaseName string) (TenantData, error)
+}
+
+// TenantData is a connection to the database of a tenant, closed by the caller
+type TenantData interface {
+	// SchemaVersion returns the migration version of the tenant database and whether it is dirty
+	SchemaVersion(ctx context.Context) (uint, bool, error)
+	// ListTables returns the tables of the tenant schema, referenced tables before the tables referencing them
+	ListTables(ctx context.Context) ([]string, error)
+	// BeginExport starts the read-only transaction in which the rows of the tables are read, so
+	// that every table is exported from the same snapshot
+	BeginExport(ctx context.Context) (TenantDataExport, error)
+	// BeginImport starts the transaction in which the rows of an archive are inserted
+	BeginImport(ctx context.Context) (TenantDataImport, error)
+	Close() error
+}
+
+// TenantDataExport reads the rows of a tenant database within a transaction, closed by the caller
+type TenantDataExport interface {
+	// ExportRows calls fn with each row of a table encoded as a JSON object
+	ExportRows(ctx context.Context, table string, fn func(row json.RawMessage) error) error
+	Close() error
+}
+
+// TenantDataImport inserts rows in a tenant database within a transaction. The rows of a
+// table are inserted before those of the next table, in the order of ListTables.
+type TenantDataImport interface {
+	InsertRows(ctx context.Context, table string, rows []json.RawMessage) error
+	Commit() error
+	Rollback() error
+}
+
+// ArchiveBlobStorage stores the media files of tenants (satisfied by the media StorageService)
+type ArchiveBlobStorage interface {
+	Upload(tenantID uuid.UUID, fileName string, fileReader io.Reader, contentType string, fileSize int64) (string, error)
+	Download(tenantID uuid.UUID, fileName string) (io.ReadCloser, error)
+	DeleteMultiple(tenantID uuid.UUID, fileNames []string) error
+}
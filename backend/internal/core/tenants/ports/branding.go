@@ -0,0 +1,14 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/domain"
+)
+
+// BrandingProvider gives other modules the branding of a tenant, so that emails and certificates
+// carry it; a nil BrandingProvider means the platform branding.
+type BrandingProvider interface {
+	// GetTenantBranding returns the branding of a tenant, with empty settings if it has none
+	GetTenantBranding(ctx context.Context, tenantID string) (*domain.TenantBranding, error)
+}
@@ -0,0 +1,95 @@
+package ports
+
+import "errors"
+
+// Tenant lifecycle errors
+var (
+	// ErrTenantNotFound is returned when a tenant does not exist
+	ErrTenantNotFound = errors.New("tenant not found")
+
+	// ErrTenantStatusConflict is returned when the current status of a tenant doesn't allow a change
+	ErrTenantStatusConflict = errors.New("the tenant status does not allow this change")
+
+	// ErrTenantStatusInvalid is returned when filtering tenants by an unknown status
+	ErrTenantStatusInvalid = errors.New("invalid tenant status")
+)
+
+// Custom domain errors
+var (
+	// ErrTenantDomainNotFound is returned when a domain is not registered in the tenant
+	ErrTenantDomainNotFound = errors.New("domain not found")
+
+	// ErrTenantDomainExists is returned when a domain is already registered by a tenant
+	ErrTenantDomainExists = errors.New("domain is already registered")
+
+	// ErrTenantDomainInvalid is returned when a domain can't be used as a custom domain
+	ErrTenantDomainInvalid = errors.New("invalid domain")
+
+	// ErrTenantDomainNotVerified is returned when the verification TXT record of a domain is not published
+	ErrTenantDomainNotVerified = errors.New("verification TXT record not found")
+
+	// ErrTenantAdminRequired is returned when a non-admin member manages tenant settings
+	ErrTenantAdminRequired = errors.New("only admins can manage the tenant")
+)
+
+// Tenant clone errors
+var (
+	// ErrCloneJobNotFound is returned when a clone job does not exist
+	ErrCloneJobNotFound = errors.New("clone job not found")
+
+	// ErrCloneInvalid is returned when the name, slug or options of a clone are not valid
+	ErrCloneInvalid = errors.New("invalid clone request")
+)
+
+// Plan errors
+var (
+	// ErrPlanNotFound is returned when a plan does not exist
+	ErrPlanNotFound = errors.New("plan not found")
+
+	// ErrPlanExists is returned when the code of a plan is already used by another plan
+	ErrPlanExists = errors.New("a plan with this code already exists")
+
+	// ErrPlanInUse is returned when deleting a plan that tenants are on
+	ErrPlanInUse = errors.New("the plan is assigned to tenants")
+
+	// ErrPlanInvalid is returned when a plan includes an unknown feature or invalid limits
+	ErrPlanInvalid = errors.New("invalid plan")
+)
+
+// Member import errors
+var (
+	// ErrMemberImportInvalid is returned when an import file can't be read or has no valid header
+	ErrMemberImportInvalid = errors.New("invalid member import file")
+	// ErrMemberImportJobNotFound is returned when a member import job does not exist
+	ErrMemberImportJobNotFound = errors.New("member import job not found")
+)
+
+// Branding errors
+var (
+	// ErrBrandingInvalid is returned when a branding setting is not valid or the logo is not a public image of the tenant
+	ErrBrandingInvalid = errors.New("invalid tenant branding")
+)
+
+// Quota errors, returned when an operation would exceed a limit of the tenant plan
+var (
+	// ErrLearnerLimitReached is returned when the tenant has as many active learners as its plan allows
+	ErrLearnerLimitReached = errors.New("the tenant reached the active learner limit of its plan")
+
+	// ErrCourseLimitReached is returned when the tenant has as many courses as its plan allows
+	ErrCourseLimitReached = errors.New("the tenant reached the course limit of its plan")
+
+	// ErrStorageLimitReached is returned when a file doesn't fit in the storage of the tenant plan
+	ErrStorageLimitReached = errors.New("the tenant reached the storage limit of its plan")
+)
+
+// Tenant archive errors
+var (
+	// ErrArchiveInvalid is returned when an archive is corrupted, truncated or was not written by the exporter
+	ErrArchiveInvalid = errors.New("invalid tenant archive")
+
+	// ErrArchiveUnsupported is returned when an archive was written by an incompatible version
+	ErrArchiveUnsupported = errors.New("unsupported tenant archive version")
+
+	// ErrArchiveStorageUnavailable is returned when a tenant with media files is archived without storage
+	ErrArchiveStorageUnavailable = errors.New("media storage is not configured")
+)
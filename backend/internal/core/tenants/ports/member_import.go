@@ -0,0 +1,25 @@
+package ports
+
+import (
+	"context"
+	"time"
+)
+
+// MemberEnroller enrolls imported members in the courses of a tenant
+type MemberEnroller interface {
+	// CourseExists checks if a course of the tenant exists and is not deleted
+	CourseExists(ctx context.Context, tenantID, courseID string) (bool, error)
+	// EnrollMember enrolls a user in a course. It returns false if the user was already enrolled.
+	EnrollMember(ctx context.Context, tenantID, userID, courseID string) (bool, error)
+}
+
+// InvitationTokenIssuer issues the single-use tokens new users choose their first password with
+type InvitationTokenIssuer interface {
+	IssuePasswordSetupToken(ctx context.Context, userID string, expiresIn time.Duration) (string, error)
+}
+
+// InvitationEmailSender sends the emails that tell imported users they were added to a tenant.
+// setPasswordToken is empty for users that already had an account.
+type InvitationEmailSender interface {
+	SendMemberInvitationEmail(ctx context.Context, to, userName, tenantName, role, setPasswordToken string, expiresIn time.Duration) error
+}
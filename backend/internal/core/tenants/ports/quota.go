@@ -0,0 +1,28 @@
+package ports
+
+import "context"
+
+// TenantUsageRepository measures the consumption of the resources limited by plans in the
+// database of a tenant
+type TenantUsageRepository interface {
+	// CountActiveLearners counts the users with a pending or active enrollment
+	CountActiveLearners(ctx context.Context, tenantID string) (int64, error)
+	// IsActiveLearner checks if a user has a pending or active enrollment
+	IsActiveLearner(ctx context.Context, tenantID, userID string) (bool, error)
+	// CountCourses counts the courses that are not deleted
+	CountCourses(ctx context.Context, tenantID string) (int64, error)
+	// GetStorageBytes sums the size of the media files that are not deleted
+	GetStorageBytes(ctx context.Context, tenantID string) (int64, error)
+}
+
+// QuotaService enforces the limits of tenant plans. Other modules call it before they create
+// a limited resource; a nil QuotaService means no limits.
+type QuotaService interface {
+	// CheckLearnerQuota returns ErrLearnerLimitReached if the user would be a new active
+	// learner and the tenant has no learner seats left. An empty userID checks for a new learner.
+	CheckLearnerQuota(ctx context.Context, tenantID, userID string) error
+	// CheckCourseQuota returns ErrCourseLimitReached if the tenant can't create another course
+	CheckCourseQuota(ctx context.Context, tenantID string) error
+	// CheckStorageQuota returns ErrStorageLimitReached if a file of size bytes doesn't fit in the tenant storage
+	CheckStorageQuota(ctx context.Context, tenantID string, size int64) error
+}
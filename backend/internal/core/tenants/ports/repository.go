@@ -2,6 +2,7 @@ package ports
 
 import (
 	"context"
+	"time"
 
 	"github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/domain"
 	"github.com/DanielIturra1610/stegmaier-landing/internal/shared/database"
@@ -16,6 +17,63 @@ type TenantRepository interface {
 	GetTenantBySlug(ctx context.Context, slug string) (*database.TenantInfo, error)
 	TenantExistsBySlug(ctx context.Context, slug string) (bool, error)
 
+	// Lifecycle operations
+	ListTenantLifecycles(ctx context.Context, status string) ([]*domain.TenantLifecycle, error)
+	GetTenantLifecycle(ctx context.Context, tenantID string) (*domain.TenantLifecycle, error)
+	// UpdateTenantStatus changes the status of a tenant only if its current status is one of fromStatuses
+	UpdateTenantStatus(ctx context.Context, tenantID string, fromStatuses []string, status string, reason *string, deletionScheduledAt *time.Time) error
+	ListTenantsDueForDeletion(ctx context.Context, now time.Time) ([]*domain.TenantLifecycle, error)
+	// MarkTenantDeleted marks a tenant due for deletion as deleted and removes its memberships and domains
+	MarkTenantDeleted(ctx context.Context, tenantID string, now time.Time) error
+	MarkTenantDatabaseDropped(ctx context.Context, tenantID string) error
+	// GetTenantDetails returns the data a tenant was created with
+	GetTenantDetails(ctx context.Context, tenantID string) (*domain.CreateTenantDTO, error)
+
+	// Clone job operations
+	CreateCloneJob(ctx context.Context, job *domain.TenantCloneJob) error
+	GetCloneJob(ctx context.Context, jobID string) (*domain.TenantCloneJob, error)
+	ListCloneJobs(ctx context.Context, sourceTenantID string) ([]*domain.TenantCloneJob, error)
+	// UpdateCloneJob saves the target, status and progress of a job
+	UpdateCloneJob(ctx context.Context, job *domain.TenantCloneJob) error
+	// FailStaleCloneJobs fails the unfinished jobs not updated since before, e.g. interrupted by a restart
+	FailStaleCloneJobs(ctx context.Context, before time.Time, message string) (int64, error)
+
+	// Plan operations
+	ListPlans(ctx context.Context) ([]*domain.TenantPlan, error)
+	GetPlan(ctx context.Context, planID string) (*domain.TenantPlan, error)
+	// CreatePlan and UpdatePlan unset the previous default plan when the plan is the default
+	CreatePlan(ctx context.Context, plan *domain.TenantPlan) error
+	UpdatePlan(ctx context.Context, plan *domain.TenantPlan) error
+	DeletePlan(ctx context.Context, planID string) error
+	// GetTenantPlan returns the plan of a tenant, or the default plan, or nil if there is none
+	GetTenantPlan(ctx context.Context, tenantID string) (*domain.TenantPlan, error)
+	// SetTenantPlan puts a tenant on a plan, or on the default plan if planID is nil
+	SetTenantPlan(ctx context.Context, tenantID string, planID *string) error
+
+	// Member import operations
+	CreateMemberImportJob(ctx context.Context, job *domain.MemberImportJob) error
+	GetMemberImportJob(ctx context.Context, jobID string) (*domain.MemberImportJob, error)
+	ListMemberImportJobs(ctx context.Context, tenantID string) ([]*domain.MemberImportJob, error)
+	// UpdateMemberImportJob saves the status, progress and row errors of a job
+	UpdateMemberImportJob(ctx context.Context, job *domain.MemberImportJob) error
+	// FailStaleMemberImportJobs fails the unfinished jobs not updated since before, e.g. interrupted by a restart
+	FailStaleMemberImportJobs(ctx context.Context, before time.Time, message string) (int64, error)
+	// GetUserIDByEmail returns the ID of the user with an email, or "" if there is none
+	GetUserIDByEmail(ctx context.Context, email string) (string, error)
+	// GetMemberInvitation returns the invitation of a member added by an import, by lowercase email, or nil if there is none
+	GetMemberInvitation(ctx context.Context, tenantID, email string) (*domain.MemberInvitation, error)
+	// SaveMemberInvitation creates or replaces the invitation of a member, as not sent yet
+	SaveMemberInvitation(ctx context.Context, invitation *domain.MemberInvitation) error
+	// MarkMemberInvitationSent records that the invitation of a member was sent
+	MarkMemberInvitationSent(ctx context.Context, tenantID, email string, sentAt time.Time) error
+
+	// Branding operations
+	// GetTenantBranding returns the branding of a tenant, with empty settings if it has none
+	GetTenantBranding(ctx context.Context, tenantID string) (*domain.TenantBranding, error)
+	SaveTenantBranding(ctx context.Context, branding *domain.TenantBranding) error
+	// GetLogoURL returns the URL of a ready, public image in the media library of a tenant
+	GetLogoURL(ctx context.Context, tenantID, mediaID string) (string, error)
+
 	// Membership operations
 	CreateMembership(ctx context.Context, membership *domain.TenantMembership) error
 	GetMembership(ctx context.Context, userID, tenantID string) (*domain.TenantMembership, error)
@@ -28,4 +86,16 @@ type TenantRepository interface {
 	UpdateUserTenant(ctx context.Context, userID, tenantID string) error
 	GetTenantMembers(ctx context.Context, tenantID string) ([]*domain.TenantMembership, error)
 	GetTenantMembersWithUsers(ctx context.Context, tenantID string) ([]*domain.MemberWithUser, error)
+
+	// Custom domain operations
+	ListTenantDomains(ctx context.Context, tenantID string) ([]*domain.TenantDomain, error)
+	GetTenantDomain(ctx context.Context, tenantID, domainID string) (*domain.TenantDomain, error)
+	CreateTenantDomain(ctx context.Context, tenantDomain *domain.TenantDomain) error
+	MarkTenantDomainVerified(ctx context.Context, tenantID, domainID string) error
+	DeleteTenantDomain(ctx context.Context, tenantID, domainID string) error
+}
+
+// DNSResolver looks up the TXT records used to verify custom domains (satisfied by *net.Resolver)
+type DNSResolver interface {
+	LookupTXT(ctx context.Context, name string) ([]string, error)
 }
@@ -0,0 +1,334 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/domain"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/ports"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/shared/database"
+	"github.com/google/uuid"
+)
+
+// mediaTable is the table whose rows describe the media files archived with the tenant
+const mediaTable = "media"
+
+// tenantIDColumn is the column with the ID of the tenant in the tables of a tenant database
+const tenantIDColumn = "tenant_id"
+
+// archivedMedia holds the columns of a media row needed to archive its file
+type archivedMedia struct {
+	FileName  string     `json:"file_name"`
+	MimeType  string     `json:"mime_type"`
+	FileSize  int64      `json:"file_size"`
+	DeletedAt *time.Time `json:"deleted_at"`
+}
+
+// ExportTenantArchive writes to w an archive of every table of the tenant database and of the
+// media files of the tenant (superadmin only). Suspended tenants and tenants pending deletion
+// can be exported. When an error is returned, the archive written so far is incomplete and
+// can't be imported.
+func (s *TenantService) ExportTenantArchive(ctx context.Context, tenantID string, w io.Writer) (*domain.TenantArchiveSummary, error) {
+	tenant, err := s.GetTenantLifecycle(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if tenant.Status == database.TenantStatusDeleted {
+		return nil, ports.ErrTenantStatusConflict
+	}
+
+	data, err := s.archiveRepo.OpenTenantData(ctx, tenant.DatabaseName)
+	if err != nil {
+		return nil, err
+	}
+	defer data.Close()
+
+	schemaVersion, dirty, err := data.SchemaVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if dirty {
+		return nil, fmt.Errorf("tenant database has a dirty migration at version %d", schemaVersion)
+	}
+
+	tables, err := data.ListTables(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	archive := newArchiveWriter(w)
+	now := time.Now()
+	if err := archive.writeHeader(&domain.ArchiveRecord{
+		SchemaVersion: schemaVersion,
+		TenantID:      tenant.ID,
+		TenantSlug:    tenant.Slug,
+		TenantName:    tenant.Name,
+		CreatedAt:     &now,
+	}); err != nil {
+		return nil, err
+	}
+
+	export, err := data.BeginExport(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer export.Close()
+
+	var mediaFiles []archivedMedia
+	for _, table := range tables {
+		if err := archive.beginTable(table); err != nil {
+			return nil, err
+		}
+
+		err := export.ExportRows(ctx, table, func(row json.RawMessage) error {
+			if table == mediaTable {
+				var media archivedMedia
+				if err := json.Unmarshal(row, &media); err != nil {
+					return fmt.Errorf("failed to decode media row: %w", err)
+				}
+				// Files of deleted media may be gone from storage
+				if media.DeletedAt == nil {
+					mediaFiles = append(mediaFiles, media)
+				}
+			}
+			return archive.writeRow(row)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if err := archive.endTable(table); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(mediaFiles) > 0 && s.blobStorage == nil {
+		return nil, ports.ErrArchiveStorageUnavailable
+	}
+
+	tenantUUID := uuid.MustParse(tenant.ID)
+	for _, media := range mediaFiles {
+		if err := s.exportMediaFile(archive, tenantUUID, media); err != nil {
+			return nil, err
+		}
+	}
+
+	summary, err := archive.close()
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("📦 [TenantService] Tenant %s (%s) exported: %d rows, %d media files", tenant.Slug, tenant.ID, summary.Rows, summary.Blobs)
+	return summary, nil
+}
+
+// exportMediaFile writes a media file of the tenant to the archive
+func (s *TenantService) exportMediaFile(archive *archiveWriter, tenantID uuid.UUID, media archivedMedia) error {
+	content, err := s.blobStorage.Download(tenantID, media.FileName)
+	if err != nil {
+		return fmt.Errorf("failed to download media file %s: %w", media.FileName, err)
+	}
+	defer content.Close()
+
+	return archive.writeBlob(media.FileName, media.MimeType, media.FileSize, content)
+}
+
+// ImportTenantArchive creates a tenant from an archive written by ExportTenantArchive
+// (superadmin only). The tenant database is created with CreateTenantWithMigrations and must
+// be at the schema version of the archive. The ID of the archived tenant is replaced with the
+// ID of the new tenant in the tenant_id column of every row. Memberships are not archived, so the owner is the only
+// member of the new tenant.
+func (s *TenantService) ImportTenantArchive(ctx context.Context, dto *domain.CreateTenantDTO, archive io.Reader, ownerID string) (*domain.ImportTenantArchiveResponse, error) {
+	if err := s.validateNewTenant(ctx, dto); err != nil {
+		return nil, err
+	}
+
+	dbName := fmt.Sprintf("tenant_%s", dto.Slug)
+	tenantID, err := s.repo.CreateTenant(ctx, dto.Name, dto.Slug, dbName, dto.Description, dto.Email, dto.Phone, dto.Address, dto.Website, ownerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tenant: %w", err)
+	}
+
+	if err := s.migrationRunner.CreateTenantWithMigrations(tenantID, dbName, "migrations/tenants"); err != nil {
+		_ = s.repo.DeleteTenant(ctx, tenantID)
+		return nil, err
+	}
+
+	summary, err := s.importArchiveData(ctx, tenantID, dbName, archive)
+	if err != nil {
+		log.Printf("❌ [TenantService] Failed to import archive into tenant %s: %v", tenantID, err)
+		// Rollback: drop database and delete tenant record
+		_ = s.manager.DropTenantDatabase(tenantID, dbName)
+		_ = s.repo.DeleteTenant(ctx, tenantID)
+		return nil, err
+	}
+
+	log.Printf("📦 [TenantService] Archive of tenant %s imported into %s (%s): %d rows, %d media files",
+		summary.TenantSlug, dto.Slug, tenantID, summary.Rows, summary.Blobs)
+
+	return &domain.ImportTenantArchiveResponse{
+		TenantID:     tenantID,
+		Name:         dto.Name,
+		Slug:         dto.Slug,
+		DatabaseName: dbName,
+		Archive:      summary,
+	}, nil
+}
+
+// importArchiveData inserts the rows and uploads the media files of an archive into a new tenant
+func (s *TenantService) importArchiveData(ctx context.Context, tenantID, dbName string, archive io.Reader) (*domain.TenantArchiveSummary, error) {
+	data, err := s.archiveRepo.OpenTenantData(ctx, dbName)
+	if err != nil {
+		return nil, err
+	}
+	defer data.Close()
+
+	importer := &archiveImporter{
+		ctx:      ctx,
+		service:  s,
+		data:     data,
+		tenantID: tenantID,
+	}
+
+	summary, err := readArchive(archive, importer)
+	if err == nil {
+		err = importer.commit()
+	}
+	if err != nil {
+		importer.discard()
+		return nil, err
+	}
+
+	return summary, nil
+}
+
+// archiveImporter inserts the content of an archive into the database and storage of a new tenant
+type archiveImporter struct {
+	ctx            context.Context
+	service        *TenantService
+	data           ports.TenantData
+	tenantID       string
+	sourceTenantID string
+	tables         map[string]bool
+	tx             ports.TenantDataImport
+	uploaded       []string
+}
+
+// header checks that the archive matches the schema of the new tenant database
+func (i *archiveImporter) header(record *domain.ArchiveRecord) error {
+	if _, err := uuid.Parse(record.TenantID); err != nil {
+		return fmt.Errorf("%w: invalid tenant ID", ports.ErrArchiveInvalid)
+	}
+	i.sourceTenantID = record.TenantID
+
+	schemaVersion, _, err := i.data.SchemaVersion(i.ctx)
+	if err != nil {
+		return err
+	}
+	if record.SchemaVersion != schemaVersion {
+		return fmt.Errorf("%w: archive schema version %d, database schema version %d",
+			ports.ErrArchiveUnsupported, record.SchemaVersion, schemaVersion)
+	}
+
+	tables, err := i.data.ListTables(i.ctx)
+	if err != nil {
+		return err
+	}
+	i.tables = make(map[string]bool, len(tables))
+	for _, table := range tables {
+		i.tables[table] = true
+	}
+
+	i.tx, err = i.data.BeginImport(i.ctx)
+	return err
+}
+
+// rows inserts rows of the archive, replacing the ID of the archived tenant in their tenant_id column
+func (i *archiveImporter) rows(table string, rows []json.RawMessage) error {
+	if !i.tables[table] {
+		return fmt.Errorf("%w: unknown table %s", ports.ErrArchiveInvalid, table)
+	}
+
+	for j, row := range rows {
+		replaced, err := replaceTenantID(row, i.sourceTenantID, i.tenantID)
+		if err != nil {
+			return fmt.Errorf("%w: invalid %s row: %v", ports.ErrArchiveInvalid, table, err)
+		}
+		rows[j] = replaced
+	}
+
+	return i.tx.InsertRows(i.ctx, table, rows)
+}
+
+// replaceTenantID replaces the ID of the source tenant with the ID of the target tenant in the
+// tenant_id column of a row. Other columns are left untouched, even when they hold the same
+// UUID (e.g. an external ID or text mentioning the tenant).
+func replaceTenantID(row json.RawMessage, sourceTenantID, targetTenantID string) (json.RawMessage, error) {
+	var columns map[string]json.RawMessage
+	if err := json.Unmarshal(row, &columns); err != nil {
+		return nil, err
+	}
+
+	value, ok := columns[tenantIDColumn]
+	if !ok {
+		return row, nil
+	}
+	var tenantID *string
+	if err := json.Unmarshal(value, &tenantID); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", tenantIDColumn, err)
+	}
+	if tenantID == nil || !strings.EqualFold(*tenantID, sourceTenantID) {
+		return row, nil
+	}
+
+	target, err := json.Marshal(targetTenantID)
+	if err != nil {
+		return nil, err
+	}
+	columns[tenantIDColumn] = target
+
+	return json.Marshal(columns)
+}
+
+// blob uploads a media file of the archive to the storage of the new tenant
+func (i *archiveImporter) blob(key, contentType string, size int64, content io.Reader) error {
+	if i.service.blobStorage == nil {
+		return ports.ErrArchiveStorageUnavailable
+	}
+
+	if _, err := i.service.blobStorage.Upload(uuid.MustParse(i.tenantID), key, content, contentType, size); err != nil {
+		return fmt.Errorf("failed to upload media file %s: %w", key, err)
+	}
+	i.uploaded = append(i.uploaded, key)
+
+	return nil
+}
+
+// commit commits the imported rows
+func (i *archiveImporter) commit() error {
+	if i.tx == nil {
+		return fmt.Errorf("%w: missing header", ports.ErrArchiveInvalid)
+	}
+	if err := i.tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit imported rows: %w", err)
+	}
+	i.tx = nil
+	return nil
+}
+
+// discard rolls back the imported rows and deletes the uploaded media files
+func (i *archiveImporter) discard() {
+	if i.tx != nil {
+		_ = i.tx.Rollback()
+	}
+	if len(i.uploaded) > 0 {
+		if err := i.service.blobStorage.DeleteMultiple(uuid.MustParse(i.tenantID), i.uploaded); err != nil {
+			log.Printf("⚠️  [TenantService] Failed to delete media files of failed import into tenant %s: %v", i.tenantID, err)
+		}
+	}
+}
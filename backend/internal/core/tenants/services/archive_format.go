@@ -0,0 +1,350 @@
+package services
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/domain"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/ports"
+)
+
+const (
+	// archiveBatchSize is the number of rows handed over at once when reading an archive
+	archiveBatchSize = 500
+
+	// archiveChunkSize is the size of the chunks in which media files are written
+	archiveChunkSize = 512 * 1024
+)
+
+// archiveWriter writes the records of a tenant archive and the checksums of its sections
+type archiveWriter struct {
+	gz       *gzip.Writer
+	encoder  *json.Encoder
+	section  hash.Hash
+	count    int64
+	sections int64
+	summary  *domain.TenantArchiveSummary
+}
+
+// newArchiveWriter creates a writer of a tenant archive
+func newArchiveWriter(w io.Writer) *archiveWriter {
+	gz := gzip.NewWriter(w)
+	encoder := json.NewEncoder(gz)
+	// Rows are written as they are hashed
+	encoder.SetEscapeHTML(false)
+
+	return &archiveWriter{
+		gz:      gz,
+		encoder: encoder,
+		summary: &domain.TenantArchiveSummary{Tables: make(map[string]int64)},
+	}
+}
+
+// writeHeader starts the archive
+func (w *archiveWriter) writeHeader(header *domain.ArchiveRecord) error {
+	header.Type = domain.ArchiveRecordHeader
+	header.FormatVersion = domain.ArchiveFormatVersion
+
+	w.summary.TenantID = header.TenantID
+	w.summary.TenantSlug = header.TenantSlug
+	w.summary.SchemaVersion = header.SchemaVersion
+
+	return w.encoder.Encode(header)
+}
+
+// beginTable starts the section of a table
+func (w *archiveWriter) beginTable(table string) error {
+	w.section = sha256.New()
+	w.count = 0
+	return w.encoder.Encode(&domain.ArchiveRecord{Type: domain.ArchiveRecordTable, Table: table})
+}
+
+// writeRow writes a row of the current table
+func (w *archiveWriter) writeRow(row json.RawMessage) error {
+	var compacted bytes.Buffer
+	if err := json.Compact(&compacted, row); err != nil {
+		return fmt.Errorf("failed to encode row: %w", err)
+	}
+
+	w.section.Write(compacted.Bytes())
+	w.section.Write([]byte{'\n'})
+	w.count++
+
+	return w.encoder.Encode(&domain.ArchiveRecord{Type: domain.ArchiveRecordRow, Data: compacted.Bytes()})
+}
+
+// endTable ends the section of a table with its row count and checksum
+func (w *archiveWriter) endTable(table string) error {
+	w.summary.Tables[table] = w.count
+	w.summary.Rows += w.count
+	w.sections++
+
+	return w.encoder.Encode(&domain.ArchiveRecord{
+		Type:   domain.ArchiveRecordTableEnd,
+		Table:  table,
+		Count:  w.count,
+		SHA256: hex.EncodeToString(w.section.Sum(nil)),
+	})
+}
+
+// writeBlob writes a media file, which must have the given size
+func (w *archiveWriter) writeBlob(key, contentType string, size int64, content io.Reader) error {
+	if err := w.encoder.Encode(&domain.ArchiveRecord{
+		Type:        domain.ArchiveRecordBlob,
+		Key:         key,
+		ContentType: contentType,
+		Size:        size,
+	}); err != nil {
+		return err
+	}
+
+	checksum := sha256.New()
+	var written int64
+	buf := make([]byte, archiveChunkSize)
+	for {
+		n, err := io.ReadFull(content, buf)
+		if n > 0 {
+			checksum.Write(buf[:n])
+			written += int64(n)
+			if err := w.encoder.Encode(&domain.ArchiveRecord{Type: domain.ArchiveRecordBlobChunk, Chunk: buf[:n]}); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read media file %s: %w", key, err)
+		}
+	}
+
+	if written != size {
+		return fmt.Errorf("media file %s has %d bytes, expected %d", key, written, size)
+	}
+
+	w.summary.Blobs++
+	w.summary.BlobBytes += written
+	w.sections++
+
+	return w.encoder.Encode(&domain.ArchiveRecord{
+		Type:   domain.ArchiveRecordBlobEnd,
+		Key:    key,
+		Count:  written,
+		SHA256: hex.EncodeToString(checksum.Sum(nil)),
+	})
+}
+
+// close ends the archive with its section count. Archives not closed are rejected on import.
+func (w *archiveWriter) close() (*domain.TenantArchiveSummary, error) {
+	if err := w.encoder.Encode(&domain.ArchiveRecord{Type: domain.ArchiveRecordEnd, Count: w.sections}); err != nil {
+		return nil, err
+	}
+	if err := w.gz.Close(); err != nil {
+		return nil, err
+	}
+
+	return w.summary, nil
+}
+
+// archiveHandler receives the content of a tenant archive while it is read
+type archiveHandler interface {
+	// header receives the header before any other record
+	header(record *domain.ArchiveRecord) error
+	// rows receives the rows of a table in batches of up to archiveBatchSize rows
+	rows(table string, rows []json.RawMessage) error
+	// blob receives a media file; its content must be read before returning
+	blob(key, contentType string, size int64, content io.Reader) error
+}
+
+// readArchive reads a tenant archive and verifies the checksum of each section. Content is
+// handed over before its section is verified, so callers must discard everything they
+// received when an error is returned.
+func readArchive(r io.Reader, handler archiveHandler) (*domain.TenantArchiveSummary, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ports.ErrArchiveInvalid, err)
+	}
+	defer gz.Close()
+
+	reader := &archiveReader{decoder: json.NewDecoder(gz)}
+	summary := &domain.TenantArchiveSummary{Tables: make(map[string]int64)}
+
+	header, err := reader.next()
+	if err != nil {
+		return nil, err
+	}
+	if header.Type != domain.ArchiveRecordHeader {
+		return nil, fmt.Errorf("%w: missing header", ports.ErrArchiveInvalid)
+	}
+	if header.FormatVersion != domain.ArchiveFormatVersion {
+		return nil, fmt.Errorf("%w: format version %d", ports.ErrArchiveUnsupported, header.FormatVersion)
+	}
+	if err := handler.header(header); err != nil {
+		return nil, err
+	}
+	summary.TenantID = header.TenantID
+	summary.TenantSlug = header.TenantSlug
+	summary.SchemaVersion = header.SchemaVersion
+
+	var sections int64
+	for {
+		record, err := reader.next()
+		if err != nil {
+			return nil, err
+		}
+
+		switch record.Type {
+		case domain.ArchiveRecordTable:
+			count, err := reader.readTable(record.Table, handler)
+			if err != nil {
+				return nil, err
+			}
+			summary.Tables[record.Table] = count
+			summary.Rows += count
+
+		case domain.ArchiveRecordBlob:
+			size, err := reader.readBlob(record, handler)
+			if err != nil {
+				return nil, err
+			}
+			summary.Blobs++
+			summary.BlobBytes += size
+
+		case domain.ArchiveRecordEnd:
+			if record.Count != sections {
+				return nil, fmt.Errorf("%w: expected %d sections, got %d", ports.ErrArchiveInvalid, record.Count, sections)
+			}
+			return summary, nil
+
+		default:
+			return nil, fmt.Errorf("%w: unexpected %q record", ports.ErrArchiveInvalid, record.Type)
+		}
+		sections++
+	}
+}
+
+// archiveReader decodes the records of a tenant archive
+type archiveReader struct {
+	decoder *json.Decoder
+}
+
+// next decodes the next record
+func (r *archiveReader) next() (*domain.ArchiveRecord, error) {
+	var record domain.ArchiveRecord
+	if err := r.decoder.Decode(&record); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%w: archive is truncated", ports.ErrArchiveInvalid)
+		}
+		return nil, fmt.Errorf("%w: %v", ports.ErrArchiveInvalid, err)
+	}
+	return &record, nil
+}
+
+// readTable reads the rows of a table up to its table_end record
+func (r *archiveReader) readTable(table string, handler archiveHandler) (int64, error) {
+	checksum := sha256.New()
+	var count int64
+	batch := make([]json.RawMessage, 0, archiveBatchSize)
+
+	for {
+		record, err := r.next()
+		if err != nil {
+			return 0, err
+		}
+
+		switch record.Type {
+		case domain.ArchiveRecordRow:
+			checksum.Write(record.Data)
+			checksum.Write([]byte{'\n'})
+			count++
+
+			batch = append(batch, record.Data)
+			if len(batch) == archiveBatchSize {
+				if err := handler.rows(table, batch); err != nil {
+					return 0, err
+				}
+				batch = make([]json.RawMessage, 0, archiveBatchSize)
+			}
+
+		case domain.ArchiveRecordTableEnd:
+			if record.Table != table || record.Count != count || record.SHA256 != hex.EncodeToString(checksum.Sum(nil)) {
+				return 0, fmt.Errorf("%w: checksum mismatch in table %s", ports.ErrArchiveInvalid, table)
+			}
+			if len(batch) > 0 {
+				if err := handler.rows(table, batch); err != nil {
+					return 0, err
+				}
+			}
+			return count, nil
+
+		default:
+			return 0, fmt.Errorf("%w: unexpected %q record in table %s", ports.ErrArchiveInvalid, record.Type, table)
+		}
+	}
+}
+
+// readBlob hands over a media file and verifies it up to its blob_end record
+func (r *archiveReader) readBlob(blob *domain.ArchiveRecord, handler archiveHandler) (int64, error) {
+	content := &archiveBlobReader{reader: r, checksum: sha256.New()}
+	if err := handler.blob(blob.Key, blob.ContentType, blob.Size, content); err != nil {
+		return 0, err
+	}
+
+	// Skip the content the handler did not read
+	if _, err := io.Copy(io.Discard, content); err != nil {
+		return 0, err
+	}
+
+	end := content.end
+	if end.Key != blob.Key || end.Count != content.size || content.size != blob.Size ||
+		end.SHA256 != hex.EncodeToString(content.checksum.Sum(nil)) {
+		return 0, fmt.Errorf("%w: checksum mismatch in media file %s", ports.ErrArchiveInvalid, blob.Key)
+	}
+
+	return content.size, nil
+}
+
+// archiveBlobReader reads the content of a media file from its blob_chunk records
+type archiveBlobReader struct {
+	reader   *archiveReader
+	checksum hash.Hash
+	pending  []byte
+	size     int64
+	end      *domain.ArchiveRecord
+}
+
+// Read implements io.Reader
+func (b *archiveBlobReader) Read(p []byte) (int, error) {
+	for len(b.pending) == 0 {
+		if b.end != nil {
+			return 0, io.EOF
+		}
+
+		record, err := b.reader.next()
+		if err != nil {
+			return 0, err
+		}
+
+		switch record.Type {
+		case domain.ArchiveRecordBlobChunk:
+			b.checksum.Write(record.Chunk)
+			b.size += int64(len(record.Chunk))
+			b.pending = record.Chunk
+		case domain.ArchiveRecordBlobEnd:
+			b.end = record
+		default:
+			return 0, fmt.Errorf("%w: unexpected %q record in media file", ports.ErrArchiveInvalid, record.Type)
+		}
+	}
+
+	n := copy(p, b.pending)
+	b.pending = b.pending[n:]
+	return n, nil
+}
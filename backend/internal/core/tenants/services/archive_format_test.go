@@ -0,0 +1,259 @@
+package services
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/domain"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/ports"
+)
+
+// recordingHandler records the content of an archive
+type recordingHandler struct {
+	headerRecord *domain.ArchiveRecord
+	tableRows    map[string][]string
+	blobs        map[string]string
+}
+
+func (h *recordingHandler) header(record *domain.ArchiveRecord) error {
+	h.headerRecord = record
+	return nil
+}
+
+func (h *recordingHandler) rows(table string, rows []json.RawMessage) error {
+	for _, row := range rows {
+		h.tableRows[table] = append(h.tableRows[table], string(row))
+	}
+	return nil
+}
+
+func (h *recordingHandler) blob(key, contentType string, size int64, content io.Reader) error {
+	data, err := io.ReadAll(content)
+	if err != nil {
+		return err
+	}
+	h.blobs[key] = string(data)
+	return nil
+}
+
+func newRecordingHandler() *recordingHandler {
+	return &recordingHandler{tableRows: make(map[string][]string), blobs: make(map[string]string)}
+}
+
+// writeTestArchive writes an archive with two tables and a media file
+func writeTestArchive(t *testing.T, blob string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	archive := newArchiveWriter(&buf)
+	if err := archive.writeHeader(&domain.ArchiveRecord{SchemaVersion: 16, TenantID: "11111111-1111-1111-1111-111111111111", TenantSlug: "acme"}); err != nil {
+		t.Fatalf("Failed to write header: %v", err)
+	}
+
+	tables := map[string][]string{
+		"courses": {`{"id": 1, "title": "Go <basics> & more"}`, `{"id":2,"title":"SQL"}`},
+		"media":   {},
+	}
+	for _, table := range []string{"courses", "media"} {
+		if err := archive.beginTable(table); err != nil {
+			t.Fatalf("Failed to begin table: %v", err)
+		}
+		for _, row := range tables[table] {
+			if err := archive.writeRow(json.RawMessage(row)); err != nil {
+				t.Fatalf("Failed to write row: %v", err)
+			}
+		}
+		if err := archive.endTable(table); err != nil {
+			t.Fatalf("Failed to end table: %v", err)
+		}
+	}
+
+	if err := archive.writeBlob("video.mp4", "video/mp4", int64(len(blob)), strings.NewReader(blob)); err != nil {
+		t.Fatalf("Failed to write blob: %v", err)
+	}
+
+	if _, err := archive.close(); err != nil {
+		t.Fatalf("Failed to close archive: %v", err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestArchiveRoundTrip(t *testing.T) {
+	blob := strings.Repeat("frame", archiveChunkSize/2)
+	archive := writeTestArchive(t, blob)
+
+	handler := newRecordingHandler()
+	summary, err := readArchive(bytes.NewReader(archive), handler)
+	if err != nil {
+		t.Fatalf("Failed to read archive: %v", err)
+	}
+
+	if handler.headerRecord.TenantSlug != "acme" || handler.headerRecord.SchemaVersion != 16 {
+		t.Errorf("Unexpected header: %+v", handler.headerRecord)
+	}
+
+	expectedRows := []string{`{"id":1,"title":"Go <basics> & more"}`, `{"id":2,"title":"SQL"}`}
+	if strings.Join(handler.tableRows["courses"], "\n") != strings.Join(expectedRows, "\n") {
+		t.Errorf("Expected rows %v, got %v", expectedRows, handler.tableRows["courses"])
+	}
+
+	if handler.blobs["video.mp4"] != blob {
+		t.Errorf("Expected media file of %d bytes, got %d bytes", len(blob), len(handler.blobs["video.mp4"]))
+	}
+
+	if summary.Rows != 2 || summary.Tables["media"] != 0 || summary.Blobs != 1 || summary.BlobBytes != int64(len(blob)) {
+		t.Errorf("Unexpected summary: %+v", summary)
+	}
+}
+
+func TestArchiveRejectsTruncatedArchive(t *testing.T) {
+	archive := writeTestArchive(t, "content")
+	raw := decompress(t, archive)
+
+	// Drop the end record
+	lines := strings.SplitAfter(strings.TrimSuffix(raw, "\n"), "\n")
+	truncated := strings.Join(lines[:len(lines)-1], "")
+
+	_, err := readArchive(bytes.NewReader(compress(t, truncated)), newRecordingHandler())
+	if !errors.Is(err, ports.ErrArchiveInvalid) {
+		t.Errorf("Expected ErrArchiveInvalid, got %v", err)
+	}
+}
+
+func TestArchiveRejectsTamperedContent(t *testing.T) {
+	tests := map[string]func(string) string{
+		"row": func(raw string) string {
+			return strings.Replace(raw, `"title":"SQL"`, `"title":"NoSQL"`, 1)
+		},
+		"media file": func(raw string) string {
+			// "content" base64-encoded
+			return strings.Replace(raw, "Y29udGVudA==", "Y29udGVudCE=", 1)
+		},
+	}
+
+	for name, tamper := range tests {
+		t.Run(name, func(t *testing.T) {
+			raw := decompress(t, writeTestArchive(t, "content"))
+			tampered := tamper(raw)
+			if tampered == raw {
+				t.Fatal("Content was not tampered")
+			}
+
+			_, err := readArchive(bytes.NewReader(compress(t, tampered)), newRecordingHandler())
+			if !errors.Is(err, ports.ErrArchiveInvalid) {
+				t.Errorf("Expected ErrArchiveInvalid, got %v", err)
+			}
+		})
+	}
+}
+
+func TestArchiveRejectsOtherFormatVersions(t *testing.T) {
+	raw := decompress(t, writeTestArchive(t, "content"))
+	raw = strings.Replace(raw, `"format_version":1`, `"format_version":2`, 1)
+
+	_, err := readArchive(bytes.NewReader(compress(t, raw)), newRecordingHandler())
+	if !errors.Is(err, ports.ErrArchiveUnsupported) {
+		t.Errorf("Expected ErrArchiveUnsupported, got %v", err)
+	}
+}
+
+func decompress(t *testing.T, archive []byte) string {
+	t.Helper()
+
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		t.Fatalf("Failed to open archive: %v", err)
+	}
+	raw, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatalf("Failed to decompress archive: %v", err)
+	}
+	return string(raw)
+}
+
+func compress(t *testing.T, raw string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write([]byte(raw)); err != nil {
+		t.Fatalf("Failed to compress archive: %v", err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatalf("Failed to compress archive: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestReplaceTenantID(t *testing.T) {
+	const source = "11111111-1111-1111-1111-111111111111"
+	const target = "22222222-2222-2222-2222-222222222222"
+
+	tests := []struct {
+		name     string
+		row      string
+		expected map[string]any
+	}{
+		{
+			name:     "Tenant column",
+			row:      `{"id":1,"tenant_id":"` + source + `"}`,
+			expected: map[string]any{"id": float64(1), "tenant_id": target},
+		},
+		{
+			name:     "Same UUID in other columns",
+			row:      `{"tenant_id":"` + source + `","external_id":"` + source + `","title":"Copy of ` + source + `"}`,
+			expected: map[string]any{"tenant_id": target, "external_id": source, "title": "Copy of " + source},
+		},
+		{
+			name:     "Uppercase tenant ID",
+			row:      `{"tenant_id":"` + strings.ToUpper(source) + `"}`,
+			expected: map[string]any{"tenant_id": target},
+		},
+		{
+			name:     "Other tenant",
+			row:      `{"tenant_id":"33333333-3333-3333-3333-333333333333"}`,
+			expected: map[string]any{"tenant_id": "33333333-3333-3333-3333-333333333333"},
+		},
+		{
+			name:     "Null tenant",
+			row:      `{"tenant_id":null,"note":"` + source + `"}`,
+			expected: map[string]any{"tenant_id": nil, "note": source},
+		},
+		{
+			name:     "No tenant column",
+			row:      `{"id":"` + source + `"}`,
+			expected: map[string]any{"id": source},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			row, err := replaceTenantID(json.RawMessage(tt.row), source, target)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			var got map[string]any
+			if err := json.Unmarshal(row, &got); err != nil {
+				t.Fatalf("Invalid row %s: %v", row, err)
+			}
+			if len(got) != len(tt.expected) {
+				t.Fatalf("Expected %v, got %v", tt.expected, got)
+			}
+			for column, value := range tt.expected {
+				if got[column] != value {
+					t.Errorf("Expected %s=%v, got %v", column, value, got[column])
+				}
+			}
+		})
+	}
+
+	if _, err := replaceTenantID(json.RawMessage(`{"tenant_id":1}`), source, target); err == nil {
+		t.Errorf("Expected an error for a tenant ID that is not a string")
+	}
+}
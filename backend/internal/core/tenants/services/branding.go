@@ -0,0 +1,94 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/domain"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/ports"
+	"github.com/google/uuid"
+)
+
+// GetTenantBranding retrieves the branding of a tenant (admin only)
+func (s *TenantService) GetTenantBranding(ctx context.Context, tenantID, requestingUserID string) (*domain.TenantBranding, error) {
+	if err := s.requireTenantAdmin(ctx, tenantID, requestingUserID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetTenantBranding(ctx, tenantID)
+}
+
+// UpdateTenantBranding replaces the branding of a tenant (admin only). The logo must be a public
+// image of the media library of the tenant.
+func (s *TenantService) UpdateTenantBranding(ctx context.Context, tenantID string, dto *domain.TenantBrandingDTO, requestingUserID string) (*domain.TenantBranding, error) {
+	if err := s.requireTenantAdmin(ctx, tenantID, requestingUserID); err != nil {
+		return nil, err
+	}
+
+	if err := s.validator.Struct(dto); err != nil {
+		return nil, fmt.Errorf("%w: %v", ports.ErrBrandingInvalid, err)
+	}
+
+	branding, err := s.repo.GetTenantBranding(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	branding.LogoMediaID = nil
+	branding.LogoURL = ""
+	if dto.LogoMediaID != nil && *dto.LogoMediaID != "" {
+		logoURL, err := s.repo.GetLogoURL(ctx, tenantID, *dto.LogoMediaID)
+		if err != nil {
+			return nil, err
+		}
+		branding.LogoMediaID = dto.LogoMediaID
+		branding.LogoURL = logoURL
+	}
+
+	branding.FaviconURL = strings.TrimSpace(dto.FaviconURL)
+	branding.Palette = normalizePalette(dto.Palette)
+	branding.CustomCSSURL = strings.TrimSpace(dto.CustomCSSURL)
+	branding.EmailSenderName = strings.TrimSpace(dto.EmailSenderName)
+	branding.EmailHeader = strings.TrimSpace(dto.EmailHeader)
+	branding.EmailFooter = strings.TrimSpace(dto.EmailFooter)
+	branding.Support = domain.SupportContact{
+		Email: strings.TrimSpace(dto.Support.Email),
+		Phone: strings.TrimSpace(dto.Support.Phone),
+		URL:   strings.TrimSpace(dto.Support.URL),
+	}
+
+	if err := s.repo.SaveTenantBranding(ctx, branding); err != nil {
+		return nil, err
+	}
+
+	log.Printf("🎨 [TenantService] Branding of tenant %s updated by user %s", tenantID, requestingUserID)
+	return branding, nil
+}
+
+// GetPublicTenantConfig retrieves what clients need to theme the pages of a tenant. It requires
+// no authentication, so it only returns the public settings of the branding.
+func (s *TenantService) GetPublicTenantConfig(ctx context.Context, tenantID string) (*domain.PublicTenantConfig, error) {
+	if _, err := uuid.Parse(tenantID); err != nil {
+		return nil, ports.ErrTenantNotFound
+	}
+
+	branding, err := s.repo.GetTenantBranding(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	return branding.PublicConfig(), nil
+}
+
+// normalizePalette stores colors in lowercase so that clients can compare them
+func normalizePalette(palette domain.BrandingPalette) domain.BrandingPalette {
+	return domain.BrandingPalette{
+		Primary:    strings.ToLower(palette.Primary),
+		Secondary:  strings.ToLower(palette.Secondary),
+		Accent:     strings.ToLower(palette.Accent),
+		Background: strings.ToLower(palette.Background),
+		Text:       strings.ToLower(palette.Text),
+	}
+}
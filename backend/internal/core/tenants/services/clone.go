@@ -0,0 +1,632 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/domain"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/ports"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/shared/database"
+	"github.com/google/uuid"
+)
+
+// staleCloneJobTimeout is how long an unfinished clone job can go without progress before it
+// is considered interrupted (e.g. by a restart)
+const staleCloneJobTimeout = 10 * time.Minute
+
+// cloneInProgressReason is the status reason of a tenant suspended while it is being cloned into
+var cloneInProgressReason = "clone in progress"
+
+// anonymizedText replaces the text written by learners or about them
+const anonymizedText = "[anonymized]"
+
+// cloneContentTables are the tables with the course content of a tenant, copied by every clone
+var cloneContentTables = map[string]bool{
+	"course_categories":      true,
+	"courses":                true,
+	"course_instructors":     true,
+	"modules":                true,
+	"lessons":                true,
+	"quizzes":                true,
+	"quiz_questions":         true,
+	"quiz_answers":           true,
+	"assignments":            true,
+	"rubrics":                true,
+	"assignment_files":       true,
+	"certificate_templates":  true,
+	"media_folders":          true,
+	"media":                  true,
+	"media_tags":             true,
+	"media_tag_associations": true,
+}
+
+// cloneLearnerTables are the tables with the activity of learners, copied only when learner
+// data is anonymized. Tables in neither list (profiles, notifications, ...) are never copied.
+var cloneLearnerTables = map[string]bool{
+	"enrollments":            true,
+	"enrollment_requests":    true,
+	"course_progress":        true,
+	"module_progress":        true,
+	"lesson_progress":        true,
+	"lesson_completions":     true,
+	"progress_snapshots":     true,
+	"certificates":           true,
+	"quiz_attempts":          true,
+	"student_answers":        true,
+	"assignment_submissions": true,
+	"submission_grades":      true,
+	"submission_comments":    true,
+	"peer_reviews":           true,
+	"reviews":                true,
+	"course_ratings":         true,
+	"review_helpful":         true,
+	"review_reports":         true,
+}
+
+// cloneUserColumns hold the IDs of users in learner tables, replaced with pseudonyms
+var cloneUserColumns = []string{
+	"user_id", "student_id", "grader_id", "author_id", "reviewer_id", "reporter_id",
+	"reviewed_by", "revoked_by", "uploaded_by", "added_by", "created_by",
+}
+
+// cloneKeptColumns are the columns of learner tables copied as they are: references to other
+// rows, states and grades. Columns whose name ends in _at or _date hold timestamps and are kept
+// too. Anonymization fails closed: the text, arrays and objects of any other column are
+// redacted or cleared, so columns added to learner tables are anonymized until listed here.
+var cloneKeptColumns = map[string]bool{
+	"id": true, "tenant_id": true, "course_id": true, "module_id": true, "lesson_id": true,
+	"quiz_id": true, "attempt_id": true, "question_id": true, "selected_option_id": true,
+	"enrollment_id": true, "progress_id": true, "certificate_id": true, "template_id": true,
+	"assignment_id": true, "submission_id": true, "criterion_id": true, "review_id": true,
+	"status": true, "grade_status": true, "milestone_type": true, "author_role": true,
+	"letter_grade": true, "certificate_number": true,
+}
+
+// cloneRedactedColumns hold the text written by learners or about them
+var cloneRedactedColumns = []string{
+	"answer_text", "text_content", "content", "comment", "feedback", "instructor_feedback",
+	"request_message", "rejection_reason", "cancellation_reason", "revocation_reason", "reason",
+	"title",
+}
+
+// cloneClearedColumns hold data of learners that has no anonymous value (uploaded files and
+// links to them, grading and certificate details), replaced with an empty value
+var cloneClearedColumns = map[string]json.RawMessage{
+	"files":          json.RawMessage("[]"),
+	"file_url":       json.RawMessage("null"),
+	"grades":         json.RawMessage("[]"),
+	"scores":         json.RawMessage("{}"),
+	"metadata":       json.RawMessage("{}"),
+	"milestone_data": json.RawMessage("null"),
+}
+
+// cloneCodeColumns hold codes that identify learners outside of the tenant (e.g. certificate
+// verification), replaced with pseudonyms
+var cloneCodeColumns = []string{"verification_code"}
+
+// isClonedAsIs checks if a column of a learner table is copied as it is
+func isClonedAsIs(column string) bool {
+	return cloneKeptColumns[column] || strings.HasSuffix(column, "_at") || strings.HasSuffix(column, "_date")
+}
+
+// StartTenantClone creates a new tenant with a copy of the course content of an active tenant
+// (superadmin only). The copy runs in the background; the returned job reports its progress.
+// Learner data is excluded unless dto.LearnerData asks to anonymize it. The requesting user
+// owns the new tenant and is its only member.
+func (s *TenantService) StartTenantClone(ctx context.Context, sourceTenantID string, dto *domain.CloneTenantDTO, requestedBy string) (*domain.TenantCloneJob, error) {
+	if err := s.validator.Struct(dto); err != nil {
+		return nil, fmt.Errorf("%w: %v", ports.ErrCloneInvalid, err)
+	}
+
+	source, err := s.GetTenantLifecycle(ctx, sourceTenantID)
+	if err != nil {
+		return nil, err
+	}
+	if source.Status != database.TenantStatusActive {
+		return nil, ports.ErrTenantStatusConflict
+	}
+
+	// The new tenant keeps the contact details of the source tenant
+	details, err := s.repo.GetTenantDetails(ctx, source.ID)
+	if err != nil {
+		return nil, err
+	}
+	details.Name = dto.Name
+	details.Slug = dto.Slug
+	if err := s.validateNewTenant(ctx, details); err != nil {
+		return nil, fmt.Errorf("%w: %v", ports.ErrCloneInvalid, err)
+	}
+
+	learnerData := dto.LearnerData
+	if learnerData == "" {
+		learnerData = domain.CloneLearnerDataExclude
+	}
+
+	now := time.Now()
+	job := &domain.TenantCloneJob{
+		ID:             uuid.New().String(),
+		SourceTenantID: source.ID,
+		TargetName:     dto.Name,
+		TargetSlug:     dto.Slug,
+		LearnerData:    learnerData,
+		Status:         domain.CloneJobStatusPending,
+		RequestedBy:    &requestedBy,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if err := s.repo.CreateCloneJob(ctx, job); err != nil {
+		return nil, err
+	}
+
+	log.Printf("🧬 [TenantService] Clone of tenant %s (%s) into %s started by user %s (job %s)",
+		source.Slug, source.ID, dto.Slug, requestedBy, job.ID)
+
+	// The job outlives the request, so it gets its own context
+	jobCopy := *job
+	go s.runCloneJob(context.Background(), &jobCopy, source, details)
+
+	return job, nil
+}
+
+// CloneTenant clones the course content of the current tenant into a new tenant (admin only)
+func (s *TenantService) CloneTenant(ctx context.Context, tenantID string, dto *domain.CloneTenantDTO, requestingUserID string) (*domain.TenantCloneJob, error) {
+	if err := s.requireTenantAdmin(ctx, tenantID, requestingUserID); err != nil {
+		return nil, err
+	}
+
+	return s.StartTenantClone(ctx, tenantID, dto, requestingUserID)
+}
+
+// GetCloneJob retrieves a clone job (superadmin only)
+func (s *TenantService) GetCloneJob(ctx context.Context, jobID string) (*domain.TenantCloneJob, error) {
+	if _, err := uuid.Parse(jobID); err != nil {
+		return nil, ports.ErrCloneJobNotFound
+	}
+
+	return s.repo.GetCloneJob(ctx, jobID)
+}
+
+// ListCloneJobs retrieves the clone jobs of a tenant (superadmin only)
+func (s *TenantService) ListCloneJobs(ctx context.Context, tenantID string) ([]*domain.TenantCloneJob, error) {
+	if _, err := uuid.Parse(tenantID); err != nil {
+		return nil, ports.ErrTenantNotFound
+	}
+
+	return s.repo.ListCloneJobs(ctx, tenantID)
+}
+
+// GetTenantCloneJob retrieves a clone job of the current tenant (admin only)
+func (s *TenantService) GetTenantCloneJob(ctx context.Context, tenantID, jobID, requestingUserID string) (*domain.TenantCloneJob, error) {
+	if err := s.requireTenantAdmin(ctx, tenantID, requestingUserID); err != nil {
+		return nil, err
+	}
+
+	job, err := s.GetCloneJob(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if job.SourceTenantID != tenantID {
+		return nil, ports.ErrCloneJobNotFound
+	}
+
+	return job, nil
+}
+
+// ListTenantCloneJobs retrieves the clone jobs of the current tenant (admin only)
+func (s *TenantService) ListTenantCloneJobs(ctx context.Context, tenantID, requestingUserID string) ([]*domain.TenantCloneJob, error) {
+	if err := s.requireTenantAdmin(ctx, tenantID, requestingUserID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.ListCloneJobs(ctx, tenantID)
+}
+
+// FailStaleCloneJobs fails the clone jobs interrupted before they finished. Their partial
+// tenants stay suspended and are left for a superadmin to delete.
+func (s *TenantService) FailStaleCloneJobs(ctx context.Context) (int64, error) {
+	return s.repo.FailStaleCloneJobs(ctx, time.Now().Add(-staleCloneJobTimeout), "clone job was interrupted")
+}
+
+// runCloneJob creates the tenant of a clone job and copies the source tenant into it. The new
+// tenant stays suspended until the copy completes, so nobody uses it half copied. When the
+// copy fails, the new tenant is removed and the job records the error.
+func (s *TenantService) runCloneJob(ctx context.Context, job *domain.TenantCloneJob, source *domain.TenantLifecycle, details *domain.CreateTenantDTO) {
+	startedAt := time.Now()
+	job.Status = domain.CloneJobStatusRunning
+	job.StartedAt = &startedAt
+	s.setCloneJobStep(ctx, job, "creating tenant")
+
+	dbName := fmt.Sprintf("tenant_%s", details.Slug)
+	tenantID, err := s.repo.CreateTenant(ctx, details.Name, details.Slug, dbName, details.Description, details.Email, details.Phone, details.Address, details.Website, *job.RequestedBy)
+	if err != nil {
+		s.failCloneJob(ctx, job, fmt.Errorf("failed to create tenant: %w", err))
+		return
+	}
+	job.TargetTenantID = &tenantID
+
+	if err := s.repo.UpdateTenantStatus(ctx, tenantID, []string{database.TenantStatusActive}, database.TenantStatusSuspended, &cloneInProgressReason, nil); err != nil {
+		_ = s.repo.DeleteTenant(ctx, tenantID)
+		job.TargetTenantID = nil
+		s.failCloneJob(ctx, job, fmt.Errorf("failed to suspend tenant: %w", err))
+		return
+	}
+
+	s.setCloneJobStep(ctx, job, "creating tenant database")
+	if err := s.migrationRunner.CreateTenantWithMigrations(tenantID, dbName, "migrations/tenants"); err != nil {
+		_ = s.repo.DeleteTenant(ctx, tenantID)
+		job.TargetTenantID = nil
+		s.failCloneJob(ctx, job, err)
+		return
+	}
+
+	copier := &tenantCopier{
+		service:     s,
+		job:         job,
+		transformer: newCloneTransformer(job.LearnerData, source.ID, tenantID),
+	}
+	if err := copier.copyTenant(ctx, source.DatabaseName, dbName); err != nil {
+		copier.discard()
+		_ = s.manager.DropTenantDatabase(tenantID, dbName)
+		_ = s.repo.DeleteTenant(ctx, tenantID)
+		job.TargetTenantID = nil
+		s.failCloneJob(ctx, job, err)
+		return
+	}
+
+	s.setCloneJobStep(ctx, job, "activating tenant")
+	if err := s.repo.UpdateTenantStatus(ctx, tenantID, []string{database.TenantStatusSuspended}, database.TenantStatusActive, nil, nil); err != nil {
+		// The copy is complete; a superadmin can still reactivate the tenant
+		s.failCloneJob(ctx, job, fmt.Errorf("failed to activate tenant: %w", err))
+		return
+	}
+
+	completedAt := time.Now()
+	job.Status = domain.CloneJobStatusCompleted
+	job.CompletedAt = &completedAt
+	job.CurrentStep = nil
+	s.saveCloneJob(ctx, job)
+
+	log.Printf("🧬 [TenantService] Tenant %s (%s) cloned into %s (%s): %d rows, %d media files",
+		source.Slug, source.ID, details.Slug, tenantID, job.RowsCopied, job.FilesCopied)
+}
+
+// setCloneJobStep records what a clone job is doing
+func (s *TenantService) setCloneJobStep(ctx context.Context, job *domain.TenantCloneJob, step string) {
+	job.CurrentStep = &step
+	s.saveCloneJob(ctx, job)
+}
+
+// failCloneJob records the error that stopped a clone job
+func (s *TenantService) failCloneJob(ctx context.Context, job *domain.TenantCloneJob, err error) {
+	log.Printf("❌ [TenantService] Clone job %s of tenant %s failed: %v", job.ID, job.SourceTenantID, err)
+
+	message := err.Error()
+	completedAt := time.Now()
+	job.Status = domain.CloneJobStatusFailed
+	job.Error = &message
+	job.CompletedAt = &completedAt
+	s.saveCloneJob(ctx, job)
+}
+
+// saveCloneJob saves the progress of a clone job. The job goes on when it can't be saved.
+func (s *TenantService) saveCloneJob(ctx context.Context, job *domain.TenantCloneJob) {
+	if err := s.repo.UpdateCloneJob(ctx, job); err != nil {
+		log.Printf("⚠️  [TenantService] Failed to save progress of clone job %s: %v", job.ID, err)
+	}
+}
+
+// tenantCopier copies the rows and media files of a tenant into the tenant of a clone job
+type tenantCopier struct {
+	service     *TenantService
+	job         *domain.TenantCloneJob
+	transformer *cloneTransformer
+	targetID    uuid.UUID
+	uploaded    []string
+}
+
+// copyTenant copies the tables selected by the transformer, read from one snapshot of the source
+// database, in one transaction, then the files of the copied media
+func (c *tenantCopier) copyTenant(ctx context.Context, sourceDatabaseName, targetDatabaseName string) error {
+	c.targetID = uuid.MustParse(*c.job.TargetTenantID)
+
+	source, err := c.service.archiveRepo.OpenTenantData(ctx, sourceDatabaseName)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	target, err := c.service.archiveRepo.OpenTenantData(ctx, targetDatabaseName)
+	if err != nil {
+		return err
+	}
+	defer target.Close()
+
+	sourceVersion, dirty, err := source.SchemaVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if dirty {
+		return fmt.Errorf("source tenant database has a dirty migration at version %d", sourceVersion)
+	}
+	targetVersion, _, err := target.SchemaVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if sourceVersion != targetVersion {
+		return fmt.Errorf("source tenant database is at schema version %d, new tenant database at %d",
+			sourceVersion, targetVersion)
+	}
+
+	allTables, err := source.ListTables(ctx)
+	if err != nil {
+		return err
+	}
+	tables := make([]string, 0, len(allTables))
+	for _, table := range allTables {
+		if c.transformer.copiesTable(table) {
+			tables = append(tables, table)
+		}
+	}
+	c.job.TablesTotal = len(tables)
+
+	export, err := source.BeginExport(ctx)
+	if err != nil {
+		return err
+	}
+	defer export.Close()
+
+	tx, err := target.BeginImport(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var mediaFiles []archivedMedia
+	for _, table := range tables {
+		c.service.setCloneJobStep(ctx, c.job, "copying "+table)
+
+		batch := make([]json.RawMessage, 0, archiveBatchSize)
+		flush := func() error {
+			if len(batch) == 0 {
+				return nil
+			}
+			if err := tx.InsertRows(ctx, table, batch); err != nil {
+				return err
+			}
+			c.job.RowsCopied += int64(len(batch))
+			c.service.saveCloneJob(ctx, c.job)
+			batch = make([]json.RawMessage, 0, archiveBatchSize)
+			return nil
+		}
+
+		err := export.ExportRows(ctx, table, func(row json.RawMessage) error {
+			row, ok, err := c.transformer.transform(table, row)
+			if err != nil || !ok {
+				return err
+			}
+
+			if table == mediaTable {
+				var media archivedMedia
+				if err := json.Unmarshal(row, &media); err != nil {
+					return fmt.Errorf("failed to decode media row: %w", err)
+				}
+				// Files of deleted media may be gone from storage
+				if media.DeletedAt == nil {
+					mediaFiles = append(mediaFiles, media)
+				}
+			}
+
+			batch = append(batch, row)
+			if len(batch) >= archiveBatchSize {
+				return flush()
+			}
+			return nil
+		})
+		if err == nil {
+			err = flush()
+		}
+		if err != nil {
+			return fmt.Errorf("failed to copy table %s: %w", table, err)
+		}
+
+		c.job.TablesDone++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit copied rows: %w", err)
+	}
+
+	if len(mediaFiles) == 0 {
+		return nil
+	}
+	if c.service.blobStorage == nil {
+		return ports.ErrArchiveStorageUnavailable
+	}
+
+	c.service.setCloneJobStep(ctx, c.job, "copying media files")
+	sourceID := uuid.MustParse(c.job.SourceTenantID)
+	for _, media := range mediaFiles {
+		if err := c.copyMediaFile(sourceID, media); err != nil {
+			return err
+		}
+		c.job.FilesCopied++
+		c.service.saveCloneJob(ctx, c.job)
+	}
+
+	return nil
+}
+
+// copyMediaFile copies a media file to the storage of the new tenant
+func (c *tenantCopier) copyMediaFile(sourceID uuid.UUID, media archivedMedia) error {
+	content, err := c.service.blobStorage.Download(sourceID, media.FileName)
+	if err != nil {
+		return fmt.Errorf("failed to download media file %s: %w", media.FileName, err)
+	}
+	defer content.Close()
+
+	if _, err := c.service.blobStorage.Upload(c.targetID, media.FileName, content, media.MimeType, media.FileSize); err != nil {
+		return fmt.Errorf("failed to upload media file %s: %w", media.FileName, err)
+	}
+	c.uploaded = append(c.uploaded, media.FileName)
+
+	return nil
+}
+
+// discard deletes the media files copied before the clone failed
+func (c *tenantCopier) discard() {
+	if len(c.uploaded) == 0 {
+		return
+	}
+	if err := c.service.blobStorage.DeleteMultiple(c.targetID, c.uploaded); err != nil {
+		log.Printf("⚠️  [TenantService] Failed to delete media files of failed clone job %s: %v", c.job.ID, err)
+	}
+}
+
+// cloneTransformer selects the tables and rows copied by a clone and rewrites them for the
+// new tenant
+type cloneTransformer struct {
+	learnerData    string
+	sourceTenantID string
+	targetTenantID string
+	// Namespace of the pseudonyms of users, random so that they can't be traced back
+	namespace uuid.UUID
+}
+
+// newCloneTransformer creates a transformer for a clone from sourceTenantID to targetTenantID
+func newCloneTransformer(learnerData, sourceTenantID, targetTenantID string) *cloneTransformer {
+	return &cloneTransformer{
+		learnerData:    learnerData,
+		sourceTenantID: sourceTenantID,
+		targetTenantID: targetTenantID,
+		namespace:      uuid.New(),
+	}
+}
+
+// containsColumn checks if a list of columns contains column
+func containsColumn(columns []string, column string) bool {
+	for _, c := range columns {
+		if c == column {
+			return true
+		}
+	}
+	return false
+}
+
+// copiesTable checks if the rows of a table are copied
+func (t *cloneTransformer) copiesTable(table string) bool {
+	if cloneContentTables[table] {
+		return true
+	}
+	return t.learnerData == domain.CloneLearnerDataAnonymize && cloneLearnerTables[table]
+}
+
+// transform rewrites a row of a copied table for the new tenant. It returns false when the
+// row holds learner data that is not copied.
+func (t *cloneTransformer) transform(table string, row json.RawMessage) (json.RawMessage, bool, error) {
+	switch {
+	case table == "assignment_files" || table == mediaTable:
+		var columns struct {
+			SubmissionID *string `json:"submission_id"`
+			Context      string  `json:"context"`
+		}
+		if err := json.Unmarshal(row, &columns); err != nil {
+			return nil, false, fmt.Errorf("failed to decode %s row: %w", table, err)
+		}
+		// Files uploaded by learners with their submissions or profiles stay behind
+		if columns.SubmissionID != nil || columns.Context == "profile" {
+			return nil, false, nil
+		}
+	case cloneLearnerTables[table]:
+		anonymized, err := t.anonymize(row)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to anonymize %s row: %w", table, err)
+		}
+		row = anonymized
+	}
+
+	row, err := replaceTenantID(row, t.sourceTenantID, t.targetTenantID)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to rewrite %s row: %w", table, err)
+	}
+
+	return row, true, nil
+}
+
+// anonymize replaces the users of a learner row with pseudonyms and removes what they wrote.
+// The same user gets the same pseudonym in every row, so that the copied rows stay consistent.
+// Columns that are not known to be safe to copy are redacted or cleared.
+func (t *cloneTransformer) anonymize(row json.RawMessage) (json.RawMessage, error) {
+	var columns map[string]json.RawMessage
+	if err := json.Unmarshal(row, &columns); err != nil {
+		return nil, err
+	}
+
+	for _, column := range cloneUserColumns {
+		value, ok := columns[column]
+		if !ok {
+			continue
+		}
+		var userID *uuid.UUID
+		if err := json.Unmarshal(value, &userID); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", column, err)
+		}
+		if userID == nil {
+			continue
+		}
+		pseudonym, err := json.Marshal(uuid.NewSHA1(t.namespace, userID[:]))
+		if err != nil {
+			return nil, err
+		}
+		columns[column] = pseudonym
+	}
+
+	redacted, err := json.Marshal(anonymizedText)
+	if err != nil {
+		return nil, err
+	}
+	for _, column := range cloneRedactedColumns {
+		// Empty text and nulls are kept, there is nothing to hide
+		if value, ok := columns[column]; ok && string(value) != "null" && string(value) != `""` {
+			columns[column] = redacted
+		}
+	}
+
+	for column, value := range cloneClearedColumns {
+		if _, ok := columns[column]; ok {
+			columns[column] = value
+		}
+	}
+
+	for _, column := range cloneCodeColumns {
+		var code *string
+		if value, ok := columns[column]; !ok || json.Unmarshal(value, &code) != nil || code == nil {
+			continue
+		}
+		pseudonym, err := json.Marshal(uuid.NewSHA1(t.namespace, []byte(*code)).String())
+		if err != nil {
+			return nil, err
+		}
+		columns[column] = pseudonym
+	}
+
+	for column, value := range columns {
+		if isClonedAsIs(column) || containsColumn(cloneUserColumns, column) || containsColumn(cloneRedactedColumns, column) ||
+			containsColumn(cloneCodeColumns, column) || cloneClearedColumns[column] != nil {
+			continue
+		}
+		switch {
+		case len(value) > 0 && value[0] == '"' && string(value) != `""`:
+			columns[column] = redacted
+		case len(value) > 0 && value[0] == '[':
+			columns[column] = json.RawMessage("[]")
+		case len(value) > 0 && value[0] == '{':
+			columns[column] = json.RawMessage("{}")
+		}
+	}
+
+	return json.Marshal(columns)
+}
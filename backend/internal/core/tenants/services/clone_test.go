@@ -0,0 +1,315 @@
+package services
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/domain"
+)
+
+const (
+	cloneSourceTenantID = "11111111-1111-1111-1111-111111111111"
+	cloneTargetTenantID = "22222222-2222-2222-2222-222222222222"
+)
+
+func TestCloneTransformerCopiesTable(t *testing.T) {
+	tests := []struct {
+		table       string
+		exclude     bool
+		anonymize   bool
+		description string
+	}{
+		{table: "courses", exclude: true, anonymize: true, description: "course content"},
+		{table: "certificate_templates", exclude: true, anonymize: true, description: "course content"},
+		{table: "enrollments", exclude: false, anonymize: true, description: "learner data"},
+		{table: "student_answers", exclude: false, anonymize: true, description: "learner data"},
+		{table: "notifications", exclude: false, anonymize: false, description: "never copied"},
+		{table: "profiles", exclude: false, anonymize: false, description: "never copied"},
+		{table: "new_table", exclude: false, anonymize: false, description: "unknown tables are not copied"},
+	}
+
+	exclude := newCloneTransformer(domain.CloneLearnerDataExclude, cloneSourceTenantID, cloneTargetTenantID)
+	anonymize := newCloneTransformer(domain.CloneLearnerDataAnonymize, cloneSourceTenantID, cloneTargetTenantID)
+
+	for _, tt := range tests {
+		t.Run(tt.table, func(t *testing.T) {
+			if got := exclude.copiesTable(tt.table); got != tt.exclude {
+				t.Errorf("%s: expected copied=%v when learner data is excluded, got %v", tt.description, tt.exclude, got)
+			}
+			if got := anonymize.copiesTable(tt.table); got != tt.anonymize {
+				t.Errorf("%s: expected copied=%v when learner data is anonymized, got %v", tt.description, tt.anonymize, got)
+			}
+		})
+	}
+}
+
+func TestCloneTransformerReplacesTenantID(t *testing.T) {
+	transformer := newCloneTransformer(domain.CloneLearnerDataExclude, cloneSourceTenantID, cloneTargetTenantID)
+
+	row, ok, err := transformer.transform("courses", json.RawMessage(`{"id":"c1","tenant_id":"`+cloneSourceTenantID+`","title":"Go"}`))
+	if err != nil || !ok {
+		t.Fatalf("Expected row to be copied, got ok=%v err=%v", ok, err)
+	}
+	if strings.Contains(string(row), cloneSourceTenantID) || !strings.Contains(string(row), cloneTargetTenantID) {
+		t.Errorf("Expected tenant ID to be replaced, got %s", row)
+	}
+
+	// Only the tenant column is rewritten
+	row, _, err = transformer.transform("courses", json.RawMessage(`{"id":"c2","tenant_id":"`+cloneSourceTenantID+`","description":"Imported from `+cloneSourceTenantID+`"}`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var columns map[string]string
+	if err := json.Unmarshal(row, &columns); err != nil {
+		t.Fatalf("Invalid row %s: %v", row, err)
+	}
+	if columns["tenant_id"] != cloneTargetTenantID || columns["description"] != "Imported from "+cloneSourceTenantID {
+		t.Errorf("Expected only tenant_id to be replaced, got %s", row)
+	}
+}
+
+func TestCloneTransformerSkipsLearnerFiles(t *testing.T) {
+	transformer := newCloneTransformer(domain.CloneLearnerDataAnonymize, cloneSourceTenantID, cloneTargetTenantID)
+
+	tests := []struct {
+		name   string
+		table  string
+		row    string
+		copied bool
+	}{
+		{name: "Assignment attachment", table: "assignment_files", row: `{"id":"f1","submission_id":null}`, copied: true},
+		{name: "Submission file", table: "assignment_files", row: `{"id":"f2","submission_id":"s1"}`, copied: false},
+		{name: "Lesson media", table: "media", row: `{"id":"m1","context":"lesson"}`, copied: true},
+		{name: "Profile picture", table: "media", row: `{"id":"m2","context":"profile"}`, copied: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, ok, err := transformer.transform(tt.table, json.RawMessage(tt.row))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if ok != tt.copied {
+				t.Errorf("Expected copied=%v, got %v", tt.copied, ok)
+			}
+		})
+	}
+}
+
+func TestCloneTransformerAnonymizesLearnerData(t *testing.T) {
+	transformer := newCloneTransformer(domain.CloneLearnerDataAnonymize, cloneSourceTenantID, cloneTargetTenantID)
+	userID := "33333333-3333-3333-3333-333333333333"
+
+	decode := func(row json.RawMessage) map[string]interface{} {
+		t.Helper()
+		var columns map[string]interface{}
+		if err := json.Unmarshal(row, &columns); err != nil {
+			t.Fatalf("Failed to decode row: %v", err)
+		}
+		return columns
+	}
+
+	submission, ok, err := transformer.transform("assignment_submissions", json.RawMessage(`{
+		"id": "s1",
+		"tenant_id": "`+cloneSourceTenantID+`",
+		"student_id": "`+userID+`",
+		"grader_id": null,
+		"text_content": "My essay",
+		"instructor_feedback": "",
+		"files": ["essay.pdf"],
+		"score": 8.5
+	}`))
+	if err != nil || !ok {
+		t.Fatalf("Expected row to be copied, got ok=%v err=%v", ok, err)
+	}
+
+	columns := decode(submission)
+	if columns["student_id"] == userID || columns["student_id"] == nil {
+		t.Errorf("Expected student_id to be replaced with a pseudonym, got %v", columns["student_id"])
+	}
+	if columns["grader_id"] != nil {
+		t.Errorf("Expected null grader_id to be kept, got %v", columns["grader_id"])
+	}
+	if columns["text_content"] != anonymizedText {
+		t.Errorf("Expected text_content to be redacted, got %v", columns["text_content"])
+	}
+	if columns["instructor_feedback"] != "" {
+		t.Errorf("Expected empty instructor_feedback to be kept, got %v", columns["instructor_feedback"])
+	}
+	if files, ok := columns["files"].([]interface{}); !ok || len(files) != 0 {
+		t.Errorf("Expected files to be cleared, got %v", columns["files"])
+	}
+	if columns["score"] != 8.5 {
+		t.Errorf("Expected score to be kept, got %v", columns["score"])
+	}
+	if columns["tenant_id"] != cloneTargetTenantID {
+		t.Errorf("Expected tenant_id %s, got %v", cloneTargetTenantID, columns["tenant_id"])
+	}
+
+	// The same user gets the same pseudonym in every table
+	enrollment, _, err := transformer.transform("enrollments", json.RawMessage(`{"id":"e1","user_id":"`+userID+`"}`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if pseudonym := decode(enrollment)["user_id"]; pseudonym != columns["student_id"] {
+		t.Errorf("Expected pseudonym %v, got %v", columns["student_id"], pseudonym)
+	}
+
+	// Pseudonyms differ between clones
+	other := newCloneTransformer(domain.CloneLearnerDataAnonymize, cloneSourceTenantID, cloneTargetTenantID)
+	otherEnrollment, _, err := other.transform("enrollments", json.RawMessage(`{"id":"e1","user_id":"`+userID+`"}`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if decode(otherEnrollment)["user_id"] == columns["student_id"] {
+		t.Error("Expected pseudonyms of different clones to differ")
+	}
+}
+
+func TestCloneTransformerRedactsUnknownColumns(t *testing.T) {
+	transformer := newCloneTransformer(domain.CloneLearnerDataAnonymize, cloneSourceTenantID, cloneTargetTenantID)
+
+	row, _, err := transformer.transform("reviews", json.RawMessage(`{
+		"id": "r1",
+		"tenant_id": "`+cloneSourceTenantID+`",
+		"title": "Great course, says Ana",
+		"new_notes": "Written by a learner",
+		"new_links": ["https://example.com/ana.pdf"],
+		"new_details": {"phone": "555-0100"},
+		"rating": 5,
+		"created_at": "2025-01-02T03:04:05Z"
+	}`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var columns map[string]interface{}
+	if err := json.Unmarshal(row, &columns); err != nil {
+		t.Fatal(err)
+	}
+	for _, column := range []string{"title", "new_notes"} {
+		if columns[column] != anonymizedText {
+			t.Errorf("Expected %s to be redacted, got %v", column, columns[column])
+		}
+	}
+	if links, ok := columns["new_links"].([]interface{}); !ok || len(links) != 0 {
+		t.Errorf("Expected new_links to be cleared, got %v", columns["new_links"])
+	}
+	if details, ok := columns["new_details"].(map[string]interface{}); !ok || len(details) != 0 {
+		t.Errorf("Expected new_details to be cleared, got %v", columns["new_details"])
+	}
+	if columns["rating"] != 5.0 || columns["created_at"] != "2025-01-02T03:04:05Z" || columns["id"] != "r1" {
+		t.Errorf("Expected ratings, timestamps and IDs to be kept, got %v", columns)
+	}
+}
+
+// tenantSchemaColumns reads the columns of the tenant tables and their types from the tenant
+// migrations
+func tenantSchemaColumns(t *testing.T) map[string]map[string]string {
+	t.Helper()
+	paths, err := filepath.Glob(filepath.Join("..", "..", "..", "..", "migrations", "tenants", "*.up.sql"))
+	if err != nil || len(paths) == 0 {
+		t.Fatalf("Failed to find the tenant migrations: %v", err)
+	}
+	sort.Strings(paths)
+
+	var (
+		comment     = regexp.MustCompile(`--[^\n]*`)
+		createTable = regexp.MustCompile(`(?i)CREATE TABLE (?:IF NOT EXISTS )?(\w+)\s*\(`)
+		alterTable  = regexp.MustCompile(`(?i)ALTER TABLE (?:IF EXISTS )?(\w+)([^;]*);`)
+		addColumn   = regexp.MustCompile(`(?i)ADD COLUMN (?:IF NOT EXISTS )?(\w+)\s+(\w+)`)
+		dropColumn  = regexp.MustCompile(`(?i)DROP COLUMN (?:IF EXISTS )?(\w+)`)
+	)
+	constraints := map[string]bool{"CONSTRAINT": true, "PRIMARY": true, "UNIQUE": true, "FOREIGN": true, "CHECK": true, "EXCLUDE": true}
+
+	tables := make(map[string]map[string]string)
+	table := func(name string) map[string]string {
+		if tables[name] == nil {
+			tables[name] = make(map[string]string)
+		}
+		return tables[name]
+	}
+
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sql := comment.ReplaceAllString(string(content), "")
+
+		for _, match := range createTable.FindAllStringSubmatchIndex(sql, -1) {
+			// The column definitions end at the parenthesis that closes the one of the match
+			depth, start, end := 1, match[1], match[1]
+			for ; depth > 0 && end < len(sql); end++ {
+				switch sql[end] {
+				case '(':
+					depth++
+				case ')':
+					depth--
+				}
+			}
+			columns := table(sql[match[2]:match[3]])
+			depth, definition := 0, ""
+			for _, c := range sql[start:end-1] + "," {
+				switch {
+				case c == '(':
+					depth++
+				case c == ')':
+					depth--
+				case c == ',' && depth == 0:
+					if fields := strings.Fields(definition); len(fields) > 1 && !constraints[strings.ToUpper(fields[0])] {
+						columns[fields[0]] = strings.ToUpper(fields[1])
+					}
+					definition = ""
+					continue
+				}
+				definition += string(c)
+			}
+		}
+
+		for _, match := range alterTable.FindAllStringSubmatch(sql, -1) {
+			columns := table(match[1])
+			for _, column := range addColumn.FindAllStringSubmatch(match[2], -1) {
+				columns[column[1]] = strings.ToUpper(column[2])
+			}
+			for _, column := range dropColumn.FindAllStringSubmatch(match[2], -1) {
+				delete(columns, column[1])
+			}
+		}
+	}
+
+	return tables
+}
+
+func TestCloneAnonymizationCoversLearnerColumns(t *testing.T) {
+	schema := tenantSchemaColumns(t)
+
+	// Numbers and booleans hold no text and are copied as they are
+	numeric := regexp.MustCompile(`^(SMALLINT|INTEGER|INT|BIGINT|DECIMAL|NUMERIC|REAL|DOUBLE|BOOLEAN|SERIAL|BIGSERIAL)\b`)
+	for table := range cloneLearnerTables {
+		columns, ok := schema[table]
+		if !ok {
+			t.Errorf("Learner table %s is not in the tenant schema", table)
+			continue
+		}
+		for column, columnType := range columns {
+			if numeric.MatchString(columnType) {
+				continue
+			}
+			kept := isClonedAsIs(column) || containsColumn(cloneUserColumns, column)
+			anonymized := containsColumn(cloneRedactedColumns, column) || containsColumn(cloneCodeColumns, column) || cloneClearedColumns[column] != nil
+			if !kept && !anonymized {
+				t.Errorf("Column %s.%s (%s) is not listed: it would be anonymized by default", table, column, columnType)
+			}
+			// Replacing references and timestamps with text would fail the copy
+			if anonymized && (columnType == "UUID" || strings.HasPrefix(columnType, "TIMESTAMP") || columnType == "DATE") {
+				t.Errorf("Column %s.%s (%s) can't be redacted", table, column, columnType)
+			}
+		}
+	}
+}
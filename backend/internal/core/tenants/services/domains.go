@@ -0,0 +1,179 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/domain"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/ports"
+	"github.com/google/uuid"
+)
+
+// ListTenantDomains retrieves the custom domains of a tenant (admin only)
+func (s *TenantService) ListTenantDomains(ctx context.Context, tenantID, requestingUserID string) ([]*domain.TenantDomainResponse, error) {
+	if err := s.requireTenantAdmin(ctx, tenantID, requestingUserID); err != nil {
+		return nil, err
+	}
+
+	domains, err := s.repo.ListTenantDomains(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*domain.TenantDomainResponse, len(domains))
+	for i, tenantDomain := range domains {
+		responses[i] = domain.NewTenantDomainResponse(tenantDomain)
+	}
+
+	return responses, nil
+}
+
+// AddTenantDomain registers a custom domain for a tenant (admin only). The domain identifies
+// the tenant once the TXT record of the response is published and verified.
+func (s *TenantService) AddTenantDomain(ctx context.Context, dto *domain.AddTenantDomainDTO, tenantID, requestingUserID string) (*domain.TenantDomainResponse, error) {
+	if err := s.requireTenantAdmin(ctx, tenantID, requestingUserID); err != nil {
+		return nil, err
+	}
+
+	name := normalizeDomain(dto.Domain)
+	dto.Domain = name
+	if err := s.validator.Struct(dto); err != nil {
+		return nil, ports.ErrTenantDomainInvalid
+	}
+	if strings.HasPrefix(name, domain.DomainVerificationPrefix+".") || net.ParseIP(name) != nil {
+		return nil, ports.ErrTenantDomainInvalid
+	}
+
+	token, err := generateVerificationToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate verification token: %w", err)
+	}
+
+	now := time.Now()
+	tenantDomain := &domain.TenantDomain{
+		ID:                uuid.New().String(),
+		TenantID:          tenantID,
+		Domain:            name,
+		VerificationToken: token,
+		CreatedBy:         &requestingUserID,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+
+	if err := s.repo.CreateTenantDomain(ctx, tenantDomain); err != nil {
+		return nil, err
+	}
+
+	log.Printf("🌐 [TenantService] Domain %s added to tenant %s, pending verification", name, tenantID)
+
+	return domain.NewTenantDomainResponse(tenantDomain), nil
+}
+
+// VerifyTenantDomain checks the TXT record of a custom domain and marks it as verified (admin only)
+func (s *TenantService) VerifyTenantDomain(ctx context.Context, tenantID, domainID, requestingUserID string) (*domain.TenantDomainResponse, error) {
+	if err := s.requireTenantAdmin(ctx, tenantID, requestingUserID); err != nil {
+		return nil, err
+	}
+
+	tenantDomain, err := s.getTenantDomain(ctx, tenantID, domainID)
+	if err != nil {
+		return nil, err
+	}
+	if tenantDomain.IsVerified() {
+		return domain.NewTenantDomainResponse(tenantDomain), nil
+	}
+
+	records, err := s.resolver.LookupTXT(ctx, tenantDomain.VerificationRecordName())
+	if err != nil {
+		log.Printf("⚠️  [TenantService] TXT lookup failed for %s: %v", tenantDomain.VerificationRecordName(), err)
+		return nil, ports.ErrTenantDomainNotVerified
+	}
+	if !containsRecord(records, tenantDomain.VerificationRecordValue()) {
+		return nil, ports.ErrTenantDomainNotVerified
+	}
+
+	if err := s.repo.MarkTenantDomainVerified(ctx, tenantID, domainID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	tenantDomain.VerifiedAt = &now
+
+	log.Printf("✅ [TenantService] Domain %s verified for tenant %s", tenantDomain.Domain, tenantID)
+
+	return domain.NewTenantDomainResponse(tenantDomain), nil
+}
+
+// DeleteTenantDomain removes a custom domain of a tenant (admin only). It returns the removed
+// domain so that callers can drop it from the host resolution cache.
+func (s *TenantService) DeleteTenantDomain(ctx context.Context, tenantID, domainID, requestingUserID string) (*domain.TenantDomainResponse, error) {
+	if err := s.requireTenantAdmin(ctx, tenantID, requestingUserID); err != nil {
+		return nil, err
+	}
+
+	tenantDomain, err := s.getTenantDomain(ctx, tenantID, domainID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.DeleteTenantDomain(ctx, tenantID, domainID); err != nil {
+		return nil, err
+	}
+
+	log.Printf("🗑️  [TenantService] Domain %s removed from tenant %s", tenantDomain.Domain, tenantID)
+
+	return domain.NewTenantDomainResponse(tenantDomain), nil
+}
+
+// normalizeDomain lowercases a host name and strips the port and the trailing dot
+func normalizeDomain(host string) string {
+	host = strings.ToLower(strings.TrimSpace(host))
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.TrimSuffix(host, ".")
+}
+
+// getTenantDomain retrieves a custom domain of the tenant, rejecting malformed IDs
+func (s *TenantService) getTenantDomain(ctx context.Context, tenantID, domainID string) (*domain.TenantDomain, error) {
+	if _, err := uuid.Parse(domainID); err != nil {
+		return nil, ports.ErrTenantDomainNotFound
+	}
+
+	return s.repo.GetTenantDomain(ctx, tenantID, domainID)
+}
+
+// requireTenantAdmin verifies that the requesting user is admin in the tenant
+func (s *TenantService) requireTenantAdmin(ctx context.Context, tenantID, requestingUserID string) error {
+	membership, err := s.repo.GetMembership(ctx, requestingUserID, tenantID)
+	if err != nil || membership.Role != "admin" || membership.Status != "active" {
+		return ports.ErrTenantAdminRequired
+	}
+
+	return nil
+}
+
+// generateVerificationToken generates the random value of a verification TXT record
+func generateVerificationToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// containsRecord checks if a TXT record with the expected value is published
+func containsRecord(records []string, expected string) bool {
+	for _, record := range records {
+		if strings.TrimSpace(record) == expected {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,162 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/domain"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/ports"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/shared/database"
+	"github.com/google/uuid"
+)
+
+// ListTenantLifecycles retrieves the lifecycle state of all tenants, or of those with a status (superadmin only)
+func (s *TenantService) ListTenantLifecycles(ctx context.Context, status string) ([]*domain.TenantLifecycle, error) {
+	switch status {
+	case "", database.TenantStatusActive, database.TenantStatusInactive, database.TenantStatusSuspended,
+		database.TenantStatusPendingDeletion, database.TenantStatusDeleted:
+	default:
+		return nil, ports.ErrTenantStatusInvalid
+	}
+
+	return s.repo.ListTenantLifecycles(ctx, status)
+}
+
+// GetTenantLifecycle retrieves the lifecycle state of a tenant (superadmin only)
+func (s *TenantService) GetTenantLifecycle(ctx context.Context, tenantID string) (*domain.TenantLifecycle, error) {
+	if _, err := uuid.Parse(tenantID); err != nil {
+		return nil, ports.ErrTenantNotFound
+	}
+
+	return s.repo.GetTenantLifecycle(ctx, tenantID)
+}
+
+// SuspendTenant blocks all access to an active tenant until it is reactivated (superadmin only)
+func (s *TenantService) SuspendTenant(ctx context.Context, tenantID string, dto *domain.ChangeTenantStatusDTO) (*domain.TenantLifecycle, error) {
+	return s.changeTenantStatus(ctx, tenantID, dto,
+		[]string{database.TenantStatusActive}, database.TenantStatusSuspended, nil)
+}
+
+// ScheduleTenantDeletion blocks all access to a tenant and drops its database once the
+// deletion grace period ends. Until then the tenant can be reactivated (superadmin only).
+func (s *TenantService) ScheduleTenantDeletion(ctx context.Context, tenantID string, dto *domain.ChangeTenantStatusDTO) (*domain.TenantLifecycle, error) {
+	deletionScheduledAt := time.Now().Add(s.deletionGracePeriod)
+	return s.changeTenantStatus(ctx, tenantID, dto,
+		[]string{database.TenantStatusActive, database.TenantStatusSuspended}, database.TenantStatusPendingDeletion, &deletionScheduledAt)
+}
+
+// ReactivateTenant restores access to a suspended tenant, or cancels the scheduled deletion
+// of a tenant (superadmin only)
+func (s *TenantService) ReactivateTenant(ctx context.Context, tenantID string) (*domain.TenantLifecycle, error) {
+	return s.changeTenantStatus(ctx, tenantID, &domain.ChangeTenantStatusDTO{},
+		[]string{database.TenantStatusSuspended, database.TenantStatusPendingDeletion}, database.TenantStatusActive, nil)
+}
+
+// changeTenantStatus moves a tenant from one of fromStatuses to status
+func (s *TenantService) changeTenantStatus(ctx context.Context, tenantID string, dto *domain.ChangeTenantStatusDTO, fromStatuses []string, status string, deletionScheduledAt *time.Time) (*domain.TenantLifecycle, error) {
+	if err := s.validator.Struct(dto); err != nil {
+		return nil, fmt.Errorf("validation error: %w", err)
+	}
+
+	// Check that the tenant exists, so that a conflict means a status mismatch
+	tenant, err := s.GetTenantLifecycle(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var reason *string
+	if trimmed := strings.TrimSpace(dto.Reason); trimmed != "" {
+		reason = &trimmed
+	}
+
+	if err := s.repo.UpdateTenantStatus(ctx, tenantID, fromStatuses, status, reason, deletionScheduledAt); err != nil {
+		return nil, err
+	}
+
+	// Pooled connections of unavailable tenants are closed; new ones are refused until it is reactivated
+	if status != database.TenantStatusActive {
+		s.manager.CloseTenantConnection(tenantID)
+	}
+
+	log.Printf("🏢 [TenantService] Tenant %s (%s) changed from %s to %s", tenant.Slug, tenantID, tenant.Status, status)
+
+	return s.repo.GetTenantLifecycle(ctx, tenantID)
+}
+
+// PurgeDueTenants drops the database of the tenants whose deletion grace period ended and
+// returns how many tenants were purged. Tenants that fail are retried on the next run.
+func (s *TenantService) PurgeDueTenants(ctx context.Context) (int, error) {
+	now := time.Now()
+	tenants, err := s.repo.ListTenantsDueForDeletion(ctx, now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list tenants due for deletion: %w", err)
+	}
+
+	purged := 0
+	for _, tenant := range tenants {
+		// Mark the tenant deleted first, so that it can't be reactivated while its database is dropped
+		if tenant.Status == database.TenantStatusPendingDeletion {
+			if err := s.repo.MarkTenantDeleted(ctx, tenant.ID, now); err != nil {
+				if !errors.Is(err, ports.ErrTenantStatusConflict) {
+					log.Printf("❌ [TenantService] Failed to mark tenant %s (%s) deleted: %v", tenant.Slug, tenant.ID, err)
+				}
+				continue
+			}
+		}
+
+		if err := s.manager.DropTenantDatabase(tenant.ID, tenant.DatabaseName); err != nil {
+			log.Printf("❌ [TenantService] Failed to drop database of deleted tenant %s (%s): %v", tenant.Slug, tenant.ID, err)
+			continue
+		}
+
+		if err := s.repo.MarkTenantDatabaseDropped(ctx, tenant.ID); err != nil {
+			log.Printf("❌ [TenantService] Failed to record deletion of tenant %s (%s): %v", tenant.Slug, tenant.ID, err)
+			continue
+		}
+
+		log.Printf("🗑️  [TenantService] Tenant %s (%s) deleted", tenant.Slug, tenant.ID)
+		purged++
+	}
+
+	return purged, nil
+}
+
+// RunTenantMaintenance calls PurgeDueTenants, FailStaleCloneJobs, FailStaleMemberImportJobs and
+// CheckSchemaDrift every interval until the context is cancelled. The schema drift is also
+// checked on start, so that the health check reports it right away.
+func (s *TenantService) RunTenantMaintenance(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	if _, err := s.CheckSchemaDrift(); err != nil {
+		log.Printf("❌ [TenantService] %v", err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.PurgeDueTenants(ctx); err != nil {
+				log.Printf("❌ [TenantService] Tenant purge failed: %v", err)
+			}
+			if failed, err := s.FailStaleCloneJobs(ctx); err != nil {
+				log.Printf("❌ [TenantService] Failed to check stale clone jobs: %v", err)
+			} else if failed > 0 {
+				log.Printf("⚠️  [TenantService] %d interrupted clone jobs marked as failed", failed)
+			}
+			if failed, err := s.FailStaleMemberImportJobs(ctx); err != nil {
+				log.Printf("❌ [TenantService] Failed to check stale member import jobs: %v", err)
+			} else if failed > 0 {
+				log.Printf("⚠️  [TenantService] %d interrupted member import jobs marked as failed", failed)
+			}
+			if _, err := s.CheckSchemaDrift(); err != nil {
+				log.Printf("❌ [TenantService] %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,415 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/domain"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/ports"
+	userdomain "github.com/DanielIturra1610/stegmaier-landing/internal/core/user/domain"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/shared/email"
+	"github.com/google/uuid"
+)
+
+const (
+	// staleMemberImportTimeout is how long an unfinished import job can go without progress
+	// before it is considered interrupted (e.g. by a restart)
+	staleMemberImportTimeout = 10 * time.Minute
+
+	// memberInvitationExpiry is how long new users have to choose their first password
+	memberInvitationExpiry = 7 * 24 * time.Hour
+
+	// memberImportSaveEvery is the number of rows processed between saves of a job's progress
+	memberImportSaveEvery = 25
+)
+
+// memberImportRoles are the roles a member can be imported with
+var memberImportRoles = map[string]bool{
+	"student":    true,
+	"instructor": true,
+	"admin":      true,
+}
+
+// MemberImportDependencies are the services the member import jobs use outside of the
+// tenants module. Imports are rejected when any of them is nil.
+type MemberImportDependencies struct {
+	Enroller    ports.MemberEnroller
+	Tokens      ports.InvitationTokenIssuer
+	Invitations ports.InvitationEmailSender
+}
+
+// StartMemberImport adds the members listed in a CSV or XLSX file to the current tenant
+// (admin only). The file is parsed right away; the rows are imported in the background and
+// the returned job reports the progress. A dry run validates the rows and counts what would
+// change without changing anything.
+func (s *TenantService) StartMemberImport(ctx context.Context, tenantID, fileName string, file io.Reader, dryRun bool, requestingUserID string) (*domain.MemberImportJob, error) {
+	if err := s.requireTenantAdmin(ctx, tenantID, requestingUserID); err != nil {
+		return nil, err
+	}
+	deps := s.memberImport
+	if deps.Enroller == nil || deps.Tokens == nil || deps.Invitations == nil {
+		return nil, fmt.Errorf("member import is not configured")
+	}
+
+	rows, err := parseMemberImportFile(fileName, file)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	job := &domain.MemberImportJob{
+		ID:          uuid.New().String(),
+		TenantID:    tenantID,
+		FileName:    fileName,
+		DryRun:      dryRun,
+		Status:      domain.MemberImportStatusPending,
+		RowsTotal:   len(rows),
+		RowErrors:   []domain.MemberImportRowError{},
+		RequestedBy: &requestingUserID,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := s.repo.CreateMemberImportJob(ctx, job); err != nil {
+		return nil, err
+	}
+
+	log.Printf("📥 [TenantService] Import of %d members from %s into tenant %s started by user %s (job %s, dry run: %v)",
+		len(rows), fileName, tenantID, requestingUserID, job.ID, dryRun)
+
+	// The job outlives the request, so it gets its own context
+	jobCopy := *job
+	go s.runMemberImportJob(context.Background(), &jobCopy, rows)
+
+	return job, nil
+}
+
+// GetMemberImportJob retrieves an import job of the current tenant (admin only)
+func (s *TenantService) GetMemberImportJob(ctx context.Context, tenantID, jobID, requestingUserID string) (*domain.MemberImportJob, error) {
+	if err := s.requireTenantAdmin(ctx, tenantID, requestingUserID); err != nil {
+		return nil, err
+	}
+	if _, err := uuid.Parse(jobID); err != nil {
+		return nil, ports.ErrMemberImportJobNotFound
+	}
+
+	job, err := s.repo.GetMemberImportJob(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if job.TenantID != tenantID {
+		return nil, ports.ErrMemberImportJobNotFound
+	}
+
+	return job, nil
+}
+
+// ListMemberImportJobs retrieves the import jobs of the current tenant (admin only)
+func (s *TenantService) ListMemberImportJobs(ctx context.Context, tenantID, requestingUserID string) ([]*domain.MemberImportJob, error) {
+	if err := s.requireTenantAdmin(ctx, tenantID, requestingUserID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.ListMemberImportJobs(ctx, tenantID)
+}
+
+// FailStaleMemberImportJobs fails the import jobs interrupted before they finished. The rows
+// imported so far are kept; importing the file again adds the rest.
+func (s *TenantService) FailStaleMemberImportJobs(ctx context.Context) (int64, error) {
+	return s.repo.FailStaleMemberImportJobs(ctx, time.Now().Add(-staleMemberImportTimeout), "member import job was interrupted")
+}
+
+// runMemberImportJob validates the rows of an import job, then imports them one by one. A row
+// that fails is recorded in the job and does not stop the others.
+func (s *TenantService) runMemberImportJob(ctx context.Context, job *domain.MemberImportJob, rows []domain.MemberImportRow) {
+	startedAt := time.Now()
+	job.Status = domain.MemberImportStatusRunning
+	job.StartedAt = &startedAt
+	s.saveMemberImportJob(ctx, job)
+
+	tenant, err := s.repo.GetTenantByID(ctx, job.TenantID)
+	if err != nil {
+		s.failMemberImportJob(ctx, job, fmt.Errorf("failed to get tenant: %w", err))
+		return
+	}
+
+	valid, rowErrors, err := s.validateMemberImportRows(ctx, job.TenantID, rows)
+	if err != nil {
+		s.failMemberImportJob(ctx, job, err)
+		return
+	}
+	for _, rowError := range rowErrors {
+		s.recordMemberImportError(job, rowError.Line, rowError.Email, rowError.Message)
+	}
+	job.RowsProcessed = len(rowErrors)
+	s.saveMemberImportJob(ctx, job)
+
+	// Invitations are sent with the branding of the tenant
+	mailCtx := email.WithTenant(ctx, job.TenantID)
+
+	for i, row := range valid {
+		if err := s.importMemberRow(ctx, mailCtx, job, tenant.Name, row); err != nil {
+			s.recordMemberImportError(job, row.Line, row.Email, err.Error())
+		}
+		job.RowsProcessed++
+		if (i+1)%memberImportSaveEvery == 0 {
+			s.saveMemberImportJob(ctx, job)
+		}
+	}
+
+	completedAt := time.Now()
+	job.Status = domain.MemberImportStatusCompleted
+	job.CompletedAt = &completedAt
+	s.saveMemberImportJob(ctx, job)
+
+	log.Printf("📥 [TenantService] Member import job %s of tenant %s finished: %d users created, %d members added, %d unchanged, %d enrollments, %d rows failed",
+		job.ID, job.TenantID, job.UsersCreated, job.MembersAdded, job.MembersUnchanged, job.EnrollmentsCreated, job.RowsFailed)
+}
+
+// validateMemberImportRows checks the rows of an import file and normalizes their email and
+// role. It returns the valid rows and why each of the others is invalid.
+func (s *TenantService) validateMemberImportRows(ctx context.Context, tenantID string, rows []domain.MemberImportRow) ([]domain.MemberImportRow, []domain.MemberImportRowError, error) {
+	valid := make([]domain.MemberImportRow, 0, len(rows))
+	var rowErrors []domain.MemberImportRowError
+	reject := func(row domain.MemberImportRow, format string, args ...interface{}) {
+		rowErrors = append(rowErrors, domain.MemberImportRowError{
+			Line:    row.Line,
+			Email:   row.Email,
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+
+	seen := make(map[string]int, len(rows))
+	courses := make(map[string]bool)
+
+nextRow:
+	for _, row := range rows {
+		row.Email = strings.ToLower(row.Email)
+		row.Role = strings.ToLower(row.Role)
+		if row.Role == "" {
+			row.Role = "student"
+		}
+
+		if err := s.validator.Var(row.Email, "required,email,max=255"); err != nil {
+			reject(row, "invalid email")
+			continue
+		}
+		if line, ok := seen[row.Email]; ok {
+			reject(row, "duplicate of line %d", line)
+			continue
+		}
+		seen[row.Email] = row.Line
+
+		if n := len([]rune(row.FullName)); n < 2 || n > 255 {
+			reject(row, "full name must be between 2 and 255 characters")
+			continue
+		}
+		if !memberImportRoles[row.Role] {
+			reject(row, "invalid role %q: must be student, instructor or admin", row.Role)
+			continue
+		}
+
+		for _, courseID := range row.CourseIDs {
+			if _, err := uuid.Parse(courseID); err != nil {
+				reject(row, "invalid course ID %q", courseID)
+				continue nextRow
+			}
+			exists, checked := courses[courseID]
+			if !checked {
+				var err error
+				exists, err = s.memberImport.Enroller.CourseExists(ctx, tenantID, courseID)
+				if err != nil {
+					return nil, nil, fmt.Errorf("failed to check course %s: %w", courseID, err)
+				}
+				courses[courseID] = exists
+			}
+			if !exists {
+				reject(row, "course %s not found", courseID)
+				continue nextRow
+			}
+		}
+
+		valid = append(valid, row)
+	}
+
+	return valid, rowErrors, nil
+}
+
+// importMemberRow adds the user of a row to the tenant, creating the user if there is none
+// with its email, enrolls it in the courses of the row and invites it. Users that already are
+// members keep their role. The invitation is recorded before anything is created and marked
+// sent last, so importing the row again after a failure finishes what is missing: the
+// membership, the enrollments and the invitation, with a password setup link when the import
+// created the user. A dry run only counts what would change; the enrollments it counts don't
+// exclude those that already exist.
+func (s *TenantService) importMemberRow(ctx, mailCtx context.Context, job *domain.MemberImportJob, tenantName string, row domain.MemberImportRow) error {
+	userID, err := s.repo.GetUserIDByEmail(ctx, row.Email)
+	if err != nil {
+		return err
+	}
+
+	isMember := false
+	if userID != "" {
+		membership, err := s.repo.GetMembership(ctx, userID, job.TenantID)
+		isMember = err == nil && membership != nil
+	}
+
+	invitation, err := s.repo.GetMemberInvitation(ctx, job.TenantID, row.Email)
+	if err != nil {
+		return err
+	}
+	// Members whose invitation was never sent are invited again
+	invite := !isMember || (invitation != nil && invitation.IsPending())
+
+	if !isMember && row.Role == "student" {
+		if err := s.quota.CheckLearnerQuota(ctx, job.TenantID, userID); err != nil {
+			return err
+		}
+	}
+
+	if job.DryRun {
+		switch {
+		case !invite:
+			job.MembersUnchanged++
+		case isMember:
+			job.InvitationsSent++
+		case userID == "":
+			job.UsersCreated++
+			job.MembersAdded++
+			job.InvitationsSent++
+		default:
+			job.MembersAdded++
+			job.InvitationsSent++
+		}
+		job.EnrollmentsCreated += len(row.CourseIDs)
+		return nil
+	}
+
+	if !isMember {
+		now := time.Now()
+		invitation = &domain.MemberInvitation{
+			TenantID: job.TenantID,
+			Email:    row.Email,
+			// A user created by an earlier run that failed has not chosen a password either
+			SetPassword: userID == "" || (invitation != nil && invitation.SetPassword),
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}
+		if err := s.repo.SaveMemberInvitation(ctx, invitation); err != nil {
+			return err
+		}
+	}
+
+	requestedBy := ""
+	if job.RequestedBy != nil {
+		requestedBy = *job.RequestedBy
+	}
+
+	if userID == "" {
+		// New users choose their password from the invitation
+		password, err := generateUnusablePassword()
+		if err != nil {
+			return err
+		}
+		user, err := s.userService.CreateUser(ctx, &userdomain.CreateUserDTO{
+			Email:    row.Email,
+			Password: password,
+			FullName: row.FullName,
+			Roles:    []string{row.Role},
+			TenantID: job.TenantID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create user: %w", err)
+		}
+		userID = user.ID
+		job.UsersCreated++
+	}
+
+	if isMember {
+		if !invite {
+			job.MembersUnchanged++
+		}
+	} else {
+		if err := s.CreateMembershipForUser(ctx, userID, job.TenantID, row.Role, requestedBy); err != nil {
+			return err
+		}
+		job.MembersAdded++
+	}
+
+	for _, courseID := range row.CourseIDs {
+		enrolled, err := s.memberImport.Enroller.EnrollMember(ctx, job.TenantID, userID, courseID)
+		if err != nil {
+			return fmt.Errorf("failed to enroll in course %s: %w", courseID, err)
+		}
+		if enrolled {
+			job.EnrollmentsCreated++
+		}
+	}
+
+	if !invite {
+		return nil
+	}
+
+	var setPasswordToken string
+	if invitation.SetPassword {
+		setPasswordToken, err = s.memberImport.Tokens.IssuePasswordSetupToken(ctx, userID, memberInvitationExpiry)
+		if err != nil {
+			return fmt.Errorf("member added but failed to issue password setup token: %w", err)
+		}
+	}
+	if err := s.memberImport.Invitations.SendMemberInvitationEmail(mailCtx, row.Email, row.FullName, tenantName, row.Role, setPasswordToken, memberInvitationExpiry); err != nil {
+		return fmt.Errorf("member added but failed to send invitation: %w", err)
+	}
+	job.InvitationsSent++
+
+	if err := s.repo.MarkMemberInvitationSent(ctx, job.TenantID, row.Email, time.Now()); err != nil {
+		return fmt.Errorf("invitation sent but failed to record it: %w", err)
+	}
+
+	return nil
+}
+
+// recordMemberImportError records why a row of an import job failed
+func (s *TenantService) recordMemberImportError(job *domain.MemberImportJob, line int, rowEmail, message string) {
+	job.RowsFailed++
+	job.RowErrors = append(job.RowErrors, domain.MemberImportRowError{
+		Line:    line,
+		Email:   rowEmail,
+		Message: message,
+	})
+}
+
+// failMemberImportJob records the error that stopped an import job
+func (s *TenantService) failMemberImportJob(ctx context.Context, job *domain.MemberImportJob, err error) {
+	log.Printf("❌ [TenantService] Member import job %s of tenant %s failed: %v", job.ID, job.TenantID, err)
+
+	message := err.Error()
+	completedAt := time.Now()
+	job.Status = domain.MemberImportStatusFailed
+	job.Error = &message
+	job.CompletedAt = &completedAt
+	s.saveMemberImportJob(ctx, job)
+}
+
+// saveMemberImportJob saves the progress of an import job. The job goes on when it can't be
+// saved.
+func (s *TenantService) saveMemberImportJob(ctx context.Context, job *domain.MemberImportJob) {
+	if err := s.repo.UpdateMemberImportJob(ctx, job); err != nil {
+		log.Printf("⚠️  [TenantService] Failed to save progress of member import job %s: %v", job.ID, err)
+	}
+}
+
+// generateUnusablePassword generates a random password nobody knows, for users that choose
+// their own from an invitation. It meets any password policy of a tenant.
+func generateUnusablePassword() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b) + "Aa1!", nil
+}
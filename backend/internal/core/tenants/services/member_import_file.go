@@ -0,0 +1,317 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/domain"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/ports"
+)
+
+const (
+	// maxMemberImportFileSize bounds the size of a member import file
+	maxMemberImportFileSize = 5 << 20
+
+	// maxMemberImportRows bounds the number of members imported by one job
+	maxMemberImportRows = 5000
+)
+
+// memberImportColumns maps the accepted headers of an import file to its columns
+var memberImportColumns = map[string]string{
+	"email":      "email",
+	"e_mail":     "email",
+	"correo":     "email",
+	"full_name":  "full_name",
+	"name":       "full_name",
+	"nombre":     "full_name",
+	"role":       "role",
+	"rol":        "role",
+	"course_ids": "course_ids",
+	"courses":    "course_ids",
+	"cursos":     "course_ids",
+}
+
+// parseMemberImportFile reads the members listed in a CSV or XLSX file, told apart by the
+// extension of fileName. The first row must be a header naming the email and full_name columns;
+// role and course_ids are optional. Course IDs are separated by spaces, commas, semicolons or
+// pipes. Rows are returned as written; they are validated by the import job.
+func parseMemberImportFile(fileName string, r io.Reader) ([]domain.MemberImportRow, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxMemberImportFileSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ports.ErrMemberImportInvalid, err)
+	}
+	if len(data) > maxMemberImportFileSize {
+		return nil, fmt.Errorf("%w: file is larger than %d MB", ports.ErrMemberImportInvalid, maxMemberImportFileSize>>20)
+	}
+
+	var records []importRecord
+	switch strings.ToLower(path.Ext(fileName)) {
+	case ".csv":
+		records, err = readCSVRecords(data)
+	case ".xlsx":
+		records, err = readXLSXRecords(data)
+	default:
+		return nil, fmt.Errorf("%w: file must be .csv or .xlsx", ports.ErrMemberImportInvalid)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ports.ErrMemberImportInvalid, err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%w: file is empty", ports.ErrMemberImportInvalid)
+	}
+
+	columns := make(map[string]int)
+	for i, header := range records[0].fields {
+		key := strings.ToLower(strings.TrimSpace(header))
+		key = strings.NewReplacer(" ", "_", "-", "_").Replace(key)
+		if column, ok := memberImportColumns[key]; ok {
+			if _, seen := columns[column]; !seen {
+				columns[column] = i
+			}
+		}
+	}
+	for _, required := range []string{"email", "full_name"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("%w: header must have an %s column", ports.ErrMemberImportInvalid, required)
+		}
+	}
+
+	field := func(record importRecord, column string) string {
+		i, ok := columns[column]
+		if !ok || i >= len(record.fields) {
+			return ""
+		}
+		return strings.TrimSpace(record.fields[i])
+	}
+
+	rows := make([]domain.MemberImportRow, 0, len(records)-1)
+	for _, record := range records[1:] {
+		row := domain.MemberImportRow{
+			Line:     record.line,
+			Email:    field(record, "email"),
+			FullName: field(record, "full_name"),
+			Role:     field(record, "role"),
+			CourseIDs: strings.FieldsFunc(field(record, "course_ids"), func(r rune) bool {
+				return r == ' ' || r == ',' || r == ';' || r == '|'
+			}),
+		}
+		// Blank rows are common at the end of spreadsheets
+		if row.Email == "" && row.FullName == "" && row.Role == "" && len(row.CourseIDs) == 0 {
+			continue
+		}
+		rows = append(rows, row)
+	}
+
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%w: file has no members", ports.ErrMemberImportInvalid)
+	}
+	if len(rows) > maxMemberImportRows {
+		return nil, fmt.Errorf("%w: file has more than %d members", ports.ErrMemberImportInvalid, maxMemberImportRows)
+	}
+
+	return rows, nil
+}
+
+// importRecord is a row of an import file with the line it starts at
+type importRecord struct {
+	line   int
+	fields []string
+}
+
+// readCSVRecords reads the records of a CSV file. Files whose header has semicolons and no
+// commas, as exported by spreadsheets in many locales, are read with semicolons as separator.
+func readCSVRecords(data []byte) ([]importRecord, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	header, _, _ := bytes.Cut(data, []byte("\n"))
+	if bytes.Contains(header, []byte(";")) && !bytes.Contains(header, []byte(",")) {
+		reader.Comma = ';'
+	}
+
+	var records []importRecord
+	for {
+		fields, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		records = append(records, importRecord{line: line, fields: fields})
+	}
+
+	return records, nil
+}
+
+// xlsxWorkbook is the part of xl/workbook.xml listing the sheets
+type xlsxWorkbook struct {
+	Sheets []struct {
+		RelationshipID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+// xlsxRelationships is xl/_rels/workbook.xml.rels, which maps sheets to their files
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+// xlsxSharedStrings is xl/sharedStrings.xml, which holds the text of the cells
+type xlsxSharedStrings struct {
+	Items []struct {
+		Text string `xml:"t"`
+		Runs []struct {
+			Text string `xml:"t"`
+		} `xml:"r"`
+	} `xml:"si"`
+}
+
+// xlsxWorksheet is the part of a sheet file with the cells
+type xlsxWorksheet struct {
+	Rows []struct {
+		Number int `xml:"r,attr"`
+		Cells  []struct {
+			Ref    string `xml:"r,attr"`
+			Type   string `xml:"t,attr"`
+			Value  string `xml:"v"`
+			Inline struct {
+				Text string `xml:"t"`
+			} `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// readXLSXRecords reads the records of the first sheet of an XLSX workbook
+func readXLSXRecords(data []byte) ([]importRecord, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("not an XLSX file: %v", err)
+	}
+	files := make(map[string]*zip.File, len(archive.File))
+	for _, file := range archive.File {
+		files[file.Name] = file
+	}
+
+	decode := func(name string, v interface{}) (bool, error) {
+		file, ok := files[name]
+		if !ok {
+			return false, nil
+		}
+		rc, err := file.Open()
+		if err != nil {
+			return false, err
+		}
+		defer rc.Close()
+		if err := xml.NewDecoder(io.LimitReader(rc, 8*maxMemberImportFileSize)).Decode(v); err != nil {
+			return false, fmt.Errorf("failed to read %s: %v", name, err)
+		}
+		return true, nil
+	}
+
+	// The first sheet of the workbook is usually, but not always, sheet1.xml
+	sheetName := "xl/worksheets/sheet1.xml"
+	var workbook xlsxWorkbook
+	var relationships xlsxRelationships
+	if ok, err := decode("xl/workbook.xml", &workbook); err != nil {
+		return nil, err
+	} else if ok && len(workbook.Sheets) > 0 {
+		if _, err := decode("xl/_rels/workbook.xml.rels", &relationships); err != nil {
+			return nil, err
+		}
+		for _, rel := range relationships.Relationships {
+			if rel.ID == workbook.Sheets[0].RelationshipID {
+				if strings.HasPrefix(rel.Target, "/") {
+					sheetName = strings.TrimPrefix(rel.Target, "/")
+				} else {
+					sheetName = path.Join("xl", rel.Target)
+				}
+				break
+			}
+		}
+	}
+
+	var sharedStrings xlsxSharedStrings
+	if _, err := decode("xl/sharedStrings.xml", &sharedStrings); err != nil {
+		return nil, err
+	}
+	text := make([]string, len(sharedStrings.Items))
+	for i, item := range sharedStrings.Items {
+		text[i] = item.Text
+		for _, run := range item.Runs {
+			text[i] += run.Text
+		}
+	}
+
+	var sheet xlsxWorksheet
+	if ok, err := decode(sheetName, &sheet); err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, fmt.Errorf("workbook has no sheet")
+	}
+
+	records := make([]importRecord, 0, len(sheet.Rows))
+	for i, row := range sheet.Rows {
+		line := row.Number
+		if line == 0 {
+			line = i + 1
+		}
+
+		var fields []string
+		for j, cell := range row.Cells {
+			column := xlsxColumnIndex(cell.Ref)
+			if column < 0 {
+				column = j
+			}
+			for len(fields) <= column {
+				fields = append(fields, "")
+			}
+
+			switch cell.Type {
+			case "s":
+				index, err := strconv.Atoi(cell.Value)
+				if err != nil || index < 0 || index >= len(text) {
+					return nil, fmt.Errorf("cell %s has an invalid shared string", cell.Ref)
+				}
+				fields[column] = text[index]
+			case "inlineStr":
+				fields[column] = cell.Inline.Text
+			default:
+				fields[column] = cell.Value
+			}
+		}
+
+		records = append(records, importRecord{line: line, fields: fields})
+	}
+
+	return records, nil
+}
+
+// xlsxColumnIndex returns the zero-based column of a cell reference such as "C12", or -1
+func xlsxColumnIndex(ref string) int {
+	column := 0
+	letters := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		column = column*26 + int(r-'A'+1)
+		letters++
+	}
+	if letters == 0 {
+		return -1
+	}
+	return column - 1
+}
@@ -0,0 +1,480 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	authdomain "github.com/DanielIturra1610/stegmaier-landing/internal/core/auth/domain"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/domain"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/ports"
+	userdomain "github.com/DanielIturra1610/stegmaier-landing/internal/core/user/domain"
+	userports "github.com/DanielIturra1610/stegmaier-landing/internal/core/user/ports"
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	importCourseID      = "33333333-3333-3333-3333-333333333333"
+	importOtherCourseID = "44444444-4444-4444-4444-444444444444"
+)
+
+// stubMemberEnroller knows the courses in courses; it does not enroll anyone
+type stubMemberEnroller struct {
+	courses map[string]bool
+}
+
+func (e *stubMemberEnroller) CourseExists(ctx context.Context, tenantID, courseID string) (bool, error) {
+	return e.courses[courseID], nil
+}
+
+func (e *stubMemberEnroller) EnrollMember(ctx context.Context, tenantID, userID, courseID string) (bool, error) {
+	return false, errors.New("not implemented")
+}
+
+// buildXLSX builds a workbook whose first sheet, sheetFile, has the given XML rows
+func buildXLSX(t *testing.T, sheetFile string, sharedStrings []string, rows string) []byte {
+	t.Helper()
+
+	var strs strings.Builder
+	for _, s := range sharedStrings {
+		strs.WriteString("<si><t>" + s + "</t></si>")
+	}
+
+	files := map[string]string{
+		"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+			`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets><sheet name="Members" sheetId="1" r:id="rId3"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId3" Target="worksheets/` + sheetFile + `"/></Relationships>`,
+		"xl/sharedStrings.xml":       `<sst>` + strs.String() + `</sst>`,
+		"xl/worksheets/" + sheetFile: `<worksheet><sheetData>` + rows + `</sheetData></worksheet>`,
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := archive.Create(name)
+		if err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatalf("Failed to close workbook: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestParseMemberImportFile(t *testing.T) {
+	xlsx := buildXLSX(t, "members.xml",
+		[]string{"Correo", "Nombre", "Cursos", "ana@example.com", "Ana Pérez"},
+		`<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c><c r="D1" t="s"><v>2</v></c></row>`+
+			`<row r="3"><c r="A3" t="s"><v>3</v></c><c r="B3" t="s"><v>4</v></c>`+
+			`<c r="D3" t="inlineStr"><is><t>`+importCourseID+`|`+importOtherCourseID+`</t></is></c></row>`)
+
+	tests := []struct {
+		name     string
+		fileName string
+		content  []byte
+		expected []domain.MemberImportRow
+		wantErr  bool
+	}{
+		{
+			name:     "CSV with commas",
+			fileName: "members.csv",
+			content: []byte("\xef\xbb\xbfEmail,Full Name,Role,Course IDs\n" +
+				"ana@example.com,Ana Pérez,instructor,\"" + importCourseID + ", " + importOtherCourseID + "\"\n" +
+				",,,\n" +
+				"luis@example.com,Luis Soto,,\n"),
+			expected: []domain.MemberImportRow{
+				{Line: 2, Email: "ana@example.com", FullName: "Ana Pérez", Role: "instructor", CourseIDs: []string{importCourseID, importOtherCourseID}},
+				{Line: 4, Email: "luis@example.com", FullName: "Luis Soto", CourseIDs: []string{}},
+			},
+		},
+		{
+			name:     "CSV with semicolons",
+			fileName: "MEMBERS.CSV",
+			content:  []byte("correo;nombre\nana@example.com;Ana Pérez\n"),
+			expected: []domain.MemberImportRow{
+				{Line: 2, Email: "ana@example.com", FullName: "Ana Pérez", CourseIDs: []string{}},
+			},
+		},
+		{
+			name:     "XLSX with shared and inline strings",
+			fileName: "members.xlsx",
+			content:  xlsx,
+			expected: []domain.MemberImportRow{
+				{Line: 3, Email: "ana@example.com", FullName: "Ana Pérez", CourseIDs: []string{importCourseID, importOtherCourseID}},
+			},
+		},
+		{
+			name:     "Missing full name column",
+			fileName: "members.csv",
+			content:  []byte("email,role\nana@example.com,student\n"),
+			wantErr:  true,
+		},
+		{
+			name:     "Header only",
+			fileName: "members.csv",
+			content:  []byte("email,full_name\n"),
+			wantErr:  true,
+		},
+		{
+			name:     "Unsupported extension",
+			fileName: "members.txt",
+			content:  []byte("email,full_name\nana@example.com,Ana Pérez\n"),
+			wantErr:  true,
+		},
+		{
+			name:     "XLSX that is not a workbook",
+			fileName: "members.xlsx",
+			content:  []byte("email,full_name\n"),
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := parseMemberImportFile(tt.fileName, bytes.NewReader(tt.content))
+			if tt.wantErr {
+				if !errors.Is(err, ports.ErrMemberImportInvalid) {
+					t.Fatalf("Expected ErrMemberImportInvalid, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(rows, tt.expected) {
+				t.Errorf("Expected rows %+v, got %+v", tt.expected, rows)
+			}
+		})
+	}
+}
+
+func TestValidateMemberImportRows(t *testing.T) {
+	service := &TenantService{
+		validator: validator.New(),
+		memberImport: MemberImportDependencies{
+			Enroller: &stubMemberEnroller{courses: map[string]bool{importCourseID: true}},
+		},
+	}
+
+	rows := []domain.MemberImportRow{
+		{Line: 2, Email: "Ana@Example.com", FullName: "Ana Pérez", CourseIDs: []string{importCourseID}},
+		{Line: 3, Email: "not-an-email", FullName: "Luis Soto"},
+		{Line: 4, Email: "ana@example.com", FullName: "Ana Again"},
+		{Line: 5, Email: "eva@example.com", FullName: "E"},
+		{Line: 6, Email: "eve@example.com", FullName: "Eve Ruiz", Role: "superadmin"},
+		{Line: 7, Email: "tom@example.com", FullName: "Tom Díaz", Role: "Instructor", CourseIDs: []string{"course-1"}},
+		{Line: 8, Email: "sol@example.com", FullName: "Sol Mora", CourseIDs: []string{importOtherCourseID}},
+		{Line: 9, Email: "max@example.com", FullName: "Max Vera", Role: "Admin"},
+	}
+
+	valid, rowErrors, err := service.validateMemberImportRows(context.Background(), cloneSourceTenantID, rows)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expectedValid := []domain.MemberImportRow{
+		{Line: 2, Email: "ana@example.com", FullName: "Ana Pérez", Role: "student", CourseIDs: []string{importCourseID}},
+		{Line: 9, Email: "max@example.com", FullName: "Max Vera", Role: "admin"},
+	}
+	if !reflect.DeepEqual(valid, expectedValid) {
+		t.Errorf("Expected valid rows %+v, got %+v", expectedValid, valid)
+	}
+
+	expectedErrors := map[int]string{
+		3: "invalid email",
+		4: "duplicate of line 2",
+		5: "full name",
+		6: "invalid role",
+		7: "invalid course ID",
+		8: "not found",
+	}
+	if len(rowErrors) != len(expectedErrors) {
+		t.Fatalf("Expected %d row errors, got %+v", len(expectedErrors), rowErrors)
+	}
+	for _, rowError := range rowErrors {
+		if !strings.Contains(rowError.Message, expectedErrors[rowError.Line]) {
+			t.Errorf("Line %d: expected an error about %q, got %q", rowError.Line, expectedErrors[rowError.Line], rowError.Message)
+		}
+	}
+}
+
+var errImportStep = errors.New("step failed")
+
+// importRepository keeps users, memberships and invitations in memory; the other
+// TenantRepository methods are not used. failMembership makes CreateMembership fail.
+type importRepository struct {
+	ports.TenantRepository
+	users          map[string]string
+	memberships    map[string]string
+	invitations    map[string]*domain.MemberInvitation
+	failMembership bool
+}
+
+func newImportRepository() *importRepository {
+	return &importRepository{
+		users:       make(map[string]string),
+		memberships: make(map[string]string),
+		invitations: make(map[string]*domain.MemberInvitation),
+	}
+}
+
+func (r *importRepository) GetUserIDByEmail(ctx context.Context, email string) (string, error) {
+	return r.users[email], nil
+}
+
+func (r *importRepository) GetMembership(ctx context.Context, userID, tenantID string) (*domain.TenantMembership, error) {
+	role, ok := r.memberships[userID]
+	if !ok {
+		return nil, errors.New("membership not found")
+	}
+	return &domain.TenantMembership{UserID: userID, TenantID: tenantID, Role: role}, nil
+}
+
+func (r *importRepository) CreateMembership(ctx context.Context, membership *domain.TenantMembership) error {
+	if r.failMembership {
+		return errImportStep
+	}
+	r.memberships[membership.UserID] = membership.Role
+	return nil
+}
+
+func (r *importRepository) GetTenantPlan(ctx context.Context, tenantID string) (*domain.TenantPlan, error) {
+	return nil, nil
+}
+
+func (r *importRepository) GetMemberInvitation(ctx context.Context, tenantID, email string) (*domain.MemberInvitation, error) {
+	invitation, ok := r.invitations[email]
+	if !ok {
+		return nil, nil
+	}
+	copied := *invitation
+	return &copied, nil
+}
+
+func (r *importRepository) SaveMemberInvitation(ctx context.Context, invitation *domain.MemberInvitation) error {
+	saved := *invitation
+	if existing, ok := r.invitations[invitation.Email]; ok {
+		saved.SetPassword = saved.SetPassword || existing.SetPassword
+	}
+	saved.SentAt = nil
+	r.invitations[invitation.Email] = &saved
+	return nil
+}
+
+func (r *importRepository) MarkMemberInvitationSent(ctx context.Context, tenantID, email string, sentAt time.Time) error {
+	r.invitations[email].SentAt = &sentAt
+	return nil
+}
+
+// importUserService creates users in an importRepository
+type importUserService struct {
+	userports.UserManagementService
+	repo    *importRepository
+	created int
+}
+
+func (s *importUserService) CreateUser(ctx context.Context, dto *userdomain.CreateUserDTO) (*authdomain.User, error) {
+	s.created++
+	userID := "user-" + dto.Email
+	s.repo.users[dto.Email] = userID
+	return &authdomain.User{ID: userID, Email: dto.Email}, nil
+}
+
+// importInvitations records the password setup tokens issued and the invitations sent
+type importInvitations struct {
+	failTokens bool
+	failEmails bool
+	issued     int
+	sent       []string
+}
+
+func (i *importInvitations) IssuePasswordSetupToken(ctx context.Context, userID string, expiresIn time.Duration) (string, error) {
+	if i.failTokens {
+		return "", errImportStep
+	}
+	i.issued++
+	return "token-" + userID, nil
+}
+
+func (i *importInvitations) SendMemberInvitationEmail(ctx context.Context, to, userName, tenantName, role, setPasswordToken string, expiresIn time.Duration) error {
+	if i.failEmails {
+		return errImportStep
+	}
+	i.sent = append(i.sent, to+":"+setPasswordToken)
+	return nil
+}
+
+func newImportService() (*TenantService, *importRepository, *importUserService, *importInvitations) {
+	repo := newImportRepository()
+	users := &importUserService{repo: repo}
+	invitations := &importInvitations{}
+	service := &TenantService{
+		repo:        repo,
+		quota:       NewQuotaService(repo, nil),
+		userService: users,
+		validator:   validator.New(),
+		memberImport: MemberImportDependencies{
+			Enroller:    &stubMemberEnroller{},
+			Tokens:      invitations,
+			Invitations: invitations,
+		},
+	}
+	return service, repo, users, invitations
+}
+
+// importRow imports a row in a new job and returns the job
+func importRow(t *testing.T, service *TenantService, row domain.MemberImportRow, dryRun bool) (*domain.MemberImportJob, error) {
+	t.Helper()
+	job := &domain.MemberImportJob{TenantID: cloneSourceTenantID, DryRun: dryRun}
+	ctx := context.Background()
+	return job, service.importMemberRow(ctx, ctx, job, "Acme", row)
+}
+
+var importNewUserRow = domain.MemberImportRow{Line: 2, Email: "ana@example.com", FullName: "Ana Pérez", Role: "student"}
+
+func TestImportMemberRowDryRun(t *testing.T) {
+	service, repo, users, invitations := newImportService()
+	repo.users["luis@example.com"] = "user-luis"
+	repo.users["eva@example.com"] = "user-eva"
+	repo.memberships["user-eva"] = "student"
+
+	rows := []domain.MemberImportRow{
+		importNewUserRow,
+		{Line: 3, Email: "luis@example.com", FullName: "Luis Soto", Role: "instructor"},
+		{Line: 4, Email: "eva@example.com", FullName: "Eva Ruiz", Role: "student"},
+	}
+	job := &domain.MemberImportJob{TenantID: cloneSourceTenantID, DryRun: true}
+	ctx := context.Background()
+	for _, row := range rows {
+		if err := service.importMemberRow(ctx, ctx, job, "Acme", row); err != nil {
+			t.Fatalf("Line %d: unexpected error: %v", row.Line, err)
+		}
+	}
+
+	if job.UsersCreated != 1 || job.MembersAdded != 2 || job.MembersUnchanged != 1 || job.InvitationsSent != 2 {
+		t.Errorf("Expected 1 user created, 2 members added, 1 unchanged and 2 invitations, got %+v", job)
+	}
+	if users.created != 0 || len(repo.memberships) != 1 || len(repo.invitations) != 0 || invitations.issued != 0 || len(invitations.sent) != 0 {
+		t.Errorf("Expected a dry run to change nothing")
+	}
+}
+
+func TestImportMemberRowRerun(t *testing.T) {
+	service, repo, users, invitations := newImportService()
+
+	job, err := importRow(t, service, importNewUserRow, false)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if job.UsersCreated != 1 || job.MembersAdded != 1 || job.InvitationsSent != 1 {
+		t.Errorf("Expected the user to be created, added and invited, got %+v", job)
+	}
+	if !reflect.DeepEqual(invitations.sent, []string{"ana@example.com:token-user-ana@example.com"}) {
+		t.Errorf("Expected an invitation with a password setup token, got %v", invitations.sent)
+	}
+	if invitation := repo.invitations["ana@example.com"]; invitation == nil || invitation.IsPending() {
+		t.Errorf("Expected the invitation to be recorded as sent, got %+v", invitation)
+	}
+
+	// Importing the file again changes nothing
+	job, err = importRow(t, service, importNewUserRow, false)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if job.MembersUnchanged != 1 || job.UsersCreated != 0 || job.MembersAdded != 0 || job.InvitationsSent != 0 {
+		t.Errorf("Expected the member to be unchanged, got %+v", job)
+	}
+	if users.created != 1 || len(invitations.sent) != 1 {
+		t.Errorf("Expected no new user or invitation, got %d users and %d invitations", users.created, len(invitations.sent))
+	}
+
+	// Members added outside of imports are not invited
+	repo.users["eva@example.com"] = "user-eva"
+	repo.memberships["user-eva"] = "student"
+	if job, err = importRow(t, service, domain.MemberImportRow{Line: 3, Email: "eva@example.com", FullName: "Eva Ruiz", Role: "student"}, false); err != nil || job.MembersUnchanged != 1 {
+		t.Errorf("Expected an existing member to be unchanged, got %+v, %v", job, err)
+	}
+	if len(invitations.sent) != 1 {
+		t.Errorf("Expected no invitation for an existing member, got %v", invitations.sent)
+	}
+}
+
+func TestImportMemberRowRerunAfterFailure(t *testing.T) {
+	tests := []struct {
+		name string
+		fail func(repo *importRepository, invitations *importInvitations, fail bool)
+	}{
+		{
+			name: "Membership not created",
+			fail: func(repo *importRepository, invitations *importInvitations, fail bool) { repo.failMembership = fail },
+		},
+		{
+			name: "Password setup token not issued",
+			fail: func(repo *importRepository, invitations *importInvitations, fail bool) { invitations.failTokens = fail },
+		},
+		{
+			name: "Invitation not sent",
+			fail: func(repo *importRepository, invitations *importInvitations, fail bool) { invitations.failEmails = fail },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, repo, users, invitations := newImportService()
+
+			tt.fail(repo, invitations, true)
+			if _, err := importRow(t, service, importNewUserRow, false); !errors.Is(err, errImportStep) {
+				t.Fatalf("Expected the row to fail, got %v", err)
+			}
+			if invitation := repo.invitations["ana@example.com"]; invitation == nil || !invitation.IsPending() || !invitation.SetPassword {
+				t.Fatalf("Expected a pending invitation with a password setup link, got %+v", invitation)
+			}
+
+			// A dry run reports the invitation that is missing
+			tt.fail(repo, invitations, false)
+			job, err := importRow(t, service, importNewUserRow, true)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if job.UsersCreated != 0 || job.InvitationsSent != 1 || job.MembersUnchanged != 0 {
+				t.Errorf("Expected the dry run to report the missing invitation, got %+v", job)
+			}
+
+			job, err = importRow(t, service, importNewUserRow, false)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if job.UsersCreated != 0 || job.InvitationsSent != 1 {
+				t.Errorf("Expected the invitation to be sent without creating the user again, got %+v", job)
+			}
+			if users.created != 1 {
+				t.Errorf("Expected the user to be created once, got %d", users.created)
+			}
+			if _, ok := repo.memberships["user-ana@example.com"]; !ok {
+				t.Errorf("Expected the membership to be created")
+			}
+			if !reflect.DeepEqual(invitations.sent, []string{"ana@example.com:token-user-ana@example.com"}) {
+				t.Errorf("Expected one invitation with a password setup token, got %v", invitations.sent)
+			}
+			if repo.invitations["ana@example.com"].IsPending() {
+				t.Errorf("Expected the invitation to be recorded as sent")
+			}
+
+			// Once the invitation is sent, the member is left as it is
+			if job, err = importRow(t, service, importNewUserRow, false); err != nil || job.MembersUnchanged != 1 || len(invitations.sent) != 1 {
+				t.Errorf("Expected the member to be unchanged, got %+v, %v", job, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/domain"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/ports"
+	"github.com/google/uuid"
+)
+
+// ListPlans lists the plans with their limits and features (superadmin only)
+func (s *TenantService) ListPlans(ctx context.Context) ([]*domain.TenantPlan, error) {
+	return s.repo.ListPlans(ctx)
+}
+
+// CreatePlan defines a plan (superadmin only)
+func (s *TenantService) CreatePlan(ctx context.Context, dto *domain.TenantPlanDTO) (*domain.TenantPlan, error) {
+	features, err := s.validatePlan(dto)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	plan := &domain.TenantPlan{
+		ID:                uuid.New().String(),
+		Code:              strings.ToLower(strings.TrimSpace(dto.Code)),
+		Name:              strings.TrimSpace(dto.Name),
+		Description:       strings.TrimSpace(dto.Description),
+		MaxActiveLearners: dto.MaxActiveLearners,
+		MaxCourses:        dto.MaxCourses,
+		MaxStorageGB:      dto.MaxStorageGB,
+		Features:          features,
+		IsDefault:         dto.IsDefault,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+
+	if err := s.repo.CreatePlan(ctx, plan); err != nil {
+		return nil, err
+	}
+
+	log.Printf("💳 [TenantService] Plan %q created", plan.Code)
+	return plan, nil
+}
+
+// UpdatePlan replaces the limits and features of a plan (superadmin only). The new limits
+// apply to the tenants on the plan right away; resources over a lowered limit are kept, but
+// no more can be created.
+func (s *TenantService) UpdatePlan(ctx context.Context, planID string, dto *domain.TenantPlanDTO) (*domain.TenantPlan, error) {
+	features, err := s.validatePlan(dto)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := uuid.Parse(planID); err != nil {
+		return nil, ports.ErrPlanNotFound
+	}
+
+	plan, err := s.repo.GetPlan(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+
+	plan.Code = strings.ToLower(strings.TrimSpace(dto.Code))
+	plan.Name = strings.TrimSpace(dto.Name)
+	plan.Description = strings.TrimSpace(dto.Description)
+	plan.MaxActiveLearners = dto.MaxActiveLearners
+	plan.MaxCourses = dto.MaxCourses
+	plan.MaxStorageGB = dto.MaxStorageGB
+	plan.Features = features
+	plan.IsDefault = dto.IsDefault
+	plan.UpdatedAt = time.Now()
+
+	if err := s.repo.UpdatePlan(ctx, plan); err != nil {
+		return nil, err
+	}
+
+	log.Printf("💳 [TenantService] Plan %q updated", plan.Code)
+	return plan, nil
+}
+
+// DeletePlan deletes a plan that no tenant is on (superadmin only)
+func (s *TenantService) DeletePlan(ctx context.Context, planID string) error {
+	if _, err := uuid.Parse(planID); err != nil {
+		return ports.ErrPlanNotFound
+	}
+
+	return s.repo.DeletePlan(ctx, planID)
+}
+
+// AssignTenantPlan puts a tenant on a plan, or on the default plan if the DTO has no plan
+// (superadmin only), and returns the usage of the tenant against its new limits
+func (s *TenantService) AssignTenantPlan(ctx context.Context, tenantID string, dto *domain.AssignTenantPlanDTO) (*domain.TenantUsage, error) {
+	if err := s.validator.Struct(dto); err != nil {
+		return nil, fmt.Errorf("%w: %v", ports.ErrPlanInvalid, err)
+	}
+
+	tenant, err := s.GetTenantLifecycle(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	planID := dto.PlanID
+	if planID != nil && *planID == "" {
+		planID = nil
+	}
+	if err := s.repo.SetTenantPlan(ctx, tenant.ID, planID); err != nil {
+		return nil, err
+	}
+
+	log.Printf("💳 [TenantService] Plan of tenant %s (%s) changed", tenant.Slug, tenant.ID)
+	return s.quota.GetTenantUsage(ctx, tenant.ID)
+}
+
+// GetTenantUsage reports the consumption of a tenant against the limits of its plan (superadmin only)
+func (s *TenantService) GetTenantUsage(ctx context.Context, tenantID string) (*domain.TenantUsage, error) {
+	tenant, err := s.GetTenantLifecycle(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.quota.GetTenantUsage(ctx, tenant.ID)
+}
+
+// GetCurrentTenantUsage reports the consumption of the current tenant against the limits of
+// its plan (admin only)
+func (s *TenantService) GetCurrentTenantUsage(ctx context.Context, tenantID, requestingUserID string) (*domain.TenantUsage, error) {
+	if err := s.requireTenantAdmin(ctx, tenantID, requestingUserID); err != nil {
+		return nil, err
+	}
+
+	return s.quota.GetTenantUsage(ctx, tenantID)
+}
+
+// validatePlan checks a plan definition and returns its features without duplicates
+func (s *TenantService) validatePlan(dto *domain.TenantPlanDTO) ([]string, error) {
+	if err := s.validator.Struct(dto); err != nil {
+		return nil, fmt.Errorf("%w: %v", ports.ErrPlanInvalid, err)
+	}
+
+	features := make([]string, 0, len(dto.Features))
+	seen := make(map[string]bool)
+	for _, feature := range dto.Features {
+		if !domain.IsValidPlanFeature(feature) {
+			return nil, fmt.Errorf("%w: unknown feature %q", ports.ErrPlanInvalid, feature)
+		}
+		if !seen[feature] {
+			seen[feature] = true
+			features = append(features, feature)
+		}
+	}
+
+	return features, nil
+}
@@ -0,0 +1,136 @@
+package services
+
+import (
+	"context"
+
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/domain"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/ports"
+)
+
+// QuotaService implements the QuotaService interface. Usage is measured when a limit is
+// checked, so limits apply as soon as a plan changes.
+type QuotaService struct {
+	repo      ports.TenantRepository
+	usageRepo ports.TenantUsageRepository
+}
+
+// NewQuotaService creates a new quota service
+func NewQuotaService(repo ports.TenantRepository, usageRepo ports.TenantUsageRepository) *QuotaService {
+	return &QuotaService{
+		repo:      repo,
+		usageRepo: usageRepo,
+	}
+}
+
+// GetTenantUsage reports the consumption of a tenant against the limits of its plan
+func (q *QuotaService) GetTenantUsage(ctx context.Context, tenantID string) (*domain.TenantUsage, error) {
+	plan, err := q.repo.GetTenantPlan(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := &domain.TenantUsage{TenantID: tenantID, Plan: plan}
+
+	usage.ActiveLearners.Used, err = q.usageRepo.CountActiveLearners(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	usage.Courses.Used, err = q.usageRepo.CountCourses(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	usage.StorageBytes.Used, err = q.usageRepo.GetStorageBytes(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	if plan != nil {
+		usage.ActiveLearners.Limit = intLimit(plan.MaxActiveLearners)
+		usage.Courses.Limit = intLimit(plan.MaxCourses)
+		usage.StorageBytes.Limit = plan.MaxStorageBytes()
+	}
+
+	return usage, nil
+}
+
+// CheckLearnerQuota returns ErrLearnerLimitReached if the user would be a new active learner
+// and the tenant has no learner seats left. Users that already are active learners can enroll
+// in more courses.
+func (q *QuotaService) CheckLearnerQuota(ctx context.Context, tenantID, userID string) error {
+	plan, err := q.repo.GetTenantPlan(ctx, tenantID)
+	if err != nil || plan == nil || plan.MaxActiveLearners == nil {
+		return err
+	}
+
+	if userID != "" {
+		isLearner, err := q.usageRepo.IsActiveLearner(ctx, tenantID, userID)
+		if err != nil {
+			return err
+		}
+		if isLearner {
+			return nil
+		}
+	}
+
+	learners, err := q.usageRepo.CountActiveLearners(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+
+	usage := domain.QuotaUsage{Used: learners, Limit: intLimit(plan.MaxActiveLearners)}
+	if !usage.Allows(1) {
+		return ports.ErrLearnerLimitReached
+	}
+
+	return nil
+}
+
+// CheckCourseQuota returns ErrCourseLimitReached if the tenant can't create another course
+func (q *QuotaService) CheckCourseQuota(ctx context.Context, tenantID string) error {
+	plan, err := q.repo.GetTenantPlan(ctx, tenantID)
+	if err != nil || plan == nil || plan.MaxCourses == nil {
+		return err
+	}
+
+	courses, err := q.usageRepo.CountCourses(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+
+	usage := domain.QuotaUsage{Used: courses, Limit: intLimit(plan.MaxCourses)}
+	if !usage.Allows(1) {
+		return ports.ErrCourseLimitReached
+	}
+
+	return nil
+}
+
+// CheckStorageQuota returns ErrStorageLimitReached if a file of size bytes doesn't fit in the
+// storage of the tenant
+func (q *QuotaService) CheckStorageQuota(ctx context.Context, tenantID string, size int64) error {
+	plan, err := q.repo.GetTenantPlan(ctx, tenantID)
+	if err != nil || plan == nil || plan.MaxStorageGB == nil {
+		return err
+	}
+
+	used, err := q.usageRepo.GetStorageBytes(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+
+	usage := domain.QuotaUsage{Used: used, Limit: plan.MaxStorageBytes()}
+	if !usage.Allows(size) {
+		return ports.ErrStorageLimitReached
+	}
+
+	return nil
+}
+
+// intLimit converts a limit of a plan to the limit of a QuotaUsage
+func intLimit(limit *int) *int64 {
+	if limit == nil {
+		return nil
+	}
+	v := int64(*limit)
+	return &v
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/domain"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/ports"
+)
+
+const quotaTenantID = "11111111-1111-1111-1111-111111111111"
+
+// quotaPlanRepository returns a fixed plan; the other TenantRepository methods are not used
+type quotaPlanRepository struct {
+	ports.TenantRepository
+	plan *domain.TenantPlan
+}
+
+func (r *quotaPlanRepository) GetTenantPlan(ctx context.Context, tenantID string) (*domain.TenantPlan, error) {
+	return r.plan, nil
+}
+
+// quotaUsageRepository reports fixed usage
+type quotaUsageRepository struct {
+	learners     []string
+	courses      int64
+	storageBytes int64
+}
+
+func (r *quotaUsageRepository) CountActiveLearners(ctx context.Context, tenantID string) (int64, error) {
+	return int64(len(r.learners)), nil
+}
+
+func (r *quotaUsageRepository) IsActiveLearner(ctx context.Context, tenantID, userID string) (bool, error) {
+	for _, learner := range r.learners {
+		if learner == userID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *quotaUsageRepository) CountCourses(ctx context.Context, tenantID string) (int64, error) {
+	return r.courses, nil
+}
+
+func (r *quotaUsageRepository) GetStorageBytes(ctx context.Context, tenantID string) (int64, error) {
+	return r.storageBytes, nil
+}
+
+func intPtr(v int) *int {
+	return &v
+}
+
+func TestQuotaServiceCheckLearnerQuota(t *testing.T) {
+	usage := &quotaUsageRepository{learners: []string{"user-1", "user-2"}}
+
+	tests := []struct {
+		name    string
+		plan    *domain.TenantPlan
+		userID  string
+		wantErr error
+	}{
+		{name: "No plan", plan: nil, userID: "user-3", wantErr: nil},
+		{name: "Unlimited plan", plan: &domain.TenantPlan{}, userID: "user-3", wantErr: nil},
+		{name: "Seat available", plan: &domain.TenantPlan{MaxActiveLearners: intPtr(3)}, userID: "user-3", wantErr: nil},
+		{name: "Limit reached", plan: &domain.TenantPlan{MaxActiveLearners: intPtr(2)}, userID: "user-3", wantErr: ports.ErrLearnerLimitReached},
+		{name: "Existing learner at limit", plan: &domain.TenantPlan{MaxActiveLearners: intPtr(2)}, userID: "user-1", wantErr: nil},
+		{name: "New user without ID at limit", plan: &domain.TenantPlan{MaxActiveLearners: intPtr(2)}, userID: "", wantErr: ports.ErrLearnerLimitReached},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quota := NewQuotaService(&quotaPlanRepository{plan: tt.plan}, usage)
+			if err := quota.CheckLearnerQuota(context.Background(), quotaTenantID, tt.userID); err != tt.wantErr {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestQuotaServiceCheckCourseQuota(t *testing.T) {
+	usage := &quotaUsageRepository{courses: 5}
+
+	tests := []struct {
+		name    string
+		plan    *domain.TenantPlan
+		wantErr error
+	}{
+		{name: "No plan", plan: nil, wantErr: nil},
+		{name: "Unlimited plan", plan: &domain.TenantPlan{}, wantErr: nil},
+		{name: "Course available", plan: &domain.TenantPlan{MaxCourses: intPtr(6)}, wantErr: nil},
+		{name: "Limit reached", plan: &domain.TenantPlan{MaxCourses: intPtr(5)}, wantErr: ports.ErrCourseLimitReached},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quota := NewQuotaService(&quotaPlanRepository{plan: tt.plan}, usage)
+			if err := quota.CheckCourseQuota(context.Background(), quotaTenantID); err != tt.wantErr {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestQuotaServiceCheckStorageQuota(t *testing.T) {
+	usage := &quotaUsageRepository{storageBytes: domain.BytesPerGB - 100}
+	plan := &domain.TenantPlan{MaxStorageGB: intPtr(1)}
+	quota := NewQuotaService(&quotaPlanRepository{plan: plan}, usage)
+
+	if err := quota.CheckStorageQuota(context.Background(), quotaTenantID, 100); err != nil {
+		t.Errorf("Expected a file that fills the storage to fit, got %v", err)
+	}
+	if err := quota.CheckStorageQuota(context.Background(), quotaTenantID, 101); err != ports.ErrStorageLimitReached {
+		t.Errorf("Expected ErrStorageLimitReached, got %v", err)
+	}
+}
+
+func TestQuotaServiceGetTenantUsage(t *testing.T) {
+	usage := &quotaUsageRepository{learners: []string{"user-1"}, courses: 2, storageBytes: 1024}
+	plan := &domain.TenantPlan{MaxCourses: intPtr(10), MaxStorageGB: intPtr(2)}
+	quota := NewQuotaService(&quotaPlanRepository{plan: plan}, usage)
+
+	got, err := quota.GetTenantUsage(context.Background(), quotaTenantID)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if got.ActiveLearners.Used != 1 || got.ActiveLearners.Limit != nil {
+		t.Errorf("Expected 1 active learner without limit, got %+v", got.ActiveLearners)
+	}
+	if got.Courses.Used != 2 || got.Courses.Limit == nil || *got.Courses.Limit != 10 {
+		t.Errorf("Expected 2 of 10 courses, got %+v", got.Courses)
+	}
+	if got.StorageBytes.Used != 1024 || got.StorageBytes.Limit == nil || *got.StorageBytes.Limit != 2*domain.BytesPerGB {
+		t.Errorf("Expected 1024 of %d storage bytes, got %+v", 2*domain.BytesPerGB, got.StorageBytes)
+	}
+}
@@ -0,0 +1,44 @@
+package services
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/DanielIturra1610/stegmaier-landing/internal/shared/database"
+)
+
+// schemaDriftConcurrency is the number of tenant databases checked at the same time
+const schemaDriftConcurrency = 4
+
+// CheckSchemaDrift compares the migration version, dirty flag, tables and indexes of every
+// active tenant against the tenant migrations, and keeps the report for LastSchemaDriftReport
+// (superadmin only)
+func (s *TenantService) CheckSchemaDrift() (*database.TenantDriftReport, error) {
+	tenants, err := s.manager.ListActiveTenants()
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := s.migrationRunner.CheckTenantDrift(tenants, "migrations/tenants", schemaDriftConcurrency)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check tenant schema drift: %w", err)
+	}
+
+	s.driftMutex.Lock()
+	s.lastDriftReport = report
+	s.driftMutex.Unlock()
+
+	if drifted := report.Summary.Drifted(); drifted > 0 {
+		log.Printf("⚠️  [TenantService] %d of %d tenants are not up to date with migration %d (behind: %d, dirty: %d, schema drift: %d, failed: %d)",
+			drifted, report.Summary.Total, report.LatestVersion, report.Summary.Behind, report.Summary.Dirty,
+			report.Summary.SchemaDrift, report.Summary.Failed)
+	}
+	return report, nil
+}
+
+// LastSchemaDriftReport returns the report of the last schema drift check, or nil before the first one
+func (s *TenantService) LastSchemaDriftReport() *database.TenantDriftReport {
+	s.driftMutex.RLock()
+	defer s.driftMutex.RUnlock()
+	return s.lastDriftReport
+}
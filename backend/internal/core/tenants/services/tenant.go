@@ -4,8 +4,10 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/domain"
@@ -20,28 +22,50 @@ import (
 // TenantService handles business logic for tenant operations
 type TenantService struct {
 	repo            ports.TenantRepository
+	archiveRepo     ports.TenantArchiveRepository
+	blobStorage     ports.ArchiveBlobStorage
+	quota           *QuotaService
 	manager         *database.Manager
 	migrationRunner *database.MigrationRunner
 	jwtService      *tokens.JWTService
 	userService     userports.UserManagementService
+	resolver        ports.DNSResolver
+	memberImport    MemberImportDependencies
 	validator       *validator.Validate
+	// Time between scheduling the deletion of a tenant and dropping its database
+	deletionGracePeriod time.Duration
+	// Last schema drift check of the tenant databases
+	driftMutex      sync.RWMutex
+	lastDriftReport *database.TenantDriftReport
 }
 
 // NewTenantService creates a new tenant service
 func NewTenantService(
 	repo ports.TenantRepository,
+	archiveRepo ports.TenantArchiveRepository,
+	blobStorage ports.ArchiveBlobStorage,
+	quota *QuotaService,
 	manager *database.Manager,
 	migrationRunner *database.MigrationRunner,
 	jwtService *tokens.JWTService,
 	userService userports.UserManagementService,
+	memberImport MemberImportDependencies,
+	deletionGracePeriod time.Duration,
 ) *TenantService {
 	return &TenantService{
 		repo:            repo,
+		archiveRepo:     archiveRepo,
+		blobStorage:     blobStorage,
+		quota:           quota,
 		manager:         manager,
 		migrationRunner: migrationRunner,
 		jwtService:      jwtService,
 		userService:     userService,
+		resolver:        net.DefaultResolver,
+		memberImport:    memberImport,
 		validator:       validator.New(),
+
+		deletionGracePeriod: deletionGracePeriod,
 	}
 }
 
@@ -49,27 +73,8 @@ func NewTenantService(
 func (s *TenantService) CreateTenant(ctx context.Context, dto *domain.CreateTenantDTO, ownerID string) (*domain.CreateTenantResponse, error) {
 	log.Printf("🏢 [TenantService] Starting tenant creation: name=%s, slug=%s, owner=%s", dto.Name, dto.Slug, ownerID)
 
-	// Validate DTO
-	if err := s.validator.Struct(dto); err != nil {
-		log.Printf("❌ [TenantService] Validation error: %v", err)
-		return nil, fmt.Errorf("validation error: %w", err)
-	}
-
-	// Validate slug format (alphanumeric, lowercase, hyphens allowed)
-	if !isValidSlug(dto.Slug) {
-		log.Printf("❌ [TenantService] Invalid slug format: %s", dto.Slug)
-		return nil, fmt.Errorf("slug must contain only lowercase letters, numbers, and hyphens")
-	}
-
-	// Check if tenant with slug already exists
-	exists, err := s.repo.TenantExistsBySlug(ctx, dto.Slug)
-	if err != nil {
-		log.Printf("❌ [TenantService] Error checking tenant existence: %v", err)
-		return nil, fmt.Errorf("failed to check tenant existence: %w", err)
-	}
-	if exists {
-		log.Printf("❌ [TenantService] Tenant with slug '%s' already exists", dto.Slug)
-		return nil, fmt.Errorf("tenant with slug '%s' already exists", dto.Slug)
+	if err := s.validateNewTenant(ctx, dto); err != nil {
+		return nil, err
 	}
 
 	// Generate database name from slug
@@ -119,6 +124,34 @@ func (s *TenantService) CreateTenant(ctx context.Context, dto *domain.CreateTena
 	}, nil
 }
 
+// validateNewTenant validates the data of a new tenant and checks that its slug is free
+func (s *TenantService) validateNewTenant(ctx context.Context, dto *domain.CreateTenantDTO) error {
+	// Validate DTO
+	if err := s.validator.Struct(dto); err != nil {
+		log.Printf("❌ [TenantService] Validation error: %v", err)
+		return fmt.Errorf("validation error: %w", err)
+	}
+
+	// Validate slug format (alphanumeric, lowercase, hyphens allowed)
+	if !isValidSlug(dto.Slug) {
+		log.Printf("❌ [TenantService] Invalid slug format: %s", dto.Slug)
+		return fmt.Errorf("slug must contain only lowercase letters, numbers, and hyphens")
+	}
+
+	// Check if tenant with slug already exists
+	exists, err := s.repo.TenantExistsBySlug(ctx, dto.Slug)
+	if err != nil {
+		log.Printf("❌ [TenantService] Error checking tenant existence: %v", err)
+		return fmt.Errorf("failed to check tenant existence: %w", err)
+	}
+	if exists {
+		log.Printf("❌ [TenantService] Tenant with slug '%s' already exists", dto.Slug)
+		return fmt.Errorf("tenant with slug '%s' already exists", dto.Slug)
+	}
+
+	return nil
+}
+
 // GetUserTenants retrieves all tenants for a user
 func (s *TenantService) GetUserTenants(ctx context.Context, userID string) ([]*domain.TenantWithMembership, error) {
 	tenants, err := s.repo.GetUserTenants(ctx, userID)
@@ -200,6 +233,13 @@ func (s *TenantService) InviteUser(ctx context.Context, dto *domain.InviteUserDT
 		return nil, fmt.Errorf("only admins can invite users to the tenant")
 	}
 
+	// Students take a learner seat of the tenant plan
+	if dto.Role == "student" {
+		if err := s.quota.CheckLearnerQuota(ctx, tenantID, ""); err != nil {
+			return nil, err
+		}
+	}
+
 	// TODO: Find user by email (requires user repository)
 	// For now, this would be implemented by the controller layer
 	// which has access to the user service
@@ -244,6 +284,16 @@ func (s *TenantService) CreateUserInTenant(ctx context.Context, dto *domain.Crea
 
 	log.Printf("✅ Role validation passed: tenant admin creating user with roles %v", roles)
 
+	// Students take a learner seat of the tenant plan
+	for _, role := range roles {
+		if role == "student" {
+			if err := s.quota.CheckLearnerQuota(ctx, tenantID, ""); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+
 	// Create user using UserManagementService
 	userDTO := &userdomain.CreateUserDTO{
 		Email:    dto.Email,
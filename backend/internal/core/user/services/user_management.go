@@ -19,6 +19,7 @@ type UserManagementService struct {
 	authRepo       authports.AuthRepository
 	userRepo       ports.UserRepository
 	passwordHasher hasher.PasswordHasher
+	passwordPolicy authports.PasswordPolicyService
 }
 
 // NewUserManagementService creates a new user management service
@@ -26,11 +27,13 @@ func NewUserManagementService(
 	authRepo authports.AuthRepository,
 	userRepo ports.UserRepository,
 	passwordHasher hasher.PasswordHasher,
+	passwordPolicy authports.PasswordPolicyService,
 ) ports.UserManagementService {
 	return &UserManagementService{
 		authRepo:       authRepo,
 		userRepo:       userRepo,
 		passwordHasher: passwordHasher,
+		passwordPolicy: passwordPolicy,
 	}
 }
 
@@ -51,6 +54,12 @@ func (s *UserManagementService) CreateUser(ctx context.Context, dto *domain.Crea
 		return nil, authports.ErrUserAlreadyExists
 	}
 
+	// Enforce the tenant password policy
+	if err := s.passwordPolicy.ValidatePassword(ctx, dto.TenantID, "", dto.Password); err != nil {
+		log.Printf("⚠️  Password rejected for %s: %v", dto.Email, err)
+		return nil, err
+	}
+
 	// Hash password
 	hashedPassword, err := s.passwordHasher.Hash(dto.Password)
 	if err != nil {
@@ -85,6 +94,10 @@ func (s *UserManagementService) CreateUser(ctx context.Context, dto *domain.Crea
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
 
+	if err := s.passwordPolicy.RecordPasswordChange(ctx, user.ID, user.PasswordHash); err != nil {
+		log.Printf("⚠️  %v", err)
+	}
+
 	log.Printf("✅ User created successfully: %s (%s)", user.Email, user.ID)
 	return user, nil
 }
@@ -341,6 +354,16 @@ func (s *UserManagementService) ResetUserPassword(ctx context.Context, userID st
 		return authports.ErrUserNotFound
 	}
 
+	// Enforce the tenant password policy
+	tenantID := ""
+	if user.TenantID != nil {
+		tenantID = *user.TenantID
+	}
+	if err := s.passwordPolicy.ValidatePassword(ctx, tenantID, user.ID, dto.NewPassword); err != nil {
+		log.Printf("⚠️  Password rejected for user %s: %v", userID, err)
+		return err
+	}
+
 	// Hash new password
 	hashedPassword, err := s.passwordHasher.Hash(dto.NewPassword)
 	if err != nil {
@@ -357,24 +380,31 @@ func (s *UserManagementService) ResetUserPassword(ctx context.Context, userID st
 		return fmt.Errorf("failed to reset password: %w", err)
 	}
 
+	if err := s.passwordPolicy.RecordPasswordChange(ctx, user.ID, user.PasswordHash); err != nil {
+		log.Printf("⚠️  %v", err)
+	}
+
 	log.Printf("✅ Password reset successfully for user: %s", userID)
 	return nil
 }
 
-// ForcePasswordChange forces user to change password on next login
+// ForcePasswordChange forces user to change password on next login.
+// Until then the user can only change the password.
 func (s *UserManagementService) ForcePasswordChange(ctx context.Context, userID string) error {
 	log.Printf("🔒 UserManagementService: Forcing password change for user %s", userID)
 
-	// For now, this could be implemented by setting a flag in the user entity
-	// Or by invalidating all existing tokens
-	// This is a placeholder implementation
-
-	user, err := s.authRepo.GetUserByID(ctx, userID)
-	if err != nil {
-		return authports.ErrUserNotFound
+	if err := s.authRepo.SetMustChangePassword(ctx, userID, true); err != nil {
+		if err == authports.ErrUserNotFound {
+			return err
+		}
+		log.Printf("❌ Failed to force password change: %v", err)
+		return fmt.Errorf("failed to force password change: %w", err)
 	}
 
-	user.UpdatedAt = time.Now()
+	// Revoke all sessions so that the flag applies right away
+	if err := s.authRepo.RevokeAllUserRefreshTokens(ctx, userID); err != nil {
+		log.Printf("⚠️  Failed to revoke sessions: %v", err)
+	}
 
 	log.Printf("✅ Password change forced for user: %s", userID)
 	return nil
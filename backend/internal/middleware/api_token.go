@@ -0,0 +1,132 @@
+package middleware
+
+import (
+	"log"
+	"strings"
+
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/auth/domain"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/auth/ports"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/shared/tokens"
+	"github.com/gofiber/fiber/v2"
+)
+
+// APITokenKey is the context key of the personal access token or API key that authenticated the request
+const APITokenKey = "apiToken"
+
+// apiTokenResourceAliases maps route segments to the resource scope that covers them
+var apiTokenResourceAliases = map[string]string{
+	"enrollment-requests": "enrollments",
+}
+
+// RequiredScope returns the API token scope needed for a request, or "" when the route
+// cannot be reached with an API token (authentication, impersonation, tenant and platform management).
+// The resource is the first path segment after /api/v1/ (or /api/v1/admin/) and GET or
+// HEAD requests need read access while any other method needs write access.
+func RequiredScope(method string, path string) string {
+	// Acting as another user needs an interactive admin login
+	if strings.HasSuffix(strings.TrimSuffix(path, "/"), "/impersonate") {
+		return ""
+	}
+
+	path = strings.TrimPrefix(path, "/api/v1/")
+	path = strings.TrimPrefix(path, "admin/")
+
+	resource, _, _ := strings.Cut(path, "/")
+	if alias, ok := apiTokenResourceAliases[resource]; ok {
+		resource = alias
+	}
+
+	action := "write"
+	if method == fiber.MethodGet || method == fiber.MethodHead {
+		action = "read"
+	}
+
+	scope := resource + ":" + action
+	if !domain.IsValidAPITokenScope(scope) {
+		return ""
+	}
+	return scope
+}
+
+// authenticateAPIToken authenticates a request made with a personal access token or tenant API key.
+// The token acts as the user who created it, with that user's role in the token's tenant,
+// and only on the routes covered by its scopes.
+func authenticateAPIToken(c *fiber.Ctx, authRepo ports.AuthRepository, token string) error {
+	apiToken, err := authRepo.GetAPITokenByHash(c.Context(), domain.HashAPIToken(token))
+	if err != nil || !apiToken.IsValid() {
+		log.Printf("⚠️  API token rejected: %s %s", c.Method(), c.Path())
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid, expired or revoked API token",
+		})
+	}
+
+	scope := RequiredScope(c.Method(), c.Path())
+	if scope == "" || !apiToken.HasScope(scope) {
+		log.Printf("⚠️  API token %s lacks scope for %s %s", apiToken.ID, c.Method(), c.Path())
+		message := "This endpoint is not available to API tokens"
+		if scope != "" {
+			message = "API token is missing the required scope: " + scope
+		}
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"success": false,
+			"error":   message,
+		})
+	}
+
+	// API tokens are bound to their tenant
+	if tenantID := c.Get("X-Tenant-ID"); tenantID != "" && tenantID != apiToken.TenantID {
+		log.Printf("⚠️  API token %s used for tenant %s, bound to %s", apiToken.ID, tenantID, apiToken.TenantID)
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"success": false,
+			"error":   "API token is not valid for this tenant",
+		})
+	}
+
+	user, err := authRepo.GetUserByID(c.Context(), apiToken.UserID)
+	if err != nil {
+		log.Printf("⚠️  User not found for API token %s: %v", apiToken.ID, err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"error":   "User not found or inactive",
+		})
+	}
+
+	if mustChangePassword(c, user) {
+		return passwordChangeRequired(c, user)
+	}
+
+	membership, err := authRepo.GetActiveMembership(c.Context(), user.ID, apiToken.TenantID)
+	if err != nil || membership == nil {
+		log.Printf("⚠️  No active membership for API token %s in tenant %s", apiToken.ID, apiToken.TenantID)
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"success": false,
+			"error":   "Access denied - you don't have access to this organization",
+		})
+	}
+
+	if err := authRepo.RecordAPITokenUse(c.Context(), apiToken.ID, c.IP()); err != nil {
+		log.Printf("⚠️  Failed to record use of API token %s: %v", apiToken.ID, err)
+	}
+
+	// Make TenantMiddleware resolve the token's tenant
+	c.Request().Header.Set("X-Tenant-ID", apiToken.TenantID)
+
+	c.Locals(UserIDKey, user.ID)
+	c.Locals(UserEmailKey, user.Email)
+	c.Locals(UserRoleKey, membership.Role)
+	c.Locals(TenantIDKey, apiToken.TenantID)
+	c.Locals(JWTClaimsKey, &tokens.Claims{
+		UserID:     user.ID,
+		TenantID:   apiToken.TenantID,
+		Email:      user.Email,
+		Role:       membership.Role,
+		ActiveRole: membership.Role,
+		Roles:      []string{membership.Role},
+	})
+	c.Locals(APITokenKey, apiToken)
+
+	log.Printf("✅ Authenticated API token: %s (%s) for user %s - Role: %s", apiToken.ID, apiToken.Type, user.ID, membership.Role)
+
+	return c.Next()
+}
@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestRequiredScope(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		expected string
+	}{
+		{"List courses", fiber.MethodGet, "/api/v1/courses", "courses:read"},
+		{"Get course", fiber.MethodGet, "/api/v1/courses/123", "courses:read"},
+		{"Create course", fiber.MethodPost, "/api/v1/courses", "courses:write"},
+		{"Head lessons", fiber.MethodHead, "/api/v1/lessons/1", "lessons:read"},
+		{"Update enrollment", fiber.MethodPut, "/api/v1/enrollments/1", "enrollments:write"},
+		{"Enrollment requests alias", fiber.MethodGet, "/api/v1/enrollment-requests/me", "enrollments:read"},
+		{"Admin users", fiber.MethodDelete, "/api/v1/admin/users/1", "users:write"},
+		{"Auth routes are not available", fiber.MethodGet, "/api/v1/auth/me", ""},
+		{"Impersonation is not available", fiber.MethodPost, "/api/v1/admin/users/1/impersonate", ""},
+		{"Token management is not available", fiber.MethodPost, "/api/v1/auth/tokens", ""},
+		{"Admin security is not available", fiber.MethodGet, "/api/v1/admin/security/api-keys", ""},
+		{"Tenant routes are not available", fiber.MethodGet, "/api/v1/tenants", ""},
+		{"Superadmin routes are not available", fiber.MethodGet, "/api/v1/superadmin/tenants", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := RequiredScope(tt.method, tt.path); result != tt.expected {
+				t.Errorf("RequiredScope(%s, %s) = %q, want %q", tt.method, tt.path, result, tt.expected)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"log"
 	"strings"
 
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/auth/domain"
 	"github.com/DanielIturra1610/stegmaier-landing/internal/core/auth/ports"
 	"github.com/DanielIturra1610/stegmaier-landing/internal/shared/tokens"
 	"github.com/gofiber/fiber/v2"
@@ -17,6 +18,29 @@ const (
 	JWTClaimsKey = "jwtClaims"
 )
 
+// passwordChangeRoutes are the routes still available to users who must change their password
+var passwordChangeRoutes = map[string]bool{
+	"/api/v1/auth/change-password":    true,
+	"/api/v1/profile/change-password": true,
+	"/api/v1/auth/logout":             true,
+	"/api/v1/auth/me":                 true,
+}
+
+// mustChangePassword checks if the request must be rejected because the user has to change
+// the password first, either forced by an admin or after the tenant maximum password age
+func mustChangePassword(c *fiber.Ctx, user *domain.User) bool {
+	return user.MustChangePassword && !passwordChangeRoutes[c.Path()]
+}
+
+// passwordChangeRequired rejects a request of a user who must change the password first
+func passwordChangeRequired(c *fiber.Ctx, user *domain.User) error {
+	log.Printf("⚠️  Password change required for user %s: %s %s", user.ID, c.Method(), c.Path())
+	return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+		"success": false,
+		"error":   "Password change required",
+	})
+}
+
 // AuthMiddleware validates JWT tokens and injects user information into context
 func AuthMiddleware(tokenService tokens.TokenService, authRepo ports.AuthRepository) fiber.Handler {
 	return func(c *fiber.Ctx) error {
@@ -40,6 +64,11 @@ func AuthMiddleware(tokenService tokens.TokenService, authRepo ports.AuthReposit
 			})
 		}
 
+		// Personal access tokens and tenant API keys are opaque, not JWTs
+		if domain.IsAPIToken(token) {
+			return authenticateAPIToken(c, authRepo, token)
+		}
+
 		// Validate JWT token
 		claims, err := tokenService.Validate(token)
 		if err != nil {
@@ -69,6 +98,10 @@ func AuthMiddleware(tokenService tokens.TokenService, authRepo ports.AuthReposit
 			})
 		}
 
+		if mustChangePassword(c, user) {
+			return passwordChangeRequired(c, user)
+		}
+
 		// Inject user information into context
 		c.Locals(UserIDKey, user.ID)
 		c.Locals(UserEmailKey, user.Email)
@@ -90,6 +123,12 @@ func AuthMiddleware(tokenService tokens.TokenService, authRepo ports.AuthReposit
 		}
 		c.Locals(JWTClaimsKey, claims)
 
+		// An admin acting as the user, see StartImpersonation
+		if claims.ImpersonatorID != "" {
+			log.Printf("✅ Authenticated user: %s (%s) - Role: %s, impersonated by %s", user.Email, user.ID, roleToUse, claims.ImpersonatorID)
+			return authenticateImpersonation(c, authRepo, claims)
+		}
+
 		log.Printf("✅ Authenticated user: %s (%s) - Role: %s (JWT role: %s)", user.Email, user.ID, roleToUse, claims.Role)
 
 		return c.Next()
@@ -121,6 +160,11 @@ func OptionalAuthMiddleware(tokenService tokens.TokenService, authRepo ports.Aut
 			return c.Next()
 		}
 
+		// Impersonation tokens are only accepted where their audit trail is recorded
+		if claims.ImpersonatorID != "" {
+			return c.Next()
+		}
+
 		// Verify user exists
 		user, err := authRepo.GetUserByID(c.Context(), claims.UserID)
 		if err != nil {
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"fmt"
+	"log"
+
+	featureports "github.com/DanielIturra1610/stegmaier-landing/internal/core/features/ports"
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequireFeature checks if a feature flag is on for the tenant of the request, so that a
+// subsystem can be rolled out to one tenant at a time.
+// This middleware must be used AFTER TenantMiddleware since it relies on TenantIDKey
+//
+// Usage:
+//
+//	reviews.Use(middleware.RequireFeature(featureService, featuredomain.FeatureReviews))
+func RequireFeature(features featureports.FeatureFlagService, key string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		tenantID, ok := c.Locals(TenantIDKey).(string)
+		if !ok || tenantID == "" {
+			log.Printf("⚠️  RequireFeature: tenant_id not found in context")
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"message": "Bad Request - tenant not specified",
+			})
+		}
+
+		enabled, err := features.IsEnabled(c.Context(), tenantID, key)
+		if err != nil {
+			log.Printf("❌ RequireFeature: failed to check feature %s for tenant %s: %v", key, tenantID, err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"success": false,
+				"message": "Internal error - failed to check feature",
+			})
+		}
+
+		if !enabled {
+			log.Printf("🚫 RequireFeature: feature %s is off for tenant %s (%s %s)", key, tenantID, c.Method(), c.Path())
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"success": false,
+				"message": fmt.Sprintf("Feature %s is not enabled for this tenant", key),
+			})
+		}
+
+		return c.Next()
+	}
+}
@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	featureports "github.com/DanielIturra1610/stegmaier-landing/internal/core/features/ports"
+	"github.com/gofiber/fiber/v2"
+)
+
+// stubFeatureService reports the flags in enabled as on; the other methods are not used
+type stubFeatureService struct {
+	featureports.FeatureFlagService
+	enabled map[string]bool
+}
+
+func (s *stubFeatureService) IsEnabled(ctx context.Context, tenantID, key string) (bool, error) {
+	return s.enabled[tenantID+":"+key], nil
+}
+
+func TestRequireFeature(t *testing.T) {
+	features := &stubFeatureService{enabled: map[string]bool{"tenant-a:reviews": true}}
+
+	tests := []struct {
+		name         string
+		tenantID     string
+		expectStatus int
+	}{
+		{name: "Feature on for tenant", tenantID: "tenant-a", expectStatus: fiber.StatusOK},
+		{name: "Feature off for tenant", tenantID: "tenant-b", expectStatus: fiber.StatusForbidden},
+		{name: "No tenant", tenantID: "", expectStatus: fiber.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Use(func(c *fiber.Ctx) error {
+				if tt.tenantID != "" {
+					c.Locals(TenantIDKey, tt.tenantID)
+				}
+				return c.Next()
+			})
+			app.Get("/reviews", RequireFeature(features, "reviews"), func(c *fiber.Ctx) error {
+				return c.SendStatus(fiber.StatusOK)
+			})
+
+			resp, err := app.Test(httptest.NewRequest("GET", "/reviews", nil))
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			if resp.StatusCode != tt.expectStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectStatus, resp.StatusCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package middleware
+
+import (
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/auth/domain"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/auth/ports"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/shared/tokens"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// Context keys of requests made by an admin impersonating a user
+const (
+	ImpersonatorIDKey         = "impersonatorID"
+	ImpersonationSessionIDKey = "impersonationSessionID"
+)
+
+// impersonationStopPath ends the session; the service records it in the audit trail
+const impersonationStopPath = "/api/v1/auth/impersonation/stop"
+
+// impersonationBlockedPrefixes are the account security routes that only the user may change.
+// Reading them is allowed; any other method is rejected while impersonating.
+var impersonationBlockedPrefixes = []string{
+	"/api/v1/auth/change-password",
+	"/api/v1/profile/change-password",
+	"/api/v1/auth/profile",
+	"/api/v1/auth/revoke-sessions",
+	"/api/v1/auth/sessions",
+	"/api/v1/auth/tokens",
+	"/api/v1/auth/mfa",
+	"/api/v1/auth/webauthn",
+	"/api/v1/auth/switch-role",
+}
+
+// IsBlockedWhileImpersonating checks if a request would change the account security of the
+// impersonated user (password, sessions, tokens, second factors) or start another impersonation
+func IsBlockedWhileImpersonating(method string, path string) bool {
+	if method == fiber.MethodGet || method == fiber.MethodHead {
+		return false
+	}
+
+	path = strings.TrimSuffix(path, "/")
+	if strings.HasSuffix(path, "/impersonate") {
+		return true
+	}
+	for _, prefix := range impersonationBlockedPrefixes {
+		if path == prefix || strings.HasPrefix(path, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// authenticateImpersonation checks the session of an impersonation token, blocks destructive
+// actions and records the request in the audit trail once it has been handled
+func authenticateImpersonation(c *fiber.Ctx, authRepo ports.AuthRepository, claims *tokens.Claims) error {
+	session, err := authRepo.GetImpersonationSessionByTokenID(c.Context(), claims.ID)
+	if err != nil || !session.IsActive() || session.TargetUserID == nil || *session.TargetUserID != claims.UserID {
+		log.Printf("⚠️  Impersonation token rejected for user %s (impersonator %s)", claims.UserID, claims.ImpersonatorID)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"error":   "Impersonation session has ended",
+		})
+	}
+
+	// Impersonation tokens are bound to their tenant
+	if tenantID := c.Get("X-Tenant-ID"); tenantID != "" && tenantID != session.TenantID {
+		log.Printf("⚠️  Impersonation session %s used for tenant %s, bound to %s", session.ID, tenantID, session.TenantID)
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"success": false,
+			"error":   "Impersonation session is not valid for this tenant",
+		})
+	}
+	c.Request().Header.Set("X-Tenant-ID", session.TenantID)
+
+	c.Locals(ImpersonatorIDKey, claims.ImpersonatorID)
+	c.Locals(ImpersonationSessionIDKey, session.ID)
+
+	var handlerErr error
+	if IsBlockedWhileImpersonating(c.Method(), c.Path()) {
+		log.Printf("⚠️  Blocked %s %s during impersonation session %s", c.Method(), c.Path(), session.ID)
+		handlerErr = c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"success": false,
+			"error":   "This action is not allowed while impersonating a user",
+		})
+	} else {
+		handlerErr = c.Next()
+	}
+
+	recordImpersonationRequest(c, authRepo, session.ID, handlerErr)
+
+	return handlerErr
+}
+
+// recordImpersonationRequest adds the handled request to the impersonation audit trail
+func recordImpersonationRequest(c *fiber.Ctx, authRepo ports.AuthRepository, sessionID string, handlerErr error) {
+	if c.Path() == impersonationStopPath {
+		return
+	}
+
+	statusCode := c.Response().StatusCode()
+	var fiberErr *fiber.Error
+	if errors.As(handlerErr, &fiberErr) {
+		statusCode = fiberErr.Code
+	}
+
+	event := &domain.ImpersonationEvent{
+		ID:         uuid.New().String(),
+		SessionID:  sessionID,
+		Type:       domain.ImpersonationRequest,
+		Method:     c.Method(),
+		Path:       c.Path(),
+		StatusCode: statusCode,
+		IPAddress:  c.IP(),
+		CreatedAt:  time.Now(),
+	}
+	if err := authRepo.RecordImpersonationEvent(c.Context(), event); err != nil {
+		log.Printf("⚠️  Failed to record request of impersonation session %s: %v", sessionID, err)
+	}
+}
@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestIsBlockedWhileImpersonating(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		expected bool
+	}{
+		{"Change password", fiber.MethodPost, "/api/v1/auth/change-password", true},
+		{"Change password from profile", fiber.MethodPost, "/api/v1/profile/change-password", true},
+		{"Update account email", fiber.MethodPut, "/api/v1/auth/profile", true},
+		{"Revoke a session", fiber.MethodDelete, "/api/v1/auth/sessions/123", true},
+		{"Create personal access token", fiber.MethodPost, "/api/v1/auth/tokens", true},
+		{"Disable MFA", fiber.MethodPost, "/api/v1/auth/mfa/disable", true},
+		{"Register passkey", fiber.MethodPost, "/api/v1/auth/webauthn/register/begin", true},
+		{"Nested impersonation", fiber.MethodPost, "/api/v1/admin/users/123/impersonate", true},
+		{"Trailing slash", fiber.MethodPost, "/api/v1/auth/change-password/", true},
+		{"View sessions", fiber.MethodGet, "/api/v1/auth/sessions", false},
+		{"View MFA status", fiber.MethodGet, "/api/v1/auth/mfa", false},
+		{"Current user", fiber.MethodGet, "/api/v1/auth/me", false},
+		{"Stop impersonation", fiber.MethodPost, "/api/v1/auth/impersonation/stop", false},
+		{"Course progress", fiber.MethodPost, "/api/v1/progress/lessons/1/complete", false},
+		{"Similar prefix", fiber.MethodPost, "/api/v1/auth/tokens-info", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := IsBlockedWhileImpersonating(tt.method, tt.path); result != tt.expected {
+				t.Errorf("IsBlockedWhileImpersonating(%s, %s) = %v, want %v", tt.method, tt.path, result, tt.expected)
+			}
+		})
+	}
+}
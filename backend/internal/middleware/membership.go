@@ -6,8 +6,18 @@ import (
 	"fmt"
 	"log"
 
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/auth/domain"
 	"github.com/gofiber/fiber/v2"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// Context keys set from the membership of the user in the tenant
+const (
+	// MembershipRoleKey holds the role of the membership (admin, instructor, student)
+	MembershipRoleKey = "membership_role"
+	// MembershipPermissionsKey holds the permissions resolved from the membership role and custom role
+	MembershipPermissionsKey = "membership_permissions"
 )
 
 // MembershipMiddleware validates that the authenticated user has an active membership in the selected tenant
@@ -71,8 +81,9 @@ func MembershipMiddleware(controlDB *sqlx.DB) fiber.Handler {
 		}
 
 		// Inject membership info into context for use by handlers
-		c.Locals("membership_role", membership.Role)
+		c.Locals(MembershipRoleKey, membership.Role)
 		c.Locals("membership_status", membership.Status)
+		c.Locals(MembershipPermissionsKey, domain.ResolvePermissions(membership.Role, membership.CustomPermissions))
 
 		log.Printf("✅ MembershipMiddleware: User %s has active %s membership in tenant %s", userIDStr, membership.Role, tenantIDStr)
 
@@ -87,6 +98,9 @@ type TenantMembership struct {
 	TenantID string `db:"tenant_id"`
 	Role     string `db:"role"`   // admin, instructor, student
 	Status   string `db:"status"` // pending, active, inactive, rejected
+	// Custom role defined by the tenant, adding permissions to Role
+	CustomRoleID      *string        `db:"custom_role_id"`
+	CustomPermissions pq.StringArray `db:"custom_permissions"`
 }
 
 // checkMembership queries the database to check if a user has membership in a tenant
@@ -94,9 +108,11 @@ func checkMembership(ctx context.Context, controlDB *sqlx.DB, userID, tenantID s
 	var membership TenantMembership
 
 	query := `
-		SELECT id, user_id, tenant_id, role, status
-		FROM tenant_memberships
-		WHERE user_id = $1 AND tenant_id = $2
+		SELECT m.id, m.user_id, m.tenant_id, m.role, m.status, m.custom_role_id,
+		       COALESCE(r.permissions, '{}') AS custom_permissions
+		FROM tenant_memberships m
+		LEFT JOIN tenant_roles r ON r.id = m.custom_role_id
+		WHERE m.user_id = $1 AND m.tenant_id = $2
 		LIMIT 1
 	`
 
@@ -133,8 +149,11 @@ func OptionalMembershipMiddleware(controlDB *sqlx.DB) fiber.Handler {
 		// Try to get membership, but continue even if it fails
 		membership, err := checkMembership(c.Context(), controlDB, userIDStr, tenantIDStr)
 		if err == nil {
-			c.Locals("membership_role", membership.Role)
+			c.Locals(MembershipRoleKey, membership.Role)
 			c.Locals("membership_status", membership.Status)
+			if membership.Status == "active" {
+				c.Locals(MembershipPermissionsKey, domain.ResolvePermissions(membership.Role, membership.CustomPermissions))
+			}
 			log.Printf("✅ OptionalMembershipMiddleware: User %s has %s membership (status: %s) in tenant %s", userIDStr, membership.Role, membership.Status, tenantIDStr)
 		} else {
 			log.Printf("⚠️  OptionalMembershipMiddleware: No membership found for user %s in tenant %s", userIDStr, tenantIDStr)
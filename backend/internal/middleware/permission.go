@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/auth/domain"
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequirePermission checks if the tenant membership of the authenticated user grants a permission,
+// either through its built-in role or the custom role assigned by the tenant. Superadmins are
+// always allowed.
+// This middleware must be used AFTER MembershipMiddleware since it relies on MembershipPermissionsKey
+//
+// Usage:
+//
+//	assignments.Post("/submissions/:submissionId/grade", middleware.RequirePermission(domain.PermissionSubmissionGrade), handler)
+func RequirePermission(permission domain.Permission) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userRole, err := GetAuthenticatedUserRole(c)
+		if err != nil {
+			log.Printf("⚠️  Permission: No authenticated user role found: %v", err)
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"success": false,
+				"error":   "Authentication required for this resource",
+			})
+		}
+
+		if HasPermission(c, permission) {
+			return c.Next()
+		}
+
+		log.Printf("🚫 Permission: User role '%s' denied access to %s %s (requires permission %s)",
+			userRole, c.Method(), c.Path(), permission)
+
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"success": false,
+			"error":   fmt.Sprintf("Insufficient permissions. Required permission: %s", permission),
+		})
+	}
+}
+
+// HasPermission checks if the tenant membership of the user grants a permission (used in handlers)
+func HasPermission(c *fiber.Ctx, permission domain.Permission) bool {
+	if userRole, err := GetAuthenticatedUserRole(c); err == nil && userRole == string(domain.RoleSuperAdmin) {
+		return true
+	}
+
+	permissions, ok := c.Locals(MembershipPermissionsKey).([]string)
+	if !ok {
+		return false
+	}
+
+	for _, granted := range permissions {
+		if granted == string(permission) {
+			return true
+		}
+	}
+	return false
+}
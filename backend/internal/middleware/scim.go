@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/auth/domain"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/auth/ports"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/shared/database"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/shared/tokens"
+	"github.com/gofiber/fiber/v2"
+)
+
+// scimErrorSchema is the schema of SCIM error responses (RFC 7644, section 3.12)
+const scimErrorSchema = "urn:ietf:params:scim:api:messages:2.0:Error"
+
+// scimContentType is the media type of SCIM responses
+const scimContentType = "application/scim+json"
+
+// SCIMAuthMiddleware authenticates the requests of identity providers to the SCIM endpoints.
+// They use a tenant API key with the scim:read scope for GET and HEAD requests and scim:write
+// for the others. The key provisions the members of its tenant while the admin who created it
+// is still an active admin of the tenant. The routes don't go through TenantMiddleware, so
+// requests for suspended tenants and tenants pending deletion are rejected here.
+func SCIMAuthMiddleware(authRepo ports.AuthRepository, dbManager *database.Manager) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		token, err := tokens.ExtractTokenFromHeader(c.Get("Authorization"))
+		if err != nil || !strings.HasPrefix(token, domain.APIKeyPrefix) {
+			return scimAuthError(c, fiber.StatusUnauthorized, "A tenant API key is required")
+		}
+
+		apiToken, err := authRepo.GetAPITokenByHash(c.Context(), domain.HashAPIToken(token))
+		if err != nil || apiToken.Type != domain.APITokenTenant || !apiToken.IsValid() {
+			log.Printf("⚠️  SCIM API key rejected: %s %s", c.Method(), c.Path())
+			return scimAuthError(c, fiber.StatusUnauthorized, "Invalid, expired or revoked API key")
+		}
+
+		scope := "scim:write"
+		if c.Method() == fiber.MethodGet || c.Method() == fiber.MethodHead {
+			scope = "scim:read"
+		}
+		if !apiToken.HasScope(scope) {
+			log.Printf("⚠️  API key %s lacks scope for %s %s", apiToken.ID, c.Method(), c.Path())
+			return scimAuthError(c, fiber.StatusForbidden, "API key is missing the required scope: "+scope)
+		}
+
+		membership, err := authRepo.GetActiveMembership(c.Context(), apiToken.UserID, apiToken.TenantID)
+		if err != nil || membership == nil || membership.Role != "admin" {
+			log.Printf("⚠️  Creator of API key %s is no longer an admin of tenant %s", apiToken.ID, apiToken.TenantID)
+			return scimAuthError(c, fiber.StatusForbidden, "The API key was created by a user who is no longer an admin of the organization")
+		}
+
+		tenantInfo, err := getTenantInfo(dbManager, apiToken.TenantID)
+		if err != nil {
+			log.Printf("❌ Tenant of API key %s not found: %s - %v", apiToken.ID, apiToken.TenantID, err)
+			return scimAuthError(c, fiber.StatusNotFound, "The organization of the API key does not exist")
+		}
+		if code, body := unavailableTenantResponse(tenantInfo); code != 0 {
+			return scimAuthError(c, code, body["message"].(string))
+		}
+
+		if err := authRepo.RecordAPITokenUse(c.Context(), apiToken.ID, c.IP()); err != nil {
+			log.Printf("⚠️  Failed to record use of API key %s: %v", apiToken.ID, err)
+		}
+
+		c.Locals(UserIDKey, apiToken.UserID)
+		c.Locals(TenantIDKey, apiToken.TenantID)
+		c.Locals(APITokenKey, apiToken)
+
+		return c.Next()
+	}
+}
+
+// scimAuthError responds with a SCIM error
+func scimAuthError(c *fiber.Ctx, status int, detail string) error {
+	return c.Status(status).JSON(fiber.Map{
+		"schemas": []string{scimErrorSchema},
+		"status":  strconv.Itoa(status),
+		"detail":  detail,
+	}, scimContentType)
+}
@@ -1,7 +1,10 @@
 package middleware
 
 import (
+	"database/sql"
+	"errors"
 	"log"
+	"net"
 	"regexp"
 	"strings"
 	"sync"
@@ -60,11 +63,21 @@ const (
 	TenantDBConnKey = "tenant_db_conn" // Stores the *sqlx.DB connection to tenant database
 )
 
+// customDomainKeyPrefix prefixes the cache keys of hosts looked up as custom domains.
+// Tenant IDs can't contain ':' so these keys never collide with them.
+const customDomainKeyPrefix = "domain:"
+
+// maxCachedMisses caps the lookups without a tenant kept in cache. Any host can be sent in
+// requests, so without a cap the misses would grow the cache without bound.
+const maxCachedMisses = 10000
+
 // TenantCache stores tenant metadata in memory for faster access
 type TenantCache struct {
 	cache map[string]*CachedTenant
 	mutex sync.RWMutex
 	ttl   time.Duration
+	// Keys cached without a tenant, oldest first; the oldest are evicted past maxCachedMisses
+	misses []string
 }
 
 // CachedTenant represents cached tenant information
@@ -120,6 +133,19 @@ func (tc *TenantCache) Set(key string, info *database.TenantInfo) {
 		CachedAt:  now,
 		ExpiresAt: now.Add(tc.ttl),
 	}
+
+	if info != nil {
+		return
+	}
+	tc.misses = append(tc.misses, key)
+	for len(tc.misses) > maxCachedMisses {
+		oldest := tc.misses[0]
+		tc.misses = tc.misses[1:]
+		// The key may have been deleted or cached with a tenant since
+		if cached, exists := tc.cache[oldest]; exists && cached.Info == nil {
+			delete(tc.cache, oldest)
+		}
+	}
 }
 
 // Delete removes a tenant from cache
@@ -130,6 +156,19 @@ func (tc *TenantCache) Delete(key string) {
 	delete(tc.cache, key)
 }
 
+// DeleteTenant removes every entry of a tenant from cache, whether it was cached by ID,
+// slug or custom domain
+func (tc *TenantCache) DeleteTenant(tenantID string) {
+	tc.mutex.Lock()
+	defer tc.mutex.Unlock()
+
+	for key, cached := range tc.cache {
+		if cached.Info != nil && cached.Info.ID == tenantID {
+			delete(tc.cache, key)
+		}
+	}
+}
+
 // cleanupExpired removes expired entries from cache
 func (tc *TenantCache) cleanupExpired() {
 	ticker := time.NewTicker(5 * time.Minute)
@@ -164,6 +203,20 @@ func TenantMiddleware(dbManager *database.Manager) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		startTime := time.Now()
 
+		// Verified custom domains (e.g., academy.customer.com) identify the tenant
+		// unless the X-Tenant-ID header is provided
+		if c.Get("X-Tenant-ID") == "" {
+			if tenantInfo := resolveCustomDomain(c, dbManager); tenantInfo != nil {
+				if code, body := unavailableTenantResponse(tenantInfo); code != 0 {
+					return c.Status(code).JSON(body)
+				}
+				injectTenantContextWithDB(c, tenantInfo, dbManager)
+				elapsed := time.Since(startTime)
+				log.Printf("✅ Tenant identified by custom domain: %s (%s) - %v", tenantInfo.Slug, tenantInfo.ID, elapsed)
+				return c.Next()
+			}
+		}
+
 		// Extract tenant identifier from various sources
 		tenantID := extractTenantID(c)
 
@@ -178,6 +231,9 @@ func TenantMiddleware(dbManager *database.Manager) fiber.Handler {
 		// Try to get tenant info from cache
 		tenantInfo, cached := tenantCache.Get(tenantID)
 		if cached {
+			if code, body := unavailableTenantResponse(tenantInfo); code != 0 {
+				return c.Status(code).JSON(body)
+			}
 			// Cache hit - inject into context with DB connection and continue
 			injectTenantContextWithDB(c, tenantInfo, dbManager)
 			elapsed := time.Since(startTime)
@@ -198,6 +254,10 @@ func TenantMiddleware(dbManager *database.Manager) fiber.Handler {
 		// Store in cache
 		tenantCache.Set(tenantID, tenantInfo)
 
+		if code, body := unavailableTenantResponse(tenantInfo); code != 0 {
+			return c.Status(code).JSON(body)
+		}
+
 		// Inject tenant info and DB connection into context
 		injectTenantContextWithDB(c, tenantInfo, dbManager)
 
@@ -277,7 +337,33 @@ func extractFromJWT(c *fiber.Ctx) string {
 	return ""
 }
 
-// getTenantInfo fetches tenant information from the database
+// unavailableTenantResponse returns the status code and body rejecting requests for a tenant
+// that is not active, or 0 for active tenants
+func unavailableTenantResponse(info *database.TenantInfo) (int, fiber.Map) {
+	switch info.Status {
+	case database.TenantStatusActive:
+		return 0, nil
+	case database.TenantStatusSuspended:
+		log.Printf("🚫 Request for suspended tenant: %s (%s)", info.Slug, info.ID)
+		return fiber.StatusForbidden, fiber.Map{
+			"error":   "Tenant Suspended",
+			"message": "This organization has been suspended. Please contact support to restore access.",
+		}
+	case database.TenantStatusPendingDeletion:
+		log.Printf("🚫 Request for tenant pending deletion: %s (%s)", info.Slug, info.ID)
+		return fiber.StatusForbidden, fiber.Map{
+			"error":   "Tenant Pending Deletion",
+			"message": "This organization is scheduled for deletion. Please contact support to cancel the deletion.",
+		}
+	default:
+		return fiber.StatusNotFound, fiber.Map{
+			"error":   "Tenant Not Found",
+			"message": "The specified tenant does not exist or is inactive.",
+		}
+	}
+}
+
+// getTenantInfo fetches tenant information from the database, whatever its status
 func getTenantInfo(dbManager *database.Manager, tenantID string) (*database.TenantInfo, error) {
 	controlDB := dbManager.GetControlDB()
 
@@ -285,7 +371,7 @@ func getTenantInfo(dbManager *database.Manager, tenantID string) (*database.Tena
 	query := `
 		SELECT id, name, slug, database_name, node_number, status
 		FROM tenants
-		WHERE (id::text = $1 OR slug = $1) AND status = 'active'
+		WHERE id::text = $1 OR slug = $1
 		LIMIT 1
 	`
 
@@ -297,6 +383,93 @@ func getTenantInfo(dbManager *database.Manager, tenantID string) (*database.Tena
 	return &tenantInfo, nil
 }
 
+// resolveCustomDomain returns the tenant owning the verified custom domain of the request host.
+// Lookups are cached by host, including misses up to maxCachedMisses, so that platform hosts
+// don't query the control DB on every request.
+func resolveCustomDomain(c *fiber.Ctx, dbManager *database.Manager) *database.TenantInfo {
+	host := normalizeHost(c.Hostname())
+	if !isCustomDomainCandidate(host) {
+		return nil
+	}
+
+	key := customDomainKeyPrefix + host
+	if tenantInfo, cached := tenantCache.Get(key); cached {
+		return tenantInfo
+	}
+
+	tenantInfo, err := getTenantInfoByDomain(dbManager, host)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			// Don't cache lookup failures
+			log.Printf("⚠️  Failed to resolve custom domain %s: %v", host, err)
+			return nil
+		}
+		tenantInfo = nil
+	}
+
+	tenantCache.Set(key, tenantInfo)
+	return tenantInfo
+}
+
+// getTenantInfoByDomain fetches the tenant owning a verified custom domain
+func getTenantInfoByDomain(dbManager *database.Manager, host string) (*database.TenantInfo, error) {
+	controlDB := dbManager.GetControlDB()
+
+	var tenantInfo database.TenantInfo
+	query := `
+		SELECT t.id, t.name, t.slug, t.database_name, t.node_number, t.status
+		FROM tenant_domains td
+		INNER JOIN tenants t ON t.id = td.tenant_id
+		WHERE td.domain = $1 AND td.verified_at IS NOT NULL
+		LIMIT 1
+	`
+
+	err := controlDB.Get(&tenantInfo, query, host)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tenantInfo, nil
+}
+
+// normalizeHost lowercases a host name and strips the port and the trailing dot
+func normalizeHost(host string) string {
+	host = strings.ToLower(strings.TrimSpace(host))
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.TrimSuffix(host, ".")
+}
+
+// isCustomDomainCandidate checks if a host could be a custom domain (not localhost or an IP)
+func isCustomDomainCandidate(host string) bool {
+	if host == "" || host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return false
+	}
+	if net.ParseIP(strings.Trim(host, "[]")) != nil {
+		return false
+	}
+	return strings.Contains(host, ".")
+}
+
+// InvalidateCustomDomain drops a host from the custom domain cache, so that a domain
+// verified or removed takes effect on the next request
+func InvalidateCustomDomain(host string) {
+	if tenantCache == nil {
+		return
+	}
+	tenantCache.Delete(customDomainKeyPrefix + normalizeHost(host))
+}
+
+// InvalidateTenant drops a tenant from the cache under all its keys, so that a change
+// of its status takes effect on the next request
+func InvalidateTenant(tenantID string) {
+	if tenantCache == nil {
+		return
+	}
+	tenantCache.DeleteTenant(tenantID)
+}
+
 // injectTenantContext injects tenant information into Fiber context
 func injectTenantContext(c *fiber.Ctx, info *database.TenantInfo) {
 	c.Locals(TenantIDKey, info.ID)
@@ -338,6 +511,15 @@ func OptionalTenantMiddleware(dbManager *database.Manager) fiber.Handler {
 			}
 		}()
 
+		// Verified custom domains identify the tenant unless the X-Tenant-ID header is provided
+		if c.Get("X-Tenant-ID") == "" {
+			if tenantInfo := resolveCustomDomain(c, dbManager); tenantInfo != nil && tenantInfo.Status == database.TenantStatusActive {
+				log.Printf("✅ Tenant found by custom domain: %s", tenantInfo.Slug)
+				injectTenantContextWithDB(c, tenantInfo, dbManager)
+				return c.Next()
+			}
+		}
+
 		tenantID := extractTenantID(c)
 		log.Printf("🔵 Extracted tenantID: '%s'", tenantID)
 
@@ -350,6 +532,11 @@ func OptionalTenantMiddleware(dbManager *database.Manager) fiber.Handler {
 		// Try cache first
 		tenantInfo, cached := tenantCache.Get(tenantID)
 		if cached {
+			if tenantInfo.Status != database.TenantStatusActive {
+				// Routes requiring the tenant reject the request in TenantMiddleware
+				log.Printf("⚠️  Optional tenant is %s: %s - continuing without tenant context", tenantInfo.Status, tenantID)
+				return c.Next()
+			}
 			log.Printf("✅ Tenant found in cache: %s", tenantID)
 			injectTenantContextWithDB(c, tenantInfo, dbManager)
 			return c.Next()
@@ -367,6 +554,10 @@ func OptionalTenantMiddleware(dbManager *database.Manager) fiber.Handler {
 		// Cache and inject with DB connection
 		log.Printf("✅ Tenant found in database: %s", tenantID)
 		tenantCache.Set(tenantID, tenantInfo)
+		if tenantInfo.Status != database.TenantStatusActive {
+			log.Printf("⚠️  Optional tenant is %s: %s - continuing without tenant context", tenantInfo.Status, tenantID)
+			return c.Next()
+		}
 		injectTenantContextWithDB(c, tenantInfo, dbManager)
 
 		return c.Next()
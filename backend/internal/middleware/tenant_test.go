@@ -1,7 +1,10 @@
 package middleware
 
 import (
+	"encoding/json"
+	"fmt"
 	"net/http/httptest"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -149,6 +152,173 @@ func TestExtractFromSubdomain(t *testing.T) {
 	}
 }
 
+func TestIsCustomDomainCandidate(t *testing.T) {
+	tests := []struct {
+		host     string
+		expected bool
+	}{
+		{host: normalizeHost("Academy.Customer.com"), expected: true},
+		{host: normalizeHost("academy.customer.com:8080"), expected: true},
+		{host: normalizeHost("academy.customer.com."), expected: true},
+		{host: normalizeHost("localhost:3000"), expected: false},
+		{host: normalizeHost("app.localhost"), expected: false},
+		{host: normalizeHost("127.0.0.1:8080"), expected: false},
+		{host: normalizeHost("[::1]:8080"), expected: false},
+		{host: normalizeHost(""), expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			if result := isCustomDomainCandidate(tt.host); result != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+
+	if host := normalizeHost("Academy.Customer.com:8080"); host != "academy.customer.com" {
+		t.Errorf("Expected 'academy.customer.com', got '%s'", host)
+	}
+}
+
+func TestResolveCustomDomainFromCache(t *testing.T) {
+	InitTenantCache(5 * time.Minute)
+
+	tenantInfo := &database.TenantInfo{
+		ID:   "custom-domain-tenant",
+		Slug: "custom-domain-tenant",
+	}
+	tenantCache.Set(customDomainKeyPrefix+"academy.customer.com", tenantInfo)
+	tenantCache.Set(customDomainKeyPrefix+"unknown.customer.com", nil)
+	defer InvalidateCustomDomain("academy.customer.com")
+	defer InvalidateCustomDomain("unknown.customer.com")
+
+	app := fiber.New()
+
+	var result *database.TenantInfo
+	app.Get("/test", func(c *fiber.Ctx) error {
+		// Cached hosts never reach the database
+		result = resolveCustomDomain(c, nil)
+		return c.SendString("ok")
+	})
+
+	for host, expected := range map[string]*database.TenantInfo{
+		"academy.customer.com": tenantInfo,
+		"unknown.customer.com": nil,
+		"localhost":            nil,
+	} {
+		req := httptest.NewRequest("GET", "http://"+host+"/test", nil)
+		if _, err := app.Test(req, -1); err != nil {
+			t.Fatalf("Failed to test request: %v", err)
+		}
+
+		if result != expected {
+			t.Errorf("Host %s: expected %v, got %v", host, expected, result)
+		}
+	}
+}
+
+func TestTenantCacheDeleteTenant(t *testing.T) {
+	cache := &TenantCache{
+		cache: make(map[string]*CachedTenant),
+		ttl:   5 * time.Minute,
+	}
+
+	tenantInfo := &database.TenantInfo{ID: "tenant-a", Slug: "acme"}
+	otherInfo := &database.TenantInfo{ID: "tenant-b", Slug: "globex"}
+	cache.Set("tenant-a", tenantInfo)
+	cache.Set("acme", tenantInfo)
+	cache.Set(customDomainKeyPrefix+"academy.acme.com", tenantInfo)
+	cache.Set(customDomainKeyPrefix+"unknown.acme.com", nil)
+	cache.Set("tenant-b", otherInfo)
+
+	cache.DeleteTenant("tenant-a")
+
+	for _, key := range []string{"tenant-a", "acme", customDomainKeyPrefix + "academy.acme.com"} {
+		if _, found := cache.Get(key); found {
+			t.Errorf("Expected key %s to be deleted from cache", key)
+		}
+	}
+	if _, found := cache.Get("tenant-b"); !found {
+		t.Error("Expected other tenants to stay in cache")
+	}
+	if _, found := cache.Get(customDomainKeyPrefix + "unknown.acme.com"); !found {
+		t.Error("Expected cached misses to stay in cache")
+	}
+}
+
+func TestTenantCacheCapsMisses(t *testing.T) {
+	cache := &TenantCache{
+		cache: make(map[string]*CachedTenant),
+		ttl:   5 * time.Minute,
+	}
+
+	tenantInfo := &database.TenantInfo{ID: "tenant-a", Slug: "acme"}
+	cache.Set(customDomainKeyPrefix+"academy.acme.com", tenantInfo)
+	for i := 0; i < maxCachedMisses+100; i++ {
+		cache.Set(fmt.Sprintf("%sunknown-%d.example.com", customDomainKeyPrefix, i), nil)
+	}
+
+	if size := cache.GetCacheSize(); size != maxCachedMisses+1 {
+		t.Errorf("Expected %d cached entries, got %d", maxCachedMisses+1, size)
+	}
+	if _, found := cache.Get(customDomainKeyPrefix + "unknown-0.example.com"); found {
+		t.Error("Expected the oldest miss to be evicted")
+	}
+	if _, found := cache.Get(fmt.Sprintf("%sunknown-%d.example.com", customDomainKeyPrefix, maxCachedMisses+99)); !found {
+		t.Error("Expected the latest miss to stay in cache")
+	}
+	if info, found := cache.Get(customDomainKeyPrefix + "academy.acme.com"); !found || info != tenantInfo {
+		t.Error("Expected cached tenants to stay in cache")
+	}
+}
+
+func TestTenantMiddlewareRejectsUnavailableTenants(t *testing.T) {
+	InitTenantCache(5 * time.Minute)
+
+	tests := []struct {
+		status       string
+		expectStatus int
+		expectError  string
+	}{
+		{status: database.TenantStatusSuspended, expectStatus: fiber.StatusForbidden, expectError: "Tenant Suspended"},
+		{status: database.TenantStatusPendingDeletion, expectStatus: fiber.StatusForbidden, expectError: "Tenant Pending Deletion"},
+		{status: database.TenantStatusDeleted, expectStatus: fiber.StatusNotFound, expectError: "Tenant Not Found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			tenantID := "tenant-" + strings.ReplaceAll(tt.status, "_", "-")
+			tenantCache.Set(tenantID, &database.TenantInfo{ID: tenantID, Slug: tenantID, Status: tt.status})
+			defer InvalidateTenant(tenantID)
+
+			// Cached tenants never reach the database
+			app := fiber.New()
+			app.Get("/test", TenantMiddleware(nil), func(c *fiber.Ctx) error {
+				return c.SendStatus(fiber.StatusOK)
+			})
+
+			req := httptest.NewRequest("GET", "/test", nil)
+			req.Header.Set("X-Tenant-ID", tenantID)
+			resp, err := app.Test(req, -1)
+			if err != nil {
+				t.Fatalf("Failed to test request: %v", err)
+			}
+
+			if resp.StatusCode != tt.expectStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectStatus, resp.StatusCode)
+			}
+
+			var body map[string]string
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if body["error"] != tt.expectError {
+				t.Errorf("Expected error %q, got %q", tt.expectError, body["error"])
+			}
+		})
+	}
+}
+
 func TestExtractTenantIDFromHeader(t *testing.T) {
 	app := fiber.New()
 
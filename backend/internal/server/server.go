@@ -1,6 +1,7 @@
 package server
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
@@ -14,6 +15,7 @@ import (
 	assignmentadapters "github.com/DanielIturra1610/stegmaier-landing/internal/core/assignments/adapters"
 	assignmentservices "github.com/DanielIturra1610/stegmaier-landing/internal/core/assignments/services"
 	"github.com/DanielIturra1610/stegmaier-landing/internal/core/auth/adapters"
+	authdomain "github.com/DanielIturra1610/stegmaier-landing/internal/core/auth/domain"
 	"github.com/DanielIturra1610/stegmaier-landing/internal/core/auth/ports"
 	"github.com/DanielIturra1610/stegmaier-landing/internal/core/auth/services"
 	certificateadapters "github.com/DanielIturra1610/stegmaier-landing/internal/core/certificates/adapters"
@@ -24,6 +26,11 @@ import (
 	enrollmentadapters "github.com/DanielIturra1610/stegmaier-landing/internal/core/enrollments/adapters"
 	enrollmentcontrollers "github.com/DanielIturra1610/stegmaier-landing/internal/core/enrollments/controllers"
 	enrollmentservices "github.com/DanielIturra1610/stegmaier-landing/internal/core/enrollments/services"
+	featureadapters "github.com/DanielIturra1610/stegmaier-landing/internal/core/features/adapters"
+	featurecontrollers "github.com/DanielIturra1610/stegmaier-landing/internal/core/features/controllers"
+	featuredomain "github.com/DanielIturra1610/stegmaier-landing/internal/core/features/domain"
+	featureports "github.com/DanielIturra1610/stegmaier-landing/internal/core/features/ports"
+	featureservices "github.com/DanielIturra1610/stegmaier-landing/internal/core/features/services"
 	lessonadapters "github.com/DanielIturra1610/stegmaier-landing/internal/core/lessons/adapters"
 	lessonservices "github.com/DanielIturra1610/stegmaier-landing/internal/core/lessons/services"
 	mediaadapters "github.com/DanielIturra1610/stegmaier-landing/internal/core/media/adapters"
@@ -41,17 +48,23 @@ import (
 	quizservices "github.com/DanielIturra1610/stegmaier-landing/internal/core/quizzes/services"
 	reviewadapters "github.com/DanielIturra1610/stegmaier-landing/internal/core/reviews/adapters"
 	reviewservices "github.com/DanielIturra1610/stegmaier-landing/internal/core/reviews/services"
+	scimadapters "github.com/DanielIturra1610/stegmaier-landing/internal/core/scim/adapters"
+	scimcontrollers "github.com/DanielIturra1610/stegmaier-landing/internal/core/scim/controllers"
+	scimservices "github.com/DanielIturra1610/stegmaier-landing/internal/core/scim/services"
 	tenantadapters "github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/adapters"
 	tenantcontrollers "github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/controllers"
 	tenantservices "github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/services"
 	useradapters "github.com/DanielIturra1610/stegmaier-landing/internal/core/user/adapters"
 	userservices "github.com/DanielIturra1610/stegmaier-landing/internal/core/user/services"
 	"github.com/DanielIturra1610/stegmaier-landing/internal/middleware"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/shared/breach"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/shared/cache"
 	"github.com/DanielIturra1610/stegmaier-landing/internal/shared/config"
 	"github.com/DanielIturra1610/stegmaier-landing/internal/shared/database"
 	"github.com/DanielIturra1610/stegmaier-landing/internal/shared/email"
 	"github.com/DanielIturra1610/stegmaier-landing/internal/shared/hasher"
 	"github.com/DanielIturra1610/stegmaier-landing/internal/shared/tokens"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/shared/webauthn"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2/middleware/limiter"
@@ -62,11 +75,33 @@ import (
 
 const (
 	// Token expiration durations
-	verificationTokenExpiry  = 24 * time.Hour // 24 hours for email verification
-	passwordResetTokenExpiry = 1 * time.Hour  // 1 hour for password reset
-	bcryptCost               = 12             // Bcrypt cost factor
+	verificationTokenExpiry  = 24 * time.Hour   // 24 hours for email verification
+	passwordResetTokenExpiry = 1 * time.Hour    // 1 hour for password reset
+	mfaChallengeExpiry       = 5 * time.Minute  // 5 minutes to complete the MFA login step
+	oidcRequestExpiry        = 10 * time.Minute // 10 minutes to complete a login at the identity provider
+	samlRequestExpiry        = 10 * time.Minute // 10 minutes to answer a SAML AuthnRequest
+	webAuthnTimeout          = 5 * time.Minute  // 5 minutes to complete a passkey ceremony
+	impersonationExpiry      = 30 * time.Minute // 30 minutes per admin impersonation session
+	magicLinkExpiry          = 15 * time.Minute // 15 minutes to use a passwordless login link
+	bcryptCost               = 12               // Bcrypt cost factor
+
+	// mfaIssuer is the issuer name shown in authenticator apps
+	mfaIssuer = "Stegmaier LMS"
 )
 
+// loginThrottlePolicy limits failed logins, password reset and magic link requests per account and IP
+var loginThrottlePolicy = services.LoginThrottlePolicy{
+	FailureWindow:               15 * time.Minute, // Counters reset 15 minutes after the first failure
+	DelayThreshold:              3,                // From the 3rd failure on, wait before the next attempt
+	BaseDelay:                   2 * time.Second,  // 2s, 4s, 8s, ...
+	MaxDelay:                    1 * time.Minute,
+	MaxFailedLogins:             10, // Lock the account and email an unlock link
+	LockoutDuration:             30 * time.Minute,
+	MaxFailedLoginsPerIP:        50, // Block credential stuffing from one IP across accounts
+	MaxPasswordResetsPerAccount: 5,
+	MaxMagicLinksPerAccount:     5,
+}
+
 // Server representa el servidor Fiber con toda su configuración
 type Server struct {
 	app                    *fiber.App
@@ -90,7 +125,13 @@ type Server struct {
 	progressController     *progresscontrollers.ProgressController
 	certificateController  *certificatecontrollers.CertificateController
 	tenantController       *tenantcontrollers.TenantController
+	tenantService          *tenantservices.TenantService
+	featureController      *featurecontrollers.FeatureFlagController
+	featureService         featureports.FeatureFlagService
+	scimController         *scimcontrollers.SCIMController
+	stopTenantMaintenance  context.CancelFunc
 	tokenService           tokens.TokenService
+	jwtKeySet              *tokens.KeySet
 	authRepo               ports.AuthRepository
 	// Tenant-aware controllers for dynamic DB connection
 	tenantAwareCourseController       *controllers.TenantAwareCourseController
@@ -127,24 +168,77 @@ func New(cfg *config.Config, dbManager *database.Manager) *Server {
 
 	// 1. Initialize shared utilities
 	passwordHasher := hasher.NewBcryptHasher(bcryptCost)
-	tokenService := tokens.NewJWTService(
-		cfg.JWT.Secret,
-		cfg.JWT.Expiration,
-		"stegmaier-lms",
-	)
+	var tokenService *tokens.JWTService
+	if cfg.JWT.KeysDir != "" {
+		keySet, err := tokens.LoadKeySetFromDir(cfg.JWT.KeysDir, cfg.JWT.ActiveKeyID)
+		if err != nil {
+			log.Fatalf("❌ Failed to load JWT signing keys: %v", err)
+		}
+		tokenService = tokens.NewJWTServiceWithKeySet(
+			keySet,
+			cfg.JWT.Secret, // Legacy HS256 tokens remain valid until JWT_LEGACY_SECRET_UNTIL
+			cfg.JWT.LegacySecretUntil,
+			cfg.JWT.Expiration,
+			"stegmaier-lms",
+		)
+		log.Printf("🔑 JWT signing with %s key %s", keySet.Active().Algorithm, keySet.Active().ID)
+		if cfg.JWT.Secret != "" {
+			if time.Now().Before(cfg.JWT.LegacySecretUntil) {
+				log.Printf("⚠️  Legacy HS256 tokens accepted until %s", cfg.JWT.LegacySecretUntil.Format(time.RFC3339))
+			} else {
+				log.Println("⚠️  JWT_LEGACY_SECRET_UNTIL has passed, remove JWT_SECRET from the configuration")
+			}
+		}
+	} else {
+		tokenService = tokens.NewJWTService(
+			cfg.JWT.Secret,
+			cfg.JWT.Expiration,
+			"stegmaier-lms",
+		)
+	}
+
+	// Control DB and tenant repository, used from here on by most modules (tenant branding of
+	// emails, plans, feature flags)
+	controlDB := dbManager.GetControlDB()
+	tenantRepo := tenantadapters.NewPostgresTenantRepository(controlDB, dbManager)
 
 	// 2. Initialize email service for auth (needed for verification emails)
 	log.Println("📧 Initializing email service for authentication...")
-	authEmailService := email.NewEmailService(&cfg.Email, cfg.Server.BaseURL)
+	authEmailService := email.NewEmailService(&cfg.Email, cfg.Server.BaseURL, tenantRepo)
 	authEmailAdapter := email.NewAuthEmailServiceAdapter(authEmailService)
 	log.Println("✅ Email service for authentication initialized")
 
+	// Failed login counters live in Redis so the lockout applies across replicas. Feature flag
+	// evaluations are cached there too.
+	var sharedCache cache.Cache
+	redisCache, err := cache.NewRedisCache(cfg.Redis.GetRedisAddr(), cfg.Redis.Password, cfg.Redis.DB)
+	if err != nil {
+		log.Printf("⚠️  Redis unavailable, login attempts are only tracked per replica and feature flags are not cached: %v", err)
+	} else {
+		sharedCache = redisCache
+		log.Println("✅ Redis connected for login attempt tracking and feature flags")
+	}
+
 	// 3. Initialize repositories (using Control DB from dbManager)
-	controlDB := dbManager.GetControlDB()
 	authRepo := adapters.NewPostgreSQLAuthRepository(controlDB)
 	authUserRepo := adapters.NewPostgreSQLUserRepository(controlDB) // For profile service
 	userRepo := useradapters.NewPostgreSQLUserRepository(controlDB)
 
+	// Password policy shared by every way of setting a password
+	var breachChecker breach.Checker
+	if cfg.Security.BreachedPasswordsDir != "" {
+		checker, err := breach.NewLocalChecker(cfg.Security.BreachedPasswordsDir)
+		if err != nil {
+			log.Printf("⚠️  Breached password dataset unavailable, check is disabled: %v", err)
+		} else {
+			breachChecker = checker
+			log.Printf("✅ Breached password check enabled (%s)", cfg.Security.BreachedPasswordsDir)
+		}
+	} else {
+		log.Println("⚠️  BREACHED_PASSWORDS_DIR not set, breached password check is disabled")
+	}
+	passwordPolicyService := services.NewPasswordPolicyService(authRepo, passwordHasher, breachChecker)
+
 	// 4. Initialize services
 	authService := services.NewAuthService(
 		authRepo,
@@ -156,6 +250,22 @@ func New(cfg *config.Config, dbManager *database.Manager) *Server {
 			RefreshTokenExpiry: cfg.JWT.RefreshExpiration,
 			VerifyTokenExpiry:  verificationTokenExpiry,
 			ResetTokenExpiry:   passwordResetTokenExpiry,
+			MFAChallengeExpiry: mfaChallengeExpiry,
+			MFAIssuer:          mfaIssuer,
+			OIDCRequestExpiry:  oidcRequestExpiry,
+			SAMLRequestExpiry:  samlRequestExpiry,
+			BaseURL:            cfg.Server.BaseURL,
+			LoginAttemptCache:  sharedCache,
+			LoginThrottle:      loginThrottlePolicy,
+			PasswordPolicy:     passwordPolicyService,
+			WebAuthn: webauthn.Config{
+				RPID:    cfg.Security.WebAuthnRPID,
+				RPName:  cfg.Security.WebAuthnRPName,
+				Origins: cfg.Security.WebAuthnOrigins,
+				Timeout: webAuthnTimeout,
+			},
+			ImpersonationExpiry: impersonationExpiry,
+			MagicLinkExpiry:     magicLinkExpiry,
 		},
 	)
 
@@ -163,6 +273,7 @@ func New(cfg *config.Config, dbManager *database.Manager) *Server {
 		authRepo,
 		userRepo,
 		passwordHasher,
+		passwordPolicyService,
 	)
 
 	// 4. Initialize controllers
@@ -193,6 +304,7 @@ func New(cfg *config.Config, dbManager *database.Manager) *Server {
 		authUserRepo, // Provides ChangePassword (UserRepository)
 		fileStorage,
 		passwordHasher,
+		passwordPolicyService,
 	)
 
 	// 4. Initialize profile controller
@@ -211,11 +323,16 @@ func New(cfg *config.Config, dbManager *database.Manager) *Server {
 	courseRepo := courseadapters.NewPostgreSQLCourseRepository(tenantDB)
 	categoryRepo := courseadapters.NewPostgreSQLCourseCategoryRepository(tenantDB)
 
-	// 3. Initialize course services
-	courseService := courseservices.NewCourseService(courseRepo, categoryRepo)
+	// 3. Initialize quota service (plan limits of the tenants, checked by courses, media,
+	// enrollments and tenant users)
+	quotaService := tenantservices.NewQuotaService(tenantRepo, tenantadapters.NewPostgresTenantUsageRepository(dbManager))
+
+	// 4. Initialize course services
+	courseService := courseservices.NewCourseService(courseRepo, categoryRepo, authRepo, quotaService)
 	categoryService := courseservices.NewCourseCategoryService(categoryRepo)
+	coursePolicy := courseservices.NewCoursePolicy(courseRepo)
 
-	// 4. Initialize course controllers
+	// 5. Initialize course controllers
 	courseController := controllers.NewCourseController(courseService)
 	categoryController := controllers.NewCategoryController(categoryService)
 
@@ -229,7 +346,7 @@ func New(cfg *config.Config, dbManager *database.Manager) *Server {
 	moduleRepo := moduleadapters.NewPostgreSQLModuleRepository(tenantDB)
 
 	// 2. Initialize module service
-	moduleService := moduleservices.NewModuleService(moduleRepo)
+	moduleService := moduleservices.NewModuleService(moduleRepo, coursePolicy)
 
 	// 3. Initialize module controller
 	moduleController := controllers.NewModuleController(moduleService)
@@ -243,7 +360,7 @@ func New(cfg *config.Config, dbManager *database.Manager) *Server {
 	lessonRepo := lessonadapters.NewPostgreSQLLessonRepository(tenantDB)
 
 	// 2. Initialize lesson service (now with module repository for validation)
-	lessonService := lessonservices.NewLessonService(lessonRepo, moduleRepo)
+	lessonService := lessonservices.NewLessonService(lessonRepo, moduleRepo, coursePolicy)
 
 	// Note: Lesson controller initialization moved after media module
 	// to support video upload functionality
@@ -257,13 +374,28 @@ func New(cfg *config.Config, dbManager *database.Manager) *Server {
 	quizRepo := quizadapters.NewPostgreSQLQuizRepository(tenantDB)
 
 	// 2. Initialize quiz service
-	quizService := quizservices.NewQuizService(quizRepo)
+	quizService := quizservices.NewQuizService(quizRepo, coursePolicy)
 
 	// 3. Initialize quiz controller
 	quizController := controllers.NewQuizController(quizService)
 
 	log.Println("✅ Quizzes module initialized")
 
+	// Initialize dependency injection for features module
+	log.Println("🔧 Initializing features module...")
+
+	// 1. Initialize feature flag repository (flags and tenant overrides live in the control DB)
+	featureRepo := featureadapters.NewPostgresFeatureFlagRepository(controlDB)
+
+	// 2. Initialize feature flag service (with the tenant repository for plan features), used by
+	// the assignments, reviews and certificates modules
+	featureService := featureservices.NewFeatureFlagService(featureRepo, tenantRepo, sharedCache)
+
+	// 3. Initialize feature flag controller
+	featureController := featurecontrollers.NewFeatureFlagController(featureService)
+
+	log.Println("✅ Features module initialized")
+
 	// Initialize dependency injection for assignments module
 	log.Println("🔧 Initializing assignments module...")
 
@@ -280,7 +412,7 @@ func New(cfg *config.Config, dbManager *database.Manager) *Server {
 	}
 
 	// 3. Initialize assignment service
-	assignmentService := assignmentservices.NewAssignmentService(assignmentRepo, assignmentFileStorage)
+	assignmentService := assignmentservices.NewAssignmentService(assignmentRepo, assignmentFileStorage, coursePolicy, featureService)
 
 	// 4. Initialize assignment controller
 	assignmentController := controllers.NewAssignmentController(assignmentService)
@@ -295,7 +427,7 @@ func New(cfg *config.Config, dbManager *database.Manager) *Server {
 
 	// 2. Initialize email service
 	log.Println("📧 Initializing email service...")
-	emailService := email.NewEmailService(&cfg.Email, cfg.Server.BaseURL)
+	emailService := email.NewEmailService(&cfg.Email, cfg.Server.BaseURL, tenantRepo)
 	emailServiceAdapter := email.NewEmailServiceAdapter(emailService)
 	log.Println("✅ Email service initialized")
 
@@ -328,7 +460,7 @@ func New(cfg *config.Config, dbManager *database.Manager) *Server {
 	mediaRepo := mediaadapters.NewPostgreSQLMediaRepository(tenantDB)
 
 	// 3. Initialize media service
-	mediaService := mediaservices.NewMediaService(mediaRepo, storageService)
+	mediaService := mediaservices.NewMediaService(mediaRepo, storageService, quotaService)
 
 	// 4. Initialize media controller
 	mediaController := controllers.NewMediaController(mediaService)
@@ -351,7 +483,7 @@ func New(cfg *config.Config, dbManager *database.Manager) *Server {
 
 	// 2. Initialize review service with course repository for rating sync
 	// The course repository implements CourseRatingSync interface
-	reviewService := reviewservices.NewReviewService(reviewRepo, courseRepo)
+	reviewService := reviewservices.NewReviewService(reviewRepo, courseRepo, featureService)
 
 	// 3. Initialize review controller
 	reviewController := controllers.NewReviewController(reviewService)
@@ -379,7 +511,7 @@ func New(cfg *config.Config, dbManager *database.Manager) *Server {
 	enrollmentRepo := enrollmentadapters.NewPostgreSQLEnrollmentRepository(dbManager)
 
 	// 2. Initialize enrollments service
-	enrollmentService := enrollmentservices.NewEnrollmentService(enrollmentRepo)
+	enrollmentService := enrollmentservices.NewEnrollmentService(enrollmentRepo, quotaService)
 
 	// 3. Initialize enrollments controller
 	enrollmentController := enrollmentcontrollers.NewEnrollmentController(enrollmentService)
@@ -406,8 +538,8 @@ func New(cfg *config.Config, dbManager *database.Manager) *Server {
 	// 1. Initialize certificates repository (tenant-aware)
 	certificateRepo := certificateadapters.NewPostgreSQLCertificateRepository(dbManager)
 
-	// 2. Initialize certificate generator (PDF generation with gofpdf)
-	certificateGenerator := certificateadapters.NewPDFGenerator()
+	// 2. Initialize certificate generator (PDF generation with gofpdf, with the tenant branding)
+	certificateGenerator := certificateadapters.NewPDFGenerator(tenantRepo)
 
 	// 3. Initialize certificate storage (local file system)
 	certificateStorage, err := certificateadapters.NewLocalCertificateStorage("./certificates", cfg.Server.BaseURL+"/certificates")
@@ -416,7 +548,7 @@ func New(cfg *config.Config, dbManager *database.Manager) *Server {
 	}
 
 	// 4. Initialize certificates service
-	certificateService := certificateservices.NewCertificateService(certificateRepo, certificateGenerator, certificateStorage, cfg.Server.BaseURL)
+	certificateService := certificateservices.NewCertificateService(certificateRepo, certificateGenerator, certificateStorage, featureService, cfg.Server.BaseURL)
 
 	// 5. Initialize certificates controller
 	certificateController := certificatecontrollers.NewCertificateController(certificateService)
@@ -429,21 +561,45 @@ func New(cfg *config.Config, dbManager *database.Manager) *Server {
 	// 1. Initialize migration runner
 	migrationRunner := database.NewMigrationRunner(dbManager)
 
-	// 2. Initialize tenant repository
-	tenantRepo := tenantadapters.NewPostgresTenantRepository(controlDB, dbManager)
-
-	// 3. Initialize tenant service (with userManagementService dependency)
-	tenantService := tenantservices.NewTenantService(tenantRepo, dbManager, migrationRunner, tokenService, userManagementService)
+	// 2. Initialize tenant service (with userManagementService dependency, media storage for archives,
+	// the quota service created with the courses module and the services member imports use)
+	tenantArchiveRepo := tenantadapters.NewPostgresTenantArchiveRepository(dbManager)
+	memberImport := tenantservices.MemberImportDependencies{
+		Enroller:    tenantadapters.NewMemberEnrollmentAdapter(dbManager, enrollmentService),
+		Tokens:      tenantadapters.NewPasswordResetTokenIssuer(authRepo),
+		Invitations: emailService,
+	}
+	tenantService := tenantservices.NewTenantService(tenantRepo, tenantArchiveRepo, storageService, quotaService, dbManager, migrationRunner, tokenService, userManagementService, memberImport, cfg.Tenants.DeletionGracePeriod)
 
-	// 4. Initialize tenant controller
+	// 3. Initialize tenant controller
 	tenantController := tenantcontrollers.NewTenantController(tenantService)
 
+	// 4. Drop the databases of tenants whose deletion grace period ended, fail interrupted
+	// clone and member import jobs and check tenant schema drift (stopped on Shutdown)
+	tenantMaintenanceCtx, stopTenantMaintenance := context.WithCancel(context.Background())
+	go tenantService.RunTenantMaintenance(tenantMaintenanceCtx, cfg.Tenants.PurgeInterval)
+
 	log.Println("✅ Tenants module initialized")
 
+	// Initialize dependency injection for SCIM module
+	log.Println("🔧 Initializing SCIM module...")
+
+	// 1. Initialize SCIM repository (provisioned members and cohorts live in the control DB)
+	scimRepo := scimadapters.NewPostgresSCIMRepository(controlDB)
+
+	// 2. Initialize SCIM service (members are enrolled in the courses of their cohorts and count
+	// against the learner quota like the ones added by hand)
+	scimService := scimservices.NewSCIMService(scimRepo, tenantRepo, userManagementService, authService, memberImport.Enroller, quotaService, cfg.Server.BaseURL)
+
+	// 3. Initialize SCIM controller
+	scimController := scimcontrollers.NewSCIMController(scimService)
+
+	log.Println("✅ SCIM module initialized")
+
 	// Initialize tenant-aware controllers for dynamic DB connection
 	log.Println("🔧 Initializing tenant-aware controllers...")
 
-	tenantAwareCourseController := controllers.NewTenantAwareCourseController()
+	tenantAwareCourseController := controllers.NewTenantAwareCourseController(authRepo, quotaService)
 	tenantAwareCategoryController := controllers.NewTenantAwareCategoryController()
 	tenantAwareNotificationController := controllers.NewTenantAwareNotificationController(emailServiceAdapter)
 	tenantAwareProgressController := controllers.NewTenantAwareProgressController(dbManager)
@@ -452,6 +608,7 @@ func New(cfg *config.Config, dbManager *database.Manager) *Server {
 		authUserRepo,
 		fileStorage,
 		passwordHasher,
+		passwordPolicyService,
 	)
 
 	log.Println("✅ Tenant-aware controllers initialized")
@@ -479,7 +636,13 @@ func New(cfg *config.Config, dbManager *database.Manager) *Server {
 		progressController:     progressController,
 		certificateController:  certificateController,
 		tenantController:       tenantController,
+		tenantService:          tenantService,
+		featureController:      featureController,
+		featureService:         featureService,
+		scimController:         scimController,
+		stopTenantMaintenance:  stopTenantMaintenance,
 		tokenService:           tokenService,
+		jwtKeySet:              tokenService.KeySet(),
 		authRepo:               authRepo,
 		// Tenant-aware controllers
 		tenantAwareCourseController:       tenantAwareCourseController,
@@ -554,9 +717,31 @@ func (s *Server) setupRoutes() {
 		})
 	})
 
+	// Public verification keys for services validating our access tokens
+	s.app.Get("/.well-known/jwks.json", s.jwksHandler)
+
 	// Serve static files (uploaded avatars, etc.)
 	s.app.Static("/uploads", "./uploads")
 
+	// ============================================================
+	// SCIM 2.0 Routes (Tenant API key with the scim scopes required)
+	// ============================================================
+	scim := s.app.Group("/scim/v2", middleware.SCIMAuthMiddleware(s.authRepo, s.dbManager))
+	scim.Get("/ServiceProviderConfig", s.scimController.GetServiceProviderConfig)
+	scim.Get("/ResourceTypes", s.scimController.ListResourceTypes)
+	scim.Get("/Users", s.scimController.ListUsers)
+	scim.Post("/Users", s.scimController.CreateUser)
+	scim.Get("/Users/:id", s.scimController.GetUser)
+	scim.Put("/Users/:id", s.scimController.ReplaceUser)
+	scim.Patch("/Users/:id", s.scimController.PatchUser)
+	scim.Delete("/Users/:id", s.scimController.DeleteUser)
+	scim.Get("/Groups", s.scimController.ListGroups)
+	scim.Post("/Groups", s.scimController.CreateGroup)
+	scim.Get("/Groups/:id", s.scimController.GetGroup)
+	scim.Put("/Groups/:id", s.scimController.ReplaceGroup)
+	scim.Patch("/Groups/:id", s.scimController.PatchGroup)
+	scim.Delete("/Groups/:id", s.scimController.DeleteGroup)
+
 	// API version group
 	api := s.app.Group("/api")
 
@@ -582,7 +767,24 @@ func (s *Server) setupRoutes() {
 	auth.Post("/resend-verification", s.authController.ResendVerification)
 	auth.Post("/forgot-password", s.authController.ForgotPassword)
 	auth.Post("/reset-password", s.authController.ResetPassword)
+	auth.Post("/unlock-account", s.authController.UnlockAccount)
+	auth.Post("/magic-link", s.authController.RequestMagicLink)
+	auth.Post("/magic-link/verify", s.authController.LoginWithMagicLink)
+	auth.Get("/password-policy", s.authController.GetPasswordRequirements)
 	auth.Post("/refresh", s.authController.RefreshToken)
+	auth.Post("/mfa/verify", s.authController.VerifyMFALogin)
+	auth.Post("/mfa/challenge/enroll", s.authController.EnrollMFAWithChallenge)
+	auth.Post("/webauthn/login/begin", s.authController.BeginWebAuthnLogin)
+	auth.Post("/webauthn/login/finish", s.authController.FinishWebAuthnLogin)
+	auth.Post("/webauthn/mfa/begin", s.authController.BeginWebAuthnMFA)
+	auth.Post("/webauthn/mfa/finish", s.authController.FinishWebAuthnMFA)
+	auth.Get("/oidc/authorize", s.authController.BeginOIDCLogin)
+	auth.Post("/oidc/callback", s.authController.CompleteOIDCLogin)
+	auth.Get("/saml/authorize", s.authController.BeginSAMLLogin)
+	auth.Post("/saml/exchange", s.authController.ExchangeSAMLLoginCode)
+	auth.Get("/saml/:tenantId/metadata", s.authController.GetSAMLMetadata)
+	auth.Post("/saml/:tenantId/acs", s.authController.SAMLAssertionConsumer)
+	auth.Post("/federated/link", s.authController.ConfirmFederatedLink)
 
 	// Protected Routes (Authentication required)
 	authProtected := auth.Group("")
@@ -593,7 +795,28 @@ func (s *Server) setupRoutes() {
 		authProtected.Get("/me", s.authController.GetCurrentUser)
 		authProtected.Put("/profile", s.authController.UpdateProfile)
 		authProtected.Post("/revoke-sessions", s.authController.RevokeAllSessions)
+		authProtected.Get("/sessions", s.authController.ListSessions)
+		authProtected.Delete("/sessions/:id", s.authController.RevokeSession)
 		authProtected.Post("/switch-role", s.authController.SwitchRole) // Multi-role support
+		authProtected.Post("/impersonation/stop", s.authController.StopImpersonation)
+
+		// Personal access tokens
+		authProtected.Get("/tokens", s.authController.ListPersonalAccessTokens)
+		authProtected.Post("/tokens", s.authController.CreatePersonalAccessToken)
+		authProtected.Delete("/tokens/:id", s.authController.RevokePersonalAccessToken)
+
+		// Multi-factor authentication (TOTP)
+		authProtected.Get("/mfa", s.authController.GetMFAStatus)
+		authProtected.Post("/mfa/enroll", s.authController.EnrollMFA)
+		authProtected.Post("/mfa/enable", s.authController.EnableMFA)
+		authProtected.Post("/mfa/disable", s.authController.DisableMFA)
+		authProtected.Post("/mfa/recovery-codes", s.authController.RegenerateRecoveryCodes)
+
+		// Passkeys and security keys (WebAuthn)
+		authProtected.Get("/webauthn/credentials", s.authController.ListWebAuthnCredentials)
+		authProtected.Delete("/webauthn/credentials/:id", s.authController.DeleteWebAuthnCredential)
+		authProtected.Post("/webauthn/register/begin", s.authController.BeginWebAuthnRegistration)
+		authProtected.Post("/webauthn/register/finish", s.authController.FinishWebAuthnRegistration)
 	}
 
 	// ============================================================
@@ -638,6 +861,45 @@ func (s *Server) setupRoutes() {
 		adminTenantRoutes.Post("/invite", s.tenantController.InviteUser)
 		adminTenantRoutes.Post("/users", s.tenantController.CreateUserInTenant)
 		adminTenantRoutes.Get("/members", s.tenantController.GetTenantMembers)
+
+		// Bulk member imports from CSV or XLSX files (imported in the background)
+		adminTenantRoutes.Post("/members/import", s.tenantController.ImportMembers)
+		adminTenantRoutes.Get("/members/imports", s.tenantController.ListMemberImportJobs)
+		adminTenantRoutes.Get("/members/imports/:id", s.tenantController.GetMemberImportJob)
+
+		// Custom domains (verified by a DNS TXT record before they resolve to the tenant)
+		adminTenantRoutes.Get("/domains", s.tenantController.ListTenantDomains)
+		adminTenantRoutes.Post("/domains", s.tenantController.AddTenantDomain)
+		adminTenantRoutes.Post("/domains/:id/verify", s.tenantController.VerifyTenantDomain)
+		adminTenantRoutes.Delete("/domains/:id", s.tenantController.DeleteTenantDomain)
+
+		// Clones of the tenant course content (copied in the background)
+		adminTenantRoutes.Post("/clone", s.tenantController.CloneTenant)
+		adminTenantRoutes.Get("/clone-jobs", s.tenantController.ListTenantCloneJobs)
+		adminTenantRoutes.Get("/clone-jobs/:id", s.tenantController.GetTenantCloneJob)
+
+		// Consumption against the limits of the tenant plan
+		adminTenantRoutes.Get("/usage", s.tenantController.GetCurrentTenantUsage)
+
+		// White-label branding (logo, colors, email texts, support contact)
+		adminTenantRoutes.Get("/branding", s.tenantController.GetTenantBranding)
+		adminTenantRoutes.Put("/branding", s.tenantController.UpdateTenantBranding)
+	}
+
+	// Public tenant config to theme the pages of a tenant before login. The tenant comes from
+	// the OptionalTenantMiddleware applied to every API route.
+	v1.Get("/tenant-config", s.tenantController.GetTenantConfig)
+
+	// ============================================================
+	// Feature Routes (Protected - Authentication + Tenant membership required)
+	// ============================================================
+	features := v1.Group("/features")
+	features.Use(middleware.AuthMiddleware(s.tokenService, s.authRepo))
+	features.Use(middleware.TenantMiddleware(s.dbManager))
+	features.Use(middleware.MembershipMiddleware(s.controlDB))
+	{
+		// Features on for the current tenant, so clients can hide the ones that are off
+		features.Get("/", s.featureController.GetCurrentTenantFeatures)
 	}
 
 	// ============================================================
@@ -668,15 +930,21 @@ func (s *Server) setupRoutes() {
 		// Student actions - using tenant-aware controller
 		coursesProtected.Post("/:id/enroll", s.tenantAwareCourseController.EnrollCourse)
 		coursesProtected.Post("/:id/unenroll", s.tenantAwareCourseController.UnenrollCourse)
-		coursesProtected.Post("/:id/rate", s.tenantAwareCourseController.RateCourse)
+		coursesProtected.Post("/:id/rate", middleware.RequireFeature(s.featureService, featuredomain.FeatureReviews), s.tenantAwareCourseController.RateCourse)
 
 		// Instructor/Admin actions - using tenant-aware controller
-		coursesProtected.Post("/", s.tenantAwareCourseController.CreateCourse)
-		coursesProtected.Put("/:id", s.tenantAwareCourseController.UpdateCourse)
-		coursesProtected.Delete("/:id", s.tenantAwareCourseController.DeleteCourse)
-		coursesProtected.Post("/:id/publish", s.tenantAwareCourseController.PublishCourse)
-		coursesProtected.Post("/:id/unpublish", s.tenantAwareCourseController.UnpublishCourse)
-		coursesProtected.Post("/:id/archive", s.tenantAwareCourseController.ArchiveCourse)
+		// Ownership of the course is checked by the course policy
+		coursesProtected.Post("/", middleware.RequirePermission(authdomain.PermissionCourseEdit), s.tenantAwareCourseController.CreateCourse)
+		coursesProtected.Put("/:id", middleware.RequirePermission(authdomain.PermissionCourseEdit), s.tenantAwareCourseController.UpdateCourse)
+		coursesProtected.Delete("/:id", middleware.RequirePermission(authdomain.PermissionCourseDelete), s.tenantAwareCourseController.DeleteCourse)
+		coursesProtected.Post("/:id/publish", middleware.RequirePermission(authdomain.PermissionCoursePublish), s.tenantAwareCourseController.PublishCourse)
+		coursesProtected.Post("/:id/unpublish", middleware.RequirePermission(authdomain.PermissionCoursePublish), s.tenantAwareCourseController.UnpublishCourse)
+		coursesProtected.Post("/:id/archive", middleware.RequirePermission(authdomain.PermissionCoursePublish), s.tenantAwareCourseController.ArchiveCourse)
+
+		// Co-instructors - only the course owner or an admin can change them
+		coursesProtected.Get("/:id/instructors", s.tenantAwareCourseController.ListCourseInstructors)
+		coursesProtected.Post("/:id/instructors", middleware.RequirePermission(authdomain.PermissionCourseEdit), s.tenantAwareCourseController.AddCourseInstructor)
+		coursesProtected.Delete("/:id/instructors/:userId", middleware.RequirePermission(authdomain.PermissionCourseEdit), s.tenantAwareCourseController.RemoveCourseInstructor)
 	}
 
 	// ============================================================
@@ -729,10 +997,10 @@ func (s *Server) setupRoutes() {
 		lessonsProtected.Post("/:id/complete", s.lessonController.MarkLessonComplete)
 		lessonsProtected.Get("/:id/completion", s.lessonController.GetLessonCompletion)
 
-		// Instructor/Admin actions (TODO: Add instructor/admin middleware)
-		lessonsProtected.Put("/:id", s.lessonController.UpdateLesson)
-		lessonsProtected.Delete("/:id", s.lessonController.DeleteLesson)
-		lessonsProtected.Post("/:id/video", s.lessonController.UploadLessonVideo)
+		// Instructor/Admin actions
+		lessonsProtected.Put("/:id", middleware.RequirePermission(authdomain.PermissionCourseEdit), s.lessonController.UpdateLesson)
+		lessonsProtected.Delete("/:id", middleware.RequirePermission(authdomain.PermissionCourseEdit), s.lessonController.DeleteLesson)
+		lessonsProtected.Post("/:id/video", middleware.RequirePermission(authdomain.PermissionCourseEdit), s.lessonController.UploadLessonVideo)
 	}
 
 	// Course-specific lesson routes (nested under courses)
@@ -742,8 +1010,8 @@ func (s *Server) setupRoutes() {
 	// Protected: Course lesson routes
 	coursesProtected.Get("/:courseId/lessons/progress", s.lessonController.GetLessonsWithProgress)
 	coursesProtected.Get("/:courseId/progress", s.lessonController.GetCourseProgress)
-	coursesProtected.Post("/:courseId/lessons", s.lessonController.CreateLesson)
-	coursesProtected.Post("/:courseId/lessons/reorder", s.lessonController.ReorderLessons)
+	coursesProtected.Post("/:courseId/lessons", middleware.RequirePermission(authdomain.PermissionCourseEdit), s.lessonController.CreateLesson)
+	coursesProtected.Post("/:courseId/lessons/reorder", middleware.RequirePermission(authdomain.PermissionCourseEdit), s.lessonController.ReorderLessons)
 
 	// ============================================================
 	// Quiz Routes
@@ -767,16 +1035,16 @@ func (s *Server) setupRoutes() {
 		quizzesProtected.Get("/attempts/:attemptId", s.quizController.GetAttemptDetails)
 		quizzesProtected.Get("/:quizId/my-attempts", s.quizController.GetMyAttempts)
 
-		// Instructor/Admin actions (TODO: Add instructor/admin middleware)
-		quizzesProtected.Put("/:id", s.quizController.UpdateQuiz)
-		quizzesProtected.Delete("/:id", s.quizController.DeleteQuiz)
-		quizzesProtected.Post("/:quizId/questions", s.quizController.CreateQuestion)
-		quizzesProtected.Put("/questions/:id", s.quizController.UpdateQuestion)
-		quizzesProtected.Delete("/questions/:id", s.quizController.DeleteQuestion)
-		quizzesProtected.Post("/:quizId/questions/reorder", s.quizController.ReorderQuestions)
-		quizzesProtected.Post("/answers/:answerId/grade", s.quizController.GradeEssayQuestion)
-		quizzesProtected.Get("/:quizId/statistics", s.quizController.GetQuizStatistics)
-		quizzesProtected.Get("/:quizId/attempts", s.quizController.GetQuizAttempts)
+		// Instructor/Admin actions
+		quizzesProtected.Put("/:id", middleware.RequirePermission(authdomain.PermissionAssessmentEdit), s.quizController.UpdateQuiz)
+		quizzesProtected.Delete("/:id", middleware.RequirePermission(authdomain.PermissionAssessmentEdit), s.quizController.DeleteQuiz)
+		quizzesProtected.Post("/:quizId/questions", middleware.RequirePermission(authdomain.PermissionAssessmentEdit), s.quizController.CreateQuestion)
+		quizzesProtected.Put("/questions/:id", middleware.RequirePermission(authdomain.PermissionAssessmentEdit), s.quizController.UpdateQuestion)
+		quizzesProtected.Delete("/questions/:id", middleware.RequirePermission(authdomain.PermissionAssessmentEdit), s.quizController.DeleteQuestion)
+		quizzesProtected.Post("/:quizId/questions/reorder", middleware.RequirePermission(authdomain.PermissionAssessmentEdit), s.quizController.ReorderQuestions)
+		quizzesProtected.Post("/answers/:answerId/grade", middleware.RequirePermission(authdomain.PermissionSubmissionGrade), s.quizController.GradeEssayQuestion)
+		quizzesProtected.Get("/:quizId/statistics", middleware.RequirePermission(authdomain.PermissionAnalyticsView), s.quizController.GetQuizStatistics)
+		quizzesProtected.Get("/:quizId/attempts", middleware.RequirePermission(authdomain.PermissionSubmissionView), s.quizController.GetQuizAttempts)
 	}
 
 	// Course-specific quiz routes (nested under courses)
@@ -784,7 +1052,7 @@ func (s *Server) setupRoutes() {
 	courses.Get("/:courseId/quizzes", s.quizController.GetQuizzesByCourse)
 
 	// Protected: Course quiz routes
-	coursesProtected.Post("/:courseId/quizzes", s.quizController.CreateQuiz)
+	coursesProtected.Post("/:courseId/quizzes", middleware.RequirePermission(authdomain.PermissionAssessmentEdit), s.quizController.CreateQuiz)
 
 	// Lesson-specific quiz routes (nested under lessons)
 	// Public: Get quiz for a lesson
@@ -805,6 +1073,7 @@ func (s *Server) setupRoutes() {
 	assignmentsProtected.Use(middleware.AuthMiddleware(s.tokenService, s.authRepo))
 	assignmentsProtected.Use(middleware.TenantMiddleware(s.dbManager))
 	assignmentsProtected.Use(middleware.MembershipMiddleware(s.controlDB))
+	peerReviewFeature := middleware.RequireFeature(s.featureService, featuredomain.FeaturePeerReview)
 	{
 		// Student actions - View assignments
 		assignmentsProtected.Get("/my", s.assignmentController.GetMyAssignments)
@@ -827,38 +1096,38 @@ func (s *Server) setupRoutes() {
 		assignmentsProtected.Delete("/comments/:commentId", s.assignmentController.DeleteComment)
 
 		// Student actions - Peer reviews
-		assignmentsProtected.Get("/peer-reviews/my", s.assignmentController.GetMyPeerReviews)
-		assignmentsProtected.Post("/peer-reviews/:reviewId/submit", s.assignmentController.SubmitPeerReview)
-		assignmentsProtected.Get("/submissions/:submissionId/peer-reviews", s.assignmentController.GetSubmissionPeerReviews)
+		assignmentsProtected.Get("/peer-reviews/my", peerReviewFeature, s.assignmentController.GetMyPeerReviews)
+		assignmentsProtected.Post("/peer-reviews/:reviewId/submit", peerReviewFeature, s.assignmentController.SubmitPeerReview)
+		assignmentsProtected.Get("/submissions/:submissionId/peer-reviews", peerReviewFeature, s.assignmentController.GetSubmissionPeerReviews)
 
 		// Instructor/Admin actions - Assignment CRUD
-		assignmentsProtected.Post("/", s.assignmentController.CreateAssignment)
-		assignmentsProtected.Put("/:id", s.assignmentController.UpdateAssignment)
-		assignmentsProtected.Delete("/:id", s.assignmentController.DeleteAssignment)
-		assignmentsProtected.Post("/:id/publish", s.assignmentController.PublishAssignment)
-		assignmentsProtected.Post("/:id/unpublish", s.assignmentController.UnpublishAssignment)
+		assignmentsProtected.Post("/", middleware.RequirePermission(authdomain.PermissionAssessmentEdit), s.assignmentController.CreateAssignment)
+		assignmentsProtected.Put("/:id", middleware.RequirePermission(authdomain.PermissionAssessmentEdit), s.assignmentController.UpdateAssignment)
+		assignmentsProtected.Delete("/:id", middleware.RequirePermission(authdomain.PermissionAssessmentEdit), s.assignmentController.DeleteAssignment)
+		assignmentsProtected.Post("/:id/publish", middleware.RequirePermission(authdomain.PermissionAssessmentEdit), s.assignmentController.PublishAssignment)
+		assignmentsProtected.Post("/:id/unpublish", middleware.RequirePermission(authdomain.PermissionAssessmentEdit), s.assignmentController.UnpublishAssignment)
 
 		// Instructor/Admin actions - File uploads for assignments
-		assignmentsProtected.Post("/:assignmentId/files", s.assignmentController.UploadAssignmentFile)
-		assignmentsProtected.Delete("/:assignmentId/files/:fileId", s.assignmentController.DeleteAssignmentFile)
+		assignmentsProtected.Post("/:assignmentId/files", middleware.RequirePermission(authdomain.PermissionAssessmentEdit), s.assignmentController.UploadAssignmentFile)
+		assignmentsProtected.Delete("/:assignmentId/files/:fileId", middleware.RequirePermission(authdomain.PermissionAssessmentEdit), s.assignmentController.DeleteAssignmentFile)
 
 		// Instructor/Admin actions - Grading
-		assignmentsProtected.Get("/submissions/:submissionId", s.assignmentController.GetSubmission)
-		assignmentsProtected.Get("/:assignmentId/submissions", s.assignmentController.GetAssignmentSubmissions)
-		assignmentsProtected.Get("/students/:studentId/submissions", s.assignmentController.GetStudentSubmissions)
-		assignmentsProtected.Post("/submissions/:submissionId/grade", s.assignmentController.GradeSubmission)
-		assignmentsProtected.Post("/bulk-grade", s.assignmentController.BulkGrade)
-		assignmentsProtected.Post("/submissions/:submissionId/return", s.assignmentController.ReturnSubmission)
-		assignmentsProtected.Delete("/submissions/:submissionId", s.assignmentController.DeleteSubmission)
+		assignmentsProtected.Get("/submissions/:submissionId", middleware.RequirePermission(authdomain.PermissionSubmissionView), s.assignmentController.GetSubmission)
+		assignmentsProtected.Get("/:assignmentId/submissions", middleware.RequirePermission(authdomain.PermissionSubmissionView), s.assignmentController.GetAssignmentSubmissions)
+		assignmentsProtected.Get("/students/:studentId/submissions", middleware.RequirePermission(authdomain.PermissionSubmissionView), s.assignmentController.GetStudentSubmissions)
+		assignmentsProtected.Post("/submissions/:submissionId/grade", middleware.RequirePermission(authdomain.PermissionSubmissionGrade), s.assignmentController.GradeSubmission)
+		assignmentsProtected.Post("/bulk-grade", middleware.RequirePermission(authdomain.PermissionSubmissionGrade), s.assignmentController.BulkGrade)
+		assignmentsProtected.Post("/submissions/:submissionId/return", middleware.RequirePermission(authdomain.PermissionSubmissionGrade), s.assignmentController.ReturnSubmission)
+		assignmentsProtected.Delete("/submissions/:submissionId", middleware.RequirePermission(authdomain.PermissionSubmissionGrade), s.assignmentController.DeleteSubmission)
 
 		// Instructor/Admin actions - Peer review management
-		assignmentsProtected.Post("/peer-reviews", s.assignmentController.AssignPeerReview)
-		assignmentsProtected.Delete("/peer-reviews/:reviewId", s.assignmentController.DeletePeerReview)
+		assignmentsProtected.Post("/peer-reviews", peerReviewFeature, middleware.RequirePermission(authdomain.PermissionAssessmentEdit), s.assignmentController.AssignPeerReview)
+		assignmentsProtected.Delete("/peer-reviews/:reviewId", peerReviewFeature, middleware.RequirePermission(authdomain.PermissionAssessmentEdit), s.assignmentController.DeletePeerReview)
 
 		// Instructor/Admin actions - Statistics
-		assignmentsProtected.Get("/:assignmentId/statistics", s.assignmentController.GetAssignmentStatistics)
-		assignmentsProtected.Get("/students/:studentId/progress", s.assignmentController.GetStudentProgress)
-		assignmentsProtected.Get("/courses/:courseId/statistics", s.assignmentController.GetCourseStatistics)
+		assignmentsProtected.Get("/:assignmentId/statistics", middleware.RequirePermission(authdomain.PermissionAnalyticsView), s.assignmentController.GetAssignmentStatistics)
+		assignmentsProtected.Get("/students/:studentId/progress", middleware.RequirePermission(authdomain.PermissionAnalyticsView), s.assignmentController.GetStudentProgress)
+		assignmentsProtected.Get("/courses/:courseId/statistics", middleware.RequirePermission(authdomain.PermissionAnalyticsView), s.assignmentController.GetCourseStatistics)
 
 		// File operations
 		assignmentsProtected.Get("/files/:fileId", s.assignmentController.GetFile)
@@ -877,16 +1146,16 @@ func (s *Server) setupRoutes() {
 	rubricsProtected.Use(middleware.MembershipMiddleware(s.controlDB))
 	{
 		// Instructor/Admin actions - Rubric management
-		rubricsProtected.Post("/", s.assignmentController.CreateRubric)
+		rubricsProtected.Post("/", middleware.RequirePermission(authdomain.PermissionAssessmentEdit), s.assignmentController.CreateRubric)
 		rubricsProtected.Get("/:id", s.assignmentController.GetRubric)
 		rubricsProtected.Get("/", s.assignmentController.GetTenantRubrics)
 		rubricsProtected.Get("/templates", s.assignmentController.GetRubricTemplates)
-		rubricsProtected.Put("/:id", s.assignmentController.UpdateRubric)
-		rubricsProtected.Delete("/:id", s.assignmentController.DeleteRubric)
+		rubricsProtected.Put("/:id", middleware.RequirePermission(authdomain.PermissionAssessmentEdit), s.assignmentController.UpdateRubric)
+		rubricsProtected.Delete("/:id", middleware.RequirePermission(authdomain.PermissionAssessmentEdit), s.assignmentController.DeleteRubric)
 
 		// Attach/detach rubrics to assignments
-		rubricsProtected.Post("/:rubricId/attach/:assignmentId", s.assignmentController.AttachRubricToAssignment)
-		rubricsProtected.Delete("/:rubricId/detach/:assignmentId", s.assignmentController.DetachRubricFromAssignment)
+		rubricsProtected.Post("/:rubricId/attach/:assignmentId", middleware.RequirePermission(authdomain.PermissionAssessmentEdit), s.assignmentController.AttachRubricToAssignment)
+		rubricsProtected.Delete("/:rubricId/detach/:assignmentId", middleware.RequirePermission(authdomain.PermissionAssessmentEdit), s.assignmentController.DetachRubricFromAssignment)
 	}
 
 	// Course-specific assignment routes (nested under courses)
@@ -894,7 +1163,7 @@ func (s *Server) setupRoutes() {
 	courses.Get("/:courseId/assignments", s.assignmentController.GetCourseAssignments)
 
 	// Protected: Course assignment routes
-	coursesProtected.Post("/:courseId/assignments", s.assignmentController.CreateAssignment)
+	coursesProtected.Post("/:courseId/assignments", middleware.RequirePermission(authdomain.PermissionAssessmentEdit), s.assignmentController.CreateAssignment)
 
 	// ============================================================
 	// Notification Routes
@@ -1002,13 +1271,13 @@ func (s *Server) setupRoutes() {
 		modulesProtected.Post("/:id/progress", s.moduleController.UpdateModuleProgress)
 
 		// Instructor/Admin actions - Module CRUD
-		modulesProtected.Post("/", s.moduleController.CreateModule)
-		modulesProtected.Patch("/:id", s.moduleController.UpdateModule)
-		modulesProtected.Delete("/:id", s.moduleController.DeleteModule)
+		modulesProtected.Post("/", middleware.RequirePermission(authdomain.PermissionCourseEdit), s.moduleController.CreateModule)
+		modulesProtected.Patch("/:id", middleware.RequirePermission(authdomain.PermissionCourseEdit), s.moduleController.UpdateModule)
+		modulesProtected.Delete("/:id", middleware.RequirePermission(authdomain.PermissionCourseEdit), s.moduleController.DeleteModule)
 
 		// Instructor/Admin actions - Publishing
-		modulesProtected.Post("/:id/publish", s.moduleController.PublishModule)
-		modulesProtected.Post("/:id/unpublish", s.moduleController.UnpublishModule)
+		modulesProtected.Post("/:id/publish", middleware.RequirePermission(authdomain.PermissionCoursePublish), s.moduleController.PublishModule)
+		modulesProtected.Post("/:id/unpublish", middleware.RequirePermission(authdomain.PermissionCoursePublish), s.moduleController.UnpublishModule)
 	}
 
 	// Course-specific module routes (nested under courses)
@@ -1017,8 +1286,8 @@ func (s *Server) setupRoutes() {
 
 	// Protected: Course module routes
 	coursesProtected.Get("/:courseId/modules/progress", s.moduleController.GetCourseModulesWithProgress)
-	coursesProtected.Post("/:courseId/modules", s.moduleController.CreateModule)
-	coursesProtected.Post("/:courseId/modules/reorder", s.moduleController.ReorderModules)
+	coursesProtected.Post("/:courseId/modules", middleware.RequirePermission(authdomain.PermissionCourseEdit), s.moduleController.CreateModule)
+	coursesProtected.Post("/:courseId/modules/reorder", middleware.RequirePermission(authdomain.PermissionCourseEdit), s.moduleController.ReorderModules)
 
 	// ============================================================
 	// Review Routes
@@ -1027,7 +1296,7 @@ func (s *Server) setupRoutes() {
 
 	// Public review routes (read-only for public reviews)
 	{
-		reviews.Get("/:id", s.reviewController.GetReview)
+		reviews.Get("/:id", middleware.RequireFeature(s.featureService, featuredomain.FeatureReviews), s.reviewController.GetReview)
 	}
 
 	// Protected review routes (authentication + tenant membership required)
@@ -1035,6 +1304,7 @@ func (s *Server) setupRoutes() {
 	reviewsProtected.Use(middleware.AuthMiddleware(s.tokenService, s.authRepo))
 	reviewsProtected.Use(middleware.TenantMiddleware(s.dbManager))
 	reviewsProtected.Use(middleware.MembershipMiddleware(s.controlDB))
+	reviewsProtected.Use(middleware.RequireFeature(s.featureService, featuredomain.FeatureReviews))
 	{
 		// Student actions - Review CRUD
 		reviewsProtected.Post("/", s.reviewController.CreateReview)
@@ -1058,11 +1328,12 @@ func (s *Server) setupRoutes() {
 
 	// Course-specific review routes (nested under courses)
 	// Public: Get reviews and rating for a course
-	courses.Get("/:courseId/reviews", s.reviewController.GetCourseReviews)
-	courses.Get("/:courseId/rating", s.reviewController.GetCourseRating)
+	reviewsFeature := middleware.RequireFeature(s.featureService, featuredomain.FeatureReviews)
+	courses.Get("/:courseId/reviews", reviewsFeature, s.reviewController.GetCourseReviews)
+	courses.Get("/:courseId/rating", reviewsFeature, s.reviewController.GetCourseRating)
 
 	// Protected: Get user's review for a course
-	coursesProtected.Get("/:courseId/my-review", s.reviewController.GetUserReviewForCourse)
+	coursesProtected.Get("/:courseId/my-review", reviewsFeature, s.reviewController.GetUserReviewForCourse)
 
 	// ============================================================
 	// Analytics Routes (Protected - Authentication required)
@@ -1111,6 +1382,7 @@ func (s *Server) setupRoutes() {
 	certificates.Use(middleware.AuthMiddleware(s.tokenService, s.authRepo))
 	certificates.Use(middleware.TenantMiddleware(s.dbManager))
 	certificates.Use(middleware.MembershipMiddleware(s.controlDB))
+	certificates.Use("/certificates", middleware.RequireFeature(s.featureService, featuredomain.FeatureCertificates))
 	s.certificateController.RegisterRoutes(certificates)
 
 	// ============================================================
@@ -1138,12 +1410,25 @@ func (s *Server) setupRoutes() {
 		users.Post("/:id/unverify", s.userController.UnverifyUser)
 		users.Post("/:id/reset-password", s.userController.ResetUserPassword)
 		users.Post("/:id/force-password-change", s.userController.ForcePasswordChange)
+		users.Post("/:id/unlock", s.authController.UnlockUser)
+		users.Post("/:id/impersonate", s.authController.StartImpersonation)
+		users.Put("/:id/custom-role", s.authController.AssignTenantRole)
 
 		// Queries by Role
 		users.Get("/role/:role", s.userController.GetUsersByRole)
 		users.Get("/role/:role/count", s.userController.CountUsersByRole)
 	}
 
+	// Custom roles (Admin only)
+	roles := admin.Group("/roles")
+	{
+		roles.Get("/permissions", s.authController.GetPermissionCatalog)
+		roles.Get("/", s.authController.ListTenantRoles)
+		roles.Post("/", s.authController.CreateTenantRole)
+		roles.Put("/:id", s.authController.UpdateTenantRole)
+		roles.Delete("/:id", s.authController.DeleteTenantRole)
+	}
+
 	// Profile Management (Admin only - Tenant-aware)
 	profiles := admin.Group("/profiles")
 	{
@@ -1151,6 +1436,28 @@ func (s *Server) setupRoutes() {
 		profiles.Put("/:id", s.tenantAwareProfileController.UpdateProfile)
 	}
 
+	// Security settings (Admin only)
+	security := admin.Group("/security")
+	{
+		security.Get("/mfa-policy", s.authController.GetMFAPolicy)
+		security.Put("/mfa-policy", s.authController.UpdateMFAPolicy)
+		security.Get("/magic-link", s.authController.GetMagicLinkSettings)
+		security.Put("/magic-link", s.authController.UpdateMagicLinkSettings)
+		security.Get("/password-policy", s.authController.GetPasswordPolicy)
+		security.Put("/password-policy", s.authController.UpdatePasswordPolicy)
+		security.Get("/oidc", s.authController.GetOIDCProvider)
+		security.Put("/oidc", s.authController.UpdateOIDCProvider)
+		security.Delete("/oidc", s.authController.DeleteOIDCProvider)
+		security.Get("/saml", s.authController.GetSAMLProvider)
+		security.Put("/saml", s.authController.UpdateSAMLProvider)
+		security.Delete("/saml", s.authController.DeleteSAMLProvider)
+		security.Get("/api-keys", s.authController.ListTenantAPIKeys)
+		security.Post("/api-keys", s.authController.CreateTenantAPIKey)
+		security.Delete("/api-keys/:id", s.authController.RevokeTenantAPIKey)
+		security.Get("/impersonations", s.authController.ListImpersonationSessions)
+		security.Get("/impersonations/:id/events", s.authController.ListImpersonationEvents)
+	}
+
 	// Dashboard (Admin only)
 	admin.Get("/dashboard", s.adminDashboardHandler)
 
@@ -1176,9 +1483,53 @@ func (s *Server) setupRoutes() {
 	// Tenant Management (SuperAdmin only - critical operations)
 	superadminTenants := superadmin.Group("/tenants")
 	{
+		// Tenant lifecycle
+		superadminTenants.Get("/", s.tenantController.ListTenantLifecycles)
+		superadminTenants.Get("/schema-drift", s.tenantController.CheckSchemaDrift)
+		superadminTenants.Get("/:tenantId/status", s.tenantController.GetTenantLifecycle)
+		superadminTenants.Post("/:tenantId/suspend", s.tenantController.SuspendTenant)
+		superadminTenants.Post("/:tenantId/reactivate", s.tenantController.ReactivateTenant)
+		superadminTenants.Post("/:tenantId/schedule-deletion", s.tenantController.ScheduleTenantDeletion)
+
+		// Tenant archives
+		superadminTenants.Get("/:tenantId/export", s.tenantController.ExportTenantArchive)
+		superadminTenants.Post("/import", s.tenantController.ImportTenantArchive)
+
+		// Tenant clones
+		superadminTenants.Post("/:tenantId/clone", s.tenantController.StartTenantClone)
+		superadminTenants.Get("/:tenantId/clone-jobs", s.tenantController.ListCloneJobs)
+		superadminTenants.Get("/clone-jobs/:id", s.tenantController.GetCloneJob)
+
+		// Tenant plans and usage
+		superadminTenants.Put("/:tenantId/plan", s.tenantController.AssignTenantPlan)
+		superadminTenants.Get("/:tenantId/usage", s.tenantController.GetTenantUsage)
+
+		// Tenant feature flags and overrides
+		superadminTenants.Get("/:tenantId/features", s.featureController.GetTenantFeatures)
+		superadminTenants.Put("/:tenantId/features/:key", s.featureController.SetTenantOverride)
+		superadminTenants.Delete("/:tenantId/features/:key", s.featureController.DeleteTenantOverride)
+
 		superadminTenants.Get("/:tenantId/users", s.userController.GetUsersByTenant)
 		superadminTenants.Get("/:tenantId/users/count", s.userController.CountUsersByTenant)
 	}
+
+	// Feature flags (global defaults and rollout percentages)
+	superadminFeatures := superadmin.Group("/features")
+	{
+		superadminFeatures.Get("/", s.featureController.ListFlags)
+		superadminFeatures.Post("/", s.featureController.CreateFlag)
+		superadminFeatures.Put("/:key", s.featureController.UpdateFlag)
+		superadminFeatures.Delete("/:key", s.featureController.DeleteFlag)
+	}
+
+	// Plans (limits and features of the tenants on them)
+	superadminPlans := superadmin.Group("/plans")
+	{
+		superadminPlans.Get("/", s.tenantController.ListPlans)
+		superadminPlans.Post("/", s.tenantController.CreatePlan)
+		superadminPlans.Put("/:id", s.tenantController.UpdatePlan)
+		superadminPlans.Delete("/:id", s.tenantController.DeletePlan)
+	}
 }
 
 // healthCheckHandler maneja el health check endpoint
@@ -1191,6 +1542,18 @@ func (s *Server) healthCheckHandler(c *fiber.Ctx) error {
 	})
 }
 
+// jwksHandler publica las claves públicas de verificación JWT (RFC 7517)
+// Devuelve un conjunto vacío cuando los tokens se firman con HS256
+func (s *Server) jwksHandler(c *fiber.Ctx) error {
+	jwks := tokens.JWKS{Keys: []tokens.JWK{}}
+	if s.jwtKeySet != nil {
+		jwks = s.jwtKeySet.JWKS()
+	}
+
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return c.Status(fiber.StatusOK).JSON(jwks)
+}
+
 // tenantHealthCheckHandler maneja el health check con contexto de tenant
 func (s *Server) tenantHealthCheckHandler(c *fiber.Ctx) error {
 	// Get tenant info from context
@@ -1207,6 +1570,17 @@ func (s *Server) tenantHealthCheckHandler(c *fiber.Ctx) error {
 	// Get cache stats
 	cacheStats := middleware.GetCacheStats()
 
+	// Get tenant migration counts from the last schema drift check
+	tenantMigrations := fiber.Map{"checked_at": nil}
+	if report := s.tenantService.LastSchemaDriftReport(); report != nil {
+		tenantMigrations = fiber.Map{
+			"checked_at":     report.CheckedAt.Format(time.RFC3339),
+			"latest_version": report.LatestVersion,
+			"healthy":        report.Summary.Drifted() == 0,
+			"summary":        report.Summary,
+		}
+	}
+
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"status":    "healthy",
 		"timestamp": time.Now().Format(time.RFC3339),
@@ -1220,7 +1594,8 @@ func (s *Server) tenantHealthCheckHandler(c *fiber.Ctx) error {
 		"database": fiber.Map{
 			"healthy": dbHealthy,
 		},
-		"cache": cacheStats,
+		"cache":             cacheStats,
+		"tenant_migrations": tenantMigrations,
 	})
 }
 
@@ -1237,6 +1612,9 @@ func (s *Server) Start() error {
 // Shutdown apaga el servidor gracefully
 func (s *Server) Shutdown() error {
 	log.Println("🛑 Shutting down server...")
+	if s.stopTenantMaintenance != nil {
+		s.stopTenantMaintenance()
+	}
 	return s.app.Shutdown()
 }
 
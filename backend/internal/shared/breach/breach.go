@@ -0,0 +1,85 @@
+// Package breach checks passwords against a dataset of breached password hashes.
+//
+// The dataset uses the k-anonymity range format of Have I Been Pwned: the SHA-1 hashes
+// are split by their first five hex characters into one file per prefix, named
+// <PREFIX>.txt, with one "<SUFFIX>:<COUNT>" line per breached password. A lookup only
+// reads the range of its prefix, so the same checker can be backed by the range API.
+package breach
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// prefixLength is the number of hex characters of the hash that select a range
+const prefixLength = 5
+
+// Checker reports whether a password appears in a breached password dataset
+type Checker interface {
+	IsBreached(ctx context.Context, password string) (bool, error)
+}
+
+// LocalChecker looks passwords up in a local directory of hash-prefix range files
+type LocalChecker struct {
+	dir string
+}
+
+// NewLocalChecker creates a checker for the range files in dir
+func NewLocalChecker(dir string) (*LocalChecker, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open breached password dataset: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("breached password dataset %s is not a directory", dir)
+	}
+
+	return &LocalChecker{dir: dir}, nil
+}
+
+// HashRange returns the range prefix and the remaining suffix of the SHA-1 hash of a password
+func HashRange(password string) (prefix string, suffix string) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	return hash[:prefixLength], hash[prefixLength:]
+}
+
+// IsBreached checks if the password hash is listed in the range file of its prefix.
+// Prefixes without a range file have no breached passwords.
+func (c *LocalChecker) IsBreached(ctx context.Context, password string) (bool, error) {
+	prefix, suffix := HashRange(password)
+
+	file, err := os.Open(filepath.Join(c.dir, prefix+".txt"))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to open hash range %s: %w", prefix, err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return false, err
+		}
+
+		line := strings.TrimSpace(scanner.Text())
+		entry, count, _ := strings.Cut(line, ":")
+		if strings.EqualFold(entry, suffix) {
+			return count != "0", nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return false, fmt.Errorf("failed to read hash range %s: %w", prefix, err)
+	}
+
+	return false, nil
+}
@@ -0,0 +1,62 @@
+package breach
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHashRange(t *testing.T) {
+	// SHA-1 of "password" is 5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8
+	prefix, suffix := HashRange("password")
+
+	if prefix != "5BAA6" {
+		t.Errorf("prefix = %s, want 5BAA6", prefix)
+	}
+	if suffix != "1E4C9B93F3F0682250B6CF8331B7EE68FD8" {
+		t.Errorf("suffix = %s, want 1E4C9B93F3F0682250B6CF8331B7EE68FD8", suffix)
+	}
+}
+
+func TestLocalChecker_IsBreached(t *testing.T) {
+	dir := t.TempDir()
+	rangeFile := "003D68EB55068C33ACE09247EE4C639306B:3\r\n" +
+		"1e4c9b93f3f0682250b6cf8331b7ee68fd8:9545824\r\n" +
+		"012C192B2F16F82EA0EB9EF18D9D539B0DD:0\r\n"
+	if err := os.WriteFile(filepath.Join(dir, "5BAA6.txt"), []byte(rangeFile), 0o600); err != nil {
+		t.Fatalf("failed to write range file: %v", err)
+	}
+
+	checker, err := NewLocalChecker(dir)
+	if err != nil {
+		t.Fatalf("NewLocalChecker() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		password string
+		expected bool
+	}{
+		{"Listed password", "password", true},
+		{"Password in a range without file", "correct horse battery staple", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			breached, err := checker.IsBreached(context.Background(), tt.password)
+			if err != nil {
+				t.Fatalf("IsBreached() error = %v", err)
+			}
+			if breached != tt.expected {
+				t.Errorf("IsBreached(%q) = %v, want %v", tt.password, breached, tt.expected)
+			}
+		})
+	}
+}
+
+func TestNewLocalChecker_MissingDirectory(t *testing.T) {
+	if _, err := NewLocalChecker(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("expected error for missing dataset directory")
+	}
+}
@@ -20,6 +20,8 @@ type Config struct {
 	Redis    RedisConfig
 	Storage  StorageConfig
 	Logging  LoggingConfig
+	Security SecurityConfig
+	Tenants  TenantsConfig
 }
 
 // ServerConfig contiene la configuración del servidor
@@ -60,6 +62,13 @@ type JWTConfig struct {
 	Secret            string
 	Expiration        time.Duration
 	RefreshExpiration time.Duration
+	// KeysDir contiene las claves asimétricas (<kid>.pem); vacío usa HS256 con Secret
+	KeysDir string
+	// ActiveKeyID es el kid de la clave usada para firmar nuevos tokens
+	ActiveKeyID string
+	// LegacySecretUntil es el fin del período en que se aceptan tokens HS256 firmados con
+	// Secret tras migrar a claves asimétricas; después Secret ya no valida ningún token
+	LegacySecretUntil time.Time
 }
 
 // EmailConfig contiene la configuración de email
@@ -97,6 +106,28 @@ type LoggingConfig struct {
 	Format string
 }
 
+// SecurityConfig contiene la configuración de seguridad de contraseñas y passkeys
+type SecurityConfig struct {
+	// BreachedPasswordsDir contiene el dataset de contraseñas filtradas por prefijo de hash
+	// (<PREFIJO>.txt con líneas SUFIJO:CONTEO); vacío desactiva la verificación
+	BreachedPasswordsDir string
+	// WebAuthnRPID es el dominio registrable del frontend al que se asocian las passkeys
+	WebAuthnRPID string
+	// WebAuthnRPName es el nombre mostrado por el navegador al crear una passkey
+	WebAuthnRPName string
+	// WebAuthnOrigins son los orígenes del frontend que pueden usar passkeys
+	WebAuthnOrigins []string
+}
+
+// TenantsConfig contiene la configuración del ciclo de vida de los tenants
+type TenantsConfig struct {
+	// DeletionGracePeriod es el tiempo que un tenant programado para eliminación puede
+	// reactivarse antes de que se elimine su base de datos
+	DeletionGracePeriod time.Duration
+	// PurgeInterval es cada cuánto se buscan tenants cuyo período de gracia terminó
+	PurgeInterval time.Duration
+}
+
 // LoadConfig carga la configuración desde variables de entorno
 func LoadConfig() (*Config, error) {
 	// Intentar cargar .env en desarrollo
@@ -119,6 +150,8 @@ func LoadConfig() (*Config, error) {
 		Redis:    loadRedisConfig(),
 		Storage:  loadStorageConfig(),
 		Logging:  loadLoggingConfig(),
+		Security: loadSecurityConfig(),
+		Tenants:  loadTenantsConfig(),
 	}
 
 	// Validar configuración
@@ -178,10 +211,57 @@ func loadJWTConfig() JWTConfig {
 		refreshExpiration = 168 * time.Hour
 	}
 
+	// Una fecha inválida se deja vacía para que Validate la rechace
+	var legacySecretUntil time.Time
+	if until := getEnv("JWT_LEGACY_SECRET_UNTIL", ""); until != "" {
+		legacySecretUntil, err = time.Parse(time.RFC3339, until)
+		if err != nil {
+			log.Printf("⚠️  Invalid JWT_LEGACY_SECRET_UNTIL '%s', expected an RFC 3339 date", until)
+		}
+	}
+
 	return JWTConfig{
 		Secret:            getEnv("JWT_SECRET", ""),
 		Expiration:        expiration,
 		RefreshExpiration: refreshExpiration,
+		KeysDir:           getEnv("JWT_KEYS_DIR", ""),
+		ActiveKeyID:       getEnv("JWT_ACTIVE_KEY_ID", ""),
+		LegacySecretUntil: legacySecretUntil,
+	}
+}
+
+// loadSecurityConfig carga la configuración de seguridad de contraseñas y passkeys
+func loadSecurityConfig() SecurityConfig {
+	// Por defecto las passkeys se aceptan desde los mismos orígenes que CORS
+	origins := getEnv("WEBAUTHN_ORIGINS", getEnv("CORS_ALLOWED_ORIGINS", "http://localhost:3000,http://localhost:5173"))
+
+	return SecurityConfig{
+		BreachedPasswordsDir: getEnv("BREACHED_PASSWORDS_DIR", ""),
+		WebAuthnRPID:         getEnv("WEBAUTHN_RP_ID", "localhost"),
+		WebAuthnRPName:       getEnv("WEBAUTHN_RP_NAME", "Stegmaier LMS"),
+		WebAuthnOrigins:      strings.Split(origins, ","),
+	}
+}
+
+// loadTenantsConfig carga la configuración del ciclo de vida de los tenants
+func loadTenantsConfig() TenantsConfig {
+	gracePeriodStr := getEnv("TENANT_DELETION_GRACE_PERIOD", "720h")
+	gracePeriod, err := time.ParseDuration(gracePeriodStr)
+	if err != nil || gracePeriod < 0 {
+		log.Printf("⚠️  Invalid TENANT_DELETION_GRACE_PERIOD '%s', using default 720h", gracePeriodStr)
+		gracePeriod = 720 * time.Hour
+	}
+
+	purgeIntervalStr := getEnv("TENANT_PURGE_INTERVAL", "1h")
+	purgeInterval, err := time.ParseDuration(purgeIntervalStr)
+	if err != nil || purgeInterval <= 0 {
+		log.Printf("⚠️  Invalid TENANT_PURGE_INTERVAL '%s', using default 1h", purgeIntervalStr)
+		purgeInterval = time.Hour
+	}
+
+	return TenantsConfig{
+		DeletionGracePeriod: gracePeriod,
+		PurgeInterval:       purgeInterval,
 	}
 }
 
@@ -255,11 +335,24 @@ func (c *Config) Validate() error {
 	}
 
 	// Validar JWT (crítico para seguridad)
+	if c.JWT.KeysDir != "" && c.JWT.ActiveKeyID == "" {
+		return fmt.Errorf("JWT_ACTIVE_KEY_ID is required when JWT_KEYS_DIR is set")
+	}
+	// Con claves asimétricas el secreto solo valida los tokens emitidos antes de la migración,
+	// así que debe dejar de aceptarse cuando esos tokens expiran
+	if c.JWT.KeysDir != "" && c.JWT.Secret != "" {
+		if c.JWT.LegacySecretUntil.IsZero() {
+			return fmt.Errorf("JWT_LEGACY_SECRET_UNTIL is required when JWT_SECRET is set with JWT_KEYS_DIR")
+		}
+		if c.JWT.LegacySecretUntil.After(time.Now().Add(c.JWT.Expiration)) {
+			return fmt.Errorf("JWT_LEGACY_SECRET_UNTIL must be within JWT_EXPIRATION from now")
+		}
+	}
 	if c.Server.Environment == "production" {
-		if c.JWT.Secret == "" {
+		if c.JWT.KeysDir == "" && c.JWT.Secret == "" {
 			return fmt.Errorf("JWT_SECRET is required in production")
 		}
-		if len(c.JWT.Secret) < 32 {
+		if c.JWT.Secret != "" && len(c.JWT.Secret) < 32 {
 			return fmt.Errorf("JWT_SECRET must be at least 32 characters in production")
 		}
 	}
@@ -113,6 +113,45 @@ func TestLoadJWTConfig(t *testing.T) {
 	os.Clearenv()
 }
 
+func TestLoadTenantsConfig(t *testing.T) {
+	// Test default values
+	os.Clearenv()
+	cfg := loadTenantsConfig()
+
+	if cfg.DeletionGracePeriod != 720*time.Hour {
+		t.Errorf("Expected default deletion grace period 720h, got %v", cfg.DeletionGracePeriod)
+	}
+
+	if cfg.PurgeInterval != time.Hour {
+		t.Errorf("Expected default purge interval 1h, got %v", cfg.PurgeInterval)
+	}
+
+	// Test custom values
+	os.Setenv("TENANT_DELETION_GRACE_PERIOD", "168h")
+	os.Setenv("TENANT_PURGE_INTERVAL", "15m")
+
+	cfg = loadTenantsConfig()
+
+	if cfg.DeletionGracePeriod != 168*time.Hour {
+		t.Errorf("Expected deletion grace period 168h, got %v", cfg.DeletionGracePeriod)
+	}
+
+	if cfg.PurgeInterval != 15*time.Minute {
+		t.Errorf("Expected purge interval 15m, got %v", cfg.PurgeInterval)
+	}
+
+	// Test invalid values fall back to defaults
+	os.Setenv("TENANT_PURGE_INTERVAL", "0s")
+
+	cfg = loadTenantsConfig()
+
+	if cfg.PurgeInterval != time.Hour {
+		t.Errorf("Expected default purge interval 1h for invalid value, got %v", cfg.PurgeInterval)
+	}
+
+	os.Clearenv()
+}
+
 func TestConfigValidate(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -232,6 +271,118 @@ func TestConfigValidate(t *testing.T) {
 			expectError: true,
 			errorMsg:    "JWT_SECRET must be at least 32 characters in production",
 		},
+		{
+			name: "Production with key set and short JWT secret",
+			config: &Config{
+				Server: ServerConfig{
+					Port:        "8000",
+					Environment: "production",
+				},
+				Database: DatabaseConfig{
+					Control: DatabaseConnection{
+						Host: "localhost",
+						Name: "test_db",
+						User: "postgres",
+					},
+					Tenant: TenantDatabaseConfig{
+						Host: "localhost",
+						User: "postgres",
+					},
+				},
+				JWT: JWTConfig{
+					Secret:            "short",
+					KeysDir:           "/keys",
+					ActiveKeyID:       "2024-02",
+					Expiration:        time.Hour,
+					LegacySecretUntil: time.Now().Add(time.Minute),
+				},
+			},
+			expectError: true,
+			errorMsg:    "JWT_SECRET must be at least 32 characters in production",
+		},
+		{
+			name: "Key set with JWT secret and no cutoff",
+			config: &Config{
+				Server: ServerConfig{
+					Port:        "8000",
+					Environment: "development",
+				},
+				Database: DatabaseConfig{
+					Control: DatabaseConnection{
+						Host: "localhost",
+						Name: "test_db",
+						User: "postgres",
+					},
+					Tenant: TenantDatabaseConfig{
+						Host: "localhost",
+						User: "postgres",
+					},
+				},
+				JWT: JWTConfig{
+					Secret:      "legacy-secret",
+					KeysDir:     "/keys",
+					ActiveKeyID: "2024-02",
+					Expiration:  time.Hour,
+				},
+			},
+			expectError: true,
+			errorMsg:    "JWT_LEGACY_SECRET_UNTIL is required when JWT_SECRET is set with JWT_KEYS_DIR",
+		},
+		{
+			name: "Key set with legacy cutoff beyond token expiration",
+			config: &Config{
+				Server: ServerConfig{
+					Port:        "8000",
+					Environment: "development",
+				},
+				Database: DatabaseConfig{
+					Control: DatabaseConnection{
+						Host: "localhost",
+						Name: "test_db",
+						User: "postgres",
+					},
+					Tenant: TenantDatabaseConfig{
+						Host: "localhost",
+						User: "postgres",
+					},
+				},
+				JWT: JWTConfig{
+					Secret:            "legacy-secret",
+					KeysDir:           "/keys",
+					ActiveKeyID:       "2024-02",
+					Expiration:        time.Hour,
+					LegacySecretUntil: time.Now().Add(2 * time.Hour),
+				},
+			},
+			expectError: true,
+			errorMsg:    "JWT_LEGACY_SECRET_UNTIL must be within JWT_EXPIRATION from now",
+		},
+		{
+			name: "Key set without JWT secret",
+			config: &Config{
+				Server: ServerConfig{
+					Port:        "8000",
+					Environment: "development",
+				},
+				Database: DatabaseConfig{
+					Control: DatabaseConnection{
+						Host: "localhost",
+						Name: "test_db",
+						User: "postgres",
+					},
+					Tenant: TenantDatabaseConfig{
+						Host: "localhost",
+						User: "postgres",
+					},
+				},
+				JWT: JWTConfig{
+					KeysDir:     "/keys",
+					ActiveKeyID: "2024-02",
+					Expiration:  time.Hour,
+				},
+			},
+			expectError: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -19,6 +19,15 @@ type Manager struct {
 	config         *config.Config
 }
 
+// Estados de un tenant en la tabla tenants
+const (
+	TenantStatusActive          = "active"
+	TenantStatusInactive        = "inactive"
+	TenantStatusSuspended       = "suspended"
+	TenantStatusPendingDeletion = "pending_deletion"
+	TenantStatusDeleted         = "deleted"
+)
+
 var (
 	instance *Manager
 	once     sync.Once
@@ -160,6 +169,27 @@ func (m *Manager) getTenantInfo(tenantID string) (*TenantInfo, error) {
 	return &tenant, nil
 }
 
+// CloseTenantConnection cierra la conexión a un tenant, por ejemplo al suspenderlo.
+// Las siguientes llamadas a GetTenantConnection fallan mientras el tenant no esté activo.
+func (m *Manager) CloseTenantConnection(tenantID string) {
+	m.closeTenantConnection(tenantID)
+}
+
+// OpenTenantDatabase abre una conexión dedicada a la base de datos de un tenant, sin importar su
+// estado (por ejemplo, para exportar un tenant suspendido). La conexión no se guarda en el pool
+// y el llamador debe cerrarla.
+func (m *Manager) OpenTenantDatabase(dbName string) (*sqlx.DB, error) {
+	dsn := m.config.Database.Tenant.GetTenantDSN(dbName)
+
+	db, err := sqlx.Connect("postgres", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to tenant database: %w", err)
+	}
+	db.SetMaxOpenConns(2)
+
+	return db, nil
+}
+
 // closeTenantConnection cierra la conexión a un tenant específico
 func (m *Manager) closeTenantConnection(tenantID string) {
 	m.tenantDBsMutex.Lock()
@@ -1,15 +1,21 @@
 package database
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
 
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/golang-migrate/migrate/v4/database/postgres"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/lib/pq"
 )
 
+// migrationsTable es la tabla donde golang-migrate guarda la versión aplicada
+const migrationsTable = "schema_migrations"
+
 // MigrationRunner gestiona la ejecución de migraciones
 type MigrationRunner struct {
 	manager *Manager
@@ -96,12 +102,21 @@ func (mr *MigrationRunner) RunAllTenantMigrations(migrationsPath string) error {
 	return nil
 }
 
-// runMigrations ejecuta las migraciones en una base de datos específica
-func (mr *MigrationRunner) runMigrations(db *sql.DB, dbName, migrationsPath string) error {
+// newMigrate crea una instancia de migrate sobre una conexión propia del pool. La instancia debe
+// cerrarse con Close, que devuelve la conexión al pool. postgres.WithInstance no sirve: retiene
+// una conexión hasta que se cierra el driver, y cerrarlo cierra también el pool compartido.
+func newMigrate(db *sql.DB, dbName, migrationsPath string) (*migrate.Migrate, error) {
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get migration connection: %w", err)
+	}
+
 	// Crear driver de PostgreSQL
-	driver, err := postgres.WithInstance(db, &postgres.Config{})
+	driver, err := postgres.WithConnection(ctx, conn, &postgres.Config{MigrationsTable: migrationsTable})
 	if err != nil {
-		return fmt.Errorf("failed to create migration driver: %w", err)
+		conn.Close()
+		return nil, fmt.Errorf("failed to create migration driver: %w", err)
 	}
 
 	// Crear instancia de migrate
@@ -111,9 +126,21 @@ func (mr *MigrationRunner) runMigrations(db *sql.DB, dbName, migrationsPath stri
 		driver,
 	)
 	if err != nil {
-		return fmt.Errorf("failed to create migrate instance: %w", err)
+		driver.Close()
+		return nil, fmt.Errorf("failed to create migrate instance: %w", err)
 	}
 
+	return m, nil
+}
+
+// runMigrations ejecuta las migraciones en una base de datos específica
+func (mr *MigrationRunner) runMigrations(db *sql.DB, dbName, migrationsPath string) error {
+	m, err := newMigrate(db, dbName, migrationsPath)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
 	// Ejecutar migraciones
 	if err := m.Up(); err != nil && err != migrate.ErrNoChange {
 		return fmt.Errorf("failed to run migrations: %w", err)
@@ -159,19 +186,11 @@ func (mr *MigrationRunner) RollbackTenantMigrations(tenantID, migrationsPath str
 
 // rollbackMigrations revierte migraciones
 func (mr *MigrationRunner) rollbackMigrations(db *sql.DB, dbName, migrationsPath string, steps int) error {
-	driver, err := postgres.WithInstance(db, &postgres.Config{})
+	m, err := newMigrate(db, dbName, migrationsPath)
 	if err != nil {
-		return fmt.Errorf("failed to create migration driver: %w", err)
-	}
-
-	m, err := migrate.NewWithDatabaseInstance(
-		fmt.Sprintf("file://%s", migrationsPath),
-		dbName,
-		driver,
-	)
-	if err != nil {
-		return fmt.Errorf("failed to create migrate instance: %w", err)
+		return err
 	}
+	defer m.Close()
 
 	if err := m.Steps(-steps); err != nil && err != migrate.ErrNoChange {
 		return fmt.Errorf("failed to rollback migrations: %w", err)
@@ -183,7 +202,7 @@ func (mr *MigrationRunner) rollbackMigrations(db *sql.DB, dbName, migrationsPath
 // GetControlMigrationVersion obtiene la versión actual de migraciones de Control DB
 func (mr *MigrationRunner) GetControlMigrationVersion(migrationsPath string) (uint, bool, error) {
 	db := mr.manager.GetControlDB().DB
-	return mr.getMigrationVersion(db, "control_db", migrationsPath)
+	return mr.getMigrationVersion(db)
 }
 
 // GetTenantMigrationVersion obtiene la versión actual de migraciones de Tenant DB
@@ -193,36 +212,28 @@ func (mr *MigrationRunner) GetTenantMigrationVersion(tenantID, migrationsPath st
 		return 0, false, fmt.Errorf("failed to get tenant connection: %w", err)
 	}
 
-	return mr.getMigrationVersion(tenantDB.DB, fmt.Sprintf("tenant_%s", tenantID), migrationsPath)
+	return mr.getMigrationVersion(tenantDB.DB)
 }
 
-// getMigrationVersion obtiene la versión actual de migraciones
-func (mr *MigrationRunner) getMigrationVersion(db *sql.DB, dbName, migrationsPath string) (uint, bool, error) {
-	driver, err := postgres.WithInstance(db, &postgres.Config{})
-	if err != nil {
-		return 0, false, fmt.Errorf("failed to create migration driver: %w", err)
-	}
-
-	m, err := migrate.NewWithDatabaseInstance(
-		fmt.Sprintf("file://%s", migrationsPath),
-		dbName,
-		driver,
-	)
+// getMigrationVersion lee la versión actual de migraciones de la tabla schema_migrations con una
+// consulta simple, sin crear una instancia de migrate ni reservar una conexión del pool
+func (mr *MigrationRunner) getMigrationVersion(db *sql.DB) (uint, bool, error) {
+	var version int64
+	var dirty bool
+	err := db.QueryRow(`SELECT version, dirty FROM `+migrationsTable+` LIMIT 1`).Scan(&version, &dirty)
 	if err != nil {
-		return 0, false, fmt.Errorf("failed to create migrate instance: %w", err)
-	}
-
-	version, dirty, err := m.Version()
-	if err != nil && err != migrate.ErrNilVersion {
+		// Sin tabla o sin filas no hay migraciones aplicadas
+		var pqErr *pq.Error
+		if err == sql.ErrNoRows || (errors.As(err, &pqErr) && pqErr.Code == "42P01") {
+			return 0, false, nil
+		}
 		return 0, false, fmt.Errorf("failed to get migration version: %w", err)
 	}
-
-	// Si err == migrate.ErrNilVersion, significa que no hay migraciones aplicadas
-	if err == migrate.ErrNilVersion {
+	if version < 0 {
 		return 0, false, nil
 	}
 
-	return version, dirty, nil
+	return uint(version), dirty, nil
 }
 
 // CreateTenantWithMigrations crea una nueva base de datos de tenant y ejecuta migraciones
@@ -0,0 +1,269 @@
+package database
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MigrationStateSchemaDrift indica que a un tenant le faltan tablas o índices que sus
+// migraciones aplicadas deberían haber creado
+const MigrationStateSchemaDrift = "schema_drift"
+
+// Sentencias de las migraciones que crean, eliminan o renombran tablas e índices
+var (
+	schemaObjectName   = `((?:"?\w+"?\.)?"?\w+"?)`
+	createTablePattern = regexp.MustCompile(`(?is)^\s*CREATE\s+(?:UNLOGGED\s+)?TABLE\s+(?:IF\s+NOT\s+EXISTS\s+)?` + schemaObjectName)
+	createIndexPattern = regexp.MustCompile(`(?is)^\s*CREATE\s+(?:UNIQUE\s+)?INDEX\s+(?:CONCURRENTLY\s+)?(?:IF\s+NOT\s+EXISTS\s+)?` + schemaObjectName + `\s+ON\s+(?:ONLY\s+)?` + schemaObjectName)
+	dropPattern        = regexp.MustCompile(`(?is)^\s*DROP\s+(TABLE|INDEX)\s+(?:CONCURRENTLY\s+)?(?:IF\s+EXISTS\s+)?(.+?)(?:\s+(?:CASCADE|RESTRICT))?\s*$`)
+	renamePattern      = regexp.MustCompile(`(?is)^\s*ALTER\s+(TABLE|INDEX)\s+(?:IF\s+EXISTS\s+)?(?:ONLY\s+)?` + schemaObjectName + `\s+RENAME\s+TO\s+` + schemaObjectName + `\s*$`)
+	sqlCommentPattern  = regexp.MustCompile(`--[^\n]*`)
+)
+
+// ExpectedSchema son las tablas e índices que deben existir en la base de datos de un tenant
+type ExpectedSchema struct {
+	Tables  []string `json:"tables"`
+	Indexes []string `json:"indexes"`
+}
+
+// SchemaMigrations son las migraciones de tenants leídas de un directorio, para calcular el
+// esquema esperado en cada versión sin volver a leer los archivos
+type SchemaMigrations struct {
+	versions   []uint
+	statements map[uint][]string
+}
+
+// TenantSchemaStatus es el estado de las migraciones de un tenant junto con las tablas e
+// índices esperados que no existen en su base de datos
+type TenantSchemaStatus struct {
+	TenantMigrationStatus
+	MissingTables  []string `json:"missing_tables,omitempty"`
+	MissingIndexes []string `json:"missing_indexes,omitempty"`
+}
+
+// MigrationSummary cuenta los tenants en cada estado
+type MigrationSummary struct {
+	Total       int `json:"total"`
+	UpToDate    int `json:"up_to_date"`
+	Behind      int `json:"behind"`
+	Dirty       int `json:"dirty"`
+	SchemaDrift int `json:"schema_drift"`
+	Failed      int `json:"failed"`
+}
+
+// TenantDriftReport compara el esquema de cada tenant con las migraciones disponibles
+type TenantDriftReport struct {
+	CheckedAt      time.Time            `json:"checked_at"`
+	MigrationsPath string               `json:"migrations_path"`
+	LatestVersion  uint                 `json:"latest_version"`
+	Summary        MigrationSummary     `json:"summary"`
+	Tenants        []TenantSchemaStatus `json:"tenants"`
+}
+
+// Add cuenta un tenant en un estado; los estados desconocidos cuentan como fallidos
+func (s *MigrationSummary) Add(state string) {
+	s.Total++
+	switch state {
+	case MigrationStateUpToDate:
+		s.UpToDate++
+	case MigrationStateBehind:
+		s.Behind++
+	case MigrationStateDirty:
+		s.Dirty++
+	case MigrationStateSchemaDrift:
+		s.SchemaDrift++
+	default:
+		s.Failed++
+	}
+}
+
+// Drifted cuenta los tenants que no están al día
+func (s MigrationSummary) Drifted() int {
+	return s.Total - s.UpToDate
+}
+
+// LoadSchemaMigrations lee las migraciones up de un directorio
+func LoadSchemaMigrations(migrationsPath string) (*SchemaMigrations, error) {
+	versions, err := MigrationVersions(migrationsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := filepath.Glob(filepath.Join(migrationsPath, "*.up.sql"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list migrations: %w", err)
+	}
+
+	migrations := &SchemaMigrations{versions: versions, statements: make(map[uint][]string)}
+	for _, file := range files {
+		version, ok := migrationFileVersion(filepath.Base(file))
+		if !ok {
+			continue
+		}
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", filepath.Base(file), err)
+		}
+		sql := sqlCommentPattern.ReplaceAllString(string(content), "")
+		migrations.statements[version] = append(migrations.statements[version], strings.Split(sql, ";")...)
+	}
+	return migrations, nil
+}
+
+// Versions devuelve las versiones de las migraciones, ordenadas
+func (sm *SchemaMigrations) Versions() []uint {
+	return sm.versions
+}
+
+// Expected calcula las tablas e índices que deben existir tras aplicar las migraciones
+// hasta version. Solo se consideran los índices con nombre; los de claves primarias y
+// restricciones UNIQUE los crea PostgreSQL junto con la tabla.
+func (sm *SchemaMigrations) Expected(version uint) ExpectedSchema {
+	tables := make(map[string]bool)
+	indexes := make(map[string]string) // índice -> tabla
+
+	for _, v := range sm.versions {
+		if v > version {
+			break
+		}
+		for _, statement := range sm.statements[v] {
+			if match := createTablePattern.FindStringSubmatch(statement); match != nil {
+				tables[schemaName(match[1])] = true
+			} else if match := createIndexPattern.FindStringSubmatch(statement); match != nil {
+				indexes[schemaName(match[1])] = schemaName(match[2])
+			} else if match := dropPattern.FindStringSubmatch(statement); match != nil {
+				for _, name := range strings.Split(match[2], ",") {
+					name = schemaName(name)
+					if strings.EqualFold(match[1], "index") {
+						delete(indexes, name)
+						continue
+					}
+					delete(tables, name)
+					for index, table := range indexes {
+						if table == name {
+							delete(indexes, index)
+						}
+					}
+				}
+			} else if match := renamePattern.FindStringSubmatch(statement); match != nil {
+				from, to := schemaName(match[2]), schemaName(match[3])
+				if strings.EqualFold(match[1], "index") {
+					if table, ok := indexes[from]; ok {
+						delete(indexes, from)
+						indexes[to] = table
+					}
+					continue
+				}
+				if tables[from] {
+					delete(tables, from)
+					tables[to] = true
+				}
+				for index, table := range indexes {
+					if table == from {
+						indexes[index] = to
+					}
+				}
+			}
+		}
+	}
+
+	expected := ExpectedSchema{Tables: make([]string, 0, len(tables)), Indexes: make([]string, 0, len(indexes))}
+	for table := range tables {
+		expected.Tables = append(expected.Tables, table)
+	}
+	for index := range indexes {
+		expected.Indexes = append(expected.Indexes, index)
+	}
+	sort.Strings(expected.Tables)
+	sort.Strings(expected.Indexes)
+	return expected
+}
+
+// schemaName normaliza el nombre de una tabla o índice del esquema public
+func schemaName(name string) string {
+	name = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(name), `"`, ""))
+	return strings.TrimPrefix(name, "public.")
+}
+
+// CheckTenantSchema obtiene el estado de las migraciones de un tenant y comprueba que existan
+// las tablas e índices de su versión. No se revisa el esquema de los tenants con errores o
+// con una migración a medio aplicar, cuyo estado ya indica el problema.
+func (mr *MigrationRunner) CheckTenantSchema(tenant TenantInfo, migrationsPath string, migrations *SchemaMigrations) TenantSchemaStatus {
+	status := TenantSchemaStatus{
+		TenantMigrationStatus: mr.GetTenantMigrationStatus(tenant, migrationsPath, migrations.Versions()),
+	}
+	if status.State == MigrationStateError || status.State == MigrationStateDirty {
+		return status
+	}
+
+	tenantDB, err := mr.manager.GetTenantConnection(tenant.ID)
+	if err != nil {
+		status.State = MigrationStateError
+		status.Error = fmt.Sprintf("failed to get tenant connection: %v", err)
+		return status
+	}
+
+	var tables, indexes []string
+	if err := tenantDB.Select(&tables, `SELECT tablename FROM pg_tables WHERE schemaname = 'public'`); err != nil {
+		status.State = MigrationStateError
+		status.Error = fmt.Sprintf("failed to list tables: %v", err)
+		return status
+	}
+	if err := tenantDB.Select(&indexes, `SELECT indexname FROM pg_indexes WHERE schemaname = 'public'`); err != nil {
+		status.State = MigrationStateError
+		status.Error = fmt.Sprintf("failed to list indexes: %v", err)
+		return status
+	}
+
+	expected := migrations.Expected(status.Version)
+	status.MissingTables = missingNames(expected.Tables, tables)
+	status.MissingIndexes = missingNames(expected.Indexes, indexes)
+	if len(status.MissingTables) > 0 || len(status.MissingIndexes) > 0 {
+		status.State = MigrationStateSchemaDrift
+	}
+	return status
+}
+
+// missingNames devuelve los nombres esperados que no existen
+func missingNames(expected, existing []string) []string {
+	found := make(map[string]bool, len(existing))
+	for _, name := range existing {
+		found[strings.ToLower(name)] = true
+	}
+
+	var missing []string
+	for _, name := range expected {
+		if !found[name] {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+// CheckTenantDrift revisa las migraciones y el esquema de los tenants, con a lo sumo
+// concurrency tenants a la vez
+func (mr *MigrationRunner) CheckTenantDrift(tenants []TenantInfo, migrationsPath string, concurrency int) (*TenantDriftReport, error) {
+	migrations, err := LoadSchemaMigrations(migrationsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &TenantDriftReport{
+		CheckedAt:      time.Now(),
+		MigrationsPath: migrationsPath,
+		Tenants: ForEachTenant(tenants, concurrency, func(tenant TenantInfo) TenantSchemaStatus {
+			return mr.CheckTenantSchema(tenant, migrationsPath, migrations)
+		}),
+	}
+	if versions := migrations.Versions(); len(versions) > 0 {
+		report.LatestVersion = versions[len(versions)-1]
+	}
+	for _, tenant := range report.Tenants {
+		report.Summary.Add(tenant.State)
+	}
+	return report, nil
+}
@@ -0,0 +1,99 @@
+package database
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSchemaMigrationsExpected(t *testing.T) {
+	dir := t.TempDir()
+	migrations := map[string]string{
+		"000001_init.up.sql": `
+			-- CREATE TABLE commented_out (id UUID);
+			CREATE TABLE IF NOT EXISTS courses (id UUID PRIMARY KEY, title TEXT);
+			CREATE INDEX IF NOT EXISTS idx_courses_title ON courses(title);
+			CREATE TABLE public."Lessons" (id UUID PRIMARY KEY);
+			CREATE INDEX idx_lessons_id ON public."Lessons" (id);
+			CREATE TABLE drafts (id UUID);
+			CREATE INDEX idx_drafts_id ON drafts(id);
+		`,
+		"000001_init.down.sql": `DROP TABLE courses;`,
+		"000002_rework.up.sql": `
+			DROP TABLE IF EXISTS drafts CASCADE;
+			ALTER TABLE lessons RENAME TO course_lessons;
+			ALTER INDEX idx_courses_title RENAME TO idx_courses_name;
+		`,
+		"000003_quizzes.up.sql": `
+			CREATE TABLE quizzes (id UUID);
+			CREATE UNIQUE INDEX CONCURRENTLY idx_quizzes_id ON ONLY quizzes (id);
+			DROP INDEX idx_lessons_id;
+		`,
+	}
+	for name, content := range migrations {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	schema, err := LoadSchemaMigrations(dir)
+	if err != nil {
+		t.Fatalf("LoadSchemaMigrations failed: %v", err)
+	}
+	if expected := []uint{1, 2, 3}; !reflect.DeepEqual(schema.Versions(), expected) {
+		t.Errorf("Expected versions %v, got %v", expected, schema.Versions())
+	}
+
+	tests := []struct {
+		name    string
+		version uint
+		tables  []string
+		indexes []string
+	}{
+		{"No migrations applied", 0, []string{}, []string{}},
+		{"First migration", 1, []string{"courses", "drafts", "lessons"}, []string{"idx_courses_title", "idx_drafts_id", "idx_lessons_id"}},
+		{"Dropped and renamed objects", 2, []string{"course_lessons", "courses"}, []string{"idx_courses_name", "idx_lessons_id"}},
+		{"Latest version", 3, []string{"course_lessons", "courses", "quizzes"}, []string{"idx_courses_name", "idx_quizzes_id"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expected := schema.Expected(tt.version)
+			if !reflect.DeepEqual(expected.Tables, tt.tables) {
+				t.Errorf("Expected tables %v, got %v", tt.tables, expected.Tables)
+			}
+			if !reflect.DeepEqual(expected.Indexes, tt.indexes) {
+				t.Errorf("Expected indexes %v, got %v", tt.indexes, expected.Indexes)
+			}
+		})
+	}
+}
+
+func TestMissingNames(t *testing.T) {
+	missing := missingNames([]string{"courses", "lessons", "quizzes"}, []string{"Courses", "quizzes", "schema_migrations"})
+	if expected := []string{"lessons"}; !reflect.DeepEqual(missing, expected) {
+		t.Errorf("Expected missing %v, got %v", expected, missing)
+	}
+	if missing := missingNames([]string{"courses"}, []string{"courses"}); missing != nil {
+		t.Errorf("Expected nothing missing, got %v", missing)
+	}
+}
+
+func TestMigrationSummary(t *testing.T) {
+	var summary MigrationSummary
+	for _, state := range []string{
+		MigrationStateUpToDate, MigrationStateUpToDate, MigrationStateBehind,
+		MigrationStateDirty, MigrationStateSchemaDrift, MigrationStateError,
+	} {
+		summary.Add(state)
+	}
+
+	expected := MigrationSummary{Total: 6, UpToDate: 2, Behind: 1, Dirty: 1, SchemaDrift: 1, Failed: 1}
+	if summary != expected {
+		t.Errorf("Expected summary %+v, got %+v", expected, summary)
+	}
+	if summary.Drifted() != 4 {
+		t.Errorf("Expected 4 drifted tenants, got %d", summary.Drifted())
+	}
+}
@@ -0,0 +1,174 @@
+package database
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Estados de las migraciones de un tenant en los reportes
+const (
+	MigrationStateUpToDate = "up_to_date"
+	MigrationStateBehind   = "behind"
+	MigrationStateDirty    = "dirty"
+	MigrationStateError    = "error"
+)
+
+// TenantMigrationStatus describe la versión de migraciones de la base de datos de un tenant
+// comparada con la última migración disponible
+type TenantMigrationStatus struct {
+	TenantID      string `json:"tenant_id"`
+	Slug          string `json:"slug"`
+	DatabaseName  string `json:"database_name"`
+	Version       uint   `json:"version"`
+	LatestVersion uint   `json:"latest_version"`
+	Dirty         bool   `json:"dirty"`
+	Pending       int    `json:"pending"` // Migraciones disponibles que aún no se aplicaron
+	State         string `json:"state"`
+	Error         string `json:"error,omitempty"`
+}
+
+// MigrationVersions lee las versiones de las migraciones de un directorio, ordenadas
+func MigrationVersions(migrationsPath string) ([]uint, error) {
+	entries, err := os.ReadDir(migrationsPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations directory: %w", err)
+	}
+
+	var versions []uint
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		if version, ok := migrationFileVersion(entry.Name()); ok {
+			versions = append(versions, version)
+		}
+	}
+
+	sort.Slice(versions, func(i, j int) bool { return versions[i] < versions[j] })
+	return versions, nil
+}
+
+// migrationFileVersion obtiene la versión de una migración up a partir del nombre del archivo
+func migrationFileVersion(name string) (uint, bool) {
+	if !strings.HasSuffix(name, ".up.sql") {
+		return 0, false
+	}
+	prefix, _, ok := strings.Cut(name, "_")
+	if !ok {
+		return 0, false
+	}
+	version, err := strconv.ParseUint(prefix, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return uint(version), true
+}
+
+// NewTenantMigrationStatus compara la versión de un tenant con las migraciones disponibles
+func NewTenantMigrationStatus(tenant TenantInfo, version uint, dirty bool, versions []uint) TenantMigrationStatus {
+	status := TenantMigrationStatus{
+		TenantID:     tenant.ID,
+		Slug:         tenant.Slug,
+		DatabaseName: tenant.DatabaseName,
+		Version:      version,
+		Dirty:        dirty,
+	}
+	if len(versions) > 0 {
+		status.LatestVersion = versions[len(versions)-1]
+	}
+	for _, v := range versions {
+		if v > version {
+			status.Pending++
+		}
+	}
+
+	switch {
+	case dirty:
+		status.State = MigrationStateDirty
+	case status.Pending > 0:
+		status.State = MigrationStateBehind
+	default:
+		status.State = MigrationStateUpToDate
+	}
+	return status
+}
+
+// ListActiveTenants lista los tenants activos, los únicos cuyas bases de datos se migran
+func (m *Manager) ListActiveTenants() ([]TenantInfo, error) {
+	var tenants []TenantInfo
+	query := `
+		SELECT id, name, slug, database_name, node_number, status
+		FROM tenants
+		WHERE status = 'active'
+		ORDER BY slug
+	`
+	if err := m.controlDB.Select(&tenants, query); err != nil {
+		return nil, fmt.Errorf("failed to list active tenants: %w", err)
+	}
+	return tenants, nil
+}
+
+// GetTenantMigrationStatus obtiene el estado de las migraciones de un tenant. Los errores al
+// leer la versión se informan en el estado, para que un tenant no impida revisar los demás.
+func (mr *MigrationRunner) GetTenantMigrationStatus(tenant TenantInfo, migrationsPath string, versions []uint) TenantMigrationStatus {
+	version, dirty, err := mr.GetTenantMigrationVersion(tenant.ID, migrationsPath)
+	status := NewTenantMigrationStatus(tenant, version, dirty, versions)
+	if err != nil {
+		status.State = MigrationStateError
+		status.Error = err.Error()
+	}
+	return status
+}
+
+// ForceTenantMigrationVersion fija la versión de migraciones de un tenant y limpia el estado
+// dirty, sin ejecutar migraciones. Se usa tras reparar a mano una migración que falló.
+func (mr *MigrationRunner) ForceTenantMigrationVersion(tenantID, migrationsPath string, version int) error {
+	log.Printf("⚠️  Forcing Tenant DB migration version %d for tenant %s...", version, tenantID)
+
+	tenantDB, err := mr.manager.GetTenantConnection(tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to get tenant connection: %w", err)
+	}
+
+	m, err := newMigrate(tenantDB.DB, fmt.Sprintf("tenant_%s", tenantID), migrationsPath)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	if err := m.Force(version); err != nil {
+		return fmt.Errorf("failed to force migration version: %w", err)
+	}
+
+	log.Printf("✅ Tenant DB migration version forced to %d for tenant: %s", version, tenantID)
+	return nil
+}
+
+// ForEachTenant ejecuta fn para cada tenant con a lo sumo concurrency ejecuciones a la vez.
+// Los resultados conservan el orden de los tenants.
+func ForEachTenant[T any](tenants []TenantInfo, concurrency int, fn func(tenant TenantInfo) T) []T {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	results := make([]T, len(tenants))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, tenant := range tenants {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, tenant TenantInfo) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = fn(tenant)
+		}(i, tenant)
+	}
+	wg.Wait()
+
+	return results
+}
@@ -0,0 +1,97 @@
+package database
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMigrationVersions(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{
+		"000002_create_courses.up.sql",
+		"000002_create_courses.down.sql",
+		"000001_init.up.sql",
+		"000001_init.down.sql",
+		"000010_add_index.up.sql",
+		"README.md",
+		"latest.up.sql",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	versions, err := MigrationVersions(dir)
+	if err != nil {
+		t.Fatalf("MigrationVersions failed: %v", err)
+	}
+	if expected := []uint{1, 2, 10}; !reflect.DeepEqual(versions, expected) {
+		t.Errorf("Expected versions %v, got %v", expected, versions)
+	}
+
+	if _, err := MigrationVersions(filepath.Join(dir, "missing")); err == nil {
+		t.Error("Expected an error for a missing directory")
+	}
+}
+
+func TestNewTenantMigrationStatus(t *testing.T) {
+	tenant := TenantInfo{ID: "tenant-1", Slug: "acme", DatabaseName: "tenant_acme"}
+	versions := []uint{1, 2, 3, 5}
+
+	tests := []struct {
+		name            string
+		version         uint
+		dirty           bool
+		expectedPending int
+		expectedState   string
+	}{
+		{"Up to date", 5, false, 0, MigrationStateUpToDate},
+		{"Behind", 2, false, 2, MigrationStateBehind},
+		{"No migrations applied", 0, false, 4, MigrationStateBehind},
+		{"Dirty", 3, true, 1, MigrationStateDirty},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := NewTenantMigrationStatus(tenant, tt.version, tt.dirty, versions)
+			if status.Pending != tt.expectedPending || status.State != tt.expectedState {
+				t.Errorf("Expected %d pending and state %s, got %d and %s", tt.expectedPending, tt.expectedState, status.Pending, status.State)
+			}
+			if status.LatestVersion != 5 || status.TenantID != "tenant-1" || status.Slug != "acme" {
+				t.Errorf("Unexpected status: %+v", status)
+			}
+		})
+	}
+}
+
+func TestForEachTenant(t *testing.T) {
+	var tenants []TenantInfo
+	for _, slug := range []string{"a", "b", "c", "d", "e", "f"} {
+		tenants = append(tenants, TenantInfo{Slug: slug})
+	}
+
+	var running, maxRunning int32
+	results := ForEachTenant(tenants, 2, func(tenant TenantInfo) string {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			current := atomic.LoadInt32(&maxRunning)
+			if n <= current || atomic.CompareAndSwapInt32(&maxRunning, current, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		return tenant.Slug
+	})
+
+	if expected := []string{"a", "b", "c", "d", "e", "f"}; !reflect.DeepEqual(results, expected) {
+		t.Errorf("Expected results in tenant order %v, got %v", expected, results)
+	}
+	if maxRunning > 2 {
+		t.Errorf("Expected at most 2 tenants at a time, got %d", maxRunning)
+	}
+}
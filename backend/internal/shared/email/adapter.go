@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"time"
 
 	authports "github.com/DanielIturra1610/stegmaier-landing/internal/core/auth/ports"
 	"github.com/DanielIturra1610/stegmaier-landing/internal/core/notifications/ports"
@@ -134,3 +135,23 @@ func (a *EmailServiceAdapter) SendPasswordResetEmail(ctx context.Context, to, us
 	log.Printf("INFO: Password reset email sent successfully to %s", to)
 	return nil
 }
+
+// SendAccountLockedEmail envía email de bloqueo temporal de cuenta (implementa authports.EmailService)
+func (a *EmailServiceAdapter) SendAccountLockedEmail(ctx context.Context, to, userName, unlockToken string, lockedFor time.Duration) error {
+	if err := a.emailService.SendAccountLockedEmail(ctx, to, userName, unlockToken, lockedFor); err != nil {
+		log.Printf("ERROR: Failed to send account locked email to %s: %v", to, err)
+		return fmt.Errorf("failed to send account locked email: %w", err)
+	}
+	log.Printf("INFO: Account locked email sent successfully to %s", to)
+	return nil
+}
+
+// SendMagicLinkEmail envía email con enlace de inicio de sesión sin contraseña (implementa authports.EmailService)
+func (a *EmailServiceAdapter) SendMagicLinkEmail(ctx context.Context, to, userName, loginToken string, expiresIn time.Duration) error {
+	if err := a.emailService.SendMagicLinkEmail(ctx, to, userName, loginToken, expiresIn); err != nil {
+		log.Printf("ERROR: Failed to send magic link email to %s: %v", to, err)
+		return fmt.Errorf("failed to send magic link email: %w", err)
+	}
+	log.Printf("INFO: Magic link email sent successfully to %s", to)
+	return nil
+}
@@ -0,0 +1,141 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"html/template"
+	"log"
+	"strings"
+
+	tenantdomain "github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/domain"
+)
+
+// tenantContextKey es la clave del tenant de un email en el contexto
+type tenantContextKey struct{}
+
+// WithTenant marca el contexto con el tenant en cuyo nombre se envían los emails, para los
+// envíos que no vienen de una request (jobs en background)
+func WithTenant(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, tenantID)
+}
+
+// tenantFromContext obtiene el tenant de un email. Los contextos de requests de Fiber exponen el
+// tenant_id que dejó el TenantMiddleware en Locals.
+func tenantFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	if tenantID, ok := ctx.Value(tenantContextKey{}).(string); ok && tenantID != "" {
+		return tenantID
+	}
+	if tenantID, ok := ctx.Value("tenant_id").(string); ok {
+		return tenantID
+	}
+	return ""
+}
+
+// brandingFor obtiene el branding del tenant del contexto; nil significa el branding de la
+// plataforma. Un error al obtenerlo no impide enviar el email.
+func (s *EmailService) brandingFor(ctx context.Context) *tenantdomain.TenantBranding {
+	if s.branding == nil {
+		return nil
+	}
+
+	tenantID := tenantFromContext(ctx)
+	if tenantID == "" {
+		return nil
+	}
+
+	brand, err := s.branding.GetTenantBranding(ctx, tenantID)
+	if err != nil {
+		log.Printf("WARN: Failed to get branding of tenant %s, using platform branding: %v", tenantID, err)
+		return nil
+	}
+	return brand
+}
+
+// brandData copia los datos de un template reemplazando el nombre de la plataforma y el email de
+// soporte por los del tenant
+func brandData(data map[string]interface{}, brand *tenantdomain.TenantBranding) map[string]interface{} {
+	branded := make(map[string]interface{}, len(data)+1)
+	for k, v := range data {
+		branded[k] = v
+	}
+	if brand == nil {
+		return branded
+	}
+
+	branded["PlatformName"] = brand.TenantName
+	if brand.Support.Email != "" {
+		branded["SupportEmail"] = brand.Support.Email
+	}
+	branded["Brand"] = brand
+	return branded
+}
+
+// defaultBrandColor es el color principal de los templates de la plataforma
+const defaultBrandColor = "#2563eb"
+
+var brandHeaderTemplate = template.Must(template.New("brand_header").Parse(`
+<div style="text-align: center; padding: 16px 0; border-bottom: 3px solid {{.Color}}; margin-bottom: 16px;">
+    {{if .LogoURL}}<img src="{{.LogoURL}}" alt="{{.Name}}" style="max-height: 56px; max-width: 240px;">{{else}}<strong style="font-size: 20px; color: {{.Color}};">{{.Name}}</strong>{{end}}
+    {{if .Header}}<p style="margin: 8px 0 0; font-size: 14px; color: #374151;">{{.Header}}</p>{{end}}
+</div>
+`))
+
+var brandFooterTemplate = template.Must(template.New("brand_footer").Parse(`
+<div style="text-align: center; padding: 16px 0; margin-top: 16px; border-top: 1px solid #e5e7eb; font-size: 12px; color: #666;">
+    {{if .Footer}}<p style="margin: 0 0 8px;">{{.Footer}}</p>{{end}}
+    {{if or .Support.Email .Support.Phone .Support.URL}}<p style="margin: 0;">Soporte de {{.Name}}:
+        {{if .Support.Email}}<a href="mailto:{{.Support.Email}}" style="color: {{.Color}};">{{.Support.Email}}</a>{{end}}
+        {{if .Support.Phone}} · {{.Support.Phone}}{{end}}
+        {{if .Support.URL}} · <a href="{{.Support.URL}}" style="color: {{.Color}};">{{.Support.URL}}</a>{{end}}
+    </p>{{end}}
+</div>
+`))
+
+// decorate agrega al HTML de un email el encabezado (logo y texto) y el pie (texto y contacto de
+// soporte) del tenant. Los valores del tenant se escapan, así que no pueden inyectar HTML.
+func decorate(html string, brand *tenantdomain.TenantBranding) string {
+	if brand == nil {
+		return html
+	}
+
+	color := brand.Palette.Primary
+	if color == "" {
+		color = defaultBrandColor
+	}
+	values := map[string]interface{}{
+		"Name":    brand.TenantName,
+		"LogoURL": brand.LogoURL,
+		"Header":  brand.EmailHeader,
+		"Footer":  brand.EmailFooter,
+		"Support": brand.Support,
+		"Color":   color,
+	}
+
+	var header, footer bytes.Buffer
+	if err := brandHeaderTemplate.Execute(&header, values); err != nil {
+		log.Printf("WARN: Failed to render brand header: %v", err)
+		return html
+	}
+	if err := brandFooterTemplate.Execute(&footer, values); err != nil {
+		log.Printf("WARN: Failed to render brand footer: %v", err)
+		return html
+	}
+
+	// El encabezado va justo después de <body ...> y el pie justo antes de </body>
+	lower := strings.ToLower(html)
+	if end := strings.LastIndex(lower, "</body>"); end >= 0 {
+		html = html[:end] + footer.String() + html[end:]
+	} else {
+		html += footer.String()
+	}
+	if start := strings.Index(lower, "<body"); start >= 0 {
+		if closing := strings.Index(lower[start:], ">"); closing >= 0 {
+			pos := start + closing + 1
+			return html[:pos] + header.String() + html[pos:]
+		}
+	}
+	return header.String() + html
+}
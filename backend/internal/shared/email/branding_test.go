@@ -0,0 +1,93 @@
+package email
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	tenantdomain "github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/domain"
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestTenantFromContext(t *testing.T) {
+	if got := tenantFromContext(context.Background()); got != "" {
+		t.Errorf("Expected no tenant, got %q", got)
+	}
+	if got := tenantFromContext(WithTenant(context.Background(), "tenant-a")); got != "tenant-a" {
+		t.Errorf("Expected tenant-a, got %q", got)
+	}
+
+	// Services receive the fasthttp context of the request, which exposes the Locals
+	var fromRequest, fromOverride string
+	app := fiber.New()
+	app.Get("/", func(c *fiber.Ctx) error {
+		c.Locals("tenant_id", "tenant-from-request")
+		fromRequest = tenantFromContext(c.Context())
+		fromOverride = tenantFromContext(WithTenant(c.Context(), "tenant-a"))
+		return nil
+	})
+	if _, err := app.Test(httptest.NewRequest("GET", "/", nil)); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+
+	if fromRequest != "tenant-from-request" {
+		t.Errorf("Expected the tenant of the request, got %q", fromRequest)
+	}
+	if fromOverride != "tenant-a" {
+		t.Errorf("Expected WithTenant to win over the request, got %q", fromOverride)
+	}
+}
+
+func TestRenderTemplateWithBranding(t *testing.T) {
+	service := &EmailService{baseURL: "https://lms.example.com"}
+	brand := &tenantdomain.TenantBranding{
+		TenantName:  "Acme <Academy>",
+		LogoURL:     "https://cdn.example.com/logo.png",
+		Palette:     tenantdomain.BrandingPalette{Primary: "#ff6600"},
+		EmailHeader: "Learning at Acme",
+		EmailFooter: "<script>alert(1)</script>",
+		Support:     tenantdomain.SupportContact{Email: "help@acme.example.com"},
+	}
+
+	html, err := service.renderTemplate("unknown", map[string]interface{}{"UserName": "Ana"}, brand)
+	if err != nil {
+		t.Fatalf("Failed to render template: %v", err)
+	}
+
+	for _, expected := range []string{
+		"Acme &lt;Academy&gt;",
+		`src="https://cdn.example.com/logo.png"`,
+		"#ff6600",
+		"Learning at Acme",
+		"&lt;script&gt;",
+		"mailto:help@acme.example.com",
+	} {
+		if !strings.Contains(html, expected) {
+			t.Errorf("Expected rendered email to contain %q", expected)
+		}
+	}
+	if strings.Contains(html, "<script>") || strings.Contains(html, "Stegmaier LMS") {
+		t.Errorf("Expected tenant values to be escaped and replace the platform name:\n%s", html)
+	}
+
+	body := strings.Index(html, "<body")
+	header := strings.Index(html, "Learning at Acme")
+	footer := strings.Index(html, "mailto:help@acme.example.com")
+	end := strings.Index(html, "</body>")
+	if !(body < header && header < footer && footer < end) {
+		t.Errorf("Expected the brand header after <body> and the footer before </body>")
+	}
+}
+
+func TestRenderTemplateWithoutBranding(t *testing.T) {
+	service := &EmailService{baseURL: "https://lms.example.com"}
+
+	html, err := service.renderTemplate("unknown", map[string]interface{}{"UserName": "Ana"}, nil)
+	if err != nil {
+		t.Fatalf("Failed to render template: %v", err)
+	}
+	if !strings.Contains(html, "Stegmaier LMS") {
+		t.Errorf("Expected the platform branding without tenant branding")
+	}
+}
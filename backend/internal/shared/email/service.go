@@ -7,12 +7,15 @@ import (
 	"fmt"
 	"html/template"
 	"log"
+	"net/mail"
 	"os"
 	"path/filepath"
 	"time"
 
 	"gopkg.in/gomail.v2"
 
+	tenantdomain "github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/domain"
+	tenantports "github.com/DanielIturra1610/stegmaier-landing/internal/core/tenants/ports"
 	"github.com/DanielIturra1610/stegmaier-landing/internal/shared/config"
 )
 
@@ -21,6 +24,7 @@ type EmailService struct {
 	config    *config.EmailConfig
 	baseURL   string
 	templates map[string]*template.Template
+	branding  tenantports.BrandingProvider
 }
 
 // EmailData contiene los datos para enviar un email
@@ -32,12 +36,14 @@ type EmailData struct {
 	Attachments []string
 }
 
-// NewEmailService crea una nueva instancia del servicio de email
-func NewEmailService(cfg *config.EmailConfig, baseURL string) *EmailService {
+// NewEmailService crea una nueva instancia del servicio de email. Los emails enviados en nombre
+// de un tenant (ver WithTenant) llevan su branding; sin branding se usa el de la plataforma.
+func NewEmailService(cfg *config.EmailConfig, baseURL string, branding tenantports.BrandingProvider) *EmailService {
 	service := &EmailService{
 		config:    cfg,
 		baseURL:   baseURL,
 		templates: make(map[string]*template.Template),
+		branding:  branding,
 	}
 
 	// Cargar templates
@@ -48,8 +54,10 @@ func NewEmailService(cfg *config.EmailConfig, baseURL string) *EmailService {
 
 // SendEmail envía un email usando un template HTML
 func (s *EmailService) SendEmail(ctx context.Context, data EmailData) error {
+	brand := s.brandingFor(ctx)
+
 	// Renderizar template
-	htmlContent, err := s.renderTemplate(data.TemplateName, data.Data)
+	htmlContent, err := s.renderTemplate(data.TemplateName, data.Data, brand)
 	if err != nil {
 		log.Printf("ERROR: Failed to render template %s: %v", data.TemplateName, err)
 		return fmt.Errorf("failed to render template: %w", err)
@@ -58,6 +66,15 @@ func (s *EmailService) SendEmail(ctx context.Context, data EmailData) error {
 	// Crear mensaje
 	m := gomail.NewMessage()
 	m.SetHeader("From", s.config.From)
+	if brand != nil {
+		// El remitente sigue siendo la dirección de la plataforma, con el nombre del tenant
+		if from, err := mail.ParseAddress(s.config.From); err == nil {
+			m.SetAddressHeader("From", from.Address, brand.DisplayName())
+		}
+		if brand.Support.Email != "" {
+			m.SetHeader("Reply-To", brand.Support.Email)
+		}
+	}
 	m.SetHeader("To", data.To...)
 	m.SetHeader("Subject", data.Subject)
 	m.SetBody("text/html", htmlContent)
@@ -199,6 +216,85 @@ func (s *EmailService) SendPasswordResetEmail(ctx context.Context, to, userName,
 	})
 }
 
+// SendAccountLockedEmail avisa del bloqueo temporal de una cuenta con un enlace para desbloquearla
+func (s *EmailService) SendAccountLockedEmail(ctx context.Context, to, userName, unlockToken string, lockedFor time.Duration) error {
+	data := map[string]interface{}{
+		"UserName":      userName,
+		"UnlockURL":     fmt.Sprintf("%s/unlock-account?token=%s", s.baseURL, unlockToken),
+		"LockedMinutes": int(lockedFor.Minutes()),
+		"PlatformName":  "Stegmaier LMS",
+		"SupportEmail":  s.config.From,
+		"Year":          time.Now().Year(),
+	}
+
+	return s.SendEmail(ctx, EmailData{
+		To:           []string{to},
+		Subject:      "Tu cuenta fue bloqueada temporalmente - Stegmaier LMS",
+		TemplateName: "account_locked",
+		Data:         data,
+	})
+}
+
+// SendMagicLinkEmail envía un enlace de un solo uso para iniciar sesión sin contraseña
+func (s *EmailService) SendMagicLinkEmail(ctx context.Context, to, userName, loginToken string, expiresIn time.Duration) error {
+	data := map[string]interface{}{
+		"UserName":         userName,
+		"LoginURL":         fmt.Sprintf("%s/magic-login?token=%s", s.baseURL, loginToken),
+		"ExpiresInMinutes": int(expiresIn.Minutes()),
+		"PlatformName":     "Stegmaier LMS",
+		"SupportEmail":     s.config.From,
+		"Year":             time.Now().Year(),
+	}
+
+	return s.SendEmail(ctx, EmailData{
+		To:           []string{to},
+		Subject:      "Tu enlace para iniciar sesión - Stegmaier LMS",
+		TemplateName: "magic_link",
+		Data:         data,
+	})
+}
+
+// memberRoleNames traduce los roles de un tenant para los emails
+var memberRoleNames = map[string]string{
+	"student":    "estudiante",
+	"instructor": "instructor",
+	"admin":      "administrador",
+}
+
+// SendMemberInvitationEmail avisa a un usuario que fue agregado a un tenant. Los usuarios nuevos
+// reciben un enlace para crear su contraseña (setPasswordToken); los que ya tenían cuenta, uno
+// para iniciar sesión.
+func (s *EmailService) SendMemberInvitationEmail(ctx context.Context, to, userName, tenantName, role, setPasswordToken string, expiresIn time.Duration) error {
+	roleName, ok := memberRoleNames[role]
+	if !ok {
+		roleName = role
+	}
+
+	data := map[string]interface{}{
+		"UserName":       userName,
+		"TenantName":     tenantName,
+		"RoleName":       roleName,
+		"ActionURL":      fmt.Sprintf("%s/login", s.baseURL),
+		"SetPasswordURL": "",
+		"ExpiresInDays":  int(expiresIn.Hours() / 24),
+		"PlatformName":   "Stegmaier LMS",
+		"SupportEmail":   s.config.From,
+		"Year":           time.Now().Year(),
+	}
+
+	if setPasswordToken != "" {
+		data["SetPasswordURL"] = fmt.Sprintf("%s/reset-password?token=%s", s.baseURL, setPasswordToken)
+		data["ActionURL"] = data["SetPasswordURL"]
+	}
+
+	return s.SendEmail(ctx, EmailData{
+		To:           []string{to},
+		Subject:      fmt.Sprintf("Te invitaron a %s", tenantName),
+		TemplateName: "member_invitation",
+		Data:         data,
+	})
+}
+
 // SendEnrollmentRequestEmail envía email cuando se solicita inscripción
 func (s *EmailService) SendEnrollmentRequestEmail(ctx context.Context, to, userName, courseTitle, courseID, message string) error {
 	data := map[string]interface{}{
@@ -277,21 +373,24 @@ func (s *EmailService) SendProgressMilestoneEmail(ctx context.Context, to, userN
 	})
 }
 
-// renderTemplate renderiza un template HTML con los datos proporcionados
-func (s *EmailService) renderTemplate(templateName string, data map[string]interface{}) (string, error) {
+// renderTemplate renderiza un template HTML con los datos proporcionados y el branding del
+// tenant, si hay uno
+func (s *EmailService) renderTemplate(templateName string, data map[string]interface{}, brand *tenantdomain.TenantBranding) (string, error) {
+	data = brandData(data, brand)
+
 	tmpl, exists := s.templates[templateName]
 	if !exists {
 		// Si no existe el template, usar el fallback
-		return s.getFallbackTemplate(data), nil
+		return decorate(s.getFallbackTemplate(data), brand), nil
 	}
 
 	var buf bytes.Buffer
 	if err := tmpl.Execute(&buf, data); err != nil {
 		log.Printf("ERROR: Failed to execute template %s: %v", templateName, err)
-		return s.getFallbackTemplate(data), nil
+		return decorate(s.getFallbackTemplate(data), brand), nil
 	}
 
-	return buf.String(), nil
+	return decorate(buf.String(), brand), nil
 }
 
 // loadTemplates carga todos los templates HTML
@@ -327,6 +426,9 @@ func (s *EmailService) loadTemplates() {
 		"course_reminder",
 		"progress_milestone",
 		"password_reset",
+		"account_locked",
+		"magic_link",
+		"member_invitation",
 	}
 
 	for _, name := range templates {
@@ -353,16 +455,23 @@ func (s *EmailService) getFallbackTemplate(data map[string]interface{}) string {
 		dashboardURL = url
 	}
 
+	platformName := "Stegmaier LMS"
+	if name, ok := data["PlatformName"].(string); ok && name != "" {
+		platformName = name
+	}
+	platformName = template.HTMLEscapeString(platformName)
+	userName = template.HTMLEscapeString(userName)
+
 	return fmt.Sprintf(`
 <!DOCTYPE html>
 <html>
 <head>
-    <title>Stegmaier LMS</title>
+    <title>%s</title>
     <meta charset="utf-8">
 </head>
 <body style="font-family: Arial, sans-serif; max-width: 600px; margin: 0 auto; padding: 20px;">
     <div style="background: linear-gradient(135deg, #2563eb 0%%, #1d4ed8 100%%); color: white; padding: 30px; border-radius: 8px 8px 0 0;">
-        <h1 style="margin: 0;">Stegmaier LMS</h1>
+        <h1 style="margin: 0;">%s</h1>
     </div>
     <div style="background: white; padding: 30px; border: 1px solid #e5e7eb; border-top: none; border-radius: 0 0 8px 8px;">
         <p>Hola <strong>%s</strong>,</p>
@@ -374,13 +483,13 @@ func (s *EmailService) getFallbackTemplate(data map[string]interface{}) string {
         </p>
         <hr style="border: none; border-top: 1px solid #e5e7eb; margin: 30px 0;">
         <p style="font-size: 12px; color: #666;">
-            Este es un email automático de Stegmaier LMS.<br>
-            © %d Stegmaier LMS. Todos los derechos reservados.
+            Este es un email automático de %s.<br>
+            © %d %s. Todos los derechos reservados.
         </p>
     </div>
 </body>
 </html>
-	`, userName, dashboardURL, time.Now().Year())
+	`, platformName, platformName, userName, dashboardURL, platformName, time.Now().Year(), platformName)
 }
 
 // sendSMTP envía el email via SMTP
@@ -0,0 +1,145 @@
+package oidc
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/DanielIturra1610/stegmaier-landing/internal/shared/oidc/oidctest"
+)
+
+func TestCodeChallengeS256(t *testing.T) {
+	// Example from RFC 7636 Appendix B
+	verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	expected := "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+
+	if got := CodeChallengeS256(verifier); got != expected {
+		t.Errorf("expected challenge %s, got %s", expected, got)
+	}
+}
+
+func TestAuthorizationCodeFlow(t *testing.T) {
+	idp := oidctest.NewServer("lms-client", "lms-secret")
+	defer idp.Close()
+
+	ctx := context.Background()
+	client := NewClient(nil)
+	cfg := Config{
+		Issuer:       idp.Issuer(),
+		ClientID:     "lms-client",
+		ClientSecret: "lms-secret",
+		RedirectURL:  "https://lms.example.com/auth/oidc/callback",
+		Scopes:       []string{"email", "profile"},
+	}
+
+	idp.SetClaims(map[string]interface{}{
+		"sub":            "employee-42",
+		"email":          "jane@corp.example.com",
+		"email_verified": true,
+		"name":           "Jane Doe",
+		"groups":         []string{"lms-instructors"},
+	})
+
+	authorize := func(t *testing.T, nonce, verifier string) string {
+		t.Helper()
+
+		authURL, err := client.AuthCodeURL(ctx, cfg, "state-123", nonce, verifier)
+		if err != nil {
+			t.Fatalf("failed to build authorization URL: %v", err)
+		}
+
+		parsed, _ := url.Parse(authURL)
+		if scope := parsed.Query().Get("scope"); scope != "openid email profile" {
+			t.Errorf("expected openid scope to be added, got %q", scope)
+		}
+
+		code, state, err := idp.Authorize(authURL)
+		if err != nil {
+			t.Fatalf("authorization failed: %v", err)
+		}
+		if state != "state-123" {
+			t.Errorf("expected state to round-trip, got %q", state)
+		}
+		return code
+	}
+
+	t.Run("Valid login", func(t *testing.T) {
+		code := authorize(t, "nonce-1", "verifier-1")
+
+		token, err := client.Exchange(ctx, cfg, code, "verifier-1")
+		if err != nil {
+			t.Fatalf("exchange failed: %v", err)
+		}
+
+		idToken, err := client.VerifyIDToken(ctx, cfg, token.IDToken, "nonce-1")
+		if err != nil {
+			t.Fatalf("id_token verification failed: %v", err)
+		}
+
+		if idToken.Subject != "employee-42" || idToken.Email != "jane@corp.example.com" || !idToken.EmailVerified {
+			t.Errorf("unexpected id_token claims: %+v", idToken)
+		}
+		if _, ok := idToken.Claims["groups"]; !ok {
+			t.Errorf("expected custom claims to be available")
+		}
+	})
+
+	t.Run("Wrong PKCE verifier", func(t *testing.T) {
+		code := authorize(t, "nonce-2", "verifier-2")
+
+		if _, err := client.Exchange(ctx, cfg, code, "another-verifier"); err == nil {
+			t.Errorf("expected exchange to fail with a wrong verifier")
+		}
+	})
+
+	t.Run("Nonce mismatch", func(t *testing.T) {
+		code := authorize(t, "nonce-3", "verifier-3")
+
+		token, err := client.Exchange(ctx, cfg, code, "verifier-3")
+		if err != nil {
+			t.Fatalf("exchange failed: %v", err)
+		}
+
+		if _, err := client.VerifyIDToken(ctx, cfg, token.IDToken, "other-nonce"); err == nil {
+			t.Errorf("expected nonce mismatch to be rejected")
+		}
+	})
+
+	t.Run("Wrong audience", func(t *testing.T) {
+		code := authorize(t, "nonce-4", "verifier-4")
+
+		token, err := client.Exchange(ctx, cfg, code, "verifier-4")
+		if err != nil {
+			t.Fatalf("exchange failed: %v", err)
+		}
+
+		otherClient := cfg
+		otherClient.ClientID = "other-client"
+		if _, err := client.VerifyIDToken(ctx, otherClient, token.IDToken, "nonce-4"); err == nil {
+			t.Errorf("expected audience mismatch to be rejected")
+		}
+	})
+
+	t.Run("Wrong client secret", func(t *testing.T) {
+		code := authorize(t, "nonce-5", "verifier-5")
+
+		badSecret := cfg
+		badSecret.ClientSecret = "wrong"
+		_, err := client.Exchange(ctx, badSecret, code, "verifier-5")
+		if err == nil || !strings.Contains(err.Error(), "invalid_client") {
+			t.Errorf("expected invalid_client error, got %v", err)
+		}
+	})
+}
+
+func TestDiscoverIssuerMismatch(t *testing.T) {
+	idp := oidctest.NewServer("lms-client", "lms-secret")
+	defer idp.Close()
+
+	// Same provider reached through a different host name
+	issuer := strings.Replace(idp.Issuer(), "127.0.0.1", "localhost", 1)
+	if _, err := NewClient(nil).Discover(context.Background(), issuer); err == nil {
+		t.Errorf("expected issuer mismatch to be rejected")
+	}
+}
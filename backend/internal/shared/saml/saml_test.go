@@ -0,0 +1,428 @@
+package saml
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/beevik/etree"
+	dsig "github.com/russellhaering/goxmldsig"
+	"github.com/russellhaering/goxmldsig/etreeutils"
+)
+
+const (
+	testIdPEntityID = "https://idp.corp.example.com/saml"
+	testSPEntityID  = "https://lms.example.com/api/v1/auth/saml/tenant-1/metadata"
+	testACSURL      = "https://lms.example.com/api/v1/auth/saml/tenant-1/acs"
+)
+
+// testIdP signs responses with a self-signed certificate
+type testIdP struct {
+	key  *rsa.PrivateKey
+	cert *x509.Certificate
+}
+
+func newTestIdP(t *testing.T) *testIdP {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "idp.corp.example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("failed to parse certificate: %v", err)
+	}
+
+	return &testIdP{key: key, cert: cert}
+}
+
+// sign replaces the <!--sign:ID--> placeholder with an enveloped signature of the element with that ID
+func (idp *testIdP) sign(t *testing.T, document, id string) string {
+	t.Helper()
+
+	doc := etree.NewDocument()
+	if err := doc.ReadFromString(document); err != nil {
+		t.Fatalf("failed to parse document: %v", err)
+	}
+	target := doc.FindElement("//[@ID='" + id + "']")
+	if target == nil {
+		t.Fatalf("element %s not found", id)
+	}
+
+	// Sign a detached copy so the inherited namespace declarations are canonicalized
+	nsContext, err := etreeutils.NSBuildParentContext(target)
+	if err != nil {
+		t.Fatalf("failed to build namespace context: %v", err)
+	}
+	detached, err := etreeutils.NSDetatch(nsContext, target)
+	if err != nil {
+		t.Fatalf("failed to detach element: %v", err)
+	}
+
+	ctx, err := dsig.NewSigningContext(idp.key, [][]byte{idp.cert.Raw})
+	if err != nil {
+		t.Fatalf("failed to create signing context: %v", err)
+	}
+	ctx.Canonicalizer = dsig.MakeC14N10ExclusiveCanonicalizerWithPrefixList("")
+	signature, err := ctx.ConstructSignature(detached, true)
+	if err != nil {
+		t.Fatalf("failed to sign: %v", err)
+	}
+
+	signatureDoc := etree.NewDocument()
+	signatureDoc.SetRoot(signature)
+	signatureXML, err := signatureDoc.WriteToString()
+	if err != nil {
+		t.Fatalf("failed to serialize signature: %v", err)
+	}
+
+	return strings.Replace(document, "<!--sign:"+id+"-->", signatureXML, 1)
+}
+
+// responseOptions customizes the generated response
+type responseOptions struct {
+	audience     string
+	recipient    string
+	notOnOrAfter time.Time
+	status       string
+	nameID       string
+	email        string
+}
+
+func testResponse(now time.Time, opts responseOptions) string {
+	if opts.audience == "" {
+		opts.audience = testSPEntityID
+	}
+	if opts.recipient == "" {
+		opts.recipient = testACSURL
+	}
+	if opts.notOnOrAfter.IsZero() {
+		opts.notOnOrAfter = now.Add(5 * time.Minute)
+	}
+	if opts.status == "" {
+		opts.status = statusSuccess
+	}
+	if opts.nameID == "" {
+		opts.nameID = "employee-42"
+	}
+	if opts.email == "" {
+		opts.email = "jane@corp.example.com"
+	}
+
+	format := func(t time.Time) string { return t.UTC().Format(time.RFC3339) }
+
+	return fmt.Sprintf(`<samlp:Response xmlns:samlp="%[1]s" xmlns:saml="%[2]s" ID="_response" Version="2.0" InResponseTo="_request" Destination="%[3]s" IssueInstant="%[4]s">`+
+		`<saml:Issuer>%[5]s</saml:Issuer><!--sign:_response-->`+
+		`<samlp:Status><samlp:StatusCode Value="%[6]s"/></samlp:Status>`+
+		`<saml:Assertion ID="_assertion" Version="2.0" IssueInstant="%[4]s">`+
+		`<saml:Issuer>%[5]s</saml:Issuer><!--sign:_assertion-->`+
+		`<saml:Subject><saml:NameID Format="%[7]s">%[8]s</saml:NameID>`+
+		`<saml:SubjectConfirmation Method="%[9]s"><saml:SubjectConfirmationData InResponseTo="_request" Recipient="%[10]s" NotOnOrAfter="%[11]s"/></saml:SubjectConfirmation></saml:Subject>`+
+		`<saml:Conditions NotBefore="%[4]s" NotOnOrAfter="%[11]s"><saml:AudienceRestriction><saml:Audience>%[12]s</saml:Audience></saml:AudienceRestriction></saml:Conditions>`+
+		`<saml:AuthnStatement AuthnInstant="%[4]s" SessionIndex="_session"/>`+
+		`<saml:AttributeStatement>`+
+		`<saml:Attribute Name="http://schemas.xmlsoap.org/ws/2005/05/identity/claims/emailaddress" FriendlyName="email"><saml:AttributeValue>%[13]s</saml:AttributeValue></saml:Attribute>`+
+		`<saml:Attribute Name="groups"><saml:AttributeValue>lms-learners</saml:AttributeValue><saml:AttributeValue>lms-instructors</saml:AttributeValue></saml:Attribute>`+
+		`</saml:AttributeStatement></saml:Assertion></samlp:Response>`,
+		nsProtocol, nsAssertion, testACSURL, format(now), testIdPEntityID, opts.status,
+		nameIDFormatUnspec, opts.nameID, confirmationBearer, opts.recipient, format(opts.notOnOrAfter),
+		opts.audience, opts.email)
+}
+
+func encode(document string) string {
+	return base64.StdEncoding.EncodeToString([]byte(document))
+}
+
+func TestParseXMLRejectsDTD(t *testing.T) {
+	_, err := parseXML([]byte(`<!DOCTYPE r [<!ENTITY x "y">]><r>&x;</r>`))
+	if err == nil {
+		t.Errorf("expected DTD to be rejected")
+	}
+}
+
+func TestParseResponse(t *testing.T) {
+	idp := newTestIdP(t)
+	now := time.Now()
+
+	sp := &ServiceProvider{
+		EntityID:        testSPEntityID,
+		ACSURL:          testACSURL,
+		IdPEntityID:     testIdPEntityID,
+		IdPSSOURL:       "https://idp.corp.example.com/sso",
+		IdPCertificates: []*x509.Certificate{idp.cert},
+	}
+
+	t.Run("Signed assertion", func(t *testing.T) {
+		document := idp.sign(t, testResponse(now, responseOptions{}), "_assertion")
+
+		assertion, err := sp.ParseResponse(encode(document), now)
+		if err != nil {
+			t.Fatalf("expected response to be valid: %v", err)
+		}
+
+		if assertion.NameID != "employee-42" || assertion.InResponseTo != "_request" || assertion.SessionIndex != "_session" {
+			t.Errorf("unexpected assertion: %+v", assertion)
+		}
+		if assertion.Attribute("email") != "jane@corp.example.com" {
+			t.Errorf("expected attribute to be indexed by friendly name, got %v", assertion.Attributes)
+		}
+		if groups := assertion.Attributes["groups"]; len(groups) != 2 {
+			t.Errorf("expected multi-valued attribute, got %v", groups)
+		}
+	})
+
+	t.Run("Signed response", func(t *testing.T) {
+		document := idp.sign(t, testResponse(now, responseOptions{}), "_response")
+
+		if _, err := sp.ParseResponse(encode(document), now); err != nil {
+			t.Fatalf("expected response to be valid: %v", err)
+		}
+	})
+
+	t.Run("Unsigned response", func(t *testing.T) {
+		_, err := sp.ParseResponse(encode(testResponse(now, responseOptions{})), now)
+		if !errors.Is(err, ErrSignatureInvalid) {
+			t.Errorf("expected ErrSignatureInvalid, got %v", err)
+		}
+	})
+
+	t.Run("Tampered assertion", func(t *testing.T) {
+		document := idp.sign(t, testResponse(now, responseOptions{}), "_assertion")
+		document = strings.Replace(document, "jane@corp.example.com", "admin@corp.example.com", 1)
+
+		_, err := sp.ParseResponse(encode(document), now)
+		if !errors.Is(err, ErrSignatureInvalid) {
+			t.Errorf("expected ErrSignatureInvalid, got %v", err)
+		}
+	})
+
+	t.Run("Untrusted certificate", func(t *testing.T) {
+		other := newTestIdP(t)
+		document := other.sign(t, testResponse(now, responseOptions{}), "_assertion")
+
+		_, err := sp.ParseResponse(encode(document), now)
+		if !errors.Is(err, ErrSignatureInvalid) {
+			t.Errorf("expected ErrSignatureInvalid, got %v", err)
+		}
+	})
+
+	t.Run("Signature wrapping", func(t *testing.T) {
+		signed := idp.sign(t, testResponse(now, responseOptions{}), "_assertion")
+		start := strings.Index(signed, "<saml:Assertion")
+		end := strings.Index(signed, "</saml:Assertion>") + len("</saml:Assertion>")
+		original := signed[start:end]
+
+		// Move the signed assertion into Extensions and inject an unsigned one
+		evil := strings.Replace(original, `ID="_assertion"`, `ID="_evil"`, 1)
+		evil = strings.Replace(evil, "employee-42", "attacker", 1)
+		document := signed[:start] + evil + signed[end:]
+		document = strings.Replace(document, "<samlp:Status>", "<samlp:Extensions>"+original+"</samlp:Extensions><samlp:Status>", 1)
+
+		if _, err := sp.ParseResponse(encode(document), now); err == nil {
+			t.Errorf("expected wrapped assertion to be rejected")
+		}
+	})
+
+	t.Run("Additional unsigned assertion", func(t *testing.T) {
+		signed := idp.sign(t, testResponse(now, responseOptions{}), "_assertion")
+		start := strings.Index(signed, "<saml:Assertion")
+		end := strings.Index(signed, "</saml:Assertion>") + len("</saml:Assertion>")
+
+		evil := strings.Replace(signed[start:end], `ID="_assertion"`, `ID="_evil"`, 1)
+		evil = strings.Replace(evil, "employee-42", "attacker", 1)
+		document := signed[:start] + evil + signed[start:]
+
+		_, err := sp.ParseResponse(encode(document), now)
+		if !errors.Is(err, ErrResponseInvalid) {
+			t.Errorf("expected ErrResponseInvalid, got %v", err)
+		}
+	})
+
+	t.Run("Signed response with replaced assertion", func(t *testing.T) {
+		signed := idp.sign(t, testResponse(now, responseOptions{}), "_response")
+		document := strings.Replace(signed, "employee-42", "attacker", 1)
+
+		_, err := sp.ParseResponse(encode(document), now)
+		if !errors.Is(err, ErrSignatureInvalid) {
+			t.Errorf("expected ErrSignatureInvalid, got %v", err)
+		}
+	})
+
+	t.Run("Reference to the whole document", func(t *testing.T) {
+		signed := idp.sign(t, testResponse(now, responseOptions{}), "_assertion")
+		document := strings.Replace(signed, `URI="#_assertion"`, `URI=""`, 1)
+
+		_, err := sp.ParseResponse(encode(document), now)
+		if !errors.Is(err, ErrSignatureInvalid) {
+			t.Errorf("expected ErrSignatureInvalid, got %v", err)
+		}
+	})
+
+	t.Run("Assertion signature moved to the response", func(t *testing.T) {
+		signed := idp.sign(t, testResponse(now, responseOptions{}), "_assertion")
+		start := strings.Index(signed, "<ds:Signature")
+		end := strings.Index(signed, "</ds:Signature>") + len("</ds:Signature>")
+		signature := signed[start:end]
+
+		// The assertion is now unsigned and the response carries a signature of another element
+		document := signed[:start] + signed[end:]
+		document = strings.Replace(document, "<!--sign:_response-->", signature, 1)
+
+		_, err := sp.ParseResponse(encode(document), now)
+		if !errors.Is(err, ErrSignatureInvalid) {
+			t.Errorf("expected ErrSignatureInvalid, got %v", err)
+		}
+	})
+
+	t.Run("Comment inside NameID", func(t *testing.T) {
+		nameID := "jane@corp.example.com.evil.example"
+		signed := idp.sign(t, testResponse(now, responseOptions{nameID: nameID}), "_assertion")
+		// Comments are not signed: a parser reading only the first text node would see jane@corp.example.com
+		document := strings.Replace(signed, nameID, "jane@corp.example.com<!---->.evil.example", 1)
+
+		assertion, err := sp.ParseResponse(encode(document), now)
+		if err != nil {
+			t.Fatalf("expected response to be valid: %v", err)
+		}
+		if assertion.NameID != nameID {
+			t.Errorf("expected NameID %q, got %q", nameID, assertion.NameID)
+		}
+	})
+
+	t.Run("Wrong audience", func(t *testing.T) {
+		document := idp.sign(t, testResponse(now, responseOptions{audience: "https://other.example.com"}), "_assertion")
+
+		_, err := sp.ParseResponse(encode(document), now)
+		if !errors.Is(err, ErrResponseInvalid) {
+			t.Errorf("expected ErrResponseInvalid, got %v", err)
+		}
+	})
+
+	t.Run("Wrong recipient", func(t *testing.T) {
+		document := idp.sign(t, testResponse(now, responseOptions{recipient: "https://other.example.com/acs"}), "_assertion")
+
+		_, err := sp.ParseResponse(encode(document), now)
+		if !errors.Is(err, ErrResponseInvalid) {
+			t.Errorf("expected ErrResponseInvalid, got %v", err)
+		}
+	})
+
+	t.Run("Expired assertion", func(t *testing.T) {
+		document := idp.sign(t, testResponse(now, responseOptions{}), "_assertion")
+
+		_, err := sp.ParseResponse(encode(document), now.Add(time.Hour))
+		if !errors.Is(err, ErrResponseInvalid) {
+			t.Errorf("expected ErrResponseInvalid, got %v", err)
+		}
+	})
+
+	t.Run("Error status", func(t *testing.T) {
+		document := idp.sign(t, testResponse(now, responseOptions{status: "urn:oasis:names:tc:SAML:2.0:status:Requester"}), "_assertion")
+
+		_, err := sp.ParseResponse(encode(document), now)
+		if !errors.Is(err, ErrStatusNotSuccess) {
+			t.Errorf("expected ErrStatusNotSuccess, got %v", err)
+		}
+	})
+}
+
+func TestAuthnRequestURL(t *testing.T) {
+	sp := &ServiceProvider{
+		EntityID:  testSPEntityID,
+		ACSURL:    testACSURL,
+		IdPSSOURL: "https://idp.corp.example.com/sso?tenant=corp",
+	}
+
+	authURL, err := sp.AuthnRequestURL("_request", "relay", time.Now())
+	if err != nil {
+		t.Fatalf("failed to build request URL: %v", err)
+	}
+
+	parsed, _ := url.Parse(authURL)
+	query := parsed.Query()
+	if query.Get("tenant") != "corp" || query.Get("RelayState") != "relay" {
+		t.Errorf("expected existing query and RelayState to be kept, got %s", parsed.RawQuery)
+	}
+
+	compressed, err := base64.StdEncoding.DecodeString(query.Get("SAMLRequest"))
+	if err != nil {
+		t.Fatalf("SAMLRequest is not base64: %v", err)
+	}
+	request, err := io.ReadAll(flate.NewReader(bytes.NewReader(compressed)))
+	if err != nil {
+		t.Fatalf("SAMLRequest is not deflated: %v", err)
+	}
+
+	root, err := parseXML(request)
+	if err != nil {
+		t.Fatalf("SAMLRequest is not valid XML: %v", err)
+	}
+	if !root.is(nsProtocol, "AuthnRequest") {
+		t.Fatalf("expected AuthnRequest, got %s", root.local)
+	}
+	if id, _ := root.attribute("ID"); id != "_request" {
+		t.Errorf("expected request ID to be kept, got %s", id)
+	}
+	if issuer := root.child(nsAssertion, "Issuer"); issuer == nil || issuer.text() != testSPEntityID {
+		t.Errorf("expected SP entity ID as issuer")
+	}
+}
+
+func TestMetadata(t *testing.T) {
+	sp := &ServiceProvider{EntityID: testSPEntityID, ACSURL: testACSURL}
+
+	metadata, err := sp.Metadata()
+	if err != nil {
+		t.Fatalf("failed to build metadata: %v", err)
+	}
+
+	root, err := parseXML(metadata)
+	if err != nil {
+		t.Fatalf("metadata is not valid XML: %v", err)
+	}
+	if entityID, _ := root.attribute("entityID"); entityID != testSPEntityID {
+		t.Errorf("unexpected entityID %s", entityID)
+	}
+	if !strings.Contains(string(metadata), `Location="`+testACSURL+`"`) {
+		t.Errorf("expected ACS location in metadata")
+	}
+}
+
+func TestParseCertificatesPEM(t *testing.T) {
+	idp := newTestIdP(t)
+	encoded := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: idp.cert.Raw}))
+
+	certificates, err := ParseCertificatesPEM(encoded + encoded)
+	if err != nil || len(certificates) != 2 {
+		t.Fatalf("expected two certificates, got %d (%v)", len(certificates), err)
+	}
+
+	if _, err := ParseCertificatesPEM("not a certificate"); err == nil {
+		t.Errorf("expected invalid PEM to be rejected")
+	}
+}
@@ -0,0 +1,159 @@
+package saml
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/beevik/etree"
+	dsig "github.com/russellhaering/goxmldsig"
+	"github.com/russellhaering/goxmldsig/etreeutils"
+)
+
+// XML Signature namespaces and algorithm identifiers
+const (
+	nsDSig = "http://www.w3.org/2000/09/xmldsig#"
+
+	algExcC14N            = "http://www.w3.org/2001/10/xml-exc-c14n#"
+	algEnvelopedSignature = "http://www.w3.org/2000/09/xmldsig#enveloped-signature"
+	algRSASHA256          = "http://www.w3.org/2001/04/xmldsig-more#rsa-sha256"
+	algRSASHA512          = "http://www.w3.org/2001/04/xmldsig-more#rsa-sha512"
+	algSHA256             = "http://www.w3.org/2001/04/xmlenc#sha256"
+	algSHA512             = "http://www.w3.org/2001/04/xmlenc#sha512"
+)
+
+// ErrSignatureInvalid is returned when an XML signature does not verify
+var ErrSignatureInvalid = errors.New("saml: invalid signature")
+
+// signatureAlgorithms and digestAlgorithms are the algorithms accepted in signatures.
+// goxmldsig also accepts SHA-1 and inclusive canonicalization, which are refused here.
+var (
+	signatureAlgorithms = map[string]bool{algRSASHA256: true, algRSASHA512: true}
+	digestAlgorithms    = map[string]bool{algSHA256: true, algSHA512: true}
+)
+
+// signatureOf returns the enveloped ds:Signature of the element, if any
+func signatureOf(el *element) *element {
+	return el.child(nsDSig, "Signature")
+}
+
+// verifySignature checks the enveloped signature of el against the trusted certificates
+// and returns the verified element. Cryptographic verification and canonicalization are
+// done by goxmldsig; the returned element is the copy goxmldsig digested, without its
+// signature, so callers only ever read data covered by the signature.
+//
+// The signature must be a direct child of el and reference el by its ID. The certificate
+// in ds:KeyInfo, when present, must be one of the trusted certificates.
+func verifySignature(el *etree.Element, certificates []*x509.Certificate, now time.Time) (*etree.Element, error) {
+	// Declare the namespaces inherited from ancestors on a detached copy, which is what
+	// goxmldsig canonicalizes
+	nsContext, err := etreeutils.NSBuildParentContext(el)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSignatureInvalid, err)
+	}
+	detached, err := etreeutils.NSDetatch(nsContext, el)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSignatureInvalid, err)
+	}
+
+	unverified, err := toElement(detached)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSignatureInvalid, err)
+	}
+	if err := checkSignatureShape(unverified); err != nil {
+		return nil, err
+	}
+
+	// Each certificate is tried on its own so signatures without ds:KeyInfo still
+	// verify when several certificates are trusted (e.g. during a rollover)
+	for _, cert := range certificates {
+		ctx := dsig.NewDefaultValidationContext(&dsig.MemoryX509CertificateStore{Roots: []*x509.Certificate{cert}})
+		ctx.Clock = dsig.NewFakeClockAt(now)
+		if verified, err := ctx.Validate(detached); err == nil {
+			return verified, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: signature does not match any trusted certificate", ErrSignatureInvalid)
+}
+
+// checkSignatureShape restricts the signature of el to a single enveloped signature
+// referencing el by ID with the accepted algorithms, before any cryptographic work.
+// goxmldsig would otherwise pick the first descendant signature whose reference
+// matches, including an empty URI that designates the whole document.
+func checkSignatureShape(el *element) error {
+	signatures := el.childElements(nsDSig, "Signature")
+	if len(signatures) == 0 {
+		return fmt.Errorf("%w: element is not signed", ErrSignatureInvalid)
+	}
+	if len(signatures) > 1 {
+		return fmt.Errorf("%w: multiple signatures", ErrSignatureInvalid)
+	}
+	signedInfo := signatures[0].child(nsDSig, "SignedInfo")
+	if signedInfo == nil {
+		return fmt.Errorf("%w: missing SignedInfo", ErrSignatureInvalid)
+	}
+
+	c14nMethod := signedInfo.child(nsDSig, "CanonicalizationMethod")
+	if c14nMethod == nil {
+		return fmt.Errorf("%w: missing CanonicalizationMethod", ErrSignatureInvalid)
+	}
+	if algorithm, _ := c14nMethod.attribute("Algorithm"); algorithm != algExcC14N {
+		return fmt.Errorf("%w: unsupported canonicalization %q", ErrSignatureInvalid, algorithm)
+	}
+
+	signatureMethod := signedInfo.child(nsDSig, "SignatureMethod")
+	if signatureMethod == nil {
+		return fmt.Errorf("%w: missing SignatureMethod", ErrSignatureInvalid)
+	}
+	if algorithm, _ := signatureMethod.attribute("Algorithm"); !signatureAlgorithms[algorithm] {
+		return fmt.Errorf("%w: unsupported signature algorithm %q", ErrSignatureInvalid, algorithm)
+	}
+
+	references := signedInfo.childElements(nsDSig, "Reference")
+	if len(references) != 1 {
+		return fmt.Errorf("%w: expected exactly one reference", ErrSignatureInvalid)
+	}
+	reference := references[0]
+
+	id, _ := el.attribute("ID")
+	uri, _ := reference.attribute("URI")
+	if id == "" || uri != "#"+id {
+		return fmt.Errorf("%w: reference does not point at the signed element", ErrSignatureInvalid)
+	}
+
+	if transforms := reference.child(nsDSig, "Transforms"); transforms != nil {
+		for _, transform := range transforms.childElements(nsDSig, "Transform") {
+			if algorithm, _ := transform.attribute("Algorithm"); algorithm != algEnvelopedSignature && algorithm != algExcC14N {
+				return fmt.Errorf("%w: unsupported transform %q", ErrSignatureInvalid, algorithm)
+			}
+		}
+	}
+
+	digestMethod := reference.child(nsDSig, "DigestMethod")
+	if digestMethod == nil {
+		return fmt.Errorf("%w: incomplete reference", ErrSignatureInvalid)
+	}
+	if algorithm, _ := digestMethod.attribute("Algorithm"); !digestAlgorithms[algorithm] {
+		return fmt.Errorf("%w: unsupported digest algorithm %q", ErrSignatureInvalid, algorithm)
+	}
+
+	return nil
+}
+
+// toElement serializes an etree element and parses it again into the minimal DOM
+func toElement(el *etree.Element) (*element, error) {
+	doc := etree.NewDocument()
+	doc.SetRoot(el.Copy())
+	data, err := doc.WriteToBytes()
+	if err != nil {
+		return nil, err
+	}
+	return parseXML(data)
+}
+
+func stripWhitespace(s string) string {
+	return strings.Join(strings.Fields(s), "")
+}
@@ -0,0 +1,484 @@
+// Package saml implements a minimal SAML 2.0 service provider: SP metadata,
+// SP-initiated login with the HTTP-Redirect binding and validation of signed
+// responses received through the HTTP-POST binding.
+package saml
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/beevik/etree"
+)
+
+// SAML namespaces, bindings and status codes
+const (
+	nsProtocol  = "urn:oasis:names:tc:SAML:2.0:protocol"
+	nsAssertion = "urn:oasis:names:tc:SAML:2.0:assertion"
+
+	bindingHTTPPost    = "urn:oasis:names:tc:SAML:2.0:bindings:HTTP-POST"
+	statusSuccess      = "urn:oasis:names:tc:SAML:2.0:status:Success"
+	confirmationBearer = "urn:oasis:names:tc:SAML:2.0:cm:bearer"
+	nameIDFormatUnspec = "urn:oasis:names:tc:SAML:1.1:nameid-format:unspecified"
+	nameIDFormatEmail  = "urn:oasis:names:tc:SAML:1.1:nameid-format:emailAddress"
+)
+
+const (
+	// clockSkew is the tolerance applied to NotBefore/NotOnOrAfter checks
+	clockSkew = 2 * time.Minute
+	// maxResponseSize bounds the encoded SAMLResponse accepted by ParseResponse
+	maxResponseSize = 512 * 1024
+)
+
+// Errors returned while validating a SAML response
+var (
+	ErrResponseMalformed = errors.New("saml: malformed response")
+	ErrResponseInvalid   = errors.New("saml: invalid response")
+	ErrStatusNotSuccess  = errors.New("saml: identity provider returned an error status")
+)
+
+// ServiceProvider holds the configuration of a SAML service provider and the
+// identity provider it trusts
+type ServiceProvider struct {
+	// EntityID identifies this service provider (also used as audience)
+	EntityID string
+	// ACSURL is the Assertion Consumer Service URL the IdP posts responses to
+	ACSURL string
+	// IdPEntityID is the expected issuer of responses and assertions
+	IdPEntityID string
+	// IdPSSOURL is the IdP single sign-on endpoint (HTTP-Redirect binding)
+	IdPSSOURL string
+	// IdPCertificates are the certificates trusted to sign responses
+	IdPCertificates []*x509.Certificate
+}
+
+// Assertion is the validated content of a SAML assertion
+type Assertion struct {
+	ID           string
+	InResponseTo string
+	NameID       string
+	SessionIndex string
+	// Attributes are indexed by Name and, when present, FriendlyName
+	Attributes map[string][]string
+}
+
+// Attribute returns the first value of the named attribute
+func (a *Assertion) Attribute(name string) string {
+	if values := a.Attributes[name]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// ParseCertificatesPEM parses one or more PEM encoded X.509 certificates
+func ParseCertificatesPEM(data string) ([]*x509.Certificate, error) {
+	var certificates []*x509.Certificate
+	rest := []byte(strings.TrimSpace(data))
+	for len(rest) > 0 {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse certificate: %w", err)
+		}
+		certificates = append(certificates, cert)
+	}
+
+	if len(certificates) == 0 {
+		return nil, fmt.Errorf("no PEM encoded certificate found")
+	}
+
+	return certificates, nil
+}
+
+// NewRequestID returns a random identifier valid as an XML ID
+func NewRequestID() (string, error) {
+	bytes := make([]byte, 20)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", fmt.Errorf("failed to generate request id: %w", err)
+	}
+	return "_" + hex.EncodeToString(bytes), nil
+}
+
+// Metadata returns the SP metadata document to register with the identity provider
+func (sp *ServiceProvider) Metadata() ([]byte, error) {
+	type assertionConsumerService struct {
+		Binding  string `xml:"Binding,attr"`
+		Location string `xml:"Location,attr"`
+		Index    int    `xml:"index,attr"`
+	}
+	type spSSODescriptor struct {
+		AuthnRequestsSigned        bool                     `xml:"AuthnRequestsSigned,attr"`
+		WantAssertionsSigned       bool                     `xml:"WantAssertionsSigned,attr"`
+		ProtocolSupportEnumeration string                   `xml:"protocolSupportEnumeration,attr"`
+		NameIDFormats              []string                 `xml:"NameIDFormat"`
+		AssertionConsumerService   assertionConsumerService `xml:"AssertionConsumerService"`
+	}
+	type entityDescriptor struct {
+		XMLName         xml.Name        `xml:"urn:oasis:names:tc:SAML:2.0:metadata EntityDescriptor"`
+		EntityID        string          `xml:"entityID,attr"`
+		SPSSODescriptor spSSODescriptor `xml:"SPSSODescriptor"`
+	}
+
+	metadata := entityDescriptor{
+		EntityID: sp.EntityID,
+		SPSSODescriptor: spSSODescriptor{
+			WantAssertionsSigned:       true,
+			ProtocolSupportEnumeration: nsProtocol,
+			NameIDFormats:              []string{nameIDFormatEmail, nameIDFormatUnspec},
+			AssertionConsumerService: assertionConsumerService{
+				Binding:  bindingHTTPPost,
+				Location: sp.ACSURL,
+				Index:    0,
+			},
+		},
+	}
+
+	out, err := xml.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+
+	return append([]byte(xml.Header), out...), nil
+}
+
+// AuthnRequestURL returns the IdP URL that starts an SP-initiated login
+// using the HTTP-Redirect binding
+func (sp *ServiceProvider) AuthnRequestURL(requestID, relayState string, now time.Time) (string, error) {
+	var request bytes.Buffer
+	request.WriteString(`<samlp:AuthnRequest xmlns:samlp="` + nsProtocol + `" xmlns:saml="` + nsAssertion + `"`)
+	writeXMLAttr(&request, "ID", requestID)
+	writeXMLAttr(&request, "Version", "2.0")
+	writeXMLAttr(&request, "IssueInstant", now.UTC().Format(time.RFC3339))
+	writeXMLAttr(&request, "Destination", sp.IdPSSOURL)
+	writeXMLAttr(&request, "AssertionConsumerServiceURL", sp.ACSURL)
+	writeXMLAttr(&request, "ProtocolBinding", bindingHTTPPost)
+	request.WriteString(`><saml:Issuer>`)
+	_ = xml.EscapeText(&request, []byte(sp.EntityID))
+	request.WriteString(`</saml:Issuer><samlp:NameIDPolicy AllowCreate="true"`)
+	writeXMLAttr(&request, "Format", nameIDFormatUnspec)
+	request.WriteString(`/></samlp:AuthnRequest>`)
+
+	var deflated bytes.Buffer
+	writer, err := flate.NewWriter(&deflated, flate.DefaultCompression)
+	if err != nil {
+		return "", fmt.Errorf("failed to compress request: %w", err)
+	}
+	if _, err := writer.Write(request.Bytes()); err != nil {
+		return "", fmt.Errorf("failed to compress request: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return "", fmt.Errorf("failed to compress request: %w", err)
+	}
+
+	ssoURL, err := url.Parse(sp.IdPSSOURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid IdP SSO URL: %w", err)
+	}
+
+	params := ssoURL.Query()
+	params.Set("SAMLRequest", base64.StdEncoding.EncodeToString(deflated.Bytes()))
+	if relayState != "" {
+		params.Set("RelayState", relayState)
+	}
+	ssoURL.RawQuery = params.Encode()
+
+	return ssoURL.String(), nil
+}
+
+// ParseResponse validates a base64 encoded samlp:Response received on the ACS
+// endpoint and returns its assertion. Either the assertion or the whole response
+// must be signed by one of the trusted IdP certificates, and data is only read
+// from the signed elements.
+func (sp *ServiceProvider) ParseResponse(encoded string, now time.Time) (*Assertion, error) {
+	if len(encoded) > maxResponseSize {
+		return nil, fmt.Errorf("%w: response too large", ErrResponseMalformed)
+	}
+	raw, err := base64.StdEncoding.DecodeString(stripWhitespace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid base64", ErrResponseMalformed)
+	}
+
+	// The minimal parser rejects DTDs and duplicate IDs before etree reads the document
+	response, err := parseXML(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrResponseMalformed, err)
+	}
+	if !response.is(nsProtocol, "Response") {
+		return nil, fmt.Errorf("%w: root element is not a SAML response", ErrResponseMalformed)
+	}
+	if err := checkUniqueIDs(response); err != nil {
+		return nil, err
+	}
+
+	doc := etree.NewDocument()
+	if err := doc.ReadFromBytes(raw); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrResponseMalformed, err)
+	}
+	responseEl := doc.Root()
+
+	// From here on data is only read from the elements returned by verifySignature
+	responseSigned := signatureOf(response) != nil
+	if responseSigned {
+		if responseEl, err = verifySignature(responseEl, sp.IdPCertificates, now); err != nil {
+			return nil, err
+		}
+		if response, err = toElement(responseEl); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrResponseMalformed, err)
+		}
+	}
+
+	if err := sp.checkResponse(response); err != nil {
+		return nil, err
+	}
+
+	if response.child(nsAssertion, "EncryptedAssertion") != nil {
+		return nil, fmt.Errorf("%w: encrypted assertions are not supported", ErrResponseInvalid)
+	}
+	assertions := response.childElements(nsAssertion, "Assertion")
+	if len(assertions) != 1 {
+		return nil, fmt.Errorf("%w: expected exactly one assertion", ErrResponseInvalid)
+	}
+	assertionEl := assertions[0]
+
+	if signatureOf(assertionEl) != nil {
+		signedEl := childByID(responseEl, assertionEl)
+		if signedEl == nil {
+			return nil, fmt.Errorf("%w: assertion without ID", ErrSignatureInvalid)
+		}
+		verifiedEl, err := verifySignature(signedEl, sp.IdPCertificates, now)
+		if err != nil {
+			return nil, err
+		}
+		if assertionEl, err = toElement(verifiedEl); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrResponseMalformed, err)
+		}
+	} else if !responseSigned {
+		return nil, fmt.Errorf("%w: neither the response nor the assertion is signed", ErrSignatureInvalid)
+	}
+
+	assertion, err := sp.readAssertion(assertionEl, now)
+	if err != nil {
+		return nil, err
+	}
+
+	// The envelope InResponseTo can only be trusted when the response itself is signed
+	inResponseTo, _ := response.attribute("InResponseTo")
+	if assertion.InResponseTo == "" && responseSigned {
+		assertion.InResponseTo = inResponseTo
+	} else if inResponseTo != "" && inResponseTo != assertion.InResponseTo {
+		return nil, fmt.Errorf("%w: InResponseTo mismatch", ErrResponseInvalid)
+	}
+
+	return assertion, nil
+}
+
+// checkResponse validates the status, destination and issuer of the response envelope
+func (sp *ServiceProvider) checkResponse(response *element) error {
+	status := response.child(nsProtocol, "Status")
+	if status == nil {
+		return fmt.Errorf("%w: missing status", ErrResponseMalformed)
+	}
+	statusCode := status.child(nsProtocol, "StatusCode")
+	if statusCode == nil {
+		return fmt.Errorf("%w: missing status code", ErrResponseMalformed)
+	}
+	if value, _ := statusCode.attribute("Value"); value != statusSuccess {
+		return fmt.Errorf("%w: %s", ErrStatusNotSuccess, value)
+	}
+
+	if destination, ok := response.attribute("Destination"); ok && destination != sp.ACSURL {
+		return fmt.Errorf("%w: unexpected destination %q", ErrResponseInvalid, destination)
+	}
+
+	if issuer := response.child(nsAssertion, "Issuer"); issuer != nil && issuer.text() != sp.IdPEntityID {
+		return fmt.Errorf("%w: unexpected issuer %q", ErrResponseInvalid, issuer.text())
+	}
+
+	return nil
+}
+
+// readAssertion validates the conditions and subject of a verified assertion and extracts its data
+func (sp *ServiceProvider) readAssertion(el *element, now time.Time) (*Assertion, error) {
+	assertion := &Assertion{Attributes: make(map[string][]string)}
+	assertion.ID, _ = el.attribute("ID")
+
+	issuer := el.child(nsAssertion, "Issuer")
+	if issuer == nil || issuer.text() != sp.IdPEntityID {
+		return nil, fmt.Errorf("%w: unexpected assertion issuer", ErrResponseInvalid)
+	}
+
+	// Conditions: validity window and audience restriction
+	conditions := el.child(nsAssertion, "Conditions")
+	if conditions == nil {
+		return nil, fmt.Errorf("%w: missing conditions", ErrResponseInvalid)
+	}
+	if err := checkValidityWindow(conditions, now); err != nil {
+		return nil, err
+	}
+	restrictions := conditions.childElements(nsAssertion, "AudienceRestriction")
+	if len(restrictions) == 0 {
+		return nil, fmt.Errorf("%w: missing audience restriction", ErrResponseInvalid)
+	}
+	for _, restriction := range restrictions {
+		matched := false
+		for _, audience := range restriction.childElements(nsAssertion, "Audience") {
+			if audience.text() == sp.EntityID {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return nil, fmt.Errorf("%w: assertion is not intended for this service provider", ErrResponseInvalid)
+		}
+	}
+
+	// Subject: NameID and a bearer confirmation addressed to our ACS
+	subject := el.child(nsAssertion, "Subject")
+	if subject == nil {
+		return nil, fmt.Errorf("%w: missing subject", ErrResponseInvalid)
+	}
+	nameID := subject.child(nsAssertion, "NameID")
+	if nameID == nil || nameID.text() == "" {
+		return nil, fmt.Errorf("%w: missing NameID", ErrResponseInvalid)
+	}
+	assertion.NameID = nameID.text()
+
+	confirmed := false
+	for _, confirmation := range subject.childElements(nsAssertion, "SubjectConfirmation") {
+		if method, _ := confirmation.attribute("Method"); method != confirmationBearer {
+			continue
+		}
+		data := confirmation.child(nsAssertion, "SubjectConfirmationData")
+		if data == nil {
+			continue
+		}
+		if recipient, _ := data.attribute("Recipient"); recipient != sp.ACSURL {
+			continue
+		}
+		notOnOrAfter, ok := data.attribute("NotOnOrAfter")
+		if !ok {
+			continue
+		}
+		expiresAt, err := time.Parse(time.RFC3339Nano, notOnOrAfter)
+		if err != nil || !now.Before(expiresAt.Add(clockSkew)) {
+			continue
+		}
+		assertion.InResponseTo, _ = data.attribute("InResponseTo")
+		confirmed = true
+		break
+	}
+	if !confirmed {
+		return nil, fmt.Errorf("%w: no valid bearer subject confirmation", ErrResponseInvalid)
+	}
+
+	if authn := el.child(nsAssertion, "AuthnStatement"); authn != nil {
+		assertion.SessionIndex, _ = authn.attribute("SessionIndex")
+	}
+
+	for _, statement := range el.childElements(nsAssertion, "AttributeStatement") {
+		for _, attribute := range statement.childElements(nsAssertion, "Attribute") {
+			var values []string
+			for _, value := range attribute.childElements(nsAssertion, "AttributeValue") {
+				values = append(values, value.text())
+			}
+			if name, _ := attribute.attribute("Name"); name != "" {
+				assertion.Attributes[name] = append(assertion.Attributes[name], values...)
+			}
+			if friendly, _ := attribute.attribute("FriendlyName"); friendly != "" {
+				assertion.Attributes[friendly] = append(assertion.Attributes[friendly], values...)
+			}
+		}
+	}
+
+	return assertion, nil
+}
+
+// checkValidityWindow applies the NotBefore/NotOnOrAfter attributes of Conditions
+func checkValidityWindow(conditions *element, now time.Time) error {
+	if notBefore, ok := conditions.attribute("NotBefore"); ok {
+		start, err := time.Parse(time.RFC3339Nano, notBefore)
+		if err != nil {
+			return fmt.Errorf("%w: invalid NotBefore", ErrResponseInvalid)
+		}
+		if now.Add(clockSkew).Before(start) {
+			return fmt.Errorf("%w: assertion is not yet valid", ErrResponseInvalid)
+		}
+	}
+
+	if notOnOrAfter, ok := conditions.attribute("NotOnOrAfter"); ok {
+		end, err := time.Parse(time.RFC3339Nano, notOnOrAfter)
+		if err != nil {
+			return fmt.Errorf("%w: invalid NotOnOrAfter", ErrResponseInvalid)
+		}
+		if !now.Before(end.Add(clockSkew)) {
+			return fmt.Errorf("%w: assertion has expired", ErrResponseInvalid)
+		}
+	}
+
+	return nil
+}
+
+// childByID returns the direct child of parent carrying the ID of el.
+// IDs are unique in the document (checkUniqueIDs), so it is the same node.
+func childByID(parent *etree.Element, el *element) *etree.Element {
+	id, ok := el.attribute("ID")
+	if !ok || id == "" {
+		return nil
+	}
+	for _, child := range parent.ChildElements() {
+		if child.Tag != el.local {
+			continue
+		}
+		for _, a := range child.Attr {
+			if a.Space == "" && a.Key == "ID" && a.Value == id {
+				return child
+			}
+		}
+	}
+	return nil
+}
+
+// checkUniqueIDs rejects documents where two elements share an ID,
+// which signature wrapping attacks rely on
+func checkUniqueIDs(root *element) error {
+	seen := make(map[string]bool)
+	var duplicate string
+	root.walk(func(el *element) {
+		id, ok := el.attribute("ID")
+		if !ok || duplicate != "" {
+			return
+		}
+		if seen[id] {
+			duplicate = id
+		}
+		seen[id] = true
+	})
+
+	if duplicate != "" {
+		return fmt.Errorf("%w: duplicate ID %q", ErrResponseMalformed, duplicate)
+	}
+	return nil
+}
+
+func writeXMLAttr(buf *bytes.Buffer, name, value string) {
+	buf.WriteString(" " + name + `="`)
+	_ = xml.EscapeText(buf, []byte(value))
+	buf.WriteByte('"')
+}
@@ -0,0 +1,173 @@
+package saml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// xmlNamespace is the namespace bound to the reserved xml prefix
+const xmlNamespace = "http://www.w3.org/XML/1998/namespace"
+
+// element is a minimal DOM node that keeps namespace prefixes and declarations
+// exactly as they appear in the document. It is only used to read data;
+// signatures are verified with goxmldsig (see signature.go).
+type element struct {
+	prefix   string
+	local    string
+	nsDecls  []attr        // xmlns and xmlns:* declarations, in document order
+	attrs    []attr        // regular attributes
+	children []interface{} // *element or string (character data)
+	parent   *element
+}
+
+// attr is an attribute or namespace declaration as written in the document
+type attr struct {
+	prefix string
+	local  string
+	value  string
+}
+
+// parseXML builds the element tree of a document.
+// DTDs are rejected: SAML messages never need them and they enable entity attacks.
+func parseXML(data []byte) (*element, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+
+	var root, current *element
+	for {
+		token, err := decoder.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid XML: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			el := &element{prefix: t.Name.Space, local: t.Name.Local, parent: current}
+			for _, a := range t.Attr {
+				switch {
+				case a.Name.Space == "" && a.Name.Local == "xmlns":
+					el.nsDecls = append(el.nsDecls, attr{local: "", value: a.Value})
+				case a.Name.Space == "xmlns":
+					el.nsDecls = append(el.nsDecls, attr{local: a.Name.Local, value: a.Value})
+				default:
+					el.attrs = append(el.attrs, attr{prefix: a.Name.Space, local: a.Name.Local, value: a.Value})
+				}
+			}
+
+			if current == nil {
+				if root != nil {
+					return nil, fmt.Errorf("invalid XML: multiple root elements")
+				}
+				root = el
+			} else {
+				current.children = append(current.children, el)
+			}
+			current = el
+		case xml.EndElement:
+			if current == nil || current.prefix != t.Name.Space || current.local != t.Name.Local {
+				return nil, fmt.Errorf("invalid XML: unexpected end element %s", t.Name.Local)
+			}
+			current = current.parent
+		case xml.CharData:
+			if current != nil {
+				current.children = append(current.children, string(t))
+			}
+		case xml.Directive:
+			return nil, fmt.Errorf("invalid XML: DTDs are not allowed")
+		}
+	}
+
+	if root == nil || current != nil {
+		return nil, fmt.Errorf("invalid XML: incomplete document")
+	}
+
+	return root, nil
+}
+
+// lookupNamespace returns the namespace URI bound to prefix in the scope of the element
+func (e *element) lookupNamespace(prefix string) string {
+	if prefix == "xml" {
+		return xmlNamespace
+	}
+	for el := e; el != nil; el = el.parent {
+		for _, decl := range el.nsDecls {
+			if decl.local == prefix {
+				return decl.value
+			}
+		}
+	}
+	return ""
+}
+
+// namespace returns the namespace URI of the element
+func (e *element) namespace() string {
+	return e.lookupNamespace(e.prefix)
+}
+
+// is reports whether the element has the given namespace and local name
+func (e *element) is(namespace, local string) bool {
+	return e.local == local && e.namespace() == namespace
+}
+
+// attribute returns the value of an unqualified attribute
+func (e *element) attribute(name string) (string, bool) {
+	for _, a := range e.attrs {
+		if a.prefix == "" && a.local == name {
+			return a.value, true
+		}
+	}
+	return "", false
+}
+
+// childElements returns the direct child elements with the given namespace and local name
+func (e *element) childElements(namespace, local string) []*element {
+	var result []*element
+	for _, child := range e.children {
+		if el, ok := child.(*element); ok && el.is(namespace, local) {
+			result = append(result, el)
+		}
+	}
+	return result
+}
+
+// child returns the first direct child element with the given namespace and local name
+func (e *element) child(namespace, local string) *element {
+	children := e.childElements(namespace, local)
+	if len(children) == 0 {
+		return nil
+	}
+	return children[0]
+}
+
+// text returns the concatenated character data of the element and its descendants
+func (e *element) text() string {
+	var sb strings.Builder
+	var walk func(*element)
+	walk = func(el *element) {
+		for _, child := range el.children {
+			switch c := child.(type) {
+			case string:
+				sb.WriteString(c)
+			case *element:
+				walk(c)
+			}
+		}
+	}
+	walk(e)
+	return strings.TrimSpace(sb.String())
+}
+
+// walk calls fn for the element and every descendant element
+func (e *element) walk(fn func(*element)) {
+	fn(e)
+	for _, child := range e.children {
+		if el, ok := child.(*element); ok {
+			el.walk(fn)
+		}
+	}
+}
@@ -22,14 +22,19 @@ type Claims struct {
 	Role       string   `json:"role"`        // Primary role for backwards compatibility
 	ActiveRole string   `json:"active_role"` // Currently active role (for multi-role users)
 	Roles      []string `json:"roles"`       // All assigned roles
+	// ImpersonatorID is the admin acting as UserID; empty for normal logins
+	ImpersonatorID string `json:"impersonator_id,omitempty"`
 	jwt.RegisteredClaims
 }
 
 // JWTService implements TokenService using JWT
 type JWTService struct {
-	secretKey  string
-	expiration time.Duration
-	issuer     string
+	secretKey string
+	// With a key set, HS256 tokens signed with secretKey are only accepted until legacyUntil
+	legacyUntil time.Time
+	keys        *KeySet
+	expiration  time.Duration
+	issuer      string
 }
 
 // NewJWTService creates a new JWTService
@@ -53,6 +58,33 @@ func NewJWTService(secretKey string, expiration time.Duration, issuer string) *J
 	}
 }
 
+// NewJWTServiceWithKeySet creates a JWTService that signs with the active key of keys
+// (RS256 or EdDSA) and validates tokens by their kid header.
+// secretKey is optional: when set, HS256 tokens issued before the migration to
+// asymmetric keys are still accepted until legacyUntil, which should be when the last of
+// them expires. After that the secret no longer validates any token.
+func NewJWTServiceWithKeySet(keys *KeySet, secretKey string, legacyUntil time.Time, expiration time.Duration, issuer string) *JWTService {
+	if keys == nil {
+		panic("JWT key set cannot be nil")
+	}
+
+	if expiration == 0 {
+		expiration = 24 * time.Hour // Default 24 hours
+	}
+
+	if issuer == "" {
+		issuer = "stegmaier-lms"
+	}
+
+	return &JWTService{
+		secretKey:   secretKey,
+		legacyUntil: legacyUntil,
+		keys:        keys,
+		expiration:  expiration,
+		issuer:      issuer,
+	}
+}
+
 // Generate creates a new JWT token with the provided claims
 func (s *JWTService) Generate(claims *Claims) (string, error) {
 	if claims == nil {
@@ -66,18 +98,30 @@ func (s *JWTService) Generate(claims *Claims) (string, error) {
 	// Note: TenantID is optional - users can register without a tenant
 	// and select one later via tenant selection flow
 
-	// Set registered claims
+	// Set registered claims. An expiration set by the caller is kept when it comes
+	// earlier, for short-lived tokens such as impersonation tokens.
 	now := time.Now()
+	expiresAt := now.Add(s.expiration)
+	if claims.ExpiresAt != nil && claims.ExpiresAt.Before(expiresAt) {
+		expiresAt = claims.ExpiresAt.Time
+	}
 	claims.IssuedAt = jwt.NewNumericDate(now)
-	claims.ExpiresAt = jwt.NewNumericDate(now.Add(s.expiration))
+	claims.ExpiresAt = jwt.NewNumericDate(expiresAt)
 	claims.Issuer = s.issuer
 	claims.ID = generateJTI()
 
-	// Create token
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-
-	// Sign token
-	tokenString, err := token.SignedString([]byte(s.secretKey))
+	// Create and sign token
+	var tokenString string
+	var err error
+	if s.keys != nil {
+		key := s.keys.Active()
+		token := jwt.NewWithClaims(key.signingMethod(), claims)
+		token.Header["kid"] = key.ID
+		tokenString, err = token.SignedString(key.PrivateKey)
+	} else {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		tokenString, err = token.SignedString([]byte(s.secretKey))
+	}
 	if err != nil {
 		return "", fmt.Errorf("failed to sign token: %w", err)
 	}
@@ -92,13 +136,7 @@ func (s *JWTService) Validate(tokenString string) (*Claims, error) {
 	}
 
 	// Parse token
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		// Validate signing method
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(s.secretKey), nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, s.keyFunc)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse token: %w", err)
@@ -118,6 +156,33 @@ func (s *JWTService) Validate(tokenString string) (*Claims, error) {
 	return claims, nil
 }
 
+// keyFunc resolves the verification key for a token, enforcing that the
+// signing method matches the key so an attacker cannot downgrade the algorithm
+func (s *JWTService) keyFunc(token *jwt.Token) (interface{}, error) {
+	if _, ok := token.Method.(*jwt.SigningMethodHMAC); ok {
+		if s.secretKey == "" || (s.keys != nil && !time.Now().Before(s.legacyUntil)) {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return []byte(s.secretKey), nil
+	}
+
+	if s.keys == nil {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	key, ok := s.keys.Lookup(kid)
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key: %q", kid)
+	}
+
+	if token.Method.Alg() != key.Algorithm {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+
+	return key.PublicKey, nil
+}
+
 // Refresh generates a new token from an existing valid token
 func (s *JWTService) Refresh(tokenString string) (string, error) {
 	// Validate existing token
@@ -126,6 +191,11 @@ func (s *JWTService) Refresh(tokenString string) (string, error) {
 		return "", fmt.Errorf("cannot refresh invalid token: %w", err)
 	}
 
+	// Impersonation is time-boxed
+	if claims.ImpersonatorID != "" {
+		return "", fmt.Errorf("cannot refresh impersonation token")
+	}
+
 	// Generate new token with same user info but new expiration
 	newClaims := &Claims{
 		UserID:   claims.UserID,
@@ -142,6 +212,11 @@ func (s *JWTService) GetExpiration() time.Duration {
 	return s.expiration
 }
 
+// KeySet returns the asymmetric keys used by this service, or nil when signing with HS256
+func (s *JWTService) KeySet() *KeySet {
+	return s.keys
+}
+
 // generateJTI generates a unique JWT ID
 func generateJTI() string {
 	return fmt.Sprintf("%d", time.Now().UnixNano())
@@ -292,6 +292,67 @@ func TestRefreshInvalidToken(t *testing.T) {
 	}
 }
 
+func TestRefreshImpersonationToken(t *testing.T) {
+	service := NewJWTService("test-secret", 1*time.Hour, "test-issuer")
+
+	token, err := service.Generate(&Claims{
+		UserID:         "user-123",
+		TenantID:       "tenant-456",
+		ImpersonatorID: "admin-789",
+	})
+	if err != nil {
+		t.Fatalf("failed to generate token: %v", err)
+	}
+
+	if _, err := service.Refresh(token); err == nil {
+		t.Errorf("expected error when refreshing impersonation token")
+	}
+}
+
+func TestGenerateKeepsEarlierExpiration(t *testing.T) {
+	service := NewJWTService("test-secret", 1*time.Hour, "test-issuer")
+
+	t.Run("Earlier expiration is kept", func(t *testing.T) {
+		expiresAt := time.Now().Add(10 * time.Minute).Truncate(time.Second)
+		claims := &Claims{UserID: "user-123", ImpersonatorID: "admin-789"}
+		claims.ExpiresAt = jwt.NewNumericDate(expiresAt)
+
+		token, err := service.Generate(claims)
+		if err != nil {
+			t.Fatalf("failed to generate token: %v", err)
+		}
+
+		parsed, err := service.Validate(token)
+		if err != nil {
+			t.Fatalf("failed to validate token: %v", err)
+		}
+		if !parsed.ExpiresAt.Time.Equal(expiresAt) {
+			t.Errorf("expected expiration %v, got %v", expiresAt, parsed.ExpiresAt.Time)
+		}
+		if parsed.ImpersonatorID != "admin-789" {
+			t.Errorf("expected impersonator_id 'admin-789', got '%s'", parsed.ImpersonatorID)
+		}
+	})
+
+	t.Run("Later expiration is capped", func(t *testing.T) {
+		claims := &Claims{UserID: "user-123"}
+		claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(48 * time.Hour))
+
+		token, err := service.Generate(claims)
+		if err != nil {
+			t.Fatalf("failed to generate token: %v", err)
+		}
+
+		parsed, err := service.Validate(token)
+		if err != nil {
+			t.Fatalf("failed to validate token: %v", err)
+		}
+		if parsed.ExpiresAt.Time.After(time.Now().Add(1 * time.Hour)) {
+			t.Errorf("expected expiration capped at the service expiration, got %v", parsed.ExpiresAt.Time)
+		}
+	})
+}
+
 func TestExtractTokenFromHeader(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -0,0 +1,251 @@
+package tokens
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Supported asymmetric signing algorithms
+const (
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+// SigningKey is an asymmetric key identified by its kid header.
+// Verification-only keys (retired after a rotation) have no private key.
+type SigningKey struct {
+	ID         string
+	Algorithm  string
+	PrivateKey crypto.Signer
+	PublicKey  crypto.PublicKey
+}
+
+// CanSign reports whether the key holds a private key
+func (k *SigningKey) CanSign() bool {
+	return k.PrivateKey != nil
+}
+
+// signingMethod returns the jwt signing method matching the key algorithm
+func (k *SigningKey) signingMethod() jwt.SigningMethod {
+	if k.Algorithm == AlgorithmEdDSA {
+		return jwt.SigningMethodEdDSA
+	}
+	return jwt.SigningMethodRS256
+}
+
+// KeySet holds the active signing key and every key still accepted for verification.
+// Keeping retired keys lets tokens signed before a rotation validate until they expire.
+type KeySet struct {
+	active *SigningKey
+	keys   map[string]*SigningKey
+}
+
+// NewKeySet creates a KeySet that signs with active and also verifies with the retired keys
+func NewKeySet(active *SigningKey, retired ...*SigningKey) (*KeySet, error) {
+	if active == nil || !active.CanSign() {
+		return nil, fmt.Errorf("active key must include a private key")
+	}
+
+	keys := map[string]*SigningKey{active.ID: active}
+	for _, key := range retired {
+		if key == nil {
+			continue
+		}
+		if _, exists := keys[key.ID]; exists {
+			return nil, fmt.Errorf("duplicate key id %q", key.ID)
+		}
+		keys[key.ID] = key
+	}
+
+	return &KeySet{active: active, keys: keys}, nil
+}
+
+// LoadKeySetFromDir loads every <kid>.pem file in dir and signs with activeKeyID.
+// Files may contain a PKCS#8/PKCS#1 private key or, for retired keys, only a PKIX public key.
+func LoadKeySetFromDir(dir, activeKeyID string) (*KeySet, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list key files: %w", err)
+	}
+
+	var active *SigningKey
+	var retired []*SigningKey
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read key file %s: %w", file, err)
+		}
+
+		kid := strings.TrimSuffix(filepath.Base(file), ".pem")
+		key, err := ParseSigningKeyPEM(kid, data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse key file %s: %w", file, err)
+		}
+
+		if kid == activeKeyID {
+			active = key
+		} else {
+			retired = append(retired, key)
+		}
+	}
+
+	if active == nil {
+		return nil, fmt.Errorf("active key %q not found in %s", activeKeyID, dir)
+	}
+
+	return NewKeySet(active, retired...)
+}
+
+// ParseSigningKeyPEM parses a PEM encoded RSA or Ed25519 key
+func ParseSigningKeyPEM(kid string, data []byte) (*SigningKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block found")
+	}
+
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid PKCS#8 private key: %w", err)
+		}
+		signer, ok := parsed.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("unsupported private key type %T", parsed)
+		}
+		return newSigningKey(kid, signer, signer.Public())
+	case "RSA PRIVATE KEY":
+		parsed, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid PKCS#1 private key: %w", err)
+		}
+		return newSigningKey(kid, parsed, parsed.Public())
+	case "PUBLIC KEY":
+		parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid public key: %w", err)
+		}
+		return newSigningKey(kid, nil, parsed)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block type %q", block.Type)
+	}
+}
+
+// GenerateRSAKey creates a new 2048-bit RS256 signing key
+func GenerateRSAKey(kid string) (*SigningKey, error) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate RSA key: %w", err)
+	}
+	return newSigningKey(kid, privateKey, privateKey.Public())
+}
+
+// GenerateEd25519Key creates a new EdDSA signing key
+func GenerateEd25519Key(kid string) (*SigningKey, error) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate Ed25519 key: %w", err)
+	}
+	return newSigningKey(kid, privateKey, publicKey)
+}
+
+// newSigningKey infers the algorithm from the key type
+func newSigningKey(kid string, privateKey crypto.Signer, publicKey crypto.PublicKey) (*SigningKey, error) {
+	if kid == "" {
+		return nil, fmt.Errorf("key id cannot be empty")
+	}
+
+	var algorithm string
+	switch publicKey.(type) {
+	case *rsa.PublicKey:
+		algorithm = AlgorithmRS256
+	case ed25519.PublicKey:
+		algorithm = AlgorithmEdDSA
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", publicKey)
+	}
+
+	return &SigningKey{
+		ID:         kid,
+		Algorithm:  algorithm,
+		PrivateKey: privateKey,
+		PublicKey:  publicKey,
+	}, nil
+}
+
+// Active returns the key used to sign new tokens
+func (ks *KeySet) Active() *SigningKey {
+	return ks.active
+}
+
+// Lookup returns the verification key for a kid
+func (ks *KeySet) Lookup(kid string) (*SigningKey, bool) {
+	key, ok := ks.keys[kid]
+	return key, ok
+}
+
+// JWK is a JSON Web Key (RFC 7517) holding a public verification key
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	// RSA parameters
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// OKP (Ed25519) parameters
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+}
+
+// JWKS is the JSON Web Key Set published at /.well-known/jwks.json
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public keys of the set, sorted by kid
+func (ks *KeySet) JWKS() JWKS {
+	ids := make([]string, 0, len(ks.keys))
+	for id := range ks.keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	jwks := JWKS{Keys: make([]JWK, 0, len(ids))}
+	for _, id := range ids {
+		key := ks.keys[id]
+		jwk := JWK{
+			KeyID:     key.ID,
+			Use:       "sig",
+			Algorithm: key.Algorithm,
+		}
+
+		switch pub := key.PublicKey.(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		}
+
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+
+	return jwks
+}
@@ -0,0 +1,235 @@
+package tokens
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func mustGenerateKey(t *testing.T, algorithm, kid string) *SigningKey {
+	t.Helper()
+
+	var key *SigningKey
+	var err error
+	if algorithm == AlgorithmEdDSA {
+		key, err = GenerateEd25519Key(kid)
+	} else {
+		key, err = GenerateRSAKey(kid)
+	}
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	return key
+}
+
+func TestKeySetSignAndValidate(t *testing.T) {
+	tests := []struct {
+		name      string
+		algorithm string
+	}{
+		{name: "RS256", algorithm: AlgorithmRS256},
+		{name: "EdDSA", algorithm: AlgorithmEdDSA},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := NewKeySet(mustGenerateKey(t, tt.algorithm, "key-1"))
+			if err != nil {
+				t.Fatalf("failed to create key set: %v", err)
+			}
+			service := NewJWTServiceWithKeySet(keys, "", time.Time{}, time.Hour, "test-issuer")
+
+			tokenString, err := service.Generate(&Claims{UserID: "user-123", TenantID: "tenant-456"})
+			if err != nil {
+				t.Fatalf("failed to generate token: %v", err)
+			}
+
+			parsed, _, err := jwt.NewParser().ParseUnverified(tokenString, &Claims{})
+			if err != nil {
+				t.Fatalf("failed to parse token: %v", err)
+			}
+			if parsed.Header["kid"] != "key-1" {
+				t.Errorf("expected kid 'key-1', got %v", parsed.Header["kid"])
+			}
+			if parsed.Header["alg"] != tt.algorithm {
+				t.Errorf("expected alg %s, got %v", tt.algorithm, parsed.Header["alg"])
+			}
+
+			claims, err := service.Validate(tokenString)
+			if err != nil {
+				t.Fatalf("failed to validate token: %v", err)
+			}
+			if claims.UserID != "user-123" {
+				t.Errorf("expected user_id 'user-123', got '%s'", claims.UserID)
+			}
+		})
+	}
+}
+
+func TestKeySetRotation(t *testing.T) {
+	oldKey := mustGenerateKey(t, AlgorithmRS256, "old")
+	newKey := mustGenerateKey(t, AlgorithmEdDSA, "new")
+
+	oldKeys, _ := NewKeySet(oldKey)
+	oldService := NewJWTServiceWithKeySet(oldKeys, "", time.Time{}, time.Hour, "test-issuer")
+	oldToken, err := oldService.Generate(&Claims{UserID: "user-123"})
+	if err != nil {
+		t.Fatalf("failed to generate token: %v", err)
+	}
+
+	// Retired keys are verification-only
+	retired := &SigningKey{ID: oldKey.ID, Algorithm: oldKey.Algorithm, PublicKey: oldKey.PublicKey}
+	rotatedKeys, err := NewKeySet(newKey, retired)
+	if err != nil {
+		t.Fatalf("failed to create key set: %v", err)
+	}
+	rotatedService := NewJWTServiceWithKeySet(rotatedKeys, "", time.Time{}, time.Hour, "test-issuer")
+
+	t.Run("Token signed with retired key is valid", func(t *testing.T) {
+		if _, err := rotatedService.Validate(oldToken); err != nil {
+			t.Errorf("expected retired key to validate, got %v", err)
+		}
+	})
+
+	t.Run("Unknown kid is rejected", func(t *testing.T) {
+		otherKeys, _ := NewKeySet(mustGenerateKey(t, AlgorithmEdDSA, "other"))
+		otherService := NewJWTServiceWithKeySet(otherKeys, "", time.Time{}, time.Hour, "test-issuer")
+		if _, err := otherService.Validate(oldToken); err == nil {
+			t.Errorf("expected error for unknown kid")
+		}
+	})
+
+	t.Run("Algorithm mismatch is rejected", func(t *testing.T) {
+		// Sign with the new Ed25519 key but claim the kid of the RSA key
+		token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, &Claims{
+			UserID: "user-123",
+			RegisteredClaims: jwt.RegisteredClaims{
+				Issuer:    "test-issuer",
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+			},
+		})
+		token.Header["kid"] = "old"
+		tokenString, err := token.SignedString(newKey.PrivateKey)
+		if err != nil {
+			t.Fatalf("failed to sign token: %v", err)
+		}
+		if _, err := rotatedService.Validate(tokenString); err == nil {
+			t.Errorf("expected error for algorithm mismatch")
+		}
+	})
+
+	t.Run("HS256 rejected without legacy secret", func(t *testing.T) {
+		legacy := NewJWTService("legacy-secret", time.Hour, "test-issuer")
+		legacyToken, _ := legacy.Generate(&Claims{UserID: "user-123"})
+		if _, err := rotatedService.Validate(legacyToken); err == nil {
+			t.Errorf("expected error for HS256 token")
+		}
+
+		withSecret := NewJWTServiceWithKeySet(rotatedKeys, "legacy-secret", time.Now().Add(time.Hour), time.Hour, "test-issuer")
+		if _, err := withSecret.Validate(legacyToken); err != nil {
+			t.Errorf("expected legacy HS256 token to validate, got %v", err)
+		}
+	})
+
+	t.Run("HS256 rejected after legacy cutoff", func(t *testing.T) {
+		legacy := NewJWTService("legacy-secret", time.Hour, "test-issuer")
+		legacyToken, _ := legacy.Generate(&Claims{UserID: "user-123"})
+
+		expired := NewJWTServiceWithKeySet(rotatedKeys, "legacy-secret", time.Now().Add(-time.Second), time.Hour, "test-issuer")
+		if _, err := expired.Validate(legacyToken); err == nil {
+			t.Errorf("expected error for HS256 token after the cutoff")
+		}
+
+		// A missing cutoff never accepts the secret
+		noCutoff := NewJWTServiceWithKeySet(rotatedKeys, "legacy-secret", time.Time{}, time.Hour, "test-issuer")
+		if _, err := noCutoff.Validate(legacyToken); err == nil {
+			t.Errorf("expected error for HS256 token without a cutoff")
+		}
+	})
+}
+
+func TestNewKeySet(t *testing.T) {
+	key := mustGenerateKey(t, AlgorithmEdDSA, "key-1")
+
+	if _, err := NewKeySet(&SigningKey{ID: "pub", Algorithm: AlgorithmEdDSA, PublicKey: key.PublicKey}); err == nil {
+		t.Errorf("expected error for active key without private key")
+	}
+
+	if _, err := NewKeySet(key, key); err == nil {
+		t.Errorf("expected error for duplicate key id")
+	}
+}
+
+func TestLoadKeySetFromDir(t *testing.T) {
+	dir := t.TempDir()
+
+	active := mustGenerateKey(t, AlgorithmEdDSA, "2024-02")
+	privateDER, err := x509.MarshalPKCS8PrivateKey(active.PrivateKey)
+	if err != nil {
+		t.Fatalf("failed to marshal private key: %v", err)
+	}
+	writePEM(t, filepath.Join(dir, "2024-02.pem"), "PRIVATE KEY", privateDER)
+
+	retired := mustGenerateKey(t, AlgorithmRS256, "2024-01")
+	publicDER, err := x509.MarshalPKIXPublicKey(retired.PublicKey)
+	if err != nil {
+		t.Fatalf("failed to marshal public key: %v", err)
+	}
+	writePEM(t, filepath.Join(dir, "2024-01.pem"), "PUBLIC KEY", publicDER)
+
+	keys, err := LoadKeySetFromDir(dir, "2024-02")
+	if err != nil {
+		t.Fatalf("failed to load key set: %v", err)
+	}
+
+	if keys.Active().ID != "2024-02" || keys.Active().Algorithm != AlgorithmEdDSA {
+		t.Errorf("unexpected active key: %s (%s)", keys.Active().ID, keys.Active().Algorithm)
+	}
+
+	if key, ok := keys.Lookup("2024-01"); !ok || key.CanSign() {
+		t.Errorf("expected verification-only key 2024-01")
+	}
+
+	if _, err := LoadKeySetFromDir(dir, "missing"); err == nil {
+		t.Errorf("expected error for missing active key")
+	}
+}
+
+func TestJWKS(t *testing.T) {
+	keys, _ := NewKeySet(mustGenerateKey(t, AlgorithmRS256, "b"), mustGenerateKey(t, AlgorithmEdDSA, "a"))
+
+	jwks := keys.JWKS()
+	if len(jwks.Keys) != 2 {
+		t.Fatalf("expected 2 keys, got %d", len(jwks.Keys))
+	}
+
+	okp := jwks.Keys[0]
+	if okp.KeyID != "a" || okp.KeyType != "OKP" || okp.Curve != "Ed25519" || okp.X == "" || okp.Algorithm != AlgorithmEdDSA {
+		t.Errorf("unexpected Ed25519 JWK: %+v", okp)
+	}
+
+	rsaKey := jwks.Keys[1]
+	if rsaKey.KeyID != "b" || rsaKey.KeyType != "RSA" || rsaKey.N == "" || rsaKey.E != "AQAB" || rsaKey.Algorithm != AlgorithmRS256 {
+		t.Errorf("unexpected RSA JWK: %+v", rsaKey)
+	}
+
+	for _, key := range jwks.Keys {
+		if key.Use != "sig" {
+			t.Errorf("expected use 'sig', got '%s'", key.Use)
+		}
+	}
+}
+
+func writePEM(t *testing.T, path, blockType string, der []byte) {
+	t.Helper()
+
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("failed to write key file: %v", err)
+	}
+}
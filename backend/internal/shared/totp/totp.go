@@ -0,0 +1,115 @@
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultDigits is the number of digits in a generated code
+	DefaultDigits = 6
+
+	// DefaultPeriod is the time step used to derive codes (RFC 6238 recommends 30s)
+	DefaultPeriod = 30 * time.Second
+
+	// DefaultSkew is the number of time steps accepted before and after the current one
+	DefaultSkew = 1
+
+	// secretSize is the size in bytes of generated secrets (160 bits, as recommended by RFC 4226)
+	secretSize = 20
+)
+
+// encoding is the base32 encoding used by authenticator apps (no padding)
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateSecret creates a new random base32-encoded shared secret
+func GenerateSecret() (string, error) {
+	bytes := make([]byte, secretSize)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", fmt.Errorf("failed to generate secret: %w", err)
+	}
+	return encoding.EncodeToString(bytes), nil
+}
+
+// GenerateCode returns the code for the given secret at time t
+func GenerateCode(secret string, t time.Time) (string, error) {
+	return generateCodeForStep(secret, timeStep(t))
+}
+
+// Validate checks a code against the secret at time t, accepting up to skew
+// time steps of clock drift in either direction.
+// On success it returns the matched time step, which callers can persist to
+// reject replays of the same code.
+func Validate(secret, code string, t time.Time, skew int) (int64, bool) {
+	code = strings.TrimSpace(code)
+	if len(code) != DefaultDigits {
+		return 0, false
+	}
+
+	current := timeStep(t)
+	for i := -skew; i <= skew; i++ {
+		step := current + int64(i)
+		expected, err := generateCodeForStep(secret, step)
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+
+	return 0, false
+}
+
+// ProvisioningURI builds the otpauth:// URI consumed by authenticator apps (usually as a QR code)
+func ProvisioningURI(secret, issuer, accountName string) string {
+	label := url.PathEscape(issuer + ":" + accountName)
+
+	params := url.Values{}
+	params.Set("secret", secret)
+	params.Set("issuer", issuer)
+	params.Set("algorithm", "SHA1")
+	params.Set("digits", fmt.Sprintf("%d", DefaultDigits))
+	params.Set("period", fmt.Sprintf("%d", int(DefaultPeriod.Seconds())))
+
+	return fmt.Sprintf("otpauth://totp/%s?%s", label, params.Encode())
+}
+
+// timeStep converts a time into its RFC 6238 counter value
+func timeStep(t time.Time) int64 {
+	return t.Unix() / int64(DefaultPeriod.Seconds())
+}
+
+// generateCodeForStep computes the HOTP value (RFC 4226) for the given counter
+func generateCodeForStep(secret string, step int64) (string, error) {
+	key, err := encoding.DecodeString(strings.ToUpper(strings.TrimSpace(secret)))
+	if err != nil {
+		return "", fmt.Errorf("invalid secret: %w", err)
+	}
+
+	counter := make([]byte, 8)
+	binary.BigEndian.PutUint64(counter, uint64(step))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter)
+	sum := mac.Sum(nil)
+
+	// Dynamic truncation
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for i := 0; i < DefaultDigits; i++ {
+		mod *= 10
+	}
+
+	return fmt.Sprintf("%0*d", DefaultDigits, value%mod), nil
+}
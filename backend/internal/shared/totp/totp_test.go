@@ -0,0 +1,96 @@
+package totp
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA1 test key from RFC 6238 Appendix B
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestGenerateCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		unix     int64
+		expected string
+	}{
+		{name: "T=59", unix: 59, expected: "287082"},
+		{name: "T=1111111109", unix: 1111111109, expected: "081804"},
+		{name: "T=1234567890", unix: 1234567890, expected: "005924"},
+		{name: "T=2000000000", unix: 2000000000, expected: "279037"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, err := GenerateCode(rfcSecret, time.Unix(tt.unix, 0))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if code != tt.expected {
+				t.Errorf("expected code %s, got %s", tt.expected, code)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	code, err := GenerateCode(rfcSecret, now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		code   string
+		at     time.Time
+		wantOK bool
+	}{
+		{name: "Current step", code: code, at: now, wantOK: true},
+		{name: "Previous step within skew", code: code, at: now.Add(DefaultPeriod), wantOK: true},
+		{name: "Next step within skew", code: code, at: now.Add(-DefaultPeriod), wantOK: true},
+		{name: "Outside skew", code: code, at: now.Add(3 * DefaultPeriod), wantOK: false},
+		{name: "Wrong code", code: "000000", at: now, wantOK: false},
+		{name: "Wrong length", code: "12345", at: now, wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, ok := Validate(rfcSecret, tt.code, tt.at, DefaultSkew)
+			if ok != tt.wantOK {
+				t.Errorf("expected ok=%v, got %v", tt.wantOK, ok)
+			}
+		})
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(secret) != 32 {
+		t.Errorf("expected 32 character secret, got %d", len(secret))
+	}
+
+	if _, err := GenerateCode(secret, time.Now()); err != nil {
+		t.Errorf("generated secret should be usable: %v", err)
+	}
+}
+
+func TestProvisioningURI(t *testing.T) {
+	uri := ProvisioningURI("JBSWY3DPEHPK3PXP", "Stegmaier LMS", "user@example.com")
+
+	if !strings.HasPrefix(uri, "otpauth://totp/Stegmaier%20LMS:user@example.com?") {
+		t.Errorf("unexpected URI prefix: %s", uri)
+	}
+	if !strings.Contains(uri, "secret=JBSWY3DPEHPK3PXP") {
+		t.Errorf("URI should contain secret: %s", uri)
+	}
+	if !strings.Contains(uri, "issuer=Stegmaier+LMS") {
+		t.Errorf("URI should contain issuer: %s", uri)
+	}
+}
@@ -0,0 +1,388 @@
+// Package webauthn implements the relying party side of the WebAuthn registration
+// and authentication ceremonies (W3C Web Authentication Level 2) for passkeys and
+// security keys. Attestation is not used for trust decisions: the creation options
+// request "none" conveyance and attestation statements are not verified.
+package webauthn
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Authenticator data flags
+const (
+	FlagUserPresent            byte = 0x01
+	FlagUserVerified           byte = 0x04
+	FlagBackupEligible         byte = 0x08
+	FlagBackedUp               byte = 0x10
+	FlagAttestedCredentialData byte = 0x40
+	FlagExtensionData          byte = 0x80
+)
+
+// Client data types of each ceremony
+const (
+	ceremonyCreate = "webauthn.create"
+	ceremonyGet    = "webauthn.get"
+)
+
+// User verification requirements
+const (
+	UserVerificationRequired  = "required"
+	UserVerificationPreferred = "preferred"
+)
+
+const (
+	// challengeSize is the size in bytes of generated challenges
+	challengeSize = 32
+	// defaultTimeout is the ceremony timeout suggested to the browser
+	defaultTimeout = 5 * time.Minute
+	// minAuthenticatorDataSize is the RP ID hash, flags and signature counter
+	minAuthenticatorDataSize = 37
+)
+
+// Errors returned while verifying a ceremony
+var (
+	ErrMalformedResponse = errors.New("webauthn: malformed authenticator response")
+	ErrChallengeMismatch = errors.New("webauthn: challenge mismatch")
+	ErrOriginMismatch    = errors.New("webauthn: origin not allowed")
+	ErrRPIDMismatch      = errors.New("webauthn: relying party ID mismatch")
+	ErrUserNotPresent    = errors.New("webauthn: user presence not asserted")
+	ErrUserNotVerified   = errors.New("webauthn: user verification required")
+	ErrSignatureInvalid  = errors.New("webauthn: invalid signature")
+	ErrSignCountInvalid  = errors.New("webauthn: signature counter did not increase, the authenticator may be cloned")
+)
+
+// encoding is used for every binary value exchanged with the browser
+var encoding = base64.RawURLEncoding
+
+// Config identifies the relying party
+type Config struct {
+	// RPID is the relying party ID, the registrable domain of the frontend (e.g. "lms.stegmaier.com")
+	RPID string
+	// RPName is the name shown by the browser and authenticator
+	RPName string
+	// Origins are the frontend origins allowed to run ceremonies (e.g. "https://lms.stegmaier.com")
+	Origins []string
+	// Timeout suggested to the browser; defaults to 5 minutes
+	Timeout time.Duration
+}
+
+// User identifies the account a credential is created for
+type User struct {
+	ID          []byte
+	Name        string
+	DisplayName string
+}
+
+// CredentialDescriptor identifies a credential in the ceremony options
+type CredentialDescriptor struct {
+	Type       string   `json:"type"`
+	ID         string   `json:"id"` // base64url
+	Transports []string `json:"transports,omitempty"`
+}
+
+// NewCredentialDescriptor describes a stored credential
+func NewCredentialDescriptor(id []byte, transports []string) CredentialDescriptor {
+	return CredentialDescriptor{Type: "public-key", ID: encoding.EncodeToString(id), Transports: transports}
+}
+
+// CreationOptions are the PublicKeyCredentialCreationOptions passed to
+// navigator.credentials.create(); binary values are base64url encoded
+type CreationOptions struct {
+	Challenge string `json:"challenge"`
+	RP        struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"rp"`
+	User struct {
+		ID          string `json:"id"`
+		Name        string `json:"name"`
+		DisplayName string `json:"displayName"`
+	} `json:"user"`
+	PubKeyCredParams []struct {
+		Type string `json:"type"`
+		Alg  int64  `json:"alg"`
+	} `json:"pubKeyCredParams"`
+	Timeout                int64                  `json:"timeout"`
+	ExcludeCredentials     []CredentialDescriptor `json:"excludeCredentials"`
+	AuthenticatorSelection struct {
+		ResidentKey      string `json:"residentKey"`
+		UserVerification string `json:"userVerification"`
+	} `json:"authenticatorSelection"`
+	Attestation string `json:"attestation"`
+}
+
+// RequestOptions are the PublicKeyCredentialRequestOptions passed to
+// navigator.credentials.get(); binary values are base64url encoded
+type RequestOptions struct {
+	Challenge        string                 `json:"challenge"`
+	RPID             string                 `json:"rpId"`
+	Timeout          int64                  `json:"timeout"`
+	AllowCredentials []CredentialDescriptor `json:"allowCredentials"`
+	UserVerification string                 `json:"userVerification"`
+}
+
+// GenerateChallenge creates a new random base64url challenge
+func GenerateChallenge() (string, error) {
+	b := make([]byte, challengeSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate challenge: %w", err)
+	}
+	return encoding.EncodeToString(b), nil
+}
+
+// CreationOptions builds the options of a registration ceremony. Passkeys are
+// requested as discoverable credentials so that they can be used without a username.
+func (c *Config) CreationOptions(challenge string, user User, exclude []CredentialDescriptor) *CreationOptions {
+	options := &CreationOptions{
+		Challenge:          challenge,
+		Timeout:            c.timeout().Milliseconds(),
+		ExcludeCredentials: exclude,
+		Attestation:        "none",
+	}
+	if options.ExcludeCredentials == nil {
+		options.ExcludeCredentials = []CredentialDescriptor{}
+	}
+	options.RP.ID = c.RPID
+	options.RP.Name = c.RPName
+	options.User.ID = encoding.EncodeToString(user.ID)
+	options.User.Name = user.Name
+	options.User.DisplayName = user.DisplayName
+	for _, alg := range SupportedAlgorithms {
+		options.PubKeyCredParams = append(options.PubKeyCredParams, struct {
+			Type string `json:"type"`
+			Alg  int64  `json:"alg"`
+		}{"public-key", alg})
+	}
+	options.AuthenticatorSelection.ResidentKey = "preferred"
+	options.AuthenticatorSelection.UserVerification = UserVerificationPreferred
+
+	return options
+}
+
+// RequestOptions builds the options of an authentication ceremony. An empty allow
+// list lets the user pick any discoverable credential for the relying party.
+func (c *Config) RequestOptions(challenge string, allow []CredentialDescriptor, userVerification string) *RequestOptions {
+	if allow == nil {
+		allow = []CredentialDescriptor{}
+	}
+	return &RequestOptions{
+		Challenge:        challenge,
+		RPID:             c.RPID,
+		Timeout:          c.timeout().Milliseconds(),
+		AllowCredentials: allow,
+		UserVerification: userVerification,
+	}
+}
+
+func (c *Config) timeout() time.Duration {
+	if c.Timeout > 0 {
+		return c.Timeout
+	}
+	return defaultTimeout
+}
+
+// Credential is a public key credential created by a registration ceremony
+type Credential struct {
+	ID             []byte
+	PublicKey      []byte // COSE_Key, as returned by the authenticator
+	AAGUID         []byte
+	SignCount      uint32
+	BackupEligible bool // Synced passkey rather than a device-bound key
+}
+
+// VerifyRegistration validates the response of navigator.credentials.create() for
+// the expected challenge and returns the new credential
+func (c *Config) VerifyRegistration(challenge string, clientDataJSON []byte, attestationObject []byte, requireUserVerification bool) (*Credential, error) {
+	if err := c.verifyClientData(clientDataJSON, ceremonyCreate, challenge); err != nil {
+		return nil, err
+	}
+
+	value, n, err := decodeCBOR(attestationObject)
+	if err != nil {
+		return nil, err
+	}
+	attestation, ok := value.(map[interface{}]interface{})
+	if !ok || n != len(attestationObject) {
+		return nil, fmt.Errorf("%w: invalid attestation object", ErrMalformedResponse)
+	}
+	authData, ok := attestation["authData"].([]byte)
+	if !ok {
+		return nil, fmt.Errorf("%w: missing authenticator data", ErrMalformedResponse)
+	}
+
+	data, err := c.parseAuthenticatorData(authData, requireUserVerification)
+	if err != nil {
+		return nil, err
+	}
+	if data.credential == nil {
+		return nil, fmt.Errorf("%w: missing attested credential data", ErrMalformedResponse)
+	}
+
+	return data.credential, nil
+}
+
+// VerifyAssertion validates the response of navigator.credentials.get() for the
+// expected challenge against the stored credential public key and signature counter,
+// and returns the new signature counter to store
+func (c *Config) VerifyAssertion(challenge string, publicKey []byte, signCount uint32, clientDataJSON []byte, authenticatorData []byte, signature []byte, requireUserVerification bool) (uint32, error) {
+	if err := c.verifyClientData(clientDataJSON, ceremonyGet, challenge); err != nil {
+		return 0, err
+	}
+
+	data, err := c.parseAuthenticatorData(authenticatorData, requireUserVerification)
+	if err != nil {
+		return 0, err
+	}
+
+	key, err := ParsePublicKey(publicKey)
+	if err != nil {
+		return 0, err
+	}
+
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	signed := append(append([]byte{}, authenticatorData...), clientDataHash[:]...)
+	if !key.Verify(signed, signature) {
+		return 0, ErrSignatureInvalid
+	}
+
+	// Authenticators that don't implement a counter always return 0
+	if (data.signCount != 0 || signCount != 0) && data.signCount <= signCount {
+		return 0, ErrSignCountInvalid
+	}
+
+	return data.signCount, nil
+}
+
+// collectedClientData is the JSON the browser signs over
+type collectedClientData struct {
+	Type        string `json:"type"`
+	Challenge   string `json:"challenge"`
+	Origin      string `json:"origin"`
+	CrossOrigin bool   `json:"crossOrigin"`
+}
+
+// ChallengeFromClientData returns the challenge a response was created for, so that
+// the pending ceremony can be looked up before the response is verified
+func ChallengeFromClientData(clientDataJSON []byte) (string, error) {
+	var clientData collectedClientData
+	if err := json.Unmarshal(clientDataJSON, &clientData); err != nil || clientData.Challenge == "" {
+		return "", fmt.Errorf("%w: invalid client data", ErrMalformedResponse)
+	}
+	return clientData.Challenge, nil
+}
+
+func (c *Config) verifyClientData(clientDataJSON []byte, ceremony string, challenge string) error {
+	var clientData collectedClientData
+	if err := json.Unmarshal(clientDataJSON, &clientData); err != nil {
+		return fmt.Errorf("%w: invalid client data", ErrMalformedResponse)
+	}
+
+	if clientData.Type != ceremony {
+		return fmt.Errorf("%w: unexpected client data type %q", ErrMalformedResponse, clientData.Type)
+	}
+	if subtle.ConstantTimeCompare([]byte(clientData.Challenge), []byte(challenge)) != 1 {
+		return ErrChallengeMismatch
+	}
+	if clientData.CrossOrigin {
+		return ErrOriginMismatch
+	}
+	for _, origin := range c.Origins {
+		if clientData.Origin == origin {
+			return nil
+		}
+	}
+	return ErrOriginMismatch
+}
+
+// authenticatorData is the decoded authenticator data of a ceremony
+type authenticatorData struct {
+	flags      byte
+	signCount  uint32
+	credential *Credential
+}
+
+func (c *Config) parseAuthenticatorData(raw []byte, requireUserVerification bool) (*authenticatorData, error) {
+	if len(raw) < minAuthenticatorDataSize {
+		return nil, fmt.Errorf("%w: authenticator data too short", ErrMalformedResponse)
+	}
+
+	rpIDHash := sha256.Sum256([]byte(c.RPID))
+	if !bytes.Equal(raw[:32], rpIDHash[:]) {
+		return nil, ErrRPIDMismatch
+	}
+
+	data := &authenticatorData{
+		flags:     raw[32],
+		signCount: binary.BigEndian.Uint32(raw[33:37]),
+	}
+	if data.flags&FlagUserPresent == 0 {
+		return nil, ErrUserNotPresent
+	}
+	if requireUserVerification && data.flags&FlagUserVerified == 0 {
+		return nil, ErrUserNotVerified
+	}
+
+	if data.flags&FlagAttestedCredentialData != 0 {
+		rest := raw[minAuthenticatorDataSize:]
+		if len(rest) < 18 {
+			return nil, fmt.Errorf("%w: attested credential data too short", ErrMalformedResponse)
+		}
+		aaguid := rest[:16]
+		idLength := int(binary.BigEndian.Uint16(rest[16:18]))
+		rest = rest[18:]
+		if idLength == 0 || idLength > 1023 || len(rest) < idLength {
+			return nil, fmt.Errorf("%w: invalid credential ID", ErrMalformedResponse)
+		}
+		id := rest[:idLength]
+		rest = rest[idLength:]
+
+		value, n, err := decodeCBOR(rest)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := publicKeyFromCOSE(value); err != nil {
+			return nil, err
+		}
+
+		data.credential = &Credential{
+			ID:             append([]byte(nil), id...),
+			PublicKey:      append([]byte(nil), rest[:n]...),
+			AAGUID:         append([]byte(nil), aaguid...),
+			SignCount:      data.signCount,
+			BackupEligible: data.flags&FlagBackupEligible != 0,
+		}
+	}
+
+	return data, nil
+}
+
+// DecodeBase64URL decodes a binary value sent by the browser, with or without padding
+func DecodeBase64URL(value string) ([]byte, error) {
+	value = trimPadding(value)
+	b, err := encoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid base64url value", ErrMalformedResponse)
+	}
+	return b, nil
+}
+
+// EncodeBase64URL encodes a binary value the way the browser expects it
+func EncodeBase64URL(value []byte) string {
+	return encoding.EncodeToString(value)
+}
+
+func trimPadding(value string) string {
+	for len(value) > 0 && value[len(value)-1] == '=' {
+		value = value[:len(value)-1]
+	}
+	return value
+}
@@ -0,0 +1,361 @@
+package webauthn
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+const (
+	testRPID   = "lms.example.com"
+	testOrigin = "https://lms.example.com"
+)
+
+var testConfig = &Config{RPID: testRPID, RPName: "Stegmaier LMS", Origins: []string{testOrigin}}
+
+func TestDecodeCBOR(t *testing.T) {
+	// Examples from RFC 8949 Appendix A
+	tests := []struct {
+		hex      string
+		expected interface{}
+	}{
+		{"00", int64(0)},
+		{"17", int64(23)},
+		{"1818", int64(24)},
+		{"1903e8", int64(1000)},
+		{"20", int64(-1)},
+		{"3863", int64(-100)},
+		{"4401020304", []byte{1, 2, 3, 4}},
+		{"6449455446", "IETF"},
+		{"83010203", []interface{}{int64(1), int64(2), int64(3)}},
+		{"a201020304", map[interface{}]interface{}{int64(1): int64(2), int64(3): int64(4)}},
+		{"a161616141", map[interface{}]interface{}{"a": "A"}},
+		{"f4", false},
+		{"f5", true},
+		{"f6", nil},
+		{"f93c00", float64(1)},
+		{"fb3ff199999999999a", 1.1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.hex, func(t *testing.T) {
+			data, _ := hex.DecodeString(tt.hex)
+			value, n, err := decodeCBOR(data)
+			if err != nil {
+				t.Fatalf("decodeCBOR() error = %v", err)
+			}
+			if n != len(data) {
+				t.Errorf("decodeCBOR() used %d bytes, want %d", n, len(data))
+			}
+			if !reflect.DeepEqual(value, tt.expected) {
+				t.Errorf("decodeCBOR() = %#v, want %#v", value, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDecodeCBOR_Malformed(t *testing.T) {
+	tests := map[string]string{
+		"Truncated byte string": "4401",
+		"Indefinite length":     "5f42010243030405ff",
+		"Truncated map":         "a201",
+		"Huge array length":     "9bffffffffffffffff",
+		"Empty input":           "",
+	}
+
+	for name, h := range tests {
+		t.Run(name, func(t *testing.T) {
+			data, _ := hex.DecodeString(h)
+			if _, _, err := decodeCBOR(data); !errors.Is(err, ErrMalformedCBOR) {
+				t.Errorf("decodeCBOR() error = %v, want ErrMalformedCBOR", err)
+			}
+		})
+	}
+}
+
+func TestCeremonies(t *testing.T) {
+	for _, newAuthenticator := range []func(t *testing.T) *testAuthenticator{newES256Authenticator, newEd25519Authenticator} {
+		auth := newAuthenticator(t)
+
+		t.Run(auth.name, func(t *testing.T) {
+			challenge, err := GenerateChallenge()
+			if err != nil {
+				t.Fatalf("GenerateChallenge() error = %v", err)
+			}
+
+			clientData, attestation := auth.create(t, challenge, testOrigin, FlagUserPresent|FlagUserVerified)
+			credential, err := testConfig.VerifyRegistration(challenge, clientData, attestation, true)
+			if err != nil {
+				t.Fatalf("VerifyRegistration() error = %v", err)
+			}
+			if !bytes.Equal(credential.ID, auth.credentialID) {
+				t.Errorf("credential ID = %x, want %x", credential.ID, auth.credentialID)
+			}
+
+			challenge, _ = GenerateChallenge()
+			clientData, authData, signature := auth.get(t, challenge, testOrigin, FlagUserPresent|FlagUserVerified, 5)
+			signCount, err := testConfig.VerifyAssertion(challenge, credential.PublicKey, credential.SignCount, clientData, authData, signature, true)
+			if err != nil {
+				t.Fatalf("VerifyAssertion() error = %v", err)
+			}
+			if signCount != 5 {
+				t.Errorf("sign count = %d, want 5", signCount)
+			}
+		})
+	}
+}
+
+func TestVerifyRegistration_Rejected(t *testing.T) {
+	auth := newES256Authenticator(t)
+	challenge, _ := GenerateChallenge()
+
+	tests := []struct {
+		name      string
+		challenge string
+		origin    string
+		flags     byte
+		config    *Config
+		expected  error
+	}{
+		{"Wrong challenge", "other", testOrigin, FlagUserPresent | FlagUserVerified, testConfig, ErrChallengeMismatch},
+		{"Wrong origin", challenge, "https://evil.example.com", FlagUserPresent | FlagUserVerified, testConfig, ErrOriginMismatch},
+		{"Wrong RP ID", challenge, testOrigin, FlagUserPresent | FlagUserVerified, &Config{RPID: "other.example.com", Origins: []string{testOrigin}}, ErrRPIDMismatch},
+		{"User not present", challenge, testOrigin, FlagUserVerified, testConfig, ErrUserNotPresent},
+		{"User not verified", challenge, testOrigin, FlagUserPresent, testConfig, ErrUserNotVerified},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientData, attestation := auth.create(t, tt.challenge, tt.origin, tt.flags)
+			if _, err := tt.config.VerifyRegistration(challenge, clientData, attestation, true); !errors.Is(err, tt.expected) {
+				t.Errorf("VerifyRegistration() error = %v, want %v", err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestVerifyAssertion_Rejected(t *testing.T) {
+	auth := newES256Authenticator(t)
+	challenge, _ := GenerateChallenge()
+	clientData, attestation := auth.create(t, challenge, testOrigin, FlagUserPresent|FlagUserVerified)
+	credential, err := testConfig.VerifyRegistration(challenge, clientData, attestation, true)
+	if err != nil {
+		t.Fatalf("VerifyRegistration() error = %v", err)
+	}
+
+	t.Run("Tampered signature", func(t *testing.T) {
+		clientData, authData, signature := auth.get(t, challenge, testOrigin, FlagUserPresent, 1)
+		authData[36]++ // signature counter byte
+		if _, err := testConfig.VerifyAssertion(challenge, credential.PublicKey, 0, clientData, authData, signature, false); !errors.Is(err, ErrSignatureInvalid) {
+			t.Errorf("VerifyAssertion() error = %v, want ErrSignatureInvalid", err)
+		}
+	})
+
+	t.Run("Signature counter did not increase", func(t *testing.T) {
+		clientData, authData, signature := auth.get(t, challenge, testOrigin, FlagUserPresent, 3)
+		if _, err := testConfig.VerifyAssertion(challenge, credential.PublicKey, 3, clientData, authData, signature, false); !errors.Is(err, ErrSignCountInvalid) {
+			t.Errorf("VerifyAssertion() error = %v, want ErrSignCountInvalid", err)
+		}
+	})
+
+	t.Run("Authenticator without counter", func(t *testing.T) {
+		clientData, authData, signature := auth.get(t, challenge, testOrigin, FlagUserPresent, 0)
+		if _, err := testConfig.VerifyAssertion(challenge, credential.PublicKey, 0, clientData, authData, signature, false); err != nil {
+			t.Errorf("VerifyAssertion() error = %v", err)
+		}
+	})
+
+	t.Run("User verification required", func(t *testing.T) {
+		clientData, authData, signature := auth.get(t, challenge, testOrigin, FlagUserPresent, 4)
+		if _, err := testConfig.VerifyAssertion(challenge, credential.PublicKey, 0, clientData, authData, signature, true); !errors.Is(err, ErrUserNotVerified) {
+			t.Errorf("VerifyAssertion() error = %v, want ErrUserNotVerified", err)
+		}
+	})
+}
+
+func TestChallengeFromClientData(t *testing.T) {
+	clientData := clientDataJSON(t, ceremonyGet, "abc123", testOrigin)
+	challenge, err := ChallengeFromClientData(clientData)
+	if err != nil || challenge != "abc123" {
+		t.Errorf("ChallengeFromClientData() = %q, %v, want abc123", challenge, err)
+	}
+
+	if _, err := ChallengeFromClientData([]byte("not json")); !errors.Is(err, ErrMalformedResponse) {
+		t.Errorf("ChallengeFromClientData() error = %v, want ErrMalformedResponse", err)
+	}
+}
+
+// testAuthenticator simulates an authenticator holding a single credential
+type testAuthenticator struct {
+	name         string
+	credentialID []byte
+	coseKey      []byte
+	sign         func(message []byte) []byte
+}
+
+func newES256Authenticator(t *testing.T) *testAuthenticator {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	return &testAuthenticator{
+		name:         "ES256",
+		credentialID: randomBytes(t, 16),
+		coseKey: encodeCBORMap(
+			coseKeyType, coseKeyTypeEC2,
+			coseAlgorithm, AlgES256,
+			coseParam1, coseCurveP256,
+			coseParam2, key.X.FillBytes(make([]byte, 32)),
+			coseParam3, key.Y.FillBytes(make([]byte, 32)),
+		),
+		sign: func(message []byte) []byte {
+			digest := sha256.Sum256(message)
+			signature, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+			if err != nil {
+				t.Fatalf("failed to sign: %v", err)
+			}
+			return signature
+		},
+	}
+}
+
+func newEd25519Authenticator(t *testing.T) *testAuthenticator {
+	t.Helper()
+
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	return &testAuthenticator{
+		name:         "EdDSA",
+		credentialID: randomBytes(t, 32),
+		coseKey: encodeCBORMap(
+			coseKeyType, coseKeyTypeOKP,
+			coseAlgorithm, AlgEdDSA,
+			coseParam1, coseCurveEd25519,
+			coseParam2, []byte(public),
+		),
+		sign: func(message []byte) []byte {
+			signature, err := private.Sign(rand.Reader, message, crypto.Hash(0))
+			if err != nil {
+				t.Fatalf("failed to sign: %v", err)
+			}
+			return signature
+		},
+	}
+}
+
+// create returns the client data and attestation object of a registration
+func (a *testAuthenticator) create(t *testing.T, challenge string, origin string, flags byte) ([]byte, []byte) {
+	t.Helper()
+
+	authData := authenticatorDataBytes(flags|FlagAttestedCredentialData, 0)
+	authData = append(authData, make([]byte, 16)...) // AAGUID
+	authData = binary.BigEndian.AppendUint16(authData, uint16(len(a.credentialID)))
+	authData = append(authData, a.credentialID...)
+	authData = append(authData, a.coseKey...)
+
+	attestation := encodeCBORMap(
+		"fmt", "none",
+		"attStmt", cborRaw(encodeCBORMap()),
+		"authData", authData,
+	)
+
+	return clientDataJSON(t, ceremonyCreate, challenge, origin), attestation
+}
+
+// get returns the client data, authenticator data and signature of an assertion
+func (a *testAuthenticator) get(t *testing.T, challenge string, origin string, flags byte, signCount uint32) ([]byte, []byte, []byte) {
+	t.Helper()
+
+	clientData := clientDataJSON(t, ceremonyGet, challenge, origin)
+	authData := authenticatorDataBytes(flags, signCount)
+
+	clientDataHash := sha256.Sum256(clientData)
+	signature := a.sign(append(append([]byte{}, authData...), clientDataHash[:]...))
+
+	return clientData, authData, signature
+}
+
+func authenticatorDataBytes(flags byte, signCount uint32) []byte {
+	rpIDHash := sha256.Sum256([]byte(testRPID))
+	data := append(rpIDHash[:], flags)
+	return binary.BigEndian.AppendUint32(data, signCount)
+}
+
+func clientDataJSON(t *testing.T, ceremony string, challenge string, origin string) []byte {
+	t.Helper()
+
+	data, err := json.Marshal(collectedClientData{Type: ceremony, Challenge: challenge, Origin: origin})
+	if err != nil {
+		t.Fatalf("failed to encode client data: %v", err)
+	}
+	return data
+}
+
+func randomBytes(t *testing.T, n int) []byte {
+	t.Helper()
+
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		t.Fatalf("failed to generate random bytes: %v", err)
+	}
+	return b
+}
+
+// cborRaw is an already encoded CBOR item
+type cborRaw []byte
+
+// encodeCBORMap encodes alternating keys and values as a CBOR map
+func encodeCBORMap(pairs ...interface{}) []byte {
+	out := cborHead(5, uint64(len(pairs)/2))
+	for _, item := range pairs {
+		out = append(out, encodeCBORItem(item)...)
+	}
+	return out
+}
+
+func encodeCBORItem(item interface{}) []byte {
+	switch v := item.(type) {
+	case cborRaw:
+		return v
+	case int64:
+		if v < 0 {
+			return cborHead(1, uint64(-1-v))
+		}
+		return cborHead(0, uint64(v))
+	case []byte:
+		return append(cborHead(2, uint64(len(v))), v...)
+	case string:
+		return append(cborHead(3, uint64(len(v))), v...)
+	default:
+		panic("unsupported CBOR test value")
+	}
+}
+
+func cborHead(major byte, arg uint64) []byte {
+	switch {
+	case arg < 24:
+		return []byte{major<<5 | byte(arg)}
+	case arg <= 0xff:
+		return []byte{major<<5 | 24, byte(arg)}
+	case arg <= 0xffff:
+		return binary.BigEndian.AppendUint16([]byte{major<<5 | 25}, uint16(arg))
+	default:
+		return binary.BigEndian.AppendUint32([]byte{major<<5 | 26}, uint32(arg))
+	}
+}
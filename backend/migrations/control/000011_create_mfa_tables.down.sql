@@ -0,0 +1,14 @@
+-- Rollback migration: Drop multi-factor authentication tables
+
+ALTER TABLE tenants
+DROP COLUMN IF EXISTS mfa_required_roles;
+
+DROP TRIGGER IF EXISTS update_user_mfa_updated_at ON user_mfa;
+
+DROP INDEX IF EXISTS idx_mfa_challenges_expires_at;
+DROP INDEX IF EXISTS idx_mfa_challenges_user_id;
+DROP INDEX IF EXISTS idx_mfa_recovery_codes_user_id;
+
+DROP TABLE IF EXISTS mfa_challenges;
+DROP TABLE IF EXISTS mfa_recovery_codes;
+DROP TABLE IF EXISTS user_mfa;
@@ -0,0 +1,56 @@
+-- Migration: Create multi-factor authentication tables
+-- Description: Adds TOTP enrollment, single-use recovery codes, login MFA challenges
+-- and a per-tenant policy listing the roles that must use MFA
+
+-- TOTP enrollment per user
+CREATE TABLE IF NOT EXISTS user_mfa (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    secret VARCHAR(64) NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT false,
+    last_used_step BIGINT NOT NULL DEFAULT 0,
+    enabled_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Single-use recovery codes (stored as SHA-256 hashes)
+CREATE TABLE IF NOT EXISTS mfa_recovery_codes (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash VARCHAR(64) NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(user_id, code_hash)
+);
+
+-- Short-lived challenges issued by the first login step
+CREATE TABLE IF NOT EXISTS mfa_challenges (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token VARCHAR(255) NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(token)
+);
+
+-- Roles that must use MFA within a tenant
+ALTER TABLE tenants
+ADD COLUMN IF NOT EXISTS mfa_required_roles TEXT[] NOT NULL DEFAULT '{}';
+
+-- Create indexes for performance
+CREATE INDEX IF NOT EXISTS idx_mfa_recovery_codes_user_id ON mfa_recovery_codes(user_id);
+CREATE INDEX IF NOT EXISTS idx_mfa_challenges_user_id ON mfa_challenges(user_id);
+CREATE INDEX IF NOT EXISTS idx_mfa_challenges_expires_at ON mfa_challenges(expires_at);
+
+CREATE TRIGGER update_user_mfa_updated_at
+    BEFORE UPDATE ON user_mfa
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Add comments for documentation
+COMMENT ON TABLE user_mfa IS 'Stores TOTP secrets and enrollment state per user';
+COMMENT ON TABLE mfa_recovery_codes IS 'Stores hashed single-use MFA recovery codes';
+COMMENT ON TABLE mfa_challenges IS 'Stores short-lived tokens for the second login step';
+COMMENT ON COLUMN user_mfa.last_used_step IS 'Last accepted TOTP time step, used to reject replayed codes';
+COMMENT ON COLUMN tenants.mfa_required_roles IS 'Membership roles that must complete MFA to log in (e.g. admin, instructor)';
@@ -0,0 +1,8 @@
+-- Rollback migration: Remove refresh token families
+
+DROP INDEX IF EXISTS idx_refresh_tokens_parent_id;
+DROP INDEX IF EXISTS idx_refresh_tokens_family_id;
+
+ALTER TABLE refresh_tokens
+DROP COLUMN IF EXISTS parent_id,
+DROP COLUMN IF EXISTS family_id;
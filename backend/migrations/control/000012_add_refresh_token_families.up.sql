@@ -0,0 +1,21 @@
+-- Migration: Add refresh token families for rotation and reuse detection
+-- Description: Every refresh rotates the token; rotated tokens point to their parent
+-- and share a family so a replayed token can revoke the whole chain
+
+ALTER TABLE refresh_tokens
+ADD COLUMN IF NOT EXISTS family_id UUID,
+ADD COLUMN IF NOT EXISTS parent_id UUID REFERENCES refresh_tokens(id) ON DELETE SET NULL;
+
+-- Existing tokens start their own family
+UPDATE refresh_tokens SET family_id = id WHERE family_id IS NULL;
+
+ALTER TABLE refresh_tokens
+ALTER COLUMN family_id SET NOT NULL;
+
+-- Create indexes for performance
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_family_id ON refresh_tokens(family_id);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_parent_id ON refresh_tokens(parent_id);
+
+-- Add comments for documentation
+COMMENT ON COLUMN refresh_tokens.family_id IS 'Identifier shared by all tokens rotated from the same login';
+COMMENT ON COLUMN refresh_tokens.parent_id IS 'Token that was rotated to issue this one (NULL for the first token of a family)';
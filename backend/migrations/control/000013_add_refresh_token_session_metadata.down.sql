@@ -0,0 +1,8 @@
+-- Rollback migration: Remove session metadata from refresh tokens
+
+DROP INDEX IF EXISTS idx_refresh_tokens_user_active;
+
+ALTER TABLE refresh_tokens
+DROP COLUMN IF EXISTS last_used_at,
+DROP COLUMN IF EXISTS ip_address,
+DROP COLUMN IF EXISTS user_agent;
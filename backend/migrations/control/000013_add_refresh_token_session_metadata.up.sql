@@ -0,0 +1,19 @@
+-- Migration: Add session metadata to refresh tokens
+-- Description: Stores the device (user agent, IP) and last use of each refresh token
+-- so users can list and revoke individual sessions. A session is a token family.
+
+ALTER TABLE refresh_tokens
+ADD COLUMN IF NOT EXISTS user_agent TEXT NOT NULL DEFAULT '',
+ADD COLUMN IF NOT EXISTS ip_address VARCHAR(45) NOT NULL DEFAULT '',
+ADD COLUMN IF NOT EXISTS last_used_at TIMESTAMP WITH TIME ZONE;
+
+-- Existing tokens were last used when they were issued
+UPDATE refresh_tokens SET last_used_at = created_at WHERE last_used_at IS NULL;
+
+-- Fast lookup of active sessions per user
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_user_active ON refresh_tokens(user_id, family_id) WHERE revoked_at IS NULL;
+
+-- Add comments for documentation
+COMMENT ON COLUMN refresh_tokens.user_agent IS 'User agent of the client the token was issued to';
+COMMENT ON COLUMN refresh_tokens.ip_address IS 'IP address of the client the token was issued to';
+COMMENT ON COLUMN refresh_tokens.last_used_at IS 'When the session was last used to log in or refresh';
@@ -0,0 +1,10 @@
+-- Rollback migration: Drop OpenID Connect tables
+
+DROP TRIGGER IF EXISTS update_tenant_oidc_providers_updated_at ON tenant_oidc_providers;
+
+DROP INDEX IF EXISTS idx_user_identities_user_id;
+DROP INDEX IF EXISTS idx_oidc_auth_requests_expires_at;
+
+DROP TABLE IF EXISTS user_identities;
+DROP TABLE IF EXISTS oidc_auth_requests;
+DROP TABLE IF EXISTS tenant_oidc_providers;
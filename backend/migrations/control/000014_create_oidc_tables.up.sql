@@ -0,0 +1,63 @@
+-- Migration: Create OpenID Connect tables
+-- Description: Adds per-tenant OIDC identity provider settings, pending authorization
+-- requests (state, nonce and PKCE verifier) and the links between users and external identities
+
+-- Identity provider configuration per tenant
+CREATE TABLE IF NOT EXISTS tenant_oidc_providers (
+    tenant_id UUID PRIMARY KEY REFERENCES tenants(id) ON DELETE CASCADE,
+    issuer VARCHAR(500) NOT NULL,
+    client_id VARCHAR(255) NOT NULL,
+    client_secret TEXT NOT NULL,
+    redirect_uri VARCHAR(500) NOT NULL,
+    scopes TEXT[] NOT NULL DEFAULT '{openid,email,profile}',
+    role_claim VARCHAR(100) NOT NULL DEFAULT '',
+    role_mapping JSONB NOT NULL DEFAULT '{}'::jsonb,
+    default_role VARCHAR(50) NOT NULL DEFAULT 'student',
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_oidc_default_role CHECK (default_role IN ('admin', 'instructor', 'student'))
+);
+
+-- Authorization requests waiting for the provider callback
+CREATE TABLE IF NOT EXISTS oidc_auth_requests (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    state VARCHAR(255) NOT NULL,
+    nonce VARCHAR(255) NOT NULL,
+    code_verifier VARCHAR(255) NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(state)
+);
+
+-- External identities linked to local users
+CREATE TABLE IF NOT EXISTS user_identities (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    issuer VARCHAR(500) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    email VARCHAR(255) NOT NULL DEFAULT '',
+    last_login_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(issuer, subject)
+);
+
+-- Create indexes for performance
+CREATE INDEX IF NOT EXISTS idx_oidc_auth_requests_expires_at ON oidc_auth_requests(expires_at);
+CREATE INDEX IF NOT EXISTS idx_user_identities_user_id ON user_identities(user_id);
+
+CREATE TRIGGER update_tenant_oidc_providers_updated_at
+    BEFORE UPDATE ON tenant_oidc_providers
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Add comments for documentation
+COMMENT ON TABLE tenant_oidc_providers IS 'Stores the OpenID Connect identity provider of each tenant';
+COMMENT ON TABLE oidc_auth_requests IS 'Stores short-lived OIDC authorization requests until the provider callback';
+COMMENT ON TABLE user_identities IS 'Links users to their subject at an external identity provider';
+COMMENT ON COLUMN tenant_oidc_providers.role_claim IS 'ID token claim holding the user groups or roles (e.g. groups)';
+COMMENT ON COLUMN tenant_oidc_providers.role_mapping IS 'Maps claim values to membership roles, e.g. {"lms-admins": "admin"}';
+COMMENT ON COLUMN tenant_oidc_providers.default_role IS 'Membership role used when no claim value is mapped';
+COMMENT ON COLUMN oidc_auth_requests.code_verifier IS 'PKCE code verifier sent with the token request';
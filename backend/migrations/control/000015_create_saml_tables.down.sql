@@ -0,0 +1,10 @@
+-- Rollback migration: Drop SAML tables
+
+DROP TRIGGER IF EXISTS update_tenant_saml_providers_updated_at ON tenant_saml_providers;
+
+DROP INDEX IF EXISTS idx_saml_login_codes_expires_at;
+DROP INDEX IF EXISTS idx_saml_auth_requests_expires_at;
+
+DROP TABLE IF EXISTS saml_login_codes;
+DROP TABLE IF EXISTS saml_auth_requests;
+DROP TABLE IF EXISTS tenant_saml_providers;
@@ -0,0 +1,58 @@
+-- Migration: Create SAML tables
+-- Description: Adds per-tenant SAML identity provider settings, pending SP-initiated
+-- authentication requests and the one-time codes that hand a SAML login over to the frontend
+
+-- Identity provider configuration per tenant
+CREATE TABLE IF NOT EXISTS tenant_saml_providers (
+    tenant_id UUID PRIMARY KEY REFERENCES tenants(id) ON DELETE CASCADE,
+    idp_entity_id VARCHAR(500) NOT NULL,
+    idp_sso_url VARCHAR(500) NOT NULL,
+    idp_certificate TEXT NOT NULL,
+    email_attribute VARCHAR(255) NOT NULL DEFAULT '',
+    name_attribute VARCHAR(255) NOT NULL DEFAULT '',
+    role_attribute VARCHAR(255) NOT NULL DEFAULT '',
+    role_mapping JSONB NOT NULL DEFAULT '{}'::jsonb,
+    default_role VARCHAR(50) NOT NULL DEFAULT 'student',
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_saml_default_role CHECK (default_role IN ('admin', 'instructor', 'student'))
+);
+
+-- AuthnRequests waiting for the identity provider response
+CREATE TABLE IF NOT EXISTS saml_auth_requests (
+    id VARCHAR(100) PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- One-time codes exchanged by the frontend for our tokens after the ACS redirect
+CREATE TABLE IF NOT EXISTS saml_login_codes (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    code VARCHAR(255) NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(code)
+);
+
+-- Create indexes for performance
+CREATE INDEX IF NOT EXISTS idx_saml_auth_requests_expires_at ON saml_auth_requests(expires_at);
+CREATE INDEX IF NOT EXISTS idx_saml_login_codes_expires_at ON saml_login_codes(expires_at);
+
+CREATE TRIGGER update_tenant_saml_providers_updated_at
+    BEFORE UPDATE ON tenant_saml_providers
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Add comments for documentation
+COMMENT ON TABLE tenant_saml_providers IS 'Stores the SAML 2.0 identity provider of each tenant';
+COMMENT ON TABLE saml_auth_requests IS 'Stores short-lived SAML AuthnRequest IDs until the response is received';
+COMMENT ON TABLE saml_login_codes IS 'Stores single-use codes that exchange a SAML login for tokens';
+COMMENT ON COLUMN tenant_saml_providers.idp_certificate IS 'PEM encoded certificates trusted to sign responses';
+COMMENT ON COLUMN tenant_saml_providers.email_attribute IS 'Attribute holding the user email, empty to use the NameID';
+COMMENT ON COLUMN tenant_saml_providers.role_attribute IS 'Attribute holding the user groups or roles';
+COMMENT ON COLUMN tenant_saml_providers.role_mapping IS 'Maps attribute values to membership roles, e.g. {"lms-admins": "admin"}';
+COMMENT ON COLUMN saml_auth_requests.id IS 'AuthnRequest ID expected in the InResponseTo of the response';
@@ -0,0 +1,6 @@
+-- Rollback migration: Drop API tokens table
+
+DROP INDEX IF EXISTS idx_api_tokens_tenant_id;
+DROP INDEX IF EXISTS idx_api_tokens_user_id;
+
+DROP TABLE IF EXISTS api_tokens;
@@ -0,0 +1,33 @@
+-- Migration: Create API tokens table
+-- Description: Adds personal access tokens and tenant API keys for integrations.
+-- Tokens are stored as SHA-256 hashes and carry resource scopes (e.g. courses:read)
+
+CREATE TABLE IF NOT EXISTS api_tokens (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    type VARCHAR(20) NOT NULL,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL,
+    token_prefix VARCHAR(20) NOT NULL,
+    scopes TEXT[] NOT NULL DEFAULT '{}',
+    expires_at TIMESTAMP WITH TIME ZONE,
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    last_used_ip VARCHAR(45),
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(token_hash),
+    CONSTRAINT chk_api_token_type CHECK (type IN ('personal', 'tenant'))
+);
+
+-- Create indexes for performance
+CREATE INDEX IF NOT EXISTS idx_api_tokens_user_id ON api_tokens(user_id) WHERE type = 'personal';
+CREATE INDEX IF NOT EXISTS idx_api_tokens_tenant_id ON api_tokens(tenant_id) WHERE type = 'tenant';
+
+-- Add comments for documentation
+COMMENT ON TABLE api_tokens IS 'Stores personal access tokens and tenant API keys';
+COMMENT ON COLUMN api_tokens.type IS 'personal: acts as its owner; tenant: API key managed by the tenant admins';
+COMMENT ON COLUMN api_tokens.user_id IS 'Owner of a personal access token or creator of an API key';
+COMMENT ON COLUMN api_tokens.token_hash IS 'Hex encoded SHA-256 hash of the token, the plaintext is never stored';
+COMMENT ON COLUMN api_tokens.token_prefix IS 'First characters of the token so users can recognize it';
+COMMENT ON COLUMN api_tokens.scopes IS 'Granted scopes as resource:action, write also grants read';
@@ -0,0 +1,12 @@
+-- Rollback migration: Drop password policy tables
+
+DROP TRIGGER IF EXISTS update_tenant_password_policies_updated_at ON tenant_password_policies;
+
+DROP INDEX IF EXISTS idx_password_history_user_id;
+
+ALTER TABLE users
+DROP COLUMN IF EXISTS must_change_password,
+DROP COLUMN IF EXISTS password_changed_at;
+
+DROP TABLE IF EXISTS password_history;
+DROP TABLE IF EXISTS tenant_password_policies;
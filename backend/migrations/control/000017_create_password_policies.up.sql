@@ -0,0 +1,54 @@
+-- Migration: Create password policy tables
+-- Description: Adds per-tenant password policies, the history of password hashes used
+-- to prevent reuse, and the password age tracking used to force password changes
+
+-- Password policy per tenant (tenants without a row use the default policy)
+CREATE TABLE IF NOT EXISTS tenant_password_policies (
+    tenant_id UUID PRIMARY KEY REFERENCES tenants(id) ON DELETE CASCADE,
+    min_length INTEGER NOT NULL DEFAULT 8,
+    require_uppercase BOOLEAN NOT NULL DEFAULT false,
+    require_lowercase BOOLEAN NOT NULL DEFAULT false,
+    require_digit BOOLEAN NOT NULL DEFAULT false,
+    require_symbol BOOLEAN NOT NULL DEFAULT false,
+    history_size INTEGER NOT NULL DEFAULT 0,
+    max_age_days INTEGER NOT NULL DEFAULT 0,
+    check_breached BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_password_policy_min_length CHECK (min_length BETWEEN 8 AND 72),
+    CONSTRAINT chk_password_policy_history_size CHECK (history_size BETWEEN 0 AND 24),
+    CONSTRAINT chk_password_policy_max_age_days CHECK (max_age_days >= 0)
+);
+
+-- Previous password hashes of each user
+CREATE TABLE IF NOT EXISTS password_history (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    password_hash VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Password age tracking
+ALTER TABLE users
+ADD COLUMN IF NOT EXISTS password_changed_at TIMESTAMP WITH TIME ZONE,
+ADD COLUMN IF NOT EXISTS must_change_password BOOLEAN NOT NULL DEFAULT false;
+
+-- Existing passwords are considered set at the last account update
+UPDATE users SET password_changed_at = updated_at WHERE password_changed_at IS NULL;
+
+-- Create indexes for performance
+CREATE INDEX IF NOT EXISTS idx_password_history_user_id ON password_history(user_id, created_at DESC);
+
+CREATE TRIGGER update_tenant_password_policies_updated_at
+    BEFORE UPDATE ON tenant_password_policies
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Add comments for documentation
+COMMENT ON TABLE tenant_password_policies IS 'Stores the password requirements of each tenant';
+COMMENT ON TABLE password_history IS 'Stores the hashes of previous passwords to prevent reuse';
+COMMENT ON COLUMN tenant_password_policies.history_size IS 'Number of previous passwords that cannot be reused (0 disables the check)';
+COMMENT ON COLUMN tenant_password_policies.max_age_days IS 'Days after which a password must be changed (0 disables expiry)';
+COMMENT ON COLUMN tenant_password_policies.check_breached IS 'Reject passwords found in the breached password dataset';
+COMMENT ON COLUMN users.password_changed_at IS 'When the current password was set';
+COMMENT ON COLUMN users.must_change_password IS 'Whether the user must change the password before using the platform';
@@ -0,0 +1,7 @@
+-- Rollback migration: Drop WebAuthn tables
+
+DROP INDEX IF EXISTS idx_webauthn_challenges_expires_at;
+DROP INDEX IF EXISTS idx_webauthn_credentials_user_id;
+
+DROP TABLE IF EXISTS webauthn_challenges;
+DROP TABLE IF EXISTS webauthn_credentials;
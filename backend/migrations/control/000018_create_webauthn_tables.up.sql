@@ -0,0 +1,44 @@
+-- Migration: Create WebAuthn tables
+-- Description: Adds the passkeys and security keys registered by users and the pending
+-- registration and authentication ceremonies (challenges)
+
+-- Public key credentials registered by users
+CREATE TABLE IF NOT EXISTS webauthn_credentials (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    credential_id VARCHAR(1400) NOT NULL,
+    public_key BYTEA NOT NULL,
+    sign_count BIGINT NOT NULL DEFAULT 0,
+    aaguid VARCHAR(36) NOT NULL DEFAULT '',
+    transports TEXT[] NOT NULL DEFAULT '{}',
+    backup_eligible BOOLEAN NOT NULL DEFAULT false,
+    name VARCHAR(100) NOT NULL,
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(credential_id)
+);
+
+-- Ceremonies waiting for the browser response
+CREATE TABLE IF NOT EXISTS webauthn_challenges (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    challenge VARCHAR(255) NOT NULL,
+    ceremony VARCHAR(20) NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(challenge),
+    CONSTRAINT chk_webauthn_ceremony CHECK (ceremony IN ('registration', 'login', 'mfa'))
+);
+
+-- Create indexes for performance
+CREATE INDEX IF NOT EXISTS idx_webauthn_credentials_user_id ON webauthn_credentials(user_id);
+CREATE INDEX IF NOT EXISTS idx_webauthn_challenges_expires_at ON webauthn_challenges(expires_at);
+
+-- Add comments for documentation
+COMMENT ON TABLE webauthn_credentials IS 'Stores the passkeys and security keys used to sign in or as a second factor';
+COMMENT ON TABLE webauthn_challenges IS 'Stores short-lived WebAuthn challenges until the browser response';
+COMMENT ON COLUMN webauthn_credentials.credential_id IS 'Credential ID assigned by the authenticator (base64url)';
+COMMENT ON COLUMN webauthn_credentials.public_key IS 'Credential public key in COSE_Key format';
+COMMENT ON COLUMN webauthn_credentials.sign_count IS 'Last signature counter, used to detect cloned authenticators';
+COMMENT ON COLUMN webauthn_credentials.backup_eligible IS 'Whether the credential is a synced passkey rather than a device-bound key';
+COMMENT ON COLUMN webauthn_challenges.user_id IS 'User the ceremony is for; NULL for usernameless passkey logins';
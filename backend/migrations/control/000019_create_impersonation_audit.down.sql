@@ -0,0 +1,7 @@
+-- Rollback migration: Drop impersonation audit tables
+
+DROP INDEX IF EXISTS idx_impersonation_events_session_id;
+DROP INDEX IF EXISTS idx_impersonation_sessions_tenant_id;
+
+DROP TABLE IF EXISTS impersonation_events;
+DROP TABLE IF EXISTS impersonation_sessions;
@@ -0,0 +1,42 @@
+-- Migration: Create impersonation audit tables
+-- Description: Adds the sessions in which an admin acts as another user of the tenant and
+-- the audit trail of their start, stop and every request made while impersonating
+
+CREATE TABLE IF NOT EXISTS impersonation_sessions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    impersonator_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    target_user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    reason VARCHAR(500) NOT NULL,
+    token_id VARCHAR(64) NOT NULL,
+    ip_address VARCHAR(45) NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    ended_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(token_id)
+);
+
+CREATE TABLE IF NOT EXISTS impersonation_events (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    session_id UUID NOT NULL REFERENCES impersonation_sessions(id) ON DELETE CASCADE,
+    type VARCHAR(20) NOT NULL,
+    method VARCHAR(10) NOT NULL DEFAULT '',
+    path TEXT NOT NULL DEFAULT '',
+    status_code INTEGER NOT NULL DEFAULT 0,
+    ip_address VARCHAR(45) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_impersonation_event_type CHECK (type IN ('start', 'stop', 'request'))
+);
+
+-- Create indexes for performance
+CREATE INDEX IF NOT EXISTS idx_impersonation_sessions_tenant_id ON impersonation_sessions(tenant_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_impersonation_events_session_id ON impersonation_events(session_id, created_at);
+
+-- Add comments for documentation
+COMMENT ON TABLE impersonation_sessions IS 'Stores the sessions in which an admin acted as another user';
+COMMENT ON TABLE impersonation_events IS 'Audit trail of impersonation sessions: start, stop and each request';
+COMMENT ON COLUMN impersonation_sessions.token_id IS 'JWT ID (jti) of the time-boxed access token issued for the session';
+COMMENT ON COLUMN impersonation_sessions.ended_at IS 'Set when the session is stopped; the token is rejected from then on';
+COMMENT ON COLUMN impersonation_events.status_code IS 'Response status of a request event, 0 for start and stop';
+COMMENT ON COLUMN impersonation_sessions.impersonator_id IS 'Kept as NULL when the user is deleted so the audit trail survives';
@@ -0,0 +1,9 @@
+-- Rollback migration: Drop magic link tokens
+
+ALTER TABLE tenants
+DROP COLUMN IF EXISTS magic_link_enabled;
+
+DROP INDEX IF EXISTS idx_magic_link_tokens_expires_at;
+DROP INDEX IF EXISTS idx_magic_link_tokens_user_id;
+
+DROP TABLE IF EXISTS magic_link_tokens;
@@ -0,0 +1,27 @@
+-- Migration: Create magic link tokens
+-- Description: Adds single-use login links sent by email and a per-tenant switch
+-- enabling passwordless login with them
+
+-- Short-lived login tokens, consumed when the link is redeemed
+CREATE TABLE IF NOT EXISTS magic_link_tokens (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    token VARCHAR(255) NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(token)
+);
+
+-- Tenants opt in to magic link login
+ALTER TABLE tenants
+ADD COLUMN IF NOT EXISTS magic_link_enabled BOOLEAN NOT NULL DEFAULT false;
+
+-- Create indexes for performance
+CREATE INDEX IF NOT EXISTS idx_magic_link_tokens_user_id ON magic_link_tokens(user_id);
+CREATE INDEX IF NOT EXISTS idx_magic_link_tokens_expires_at ON magic_link_tokens(expires_at);
+
+-- Add comments for documentation
+COMMENT ON TABLE magic_link_tokens IS 'Stores single-use tokens of the login links sent by email';
+COMMENT ON COLUMN magic_link_tokens.tenant_id IS 'Tenant the link was requested for; the link stops working if the tenant disables magic links';
+COMMENT ON COLUMN tenants.magic_link_enabled IS 'Whether members can log in with a link sent by email instead of their password';
@@ -0,0 +1,12 @@
+-- Rollback migration: Drop tenant roles
+
+DROP INDEX IF EXISTS idx_memberships_custom_role_id;
+
+ALTER TABLE tenant_memberships
+DROP COLUMN IF EXISTS custom_role_id;
+
+DROP TRIGGER IF EXISTS update_tenant_roles_updated_at ON tenant_roles;
+
+DROP INDEX IF EXISTS idx_tenant_roles_tenant_name;
+
+DROP TABLE IF EXISTS tenant_roles;
@@ -0,0 +1,32 @@
+-- Migration: Create tenant roles
+-- Description: Adds custom roles defined per tenant with the permissions they grant,
+-- and lets a custom role be assigned to a tenant membership
+
+-- Custom roles of a tenant (e.g. teaching assistant, reporting viewer)
+CREATE TABLE IF NOT EXISTS tenant_roles (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    name VARCHAR(50) NOT NULL,
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    permissions TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Members keep their built-in role and gain the permissions of their custom role
+ALTER TABLE tenant_memberships
+ADD COLUMN IF NOT EXISTS custom_role_id UUID REFERENCES tenant_roles(id) ON DELETE SET NULL;
+
+-- Create indexes for performance
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tenant_roles_tenant_name ON tenant_roles(tenant_id, LOWER(name));
+CREATE INDEX IF NOT EXISTS idx_memberships_custom_role_id ON tenant_memberships(custom_role_id);
+
+CREATE TRIGGER update_tenant_roles_updated_at
+    BEFORE UPDATE ON tenant_roles
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Add comments for documentation
+COMMENT ON TABLE tenant_roles IS 'Stores the custom roles defined by each tenant';
+COMMENT ON COLUMN tenant_roles.permissions IS 'Permissions of the catalog granted by the role (e.g. submission.grade, analytics.view)';
+COMMENT ON COLUMN tenant_memberships.custom_role_id IS 'Custom role adding permissions to the membership role (NULL if none)';
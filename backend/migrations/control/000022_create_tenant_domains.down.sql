@@ -0,0 +1,8 @@
+-- Rollback migration: Drop tenant domains
+
+DROP TRIGGER IF EXISTS update_tenant_domains_updated_at ON tenant_domains;
+
+DROP INDEX IF EXISTS idx_tenant_domains_verified_domain;
+DROP INDEX IF EXISTS idx_tenant_domains_tenant_domain;
+
+DROP TABLE IF EXISTS tenant_domains;
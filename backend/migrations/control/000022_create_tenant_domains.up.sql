@@ -0,0 +1,29 @@
+-- Migration: Create tenant domains
+-- Description: Adds the custom domains of each tenant (e.g. academy.customer.com). A domain
+-- identifies its tenant once the owner proves control of it with a DNS TXT record
+
+-- Custom domains of a tenant
+CREATE TABLE IF NOT EXISTS tenant_domains (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    domain VARCHAR(253) NOT NULL,
+    verification_token VARCHAR(64) NOT NULL,
+    verified_at TIMESTAMP WITH TIME ZONE,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Several tenants may claim a domain, but only the first one to verify it gets it
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tenant_domains_tenant_domain ON tenant_domains(tenant_id, domain);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tenant_domains_verified_domain ON tenant_domains(domain) WHERE verified_at IS NOT NULL;
+
+CREATE TRIGGER update_tenant_domains_updated_at
+    BEFORE UPDATE ON tenant_domains
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Add comments for documentation
+COMMENT ON TABLE tenant_domains IS 'Stores the custom domains that identify each tenant';
+COMMENT ON COLUMN tenant_domains.verification_token IS 'Value expected in the _stegmaier-verification TXT record of the domain';
+COMMENT ON COLUMN tenant_domains.verified_at IS 'When the TXT record was found (NULL until verified, only verified domains resolve)';
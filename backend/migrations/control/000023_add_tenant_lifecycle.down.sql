@@ -0,0 +1,16 @@
+-- Rollback migration: Remove tenant lifecycle
+
+DROP INDEX IF EXISTS idx_tenants_deletion_scheduled_at;
+
+-- Tenants pending deletion stay unavailable
+UPDATE tenants SET status = 'suspended' WHERE status = 'pending_deletion';
+
+ALTER TABLE tenants DROP CONSTRAINT IF EXISTS tenants_status_check;
+ALTER TABLE tenants ADD CONSTRAINT tenants_status_check
+    CHECK (status IN ('active', 'inactive', 'suspended', 'deleted'));
+
+ALTER TABLE tenants
+DROP COLUMN IF EXISTS status_reason,
+DROP COLUMN IF EXISTS status_changed_at,
+DROP COLUMN IF EXISTS deletion_scheduled_at,
+DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,24 @@
+-- Migration: Add tenant lifecycle
+-- Description: Adds the pending_deletion status and the columns that track suspensions and
+-- scheduled deletions. Tenants pending deletion can be reactivated until their grace period
+-- ends, then their database is dropped and the row is kept as a tombstone with status deleted
+
+ALTER TABLE tenants DROP CONSTRAINT IF EXISTS tenants_status_check;
+ALTER TABLE tenants ADD CONSTRAINT tenants_status_check
+    CHECK (status IN ('active', 'inactive', 'suspended', 'pending_deletion', 'deleted'));
+
+ALTER TABLE tenants
+ADD COLUMN IF NOT EXISTS status_reason TEXT,
+ADD COLUMN IF NOT EXISTS status_changed_at TIMESTAMP WITH TIME ZONE,
+ADD COLUMN IF NOT EXISTS deletion_scheduled_at TIMESTAMP WITH TIME ZONE,
+ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+
+-- Create indexes for performance
+CREATE INDEX IF NOT EXISTS idx_tenants_deletion_scheduled_at ON tenants(deletion_scheduled_at)
+    WHERE status = 'pending_deletion';
+
+-- Add comments for documentation
+COMMENT ON COLUMN tenants.status_reason IS 'Why the tenant was suspended or scheduled for deletion';
+COMMENT ON COLUMN tenants.status_changed_at IS 'When the status of the tenant last changed';
+COMMENT ON COLUMN tenants.deletion_scheduled_at IS 'When the database of a tenant pending deletion is dropped';
+COMMENT ON COLUMN tenants.deleted_at IS 'When the database of the tenant was dropped';
@@ -0,0 +1,8 @@
+-- Rollback migration: Drop tenant clone jobs
+
+DROP TRIGGER IF EXISTS update_tenant_clone_jobs_updated_at ON tenant_clone_jobs;
+
+DROP INDEX IF EXISTS idx_tenant_clone_jobs_running;
+DROP INDEX IF EXISTS idx_tenant_clone_jobs_source;
+
+DROP TABLE IF EXISTS tenant_clone_jobs;
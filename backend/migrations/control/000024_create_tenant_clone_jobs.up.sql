@@ -0,0 +1,40 @@
+-- Migration: Create tenant clone jobs
+-- Description: Tracks the background jobs that copy the course content of a tenant into a new
+-- tenant (e.g. a sandbox to try course changes), with their progress
+
+CREATE TABLE IF NOT EXISTS tenant_clone_jobs (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    source_tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    target_tenant_id UUID REFERENCES tenants(id) ON DELETE SET NULL,
+    target_name VARCHAR(100) NOT NULL,
+    target_slug VARCHAR(50) NOT NULL,
+    learner_data VARCHAR(20) NOT NULL DEFAULT 'exclude',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    current_step VARCHAR(100),
+    tables_total INTEGER NOT NULL DEFAULT 0,
+    tables_done INTEGER NOT NULL DEFAULT 0,
+    rows_copied BIGINT NOT NULL DEFAULT 0,
+    files_copied INTEGER NOT NULL DEFAULT 0,
+    error TEXT,
+    requested_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    started_at TIMESTAMP WITH TIME ZONE,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT tenant_clone_jobs_status_check CHECK (status IN ('pending', 'running', 'completed', 'failed')),
+    CONSTRAINT tenant_clone_jobs_learner_data_check CHECK (learner_data IN ('exclude', 'anonymize'))
+);
+
+-- Create indexes for performance
+CREATE INDEX IF NOT EXISTS idx_tenant_clone_jobs_source ON tenant_clone_jobs(source_tenant_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_tenant_clone_jobs_running ON tenant_clone_jobs(status) WHERE status IN ('pending', 'running');
+
+CREATE TRIGGER update_tenant_clone_jobs_updated_at
+    BEFORE UPDATE ON tenant_clone_jobs
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Add comments for documentation
+COMMENT ON TABLE tenant_clone_jobs IS 'Stores the jobs that clone the course content of a tenant into a new tenant';
+COMMENT ON COLUMN tenant_clone_jobs.learner_data IS 'What happens to enrollments, progress, attempts and submissions: exclude or anonymize';
+COMMENT ON COLUMN tenant_clone_jobs.current_step IS 'What the job is doing (e.g. the table being copied)';
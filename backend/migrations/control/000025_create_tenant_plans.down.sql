@@ -0,0 +1,8 @@
+-- Rollback migration: Drop tenant plans
+
+DROP INDEX IF EXISTS idx_tenants_plan_id;
+
+ALTER TABLE tenants DROP COLUMN IF EXISTS plan_id;
+
+DROP TRIGGER IF EXISTS update_tenant_plans_updated_at ON tenant_plans;
+DROP TABLE IF EXISTS tenant_plans;
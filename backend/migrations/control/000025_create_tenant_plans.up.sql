@@ -0,0 +1,41 @@
+-- Migration: Create tenant plans
+-- Description: Plans define the limits of tenants (active learners, courses, media storage) and
+-- the features they include. Tenants without a plan get the default plan; without a default
+-- plan they are unlimited. A NULL limit means unlimited
+
+CREATE TABLE IF NOT EXISTS tenant_plans (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    code VARCHAR(50) UNIQUE NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    max_active_learners INTEGER CHECK (max_active_learners >= 0),
+    max_courses INTEGER CHECK (max_courses >= 0),
+    max_storage_gb INTEGER CHECK (max_storage_gb >= 0),
+    features TEXT[] NOT NULL DEFAULT '{}',
+    is_default BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- At most one default plan
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tenant_plans_default ON tenant_plans(is_default) WHERE is_default;
+
+CREATE TRIGGER update_tenant_plans_updated_at
+    BEFORE UPDATE ON tenant_plans
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Plans in use can't be deleted
+ALTER TABLE tenants
+ADD COLUMN IF NOT EXISTS plan_id UUID REFERENCES tenant_plans(id) ON DELETE RESTRICT;
+
+CREATE INDEX IF NOT EXISTS idx_tenants_plan_id ON tenants(plan_id);
+
+-- Add comments for documentation
+COMMENT ON TABLE tenant_plans IS 'Stores the plans that define the limits and features of tenants';
+COMMENT ON COLUMN tenant_plans.max_active_learners IS 'Maximum users with a pending or active enrollment, NULL for unlimited';
+COMMENT ON COLUMN tenant_plans.max_courses IS 'Maximum courses not deleted, NULL for unlimited';
+COMMENT ON COLUMN tenant_plans.max_storage_gb IS 'Maximum size of the media files in GB, NULL for unlimited';
+COMMENT ON COLUMN tenant_plans.features IS 'Features included in the plan';
+COMMENT ON COLUMN tenant_plans.is_default IS 'Whether tenants without a plan get this plan';
+COMMENT ON COLUMN tenants.plan_id IS 'Plan of the tenant, NULL for the default plan';
@@ -0,0 +1,8 @@
+-- Rollback migration: Drop feature flags
+
+DROP TRIGGER IF EXISTS update_tenant_feature_overrides_updated_at ON tenant_feature_overrides;
+DROP INDEX IF EXISTS idx_tenant_feature_overrides_tenant_id;
+DROP TABLE IF EXISTS tenant_feature_overrides;
+
+DROP TRIGGER IF EXISTS update_feature_flags_updated_at ON feature_flags;
+DROP TABLE IF EXISTS feature_flags;
@@ -0,0 +1,51 @@
+-- Migration: Create feature flags
+-- Description: Feature flags gate subsystems per tenant. A flag is on for every tenant when it is
+-- enabled, or for a stable share of tenants given by its rollout percentage. Tenant overrides
+-- take precedence over both. Flags named after a plan feature are also off for tenants whose
+-- plan doesn't include the feature, unless a tenant override turns them on
+
+CREATE TABLE IF NOT EXISTS feature_flags (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    key VARCHAR(100) UNIQUE NOT NULL,
+    description TEXT,
+    enabled BOOLEAN NOT NULL DEFAULT false,
+    rollout_percentage INTEGER NOT NULL DEFAULT 0 CHECK (rollout_percentage BETWEEN 0 AND 100),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TRIGGER update_feature_flags_updated_at
+    BEFORE UPDATE ON feature_flags
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE IF NOT EXISTS tenant_feature_overrides (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    flag_id UUID NOT NULL REFERENCES feature_flags(id) ON DELETE CASCADE,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    enabled BOOLEAN NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (flag_id, tenant_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_tenant_feature_overrides_tenant_id ON tenant_feature_overrides(tenant_id);
+
+CREATE TRIGGER update_tenant_feature_overrides_updated_at
+    BEFORE UPDATE ON tenant_feature_overrides
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Gated subsystems start enabled for every tenant
+INSERT INTO feature_flags (key, description, enabled) VALUES
+    ('peer_review', 'Peer review of assignment submissions', true),
+    ('reviews', 'Course reviews and ratings', true),
+    ('certificates', 'Course completion certificates', true)
+ON CONFLICT (key) DO NOTHING;
+
+-- Add comments for documentation
+COMMENT ON TABLE feature_flags IS 'Stores the feature flags and their global defaults';
+COMMENT ON COLUMN feature_flags.key IS 'Identifier used by the code to check the flag';
+COMMENT ON COLUMN feature_flags.enabled IS 'Whether the flag is on for every tenant';
+COMMENT ON COLUMN feature_flags.rollout_percentage IS 'Share of tenants the flag is on for when it is not enabled globally';
+COMMENT ON TABLE tenant_feature_overrides IS 'Stores the flags turned on or off for a single tenant';
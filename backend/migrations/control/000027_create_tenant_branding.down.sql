@@ -0,0 +1,4 @@
+-- Rollback migration: Drop tenant branding
+
+DROP TRIGGER IF EXISTS update_tenant_branding_updated_at ON tenant_branding;
+DROP TABLE IF EXISTS tenant_branding;
@@ -0,0 +1,33 @@
+-- Migration: Create tenant branding
+-- Description: White-label settings of tenants: logo, favicon, color palette, custom CSS, the
+-- sender name, header and footer of outbound emails, and the support contact. Emails and
+-- certificates of a tenant carry its branding
+
+CREATE TABLE IF NOT EXISTS tenant_branding (
+    tenant_id UUID PRIMARY KEY REFERENCES tenants(id) ON DELETE CASCADE,
+    logo_media_id UUID,
+    logo_url TEXT,
+    favicon_url TEXT,
+    palette JSONB NOT NULL DEFAULT '{}',
+    custom_css_url TEXT,
+    email_sender_name VARCHAR(100),
+    email_header TEXT,
+    email_footer TEXT,
+    support_email VARCHAR(255),
+    support_phone VARCHAR(50),
+    support_url TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TRIGGER update_tenant_branding_updated_at
+    BEFORE UPDATE ON tenant_branding
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Add comments for documentation
+COMMENT ON TABLE tenant_branding IS 'Stores the white-label settings of tenants';
+COMMENT ON COLUMN tenant_branding.logo_media_id IS 'Public image in the media library of the tenant';
+COMMENT ON COLUMN tenant_branding.logo_url IS 'URL of the logo media, copied when the logo is set';
+COMMENT ON COLUMN tenant_branding.palette IS 'Hex colors: primary, secondary, accent, background and text';
+COMMENT ON COLUMN tenant_branding.email_sender_name IS 'Display name of the From address of emails';
@@ -0,0 +1,4 @@
+-- Rollback migration: Drop member import jobs
+
+DROP TRIGGER IF EXISTS update_member_import_jobs_updated_at ON member_import_jobs;
+DROP TABLE IF EXISTS member_import_jobs;
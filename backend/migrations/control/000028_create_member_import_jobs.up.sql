@@ -0,0 +1,42 @@
+-- Migration: Create member import jobs
+-- Description: Tracks the background jobs that add the members listed in a CSV or XLSX file to a
+-- tenant, with their progress and the errors of each row. Dry runs only validate the file
+
+CREATE TABLE IF NOT EXISTS member_import_jobs (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    file_name VARCHAR(255) NOT NULL,
+    dry_run BOOLEAN NOT NULL DEFAULT false,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    rows_total INTEGER NOT NULL DEFAULT 0,
+    rows_processed INTEGER NOT NULL DEFAULT 0,
+    users_created INTEGER NOT NULL DEFAULT 0,
+    members_added INTEGER NOT NULL DEFAULT 0,
+    members_unchanged INTEGER NOT NULL DEFAULT 0,
+    enrollments_created INTEGER NOT NULL DEFAULT 0,
+    invitations_sent INTEGER NOT NULL DEFAULT 0,
+    rows_failed INTEGER NOT NULL DEFAULT 0,
+    row_errors JSONB NOT NULL DEFAULT '[]',
+    error TEXT,
+    requested_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    started_at TIMESTAMP WITH TIME ZONE,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT member_import_jobs_status_check CHECK (status IN ('pending', 'running', 'completed', 'failed'))
+);
+
+-- Create indexes for performance
+CREATE INDEX IF NOT EXISTS idx_member_import_jobs_tenant ON member_import_jobs(tenant_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_member_import_jobs_running ON member_import_jobs(status) WHERE status IN ('pending', 'running');
+
+CREATE TRIGGER update_member_import_jobs_updated_at
+    BEFORE UPDATE ON member_import_jobs
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Add comments for documentation
+COMMENT ON TABLE member_import_jobs IS 'Stores the jobs that import the members of a tenant from a CSV or XLSX file';
+COMMENT ON COLUMN member_import_jobs.dry_run IS 'Validates every row and reports what would change without changing anything';
+COMMENT ON COLUMN member_import_jobs.members_unchanged IS 'Rows of users that already were members, so that re-running a file changes nothing';
+COMMENT ON COLUMN member_import_jobs.row_errors IS 'Line, email and error of each row that could not be imported';
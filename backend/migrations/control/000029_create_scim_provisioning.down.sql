@@ -0,0 +1,12 @@
+-- Rollback migration: Drop SCIM provisioning
+
+DROP INDEX IF EXISTS idx_tenant_cohort_members_user_id;
+DROP TABLE IF EXISTS tenant_cohort_members;
+
+DROP TRIGGER IF EXISTS update_tenant_cohorts_updated_at ON tenant_cohorts;
+DROP INDEX IF EXISTS idx_tenant_cohorts_external_id;
+DROP INDEX IF EXISTS idx_tenant_cohorts_display_name;
+DROP TABLE IF EXISTS tenant_cohorts;
+
+DROP INDEX IF EXISTS idx_memberships_tenant_external_id;
+ALTER TABLE tenant_memberships DROP COLUMN IF EXISTS external_id;
@@ -0,0 +1,44 @@
+-- Migration: Create SCIM provisioning
+-- Description: Identity providers provision the members of a tenant through SCIM 2.0 with a
+-- tenant API key. The ID the provider gives a member is kept on its membership. SCIM groups are
+-- cohorts: their members are enrolled in the courses of the cohort
+
+ALTER TABLE tenant_memberships ADD COLUMN IF NOT EXISTS external_id VARCHAR(255);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_memberships_tenant_external_id
+    ON tenant_memberships(tenant_id, external_id) WHERE external_id IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS tenant_cohorts (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    display_name VARCHAR(255) NOT NULL,
+    external_id VARCHAR(255),
+    course_ids UUID[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tenant_cohorts_display_name
+    ON tenant_cohorts(tenant_id, LOWER(display_name));
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tenant_cohorts_external_id
+    ON tenant_cohorts(tenant_id, external_id) WHERE external_id IS NOT NULL;
+
+CREATE TRIGGER update_tenant_cohorts_updated_at
+    BEFORE UPDATE ON tenant_cohorts
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE IF NOT EXISTS tenant_cohort_members (
+    cohort_id UUID NOT NULL REFERENCES tenant_cohorts(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (cohort_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_tenant_cohort_members_user_id ON tenant_cohort_members(user_id);
+
+-- Add comments for documentation
+COMMENT ON COLUMN tenant_memberships.external_id IS 'ID of the member in the identity provider that provisions it through SCIM';
+COMMENT ON TABLE tenant_cohorts IS 'Stores the cohorts of a tenant, exposed as SCIM groups';
+COMMENT ON COLUMN tenant_cohorts.course_ids IS 'Courses the members of the cohort are enrolled in';
+COMMENT ON TABLE tenant_cohort_members IS 'Stores the users in each cohort';
@@ -0,0 +1,7 @@
+-- Rollback migration: Store MFA challenge tokens in plaintext
+
+DELETE FROM mfa_challenges;
+
+COMMENT ON COLUMN mfa_challenges.token_hash IS NULL;
+ALTER TABLE mfa_challenges ALTER COLUMN token_hash TYPE VARCHAR(255);
+ALTER TABLE mfa_challenges RENAME COLUMN token_hash TO token;
@@ -0,0 +1,11 @@
+-- Migration: Hash MFA challenge tokens
+-- Description: Login MFA challenges keep the SHA-256 hash of their token, like API tokens, so
+-- that a database leak can't be used to complete a login. Pending challenges live a few minutes
+-- and can't be hashed in place, so they are discarded and the users log in again
+
+DELETE FROM mfa_challenges;
+
+ALTER TABLE mfa_challenges RENAME COLUMN token TO token_hash;
+ALTER TABLE mfa_challenges ALTER COLUMN token_hash TYPE VARCHAR(64);
+
+COMMENT ON COLUMN mfa_challenges.token_hash IS 'Hex encoded SHA-256 hash of the token returned by the first login step';
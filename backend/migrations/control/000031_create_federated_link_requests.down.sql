@@ -0,0 +1,5 @@
+-- Rollback migration: Drop federated link requests
+
+DROP INDEX IF EXISTS idx_federated_link_requests_expires_at;
+
+DROP TABLE IF EXISTS federated_link_requests;
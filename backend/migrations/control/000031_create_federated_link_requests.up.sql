@@ -0,0 +1,27 @@
+-- Migration: Create federated link requests
+-- Description: A first single sign-on login whose email matches an existing account no longer
+-- links it right away. The login is kept as a link request until the user confirms it with
+-- their local password or second factor.
+
+CREATE TABLE IF NOT EXISTS federated_link_requests (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    token_hash VARCHAR(64) NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    issuer VARCHAR(500) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    role VARCHAR(50) NOT NULL,
+    role_mapped BOOLEAN NOT NULL DEFAULT FALSE,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(token_hash)
+);
+
+CREATE INDEX IF NOT EXISTS idx_federated_link_requests_expires_at ON federated_link_requests(expires_at);
+
+-- Add comments for documentation
+COMMENT ON TABLE federated_link_requests IS 'Stores single sign-on logins waiting for the user to confirm the link to an existing account';
+COMMENT ON COLUMN federated_link_requests.token_hash IS 'SHA-256 of the link token returned to the client';
+COMMENT ON COLUMN federated_link_requests.role_mapped IS 'Whether the role was mapped from the identity provider groups';
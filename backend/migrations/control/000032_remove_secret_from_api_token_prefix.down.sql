@@ -0,0 +1,4 @@
+-- Rollback migration: Remove secret characters from API token prefixes
+-- The removed characters of the secret can't be restored, only the column comment is reverted
+
+COMMENT ON COLUMN api_tokens.token_prefix IS 'First characters of the token so users can recognize it';
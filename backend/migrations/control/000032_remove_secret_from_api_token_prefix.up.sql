@@ -0,0 +1,8 @@
+-- Migration: Remove secret characters from API token prefixes
+-- Description: token_prefix kept the first characters of the plaintext token, four of which
+-- belong to the secret part. It now holds the type prefix followed by the start of the token ID
+
+UPDATE api_tokens
+SET token_prefix = CASE type WHEN 'personal' THEN 'stg_pat_' ELSE 'stg_key_' END || substring(id::text from 1 for 8);
+
+COMMENT ON COLUMN api_tokens.token_prefix IS 'Type prefix and first characters of the token ID so users can recognize it, no secret characters';
@@ -0,0 +1,2 @@
+-- Rollback migration: Remove admin-only permissions from custom roles
+-- The removed permissions granted nothing, so there is nothing to restore
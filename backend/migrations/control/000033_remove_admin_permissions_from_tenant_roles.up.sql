@@ -0,0 +1,8 @@
+-- Migration: Remove admin-only permissions from custom roles
+-- Description: user.manage, role.manage and settings.manage were never checked on any route and
+-- are no longer part of the permission catalog: member, role and settings management require
+-- the admin role. Custom roles that listed them are cleaned up
+
+UPDATE tenant_roles
+SET permissions = array_remove(array_remove(array_remove(permissions, 'user.manage'), 'role.manage'), 'settings.manage')
+WHERE permissions && ARRAY['user.manage', 'role.manage', 'settings.manage'];
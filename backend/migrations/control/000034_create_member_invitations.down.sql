@@ -0,0 +1,4 @@
+-- Rollback migration: Drop member invitations
+
+DROP TRIGGER IF EXISTS update_member_invitations_updated_at ON member_invitations;
+DROP TABLE IF EXISTS member_invitations;
@@ -0,0 +1,25 @@
+-- Migration: Create member invitations
+-- Description: Records the invitation of each member added by an import before the member is
+-- created, so that re-importing a file after a partial failure sends the invitations that were
+-- not sent, with a password setup link for the users the import created
+
+CREATE TABLE IF NOT EXISTS member_invitations (
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL,
+    set_password BOOLEAN NOT NULL DEFAULT false,
+    sent_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (tenant_id, email)
+);
+
+CREATE TRIGGER update_member_invitations_updated_at
+    BEFORE UPDATE ON member_invitations
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Add comments for documentation
+COMMENT ON TABLE member_invitations IS 'Stores the invitations of the members added to a tenant by an import';
+COMMENT ON COLUMN member_invitations.email IS 'Lowercase email of the member';
+COMMENT ON COLUMN member_invitations.set_password IS 'Whether the import created the user, who chooses a password from the invitation';
+COMMENT ON COLUMN member_invitations.sent_at IS 'When the invitation was sent; NULL until then, so that the next import of the member sends it';
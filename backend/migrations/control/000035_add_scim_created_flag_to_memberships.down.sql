@@ -0,0 +1,3 @@
+-- Rollback migration: Remove SCIM created flag from memberships
+
+ALTER TABLE tenant_memberships DROP COLUMN IF EXISTS created_by_scim;
@@ -0,0 +1,7 @@
+-- Migration: Add SCIM created flag to memberships
+-- Description: SCIM can only change the email of the users it created for the tenant. Accounts
+-- that existed before they were provisioned keep the email their owner registered
+
+ALTER TABLE tenant_memberships ADD COLUMN IF NOT EXISTS created_by_scim BOOLEAN NOT NULL DEFAULT false;
+
+COMMENT ON COLUMN tenant_memberships.created_by_scim IS 'Whether the user account was created by the SCIM provisioning of the tenant';
@@ -0,0 +1,3 @@
+-- Rollback migration: Remove enrollment required from MFA challenges
+
+ALTER TABLE mfa_challenges DROP COLUMN IF EXISTS enrollment_required;
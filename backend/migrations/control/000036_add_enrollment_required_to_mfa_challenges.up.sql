@@ -0,0 +1,8 @@
+-- Migration: Add enrollment required to MFA challenges
+-- Description: A login MFA challenge can only start a TOTP enrollment when it was issued to a
+-- user that the tenant policy requires to use MFA and that had no second factor. Challenges of
+-- users with a passkey must be completed with the passkey
+
+ALTER TABLE mfa_challenges ADD COLUMN IF NOT EXISTS enrollment_required BOOLEAN NOT NULL DEFAULT false;
+
+COMMENT ON COLUMN mfa_challenges.enrollment_required IS 'Whether the challenge was issued to enroll the first second factor of the user';
@@ -0,0 +1,7 @@
+-- Rollback migration: Store magic link tokens in plaintext
+
+DELETE FROM magic_link_tokens;
+
+COMMENT ON COLUMN magic_link_tokens.token_hash IS NULL;
+ALTER TABLE magic_link_tokens ALTER COLUMN token_hash TYPE VARCHAR(255);
+ALTER TABLE magic_link_tokens RENAME COLUMN token_hash TO token;
@@ -0,0 +1,11 @@
+-- Migration: Hash magic link tokens
+-- Description: Login links keep the SHA-256 hash of their token, like MFA challenges, so that
+-- a database leak can't be used to log in. Pending links live a few minutes and can't be
+-- hashed in place, so they are discarded and the users request a new link
+
+DELETE FROM magic_link_tokens;
+
+ALTER TABLE magic_link_tokens RENAME COLUMN token TO token_hash;
+ALTER TABLE magic_link_tokens ALTER COLUMN token_hash TYPE VARCHAR(64);
+
+COMMENT ON COLUMN magic_link_tokens.token_hash IS 'Hex encoded SHA-256 hash of the token sent in the login link';
@@ -0,0 +1,6 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_course_instructors_user;
+DROP INDEX IF EXISTS idx_course_instructors_tenant;
+
+-- Drop tables
+DROP TABLE IF EXISTS course_instructors;
@@ -0,0 +1,15 @@
+-- Create course_instructors table for co-instructors of a course
+-- Note: user_id and added_by reference users in the control database, so we don't use REFERENCES constraint
+CREATE TABLE IF NOT EXISTS course_instructors (
+    course_id       UUID NOT NULL REFERENCES courses(id) ON DELETE CASCADE,
+    tenant_id       UUID NOT NULL,
+    user_id         UUID NOT NULL,
+    added_by        UUID NOT NULL,
+    created_at      TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (course_id, user_id)
+);
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_course_instructors_tenant ON course_instructors(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_course_instructors_user ON course_instructors(user_id);
@@ -370,3 +370,24 @@ func TestCourseCategory_Deactivate(t *testing.T) {
 	category.Deactivate()
 	assert.False(t, category.IsActive)
 }
+
+func TestCourseActor_IsAdmin(t *testing.T) {
+	tests := []struct {
+		role string
+		want bool
+	}{
+		{role: "student", want: false},
+		{role: "instructor", want: false},
+		{role: "admin", want: true},
+		{role: "superadmin", want: true},
+		{role: "", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.role, func(t *testing.T) {
+			actor := domain.NewCourseActor(uuid.New(), tt.role)
+
+			assert.Equal(t, tt.want, actor.IsAdmin())
+		})
+	}
+}
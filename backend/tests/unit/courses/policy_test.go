@@ -0,0 +1,92 @@
+package courses
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/courses/domain"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/courses/ports"
+	"github.com/DanielIturra1610/stegmaier-landing/internal/core/courses/services"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockCourseRepository stores a single course and its co-instructors
+type mockCourseRepository struct {
+	ports.CourseRepository
+	course        *domain.Course
+	coInstructors map[uuid.UUID]bool
+}
+
+func (m *mockCourseRepository) GetCourse(ctx context.Context, courseID, tenantID uuid.UUID) (*domain.Course, error) {
+	if m.course == nil || m.course.ID != courseID {
+		return nil, ports.ErrCourseNotFound
+	}
+	return m.course, nil
+}
+
+func (m *mockCourseRepository) IsCourseInstructor(ctx context.Context, courseID, userID, tenantID uuid.UUID) (bool, error) {
+	return m.coInstructors[userID], nil
+}
+
+func TestCoursePolicy(t *testing.T) {
+	tenantID := uuid.New()
+	ownerID := uuid.New()
+	coInstructorID := uuid.New()
+	course := domain.NewCourse(tenantID, ownerID, "Test", "test", "Description", domain.CourseLevelBeginner)
+
+	policy := services.NewCoursePolicy(&mockCourseRepository{
+		course:        course,
+		coInstructors: map[uuid.UUID]bool{coInstructorID: true},
+	})
+
+	tests := []struct {
+		name                  string
+		actor                 domain.CourseActor
+		wantManageCourse      error
+		wantManageInstructors error
+	}{
+		{
+			name:  "owner",
+			actor: domain.NewCourseActor(ownerID, "instructor"),
+		},
+		{
+			name:                  "co-instructor",
+			actor:                 domain.NewCourseActor(coInstructorID, "instructor"),
+			wantManageInstructors: ports.ErrNotCourseOwner,
+		},
+		{
+			name:                  "other instructor",
+			actor:                 domain.NewCourseActor(uuid.New(), "instructor"),
+			wantManageCourse:      ports.ErrNotCourseOwner,
+			wantManageInstructors: ports.ErrNotCourseOwner,
+		},
+		{
+			name:  "tenant admin",
+			actor: domain.NewCourseActor(uuid.New(), "admin"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			assert.Equal(t, tt.wantManageCourse, policy.CanManageCourse(ctx, course.ID, tenantID, tt.actor))
+			assert.Equal(t, tt.wantManageInstructors, policy.CanManageInstructors(ctx, course.ID, tenantID, tt.actor))
+		})
+	}
+
+	t.Run("missing course", func(t *testing.T) {
+		err := policy.CanManageCourse(context.Background(), uuid.New(), tenantID, domain.NewCourseActor(ownerID, "instructor"))
+		assert.Equal(t, ports.ErrCourseNotFound, err)
+	})
+}
+
+func TestCoursePolicy_CanTeachCourse(t *testing.T) {
+	policy := services.NewCoursePolicy(&mockCourseRepository{})
+	instructorID := uuid.New()
+
+	assert.NoError(t, policy.CanTeachCourse(domain.NewCourseActor(instructorID, "instructor"), instructorID))
+	assert.NoError(t, policy.CanTeachCourse(domain.NewCourseActor(uuid.New(), "admin"), instructorID))
+	assert.Equal(t, ports.ErrNotCourseOwner, policy.CanTeachCourse(domain.NewCourseActor(uuid.New(), "instructor"), instructorID))
+}